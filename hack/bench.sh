@@ -0,0 +1,26 @@
+#!/usr/bin/env bash
+# Copyright 2020 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Runs the benchmarks of the k8s-repo-tools packages.
+# BENCH selects the benchmarks to run and defaults to all of them.
+# BENCH_COUNT can be increased to compare runs with benchstat.
+
+set -o errexit
+set -o pipefail
+
+script_path=$(dirname "$(realpath "$0")")
+
+cd "$script_path"/../k8s-repo-tools
+go test -run='^$' -bench="${BENCH:-.}" -benchmem -count="${BENCH_COUNT:-1}" ./...
@@ -36,7 +36,7 @@ fi
 
 set -x
 curl --version
-REFS=$(jq -c ".[]" < "$SYNC_OUTPUT")
+REFS=$(jq -c ".refs[]" < "$SYNC_OUTPUT")
 EVENT_TYPE="dispatch-k8s-create-release"
 RELEASE_TAG="release_tag"
 set +x
@@ -160,6 +160,8 @@ kube::version::ldflags() {
     ldflags+=(
       # k8s.io/kubeadm modification: don't use vendor
       "-X 'k8s.io/component-base/version.${key}=${val}'"
+      # k8s.io/kubeadm modification: also set the version of k8s-repo-tools
+      "-X 'k8s.io/kubeadm/k8s-repo-tools/pkg.${key}=${val}'"
     )
   }
 
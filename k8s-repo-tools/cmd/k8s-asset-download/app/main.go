@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-asset-download"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-asset-download is a tool for downloading and verifying the assets "+
+		"of a release of a GitHub repository")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-asset-download -source=org/repo -token=<token> -release-tag=<tag> -download-dir=<dir> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM.
+	stop := pkg.HandleSignals(&d)
+	defer stop()
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(&d, nil)
+	res, err := process(&d)
+	if err != nil {
+		// Record the assets that were downloaded before the error.
+		if len(d.Output) != 0 {
+			if outputErr := writeOutputToFile(d.Output, res, err, true); outputErr != nil {
+				pkg.PrintErrorAndExit(outputErr)
+			}
+		}
+		if d.Interrupted() {
+			pkg.ExitInterrupted(err)
+		}
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Write the output to disk.
+	if len(d.Output) != 0 {
+		if err := writeOutputToFile(d.Output, res, nil, false); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	pkg.Logf("done!")
+}
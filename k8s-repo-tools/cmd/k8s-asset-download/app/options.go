@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Source         []string `flag:"source" usage:"The org/repo of the release to download" validate:"required,repo"`
+	ReleaseTag     string   `flag:"release-tag" usage:"The tag of the release to download" validate:"required"`
+	DownloadDir    string   `flag:"download-dir" usage:"Path to the directory in which to store the downloaded release assets" validate:"required"`
+	ChecksumsAsset string   `flag:"checksums-asset" default:"SHA256SUMS" usage:"Name of the release asset with the SHA256 checksums of the other assets as written by 'sha256sum'. Empty skips the verification"`
+	Output         string   `flag:"output" usage:"Path to a file that will be written with the downloaded assets and their checksums"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	return pkg.ValidateOptions(d, &options{})
+}
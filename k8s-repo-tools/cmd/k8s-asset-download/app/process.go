@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// downloadedAsset is a release asset that was stored on disk.
+type downloadedAsset struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Size     int    `json:"size"`
+	SHA256   string `json:"sha256"`
+	Verified bool   `json:"verified"`
+}
+
+// result holds the release and the assets that were downloaded.
+type result struct {
+	Repo   string             `json:"repo"`
+	Tag    string             `json:"tag"`
+	Draft  bool               `json:"draft"`
+	Assets []*downloadedAsset `json:"assets"`
+}
+
+// process is responsible for all operations that the application performs.
+// An asset is only written to disk after its checksum was verified.
+func process(d *pkg.Data) (*result, error) {
+	release, err := getRelease(d)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{Repo: d.SourceRepo(), Tag: d.ReleaseTag, Draft: release.GetDraft(), Assets: []*downloadedAsset{}}
+
+	assets := map[string]*github.ReleaseAsset{}
+	names := []string{}
+	for i := range release.Assets {
+		name := release.Assets[i].GetName()
+		// Asset names are used as file names and must not point outside of the directory.
+		if filepath.Base(name) != name || name == ".." {
+			return nil, errors.Errorf("the release has an asset with an invalid file name %q", name)
+		}
+		assets[name] = &release.Assets[i]
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pkg.Logf("found %d asset(s) in the release for tag %q", len(names), d.ReleaseTag)
+
+	if err := os.MkdirAll(d.DownloadDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// Read the checksums of the other assets.
+	var checksums *pkg.ChecksumManifest
+	if len(d.ChecksumsAsset) != 0 {
+		asset, ok := assets[d.ChecksumsAsset]
+		if !ok {
+			return nil, errors.Errorf("the release does not have the checksums asset %q", d.ChecksumsAsset)
+		}
+		data, err := pkg.GitHubDownloadReleaseAsset(d, d.SourceRepo(), asset)
+		if err != nil {
+			return nil, err
+		}
+		if checksums, err = parseChecksums(d.ChecksumsAsset, data, assets); err != nil {
+			return nil, err
+		}
+		if err := writeAsset(d, res, d.ChecksumsAsset, data, false); err != nil {
+			return res, err
+		}
+	} else {
+		pkg.Warningf("the option %q is empty. The assets will not be verified", pkg.FlagChecksumsAsset)
+	}
+
+	for _, name := range names {
+		if name == d.ChecksumsAsset {
+			continue
+		}
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		data, err := pkg.GitHubDownloadReleaseAsset(d, d.SourceRepo(), assets[name])
+		if err != nil {
+			return res, err
+		}
+		if checksums != nil {
+			if err := checksums.Verify(name, data); err != nil {
+				return res, err
+			}
+		}
+		if err := writeAsset(d, res, name, data, checksums != nil); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// getRelease obtains the release for d.ReleaseTag. Draft releases are not returned
+// when getting a release by tag, so the list of releases is searched as well. Drafts
+// are only listed if the token has push access to the repository.
+func getRelease(d *pkg.Data) (*github.RepositoryRelease, error) {
+	release, err := pkg.GitHubGetRelease(d, d.SourceRepo(), d.ReleaseTag)
+	if err == nil || pkg.ErrorKindOf(err) != pkg.ErrorKindNotFound {
+		return release, err
+	}
+	releases, err := pkg.GitHubGetReleases(d, d.SourceRepo())
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if r.GetTagName() == d.ReleaseTag {
+			pkg.Logf("found a draft release for tag %q", d.ReleaseTag)
+			return r, nil
+		}
+	}
+	return nil, pkg.NewErrorf(pkg.ErrorKindNotFound, "could not find a release for tag %q in repository %q", d.ReleaseTag, d.SourceRepo())
+}
+
+// parseChecksums parses the contents of the checksums asset, which is either a checksum
+// manifest in JSON format or in the format written by 'sha256sum'. Every other asset of
+// the release must have a checksum and every listed asset must exist in the release.
+func parseChecksums(name string, data []byte, assets map[string]*github.ReleaseAsset) (*pkg.ChecksumManifest, error) {
+	checksums, err := pkg.ParseChecksumManifest(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse the checksums asset %q", name)
+	}
+	for _, entry := range checksums.Assets {
+		if len(entry.SHA256) == 0 {
+			return nil, errors.Errorf("the asset %q does not have a checksum in %q", entry.Name, name)
+		}
+		if _, ok := assets[entry.Name]; !ok {
+			return nil, errors.Errorf("the asset %q is listed in %q, but is missing from the release", entry.Name, name)
+		}
+	}
+	for asset := range assets {
+		if checksums.Entry(asset) == nil && asset != name {
+			return nil, errors.Errorf("the asset %q is not listed in %q", asset, name)
+		}
+	}
+	return checksums, nil
+}
+
+// writeAsset writes the contents of an asset to d.DownloadDir and adds it to the result.
+func writeAsset(d *pkg.Data, res *result, name string, data []byte, verified bool) error {
+	path := filepath.Join(d.DownloadDir, name)
+	pkg.Logf("writing asset %q to %q", name, path)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	entry := pkg.NewChecksumEntry(name, data)
+	res.Assets = append(res.Assets, &downloadedAsset{
+		Name:     name,
+		Path:     path,
+		Size:     len(data),
+		SHA256:   entry.SHA256,
+		Verified: verified,
+	})
+	return nil
+}
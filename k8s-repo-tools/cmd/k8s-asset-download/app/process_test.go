@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-asset-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The SHA256 checksums of "foo" and "bar".
+	const (
+		sumFoo = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+		sumBar = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+	)
+	sums := []byte(sumFoo + "  kubeadm\n" + sumBar + "  kubectl\n")
+	const sumSums = "48deb35d89061be650158b227d47e89fc85bb4bd0a692380b48648326c7ad3fa"
+	manifest := []byte(`{"assets": [
+		{"name": "kubeadm", "size": 3, "sha256": "` + sumFoo + `"},
+		{"name": "kubectl", "size": 3, "sha256": "` + sumBar + `"}
+	]}`)
+	manifestEntry := pkg.NewChecksumEntry("checksums.json", manifest)
+	contents := map[int64][]byte{
+		1: []byte("foo"),
+		2: []byte("bar"),
+		3: sums,
+		4: []byte(sumBar + "  kubeadm\n" + sumBar + "  kubectl\n"),
+		5: []byte(sumFoo + "  kubeadm\n"),
+		6: manifest,
+	}
+	newAsset := func(id int64, name string) github.ReleaseAsset {
+		return github.ReleaseAsset{ID: github.Int64(id), Name: github.String(name)}
+	}
+	published := []*github.RepositoryRelease{
+		{TagName: github.String("v1.19.0"), Assets: []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(6, "checksums.json")}},
+		{TagName: github.String("v1.17.0"), Assets: []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(3, "SHA256SUMS")}},
+		{TagName: github.String("v1.16.0"), Assets: []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(4, "SHA256SUMS")}},
+		{TagName: github.String("v1.15.0"), Assets: []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(5, "SHA256SUMS")}},
+		{TagName: github.String("v1.14.0"), Assets: []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl")}},
+		{TagName: github.String("v1.13.0"), Assets: []github.ReleaseAsset{newAsset(1, "../kubeadm")}},
+	}
+	draft := &github.RepositoryRelease{
+		TagName: github.String("v1.18.0"),
+		Draft:   github.Bool(true),
+		Assets:  []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(3, "SHA256SUMS")},
+	}
+	// Draft releases cannot be obtained by tag, but they are listed.
+	all := append([]*github.RepositoryRelease{draft}, published...)
+
+	tests := []struct {
+		name           string
+		releaseTag     string
+		checksumsAsset string
+		expected       []*downloadedAsset
+		expectedDraft  bool
+		// expectedMissing are assets that must not be written.
+		expectedMissing []string
+		expectedError   bool
+	}{
+		{
+			name:           "valid: verified assets of a published release",
+			releaseTag:     "v1.17.0",
+			checksumsAsset: pkg.DefaultChecksumsAsset,
+			expected: []*downloadedAsset{
+				{Name: "SHA256SUMS", Size: len(sums), SHA256: sumSums},
+				{Name: "kubeadm", Size: 3, SHA256: sumFoo, Verified: true},
+				{Name: "kubectl", Size: 3, SHA256: sumBar, Verified: true},
+			},
+		},
+		{
+			name:           "valid: verified assets with a checksum manifest",
+			releaseTag:     "v1.19.0",
+			checksumsAsset: "checksums.json",
+			expected: []*downloadedAsset{
+				{Name: "checksums.json", Size: len(manifest), SHA256: manifestEntry.SHA256},
+				{Name: "kubeadm", Size: 3, SHA256: sumFoo, Verified: true},
+				{Name: "kubectl", Size: 3, SHA256: sumBar, Verified: true},
+			},
+		},
+		{
+			name:           "valid: verified assets of a draft release",
+			releaseTag:     "v1.18.0",
+			checksumsAsset: pkg.DefaultChecksumsAsset,
+			expected: []*downloadedAsset{
+				{Name: "SHA256SUMS", Size: len(sums), SHA256: sumSums},
+				{Name: "kubeadm", Size: 3, SHA256: sumFoo, Verified: true},
+				{Name: "kubectl", Size: 3, SHA256: sumBar, Verified: true},
+			},
+			expectedDraft: true,
+		},
+		{
+			name:       "valid: assets are not verified without a checksums asset",
+			releaseTag: "v1.14.0",
+			expected: []*downloadedAsset{
+				{Name: "kubeadm", Size: 3, SHA256: sumFoo},
+				{Name: "kubectl", Size: 3, SHA256: sumBar},
+			},
+		},
+		{
+			name:            "invalid: checksum mismatch",
+			releaseTag:      "v1.16.0",
+			checksumsAsset:  pkg.DefaultChecksumsAsset,
+			expectedMissing: []string{"kubeadm", "kubectl"},
+			expectedError:   true,
+		},
+		{
+			name:           "invalid: asset is not listed in the checksums asset",
+			releaseTag:     "v1.15.0",
+			checksumsAsset: pkg.DefaultChecksumsAsset,
+			expectedError:  true,
+		},
+		{
+			name:           "invalid: missing checksums asset",
+			releaseTag:     "v1.14.0",
+			checksumsAsset: pkg.DefaultChecksumsAsset,
+			expectedError:  true,
+		},
+		{
+			name:          "invalid: asset name outside of the directory",
+			releaseTag:    "v1.13.0",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing release",
+			releaseTag:    "v1.12.0",
+			expectedError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Source:         []string{"org/repo"},
+				ReleaseTag:     tt.releaseTag,
+				ChecksumsAsset: tt.checksumsAsset,
+				DownloadDir:    filepath.Join(dir, fmt.Sprintf("%d", i)),
+			}
+
+			// Create fake client and setup endpoint handlers.
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/repo/releases",
+				pkg.NewReleaseHandler(&all, nil))
+			data.Transport.SetHandler("https://api.github.com/repos/org/repo/releases/tags",
+				pkg.NewReleaseHandler(&published, nil))
+			data.Transport.SetHandler("https://api.github.com/repos/org/repo/releases/assets",
+				pkg.NewReleaseAssetDownloadHandler(contents, nil))
+
+			res, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			for _, name := range tt.expectedMissing {
+				if _, err := os.Stat(filepath.Join(data.DownloadDir, name)); !os.IsNotExist(err) {
+					t.Errorf("expected asset %q to not be written", name)
+				}
+			}
+			if err != nil {
+				return
+			}
+			if res.Draft != tt.expectedDraft {
+				t.Errorf("expected draft %v, got %v", tt.expectedDraft, res.Draft)
+			}
+			for _, a := range tt.expected {
+				a.Path = filepath.Join(data.DownloadDir, a.Name)
+			}
+			if !reflect.DeepEqual(res.Assets, tt.expected) {
+				t.Errorf("expected assets:\n%+v\ngot:\n%+v", tt.expected, res.Assets)
+			}
+			for _, a := range res.Assets {
+				if _, err := os.Stat(a.Path); err != nil {
+					t.Errorf("expected asset %q to be written: %v", a.Name, err)
+				}
+			}
+		})
+	}
+}
@@ -16,8 +16,14 @@ limitations under the License.
 
 package main
 
-type releaseBranchError struct{ error }
-type fastForwardWindowError struct{ error }
-type identicalBranchesError struct{ error }
-type noContentError struct{ error }
-type genericError struct{ error }
+import (
+	"os"
+
+	"k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-asset-download/app"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func main() {
+	app.Main(os.Args[1:])
+	pkg.FinishRun(0, nil)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest             []string      `flag:"dest" usage:"The org/repo from which to delete tags and branches" validate:"required,repo"`
+	KeepPatches      int           `flag:"keep-patches" usage:"Keep only the latest N PATCH release tags for every MAJOR.MINOR and delete older ones. 0 keeps all tags" validate:"nonnegative"`
+	PreReleaseMaxAge time.Duration `flag:"pre-release-max-age" usage:"Delete pre-release tags such as 'v1.17.0-alpha.1' that are older than this duration (e.g. '8760h' for a year). 0 keeps all pre-release tags" validate:"nonnegative"`
+	BranchMaxAge     time.Duration `flag:"branch-max-age" usage:"Delete branches whose last commit is older than this duration. \"master\" and release branches are never deleted. 0 keeps all branches" validate:"nonnegative"`
+	DeleteMerged     bool          `flag:"delete-merged" usage:"Delete branches that are fully merged into \"master\". \"master\" and release branches are never deleted"`
+	PrefixBranch     string        `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	Output           string        `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the policy.
+	if d.KeepPatches == 0 && d.PreReleaseMaxAge == 0 && d.BranchMaxAge == 0 && !d.DeleteMerged {
+		return errors.Errorf("at least one of the options %q, %q, %q or %q is required",
+			pkg.FlagKeepPatches, pkg.FlagPreReleaseMaxAge, pkg.FlagBranchMaxAge, pkg.FlagDeleteMerged)
+	}
+
+	return nil
+}
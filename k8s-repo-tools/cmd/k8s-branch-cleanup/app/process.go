@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// now returns the current time. It can be replaced in tests.
+var now = time.Now
+
+// deletion is a tag or branch that is deleted and the reason for the deletion.
+type deletion struct {
+	Ref    string `json:"ref"`
+	Reason string `json:"reason"`
+}
+
+// process is responsible for all operations that the application performs.
+// It returns the tags and branches that were deleted.
+func process(d *pkg.Data) ([]*deletion, error) {
+	candidates := []*deletion{}
+
+	// Find the tags to delete.
+	if d.KeepPatches > 0 || d.PreReleaseMaxAge > 0 {
+		tags, err := pkg.GitHubGetTags(d, d.DestRepo())
+		if err != nil {
+			return nil, err
+		}
+		pkg.LogRefList("existing tags", d.DestRepo(), tags)
+		found, err := findTags(d, tags)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+
+	// Find the branches to delete.
+	if d.BranchMaxAge > 0 || d.DeleteMerged {
+		branches, err := pkg.GitHubGetBranches(d, d.DestRepo())
+		if err != nil {
+			return nil, err
+		}
+		pkg.LogRefList("existing branches", d.DestRepo(), branches)
+		found, err := findBranches(d, branches)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+
+	deleted := []*deletion{}
+	if len(candidates) == 0 {
+		pkg.Logf("nothing to delete in repository %q", d.DestRepo())
+		return deleted, nil
+	}
+
+	// List the refs that will be deleted.
+	pkg.PrintSeparator()
+	pkg.Logf("found %d tag(s) and branch(es) to delete from repository %q:", len(candidates), d.DestRepo())
+	for _, c := range candidates {
+		pkg.Logf("- %s (%s)", c.Ref, c.Reason)
+	}
+	pkg.PrintSeparator()
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to delete %d tag(s) and branch(es) from repository %q?", len(candidates), d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return deleted, nil
+	}
+
+	for _, c := range candidates {
+		if d.Interrupted() {
+			return deleted, pkg.ErrInterrupted
+		}
+		if err := pkg.GitHubDeleteRef(d, d.DestRepo(), c.Ref, d.DryRun); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, c)
+	}
+	return deleted, nil
+}
+
+// findTags returns the tags that are older than the latest d.KeepPatches PATCH
+// releases of their MAJOR.MINOR and the pre-release tags that are older than
+// d.PreReleaseMaxAge. Tags that are not SemVer are skipped.
+func findTags(d *pkg.Data, tags []*github.Reference) ([]*deletion, error) {
+	type versionRef struct {
+		v   *version.Version
+		ref *github.Reference
+	}
+	stable := map[string][]versionRef{}
+	result := []*deletion{}
+	for _, ref := range tags {
+		v, err := pkg.TagRefToVersion(ref)
+		if err != nil {
+			pkg.V(1).Logf("skipping tag: %v", err)
+			continue
+		}
+
+		if len(v.PreRelease()) == 0 {
+			minor := fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+			stable[minor] = append(stable[minor], versionRef{v: v, ref: ref})
+			continue
+		}
+
+		if d.PreReleaseMaxAge == 0 {
+			continue
+		}
+		date, err := pkg.GitHubGetRefDate(d, d.DestRepo(), ref)
+		if err != nil {
+			return nil, err
+		}
+		if age := now().Sub(date); age > d.PreReleaseMaxAge {
+			result = append(result, &deletion{
+				Ref:    ref.GetRef(),
+				Reason: fmt.Sprintf("pre-release tag from %s is older than %v", date.Format("2006-01-02"), d.PreReleaseMaxAge),
+			})
+		}
+	}
+
+	if d.KeepPatches > 0 {
+		minors := make([]string, 0, len(stable))
+		for minor := range stable {
+			minors = append(minors, minor)
+		}
+		sort.Strings(minors)
+		for _, minor := range minors {
+			refs := stable[minor]
+			sort.Slice(refs, func(i, j int) bool {
+				return refs[j].v.LessThan(refs[i].v)
+			})
+			for i := d.KeepPatches; i < len(refs); i++ {
+				result = append(result, &deletion{
+					Ref:    refs[i].ref.GetRef(),
+					Reason: fmt.Sprintf("not one of the latest %d PATCH release(s) of %s", d.KeepPatches, minor),
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// findBranches returns the branches that are fully merged into the master branch if
+// d.DeleteMerged is set, and the branches whose last commit is older than d.BranchMaxAge.
+// The master branch and release branches are never returned.
+func findBranches(d *pkg.Data, branches []*github.Reference) ([]*deletion, error) {
+	result := []*deletion{}
+	for _, ref := range branches {
+		name := strings.TrimPrefix(ref.GetRef(), "refs/heads/")
+		if name == pkg.BranchMaster {
+			continue
+		}
+		if _, err := pkg.BranchRefToVersion(ref, d.PrefixBranch); err == nil {
+			pkg.V(2).Logf("skipping release branch %q", ref.GetRef())
+			continue
+		}
+
+		if d.DeleteMerged {
+			cmp, err := pkg.GitHubCompareBranches(d, d.DestRepo(), pkg.BranchMaster, ref.GetObject().GetSHA())
+			if err != nil {
+				return nil, err
+			}
+			// If the HEAD of the branch is reachable on master, the branch is merged.
+			if status := cmp.GetStatus(); status == "behind" || status == "identical" {
+				result = append(result, &deletion{
+					Ref:    ref.GetRef(),
+					Reason: fmt.Sprintf("merged into %q", pkg.BranchMaster),
+				})
+				continue
+			}
+		}
+
+		if d.BranchMaxAge > 0 {
+			date, err := pkg.GitHubGetRefDate(d, d.DestRepo(), ref)
+			if err != nil {
+				return nil, err
+			}
+			if age := now().Sub(date); age > d.BranchMaxAge {
+				result = append(result, &deletion{
+					Ref:    ref.GetRef(),
+					Reason: fmt.Sprintf("last commit from %s is older than %v", date.Format("2006-01-02"), d.BranchMaxAge),
+				})
+			}
+		}
+	}
+	return result, nil
+}
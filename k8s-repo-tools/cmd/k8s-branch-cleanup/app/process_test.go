@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC) }
+	dateOld := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateNew := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	newRef := func(ref, sha, objectType string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha), Type: github.String(objectType)}}
+	}
+	refs := []*github.Reference{
+		newRef("refs/heads/master", "new", "commit"),
+		newRef("refs/heads/release-1.17", "old", "commit"),
+		newRef("refs/heads/feature-merged", "merged", "commit"),
+		newRef("refs/heads/feature-old", "old", "commit"),
+		newRef("refs/heads/feature-new", "new", "commit"),
+		newRef("refs/tags/v1.17.0", "old", "commit"),
+		newRef("refs/tags/v1.17.2", "new", "commit"),
+		newRef("refs/tags/v1.17.1", "old", "commit"),
+		newRef("refs/tags/v1.18.0-alpha.1", "old", "commit"),
+		newRef("refs/tags/v1.18.0-rc.1", "tag-rc", "tag"),
+		newRef("refs/tags/foo", "old", "commit"),
+	}
+	commits := map[string]*github.Commit{
+		"old":    {SHA: github.String("old"), Committer: &github.CommitAuthor{Date: &dateOld}},
+		"new":    {SHA: github.String("new"), Committer: &github.CommitAuthor{Date: &dateNew}},
+		"merged": {SHA: github.String("merged"), Committer: &github.CommitAuthor{Date: &dateNew}},
+	}
+	tags := map[string]*github.Tag{
+		"tag-rc": {SHA: github.String("tag-rc"), Tagger: &github.CommitAuthor{Date: &dateNew}},
+	}
+	commit := func(sha string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha)}
+	}
+	commitsShort := []*github.RepositoryCommit{commit("1")}
+	commitsLong := []*github.RepositoryCommit{commit("1"), commit("2")}
+
+	tests := []struct {
+		name             string
+		keepPatches      int
+		preReleaseMaxAge time.Duration
+		branchMaxAge     time.Duration
+		deleteMerged     bool
+		methodErrors     map[string]bool
+		expectedDeleted  []*deletion
+		expectedError    bool
+		skipDryRun       bool
+	}{
+		{
+			name:        "valid: keep the latest PATCH release",
+			keepPatches: 1,
+			expectedDeleted: []*deletion{
+				{Ref: "refs/tags/v1.17.1", Reason: "not one of the latest 1 PATCH release(s) of 1.17"},
+				{Ref: "refs/tags/v1.17.0", Reason: "not one of the latest 1 PATCH release(s) of 1.17"},
+			},
+		},
+		{
+			name:             "valid: delete pre-release tags older than a year",
+			preReleaseMaxAge: 365 * 24 * time.Hour,
+			expectedDeleted: []*deletion{
+				{Ref: "refs/tags/v1.18.0-alpha.1", Reason: "pre-release tag from 2019-01-01 is older than 8760h0m0s"},
+			},
+		},
+		{
+			name:         "valid: delete merged and stale branches",
+			deleteMerged: true,
+			branchMaxAge: 30 * 24 * time.Hour,
+			expectedDeleted: []*deletion{
+				{Ref: "refs/heads/feature-merged", Reason: `merged into "master"`},
+				{Ref: "refs/heads/feature-old", Reason: "last commit from 2019-01-01 is older than 720h0m0s"},
+			},
+		},
+		{
+			name:            "valid: nothing to delete",
+			keepPatches:     5,
+			expectedDeleted: []*deletion{},
+		},
+		{
+			name:          "invalid: simulated error when getting the refs",
+			keepPatches:   1,
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: simulated error when deleting a ref",
+			keepPatches:   1,
+			methodErrors:  map[string]bool{http.MethodDelete: true},
+			expectedError: true,
+			skipDryRun:    true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like DELETE will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Dest:             []string{"org/dest"},
+					PrefixBranch:     pkg.PrefixBranch,
+					KeepPatches:      tt.keepPatches,
+					PreReleaseMaxAge: tt.preReleaseMaxAge,
+					BranchMaxAge:     tt.branchMaxAge,
+					DeleteMerged:     tt.deleteMerged,
+					DryRun:           dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handler modifies it.
+				refsDest := append([]*github.Reference{}, refs...)
+
+				// Create fake client and setup endpoint handlers.
+				api := "https://api.github.com/repos/org/dest/"
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler(api+"git/refs", pkg.NewReferenceHandler(&refsDest, tt.methodErrors))
+				data.Transport.SetHandler(api+"git/commits", pkg.NewGitCommitHandler(commits, map[string]bool{}))
+				data.Transport.SetHandler(api+"git/tags", pkg.NewGitTagHandler(tags, map[string]bool{}))
+				// "feature-merged" is behind master and the other branches are ahead of master.
+				data.Transport.SetHandler(api+"compare", pkg.NewCompareHandler(&commitsLong, &commitsShort, map[string]bool{}))
+				data.Transport.SetHandler(api+"compare/master...merged", pkg.NewCompareHandler(&commitsShort, &commitsLong, map[string]bool{}))
+
+				deleted, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(deleted, tt.expectedDeleted) {
+					t.Errorf("expected deleted:\n%+v\ngot:\n%+v", tt.expectedDeleted, deleted)
+				}
+
+				// In dry-run mode no refs are deleted.
+				expectedRefs := len(refs)
+				if !dryRunVal {
+					expectedRefs -= len(tt.expectedDeleted)
+				}
+				if len(refsDest) != expectedRefs {
+					t.Errorf("expected %d refs, got %d", expectedRefs, len(refsDest))
+				}
+			})
+		}
+	}
+}
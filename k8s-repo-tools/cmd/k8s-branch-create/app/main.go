@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-branch-create"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-branch-create is a tool for cutting a new release branch "+
+		"from the default branch of a GitHub repository")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-branch-create -dest=org/repo -token=<token> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Apply a plan that was written in DRY-RUN mode.
+	if len(d.ApplyPlanFile) != 0 {
+		pkg.RunApplyPlan(&d, Name)
+		return
+	}
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Print a warning in dry-run mode.
+	if d.DryRun {
+		pkg.PrintSeparator()
+		pkg.Warningf("running in %s mode. To enable repository writing operations pass --%s=false", pkg.PrefixDryRun, pkg.FlagDryRun)
+		pkg.PrintSeparator()
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM.
+	stop := pkg.HandleSignals(&d)
+	defer stop()
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(&d, nil)
+
+	// Verify the token before writing.
+	if d.Preflight && !d.DryRun {
+		if err := pkg.GitHubPreflightToken(&d, d.DestRepo()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	res, err := process(&d)
+	if err != nil && d.Interrupted() {
+		if len(d.Output) != 0 {
+			if outputErr := writeOutputToFile(d.Output, res, pkg.ErrInterrupted, true); outputErr != nil {
+				pkg.PrintErrorAndExit(outputErr)
+			}
+		}
+		pkg.ExitInterrupted(err)
+	}
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Write the output to disk.
+	if len(d.Output) != 0 {
+		if err := writeOutputToFile(d.Output, res, nil, false); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+
+	// Write the plan in DRY-RUN mode.
+	if d.DryRun && len(d.PlanFile) != 0 {
+		if err := pkg.WritePlanToFile(d.PlanFile, Name, d.GetPlanSteps()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	pkg.Logf("done!")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest          []string `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	PrefixBranch  string   `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	ReleaseBranch string   `flag:"release-branch" usage:"Name of the release branch to create in the format \"prefixMAJOR.MINOR\". Defaults to the next MINOR after the latest release branch"`
+	ProtectBranch bool     `flag:"protect-branch" usage:"Apply branch protection to the new release branch, which requires an approving review for pull requests"`
+	Output        string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+	Strict        bool     `flag:"strict" usage:"Fail instead of continuing with a warning when refs that are not valid versions are found or a repository has been renamed"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the optional release branch.
+	if len(d.ReleaseBranch) != 0 {
+		if !strings.HasPrefix(d.ReleaseBranch, d.PrefixBranch) {
+			return errors.Errorf("the option %q must start with the branch prefix %q", pkg.FlagReleaseBranch, d.PrefixBranch)
+		}
+		if _, err := releaseBranchVersion(d.ReleaseBranch, d.PrefixBranch); err != nil {
+			return errors.Wrapf(err, "invalid value for the option %q", pkg.FlagReleaseBranch)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// result holds the references that were created.
+type result struct {
+	branch    *github.Reference
+	tag       *github.Reference
+	protected bool
+}
+
+var regexpMajorMinor = regexp.MustCompile(`^v?[0-9]+\.[0-9]+$`)
+
+// releaseBranchVersion returns the version of a release branch name
+// of the format "prefixMAJOR.MINOR".
+func releaseBranchVersion(name, prefix string) (*version.Version, error) {
+	if !regexpMajorMinor.MatchString(strings.TrimPrefix(name, prefix)) {
+		return nil, errors.Errorf("the release branch %q must be of the format %q", name, prefix+"MAJOR.MINOR")
+	}
+	return pkg.BranchRefToVersion(&github.Reference{Ref: github.String("refs/heads/" + name)}, prefix)
+}
+
+// process is responsible for all operations that the application performs.
+// Given the release branch prefixX.Y it:
+// - creates the release branch from the HEAD of the default branch
+// - creates the tag vX.(Y+1).0-alpha.0 on the default branch
+// - optionally protects the release branch
+func process(d *pkg.Data) (*result, error) {
+	pkg.Logf("using branch prefix %q", d.PrefixBranch)
+
+	// Obtain the default branch of the destination repository.
+	repo, err := pkg.GitHubGetRepository(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	defaultBranch := repo.GetDefaultBranch()
+	if len(defaultBranch) == 0 {
+		defaultBranch = pkg.BranchMaster
+	}
+	pkg.Logf("using %q as the default branch of repository %q", defaultBranch, d.DestRepo())
+
+	// Obtain destination repository tags and branches.
+	tagsDest, err := pkg.GitHubGetTags(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	branchesDest, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	pkg.LogRefList("existing tags", d.DestRepo(), tagsDest)
+	pkg.LogRefList("existing branches", d.DestRepo(), branchesDest)
+
+	// Determine the name of the new release branch.
+	branchName := d.ReleaseBranch
+	if len(branchName) == 0 {
+		latestBranch, err := pkg.FindLatestBranch(branchesDest, d.PrefixBranch, d.Strict)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot determine the name of the new release branch. Please use --%s", pkg.FlagReleaseBranch)
+		}
+		latestBranchVer, _ := pkg.BranchRefToVersion(latestBranch, d.PrefixBranch)
+		next := pkg.NextMinor(latestBranchVer)
+		branchName = fmt.Sprintf("%s%d.%d", d.PrefixBranch, next.Major(), next.Minor())
+		pkg.Logf("found %q as the latest versioned branch", latestBranch.GetRef())
+	}
+	branchVer, err := releaseBranchVersion(branchName, d.PrefixBranch)
+	if err != nil {
+		return nil, err
+	}
+	nextMinor := pkg.NextMinor(branchVer)
+	branchRef := "refs/heads/" + branchName
+	tagRef := fmt.Sprintf("refs/tags/v%d.%d.0-alpha.0", nextMinor.Major(), nextMinor.Minor())
+
+	// The branch and the tag must not exist.
+	if ref := findRef(branchesDest, branchRef); ref != nil {
+		return nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the branch %q already exists in repository %q", branchRef, d.DestRepo())
+	}
+	if ref := findRef(tagsDest, tagRef); ref != nil {
+		return nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the tag %q already exists in repository %q", tagRef, d.DestRepo())
+	}
+
+	// Find the HEAD of the default branch.
+	head := findRef(branchesDest, "refs/heads/"+defaultBranch)
+	if head == nil {
+		return nil, errors.Errorf("could not find the default branch %q in repository %q", defaultBranch, d.DestRepo())
+	}
+	sha := head.GetObject().GetSHA()
+	pkg.Logf("the HEAD of branch %q is at commit %q", defaultBranch, sha)
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create branch %q and tag %q from commit %q in repository %q?",
+		branchRef, tagRef, sha, d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return nil, nil
+	}
+
+	// Create the release branch and the tag.
+	res := &result{}
+	if res.branch, err = pkg.GitHubCreateRef(d, d.DestRepo(), branchRef, sha, d.DryRun); err != nil {
+		return nil, err
+	}
+	if !d.DryRun && d.Interrupted() {
+		return res, pkg.ErrInterrupted
+	}
+	if res.tag, err = pkg.GitHubCreateRef(d, d.DestRepo(), tagRef, sha, d.DryRun); err != nil {
+		return res, err
+	}
+
+	// Protect the release branch.
+	if d.ProtectBranch {
+		if !d.DryRun && d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		if err := pkg.GitHubProtectBranch(d, d.DestRepo(), branchName, d.DryRun); err != nil {
+			return res, err
+		}
+		res.protected = true
+	}
+	return res, nil
+}
+
+// findRef returns the reference with the given name from a list of references.
+func findRef(refs []*github.Reference, name string) *github.Reference {
+	for _, ref := range refs {
+		if ref.GetRef() == name {
+			return ref
+		}
+	}
+	return nil
+}
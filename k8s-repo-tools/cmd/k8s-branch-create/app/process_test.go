@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	newRef := func(ref, sha string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+	}
+
+	tests := []struct {
+		name              string
+		defaultBranch     string
+		releaseBranch     string
+		protectBranch     bool
+		refsDest          []*github.Reference
+		methodErrorsRef   map[string]bool
+		methodErrorsRepo  map[string]bool
+		expectedRefs      []*github.Reference
+		expectedProtected []string
+		expectedResult    *result
+		expectedError     bool
+		expectedErrorKind pkg.ErrorKind
+		skipDryRun        bool
+	}{
+		{
+			name: "valid: create the next release branch and the alpha.0 tag",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+				newRef("refs/heads/release-1.17", "sha-1.17"),
+				newRef("refs/tags/v1.18.0-alpha.3", "sha-master"),
+			},
+			expectedRefs: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+				newRef("refs/heads/release-1.17", "sha-1.17"),
+				newRef("refs/tags/v1.18.0-alpha.3", "sha-master"),
+				newRef("refs/heads/release-1.18", "sha-master"),
+				newRef("refs/tags/v1.19.0-alpha.0", "sha-master"),
+			},
+			expectedResult: &result{
+				branch: newRef("refs/heads/release-1.18", "sha-master"),
+				tag:    newRef("refs/tags/v1.19.0-alpha.0", "sha-master"),
+			},
+		},
+		{
+			name:          "valid: create an explicit release branch from a custom default branch and protect it",
+			defaultBranch: "main",
+			releaseBranch: "release-2.0",
+			protectBranch: true,
+			refsDest: []*github.Reference{
+				newRef("refs/heads/main", "sha-main"),
+			},
+			expectedRefs: []*github.Reference{
+				newRef("refs/heads/main", "sha-main"),
+				newRef("refs/heads/release-2.0", "sha-main"),
+				newRef("refs/tags/v2.1.0-alpha.0", "sha-main"),
+			},
+			expectedProtected: []string{"release-2.0"},
+			expectedResult: &result{
+				branch:    newRef("refs/heads/release-2.0", "sha-main"),
+				tag:       newRef("refs/tags/v2.1.0-alpha.0", "sha-main"),
+				protected: true,
+			},
+		},
+		{
+			name: "invalid: no release branches and no explicit release branch",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindNoReleaseBranch,
+		},
+		{
+			name:          "invalid: the release branch already exists",
+			releaseBranch: "release-1.17",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+				newRef("refs/heads/release-1.17", "sha-1.17"),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name: "invalid: the alpha.0 tag already exists",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+				newRef("refs/heads/release-1.17", "sha-1.17"),
+				newRef("refs/tags/v1.19.0-alpha.0", "sha-master"),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name:          "invalid: the default branch does not exist",
+			releaseBranch: "release-1.18",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/release-1.17", "sha-1.17"),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindGeneric,
+		},
+		{
+			name: "invalid: return error obtaining the repository",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+			},
+			methodErrorsRepo:  map[string]bool{http.MethodGet: true},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindGeneric,
+		},
+		{
+			name:          "invalid: return error creating refs",
+			releaseBranch: "release-1.18",
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master", "sha-master"),
+			},
+			methodErrorsRef:   map[string]bool{http.MethodPost: true},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindGeneric,
+			skipDryRun:        true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{}
+				data.Dest = []string{"org/dest"}
+				data.PrefixBranch = pkg.PrefixBranch
+				data.ReleaseBranch = tt.releaseBranch
+				data.ProtectBranch = tt.protectBranch
+				data.SetPrompter(pkg.PrompterYes)
+				data.DryRun = dryRunVal
+
+				refs := append([]*github.Reference{}, tt.refsDest...)
+				protected := []string{}
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest",
+					pkg.NewRepositoryHandler(&github.Repository{DefaultBranch: github.String(tt.defaultBranch)}, tt.methodErrorsRepo))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs",
+					pkg.NewReferenceHandler(&refs, tt.methodErrorsRef))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/branches",
+					pkg.NewBranchProtectionHandler(&protected, nil))
+
+				res, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if kind := pkg.ErrorKindOf(err); err != nil && kind != tt.expectedErrorKind {
+					t.Fatalf("expected error kind %q, got %q, error: %v", tt.expectedErrorKind, kind, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(res, tt.expectedResult) {
+					t.Errorf("expected result:\n%+v\ngot:\n%+v", tt.expectedResult, res)
+				}
+
+				// In dry-run mode no refs are created and no branches are protected.
+				expectedRefs := tt.expectedRefs
+				expectedProtected := tt.expectedProtected
+				if dryRunVal {
+					expectedRefs = tt.refsDest
+					expectedProtected = nil
+				}
+				if !reflect.DeepEqual(refs, expectedRefs) {
+					t.Errorf("expected refs:\n%v\ngot:\n%v", expectedRefs, refs)
+				}
+				if len(protected) != len(expectedProtected) || (len(protected) != 0 && !reflect.DeepEqual(protected, expectedProtected)) {
+					t.Errorf("expected protected branches %v, got %v", expectedProtected, protected)
+				}
+			})
+		}
+	}
+}
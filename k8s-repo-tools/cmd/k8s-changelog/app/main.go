@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-changelog"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-changelog is a tool for adding the release notes of a tag "+
+		"to the CHANGELOG of a GitHub repository")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-changelog -dest=org/repo -token=<token> -release-tag=<tag> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Apply a plan that was written in DRY-RUN mode.
+	if len(d.ApplyPlanFile) != 0 {
+		pkg.RunApplyPlan(&d, Name)
+		return
+	}
+
+	// Trim 'refs/tags/' from the ReleaseTag.
+	d.ReleaseTag = strings.TrimPrefix(d.ReleaseTag, "refs/tags/")
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Print a warning in dry-run mode.
+	if d.DryRun {
+		pkg.PrintSeparator()
+		pkg.Warningf("running in %s mode. To enable repository writing operations pass --%s=false", pkg.PrefixDryRun, pkg.FlagDryRun)
+		pkg.PrintSeparator()
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM.
+	stop := pkg.HandleSignals(&d)
+	defer stop()
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(&d, nil)
+
+	// Verify the token before writing.
+	if d.Preflight && !d.DryRun {
+		if err := pkg.GitHubPreflightToken(&d, d.DestRepo()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	branch, pr, err := process(&d)
+	if err != nil && d.Interrupted() {
+		pkg.ExitInterrupted(err)
+	}
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Write the output to disk.
+	if len(d.Output) != 0 {
+		if err := writeOutputToFile(d.Output, changelogPath(d.ReleaseTag), branch, pr, nil); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+
+	// Write the plan in DRY-RUN mode.
+	if d.DryRun && len(d.PlanFile) != 0 {
+		if err := pkg.WritePlanToFile(d.PlanFile, Name, d.GetPlanSteps()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	pkg.Logf("done!")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.PullRequestNotesOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest                 []string `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	ReleaseTag           string   `flag:"release-tag" usage:"A SemVer tag from which to create a release" validate:"required,semver"`
+	ReleaseNotesPath     string   `flag:"release-notes-path" usage:"Path to a text file containing release notes. Overrides the usage of \"release-notes-tool-path\""`
+	ReleaseNotesToolPath string   `flag:"release-notes-tool-path" usage:"Path to the release notes tool binary"`
+	BaseBranch           string   `flag:"base-branch" default:"master" usage:"Name of the branch to commit to. If a pull request is created this is the base branch of the pull request" validate:"required"`
+	PullRequest          bool     `flag:"pull-request" usage:"Commit to a new branch and create a pull request against \"base-branch\" instead of committing to \"base-branch\" directly"`
+	PrefixBranch         string   `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	Output               string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate that the release notes can be obtained.
+	if err := pkg.ValidatePullRequestNotesOptions(d); err != nil {
+		return err
+	}
+	if len(d.ReleaseNotesPath) == 0 && len(d.ReleaseNotesToolPath) == 0 && !d.PullRequestNotes {
+		return errors.Errorf("one of the options %q, %q or %q is required",
+			pkg.FlagReleaseNotesPath, pkg.FlagReleaseNotesToolPath, pkg.FlagReleaseNotesFromPullRequests)
+	}
+
+	return nil
+}
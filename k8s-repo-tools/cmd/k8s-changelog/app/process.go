@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// sectionRegexp matches the heading of a release section in a changelog.
+var sectionRegexp = regexp.MustCompile(`^# v?[0-9]+\.[0-9]+\.[0-9]+`)
+
+// process is responsible for all operations that the application performs.
+// It returns the branch to which the changelog was committed and the pull request
+// if one was created. The branch is empty if the changelog was not changed.
+func process(d *pkg.Data) (string, *github.PullRequest, error) {
+	path := changelogPath(d.ReleaseTag)
+
+	// Obtain the existing changelog. A missing changelog is created.
+	changelog, sha, err := pkg.GitHubGetFile(d, d.DestRepo(), d.BaseBranch, path)
+	if err != nil {
+		if pkg.ErrorKindOf(err) != pkg.ErrorKindNotFound {
+			return "", nil, err
+		}
+		pkg.Logf("the file %q does not exist in branch %q and will be created", path, d.BaseBranch)
+	}
+	if hasChangelogSection(changelog, d.ReleaseTag) {
+		pkg.Logf("the file %q already has a section for tag %q", path, d.ReleaseTag)
+		return "", nil, nil
+	}
+
+	// Obtain the release notes.
+	notes, err := pkg.GenerateReleaseNotes(d, d.DestRepo(), d.ReleaseTag)
+	if err != nil {
+		return "", nil, err
+	}
+	changelog = addChangelogSection(changelog, d.ReleaseTag, notes)
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to add the release notes for tag %q to the file %q in repository %q?",
+		d.ReleaseTag, path, d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return "", nil, err
+	}
+	if !yes {
+		return "", nil, nil
+	}
+
+	message := fmt.Sprintf("CHANGELOG: add release notes for %s", d.ReleaseTag)
+	if !d.PullRequest {
+		if err := pkg.GitHubUpdateFile(d, d.DestRepo(), d.BaseBranch, path, message, changelog, sha, d.DryRun); err != nil {
+			return "", nil, err
+		}
+		return d.BaseBranch, nil, nil
+	}
+
+	// Create a new branch from the HEAD of the base branch.
+	head := "changelog-" + d.ReleaseTag
+	branches, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return "", nil, err
+	}
+	var base *github.Reference
+	for _, branch := range branches {
+		switch branch.GetRef() {
+		case "refs/heads/" + head:
+			return "", nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the branch %q already exists in repository %q", head, d.DestRepo())
+		case "refs/heads/" + d.BaseBranch:
+			base = branch
+		}
+	}
+	if base == nil {
+		return "", nil, errors.Errorf("could not find branch %q in repository %q", d.BaseBranch, d.DestRepo())
+	}
+	if _, err := pkg.GitHubCreateRef(d, d.DestRepo(), "refs/heads/"+head, base.GetObject().GetSHA(), d.DryRun); err != nil {
+		return "", nil, err
+	}
+
+	// Commit the changelog to the new branch and create a pull request.
+	if err := pkg.GitHubUpdateFile(d, d.DestRepo(), head, path, message, changelog, sha, d.DryRun); err != nil {
+		return "", nil, err
+	}
+	body := fmt.Sprintf("Add the release notes for %s to %s.", d.ReleaseTag, path)
+	pr, err := pkg.GitHubCreatePullRequest(d, d.DestRepo(), d.BaseBranch, head, message, body, nil, d.DryRun)
+	if err != nil {
+		return "", nil, err
+	}
+	return head, pr, nil
+}
+
+// changelogPath returns the path of the changelog for the MAJOR.MINOR of a tag.
+func changelogPath(tag string) string {
+	v, err := version.ParseSemantic(tag)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("CHANGELOG/CHANGELOG-%d.%d.md", v.Major(), v.Minor())
+}
+
+// hasChangelogSection returns true if a changelog has a section for a tag.
+func hasChangelogSection(changelog, tag string) bool {
+	for _, line := range strings.Split(changelog, "\n") {
+		if strings.TrimSpace(line) == "# "+tag {
+			return true
+		}
+	}
+	return false
+}
+
+// addChangelogSection inserts a section with release notes for a tag before the first
+// release section of a changelog, so that newer releases are listed first. Content
+// before the first release section, such as a table of contents, is preserved.
+func addChangelogSection(changelog, tag, notes string) string {
+	section := fmt.Sprintf("# %s\n\n%s\n", tag, strings.TrimSpace(notes))
+	lines := strings.SplitAfter(changelog, "\n")
+	offset := 0
+	for _, line := range lines {
+		if sectionRegexp.MatchString(line) {
+			return changelog[:offset] + section + "\n" + changelog[offset:]
+		}
+		offset += len(line)
+	}
+	if len(strings.TrimSpace(changelog)) == 0 {
+		return section
+	}
+	return strings.TrimRight(changelog, "\n") + "\n\n" + section
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-changelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	notesPath := filepath.Join(dir, "notes.md")
+	if err := ioutil.WriteFile(notesPath, []byte("- Fix a bug.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		path      = "CHANGELOG/CHANGELOG-1.18.md"
+		shaMaster = "1111111111111111111111111111111111111111"
+		existing  = "- [v1.18.0](#v1180)\n\n# v1.18.0\n\n- Add a feature.\n"
+	)
+	newRef := func(ref, sha string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+	}
+
+	tests := []struct {
+		name                 string
+		releaseTag           string
+		pullRequest          bool
+		files                map[string]string
+		refs                 []*github.Reference
+		methodErrorsContents map[string]bool
+		expectedBranch       string
+		expectedChangelog    string
+		expectedPullRequest  bool
+		expectedSteps        int
+		expectedError        bool
+		expectedErrorKind    pkg.ErrorKind
+	}{
+		{
+			name:              "valid: create a missing changelog",
+			releaseTag:        "v1.18.0",
+			files:             map[string]string{},
+			expectedBranch:    "master",
+			expectedChangelog: "# v1.18.0\n\n- Fix a bug.\n",
+			expectedSteps:     1,
+		},
+		{
+			name:              "valid: add a section before the latest release",
+			releaseTag:        "v1.18.1",
+			files:             map[string]string{path: existing},
+			expectedBranch:    "master",
+			expectedChangelog: "- [v1.18.0](#v1180)\n\n# v1.18.1\n\n- Fix a bug.\n\n# v1.18.0\n\n- Add a feature.\n",
+			expectedSteps:     1,
+		},
+		{
+			name:              "valid: the changelog already has a section for the tag",
+			releaseTag:        "v1.18.0",
+			files:             map[string]string{path: existing},
+			expectedChangelog: existing,
+		},
+		{
+			name:                "valid: commit to a new branch and create a pull request",
+			releaseTag:          "v1.18.1",
+			pullRequest:         true,
+			files:               map[string]string{path: existing},
+			refs:                []*github.Reference{newRef("refs/heads/master", shaMaster)},
+			expectedBranch:      "changelog-v1.18.1",
+			expectedChangelog:   "- [v1.18.0](#v1180)\n\n# v1.18.1\n\n- Fix a bug.\n\n# v1.18.0\n\n- Add a feature.\n",
+			expectedPullRequest: true,
+			expectedSteps:       3,
+		},
+		{
+			name:        "invalid: the branch for the pull request already exists",
+			releaseTag:  "v1.18.1",
+			pullRequest: true,
+			files:       map[string]string{path: existing},
+			refs: []*github.Reference{
+				newRef("refs/heads/master", shaMaster),
+				newRef("refs/heads/changelog-v1.18.1", shaMaster),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name:                 "invalid: simulated error when getting the changelog",
+			releaseTag:           "v1.18.1",
+			files:                map[string]string{path: existing},
+			methodErrorsContents: map[string]bool{http.MethodGet: true},
+			expectedError:        true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				data := &pkg.Data{
+					Dest:             []string{"org/dest"},
+					ReleaseTag:       tt.releaseTag,
+					ReleaseNotesPath: notesPath,
+					BaseBranch:       pkg.BranchMaster,
+					PullRequest:      tt.pullRequest,
+					PrefixBranch:     pkg.PrefixBranch,
+					DryRun:           dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handlers modify it.
+				files := map[string]string{}
+				for k, v := range tt.files {
+					files[k] = v
+				}
+				refs := append([]*github.Reference{}, tt.refs...)
+				commits := []*github.RepositoryContentFileOptions{}
+				prs := []*github.PullRequest{}
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/contents",
+					pkg.NewContentsHandler(files, &commits, tt.methodErrorsContents))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs",
+					pkg.NewReferenceHandler(&refs, map[string]bool{}))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/pulls",
+					pkg.NewPullRequestHandler(&prs, map[string]bool{}))
+
+				branch, pr, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					if kind := pkg.ErrorKindOf(err); len(tt.expectedErrorKind) != 0 && kind != tt.expectedErrorKind {
+						t.Errorf("expected error kind %q, got %q, error: %v", tt.expectedErrorKind, kind, err)
+					}
+					return
+				}
+				if branch != tt.expectedBranch {
+					t.Errorf("expected branch %q, got %q", tt.expectedBranch, branch)
+				}
+				if (pr != nil) != tt.expectedPullRequest {
+					t.Errorf("expected pull request %v, got %v", tt.expectedPullRequest, pr != nil)
+				}
+
+				// In dry-run mode nothing is written, but the writes are recorded in the plan.
+				if dryRunVal {
+					if steps := len(data.GetPlanSteps()); steps != tt.expectedSteps {
+						t.Errorf("expected %d plan steps, got %d", tt.expectedSteps, steps)
+					}
+					if len(commits) != 0 || len(prs) != 0 {
+						t.Errorf("expected no commits and pull requests in dry-run mode")
+					}
+					return
+				}
+				if files[path] != tt.expectedChangelog {
+					t.Errorf("expected changelog:\n%q\ngot:\n%q", tt.expectedChangelog, files[path])
+				}
+				if len(commits) != 0 && commits[0].GetBranch() != tt.expectedBranch {
+					t.Errorf("expected a commit to branch %q, got %q", tt.expectedBranch, commits[0].GetBranch())
+				}
+				if tt.expectedPullRequest && len(prs) != 1 {
+					t.Errorf("expected one pull request, got %d", len(prs))
+				}
+			})
+		}
+	}
+}
+
+func TestAddChangelogSection(t *testing.T) {
+	tests := []struct {
+		name      string
+		changelog string
+		expected  string
+	}{
+		{
+			name:     "valid: empty changelog",
+			expected: "# v1.18.1\n\n- Fix a bug.\n",
+		},
+		{
+			name:      "valid: changelog without release sections",
+			changelog: "Intro\n",
+			expected:  "Intro\n\n# v1.18.1\n\n- Fix a bug.\n",
+		},
+		{
+			name:      "valid: newer releases are listed first",
+			changelog: "# v1.18.0\n\n- Add a feature.\n",
+			expected:  "# v1.18.1\n\n- Fix a bug.\n\n# v1.18.0\n\n- Add a feature.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addChangelogSection(tt.changelog, "v1.18.1", "\n- Fix a bug.\n\n"); got != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest              []string        `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	PullRequestNumber int             `flag:"pull-request-number" usage:"Number of a merged pull request" validate:"required,positive"`
+	Branches          pkg.MultiString `flag:"branch" usage:"Branch to use in the format \"prefixMAJOR.MINOR\". Multiple instances of the flag are allowed" validate:"required,noempty"`
+	Labels            pkg.MultiString `flag:"label" usage:"Label to add to created pull requests. Multiple instances of the flag are allowed"`
+	Output            string          `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	return pkg.ValidateOptions(d, &options{})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// copiedLabelPrefixes are the prefixes of labels that are copied from the
+// cherry-picked pull request.
+var copiedLabelPrefixes = []string{"kind/", "sig/", "area/"}
+
+// cherryPick is a cherry-pick of a pull request on a target branch.
+type cherryPick struct {
+	Branch      string              `json:"branch"`
+	Head        *github.Reference   `json:"head"`
+	PullRequest *github.PullRequest `json:"pullRequest"`
+}
+
+// process is responsible for all operations that the application performs.
+// It returns the cherry-picks that were created. If an error occurs the
+// cherry-picks that were created before the error are returned.
+func process(d *pkg.Data) ([]*cherryPick, error) {
+	number := d.PullRequestNumber
+
+	// Obtain the pull request and its commits.
+	pr, err := pkg.GitHubGetPullRequest(d, d.DestRepo(), number)
+	if err != nil {
+		return nil, err
+	}
+	if !pr.GetMerged() {
+		return nil, errors.Errorf("pull request #%d in repository %q is not merged", number, d.DestRepo())
+	}
+	commits, err := pkg.GitHubGetPullRequestCommits(d, d.DestRepo(), number)
+	if err != nil {
+		return nil, err
+	}
+	shas := make([]string, 0, len(commits))
+	for _, c := range commits {
+		shas = append(shas, c.GetSHA())
+	}
+	pkg.Logf("found %d commit(s) in pull request #%d: %v", len(shas), number, shas)
+
+	// The target branches must exist and the cherry-pick branches must not exist.
+	branchesDest, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	pkg.LogRefList("existing branches", d.DestRepo(), branchesDest)
+	existing := map[string]bool{}
+	for _, b := range branchesDest {
+		existing[strings.TrimPrefix(b.GetRef(), "refs/heads/")] = true
+	}
+	for _, branch := range d.Branches {
+		if !existing[branch] {
+			return nil, errors.Errorf("could not find branch %q in repository %q", branch, d.DestRepo())
+		}
+		if head := cherryPickBranch(number, branch); existing[head] {
+			return nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the branch %q already exists in repository %q", head, d.DestRepo())
+		}
+	}
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to cherry-pick pull request #%d on branches %v of repository %q?",
+		number, []string(d.Branches), d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return nil, nil
+	}
+
+	labels := cherryPickLabels(pr, d.Labels)
+	picks := []*cherryPick{}
+	for _, branch := range d.Branches {
+		if d.Interrupted() {
+			return picks, pkg.ErrInterrupted
+		}
+
+		// Create the cherry-pick branch.
+		head := cherryPickBranch(number, branch)
+		ref, err := pkg.GitHubCherryPick(d, d.DestRepo(), branch, head, shas, d.DryRun)
+		if err != nil {
+			return picks, err
+		}
+		pick := &cherryPick{Branch: branch, Head: ref}
+		picks = append(picks, pick)
+
+		// Create the pull request.
+		title := fmt.Sprintf("Automated cherry pick of #%d: %s", number, pr.GetTitle())
+		body := fmt.Sprintf("Cherry pick of #%d on %s.\n\n#%d: %s\n\n%s", number, branch, number, pr.GetTitle(), pr.GetBody())
+		if pick.PullRequest, err = pkg.GitHubCreatePullRequest(d, d.DestRepo(), branch, head, title, body, labels, d.DryRun); err != nil {
+			return picks, err
+		}
+	}
+	return picks, nil
+}
+
+// cherryPickBranch returns the name of the branch for the cherry-pick of
+// a pull request on a target branch.
+func cherryPickBranch(number int, branch string) string {
+	return fmt.Sprintf("automated-cherry-pick-of-%d-%s", number, branch)
+}
+
+// cherryPickLabels returns the labels of a pull request that should be copied to its
+// cherry-picks, followed by the extra labels.
+func cherryPickLabels(pr *github.PullRequest, extra []string) []string {
+	labels := []string{}
+	seen := map[string]bool{}
+	add := func(l string) {
+		if !seen[l] {
+			seen[l] = true
+			labels = append(labels, l)
+		}
+	}
+	for _, l := range pr.Labels {
+		for _, prefix := range copiedLabelPrefixes {
+			if strings.HasPrefix(l.GetName(), prefix) {
+				add(l.GetName())
+				break
+			}
+		}
+	}
+	for _, l := range extra {
+		add(l)
+	}
+	return labels
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	const (
+		shaMaster = "1111111111111111111111111111111111111111"
+		sha117    = "2222222222222222222222222222222222222222"
+		sha116    = "3333333333333333333333333333333333333333"
+		shaParent = "4444444444444444444444444444444444444444"
+		shaPick   = "5555555555555555555555555555555555555555"
+	)
+	newRef := func(ref, sha string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+	}
+	newCommit := func(sha, tree string, parents ...string) *github.Commit {
+		c := &github.Commit{SHA: github.String(sha), Tree: &github.Tree{SHA: github.String(tree)}, Message: github.String("Fix a bug")}
+		for _, p := range parents {
+			c.Parents = append(c.Parents, github.Commit{SHA: github.String(p)})
+		}
+		return c
+	}
+	refsDest := []*github.Reference{
+		newRef("refs/heads/master", shaMaster),
+		newRef("refs/heads/release-1.17", sha117),
+		newRef("refs/heads/release-1.16", sha116),
+	}
+	merged := &github.PullRequest{
+		Number: github.Int(1234),
+		Title:  github.String("Fix a bug"),
+		Merged: github.Bool(true),
+		Labels: []*github.Label{
+			{Name: github.String("kind/bug")},
+			{Name: github.String("lgtm")},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		branches          []string
+		pr                *github.PullRequest
+		refs              []*github.Reference
+		mergeStatus       int
+		expectedHeads     map[string]string
+		expectedNoRefs    []string
+		expectedLabels    []string
+		expectedError     bool
+		expectedErrorKind pkg.ErrorKind
+		skipDryRun        bool
+		expectedPlanSteps int
+		expectedPickCount int
+	}{
+		{
+			name:              "valid: cherry-pick on two branches",
+			branches:          []string{"release-1.17", "release-1.16"},
+			pr:                merged,
+			refs:              refsDest,
+			expectedPickCount: 2,
+			expectedPlanSteps: 4,
+			expectedHeads: map[string]string{
+				"refs/heads/automated-cherry-pick-of-1234-release-1.17": "created-2",
+				"refs/heads/automated-cherry-pick-of-1234-release-1.16": "created-4",
+			},
+			expectedLabels: []string{"kind/bug", "cherry-pick", "kind/bug", "cherry-pick"},
+		},
+		{
+			name:     "invalid: the pull request is not merged",
+			branches: []string{"release-1.17"},
+			pr: &github.PullRequest{
+				Number: github.Int(1234),
+				Merged: github.Bool(false),
+			},
+			refs:          refsDest,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing target branch",
+			branches:      []string{"release-1.15"},
+			pr:            merged,
+			refs:          refsDest,
+			expectedError: true,
+		},
+		{
+			name:     "invalid: the cherry-pick branch already exists",
+			branches: []string{"release-1.17"},
+			pr:       merged,
+			refs: append(append([]*github.Reference{}, refsDest...),
+				newRef("refs/heads/automated-cherry-pick-of-1234-release-1.17", sha117)),
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name:              "invalid: merge conflict",
+			branches:          []string{"release-1.17"},
+			pr:                merged,
+			refs:              refsDest,
+			mergeStatus:       http.StatusConflict,
+			expectedNoRefs:    []string{"refs/heads/automated-cherry-pick-of-1234-release-1.17"},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+			skipDryRun:        true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := pkg.NewData()
+				data.Dest = []string{"org/dest"}
+				data.PullRequestNumber = 1234
+				data.Branches = tt.branches
+				data.Labels = []string{"cherry-pick"}
+				data.SetPrompter(pkg.PrompterYes)
+				data.DryRun = dryRunVal
+
+				// Copy the test data, since the handlers modify it.
+				refs := []*github.Reference{}
+				for _, r := range tt.refs {
+					refs = append(refs, newRef(r.GetRef(), r.GetObject().GetSHA()))
+				}
+				prs := []*github.PullRequest{tt.pr}
+				commits := map[string]*github.Commit{
+					sha117:        newCommit(sha117, "tree-1.17"),
+					sha116:        newCommit(sha116, "tree-1.16"),
+					shaPick:       newCommit(shaPick, "tree-pick", shaParent),
+					"dry-run-sha": newCommit("dry-run-sha", "tree-merge"),
+				}
+				prCommits := []*github.RepositoryCommit{{SHA: github.String(shaPick)}}
+				labels := []string{}
+
+				// Create fake client and setup endpoint handlers.
+				const api = "https://api.github.com/repos/org/dest/"
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler(api+"pulls", pkg.NewPullRequestHandler(&prs, map[string]bool{}))
+				data.Transport.SetHandler(api+"pulls/1234/commits", pkg.NewPullRequestCommitsHandler(prCommits, map[string]bool{}))
+				data.Transport.SetHandler(api+"git/refs", pkg.NewReferenceHandler(&refs, map[string]bool{}))
+				data.Transport.SetHandler(api+"git/commits", pkg.NewGitCommitHandler(commits, map[string]bool{}))
+				data.Transport.SetHandler(api+"merges", pkg.NewMergeHandler(&github.RepositoryMergeRequest{}, tt.mergeStatus, map[string]bool{}))
+				data.Transport.SetHandler(api+"issues", pkg.NewIssueLabelsHandler(&labels, map[string]bool{}))
+
+				picks, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				for _, r := range refs {
+					for _, noRef := range tt.expectedNoRefs {
+						if r.GetRef() == noRef {
+							t.Errorf("expected ref %q to be deleted", noRef)
+						}
+					}
+				}
+				if err != nil {
+					if kind := pkg.ErrorKindOf(err); len(tt.expectedErrorKind) != 0 && kind != tt.expectedErrorKind {
+						t.Errorf("expected error kind %q, got %q, error: %v", tt.expectedErrorKind, kind, err)
+					}
+					return
+				}
+				if len(picks) != tt.expectedPickCount {
+					t.Fatalf("expected %d cherry-picks, got %d", tt.expectedPickCount, len(picks))
+				}
+				for _, pick := range picks {
+					if pick.PullRequest.GetBase().GetRef() != pick.Branch {
+						t.Errorf("expected a pull request against %q, got %q", pick.Branch, pick.PullRequest.GetBase().GetRef())
+					}
+				}
+
+				// In dry-run mode nothing is written, but the writes are recorded in the plan.
+				if dryRunVal {
+					if steps := len(data.GetPlanSteps()); steps != tt.expectedPlanSteps {
+						t.Errorf("expected %d plan steps, got %d", tt.expectedPlanSteps, steps)
+					}
+					return
+				}
+				heads := map[string]string{}
+				for _, r := range refs {
+					if _, ok := tt.expectedHeads[r.GetRef()]; ok {
+						heads[r.GetRef()] = r.GetObject().GetSHA()
+					}
+				}
+				if !reflect.DeepEqual(heads, tt.expectedHeads) {
+					t.Errorf("expected heads:\n%v\ngot:\n%v", tt.expectedHeads, heads)
+				}
+				picked := commits["created-2"]
+				expectedMessage := "Fix a bug\n\n(cherry picked from commit " + shaPick + ")"
+				if picked.GetMessage() != expectedMessage || picked.GetTree().GetSHA() != "tree-merge" ||
+					len(picked.Parents) != 1 || picked.Parents[0].GetSHA() != sha117 {
+					t.Errorf("unexpected cherry-picked commit: %v", picked)
+				}
+				if !reflect.DeepEqual(labels, tt.expectedLabels) {
+					t.Errorf("expected labels %v, got %v", tt.expectedLabels, labels)
+				}
+			})
+		}
+	}
+}
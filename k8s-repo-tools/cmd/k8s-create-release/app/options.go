@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The GitHub token is not required for a
+// repository on a Gitea server.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	pkg.PullRequestNotesOptions
+	pkg.GiteaOptions
+	pkg.SlackOptions
+	Token                string        `validate:"requiredunless=gitea-url,token"`
+	Dest                 []string      `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	BuildCommand         string        `flag:"build-command" usage:"A command to execute for build the release assets"`
+	BuildWorkflow        string        `flag:"build-workflow" usage:"File name or ID of a GitHub Actions workflow in the destination repository that builds the release assets. The workflow is dispatched for the release tag instead of running \"build-command\" locally"`
+	BuildArtifactsDir    string        `flag:"build-artifacts-dir" usage:"Directory to which the artifacts of the \"build-workflow\" run are extracted" validate:"requiredif=build-workflow"`
+	BuildTimeout         time.Duration `flag:"build-timeout" default:"1h0m0s" usage:"Maximum time to wait for the \"build-workflow\" run to complete" validate:"requiredif=build-workflow,positive"`
+	ReleaseTag           string        `flag:"release-tag" usage:"A SemVer tag from which to create a release" validate:"required,semver"`
+	ReleaseNotesPath     string        `flag:"release-notes-path" usage:"Path to a text file containing release notes. Overrides the usage of \"release-notes-tool-path\""`
+	ReleaseNotesToolPath string        `flag:"release-notes-tool-path" usage:"Path to the release notes tool binary"`
+	ReleaseAssets        pkg.AssetMap  `flag:"release-asset" usage:"A release asset to upload to the GitHub release. Must be formatted as 'assetName=filePath'. Multiple instances of the flag are allowed"`
+	ChecksumManifest     string        `flag:"checksum-manifest" usage:"Name of a release asset to upload with a JSON manifest of the name, size, SHA256 checksum and signature of every asset passed with \"release-asset\""`
+	CloseMilestone       bool          `flag:"milestone" usage:"Link the milestone with the title of the release tag, such as 'v1.18.0', in the release body and close it if it has no open issues"`
+	OCIRepository        string        `flag:"oci-repository" usage:"Repository in an OCI registry (e.g. 'ghcr.io/org/kubeadm') to which the release assets and their checksums are pushed as an OCI artifact with the release tag" validate:"ocirepo"`
+	OCIUsername          string        `flag:"oci-username" usage:"Username for authentication with the registry of \"oci-repository\""`
+	OCIPassword          string        `flag:"oci-password" usage:"Password or token for authentication with the registry of \"oci-repository\""`
+	Output               string        `flag:"output" usage:"Path to a file that will be written with the release and its assets as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the release notes options.
+	if err := pkg.ValidatePullRequestNotesOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the remote build.
+	if len(d.BuildWorkflow) != 0 {
+		if len(d.BuildCommand) != 0 {
+			return errors.Errorf("--%s and --%s cannot be used together", pkg.FlagBuildCommand, pkg.FlagBuildWorkflow)
+		}
+		if len(d.GiteaURL) != 0 {
+			return errors.Errorf("--%s is not supported with --%s", pkg.FlagBuildWorkflow, pkg.FlagGiteaURL)
+		}
+	}
+
+	// Milestones are only managed on GitHub.
+	if d.CloseMilestone && len(d.GiteaURL) != 0 {
+		return errors.Errorf("--%s is not supported with --%s", pkg.FlagMilestone, pkg.FlagGiteaURL)
+	}
+
+	// The OCI artifact is made of the release assets.
+	if len(d.OCIRepository) != 0 && len(d.ReleaseAssets) == 0 {
+		return errors.Errorf("--%s requires at least one --%s", pkg.FlagOCIRepository, pkg.FlagReleaseAsset)
+	}
+
+	// Validate the checksum manifest.
+	if len(d.ChecksumManifest) != 0 {
+		if len(d.ReleaseAssets) == 0 {
+			return errors.Errorf("--%s requires at least one --%s", pkg.FlagChecksumManifest, pkg.FlagReleaseAsset)
+		}
+		if _, ok := d.ReleaseAssets[d.ChecksumManifest]; ok {
+			return errors.Errorf("--%s %q is also passed with --%s", pkg.FlagChecksumManifest, d.ChecksumManifest, pkg.FlagReleaseAsset)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// process is responsible for all operations that the application performs.
+// It creates the release in the destination repository dest and returns
+// the release and its assets.
+func process(d *pkg.Data, dest string) (*github.RepositoryRelease, []*github.ReleaseAsset, error) {
+
+	// Handle release notes.
+	// If a direct release notes path is given read them from the file.
+	// If a path to a release notes tools is given use it to generate the release notes.
+	bodyStr, err := pkg.GenerateReleaseNotes(d, dest, d.ReleaseTag)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bodyStr) != 0 {
+		// Format the release in a <details> tag.
+		bodyStr = "<details><summary>Release notes</summary>\n\n" + bodyStr + "\n\n</details>"
+	}
+
+	// Link the milestone of the release tag in the release body.
+	var milestone *github.Milestone
+	if d.CloseMilestone {
+		if milestone, err = pkg.GitHubGetReleaseMilestone(d, dest, d.ReleaseTag); err != nil {
+			return nil, nil, err
+		}
+		if milestone != nil {
+			bodyStr = appendMilestoneLink(bodyStr, milestone)
+		} else {
+			pkg.Warningf("the repository %q does not have a milestone for tag %q; skipping the milestone", dest, d.ReleaseTag)
+		}
+	}
+
+	var promptMessage string
+	var yes bool
+	var assets []*github.ReleaseAsset
+
+	// Prompt the user about creating a release.
+	promptMessage = fmt.Sprintf("Do you want to create a release for tag %q if it does not exist already?",
+		d.ReleaseTag)
+	if yes, err = d.Prompter().Confirm(promptMessage); err != nil {
+		return nil, nil, err
+	} else if yes {
+		goto createRelease
+	}
+	return nil, nil, nil
+
+createRelease:
+
+	// Create a release for this tag.
+	// Note: bodyStr can be empty if the release notes process was skipped.
+	release, err := d.Provider().GetCreateRelease(dest, d.ReleaseTag, bodyStr, d.DryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Close the milestone of the release.
+	if milestone != nil {
+		if err := closeMilestone(d, dest, milestone); err != nil {
+			return release, nil, err
+		}
+	}
+
+	// Build the release with a remote workflow or with a local build command.
+	if len(d.BuildWorkflow) != 0 {
+		if _, err := pkg.RunRemoteBuild(d, dest, d.BuildWorkflow, d.ReleaseTag, d.BuildArtifactsDir, d.BuildTimeout, d.DryRun); err != nil {
+			return release, nil, err
+		}
+	} else if len(d.BuildCommand) != 0 {
+		buildCommmandSplit := strings.Split(d.BuildCommand, " ")
+		var args []string
+		if len(buildCommmandSplit) > 1 {
+			args = buildCommmandSplit[1:]
+		}
+		if err := pkg.RunCommand(buildCommmandSplit[0], []string{}, d.DryRun, args...); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		pkg.Warningf("empty --%s and --%s values; skipping build", pkg.FlagBuildCommand, pkg.FlagBuildWorkflow)
+	}
+
+	// Prompt the user about uploading the assets.
+	promptMessage = fmt.Sprintf("Do you want to upload the given assets to release %q?",
+		d.ReleaseTag)
+	if yes, err = d.Prompter().Confirm(promptMessage); err != nil {
+		return nil, nil, err
+	} else if yes {
+		goto uploadAssets
+	}
+	return release, nil, nil
+
+uploadAssets:
+
+	// Add a checksum manifest of the built release assets.
+	releaseAssets := d.ReleaseAssets
+	if len(d.ChecksumManifest) != 0 {
+		path, err := writeChecksumManifest(d)
+		if err != nil {
+			return release, nil, err
+		}
+		if len(path) != 0 {
+			defer os.Remove(path)
+			releaseAssets = d.ReleaseAssets.With(d.ChecksumManifest, path)
+		}
+	}
+
+	// Upload the release assets if such are provided.
+	if len(releaseAssets) > 0 {
+		if assets, err = d.Provider().UploadReleaseAssets(dest, release, releaseAssets, d.DryRun); err != nil {
+			return release, assets, err
+		}
+	} else {
+		pkg.Warningf("no release assets were provided using --%s; skipping upload", pkg.FlagReleaseAsset)
+	}
+
+	// Push the release assets as an OCI artifact.
+	if len(d.OCIRepository) != 0 {
+		if _, err := pkg.PushOCIArtifact(d, d.OCIRepository, d.ReleaseTag, release.GetHTMLURL(), releaseAssets, d.DryRun); err != nil {
+			return release, assets, err
+		}
+	}
+	return release, assets, nil
+}
+
+// appendMilestoneLink appends a link to the milestone m to the release body.
+func appendMilestoneLink(body string, m *github.Milestone) string {
+	link := fmt.Sprintf("Milestone: [%s](%s)", m.GetTitle(), m.GetHTMLURL())
+	if len(body) == 0 {
+		return link
+	}
+	return body + "\n\n" + link
+}
+
+// closeMilestone closes the milestone m of the repository dest if it has no
+// open issues. A milestone with open issues is not closed and only logs a warning.
+func closeMilestone(d *pkg.Data, dest string, m *github.Milestone) error {
+	if m.GetState() == "closed" {
+		pkg.Logf("the milestone %q is already closed", m.GetTitle())
+		return nil
+	}
+	if m.GetOpenIssues() != 0 {
+		pkg.Warningf("the milestone %q has %d open issue(s) and pull request(s); not closing it", m.GetTitle(), m.GetOpenIssues())
+		return nil
+	}
+	closed := &github.Milestone{
+		Title:       m.Title,
+		Description: m.Description,
+		DueOn:       m.DueOn,
+		State:       github.String("closed"),
+	}
+	if err := pkg.GitHubEditMilestone(d, dest, m.GetNumber(), closed, d.DryRun); err != nil {
+		return err
+	}
+	pkg.Noticef("closed milestone %q in repository %q", m.GetTitle(), dest)
+	return nil
+}
+
+// writeChecksumManifest generates a checksum manifest of d.ReleaseAssets and writes it
+// to a temporary file. The path of the file is returned. In DRY-RUN mode the assets
+// might not be built, so the manifest is not generated and the path is empty.
+func writeChecksumManifest(d *pkg.Data) (string, error) {
+	if d.DryRun {
+		pkg.Logf("%s: would upload a checksum manifest of %d asset(s) as %q", pkg.PrefixDryRun, len(d.ReleaseAssets), d.ChecksumManifest)
+		return "", nil
+	}
+	pkg.Logf("generating the checksum manifest %q", d.ChecksumManifest)
+	manifest, err := pkg.NewChecksumManifest(d.ReleaseAssets)
+	if err != nil {
+		return "", err
+	}
+	buf, err := manifest.Marshal()
+	if err != nil {
+		return "", err
+	}
+	file, err := ioutil.TempFile("", "checksum-manifest")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := file.Write(buf); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	const (
+		tag  = "v1.18.0"
+		link = "Milestone: [v1.18.0](https://github.com/org/dest/milestone/1)"
+	)
+	newMilestone := func(openIssues int) *github.Milestone {
+		return &github.Milestone{
+			Number:     github.Int(1),
+			Title:      github.String(tag),
+			State:      github.String("open"),
+			OpenIssues: github.Int(openIssues),
+			HTMLURL:    github.String("https://github.com/org/dest/milestone/1"),
+		}
+	}
+
+	tests := []struct {
+		name              string
+		milestones        []*github.Milestone
+		dryRun            bool
+		expectedBody      string
+		expectedState     string
+		expectedPlanSteps int
+	}{
+		{
+			name:          "valid: the milestone is linked and closed",
+			milestones:    []*github.Milestone{newMilestone(0)},
+			expectedBody:  link,
+			expectedState: "closed",
+		},
+		{
+			name:          "valid: a milestone with open issues is linked, but not closed",
+			milestones:    []*github.Milestone{newMilestone(2)},
+			expectedBody:  link,
+			expectedState: "open",
+		},
+		{
+			name:       "valid: there is no milestone for the tag",
+			milestones: []*github.Milestone{},
+		},
+		{
+			name:              "valid: the milestone is not closed in dry-run mode",
+			milestones:        []*github.Milestone{newMilestone(0)},
+			dryRun:            true,
+			expectedBody:      link,
+			expectedState:     "open",
+			expectedPlanSteps: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := pkg.NewData()
+			data.Dest = []string{"org/dest"}
+			data.ReleaseTag = tag
+			data.CloseMilestone = true
+			data.SetPrompter(pkg.PrompterYes)
+			data.DryRun = tt.dryRun
+
+			// Copy the test data, since the handlers modify it.
+			milestones := []*github.Milestone{}
+			for _, m := range tt.milestones {
+				copied := *m
+				milestones = append(milestones, &copied)
+			}
+			refs := []*github.Reference{
+				{Ref: github.String("refs/tags/" + tag), Object: &github.GitObject{SHA: github.String("1111")}},
+			}
+			releases := []*github.RepositoryRelease{}
+
+			// Create fake client and setup endpoint handlers.
+			const api = "https://api.github.com/repos/org/dest/"
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler(api+"git/refs", pkg.NewReferenceHandler(&refs, map[string]bool{}))
+			data.Transport.SetHandler(api+"releases", pkg.NewReleaseHandler(&releases, map[string]bool{}))
+			data.Transport.SetHandler(api+"milestones", pkg.NewMilestoneHandler(&milestones, map[string]bool{}))
+
+			release, _, err := process(data, data.DestRepo())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if release.GetBody() != tt.expectedBody {
+				t.Errorf("expected release body %q, got %q", tt.expectedBody, release.GetBody())
+			}
+			if len(milestones) != 0 {
+				if state := milestones[0].GetState(); state != tt.expectedState {
+					t.Errorf("expected milestone state %q, got %q", tt.expectedState, state)
+				}
+			}
+			if steps := len(data.GetPlanSteps()); steps != tt.expectedPlanSteps {
+				t.Errorf("expected %d plan steps, got %d", tt.expectedPlanSteps, steps)
+			}
+		})
+	}
+}
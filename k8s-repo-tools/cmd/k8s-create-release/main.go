@@ -17,67 +17,13 @@ limitations under the License.
 package main
 
 import (
-	"flag"
-	"fmt"
 	"os"
-	"strings"
 
+	"k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-create-release/app"
 	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
-func printUsage() {
-	out := os.Stderr
-	fmt.Fprintln(out, "k8s-create-release is a tool for creating a GitHub release "+
-		"from a tag with a changelog and assets")
-	fmt.Fprintln(out, "\nusage:")
-	fmt.Fprintf(out, "  k8s-create-release -dest=org/repo -token=<token> -release-tag=<tag> <options>\n\n")
-	flag.CommandLine.PrintDefaults()
-}
-
 func main() {
-	// Set the default output writers.
-	pkg.SetLogWriters(os.Stdout, os.Stderr)
-
-	// Initialize the main data structure.
-	d := pkg.NewData()
-
-	// Manage flags and source.
-	flag.Usage = printUsage
-	flag.CommandLine.SetOutput(os.Stderr)
-	flagList := []string{
-		pkg.FlagDest,
-		pkg.FlagToken,
-		pkg.FlagTimeout,
-		pkg.FlagDryRun,
-		pkg.FlagForce,
-		pkg.FlagBuildCommand,
-		pkg.FlagReleaseTag,
-		pkg.FlagReleaseNotesPath,
-		pkg.FlagReleaseNotesToolPath,
-		pkg.FlagReleaseAsset,
-	}
-	pkg.SetupFlags(d, flag.CommandLine, flagList, nil)
-	flag.Parse()
-
-	// Trim 'refs/tags/' from the ReleaseTag.
-	d.ReleaseTag = strings.TrimPrefix(d.ReleaseTag, "refs/tags/")
-
-	// Validate the user parameters.
-	if err := validateData(d); err != nil {
-		pkg.PrintErrorAndExit(err)
-	}
-
-	// Print a warning in dry-run mode.
-	if d.DryRun {
-		pkg.PrintSeparator()
-		pkg.Warningf("running in %s mode. To enable repository writing operations pass --%s=false", pkg.PrefixDryRun, pkg.FlagDryRun)
-		pkg.PrintSeparator()
-	}
-
-	pkg.NewClient(d, nil)
-	err := process(d)
-	if err != nil {
-		pkg.PrintErrorAndExit(err)
-	}
-	pkg.Logf("done!")
+	app.Main(os.Args[1:])
+	pkg.FinishRun(0, nil)
 }
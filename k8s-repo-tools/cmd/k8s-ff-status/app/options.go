@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Dest         []string `flag:"dest" usage:"Comma separated list of org/repo for which to report the status of the release branches" validate:"required,repos"`
+	PrefixBranch string   `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	MinVersion   string   `flag:"min-version" usage:"All versions for tags and branches older than this SemVer will be ignored" validate:"semver"`
+	OutputFormat string   `flag:"output-format" default:"text" usage:"Format of the output. One of \"text\" or \"json\"" validate:"oneof=text|json"`
+	Parallelism  int      `flag:"parallelism" default:"1" usage:"Number of repositories to process in parallel. With a value greater than 1 a failing repository does not stop the others" validate:"nonnegative"`
+	Strict       bool     `flag:"strict" usage:"Fail instead of continuing with a warning when refs that are not valid versions are found or a repository has been renamed"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	return pkg.ValidateOptions(d, &options{})
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// formatOutput formats the status of the branches in the output format from d.OutputFormat.
+// The text format is a table, while the JSON format is an array of branch status objects.
+func formatOutput(statuses []*branchStatus, d *pkg.Data) ([]byte, error) {
+	if d.OutputFormat == pkg.OutputFormatJSON {
+		return json.MarshalIndent(statuses, "", "\t")
+	}
+
+	var b bytes.Buffer
+	tabW := tabwriter.NewWriter(&b, 12, 0, 2, ' ', 0)
+	fmt.Fprintln(tabW, "REPO\tBRANCH\tLATEST TAG\tWINDOW\tBEHIND MASTER")
+	for _, s := range statuses {
+		tag := s.LatestTag
+		if len(tag) == 0 {
+			tag = "-"
+		}
+		fmt.Fprintf(tabW, "%s\t%s\t%s\t%s\t%d\n", s.Repo, s.Branch, tag, s.Window, s.CommitsBehind)
+	}
+	tabW.Flush()
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestFormatOutput(t *testing.T) {
+	statuses := []*branchStatus{
+		{
+			Repo: "org/a", Branch: "refs/heads/release-1.18", LatestTag: "refs/tags/v1.18.0-beta.1",
+			Window: windowOpen, WindowMin: "1.18.0-beta.0", WindowMax: "1.18.0-rc.1", CommitsBehind: 2,
+			PullRequests: []int{123, 456},
+		},
+		{
+			Repo: "org/b", Branch: "refs/heads/release-1.18",
+			Window: windowClosed, WindowMin: "1.18.0-beta.0", WindowMax: "1.18.0-rc.1",
+		},
+	}
+
+	tests := []struct {
+		name           string
+		outputFormat   string
+		statuses       []*branchStatus
+		expectedOutput string
+	}{
+		{
+			name:     "valid: text table",
+			statuses: statuses,
+			expectedOutput: "REPO        BRANCH                   LATEST TAG                WINDOW      BEHIND MASTER\n" +
+				"org/a       refs/heads/release-1.18  refs/tags/v1.18.0-beta.1  open        2\n" +
+				"org/b       refs/heads/release-1.18  -                         closed      0",
+		},
+		{
+			name:         "valid: JSON",
+			outputFormat: pkg.OutputFormatJSON,
+			statuses:     statuses[1:],
+			expectedOutput: `[
+	{
+		"repo": "org/b",
+		"branch": "refs/heads/release-1.18",
+		"latestTag": "",
+		"window": "closed",
+		"windowMin": "1.18.0-beta.0",
+		"windowMax": "1.18.0-rc.1",
+		"commitsBehind": 0
+	}
+]`,
+		},
+		{
+			name:         "valid: JSON with pull requests",
+			outputFormat: pkg.OutputFormatJSON,
+			statuses:     statuses[:1],
+			expectedOutput: `[
+	{
+		"repo": "org/a",
+		"branch": "refs/heads/release-1.18",
+		"latestTag": "refs/tags/v1.18.0-beta.1",
+		"window": "open",
+		"windowMin": "1.18.0-beta.0",
+		"windowMax": "1.18.0-rc.1",
+		"commitsBehind": 2,
+		"pullRequests": [
+			123,
+			456
+		]
+	}
+]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := formatOutput(tt.statuses, &pkg.Data{OutputFormat: tt.outputFormat})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != tt.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expectedOutput, buf)
+			}
+		})
+	}
+}
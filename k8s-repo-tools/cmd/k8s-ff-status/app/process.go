@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	windowOpen   = "open"
+	windowClosed = "closed"
+)
+
+// branchStatus is the fast-forward state of a release branch.
+type branchStatus struct {
+	Repo          string `json:"repo"`
+	Branch        string `json:"branch"`
+	LatestTag     string `json:"latestTag"`
+	Window        string `json:"window"`
+	WindowMin     string `json:"windowMin"`
+	WindowMax     string `json:"windowMax"`
+	CommitsBehind int    `json:"commitsBehind"`
+	// PullRequests are the numbers of the pull requests that were merged
+	// in master but are missing in the branch.
+	PullRequests []int `json:"pullRequests,omitempty"`
+}
+
+// process is responsible for all operations that the application performs.
+// It returns the status of the release branches of all repositories in d.Dest.
+// No writes are performed.
+func process(d *pkg.Data) ([]*branchStatus, error) {
+	minV := version.MustParseSemantic("v0.0.0")
+	if len(d.MinVersion) != 0 {
+		minV = version.MustParseSemantic(d.MinVersion)
+	}
+
+	repos := d.Dest
+	repoStatuses := make([][]*branchStatus, len(repos))
+	err := pkg.ForEachRepo(d, repos, func(i int, repo string, log *pkg.Logger) error {
+		var err error
+		repoStatuses[i], err = repoStatus(d, repo, minV, log)
+		return err
+	})
+	statuses := []*branchStatus{}
+	for _, s := range repoStatuses {
+		statuses = append(statuses, s...)
+	}
+	return statuses, err
+}
+
+// repoStatus returns the status of the release branches of a repository
+// that are not older than minV, starting from the latest branch.
+func repoStatus(d *pkg.Data, repo string, minV *version.Version, log *pkg.Logger) ([]*branchStatus, error) {
+	tags, err := pkg.GitHubGetTags(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := pkg.GitHubGetBranches(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	pkg.LogRefList("existing tags", repo, tags)
+	pkg.LogRefList("existing branches", repo, branches)
+
+	type versionRef struct {
+		v   *version.Version
+		ref *github.Reference
+	}
+	releaseBranches := []versionRef{}
+	// In strict mode fail on tags and branches that are not versioned.
+	if d.Strict {
+		if _, err := pkg.TrimTags(tags, minV, true); err != nil {
+			return nil, err
+		}
+	}
+	trimmedBranches, err := pkg.TrimBranches(branches, minV, d.PrefixBranch, d.Strict)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range trimmedBranches {
+		v, _ := pkg.BranchRefToVersion(ref, d.PrefixBranch)
+		releaseBranches = append(releaseBranches, versionRef{v: v, ref: ref})
+	}
+	sort.Slice(releaseBranches, func(i, j int) bool {
+		return releaseBranches[j].v.LessThan(releaseBranches[i].v)
+	})
+	log.Logf("found %d release branch(es) in repository %q", len(releaseBranches), repo)
+
+	result := []*branchStatus{}
+	for _, b := range releaseBranches {
+		if d.Interrupted() {
+			return result, pkg.ErrInterrupted
+		}
+		min, max := pkg.FastForwardWindow(b.v)
+		s := &branchStatus{
+			Repo:      repo,
+			Branch:    b.ref.GetRef(),
+			Window:    windowClosed,
+			WindowMin: min.String(),
+			WindowMax: max.String(),
+		}
+
+		// A branch without tags cannot be in the window.
+		if tag, err := pkg.FindLatestTag(tags, b.v, false); err == nil {
+			s.LatestTag = tag.GetRef()
+			if tagV, err := pkg.TagRefToVersion(tag); err == nil && pkg.InFastForwardWindow(tagV, b.v) {
+				s.Window = windowOpen
+			}
+		} else {
+			log.V(1).Logf("%v", err)
+		}
+
+		// The commits that master is ahead of the branch are the commits the branch is behind.
+		cmp, err := pkg.GitHubCompareBranches(d, repo, b.ref.GetRef(), pkg.BranchMaster)
+		if err != nil {
+			return result, err
+		}
+		s.CommitsBehind = cmp.GetAheadBy()
+		s.PullRequests = pkg.PullRequestNumbers(pkg.RangeCommitsFromComparison(cmp))
+		result = append(result, s)
+	}
+	return result, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	newRef := func(ref string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String("sha")}}
+	}
+	refsA := []*github.Reference{
+		newRef("refs/heads/master"),
+		newRef("refs/heads/release-1.16"),
+		newRef("refs/heads/release-1.17"),
+		newRef("refs/heads/release-1.18"),
+		newRef("refs/tags/v1.16.3"),
+		newRef("refs/tags/v1.17.0-rc.1"),
+		newRef("refs/tags/v1.18.0-beta.1"),
+	}
+	refsB := []*github.Reference{
+		newRef("refs/heads/master"),
+		newRef("refs/heads/release-1.18"),
+	}
+	commit := func(sha string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha)}
+	}
+	commitsBranch := []*github.RepositoryCommit{commit("1")}
+	commitsMaster := []*github.RepositoryCommit{commit("1"), commit("2"), commit("3")}
+
+	tests := []struct {
+		name             string
+		dest             []string
+		minVersion       string
+		methodErrors     map[string]bool
+		expectedStatuses []*branchStatus
+		expectedError    bool
+	}{
+		{
+			name:       "valid: multiple repositories",
+			dest:       []string{"org/a", "org/b"},
+			minVersion: "v1.17.0",
+			expectedStatuses: []*branchStatus{
+				{
+					Repo: "org/a", Branch: "refs/heads/release-1.18", LatestTag: "refs/tags/v1.18.0-beta.1",
+					Window: windowOpen, WindowMin: "1.18.0-beta.0", WindowMax: "1.18.0-rc.1", CommitsBehind: 3,
+				},
+				{
+					Repo: "org/a", Branch: "refs/heads/release-1.17", LatestTag: "refs/tags/v1.17.0-rc.1",
+					Window: windowClosed, WindowMin: "1.17.0-beta.0", WindowMax: "1.17.0-rc.1", CommitsBehind: 0,
+				},
+				{
+					Repo: "org/b", Branch: "refs/heads/release-1.18", LatestTag: "",
+					Window: windowClosed, WindowMin: "1.18.0-beta.0", WindowMax: "1.18.0-rc.1", CommitsBehind: 3,
+				},
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the refs",
+			dest:          []string{"org/a"},
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Dest:         tt.dest,
+				MinVersion:   tt.minVersion,
+				PrefixBranch: pkg.PrefixBranch,
+			}
+
+			// Create fake client and setup endpoint handlers.
+			// The release-1.17 branch of org/a is identical to master. The fake comparison
+			// of the other branches returns all commits of master starting from the branch HEAD.
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/git/refs",
+				pkg.NewReferenceHandler(&refsA, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/b/git/refs",
+				pkg.NewReferenceHandler(&refsB, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/compare",
+				pkg.NewCompareHandler(&commitsMaster, &commitsBranch, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/compare/refs/heads/release-1.17",
+				pkg.NewCompareHandler(&commitsMaster, &commitsMaster, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/b/compare",
+				pkg.NewCompareHandler(&commitsMaster, &commitsBranch, map[string]bool{}))
+
+			statuses, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(statuses, tt.expectedStatuses) {
+				t.Errorf("expected statuses:\n%+v\ngot:\n%+v", tt.expectedStatuses, statuses)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// comparisonConfig is the structure of the YAML file passed with
+// the --comparison-config flag.
+type comparisonConfig struct {
+	Comparisons []comparison `json:"comparisons"`
+}
+
+// comparison is a single pair of gomod files to compare.
+type comparison struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source"`
+	Dest        string   `json:"dest"`
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
+}
+
+// parseComparisonConfig parses and validates the contents of a comparison config.
+func parseComparisonConfig(data []byte) (*comparisonConfig, error) {
+	cfg := &comparisonConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, errors.Wrap(err, "cannot parse comparison config")
+	}
+	if len(cfg.Comparisons) == 0 {
+		return nil, errors.New("the comparison config must contain at least one comparison")
+	}
+	for i, c := range cfg.Comparisons {
+		if len(c.Source) == 0 || len(c.Dest) == 0 {
+			return nil, errors.Errorf("comparison %d in the comparison config must have both 'source' and 'dest' set", i)
+		}
+		if len(c.Name) == 0 {
+			cfg.Comparisons[i].Name = c.Source + " -> " + c.Dest
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseComparisonConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           []byte
+		expectedConfig *comparisonConfig
+		expectedError  bool
+	}{
+		{
+			name: "valid: multiple comparisons",
+			data: []byte(`
+comparisons:
+- name: kubeadm vs kubernetes
+  source: https://foo/go.mod
+  dest: https://bar/go.mod
+  ignorePaths:
+  - k8s.io/klog
+- source: ./go.mod
+  dest: https://baz/go.mod
+`),
+			expectedConfig: &comparisonConfig{
+				Comparisons: []comparison{
+					{
+						Name:        "kubeadm vs kubernetes",
+						Source:      "https://foo/go.mod",
+						Dest:        "https://bar/go.mod",
+						IgnorePaths: []string{"k8s.io/klog"},
+					},
+					{
+						Name:   "./go.mod -> https://baz/go.mod",
+						Source: "./go.mod",
+						Dest:   "https://baz/go.mod",
+					},
+				},
+			},
+		},
+		{
+			name:          "invalid: no comparisons",
+			data:          []byte(`comparisons: []`),
+			expectedError: true,
+		},
+		{
+			name: "invalid: missing destination",
+			data: []byte(`
+comparisons:
+- source: ./go.mod
+`),
+			expectedError: true,
+		},
+		{
+			name: "invalid: unknown field",
+			data: []byte(`
+comparisons:
+- source: ./go.mod
+  dest: ./go.mod
+  foo: bar
+`),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseComparisonConfig(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cfg, tt.expectedConfig) {
+				t.Errorf("expected config:\n%+v\ngot:\n%+v\n", tt.expectedConfig, cfg)
+			}
+		})
+	}
+}
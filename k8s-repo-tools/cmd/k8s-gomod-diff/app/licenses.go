@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// licenseQueryFunc returns the licenses of a module version.
+type licenseQueryFunc func(path, version string) ([]string, error)
+
+// newDepsDevQueryFunc returns a licenseQueryFunc that uses the deps.dev API.
+// The requests are sent with the custom transport of d, if it is set.
+func newDepsDevQueryFunc(d *pkg.Data) licenseQueryFunc {
+	client := pkg.NewHTTPClient(d.Timeout)
+	if d.Transport != nil {
+		client.Transport = d.Transport
+	}
+	return func(path, version string) ([]string, error) {
+		return pkg.DepsDevGetLicenses(client, pkg.DepsDevURL, path, version)
+	}
+}
+
+// checkLicenses queries the licenses for both versions of every dependency
+// that differs between the source and the destination. A warning is printed
+// for every dependency whose license changed.
+func checkLicenses(o *output, query licenseQueryFunc) error {
+	// Sort the keys to query in a deterministic order.
+	keys := make([]string, 0, len(o.Dependencies))
+	for k := range o.Dependencies {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := o.Dependencies[k]
+		// Go directives are not modules and dependencies missing in dest are not compared.
+		if isGoDirective(k) || v.Dest == "" || v.Source == v.Dest {
+			continue
+		}
+		var err error
+		if v.SourceLicenses, err = query(k, v.Source); err != nil {
+			return err
+		}
+		if v.DestLicenses, err = query(k, v.Dest); err != nil {
+			return err
+		}
+		if licenseChanged(v) {
+			pkg.Warningf("the license of %q changed from %v to %v", k, v.SourceLicenses, v.DestLicenses)
+		}
+	}
+	o.LicensesChecked = true
+	return nil
+}
+
+// licenseChanged returns true if the source and destination licenses differ.
+func licenseChanged(v *versionTuple) bool {
+	if len(v.SourceLicenses) == 0 && len(v.DestLicenses) == 0 {
+		return false
+	}
+	return !reflect.DeepEqual(v.SourceLicenses, v.DestLicenses)
+}
+
+// formatLicenses formats the licenses of a versionTuple for a table cell.
+func formatLicenses(path string, v *versionTuple) string {
+	if isGoDirective(path) {
+		return "-"
+	}
+	source := formatLicenseList(v.SourceLicenses)
+	if !licenseChanged(v) {
+		return source
+	}
+	return "CHANGED: " + source + " -> " + formatLicenseList(v.DestLicenses)
+}
+
+func formatLicenseList(licenses []string) string {
+	if len(licenses) == 0 {
+		return "unknown"
+	}
+	return strings.Join(licenses, ",")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestCheckLicenses(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	knownLicenses := map[string][]string{
+		"k8s.io/klog@v1.0.0":      {"Apache-2.0"},
+		"k8s.io/klog@v1.1.0":      {"Apache-2.0"},
+		"github.com/foo/bar@v1.0": {"MIT"},
+		"github.com/foo/bar@v2.0": {"GPL-3.0"},
+	}
+
+	o := &output{
+		Dependencies: pathVersionTuple{
+			"Golang":             &versionTuple{Source: "1.12", Dest: "1.13"},
+			"k8s.io/klog":        &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+			"github.com/foo/bar": &versionTuple{Source: "v1.0", Dest: "v2.0"},
+			"github.com/foo/baz": &versionTuple{Source: "v1.0", Dest: "v2.0"},
+		},
+	}
+	query := func(path, version string) ([]string, error) {
+		return knownLicenses[path+"@"+version], nil
+	}
+	if err := checkLicenses(o, query); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOutput := `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH                SOURCE      DEST        LICENSES
+Golang              1.12        1.13        -
+github.com/foo/bar  v1.0        v2.0        CHANGED: MIT -> GPL-3.0
+github.com/foo/baz  v1.0        v2.0        unknown
+k8s.io/klog         v1.0.0      v1.1.0      Apache-2.0
+`
+	var b bytes.Buffer
+	formatOutput(&b, o, "https://foo", "https://bar")
+	if b.String() != expectedOutput {
+		t.Errorf("expected output:\n%s\ngot:\n%s\n", expectedOutput, b.String())
+	}
+
+	// Query errors are returned.
+	query = func(path, version string) ([]string, error) {
+		return nil, errors.New("simulated error")
+	}
+	if err := checkLicenses(o, query); err == nil {
+		t.Errorf("expected an error")
+	}
+}
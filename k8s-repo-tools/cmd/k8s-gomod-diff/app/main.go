@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-gomod-diff"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-gomod-diff is a tool for comparing gomod files "+
+		"and optionally printing results in GitHub issues")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-gomod-diff -dest=some-url-or-file -source=some-url-or-file -token=<token> <options>\n")
+	fmt.Fprintf(out, "  k8s-gomod-diff -comparison-config=some-url-or-file -token=<token> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// newFlagSet returns the FlagSet of the tool with the flags that set the fields of d.
+func newFlagSet(d *pkg.Data, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(Name, errorHandling)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(d, fs, &options{})
+	return fs
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.NewData()
+
+	// Manage flags and source.
+	fs := newFlagSet(d, flag.ExitOnError)
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+	pkg.ConfigureURLCache(d.CacheDir)
+	pkg.ConfigureURLToken(d.Token)
+
+	// Apply a plan that was written in DRY-RUN mode.
+	if len(d.ApplyPlanFile) != 0 {
+		pkg.RunApplyPlan(d, Name)
+		return
+	}
+
+	// Validate the user parameters.
+	if err := validateData(d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Load the list of comparisons.
+	comparisons, err := loadComparisons(d)
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(d, nil)
+
+	// Verify the token before writing. Only a comment is written to the target issue,
+	// for which push access is not required.
+	if d.Preflight && !d.DryRun && len(d.TargetIssue) != 0 {
+		if err := pkg.GitHubPreflightToken(d); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	results := process(d, comparisons)
+
+	// Format the results. A single comparison from flags preserves the
+	// classic output that only includes differences.
+	format := formatOutput
+	if d.GroupByNamespace {
+		format = formatGroupedOutput
+	}
+	var b bytes.Buffer
+	if len(d.ComparisonConfig) == 0 {
+		if len(results[0].Error) != 0 {
+			pkg.PrintErrorAndExit(errors.New(results[0].Error))
+		}
+		format(&b, results[0].Output, d.SourceRepo(), d.DestRepo())
+	} else {
+		formatReport(&b, results, format)
+	}
+
+	pkg.Logf("done!")
+
+	if len(d.TargetIssue) > 0 && b.Len() == 0 {
+		pkg.Logf("no differences found; skipping comment in issue %q", d.TargetIssue)
+	} else if len(d.TargetIssue) > 0 {
+		body := "```\n" + b.String() + "```\n"
+		if _, err := pkg.GitHubCreateIssueComment(d, d.TargetIssue, body, d.DryRun); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	} else {
+		os.Stdout.Write(b.Bytes())
+	}
+
+	// Write the plan in DRY-RUN mode.
+	if d.DryRun && len(d.PlanFile) != 0 {
+		if err := pkg.WritePlanToFile(d.PlanFile, Name, d.GetPlanSteps()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+
+	// Signal that some of the comparisons failed.
+	for _, r := range results {
+		if len(r.Error) != 0 {
+			pkg.FinishRun(1, nil)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadComparisons returns the list of comparisons from the comparison config
+// or a single comparison from the source and destination flags.
+func loadComparisons(d *pkg.Data) ([]comparison, error) {
+	if len(d.ComparisonConfig) == 0 {
+		return []comparison{{
+			Name:        d.SourceRepo() + " -> " + d.DestRepo(),
+			Source:      d.SourceRepo(),
+			Dest:        d.DestRepo(),
+			IgnorePaths: d.IgnorePaths,
+		}}, nil
+	}
+	data, err := pkg.ReadFromFileOrURL(d.ComparisonConfig, d.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read comparison config")
+	}
+	cfg, err := parseComparisonConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	// Paths ignored from the command line apply to all comparisons.
+	for i := range cfg.Comparisons {
+		cfg.Comparisons[i].IgnorePaths = append(cfg.Comparisons[i].IgnorePaths, d.IgnorePaths...)
+	}
+	return cfg.Comparisons, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// newJSONResponse returns a response with the JSON encoding of v.
+func newJSONResponse(v interface{}) (*http.Response, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+		Header:     http.Header{},
+	}, nil
+}
+
+func TestMainFlags(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-gomod-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "source.mod")
+	dest := filepath.Join(dir, "dest.mod")
+	for path, version := range map[string]string{source: "v1.0.0", dest: "v1.1.0"} {
+		if err := ioutil.WriteFile(path, []byte("module foo\n\ngo 1.13\n\nrequire k8s.io/klog "+version+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		args                 []string
+		expectedVersionTuple *versionTuple
+	}{
+		{
+			name: "valid: check vulnerabilities",
+			args: []string{"-" + pkg.FlagCheckVulnerabilities},
+			expectedVersionTuple: &versionTuple{
+				Source:                "v1.0.0",
+				Dest:                  "v1.1.0",
+				SourceVulnerabilities: []string{"GO-2020-0001"},
+				DestVulnerabilities:   []string{},
+			},
+		},
+		{
+			name: "valid: check licenses",
+			args: []string{"-" + pkg.FlagCheckLicenses},
+			expectedVersionTuple: &versionTuple{
+				Source:         "v1.0.0",
+				Dest:           "v1.1.0",
+				SourceLicenses: []string{"Apache-2.0"},
+				DestLicenses:   []string{"MIT"},
+			},
+		},
+		{
+			name: "valid: check vulnerabilities and licenses",
+			args: []string{"-" + pkg.FlagCheckVulnerabilities, "-" + pkg.FlagCheckLicenses},
+			expectedVersionTuple: &versionTuple{
+				Source:                "v1.0.0",
+				Dest:                  "v1.1.0",
+				SourceVulnerabilities: []string{"GO-2020-0001"},
+				DestVulnerabilities:   []string{},
+				SourceLicenses:        []string{"Apache-2.0"},
+				DestLicenses:          []string{"MIT"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := pkg.NewData()
+			fs := newFlagSet(d, flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			args := append([]string{"-source=" + source, "-dest=" + dest}, tt.args...)
+			if err := pkg.ParseFlags(fs, args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := validateData(d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			comparisons, err := loadComparisons(d)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pkg.NewClient(d, pkg.NewTransport())
+			d.Transport.SetHandler(pkg.OSVQueryURL, func(req *http.Request) (*http.Response, error) {
+				query := struct {
+					Version string `json:"version"`
+				}{}
+				if err := json.NewDecoder(req.Body).Decode(&query); err != nil {
+					return nil, err
+				}
+				type vuln struct {
+					ID string `json:"id"`
+				}
+				vulns := []vuln{}
+				if query.Version == "v1.0.0" {
+					vulns = append(vulns, vuln{ID: "GO-2020-0001"})
+				}
+				return newJSONResponse(map[string][]vuln{"vulns": vulns})
+			})
+
+			d.Transport.SetHandler(pkg.DepsDevURL, func(req *http.Request) (*http.Response, error) {
+				license := "MIT"
+				if strings.HasSuffix(req.URL.Path, "/versions/v1.0.0") {
+					license = "Apache-2.0"
+				}
+				return newJSONResponse(map[string][]string{"licenses": {license}})
+			})
+
+			results := process(d, comparisons)
+			if len(results[0].Error) != 0 {
+				t.Fatalf("unexpected error: %v", results[0].Error)
+			}
+			if v := results[0].Output.Dependencies["k8s.io/klog"]; !reflect.DeepEqual(v, tt.expectedVersionTuple) {
+				t.Errorf("expected version tuple:\n%+v\ngot:\n%+v", tt.expectedVersionTuple, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The source and destination are gomod
+// files, which are not required with a comparison config. A token is only
+// required for posting the result to the target issue.
+type options struct {
+	pkg.TokenOptions
+	pkg.DryRunOptions
+	pkg.TimeoutOptions
+	Token                string          `validate:"requiredif=target-issue,token"`
+	Source               []string        `flag:"source" usage:"Source gomod file, URL or 'github://org/repo@ref/path'" validate:"requiredunless=comparison-config,single,githuburl"`
+	Dest                 []string        `flag:"dest" usage:"Destination gomod file, URL or 'github://org/repo@ref/path'" validate:"requiredunless=comparison-config,single,githuburl"`
+	ComparisonConfig     string          `flag:"comparison-config" usage:"Path or URL to a YAML file with a list of source and destination pairs to compare in a single run"`
+	IgnorePaths          pkg.MultiString `flag:"ignore-path" usage:"A dependency path to ignore from the source Gomod (e.g. 'Golang', 'k8s.io/klog'). Multiple instances of the flag are allowed"`
+	GroupByNamespace     bool            `flag:"group-by-namespace" usage:"Group the dependencies by module namespace (e.g. 'k8s.io/*') with a count of differences per group"`
+	CheckVulnerabilities bool            `flag:"check-vulnerabilities" usage:"Query the OSV database for known vulnerabilities of dependency versions that differ"`
+	CheckLicenses        bool            `flag:"check-licenses" usage:"Query the deps.dev database for the licenses of dependency versions that differ and flag license changes"`
+	TargetIssue          string          `flag:"target-issue" usage:"A GitHub issue in the format 'org/repo#issue' where to post the results as a comment" validate:"issue"`
+	CacheDir             string          `flag:"cache-dir" usage:"Path to a directory in which to cache the gomod files downloaded from URLs. Files that did not change are not downloaded again"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Source and destination are not used with a comparison config.
+	if len(d.ComparisonConfig) > 0 && (len(d.Source) > 0 || len(d.Dest) > 0) {
+		return errors.Errorf("--%s cannot be used together with --%s and --%s",
+			pkg.FlagComparisonConfig, pkg.FlagSource, pkg.FlagDest)
+	}
+
+	return nil
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"io/ioutil"
@@ -36,8 +36,8 @@ func TestValidateData(t *testing.T) {
 			name: "valid: all fields are valid",
 			data: &pkg.Data{
 				Token:       validToken,
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "org/repo#123",
 			},
 		},
@@ -45,8 +45,8 @@ func TestValidateData(t *testing.T) {
 			name: "invalid: malformed target issue [1]",
 			data: &pkg.Data{
 				Token:       validToken,
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "org/repo#",
 			},
 			expectedError: true,
@@ -55,8 +55,8 @@ func TestValidateData(t *testing.T) {
 			name: "invalid: malformed target issue [2]",
 			data: &pkg.Data{
 				Token:       validToken,
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "org/repo",
 			},
 			expectedError: true,
@@ -65,32 +65,62 @@ func TestValidateData(t *testing.T) {
 			name: "invalid: malformed target issue [3]",
 			data: &pkg.Data{
 				Token:       validToken,
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "org/repo#00123",
 			},
 			expectedError: true,
 		},
 		{
-			name: "invalid: token set but target issue not set",
+			name: "valid: token set but target issue not set",
 			data: &pkg.Data{
 				Token:       validToken,
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "",
 			},
+		},
+		{
+			name: "valid: GitHub locations",
+			data: &pkg.Data{
+				Token:  validToken,
+				Source: []string{"github://org/private@v1.0.0/go.mod"},
+				Dest:   []string{"github://org/repo/staging/go.mod"},
+			},
+		},
+		{
+			name: "invalid: malformed GitHub location",
+			data: &pkg.Data{
+				Source: []string{"github://org/go.mod"},
+				Dest:   []string{"-"},
+			},
 			expectedError: true,
 		},
 		{
 			name: "invalid: target issue set but token not set",
 			data: &pkg.Data{
 				Token:       "",
-				Dest:        "-",
-				Source:      "-",
+				Dest:        []string{"-"},
+				Source:      []string{"-"},
 				TargetIssue: "org/repo#123",
 			},
 			expectedError: true,
 		},
+		{
+			name: "valid: comparison config without source and destination",
+			data: &pkg.Data{
+				ComparisonConfig: "config.yaml",
+			},
+		},
+		{
+			name: "invalid: comparison config together with source and destination",
+			data: &pkg.Data{
+				ComparisonConfig: "config.yaml",
+				Dest:             []string{"-"},
+				Source:           []string{"-"},
+			},
+			expectedError: true,
+		},
 		{
 			name:          "invalid: empty fields",
 			data:          &pkg.Data{},
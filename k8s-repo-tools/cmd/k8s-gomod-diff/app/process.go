@@ -0,0 +1,375 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const golangPath = "Golang"
+
+// process runs a list of comparisons and returns a result for each of them.
+// A failing comparison does not prevent the rest of the comparisons from running.
+func process(d *pkg.Data, comparisons []comparison) []*comparisonResult {
+	results := make([]*comparisonResult, len(comparisons))
+	for i, c := range comparisons {
+		pkg.Logf("running comparison %q", c.Name)
+		r := &comparisonResult{comparison: c}
+		out, err := processComparison(c, d.Timeout)
+		if err == nil && d.CheckVulnerabilities {
+			err = checkVulnerabilities(out, newOSVQueryFunc(d))
+		}
+		if err == nil && d.CheckLicenses {
+			err = checkLicenses(out, newDepsDevQueryFunc(d))
+		}
+		if err != nil {
+			pkg.Errorf("comparison %q failed: %v", c.Name, err)
+			r.Error = err.Error()
+		}
+		r.Output = out
+		results[i] = r
+	}
+	return results
+}
+
+func processComparison(c comparison, timeout time.Duration) (*output, error) {
+	dataSource, err := pkg.ReadFromFileOrURL(c.Source, timeout)
+	if err != nil {
+		return nil, err
+	}
+	dataDest, err := pkg.ReadFromFileOrURL(c.Dest, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return processBytes(dataSource, dataDest, c.IgnorePaths)
+}
+
+func processBytes(dataSource, dataDest []byte, ignorePaths []string) (*output, error) {
+	m := pathVersionTuple{}
+
+	// Parse the source data.
+	goSource, dataSource := extractGoDirectives(dataSource)
+	modFileSource, err := modfile.Parse("", dataSource, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range modFileSource.Require {
+		if r.Indirect {
+			continue // Skip indirect dependencies.
+		}
+		if isIgnored(r.Mod.Path, ignorePaths) {
+			continue // Skip paths that are ignored.
+		}
+		m[r.Mod.Path] = &versionTuple{Source: r.Mod.Version}
+	}
+
+	// Parse the destination data.
+	goDest, dataDest := extractGoDirectives(dataDest)
+	modFileDest, err := modfile.Parse("", dataDest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyInDest := map[string]string{}
+	for _, r := range modFileDest.Require {
+		if r.Indirect {
+			continue // Skip indirect dependencies.
+		}
+		if _, ok := m[r.Mod.Path]; !ok {
+			if !isIgnored(r.Mod.Path, ignorePaths) {
+				onlyInDest[r.Mod.Path] = r.Mod.Version
+			}
+			continue
+		}
+		m[r.Mod.Path].Dest = r.Mod.Version
+	}
+
+	// Add the go and toolchain directives to the dependency list.
+	var skipGolang, skipToolchain bool
+	for _, ip := range ignorePaths {
+		switch ip {
+		case golangPath:
+			skipGolang = true
+		case toolchainPath:
+			skipToolchain = true
+		}
+	}
+	if !skipGolang {
+		m[golangPath] = &versionTuple{
+			Source: goSource.Go,
+			Dest:   goDest.Go,
+		}
+	}
+	if !skipToolchain && (len(goSource.Toolchain) != 0 || len(goDest.Toolchain) != 0) {
+		m[toolchainPath] = &versionTuple{
+			Source: goSource.Toolchain,
+			Dest:   goDest.Toolchain,
+		}
+	}
+	if !skipGolang || !skipToolchain {
+		newer, err := toolchainNewer(goSource, goDest)
+		if err != nil {
+			pkg.Warningf("could not compare the go and toolchain directives: %v", err)
+		} else if newer {
+			pkg.Warningf("the destination requires a newer Go toolchain (go %s, toolchain %q) than the source supports (go %s, toolchain %q)",
+				goDest.Go, goDest.Toolchain, goSource.Go, goSource.Toolchain)
+		}
+	}
+
+	// Format output.
+	o := &output{
+		Dependencies: m,
+	}
+	if len(onlyInDest) != 0 {
+		o.OnlyInDest = onlyInDest
+	}
+	return o, nil
+}
+
+// isIgnored returns true if a path is in the list of ignored paths.
+func isIgnored(path string, ignorePaths []string) bool {
+	for _, ip := range ignorePaths {
+		if path == ip {
+			return true
+		}
+	}
+	return false
+}
+
+type output struct {
+	Dependencies           pathVersionTuple `json:"dependencies"`
+	VulnerabilitiesChecked bool             `json:"vulnerabilitiesChecked,omitempty"`
+	LicensesChecked        bool             `json:"licensesChecked,omitempty"`
+	// OnlyInDest holds the versions of dependencies that are not in the source.
+	OnlyInDest map[string]string `json:"onlyInDest,omitempty"`
+}
+
+type versionTuple struct {
+	Source                string   `json:"source"`
+	Dest                  string   `json:"dest"`
+	SourceVulnerabilities []string `json:"sourceVulnerabilities,omitempty"`
+	DestVulnerabilities   []string `json:"destVulnerabilities,omitempty"`
+	SourceLicenses        []string `json:"sourceLicenses,omitempty"`
+	DestLicenses          []string `json:"destLicenses,omitempty"`
+}
+
+type pathVersionTuple map[string]*versionTuple
+
+// comparisonResult holds the output or the error of a single comparison.
+type comparisonResult struct {
+	comparison
+	Output *output `json:"output,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// outputFormatter writes an output in a human readable format.
+type outputFormatter func(w io.Writer, o *output, source, dest string)
+
+func formatOutput(w io.Writer, o *output, source, dest string) {
+	keys := sortedDifferingKeys(o)
+	if len(keys) == 0 && !hasAddedOrRemoved(o) {
+		return
+	}
+	fmt.Fprintf(w, "Comparing Go module files:\n  Source: %s\n  Destination: %s\n", source, dest)
+
+	if len(keys) != 0 {
+		fmt.Fprintln(w, "The following dependency versions differ:")
+		tabW := tabwriter.NewWriter(w, 12, 0, 2, ' ', 0)
+		fmt.Fprintln(tabW, strings.Join(formatColumns(o), "\t"))
+		// Loop trough the sorted keys.
+		for _, k := range keys {
+			fmt.Fprintln(tabW, strings.Join(formatRow(o, k), "\t"))
+		}
+		tabW.Flush()
+	}
+
+	formatAddedRemoved(w, o)
+}
+
+// formatGroupedOutput is like formatOutput, but groups the dependencies by
+// their module namespace (e.g. "k8s.io/*") and prints a drift count per group.
+func formatGroupedOutput(w io.Writer, o *output, source, dest string) {
+	// Group the sorted keys.
+	groups := map[string][]string{}
+	var groupNames []string
+	var total int
+	for _, k := range sortedDifferingKeys(o) {
+		ns := moduleNamespace(k)
+		if _, ok := groups[ns]; !ok {
+			groupNames = append(groupNames, ns)
+		}
+		groups[ns] = append(groups[ns], k)
+		total++
+	}
+	if total == 0 && !hasAddedOrRemoved(o) {
+		return
+	}
+	sort.Strings(groupNames)
+
+	fmt.Fprintf(w, "Comparing Go module files:\n  Source: %s\n  Destination: %s\n", source, dest)
+	if total != 0 {
+		fmt.Fprintf(w, "The following dependency versions differ (%d in %d groups):\n", total, len(groupNames))
+	}
+	for _, ns := range groupNames {
+		fmt.Fprintf(w, "\n%s (%d differ)\n", ns, len(groups[ns]))
+		tabW := tabwriter.NewWriter(w, 12, 0, 2, ' ', 0)
+		fmt.Fprintln(tabW, strings.Join(formatColumns(o), "\t"))
+		for _, k := range groups[ns] {
+			fmt.Fprintln(tabW, strings.Join(formatRow(o, k), "\t"))
+		}
+		tabW.Flush()
+	}
+
+	if total != 0 && hasAddedOrRemoved(o) {
+		fmt.Fprintln(w)
+	}
+	formatAddedRemoved(w, o)
+}
+
+// formatAddedRemoved writes the sections for dependencies that are missing
+// in the destination and dependencies that are only in the destination.
+func formatAddedRemoved(w io.Writer, o *output) {
+	if missing := sortedMissingInDestKeys(o); len(missing) != 0 {
+		fmt.Fprintln(w, "The following dependencies are missing in the destination:")
+		tabW := tabwriter.NewWriter(w, 12, 0, 2, ' ', 0)
+		fmt.Fprintln(tabW, "PATH\tSOURCE")
+		for _, k := range missing {
+			fmt.Fprintf(tabW, "%s\t%s\n", k, o.Dependencies[k].Source)
+		}
+		tabW.Flush()
+	}
+
+	if len(o.OnlyInDest) != 0 {
+		keys := make([]string, 0, len(o.OnlyInDest))
+		for k := range o.OnlyInDest {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintln(w, "The following dependencies are only in the destination:")
+		tabW := tabwriter.NewWriter(w, 12, 0, 2, ' ', 0)
+		fmt.Fprintln(tabW, "PATH\tDEST")
+		for _, k := range keys {
+			fmt.Fprintf(tabW, "%s\t%s\n", k, o.OnlyInDest[k])
+		}
+		tabW.Flush()
+	}
+}
+
+// hasAddedOrRemoved returns true if there are dependencies that are missing
+// in the destination or only in the destination.
+func hasAddedOrRemoved(o *output) bool {
+	return len(sortedMissingInDestKeys(o)) != 0 || len(o.OnlyInDest) != 0
+}
+
+// sortedMissingInDestKeys returns the sorted paths of source dependencies
+// that are missing in the destination. A toolchain directive that is only
+// in the source is reported as missing too.
+func sortedMissingInDestKeys(o *output) []string {
+	keys := []string{}
+	for k, v := range o.Dependencies {
+		if k == golangPath || v.Dest != "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// moduleNamespace returns the owner namespace of a module path,
+// such as "k8s.io/*" for "k8s.io/klog".
+func moduleNamespace(path string) string {
+	if isGoDirective(path) {
+		return path
+	}
+	return strings.Split(path, "/")[0] + "/*"
+}
+
+// sortedDifferingKeys returns the sorted paths of dependencies that have
+// different versions in the source and destination.
+func sortedDifferingKeys(o *output) []string {
+	keys := []string{}
+	for k, v := range o.Dependencies {
+		// If the destination version is empty, dest does not have this dependency.
+		if v.Dest == "" || v.Source == v.Dest {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatColumns(o *output) []string {
+	columns := []string{"PATH", "SOURCE", "DEST"}
+	if o.VulnerabilitiesChecked {
+		columns = append(columns, "VULNERABILITIES")
+	}
+	if o.LicensesChecked {
+		columns = append(columns, "LICENSES")
+	}
+	return columns
+}
+
+func formatRow(o *output, k string) []string {
+	v := o.Dependencies[k]
+	row := []string{k, v.Source, v.Dest}
+	if o.VulnerabilitiesChecked {
+		row = append(row, formatVulnerabilities(k, v))
+	}
+	if o.LicensesChecked {
+		row = append(row, formatLicenses(k, v))
+	}
+	return row
+}
+
+// formatReport writes an aggregated report for a list of comparison results.
+// Unlike formatOutput, a section is written for every comparison, even if
+// there are no differences or if the comparison failed.
+func formatReport(w io.Writer, results []*comparisonResult, format outputFormatter) {
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%d/%d] %s\n", i+1, len(results), r.Name)
+
+		if len(r.Error) != 0 {
+			fmt.Fprintf(w, "Comparing Go module files:\n  Source: %s\n  Destination: %s\n"+
+				"The comparison failed: %s\n", r.Source, r.Dest, r.Error)
+			continue
+		}
+
+		var b bytes.Buffer
+		format(&b, r.Output, r.Source, r.Dest)
+		if b.Len() == 0 {
+			fmt.Fprintf(w, "Comparing Go module files:\n  Source: %s\n  Destination: %s\n"+
+				"No dependency versions differ.\n", r.Source, r.Dest)
+			continue
+		}
+		w.Write(b.Bytes())
+	}
+}
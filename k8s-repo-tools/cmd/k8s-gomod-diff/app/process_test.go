@@ -0,0 +1,455 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestProcessBytes(t *testing.T) {
+	tests := []struct {
+		name               string
+		dataSource         []byte
+		dataDest           []byte
+		ignorePaths        []string
+		expectedOutputJSON string
+		expectedError      bool
+	}{
+		{
+			name: "valid: dest has newer components",
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.12
+			require (
+				k8s.io/klog v0.8.0
+				sigs.k8s.io/yaml v1.0.0
+			)
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.13
+			require (
+				k8s.io/klog v0.9.0
+				sigs.k8s.io/yaml v1.1.0
+				k8s.io/api v1.0.0
+			)
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.12","dest":"1.13"},"k8s.io/klog":{"source":"v0.8.0","dest":"v0.9.0"},"sigs.k8s.io/yaml":{"source":"v1.0.0","dest":"v1.1.0"}},"onlyInDest":{"k8s.io/api":"v1.0.0"}}`,
+		},
+		{
+			name: "valid: dependency versions match",
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0
+			)
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0
+			)
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.13","dest":"1.13"},"k8s.io/klog":{"source":"v0.8.0","dest":"v0.8.0"}}}`,
+		},
+		{
+			name: "valid: no matching dependencies in dest",
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0
+			)
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.13
+			require (
+				k8s.io/api v1.0.0
+			)
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.13","dest":"1.13"},"k8s.io/klog":{"source":"v0.8.0","dest":""}},"onlyInDest":{"k8s.io/api":"v1.0.0"}}`,
+		},
+		{
+			name: "valid: indirect dependencies are skipped",
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0 // indirect
+			)
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0 // indirect
+			)
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.13","dest":"1.13"}}}`,
+		},
+		{
+			name:        "valid: ignored paths are skipped",
+			ignorePaths: []string{"Golang", "k8s.io/klog"},
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0
+				k8s.io/api v1.0.0
+			)
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.13
+			require (
+				k8s.io/klog v0.8.0
+				k8s.io/api v1.1.0
+			)
+			`),
+			expectedOutputJSON: `{"dependencies":{"k8s.io/api":{"source":"v1.0.0","dest":"v1.1.0"}}}`,
+		},
+		{
+			name: "valid: toolchain directives are compared",
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.21.0
+			toolchain go1.21.1
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.22.0
+			toolchain go1.22.2
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.21.0","dest":"1.22.0"},"Toolchain":{"source":"go1.21.1","dest":"go1.22.2"}}}`,
+		},
+		{
+			name:        "valid: ignored toolchain is skipped",
+			ignorePaths: []string{"Toolchain"},
+			dataSource: []byte(`
+			module k8s.io/kubeadm
+			go 1.21.0
+			toolchain go1.21.1
+			`),
+			dataDest: []byte(`
+			module k8s.io/kubernetes
+			go 1.21.0
+			`),
+			expectedOutputJSON: `{"dependencies":{"Golang":{"source":"1.21.0","dest":"1.21.0"}}}`,
+		},
+		{
+			name:          "invalid: error parsing input",
+			dataSource:    []byte(`foo`),
+			dataDest:      []byte(`bar`),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := processBytes(tc.dataSource, tc.dataDest, tc.ignorePaths)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error: %v, got: %v, error: %v", tc.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			outputJSON, err := json.Marshal(output)
+			if err != nil {
+				t.Fatalf("could not marshal output: %v", err)
+			}
+			outputJSONString := string(outputJSON)
+			if tc.expectedOutputJSON != outputJSONString {
+				t.Errorf("expected output:\n%s\ngot:\n%s\n", tc.expectedOutputJSON, outputJSON)
+			}
+		})
+	}
+}
+
+func TestFormatOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         *output
+		expectedOutput string
+	}{
+		{
+			name: "valid: output with go version and two dependencies",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":      &versionTuple{Source: "1.12", Dest: "1.13"},
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+					"github.com/someorg/someverylongnamegoeshere": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH                                         SOURCE      DEST
+Golang                                       1.12        1.13
+github.com/someorg/someverylongnamegoeshere  v1.0.0      v1.1.0
+k8s.io/klog                                  v1.0.0      v1.1.0
+`,
+		},
+		{
+			name: "valid: only one dependency differs",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":      &versionTuple{Source: "1.12", Dest: "1.12"},
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH         SOURCE      DEST
+k8s.io/klog  v1.0.0      v1.1.0
+`,
+		},
+		{
+			name: "valid: only go version differs",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang": &versionTuple{Source: "1.12", Dest: "1.13"},
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH        SOURCE      DEST
+Golang      1.12        1.13
+`,
+		},
+		{
+			name: "valid: dependencies missing in dest and only in dest",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":      &versionTuple{Source: "1.12", Dest: "1.12"},
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+					"k8s.io/api":  &versionTuple{Source: "v1.0.0", Dest: ""},
+				},
+				OnlyInDest: map[string]string{
+					"sigs.k8s.io/yaml": "v1.1.0",
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH         SOURCE      DEST
+k8s.io/klog  v1.0.0      v1.1.0
+The following dependencies are missing in the destination:
+PATH        SOURCE
+k8s.io/api  v1.0.0
+The following dependencies are only in the destination:
+PATH              DEST
+sigs.k8s.io/yaml  v1.1.0
+`,
+		},
+		{
+			name: "valid: toolchain missing in dest",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":    &versionTuple{Source: "1.21.0", Dest: "1.21.0"},
+					"Toolchain": &versionTuple{Source: "go1.21.5", Dest: ""},
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependencies are missing in the destination:
+PATH        SOURCE
+Toolchain   go1.21.5
+`,
+		},
+		{
+			name: "valid: only dependencies in dest",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang": &versionTuple{Source: "1.12", Dest: "1.12"},
+				},
+				OnlyInDest: map[string]string{
+					"sigs.k8s.io/yaml": "v1.1.0",
+				},
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependencies are only in the destination:
+PATH              DEST
+sigs.k8s.io/yaml  v1.1.0
+`,
+		},
+		{
+			name: "valid: no differences",
+			output: &output{
+				Dependencies: pathVersionTuple{},
+			},
+			expectedOutput: ``,
+		},
+		{
+			name: "valid: vulnerabilities were checked",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":     &versionTuple{Source: "1.12", Dest: "1.13"},
+					"k8s.io/api": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+					"k8s.io/klog": &versionTuple{
+						Source:                "v1.0.0",
+						Dest:                  "v1.1.0",
+						SourceVulnerabilities: []string{"GO-2020-0001", "GO-2020-0002"},
+						DestVulnerabilities:   []string{"GO-2020-0003"},
+					},
+				},
+				VulnerabilitiesChecked: true,
+			},
+			expectedOutput: `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH         SOURCE      DEST        VULNERABILITIES
+Golang       1.12        1.13        -
+k8s.io/api   v1.0.0      v1.1.0      none
+k8s.io/klog  v1.0.0      v1.1.0      source: GO-2020-0001,GO-2020-0002; dest: GO-2020-0003
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			formatOutput(&b, tc.output, "https://foo", "https://bar")
+			bStr := b.String()
+			if bStr != tc.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s\n", tc.expectedOutput, bStr)
+			}
+		})
+	}
+}
+
+func TestFormatReport(t *testing.T) {
+	results := []*comparisonResult{
+		{
+			comparison: comparison{Name: "first", Source: "https://foo", Dest: "https://bar"},
+			Output: &output{
+				Dependencies: pathVersionTuple{
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+				},
+			},
+		},
+		{
+			comparison: comparison{Name: "second", Source: "https://foo", Dest: "https://baz"},
+			Output: &output{
+				Dependencies: pathVersionTuple{
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.0.0"},
+				},
+			},
+		},
+		{
+			comparison: comparison{Name: "third", Source: "https://foo", Dest: "https://qux"},
+			Error:      "some-error",
+		},
+	}
+
+	expectedOutput := `[1/3] first
+Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ:
+PATH         SOURCE      DEST
+k8s.io/klog  v1.0.0      v1.1.0
+
+[2/3] second
+Comparing Go module files:
+  Source: https://foo
+  Destination: https://baz
+No dependency versions differ.
+
+[3/3] third
+Comparing Go module files:
+  Source: https://foo
+  Destination: https://qux
+The comparison failed: some-error
+`
+
+	var b bytes.Buffer
+	formatReport(&b, results, formatOutput)
+	if b.String() != expectedOutput {
+		t.Errorf("expected output:\n%s\ngot:\n%s\n", expectedOutput, b.String())
+	}
+}
+
+func TestFormatGroupedOutput(t *testing.T) {
+	o := &output{
+		Dependencies: pathVersionTuple{
+			"Golang":                 &versionTuple{Source: "1.12", Dest: "1.13"},
+			"k8s.io/klog":            &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+			"k8s.io/api":             &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+			"k8s.io/apimachinery":    &versionTuple{Source: "v1.0.0", Dest: "v1.0.0"},
+			"github.com/foo/bar":     &versionTuple{Source: "v1.0.0", Dest: "v1.2.0"},
+			"sigs.k8s.io/yaml":       &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+			"sigs.k8s.io/kustomize":  &versionTuple{Source: "v1.0.0", Dest: ""},
+			"github.com/foo/missing": &versionTuple{Source: "v1.0.0", Dest: ""},
+		},
+	}
+
+	expectedOutput := `Comparing Go module files:
+  Source: https://foo
+  Destination: https://bar
+The following dependency versions differ (5 in 4 groups):
+
+Golang (1 differ)
+PATH        SOURCE      DEST
+Golang      1.12        1.13
+
+github.com/* (1 differ)
+PATH                SOURCE      DEST
+github.com/foo/bar  v1.0.0      v1.2.0
+
+k8s.io/* (2 differ)
+PATH         SOURCE      DEST
+k8s.io/api   v1.0.0      v1.1.0
+k8s.io/klog  v1.0.0      v1.1.0
+
+sigs.k8s.io/* (1 differ)
+PATH              SOURCE      DEST
+sigs.k8s.io/yaml  v1.0.0      v1.1.0
+
+The following dependencies are missing in the destination:
+PATH                    SOURCE
+github.com/foo/missing  v1.0.0
+sigs.k8s.io/kustomize   v1.0.0
+`
+
+	var b bytes.Buffer
+	formatGroupedOutput(&b, o, "https://foo", "https://bar")
+	if b.String() != expectedOutput {
+		t.Errorf("expected output:\n%s\ngot:\n%s\n", expectedOutput, b.String())
+	}
+
+	// No differences result in no output.
+	b.Reset()
+	formatGroupedOutput(&b, &output{Dependencies: pathVersionTuple{}}, "https://foo", "https://bar")
+	if b.Len() != 0 {
+		t.Errorf("expected empty output, got:\n%s\n", b.String())
+	}
+}
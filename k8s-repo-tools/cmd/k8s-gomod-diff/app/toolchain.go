@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const toolchainPath = "Toolchain"
+
+// goDirectives holds the values of the go and toolchain directives of a gomod file.
+type goDirectives struct {
+	Go        string
+	Toolchain string
+}
+
+// isGoDirective returns true if a path is not a module, but a go or toolchain directive.
+func isGoDirective(path string) bool {
+	return path == golangPath || path == toolchainPath
+}
+
+// extractGoDirectives returns the go and toolchain directives of a gomod file
+// and the rest of the file without them. The modfile parser only supports
+// MAJOR.MINOR go directives and no toolchain directive, so they are extracted
+// before parsing.
+func extractGoDirectives(data []byte) (goDirectives, []byte) {
+	d := goDirectives{}
+	lines := bytes.Split(data, []byte("\n"))
+	rest := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		s := string(line)
+		if i := strings.Index(s, "//"); i != -1 {
+			s = s[:i]
+		}
+		fields := strings.Fields(s)
+		if len(fields) == 2 {
+			switch fields[0] {
+			case "go":
+				d.Go = fields[1]
+				continue
+			case "toolchain":
+				d.Toolchain = fields[1]
+				continue
+			}
+		}
+		rest = append(rest, line)
+	}
+	return d, bytes.Join(rest, []byte("\n"))
+}
+
+// required returns the minimum Go version required by the directives.
+// A toolchain directive has precedence over the go directive.
+func (d goDirectives) required() (*version.Version, error) {
+	v := d.Go
+	if len(d.Toolchain) != 0 && d.Toolchain != "default" {
+		v = strings.TrimPrefix(d.Toolchain, "go")
+	}
+	return version.ParseGeneric(v)
+}
+
+// toolchainNewer returns true if the destination requires a newer Go version
+// than what the source supports. Files without a go directive are not compared.
+func toolchainNewer(source, dest goDirectives) (bool, error) {
+	if len(source.Go) == 0 || len(dest.Go) == 0 {
+		return false, nil
+	}
+	vSource, err := source.required()
+	if err != nil {
+		return false, err
+	}
+	vDest, err := dest.required()
+	if err != nil {
+		return false, err
+	}
+	return vSource.LessThan(vDest), nil
+}
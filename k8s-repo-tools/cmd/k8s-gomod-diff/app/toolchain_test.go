@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+)
+
+func TestExtractGoDirectives(t *testing.T) {
+	data := []byte(`module k8s.io/kubeadm
+go 1.21.0 // comment
+toolchain go1.21.5
+require k8s.io/klog v0.8.0
+`)
+	expectedRest := `module k8s.io/kubeadm
+require k8s.io/klog v0.8.0
+`
+	d, rest := extractGoDirectives(data)
+	if d.Go != "1.21.0" || d.Toolchain != "go1.21.5" {
+		t.Errorf("unexpected directives: %+v", d)
+	}
+	if string(rest) != expectedRest {
+		t.Errorf("expected rest:\n%s\ngot:\n%s\n", expectedRest, rest)
+	}
+}
+
+func TestToolchainNewer(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         goDirectives
+		dest           goDirectives
+		expectedResult bool
+		expectedError  bool
+	}{
+		{
+			name:           "valid: dest requires a newer go version",
+			source:         goDirectives{Go: "1.20"},
+			dest:           goDirectives{Go: "1.21.0"},
+			expectedResult: true,
+		},
+		{
+			name:   "valid: source supports the dest go version",
+			source: goDirectives{Go: "1.21.0"},
+			dest:   goDirectives{Go: "1.21"},
+		},
+		{
+			name:           "valid: dest requires a newer toolchain",
+			source:         goDirectives{Go: "1.21.0", Toolchain: "go1.21.1"},
+			dest:           goDirectives{Go: "1.21.0", Toolchain: "go1.21.5"},
+			expectedResult: true,
+		},
+		{
+			name:   "valid: source toolchain is newer than the dest go version",
+			source: goDirectives{Go: "1.20", Toolchain: "go1.22.0"},
+			dest:   goDirectives{Go: "1.21.0", Toolchain: "default"},
+		},
+		{
+			name:   "valid: missing go directive is not compared",
+			source: goDirectives{},
+			dest:   goDirectives{Go: "1.21.0"},
+		},
+		{
+			name:          "invalid: cannot parse toolchain",
+			source:        goDirectives{Go: "1.21.0", Toolchain: "foo"},
+			dest:          goDirectives{Go: "1.21.0"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := toolchainNewer(tc.source, tc.dest)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error: %v, got: %v, error: %v", tc.expectedError, err != nil, err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("expected result: %v, got: %v", tc.expectedResult, result)
+			}
+		})
+	}
+}
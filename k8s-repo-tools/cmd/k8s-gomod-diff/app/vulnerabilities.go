@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// vulnerabilityQueryFunc returns the known vulnerability IDs for a module version.
+type vulnerabilityQueryFunc func(path, version string) ([]string, error)
+
+// newOSVQueryFunc returns a vulnerabilityQueryFunc that uses the OSV API.
+// The requests are sent with the custom transport of d, if it is set.
+func newOSVQueryFunc(d *pkg.Data) vulnerabilityQueryFunc {
+	client := pkg.NewHTTPClient(d.Timeout)
+	if d.Transport != nil {
+		client.Transport = d.Transport
+	}
+	return func(path, version string) ([]string, error) {
+		return pkg.OSVGetVulnerabilities(client, pkg.OSVQueryURL, path, version)
+	}
+}
+
+// checkVulnerabilities queries the known vulnerabilities for both versions of
+// every dependency that differs between the source and the destination.
+func checkVulnerabilities(o *output, query vulnerabilityQueryFunc) error {
+	// Sort the keys to query in a deterministic order.
+	keys := make([]string, 0, len(o.Dependencies))
+	for k := range o.Dependencies {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := o.Dependencies[k]
+		// Go directives are not modules and dependencies missing in dest are not compared.
+		if isGoDirective(k) || v.Dest == "" || v.Source == v.Dest {
+			continue
+		}
+		var err error
+		if v.SourceVulnerabilities, err = query(k, v.Source); err != nil {
+			return err
+		}
+		if v.DestVulnerabilities, err = query(k, v.Dest); err != nil {
+			return err
+		}
+	}
+	o.VulnerabilitiesChecked = true
+	return nil
+}
+
+// formatVulnerabilities formats the vulnerabilities of a versionTuple for a table cell.
+func formatVulnerabilities(path string, v *versionTuple) string {
+	if isGoDirective(path) {
+		return "-"
+	}
+	if len(v.SourceVulnerabilities) == 0 && len(v.DestVulnerabilities) == 0 {
+		return "none"
+	}
+	var result []string
+	if len(v.SourceVulnerabilities) > 0 {
+		result = append(result, "source: "+strings.Join(v.SourceVulnerabilities, ","))
+	}
+	if len(v.DestVulnerabilities) > 0 {
+		result = append(result, "dest: "+strings.Join(v.DestVulnerabilities, ","))
+	}
+	return strings.Join(result, "; ")
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckVulnerabilities(t *testing.T) {
+	knownVulnerabilities := map[string][]string{
+		"k8s.io/klog@v1.0.0": {"GO-2020-0001"},
+	}
+
+	tests := []struct {
+		name           string
+		output         *output
+		queryError     bool
+		expectedOutput *output
+		expectedError  bool
+	}{
+		{
+			name: "valid: only differing dependencies are queried",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"Golang":           &versionTuple{Source: "1.12", Dest: "1.13"},
+					"k8s.io/klog":      &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+					"k8s.io/api":       &versionTuple{Source: "v1.0.0", Dest: "v1.0.0"},
+					"sigs.k8s.io/yaml": &versionTuple{Source: "v1.0.0", Dest: ""},
+				},
+			},
+			expectedOutput: &output{
+				Dependencies: pathVersionTuple{
+					"Golang": &versionTuple{Source: "1.12", Dest: "1.13"},
+					"k8s.io/klog": &versionTuple{
+						Source:                "v1.0.0",
+						Dest:                  "v1.1.0",
+						SourceVulnerabilities: []string{"GO-2020-0001"},
+					},
+					"k8s.io/api":       &versionTuple{Source: "v1.0.0", Dest: "v1.0.0"},
+					"sigs.k8s.io/yaml": &versionTuple{Source: "v1.0.0", Dest: ""},
+				},
+				VulnerabilitiesChecked: true,
+			},
+		},
+		{
+			name: "invalid: query error",
+			output: &output{
+				Dependencies: pathVersionTuple{
+					"k8s.io/klog": &versionTuple{Source: "v1.0.0", Dest: "v1.1.0"},
+				},
+			},
+			queryError:    true,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := func(path, version string) ([]string, error) {
+				if tt.queryError {
+					return nil, errors.New("simulated error")
+				}
+				return knownVulnerabilities[path+"@"+version], nil
+			}
+			err := checkVulnerabilities(tt.output, query)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tt.output, tt.expectedOutput) {
+				t.Errorf("expected output:\n%+v\ngot:\n%+v\n", tt.expectedOutput, tt.output)
+			}
+		})
+	}
+}
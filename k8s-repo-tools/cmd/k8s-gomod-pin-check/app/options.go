@@ -14,35 +14,24 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Source       []string        `flag:"source" usage:"Path or URL to the go.mod file to check" validate:"required,single"`
+	IgnorePaths  pkg.MultiString `flag:"ignore-path" usage:"A dependency path to ignore from the source Gomod (e.g. 'Golang', 'k8s.io/klog'). Multiple instances of the flag are allowed"`
+	OutputFormat string          `flag:"output-format" default:"text" usage:"Format of the output. One of \"text\" or \"json\"" validate:"oneof=text|json"`
+}
+
 // validateData validates the user input.
 func validateData(d *pkg.Data) error {
 	pkg.Logf("validating user input...")
 
-	// Validate empty options.
-	for k, v := range map[string]*string{
-		pkg.FlagDest:  &d.Dest,
-		pkg.FlagToken: &d.Token,
-	} {
-		if err := pkg.ValidateEmptyOption(k, *v); err != nil {
-			return err
-		}
-	}
-
-	// Validate org/repo.
-	if err := pkg.ValidateRepo(pkg.FlagDest, d.Dest); err != nil {
-		return err
-	}
-
-	// Validate token.
-	if err := pkg.ValidateToken(pkg.FlagToken, d.Token); err != nil {
-		return err
-	}
-
-	return nil
+	return pkg.ValidateOptions(d, &options{})
 }
@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnreachable = "unreachable"
+	statusNotFound    = "not-found"
+	statusSkipped     = "skipped"
+)
+
+// pseudoVersionRE matches pseudo-versions such as v0.0.0-20200101000000-0123456789ab.
+// It is the same expression that the go command uses.
+var pseudoVersionRE = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// pin is the result of checking a single replace directive.
+type pin struct {
+	Module      string `json:"module"`
+	Replacement string `json:"replacement"`
+	Repo        string `json:"repo,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// report holds the results for all replace directives in a go.mod file.
+type report struct {
+	Source string `json:"source"`
+	Pins   []*pin `json:"pins"`
+}
+
+// failed returns the number of pins that did not pass the check.
+func (r *report) failed() int {
+	var n int
+	for _, p := range r.Pins {
+		if p.Status == statusUnreachable || p.Status == statusNotFound {
+			n++
+		}
+	}
+	return n
+}
+
+// process reads the go.mod file from d.Source and checks its replace directives.
+func process(d *pkg.Data) (*report, error) {
+	data, err := pkg.ReadFromFileOrURL(d.SourceRepo(), d.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse(d.SourceRepo(), data, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %q", d.SourceRepo())
+	}
+
+	r := &report{Source: d.SourceRepo(), Pins: []*pin{}}
+	tagCache := map[string][]*github.Reference{}
+	for _, rep := range f.Replace {
+		// Replacements with a local directory are not pins.
+		if rep.New.Version == "" {
+			continue
+		}
+		if isIgnored(rep.Old.Path, d.IgnorePaths) || isIgnored(rep.New.Path, d.IgnorePaths) {
+			pkg.Logf("ignoring the replace directive for %q", rep.Old.Path)
+			continue
+		}
+
+		p := &pin{
+			Module:      rep.Old.Path,
+			Replacement: rep.New.Path + "@" + rep.New.Version,
+		}
+		r.Pins = append(r.Pins, p)
+
+		repo, prefix, ok := moduleRepo(rep.New.Path)
+		if !ok {
+			p.Status = statusSkipped
+			p.Reason = fmt.Sprintf("cannot map %q to a GitHub repository", rep.New.Path)
+			continue
+		}
+		p.Repo = repo
+
+		tags, ok := tagCache[repo]
+		if !ok {
+			pkg.Logf("getting the tags of repository %q", repo)
+			tags, err = pkg.GitHubGetTags(d, repo)
+			if err != nil {
+				return nil, err
+			}
+			tagCache[repo] = tags
+		}
+		if err := checkPin(d, p, tags, prefix, rep.New.Version); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// checkPin checks a single pin and updates its status. For a pseudo-version the
+// commit must be an ancestor of a tag, while for a regular version the tag must exist.
+func checkPin(d *pkg.Data, p *pin, tags []*github.Reference, prefix, v string) error {
+	v = strings.TrimSuffix(v, "+incompatible")
+	p.Commit = pseudoVersionRev(v)
+	if p.Commit == "" {
+		tag := prefix + v
+		for _, ref := range tags {
+			if ref.GetRef() == "refs/tags/"+tag {
+				p.Status = statusOK
+				p.Tag = tag
+				return nil
+			}
+		}
+		p.Status = statusNotFound
+		p.Reason = fmt.Sprintf("tag %q does not exist", tag)
+		return nil
+	}
+
+	// Compare the commit with the latest tag of each release line, newest first.
+	// A commit that is an ancestor of one of them was published as part of that tag.
+	for _, tag := range latestTagsPerMinor(tags, prefix) {
+		pkg.Logf("checking if commit %q in repository %q is reachable from tag %q", p.Commit, p.Repo, tag)
+		cmp, err := pkg.GitHubCompareBranches(d, p.Repo, p.Commit, tag)
+		if pkg.ErrorKindOf(err) == pkg.ErrorKindNotFound {
+			p.Status = statusNotFound
+			p.Reason = fmt.Sprintf("commit %q does not exist in repository %q", p.Commit, p.Repo)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch cmp.GetStatus() {
+		case "ahead", "identical":
+			p.Status = statusOK
+			p.Tag = tag
+			return nil
+		}
+	}
+	p.Status = statusUnreachable
+	p.Reason = fmt.Sprintf("commit %q is not reachable from any tag", p.Commit)
+	return nil
+}
+
+// moduleRepo maps a module path to a GitHub repository in the format org/repo.
+// It also returns the tag prefix for modules in a subdirectory of the repository.
+func moduleRepo(path string) (string, string, bool) {
+	// Drop the major version suffix, as it is not part of the repository path.
+	if prefix, _, ok := module.SplitPathVersion(path); ok {
+		path = prefix
+	}
+	elems := strings.Split(path, "/")
+
+	var repo string
+	var rest []string
+	switch elems[0] {
+	case "github.com":
+		if len(elems) < 3 {
+			return "", "", false
+		}
+		repo, rest = elems[1]+"/"+elems[2], elems[3:]
+	case "k8s.io":
+		if len(elems) < 2 {
+			return "", "", false
+		}
+		repo, rest = "kubernetes/"+elems[1], elems[2:]
+	case "sigs.k8s.io":
+		if len(elems) < 2 {
+			return "", "", false
+		}
+		repo, rest = "kubernetes-sigs/"+elems[1], elems[2:]
+	default:
+		return "", "", false
+	}
+
+	var prefix string
+	if len(rest) != 0 {
+		prefix = strings.Join(rest, "/") + "/"
+	}
+	return repo, prefix, true
+}
+
+// pseudoVersionRev returns the commit SHA of a pseudo-version or an empty
+// string if the version is not a pseudo-version.
+func pseudoVersionRev(v string) string {
+	if !pseudoVersionRE.MatchString(v) {
+		return ""
+	}
+	if i := strings.Index(v, "+"); i != -1 {
+		v = v[:i]
+	}
+	return v[strings.LastIndex(v, "-")+1:]
+}
+
+// latestTagsPerMinor returns the latest tag with the given prefix for each
+// MAJOR.MINOR release line, sorted from the newest to the oldest line.
+func latestTagsPerMinor(refs []*github.Reference, prefix string) []string {
+	latest := map[string]*version.Version{}
+	for _, ref := range refs {
+		name := strings.TrimPrefix(ref.GetRef(), "refs/tags/")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		v, err := version.ParseSemantic(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+		if cur, ok := latest[key]; !ok || cur.LessThan(v) {
+			latest[key] = v
+		}
+	}
+
+	versions := make([]*version.Version, 0, len(latest))
+	for _, v := range latest {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[j].LessThan(versions[i])
+	})
+
+	tags := make([]string, 0, len(versions))
+	for _, v := range versions {
+		tags = append(tags, prefix+"v"+v.String())
+	}
+	return tags
+}
+
+// isIgnored returns true if a path is in the list of ignored paths.
+func isIgnored(path string, ignorePaths []string) bool {
+	for _, ip := range ignorePaths {
+		if path == ip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// newCompareHandler returns a handler for comparing a commit with a tag.
+// The commit is reachable from the tags in the reachable map and a commit
+// that is missing from the map does not exist.
+func newCompareHandler(reachable map[string][]string) pkg.HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		refs := strings.Split(strings.Split(req.URL.Path, "compare/")[1], "...")
+		tags, ok := reachable[refs[0]]
+		if !ok {
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusNotFound,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"Not Found"}`)),
+				Header:     http.Header{},
+			}, nil
+		}
+		status := "diverged"
+		for _, tag := range tags {
+			if tag == refs[1] {
+				status = "ahead"
+			}
+		}
+		buf, err := json.Marshal(&github.CommitsComparison{Status: github.String(status)})
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-gomod-pin-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repoTags := []*github.Reference{
+		{Ref: github.String("refs/tags/v1.0.0")},
+		{Ref: github.String("refs/tags/v1.1.0")},
+		{Ref: github.String("refs/tags/v1.1.1")},
+		{Ref: github.String("refs/tags/v2.0.0-beta.0")},
+		{Ref: github.String("refs/tags/sub/v0.1.0")},
+	}
+	apiTags := []*github.Reference{
+		{Ref: github.String("refs/tags/v0.18.0")},
+	}
+	reachable := map[string][]string{
+		"aaaaaaaaaaaa": {"v1.1.1"},
+		"bbbbbbbbbbbb": {},
+	}
+
+	tests := []struct {
+		name          string
+		gomod         string
+		ignorePaths   []string
+		methodErrors  map[string]bool
+		expected      []*pin
+		expectedError bool
+	}{
+		{
+			name:  "valid: commit reachable from the latest tag of an older release line",
+			gomod: "replace github.com/org/repo => github.com/org/repo v0.0.0-20200101000000-aaaaaaaaaaaa\n",
+			expected: []*pin{
+				{
+					Module:      "github.com/org/repo",
+					Replacement: "github.com/org/repo@v0.0.0-20200101000000-aaaaaaaaaaaa",
+					Repo:        "org/repo",
+					Commit:      "aaaaaaaaaaaa",
+					Tag:         "v1.1.1",
+					Status:      statusOK,
+				},
+			},
+		},
+		{
+			name:  "valid: commit not reachable from any tag",
+			gomod: "replace github.com/org/repo/v2 => github.com/org/repo/v2 v2.0.0-beta.0.0.20200101000000-bbbbbbbbbbbb\n",
+			expected: []*pin{
+				{
+					Module:      "github.com/org/repo/v2",
+					Replacement: "github.com/org/repo/v2@v2.0.0-beta.0.0.20200101000000-bbbbbbbbbbbb",
+					Repo:        "org/repo",
+					Commit:      "bbbbbbbbbbbb",
+					Status:      statusUnreachable,
+					Reason:      `commit "bbbbbbbbbbbb" is not reachable from any tag`,
+				},
+			},
+		},
+		{
+			name:  "valid: dangling commit",
+			gomod: "replace github.com/org/repo => github.com/org/repo v1.1.2-0.20200101000000-cccccccccccc\n",
+			expected: []*pin{
+				{
+					Module:      "github.com/org/repo",
+					Replacement: "github.com/org/repo@v1.1.2-0.20200101000000-cccccccccccc",
+					Repo:        "org/repo",
+					Commit:      "cccccccccccc",
+					Status:      statusNotFound,
+					Reason:      `commit "cccccccccccc" does not exist in repository "org/repo"`,
+				},
+			},
+		},
+		{
+			name: "valid: tagged versions",
+			gomod: `replace (
+	k8s.io/api => k8s.io/api v0.18.0
+	github.com/org/repo/sub => github.com/org/repo/sub v0.1.0
+	github.com/org/foo => github.com/org/repo v1.2.0
+)
+`,
+			expected: []*pin{
+				{
+					Module:      "k8s.io/api",
+					Replacement: "k8s.io/api@v0.18.0",
+					Repo:        "kubernetes/api",
+					Tag:         "v0.18.0",
+					Status:      statusOK,
+				},
+				{
+					Module:      "github.com/org/repo/sub",
+					Replacement: "github.com/org/repo/sub@v0.1.0",
+					Repo:        "org/repo",
+					Tag:         "sub/v0.1.0",
+					Status:      statusOK,
+				},
+				{
+					Module:      "github.com/org/foo",
+					Replacement: "github.com/org/repo@v1.2.0",
+					Repo:        "org/repo",
+					Status:      statusNotFound,
+					Reason:      `tag "v1.2.0" does not exist`,
+				},
+			},
+		},
+		{
+			name: "valid: local, ignored and unsupported replacements",
+			gomod: `replace (
+	k8s.io/api => ../api
+	github.com/org/repo => github.com/org/repo v0.0.0-20200101000000-cccccccccccc
+	example.com/foo => example.com/foo v1.0.0
+)
+`,
+			ignorePaths: []string{"github.com/org/repo"},
+			expected: []*pin{
+				{
+					Module:      "example.com/foo",
+					Replacement: "example.com/foo@v1.0.0",
+					Status:      statusSkipped,
+					Reason:      `cannot map "example.com/foo" to a GitHub repository`,
+				},
+			},
+		},
+		{
+			name:          "invalid: malformed go.mod",
+			gomod:         "replace github.com/org/repo =>\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: cannot get tags",
+			gomod:         "replace github.com/org/repo => github.com/org/repo v1.1.1\n",
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("%d.mod", i))
+			if err := ioutil.WriteFile(path, []byte("module example.com/test\n\n"+tt.gomod), 0600); err != nil {
+				t.Fatal(err)
+			}
+			data := &pkg.Data{Source: []string{path}, IgnorePaths: tt.ignorePaths}
+
+			// Create fake client and setup endpoint handlers.
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs",
+				pkg.NewReferenceHandler(&repoTags, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/kubernetes/api/git/refs",
+				pkg.NewReferenceHandler(&apiTags, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/repo/compare",
+				newCompareHandler(reachable))
+
+			r, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(r.Pins, tt.expected) {
+				t.Errorf("expected pins:\n%+v\ngot:\n%+v", tt.expected, r.Pins)
+			}
+		})
+	}
+}
+
+func TestModuleRepo(t *testing.T) {
+	tests := []struct {
+		path           string
+		expectedRepo   string
+		expectedPrefix string
+		expectedOK     bool
+	}{
+		{path: "github.com/org/repo", expectedRepo: "org/repo", expectedOK: true},
+		{path: "github.com/org/repo/v2", expectedRepo: "org/repo", expectedOK: true},
+		{path: "github.com/org/repo/sub/v3", expectedRepo: "org/repo", expectedPrefix: "sub/", expectedOK: true},
+		{path: "k8s.io/api", expectedRepo: "kubernetes/api", expectedOK: true},
+		{path: "sigs.k8s.io/yaml", expectedRepo: "kubernetes-sigs/yaml", expectedOK: true},
+		{path: "github.com/org"},
+		{path: "golang.org/x/mod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			repo, prefix, ok := moduleRepo(tt.path)
+			if repo != tt.expectedRepo || prefix != tt.expectedPrefix || ok != tt.expectedOK {
+				t.Errorf("expected %q, %q, %v, got %q, %q, %v",
+					tt.expectedRepo, tt.expectedPrefix, tt.expectedOK, repo, prefix, ok)
+			}
+		})
+	}
+}
+
+func TestPseudoVersionRev(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: "v0.0.0-20200101000000-0123456789ab", expected: "0123456789ab"},
+		{version: "v1.2.4-0.20200101000000-0123456789ab", expected: "0123456789ab"},
+		{version: "v1.2.3-rc.1.0.20200101000000-0123456789ab+incompatible", expected: "0123456789ab"},
+		{version: "v1.2.3"},
+		{version: "v1.2.3-rc.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if rev := pseudoVersionRev(tt.version); rev != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, rev)
+			}
+		})
+	}
+}
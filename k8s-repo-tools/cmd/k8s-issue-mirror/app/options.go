@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Source []string        `flag:"source" usage:"Source org/repo from which to take issues" validate:"required,repo"`
+	Dest   []string        `flag:"dest" usage:"Destination org/repo to mirror issues to" validate:"required,repo"`
+	Labels pkg.MultiString `flag:"label" usage:"Label that source issues must have to be mirrored. Multiple instances of the flag are allowed, in which case issues must have all labels" validate:"required"`
+	Output string          `flag:"output" usage:"Path to a file that will be written with the created and updated issues"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Mirroring issues to the same repository is a mistake.
+	if d.SourceRepo() == d.DestRepo() {
+		return errors.Errorf("the options %q and %q must not be the same", pkg.FlagSource, pkg.FlagDest)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// mirrorMarker is a hidden comment in the body of a mirrored issue that
+// links it to the source issue in the format 'org/repo#issue'.
+const mirrorMarker = "<!-- k8s-issue-mirror: %s -->"
+
+var regexpMirrorMarker = regexp.MustCompile(`<!-- k8s-issue-mirror: (\S+#\d+) -->`)
+
+// mirror is a source issue and its mirrored issue in the destination repository.
+type mirror struct {
+	Source string `json:"source"`
+	Dest   string `json:"dest,omitempty"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+}
+
+// result holds the mirrored issues that were created and updated.
+type result struct {
+	Created []*mirror `json:"created"`
+	Updated []*mirror `json:"updated"`
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*result, error) {
+
+	// Obtain the source issues that have all of the labels.
+	issuesSrc, err := pkg.GitHubGetIssues(d, d.SourceRepo(), d.Labels)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Logf("found %d issue(s) with labels %v in repository %q", len(issuesSrc), []string(d.Labels), d.SourceRepo())
+
+	// Find the issues in the destination that were already mirrored.
+	issuesDest, err := pkg.GitHubGetIssues(d, d.DestRepo(), nil)
+	if err != nil {
+		return nil, err
+	}
+	mirrored := map[string]*github.Issue{}
+	for _, issue := range issuesDest {
+		if match := regexpMirrorMarker.FindStringSubmatch(issue.GetBody()); match != nil {
+			mirrored[match[1]] = issue
+		}
+	}
+
+	var create, update []*github.Issue
+	for _, issue := range issuesSrc {
+		existing, found := mirrored[issueRef(d.SourceRepo(), issue.GetNumber())]
+		switch {
+		case !found && issue.GetState() == "open":
+			pkg.V(1).Logf("issue #%d is not mirrored in repository %q", issue.GetNumber(), d.DestRepo())
+			create = append(create, issue)
+		case found && (existing.GetTitle() != issue.GetTitle() || existing.GetState() != issue.GetState()):
+			pkg.V(1).Logf("issue #%d differs from its mirror #%d in repository %q", issue.GetNumber(), existing.GetNumber(), d.DestRepo())
+			update = append(update, issue)
+		}
+	}
+	res := &result{Created: []*mirror{}, Updated: []*mirror{}}
+	if len(create) == 0 && len(update) == 0 {
+		pkg.Logf("the issues of repository %q are in sync with %q", d.DestRepo(), d.SourceRepo())
+		return res, nil
+	}
+	pkg.Logf("found %d issue(s) to create and %d issue(s) to update in repository %q",
+		len(create), len(update), d.DestRepo())
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create %d and update %d issue(s) in repository %q?",
+		len(create), len(update), d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return res, nil
+	}
+
+	for _, issue := range create {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		created, err := pkg.GitHubCreateIssue(d, d.DestRepo(), issue.GetTitle(), mirrorBody(d.SourceRepo(), issue), nil, d.DryRun)
+		if err != nil {
+			return res, err
+		}
+		m := newMirror(d.SourceRepo(), issue)
+		if created.GetNumber() != 0 {
+			m.Dest = issueRef(d.DestRepo(), created.GetNumber())
+		}
+		res.Created = append(res.Created, m)
+	}
+	for _, issue := range update {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		number := mirrored[issueRef(d.SourceRepo(), issue.GetNumber())].GetNumber()
+		if err := pkg.GitHubEditIssue(d, d.DestRepo(), number, issue.GetTitle(), issue.GetState(), d.DryRun); err != nil {
+			return res, err
+		}
+		m := newMirror(d.SourceRepo(), issue)
+		m.Dest = issueRef(d.DestRepo(), number)
+		res.Updated = append(res.Updated, m)
+	}
+	return res, nil
+}
+
+// issueRef returns a reference to an issue in the format 'org/repo#issue'.
+func issueRef(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
+
+// newMirror returns a mirror for a source issue.
+func newMirror(repo string, issue *github.Issue) *mirror {
+	return &mirror{
+		Source: issueRef(repo, issue.GetNumber()),
+		Title:  issue.GetTitle(),
+		State:  issue.GetState(),
+	}
+}
+
+// mirrorBody returns the body of a mirrored issue, which holds a link back to
+// the source issue and the marker that is used to find the mirror on later runs.
+// The body of the source issue is not copied, so that mentions in it do not
+// notify users again.
+func mirrorBody(repo string, issue *github.Issue) string {
+	url := issue.GetHTMLURL()
+	if len(url) == 0 {
+		url = fmt.Sprintf("https://github.com/%s/issues/%d", repo, issue.GetNumber())
+	}
+	return fmt.Sprintf("Mirrored from %s\n\n"+mirrorMarker+"\n", url, issueRef(repo, issue.GetNumber()))
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	newIssue := func(number int, title, state, body string, labels ...string) *github.Issue {
+		issue := &github.Issue{
+			Number: github.Int(number),
+			Title:  github.String(title),
+			State:  github.String(state),
+			Body:   github.String(body),
+		}
+		for _, l := range labels {
+			issue.Labels = append(issue.Labels, github.Label{Name: github.String(l)})
+		}
+		return issue
+	}
+	const blocker = "release-blocker"
+	issuesSrc := []*github.Issue{
+		newIssue(1, "foo is broken", "open", "", blocker),
+		newIssue(2, "bar is broken", "closed", "", blocker),
+		newIssue(3, "baz is slow", "open", ""),
+		newIssue(4, "qux is broken", "open", "", blocker, "sig/foo"),
+	}
+
+	tests := []struct {
+		name               string
+		issuesDest         []*github.Issue
+		methodErrorsDest   map[string]bool
+		expectedResult     *result
+		expectedIssuesDest []*github.Issue
+		expectedError      bool
+		skipDryRun         bool
+	}{
+		{
+			name: "valid: create missing mirrors of open issues and update changed mirrors",
+			issuesDest: []*github.Issue{
+				newIssue(1, "unrelated", "open", ""),
+				newIssue(2, "bar is broken", "open", mirrorBody("org/src", issuesSrc[1])),
+			},
+			expectedResult: &result{
+				Created: []*mirror{
+					{Source: "org/src#1", Dest: "org/dest#3", Title: "foo is broken", State: "open"},
+					{Source: "org/src#4", Dest: "org/dest#4", Title: "qux is broken", State: "open"},
+				},
+				Updated: []*mirror{
+					{Source: "org/src#2", Dest: "org/dest#2", Title: "bar is broken", State: "closed"},
+				},
+			},
+			expectedIssuesDest: []*github.Issue{
+				newIssue(1, "unrelated", "open", ""),
+				newIssue(2, "bar is broken", "closed", mirrorBody("org/src", issuesSrc[1])),
+				newIssue(3, "foo is broken", "open", mirrorBody("org/src", issuesSrc[0])),
+				newIssue(4, "qux is broken", "open", mirrorBody("org/src", issuesSrc[3])),
+			},
+		},
+		{
+			name: "valid: mirrors are in sync",
+			issuesDest: []*github.Issue{
+				newIssue(7, "foo is broken", "open", mirrorBody("org/src", issuesSrc[0])),
+				newIssue(8, "bar is broken", "closed", mirrorBody("org/src", issuesSrc[1])),
+				newIssue(9, "qux is broken", "open", mirrorBody("org/src", issuesSrc[3])),
+			},
+			expectedResult: &result{Created: []*mirror{}, Updated: []*mirror{}},
+			expectedIssuesDest: []*github.Issue{
+				newIssue(7, "foo is broken", "open", mirrorBody("org/src", issuesSrc[0])),
+				newIssue(8, "bar is broken", "closed", mirrorBody("org/src", issuesSrc[1])),
+				newIssue(9, "qux is broken", "open", mirrorBody("org/src", issuesSrc[3])),
+			},
+		},
+		{
+			name:             "invalid: simulated error when getting the destination issues",
+			issuesDest:       []*github.Issue{},
+			methodErrorsDest: map[string]bool{http.MethodGet: true},
+			expectedError:    true,
+		},
+		{
+			name:             "invalid: simulated error when creating an issue",
+			issuesDest:       []*github.Issue{},
+			methodErrorsDest: map[string]bool{http.MethodPost: true},
+			expectedError:    true,
+			skipDryRun:       true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Source: []string{"org/src"},
+					Dest:   []string{"org/dest"},
+					Labels: []string{blocker},
+					DryRun: dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handlers modify it.
+				src := append([]*github.Issue{}, issuesSrc...)
+				dest := []*github.Issue{}
+				for _, issue := range tt.issuesDest {
+					copied := *issue
+					dest = append(dest, &copied)
+				}
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/src/issues",
+					pkg.NewIssueHandler(&src, nil))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/issues",
+					pkg.NewIssueHandler(&dest, tt.methodErrorsDest))
+
+				res, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+
+				// In dry-run mode the numbers of created issues are not known
+				// and the destination issues are not changed.
+				expectedResult, expectedIssuesDest := tt.expectedResult, tt.expectedIssuesDest
+				if dryRunVal {
+					expectedResult = &result{Created: []*mirror{}, Updated: expectedResult.Updated}
+					for _, m := range tt.expectedResult.Created {
+						copied := *m
+						copied.Dest = ""
+						expectedResult.Created = append(expectedResult.Created, &copied)
+					}
+					expectedIssuesDest = tt.issuesDest
+				}
+				if !reflect.DeepEqual(res, expectedResult) {
+					t.Errorf("expected result:\n%+v\ngot:\n%+v", expectedResult, res)
+				}
+				if !reflect.DeepEqual(dest, expectedIssuesDest) {
+					t.Errorf("expected destination issues:\n%+v\ngot:\n%+v", expectedIssuesDest, dest)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The labels are taken from the source
+// repository or from the label spec.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Source      []string `flag:"source" usage:"Source org/repo from which to take labels" validate:"requiredunless=label-spec,repo"`
+	LabelSpec   string   `flag:"label-spec" usage:"Path or URL to a YAML file with the list of labels to use instead of the labels of \"source\""`
+	Dest        []string `flag:"dest" usage:"Comma separated list of destination org/repo to write labels to" validate:"required,repos"`
+	Prune       bool     `flag:"prune" usage:"Delete labels that are not in the source repository or the label spec"`
+	Output      string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+	Parallelism int      `flag:"parallelism" default:"1" usage:"Number of repositories to process in parallel. With a value greater than 1 a failing repository does not stop the others" validate:"nonnegative"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the source of the labels.
+	if len(d.Source) != 0 && len(d.LabelSpec) != 0 {
+		return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagSource, pkg.FlagLabelSpec)
+	}
+
+	// Validate the number of repositories to process in parallel.
+	if err := pkg.ValidateParallelism(d); err != nil {
+		return err
+	}
+
+	return nil
+}
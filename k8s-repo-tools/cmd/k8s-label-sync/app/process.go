@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// repoResult holds the names of the labels that were created, updated and deleted
+// in a destination repository.
+type repoResult struct {
+	Repo    string   `json:"repo"`
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// labelUpdate is an existing label and the label it should be updated to.
+type labelUpdate struct {
+	existing *github.Label
+	label    *github.Label
+}
+
+// process is responsible for all operations that the application performs.
+// It returns a result for every destination repository that was changed.
+func process(d *pkg.Data) ([]*repoResult, error) {
+
+	// Obtain the labels from the label spec or from the source repository.
+	var labelsSrc []*github.Label
+	if len(d.LabelSpec) != 0 {
+		pkg.Logf("reading the label spec from %q", d.LabelSpec)
+		data, err := pkg.ReadFromFileOrURL(d.LabelSpec, d.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if labelsSrc, err = parseLabelSpec(data); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if labelsSrc, err = pkg.GitHubGetLabels(d, d.SourceRepo()); err != nil {
+			return nil, err
+		}
+	}
+	pkg.Logf("found %d source label(s)", len(labelsSrc))
+
+	dests := d.Dest
+	repoResults := make([]*repoResult, len(dests))
+	err := pkg.ForEachRepo(d, dests, func(i int, dest string, log *pkg.Logger) error {
+		var err error
+		repoResults[i], err = syncLabels(d, dest, labelsSrc, log)
+		return err
+	})
+	results := []*repoResult{}
+	for _, res := range repoResults {
+		if res != nil {
+			results = append(results, res)
+		}
+	}
+	return results, err
+}
+
+// syncLabels creates the source labels that are missing in a destination repository
+// and updates the ones that differ. If d.Prune is set labels that are not in the
+// source are deleted. The changes are printed as a diff before they are applied.
+func syncLabels(d *pkg.Data, dest string, labelsSrc []*github.Label, log *pkg.Logger) (*repoResult, error) {
+	labelsDest, err := pkg.GitHubGetLabels(d, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Label names are case insensitive.
+	byName := map[string]*github.Label{}
+	for _, l := range labelsDest {
+		byName[strings.ToLower(l.GetName())] = l
+	}
+	inSource := map[string]bool{}
+
+	var create, remove []*github.Label
+	var update []labelUpdate
+	for _, l := range labelsSrc {
+		inSource[strings.ToLower(l.GetName())] = true
+		existing, found := byName[strings.ToLower(l.GetName())]
+		switch {
+		case !found:
+			create = append(create, l)
+		case !labelsEqual(l, existing):
+			update = append(update, labelUpdate{existing: existing, label: l})
+		}
+	}
+	if d.Prune {
+		for _, l := range labelsDest {
+			if !inSource[strings.ToLower(l.GetName())] {
+				remove = append(remove, l)
+			}
+		}
+	}
+	if len(create) == 0 && len(update) == 0 && len(remove) == 0 {
+		log.Logf("the labels of repository %q are in sync", dest)
+		return nil, nil
+	}
+
+	// Print the diff. The lines have the repository as a field, since the
+	// diffs of repositories that are processed in parallel can interleave.
+	pkg.PrintSeparator()
+	log.Logf("label changes for repository %q:", dest)
+	for _, l := range create {
+		log.Logf("+ %s (#%s) %q", l.GetName(), l.GetColor(), l.GetDescription())
+	}
+	for _, u := range update {
+		log.Logf("~ %s (#%s) %q -> %s (#%s) %q", u.existing.GetName(), u.existing.GetColor(), u.existing.GetDescription(),
+			u.label.GetName(), u.label.GetColor(), u.label.GetDescription())
+	}
+	for _, l := range remove {
+		log.Logf("- %s (#%s) %q", l.GetName(), l.GetColor(), l.GetDescription())
+	}
+	pkg.PrintSeparator()
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create %d, update %d and delete %d label(s) in repository %q?",
+		len(create), len(update), len(remove), dest)
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return nil, nil
+	}
+
+	res := &repoResult{Repo: dest, Created: []string{}, Updated: []string{}, Deleted: []string{}}
+	for _, l := range create {
+		if err := pkg.GitHubCreateLabel(d, dest, l, d.DryRun); err != nil {
+			return res, err
+		}
+		res.Created = append(res.Created, l.GetName())
+	}
+	for _, u := range update {
+		if err := pkg.GitHubEditLabel(d, dest, u.existing.GetName(), u.label, d.DryRun); err != nil {
+			return res, err
+		}
+		res.Updated = append(res.Updated, u.label.GetName())
+	}
+	for _, l := range remove {
+		if err := pkg.GitHubDeleteLabel(d, dest, l.GetName(), d.DryRun); err != nil {
+			return res, err
+		}
+		res.Deleted = append(res.Deleted, l.GetName())
+	}
+	return res, nil
+}
+
+// labelsEqual returns true if two labels have the same name, color and description.
+// Colors are compared case insensitively.
+func labelsEqual(a, b *github.Label) bool {
+	return a.GetName() == b.GetName() &&
+		strings.EqualFold(a.GetColor(), b.GetColor()) &&
+		a.GetDescription() == b.GetDescription()
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-label-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	specPath := filepath.Join(dir, "labels.yaml")
+	const spec = "labels:\n- name: kind/bug\n  color: e11d21\n- name: lgtm\n  color: 15dd18\n"
+	if err := ioutil.WriteFile(specPath, []byte(spec), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newLabel := func(name, color, description string) *github.Label {
+		return &github.Label{Name: github.String(name), Color: github.String(color), Description: github.String(description)}
+	}
+	labelsSrc := []*github.Label{
+		newLabel("kind/bug", "e11d21", ""),
+		newLabel("lgtm", "15dd18", ""),
+	}
+
+	tests := []struct {
+		name             string
+		labelSpec        string
+		prune            bool
+		labelsDest       []*github.Label
+		methodErrorsSrc  map[string]bool
+		methodErrorsDest map[string]bool
+		expectedResults  []*repoResult
+		expectedLabels   []*github.Label
+		expectedError    bool
+		skipDryRun       bool
+		prompter         pkg.Prompter
+	}{
+		{
+			name: "valid: create missing and update different labels",
+			labelsDest: []*github.Label{
+				newLabel("LGTM", "15DD18", ""),
+				newLabel("old", "ffffff", ""),
+			},
+			expectedResults: []*repoResult{
+				{Repo: "org/dest", Created: []string{"kind/bug"}, Updated: []string{"lgtm"}, Deleted: []string{}},
+			},
+			expectedLabels: []*github.Label{
+				newLabel("lgtm", "15dd18", ""),
+				newLabel("old", "ffffff", ""),
+				newLabel("kind/bug", "e11d21", ""),
+			},
+		},
+		{
+			name:  "valid: prune labels that are not in the source",
+			prune: true,
+			labelsDest: []*github.Label{
+				newLabel("lgtm", "15dd18", ""),
+				newLabel("old", "ffffff", ""),
+				newLabel("kind/bug", "E11D21", ""),
+			},
+			expectedResults: []*repoResult{
+				{Repo: "org/dest", Created: []string{}, Updated: []string{}, Deleted: []string{"old"}},
+			},
+			expectedLabels: []*github.Label{
+				newLabel("lgtm", "15dd18", ""),
+				newLabel("kind/bug", "E11D21", ""),
+			},
+		},
+		{
+			name:      "valid: labels from a label spec are in sync",
+			labelSpec: specPath,
+			labelsDest: []*github.Label{
+				newLabel("kind/bug", "e11d21", ""),
+				newLabel("lgtm", "15dd18", ""),
+			},
+			expectedResults: []*repoResult{},
+			expectedLabels: []*github.Label{
+				newLabel("kind/bug", "e11d21", ""),
+				newLabel("lgtm", "15dd18", ""),
+			},
+		},
+		{
+			name:       "valid: confirmed prompt",
+			labelsDest: []*github.Label{},
+			prompter:   pkg.PrompterYes,
+			expectedResults: []*repoResult{
+				{Repo: "org/dest", Created: []string{"kind/bug", "lgtm"}, Updated: []string{}, Deleted: []string{}},
+			},
+			expectedLabels: []*github.Label{
+				newLabel("kind/bug", "e11d21", ""),
+				newLabel("lgtm", "15dd18", ""),
+			},
+		},
+		{
+			name:            "valid: declined prompt",
+			labelsDest:      []*github.Label{newLabel("old", "ffffff", "")},
+			prune:           true,
+			prompter:        pkg.PrompterNo,
+			expectedResults: []*repoResult{},
+			expectedLabels:  []*github.Label{newLabel("old", "ffffff", "")},
+		},
+		{
+			name:            "invalid: simulated error when getting the source labels",
+			labelsDest:      []*github.Label{},
+			methodErrorsSrc: map[string]bool{http.MethodGet: true},
+			expectedError:   true,
+		},
+		{
+			name:             "invalid: simulated error when deleting a label",
+			prune:            true,
+			labelsDest:       []*github.Label{newLabel("old", "ffffff", "")},
+			methodErrorsDest: map[string]bool{http.MethodDelete: true},
+			expectedError:    true,
+			skipDryRun:       true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Dest:      []string{"org/dest"},
+					LabelSpec: tt.labelSpec,
+					Prune:     tt.prune,
+					DryRun:    dryRunVal,
+				}
+				if len(tt.labelSpec) == 0 {
+					data.Source = []string{"org/src"}
+				}
+				if tt.prompter == nil {
+					tt.prompter = pkg.PrompterYes
+				}
+				data.SetPrompter(tt.prompter)
+
+				// Copy the test data, since the handlers modify it.
+				src := append([]*github.Label{}, labelsSrc...)
+				labels := append([]*github.Label{}, tt.labelsDest...)
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/src/labels",
+					pkg.NewLabelHandler(&src, tt.methodErrorsSrc))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/labels",
+					pkg.NewLabelHandler(&labels, tt.methodErrorsDest))
+
+				results, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(results, tt.expectedResults) {
+					t.Errorf("expected results:\n%+v\ngot:\n%+v", tt.expectedResults, results)
+				}
+
+				// In dry-run mode the labels are not changed.
+				expectedLabels := tt.expectedLabels
+				if dryRunVal {
+					expectedLabels = tt.labelsDest
+				}
+				if !reflect.DeepEqual(labels, expectedLabels) {
+					t.Errorf("expected labels:\n%v\ngot:\n%v", expectedLabels, labels)
+				}
+			})
+		}
+	}
+}
+
+func TestProcessParallel(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	src := []*github.Label{{Name: github.String("lgtm"), Color: github.String("15dd18")}}
+	dests := map[string]*[]*github.Label{
+		"org/a": {},
+		"org/b": {},
+		"org/c": {},
+	}
+	data := &pkg.Data{
+		Source:      []string{"org/src"},
+		Dest:        []string{"org/a", "org/b", "org/c"},
+		Parallelism: 2,
+	}
+	data.SetPrompter(pkg.PrompterYes)
+	pkg.NewClient(data, pkg.NewTransport())
+	data.Transport.SetHandler("https://api.github.com/repos/org/src/labels", pkg.NewLabelHandler(&src, nil))
+	for repo, labels := range dests {
+		// Listing the labels of "org/b" fails, which must not stop the other repositories.
+		methodErrors := map[string]bool{http.MethodGet: repo == "org/b"}
+		data.Transport.SetHandler("https://api.github.com/repos/"+repo+"/labels", pkg.NewLabelHandler(labels, methodErrors))
+	}
+
+	results, err := process(data)
+	if err == nil || !strings.Contains(err.Error(), `repository "org/b"`) {
+		t.Errorf("expected an error for repository %q, got: %v", "org/b", err)
+	}
+	expectedResults := []*repoResult{
+		{Repo: "org/a", Created: []string{"lgtm"}, Updated: []string{}, Deleted: []string{}},
+		{Repo: "org/c", Created: []string{"lgtm"}, Updated: []string{}, Deleted: []string{}},
+	}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("expected results:\n%+v\ngot:\n%+v", expectedResults, results)
+	}
+}
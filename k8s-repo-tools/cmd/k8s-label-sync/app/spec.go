@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// colorRegexp matches the six character HEX color of a label.
+var colorRegexp = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// labelSpec is the structure of the YAML file passed with
+// the --label-spec flag.
+type labelSpec struct {
+	Labels []labelSpecItem `json:"labels"`
+}
+
+// labelSpecItem is a single label in a label spec.
+type labelSpecItem struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+// parseLabelSpec parses and validates the contents of a label spec.
+func parseLabelSpec(data []byte) ([]*github.Label, error) {
+	spec := &labelSpec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, errors.Wrap(err, "cannot parse label spec")
+	}
+	if len(spec.Labels) == 0 {
+		return nil, errors.New("the label spec must contain at least one label")
+	}
+	labels := make([]*github.Label, 0, len(spec.Labels))
+	seen := map[string]bool{}
+	for i, l := range spec.Labels {
+		if len(l.Name) == 0 {
+			return nil, errors.Errorf("label %d in the label spec must have a 'name'", i)
+		}
+		// Label names are case insensitive.
+		if seen[strings.ToLower(l.Name)] {
+			return nil, errors.Errorf("duplicate label %q in the label spec", l.Name)
+		}
+		seen[strings.ToLower(l.Name)] = true
+		color := strings.TrimPrefix(l.Color, "#")
+		if !colorRegexp.MatchString(color) {
+			return nil, errors.Errorf("label %q in the label spec must have a six character HEX 'color'", l.Name)
+		}
+		labels = append(labels, &github.Label{
+			Name:        github.String(l.Name),
+			Color:       github.String(strings.ToLower(color)),
+			Description: github.String(l.Description),
+		})
+	}
+	return labels, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestParseLabelSpec(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           []byte
+		expectedLabels []*github.Label
+		expectedError  bool
+	}{
+		{
+			name: "valid: labels with and without descriptions",
+			data: []byte(`
+labels:
+- name: kind/bug
+  color: "#E11D21"
+  description: Categorizes issue or PR as related to a bug.
+- name: lgtm
+  color: 15dd18
+`),
+			expectedLabels: []*github.Label{
+				{
+					Name:        github.String("kind/bug"),
+					Color:       github.String("e11d21"),
+					Description: github.String("Categorizes issue or PR as related to a bug."),
+				},
+				{
+					Name:        github.String("lgtm"),
+					Color:       github.String("15dd18"),
+					Description: github.String(""),
+				},
+			},
+		},
+		{
+			name:          "invalid: no labels",
+			data:          []byte("labels: []"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown field",
+			data:          []byte("labels:\n- name: lgtm\n  color: 15dd18\n  foo: bar\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing name",
+			data:          []byte("labels:\n- color: 15dd18\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed color",
+			data:          []byte("labels:\n- name: lgtm\n  color: green\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: duplicate names with different case",
+			data:          []byte("labels:\n- name: lgtm\n  color: 15dd18\n- name: LGTM\n  color: 15dd18\n"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := parseLabelSpec(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(labels, tt.expectedLabels) {
+				t.Errorf("expected labels:\n%v\ngot:\n%v", tt.expectedLabels, labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	bumpPatch      = "patch"
+	bumpMinor      = "minor"
+	bumpPreRelease = "prerelease"
+)
+
+var bumpTypes = []string{bumpPatch, bumpMinor, bumpPreRelease}
+
+// bumpVersion returns the next version to cut after the given tag.
+//   - patch: v1.17.3 -> v1.17.4. A pre-release becomes its release: v1.17.4-rc.1 -> v1.17.4.
+//   - minor: v1.17.3 -> v1.18.0. A pre-release of a .0 version becomes its release: v1.18.0-rc.1 -> v1.18.0.
+//   - prerelease: v1.18.0-beta.1 -> v1.18.0-beta.2. The tag must be a pre-release.
+func bumpVersion(tag, bump string) (string, error) {
+	v, err := pkg.TagToVersion(tag)
+	if err != nil {
+		return "", err
+	}
+
+	var next *version.Version
+	switch bump {
+	case bumpPatch:
+		next = pkg.NextPatch(v)
+	case bumpMinor:
+		next = pkg.NextMinor(v)
+	case bumpPreRelease:
+		if next, err = pkg.NextPreRelease(v); err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.Errorf("unknown bump type %q, must be one of %v", bump, bumpTypes)
+	}
+
+	var prefix string
+	if strings.HasPrefix(tag, "v") {
+		prefix = "v"
+	}
+	return prefix + next.String(), nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		tag            string
+		bump           string
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name:           "valid: bump patch",
+			tag:            "v1.17.3",
+			bump:           bumpPatch,
+			expectedOutput: "v1.17.4",
+		},
+		{
+			name:           "valid: bump patch of a pre-release",
+			tag:            "v1.17.4-rc.1",
+			bump:           bumpPatch,
+			expectedOutput: "v1.17.4",
+		},
+		{
+			name:           "valid: bump minor",
+			tag:            "v1.17.3",
+			bump:           bumpMinor,
+			expectedOutput: "v1.18.0",
+		},
+		{
+			name:           "valid: bump minor of a .0 pre-release",
+			tag:            "v1.18.0-rc.1",
+			bump:           bumpMinor,
+			expectedOutput: "v1.18.0",
+		},
+		{
+			name:           "valid: bump minor of a patch pre-release",
+			tag:            "v1.17.4-rc.1",
+			bump:           bumpMinor,
+			expectedOutput: "v1.18.0",
+		},
+		{
+			name:           "valid: bump pre-release",
+			tag:            "v1.18.0-beta.1",
+			bump:           bumpPreRelease,
+			expectedOutput: "v1.18.0-beta.2",
+		},
+		{
+			name:           "valid: preserve the missing 'v' prefix",
+			tag:            "1.17.3",
+			bump:           bumpPatch,
+			expectedOutput: "1.17.4",
+		},
+		{
+			name:          "invalid: bump pre-release of a release",
+			tag:           "v1.17.3",
+			bump:          bumpPreRelease,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: pre-release without a number",
+			tag:           "v1.18.0-beta",
+			bump:          bumpPreRelease,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown bump type",
+			tag:           "v1.17.3",
+			bump:          "major",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: tag is not SemVer",
+			tag:           "foo",
+			bump:          bumpPatch,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := bumpVersion(tt.tag, tt.bump)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if output != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
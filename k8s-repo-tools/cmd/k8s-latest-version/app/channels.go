@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	channelStable = "stable"
+	channelLatest = "latest"
+)
+
+// computeChannels computes the dl.k8s.io style channel markers from a list of tags.
+// The result maps marker names such as "stable", "latest", "stable-1.17" and
+// "latest-1.17" to tags. "stable" markers skip pre-releases, while "latest"
+// markers include them.
+func computeChannels(lines []string) (map[string]string, error) {
+	markers := map[string]string{}
+	versions := map[string]*version.Version{}
+
+	update := func(name, tag string, v *version.Version) {
+		if cur, ok := versions[name]; !ok || cur.LessThan(v) {
+			versions[name] = v
+			markers[name] = tag
+		}
+	}
+
+	for _, line := range lines {
+		v, err := pkg.TagToVersion(line)
+		if err != nil {
+			pkg.Warningf(err.Error())
+			continue
+		}
+		minor := fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+		update(channelLatest, line, v)
+		update(channelLatest+"-"+minor, line, v)
+		if len(v.PreRelease()) == 0 {
+			update(channelStable, line, v)
+			update(channelStable+"-"+minor, line, v)
+		}
+	}
+
+	if len(markers) == 0 {
+		return nil, errors.New("could not find any SemVer tags in the given input")
+	}
+	return markers, nil
+}
+
+// writeChannels writes each channel marker to a "<name>.txt" file in dir
+// and returns the sorted list of written "<name>.txt <tag>" pairs.
+func writeChannels(dir string, markers map[string]string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "could not create the directory %q", dir)
+	}
+
+	names := make([]string, 0, len(markers))
+	for name := range markers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		file := name + ".txt"
+		path := filepath.Join(dir, file)
+		pkg.Logf("writing %q to %q", markers[name], path)
+		if err := ioutil.WriteFile(path, []byte(markers[name]+"\n"), 0644); err != nil {
+			return nil, errors.Wrapf(err, "could not write the channel marker %q", path)
+		}
+		result = append(result, file+" "+markers[name])
+	}
+	return result, nil
+}
+
+// publishChannels publishes each channel marker as a "<name>.txt" object under the
+// bucket path prefix, such as "gs://bucket/release". The published markers are read
+// first: markers that did not change are not written again, and no marker is written
+// if a marker would move to an older version. Every written marker is read back to
+// verify it. It returns the sorted list of "<name>.txt <old> -> <new>" changes, where
+// <old> is "-" for a new marker, and "<name>.txt <tag> (unchanged)" for the others.
+func publishChannels(prefix string, markers map[string]string, dryRun bool) ([]string, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	names := make([]string, 0, len(markers))
+	for name := range markers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type change struct {
+		file, path, old, tag string
+	}
+	changes := []change{}
+	result := []string{}
+	regressions := []string{}
+	for _, name := range names {
+		c := change{file: name + ".txt", old: "-", tag: markers[name]}
+		c.path = prefix + "/" + c.file
+		buf, err := pkg.ReadStorageFile(c.path)
+		switch {
+		case stderrors.Is(err, pkg.ErrNotFound):
+			pkg.V(1).Logf("the channel marker %q was not published yet", c.path)
+		case err != nil:
+			return nil, err
+		default:
+			c.old = strings.TrimSpace(string(buf))
+			oldV, err := pkg.TagToVersion(c.old)
+			if err != nil {
+				return nil, errors.Wrapf(err, "the published channel marker %q is not valid", c.path)
+			}
+			newV, err := pkg.TagToVersion(c.tag)
+			if err != nil {
+				return nil, err
+			}
+			if c.old == c.tag {
+				result = append(result, c.file+" "+c.tag+" (unchanged)")
+				continue
+			}
+			if newV.LessThan(oldV) {
+				regressions = append(regressions, fmt.Sprintf("%s: %s -> %s", c.file, c.old, c.tag))
+				continue
+			}
+		}
+		changes = append(changes, c)
+	}
+	if len(regressions) != 0 {
+		return nil, errors.Errorf("refusing to publish channel markers that are older than the published markers:\n%s",
+			strings.Join(regressions, "\n"))
+	}
+
+	for _, c := range changes {
+		if dryRun {
+			pkg.Logf("%s: would write %q to %q", pkg.PrefixDryRun, c.tag, c.path)
+		} else {
+			pkg.Logf("writing %q to %q", c.tag, c.path)
+			if err := pkg.WriteTextFile(c.path, []byte(c.tag+"\n")); err != nil {
+				return nil, err
+			}
+			buf, err := pkg.ReadStorageFile(c.path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not verify the channel marker %q", c.path)
+			}
+			if got := strings.TrimSpace(string(buf)); got != c.tag {
+				return nil, pkg.NewErrorf(pkg.ErrorKindVerificationFailed,
+					"the published channel marker %q contains %q instead of %q", c.path, got, c.tag)
+			}
+		}
+		result = append(result, fmt.Sprintf("%s %s -> %s", c.file, c.old, c.tag))
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestComputeChannels(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name            string
+		input           []string
+		expectedMarkers map[string]string
+		expectedError   bool
+	}{
+		{
+			name: "valid: compute stable and latest markers",
+			input: []string{
+				"v1.16.2",
+				"v1.17.3",
+				"foo",
+				"v1.17.4-rc.1",
+				"v1.18.0-alpha.1",
+				"v1.16.3-beta.0",
+			},
+			expectedMarkers: map[string]string{
+				"stable":      "v1.17.3",
+				"latest":      "v1.18.0-alpha.1",
+				"stable-1.16": "v1.16.2",
+				"latest-1.16": "v1.16.3-beta.0",
+				"stable-1.17": "v1.17.3",
+				"latest-1.17": "v1.17.4-rc.1",
+				"latest-1.18": "v1.18.0-alpha.1",
+			},
+		},
+		{
+			name:          "invalid: no SemVer tags",
+			input:         []string{"foo"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markers, err := computeChannels(tt.input)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(markers, tt.expectedMarkers) {
+				t.Errorf("expected markers %v, got %v", tt.expectedMarkers, markers)
+			}
+		})
+	}
+}
+
+func TestWriteChannels(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-latest-version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	markers := map[string]string{
+		"stable":      "v1.17.3",
+		"latest-1.18": "v1.18.0-alpha.1",
+	}
+	output, err := writeChannels(filepath.Join(dir, "channels"), markers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedOutput := []string{"latest-1.18.txt v1.18.0-alpha.1", "stable.txt v1.17.3"}
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("expected output %v, got %v", expectedOutput, output)
+	}
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "channels", "stable.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(buf) != "v1.17.3\n" {
+		t.Errorf("expected contents %q, got %q", "v1.17.3\n", buf)
+	}
+}
+
+// fakeGCS is a minimal GCS emulator that stores objects in memory.
+type fakeGCS struct {
+	sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	switch req.Method {
+	case http.MethodGet:
+		name, _ := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), "/storage/v1/b/bucket/o/"))
+		content, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(content))
+	case http.MethodPost:
+		buf, _ := ioutil.ReadAll(req.Body)
+		f.objects[req.URL.Query().Get("name")] = string(buf)
+	}
+}
+
+func TestPublishChannels(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	f := &fakeGCS{objects: map[string]string{}}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	defer func(host string, ok bool) {
+		if ok {
+			os.Setenv("STORAGE_EMULATOR_HOST", host)
+		} else {
+			os.Unsetenv("STORAGE_EMULATOR_HOST")
+		}
+	}(os.LookupEnv("STORAGE_EMULATOR_HOST"))
+	os.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+
+	tests := []struct {
+		name            string
+		published       map[string]string
+		markers         map[string]string
+		dryRun          bool
+		expectedOutput  []string
+		expectedObjects map[string]string
+		expectedError   bool
+	}{
+		{
+			name:            "valid: nothing is written in dry-run mode",
+			markers:         map[string]string{"stable": "v1.17.3"},
+			dryRun:          true,
+			expectedOutput:  []string{"stable.txt - -> v1.17.3"},
+			expectedObjects: map[string]string{},
+		},
+		{
+			name:      "valid: new and newer markers are published",
+			published: map[string]string{"release/stable.txt": "v1.17.2\n", "release/latest.txt": "v1.18.0-alpha.1"},
+			markers:   map[string]string{"stable": "v1.17.3", "latest": "v1.18.0-alpha.1", "latest-1.18": "v1.18.0-alpha.1"},
+			expectedOutput: []string{
+				"latest-1.18.txt - -> v1.18.0-alpha.1",
+				"latest.txt v1.18.0-alpha.1 (unchanged)",
+				"stable.txt v1.17.2 -> v1.17.3",
+			},
+			expectedObjects: map[string]string{
+				"release/stable.txt":      "v1.17.3\n",
+				"release/latest.txt":      "v1.18.0-alpha.1",
+				"release/latest-1.18.txt": "v1.18.0-alpha.1\n",
+			},
+		},
+		{
+			name:            "invalid: a marker would move to an older version",
+			published:       map[string]string{"release/stable.txt": "v1.17.4\n"},
+			markers:         map[string]string{"stable": "v1.17.3", "latest": "v1.17.3"},
+			expectedObjects: map[string]string{"release/stable.txt": "v1.17.4\n"},
+			expectedError:   true,
+		},
+		{
+			name:            "invalid: a published marker is not a version",
+			published:       map[string]string{"release/stable.txt": "<html>"},
+			markers:         map[string]string{"stable": "v1.17.3"},
+			expectedObjects: map[string]string{"release/stable.txt": "<html>"},
+			expectedError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.objects = map[string]string{}
+			for k, v := range tt.published {
+				f.objects[k] = v
+			}
+			output, err := publishChannels("gs://bucket/release/", tt.markers, tt.dryRun)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !tt.expectedError && !reflect.DeepEqual(output, tt.expectedOutput) {
+				t.Errorf("expected output %v, got %v", tt.expectedOutput, output)
+			}
+			if !reflect.DeepEqual(f.objects, tt.expectedObjects) {
+				t.Errorf("expected objects %v, got %v", tt.expectedObjects, f.objects)
+			}
+		})
+	}
+}
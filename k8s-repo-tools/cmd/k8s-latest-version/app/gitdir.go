@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const refsTagsPrefix = "refs/tags/"
+
+// readGitDirTags returns the sorted list of tag names in a local git repository.
+// The path can be a working tree that contains a .git directory or a bare repository.
+// Both loose refs and packed refs are read directly, without calling the git binary.
+func readGitDirTags(path string) ([]string, error) {
+	gitDir := filepath.Join(path, ".git")
+	if fi, err := os.Stat(gitDir); err != nil || !fi.IsDir() {
+		gitDir = path
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, errors.Errorf("%q is not a git repository", path)
+	}
+
+	tags := map[string]struct{}{}
+
+	// Read the loose tag refs.
+	tagsDir := filepath.Join(gitDir, filepath.FromSlash(refsTagsPrefix))
+	err := filepath.Walk(tagsDir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(tagsDir, p)
+		if err != nil {
+			return err
+		}
+		tags[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the tags in %q", tagsDir)
+	}
+
+	// Read the packed tag refs.
+	if err := readPackedTags(filepath.Join(gitDir, "packed-refs"), tags); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(tags))
+	for t := range tags {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// readPackedTags adds the tag names from a packed-refs file to a set of tags.
+// A missing packed-refs file is not an error.
+func readPackedTags(path string, tags map[string]struct{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are of the format "<sha> <ref>". Skip comments and
+		// peeled tag lines that start with '^'.
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], refsTagsPrefix) {
+			continue
+		}
+		tags[strings.TrimPrefix(fields[1], refsTagsPrefix)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "could not read %q", path)
+	}
+	return nil
+}
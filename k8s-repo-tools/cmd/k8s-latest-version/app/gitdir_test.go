@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadGitDirTags(t *testing.T) {
+	const sha = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+	packedRefs := "# pack-refs with: peeled fully-peeled sorted\n" +
+		sha + " refs/heads/master\n" +
+		sha + " refs/tags/v1.16.0\n" +
+		"^" + sha + "\n" +
+		sha + " refs/tags/v1.17.0\n"
+
+	tests := []struct {
+		name           string
+		bare           bool
+		looseTags      []string
+		packedRefs     string
+		noRepository   bool
+		expectedOutput []string
+		expectedError  bool
+	}{
+		{
+			name:           "valid: loose and packed tags in a working tree",
+			looseTags:      []string{"v1.17.0", "v1.17.1", "foo/bar"},
+			packedRefs:     packedRefs,
+			expectedOutput: []string{"foo/bar", "v1.16.0", "v1.17.0", "v1.17.1"},
+		},
+		{
+			name:           "valid: packed tags in a bare repository",
+			bare:           true,
+			packedRefs:     packedRefs,
+			expectedOutput: []string{"v1.16.0", "v1.17.0"},
+		},
+		{
+			name:           "valid: repository without tags",
+			expectedOutput: []string{},
+		},
+		{
+			name:          "invalid: not a git repository",
+			noRepository:  true,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "k8s-latest-version")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			gitDir := dir
+			if !tt.bare {
+				gitDir = filepath.Join(dir, ".git")
+			}
+			if !tt.noRepository {
+				writeTestFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/master\n")
+			}
+			for _, tag := range tt.looseTags {
+				writeTestFile(t, filepath.Join(gitDir, "refs", "tags", filepath.FromSlash(tag)), sha+"\n")
+			}
+			if len(tt.packedRefs) != 0 {
+				writeTestFile(t, filepath.Join(gitDir, "packed-refs"), tt.packedRefs)
+			}
+
+			output, err := readGitDirTags(dir)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(output, tt.expectedOutput) {
+				t.Errorf("expected output %v, got %v", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
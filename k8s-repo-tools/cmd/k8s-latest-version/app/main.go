@@ -0,0 +1,368 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-latest-version"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-latest-version is a tool for obtaining the latest SemVer "+
+		"from a list of tags separated by \\n and passed via stdin, from the tags of a GitHub repository "+
+		"or from the tags of a local git repository")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  git tag | k8s-latest-version -branch=release-1.17 -branch-prefix=release-\n")
+	fmt.Fprintf(out, "  k8s-latest-version -dest=org/repo -branch=release-1.17 -branch-prefix=release-\n")
+	fmt.Fprintf(out, "  k8s-latest-version -git-dir=/path/to/repo -branch=release-1.17 -branch-prefix=release-\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Create an HTTP client if the tags are read from GitHub.
+	if len(d.Dest) != 0 {
+		pkg.NewClient(&d, nil)
+	}
+
+	// Process multiple branches.
+	if len(d.Branches) > 1 {
+		result, err := processBranches(os.Stdin, &d)
+		if err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+		buf, err := formatBranchesOutput(result, &d)
+		if err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+		fmt.Println(string(buf))
+		return
+	}
+	if len(d.Branches) == 1 {
+		d.Branch = d.Branches[0]
+	}
+
+	result, err := process(os.Stdin, &d)
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Print the result to stdout
+	buf, err := formatOutput(result, &d)
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+	fmt.Println(string(buf))
+}
+
+func process(input io.Reader, d *pkg.Data) ([]string, error) {
+	// If the branch is defined extract a Version out of it
+	if _, err := branchToVersion(d); err != nil {
+		return nil, err
+	}
+
+	// Read the list of tags
+	lines, err := readTags(input, d)
+	if err != nil {
+		return nil, err
+	}
+	pkg.V(2).Warningf("using the following input: %v", lines)
+	if d.Strict {
+		if err := validateTags(lines); err != nil {
+			return nil, err
+		}
+	}
+
+	return processTags(lines, d)
+}
+
+// processBranches reads the list of tags once and returns the result
+// of processTags for each branch in d.Branches.
+func processBranches(input io.Reader, d *pkg.Data) (map[string]string, error) {
+	// Validate all branches before reading the tags
+	for _, b := range d.Branches {
+		bd := *d
+		bd.Branch = b
+		if _, err := branchToVersion(&bd); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the list of tags
+	lines, err := readTags(input, d)
+	if err != nil {
+		return nil, err
+	}
+	pkg.V(2).Warningf("using the following input: %v", lines)
+	if d.Strict {
+		if err := validateTags(lines); err != nil {
+			return nil, err
+		}
+	}
+
+	result := map[string]string{}
+	for _, b := range d.Branches {
+		bd := *d
+		bd.Branch = b
+		tags, err := processTags(lines, &bd)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not process branch %q", b)
+		}
+		result[b] = tags[0]
+	}
+	return result, nil
+}
+
+// processTags returns the result for a list of tags, depending on the mode in d.
+func processTags(lines []string, d *pkg.Data) ([]string, error) {
+	// If the branch is defined extract a Version out of it
+	branchV, err := branchToVersion(d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write or publish the release channel markers
+	if len(d.ChannelDir) != 0 || len(d.ChannelBucket) != 0 {
+		markers, err := computeChannels(lines)
+		if err != nil {
+			return nil, err
+		}
+		if len(d.ChannelBucket) != 0 {
+			return publishChannels(d.ChannelBucket, markers, d.DryRun)
+		}
+		return writeChannels(d.ChannelDir, markers)
+	}
+
+	// List all matching SemVer tags
+	if d.List {
+		tags, err := listTags(lines, branchV, d.StableOnly)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Warningf("found %d tags", len(tags))
+		return tags, nil
+	}
+
+	// Get the latest SemVer tag
+	latestTag, err := getLatestTag(lines, branchV, d.StableOnly)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Warningf("found latest tag %q", latestTag)
+
+	// Select the N-th latest SemVer tag
+	if d.Offset > 0 {
+		tags, err := listTags(lines, branchV, d.StableOnly)
+		if err != nil {
+			return nil, err
+		}
+		if d.Offset >= len(tags) {
+			return nil, errors.Errorf("cannot select the tag at offset %d, only %d matching tags were found",
+				d.Offset, len(tags))
+		}
+		latestTag = tags[d.Offset]
+		pkg.Warningf("found tag %q at offset %d", latestTag, d.Offset)
+	}
+	if len(d.Bump) == 0 {
+		return []string{latestTag}, nil
+	}
+
+	// Compute the next version after the latest tag
+	nextVersion, err := bumpVersion(latestTag, d.Bump)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Warningf("the next %s version is %q", d.Bump, nextVersion)
+	return []string{nextVersion}, nil
+}
+
+// branchToVersion extracts a Version out of d.Branch.
+// It returns a nil Version if d.Branch is not set.
+func branchToVersion(d *pkg.Data) (*version.Version, error) {
+	if len(d.Branch) == 0 {
+		return nil, nil
+	}
+	branchV, err := pkg.BranchToVersion(d.Branch, d.PrefixBranch)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not extract a SemVer from the given branch")
+	}
+	return branchV, nil
+}
+
+// readTags reads a list of tags from the GitHub repository in d.Dest,
+// the local git repository in d.GitDir, or from the input if neither is set.
+func readTags(input io.Reader, d *pkg.Data) ([]string, error) {
+	var lines []string
+	if len(d.GitDir) != 0 {
+		return readGitDirTags(d.GitDir)
+	}
+	if len(d.Dest) != 0 {
+		refs, err := pkg.GitHubGetTags(d, d.DestRepo())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get the tags from %q", d.DestRepo())
+		}
+		for _, ref := range refs {
+			lines = append(lines, strings.TrimPrefix(ref.GetRef(), "refs/tags/"))
+		}
+		return lines, nil
+	}
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error scanning the given input")
+	}
+	return lines, nil
+}
+
+func getLatestTag(lines []string, branchV *version.Version, stableOnly bool) (string, error) {
+	var result string
+	minV := version.MustParseSemantic("v0.0.0")
+
+	for _, line := range lines {
+		v, err := pkg.TagToVersion(line)
+		if err != nil {
+			pkg.Warningf(err.Error())
+			continue
+		}
+
+		if !matchVersion(v, branchV, stableOnly) {
+			continue
+		}
+
+		if minV.LessThan(v) {
+			minV = v
+			result = line
+		}
+	}
+
+	if len(result) == 0 {
+		if branchV != nil {
+			return "", errors.Errorf("could not find any SemVer tag that matches branch version %d.%d",
+				branchV.Major(), branchV.Minor())
+		}
+		return "", errors.Errorf("could not find the latest tag from the given input")
+	}
+	return result, nil
+}
+
+// validateTags returns an error with the list of tags that are not SemVer.
+// Empty lines are ignored.
+func validateTags(lines []string) error {
+	var invalid []string
+	for _, line := range lines {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		if _, err := pkg.TagToVersion(line); err != nil {
+			invalid = append(invalid, line)
+		}
+	}
+	if len(invalid) != 0 {
+		return errors.Errorf("found %d non-SemVer tags in the input: %v", len(invalid), invalid)
+	}
+	return nil
+}
+
+// listTags returns all SemVer tags from the input that match the branch
+// version, sorted in descending order.
+func listTags(lines []string, branchV *version.Version, stableOnly bool) ([]string, error) {
+	type tagVersion struct {
+		tag string
+		v   *version.Version
+	}
+	var tags []tagVersion
+	for _, line := range lines {
+		v, err := pkg.TagToVersion(line)
+		if err != nil {
+			pkg.Warningf(err.Error())
+			continue
+		}
+		if !matchVersion(v, branchV, stableOnly) {
+			continue
+		}
+		tags = append(tags, tagVersion{tag: line, v: v})
+	}
+
+	if len(tags) == 0 {
+		if branchV != nil {
+			return nil, errors.Errorf("could not find any SemVer tag that matches branch version %d.%d",
+				branchV.Major(), branchV.Minor())
+		}
+		return nil, errors.Errorf("could not find any SemVer tags in the given input")
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[j].v.LessThan(tags[i].v)
+	})
+	result := make([]string, len(tags))
+	for i := range tags {
+		result[i] = tags[i].tag
+	}
+	return result, nil
+}
+
+// matchVersion returns true if a version matches the branch version
+// and is not a pre-release when only stable versions are requested.
+func matchVersion(v, branchV *version.Version, stableOnly bool) bool {
+	// If only stable tags are requested skip pre-releases
+	if stableOnly && len(v.PreRelease()) != 0 {
+		return false
+	}
+	// If a branch is requested skip all other versioned tags
+	if branchV != nil {
+		if v.Major() != branchV.Major() || v.Minor() != branchV.Minor() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,459 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name           string
+		data           *pkg.Data
+		input          []string
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name: "valid: find the latest SemVer tag",
+			input: []string{
+				"v1.16.2-alpha.1",
+				"v1.15.0",
+				"v1.16.2-rc.1",
+				"v1.14.3",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: "v1.16.2-rc.1",
+		},
+		{
+			name: "valid: find the latest SemVer tag matching a branch",
+			input: []string{
+				"v1.16.2",
+				"v1.14.2",
+				"v1.15.0",
+				"v1.14.3",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.14",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: "v1.14.3",
+		},
+		{
+			name: "valid: branch prefix characters in the version are kept",
+			input: []string{
+				"v8.1.2",
+				"v8.2.0",
+				"v1.1.0",
+			},
+			data: &pkg.Data{
+				Branch:       "k8s-8.1",
+				PrefixBranch: "k8s-",
+			},
+			expectedOutput: "v8.1.2",
+		},
+		{
+			name: "valid: should ignore tags that are not SemVer",
+			input: []string{
+				"v1.16.2",
+				"foo",
+				"v1.14.3",
+				"bar",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: "v1.16.2",
+		},
+		{
+			name: "valid: should tollerate missing PATCH and 'v' in tags",
+			input: []string{
+				"1.15",
+				"1.16",
+				"1.14",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: "1.16",
+		},
+		{
+			name: "valid: bump the latest SemVer tag matching a branch",
+			input: []string{
+				"v1.16.2",
+				"v1.15.0",
+				"v1.15.1-rc.0",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.15",
+				PrefixBranch: pkg.PrefixBranch,
+				Bump:         bumpPatch,
+			},
+			expectedOutput: "v1.15.1",
+		},
+		{
+			name: "valid: find the latest stable SemVer tag matching a branch",
+			input: []string{
+				"v1.17.4-rc.1",
+				"v1.17.3",
+				"v1.17.2",
+				"v1.18.0-alpha.1",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.17",
+				PrefixBranch: pkg.PrefixBranch,
+				StableOnly:   true,
+			},
+			expectedOutput: "v1.17.3",
+		},
+		{
+			name: "valid: list the SemVer tags matching a branch in descending order",
+			input: []string{
+				"v1.17.0",
+				"foo",
+				"v1.17.4-rc.1",
+				"v1.16.2",
+				"v1.17.3",
+				"v1.17.4-alpha.0",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.17",
+				PrefixBranch: pkg.PrefixBranch,
+				List:         true,
+			},
+			expectedOutput: "v1.17.4-rc.1\nv1.17.4-alpha.0\nv1.17.3\nv1.17.0",
+		},
+		{
+			name: "valid: list the stable SemVer tags",
+			input: []string{
+				"v1.17.0",
+				"v1.17.4-rc.1",
+				"v1.16.2",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				StableOnly:   true,
+				List:         true,
+			},
+			expectedOutput: "v1.17.0\nv1.16.2",
+		},
+		{
+			name: "valid: select the previous SemVer tag",
+			input: []string{
+				"v1.16.2",
+				"v1.17.0",
+				"v1.17.1-rc.0",
+				"v1.16.3",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				Offset:       1,
+			},
+			expectedOutput: "v1.17.0",
+		},
+		{
+			name: "valid: select the stable SemVer tag before the previous one on a branch",
+			input: []string{
+				"v1.16.2",
+				"v1.16.4-rc.0",
+				"v1.16.0",
+				"v1.16.3",
+				"v1.17.0",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.16",
+				PrefixBranch: pkg.PrefixBranch,
+				StableOnly:   true,
+				Offset:       2,
+			},
+			expectedOutput: "v1.16.0",
+		},
+		{
+			name:  "invalid: cannot parse SemVer from branch",
+			input: []string{},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "foo",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+		{
+			name:  "invalid: branch does not contain the branch prefix",
+			input: []string{},
+			data: &pkg.Data{
+				Branch:       "foo-bar",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: could not find SemVer tags in the input",
+			input: []string{
+				"foo",
+				"bar",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: could not find stable SemVer tags in the input",
+			input: []string{
+				"v1.18.0-alpha.1",
+				"v1.18.0-beta.0",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				StableOnly:   true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: strict mode allows empty lines",
+			input: []string{
+				"v1.16.2",
+				"",
+				"v1.17.0",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				Strict:       true,
+			},
+			expectedOutput: "v1.17.0",
+		},
+		{
+			name: "invalid: strict mode fails on tags that are not SemVer",
+			input: []string{
+				"v1.16.2",
+				"foo",
+				"v1.17.0",
+				"v1.17.0.1",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				Strict:       true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: offset is out of range",
+			input: []string{
+				"v1.16.2",
+				"v1.17.0",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				Offset:       2,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: could not list SemVer tags in the input",
+			input: []string{
+				"foo",
+			},
+			data: &pkg.Data{
+				PrefixBranch: pkg.PrefixBranch,
+				List:         true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: could not find SemVer tags in the input for a given branch",
+			input: []string{
+				"foo",
+				"bar",
+			},
+			data: &pkg.Data{
+				Branch:       pkg.PrefixBranch + "1.14",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := strings.NewReader(strings.Join(tt.input, "\n"))
+
+			output, err := process(input, tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+
+			if strings.Join(output, "\n") != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestProcessDest(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name           string
+		branch         string
+		refs           []*github.Reference
+		methodErrors   map[string]bool
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name:   "valid: find the latest SemVer tag matching a branch in a repository",
+			branch: pkg.PrefixBranch + "1.16",
+			refs: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/release-1.16")},
+				&github.Reference{Ref: github.String("refs/tags/v1.16.1")},
+				&github.Reference{Ref: github.String("refs/tags/v1.16.2")},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0")},
+			},
+			expectedOutput: "v1.16.2",
+		},
+		{
+			name: "invalid: repository has no tags",
+			refs: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/master")},
+			},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: error getting the tags",
+			methodErrors:  map[string]bool{"GET": true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Dest:         []string{"org/dest"},
+				Branch:       tt.branch,
+				PrefixBranch: pkg.PrefixBranch,
+			}
+			if tt.methodErrors == nil {
+				tt.methodErrors = map[string]bool{}
+			}
+
+			// Create fake client and setup endpoint handlers.
+			pkg.NewClient(data, pkg.NewTransport())
+			const testRefs = "https://api.github.com/repos/org/dest/git/refs"
+			data.Transport.SetHandler(testRefs, pkg.NewReferenceHandler(&tt.refs, tt.methodErrors))
+
+			// Stdin must be ignored when reading from a repository.
+			output, err := process(strings.NewReader("v2.0.0"), data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if strings.Join(output, "\n") != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestProcessBranches(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name           string
+		data           *pkg.Data
+		input          []string
+		expectedOutput map[string]string
+		expectedError  bool
+	}{
+		{
+			name: "valid: find the latest SemVer tag for multiple branches",
+			input: []string{
+				"v1.16.2",
+				"v1.17.0",
+				"v1.17.1-rc.0",
+				"v1.16.3",
+			},
+			data: &pkg.Data{
+				Branches:     []string{pkg.PrefixBranch + "1.16", pkg.PrefixBranch + "1.17"},
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: map[string]string{
+				pkg.PrefixBranch + "1.16": "v1.16.3",
+				pkg.PrefixBranch + "1.17": "v1.17.1-rc.0",
+			},
+		},
+		{
+			name: "valid: find the latest stable SemVer tag for multiple branches",
+			input: []string{
+				"v1.16.2",
+				"v1.17.0",
+				"v1.17.1-rc.0",
+				"v1.16.3",
+			},
+			data: &pkg.Data{
+				Branches:     []string{pkg.PrefixBranch + "1.16", pkg.PrefixBranch + "1.17"},
+				PrefixBranch: pkg.PrefixBranch,
+				StableOnly:   true,
+			},
+			expectedOutput: map[string]string{
+				pkg.PrefixBranch + "1.16": "v1.16.3",
+				pkg.PrefixBranch + "1.17": "v1.17.0",
+			},
+		},
+		{
+			name: "invalid: one of the branches has no tags",
+			input: []string{
+				"v1.16.2",
+			},
+			data: &pkg.Data{
+				Branches:     []string{pkg.PrefixBranch + "1.16", pkg.PrefixBranch + "1.17"},
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+		{
+			name:  "invalid: cannot parse SemVer from one of the branches",
+			input: []string{},
+			data: &pkg.Data{
+				Branches:     []string{pkg.PrefixBranch + "1.16", pkg.PrefixBranch + "foo"},
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := strings.NewReader(strings.Join(tt.input, "\n"))
+			output, err := processBranches(input, tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(output, tt.expectedOutput) {
+				t.Errorf("expected output %v, got %v", tt.expectedOutput, output)
+			}
+		})
+	}
+}
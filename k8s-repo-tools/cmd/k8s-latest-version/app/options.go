@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The tags are read from stdin if
+// neither --dest nor --git-dir is passed, so that the token is optional.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	Token         string          `validate:"token"`
+	Branches      pkg.MultiString `flag:"branch" usage:"Branch to use in the format \"prefixMAJOR.MINOR\". Multiple instances of the flag are allowed"`
+	PrefixBranch  string          `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	Dest          []string        `flag:"dest" usage:"GitHub org/repo from which to read the tags instead of reading them from stdin" validate:"repo"`
+	Bump          string          `flag:"bump" usage:"Print the next version to cut after the latest tag instead of the latest tag. One of 'patch', 'minor' or 'prerelease'" validate:"oneof=patch|minor|prerelease"`
+	StableOnly    bool            `flag:"stable-only" usage:"Ignore pre-release tags such as 'v1.17.4-rc.1'"`
+	OutputFormat  string          `flag:"output-format" default:"text" usage:"Format of the output. One of \"text\" or \"json\"" validate:"oneof=text|json"`
+	GitDir        string          `flag:"git-dir" usage:"Path to a local git repository from which to read the tags"`
+	List          bool            `flag:"list" usage:"Print all recognized SemVer tags in descending order instead of the latest tag"`
+	ChannelDir    string          `flag:"channel-dir" usage:"Path to a directory where to write the release channel markers such as 'stable.txt' and 'latest-1.17.txt'"`
+	ChannelBucket string          `flag:"channel-bucket" usage:"Bucket and path such as 'gs://bucket/release' where to publish the release channel markers such as 'stable.txt' and 'latest-1.17.txt'" validate:"bucket"`
+	Offset        int             `flag:"offset" usage:"Select the N-th latest tag instead of the latest tag. 1 means the previous tag" validate:"nonnegative"`
+	Strict        bool            `flag:"strict" usage:"Fail instead of continuing with a warning when refs that are not valid versions are found or a repository has been renamed"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	// Validate the tag sources.
+	if len(d.Dest) != 0 && len(d.GitDir) != 0 {
+		return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagDest, pkg.FlagGitDir)
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the release channel mode.
+	channelFlag := pkg.FlagChannelDir
+	if len(d.ChannelBucket) != 0 {
+		channelFlag = pkg.FlagChannelBucket
+		if len(d.ChannelDir) != 0 {
+			return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagChannelDir, pkg.FlagChannelBucket)
+		}
+	}
+	if len(d.ChannelDir) != 0 || len(d.ChannelBucket) != 0 {
+		for k, v := range map[string]bool{
+			pkg.FlagBranch:       len(d.Branch) != 0 || len(d.Branches) != 0,
+			pkg.FlagBump:         len(d.Bump) != 0,
+			pkg.FlagList:         d.List,
+			pkg.FlagStableOnly:   d.StableOnly,
+			pkg.FlagOutputFormat: d.OutputFormat == pkg.OutputFormatJSON,
+			pkg.FlagOffset:       d.Offset != 0,
+		} {
+			if v {
+				return errors.Errorf("the options %q and %q cannot be used together", channelFlag, k)
+			}
+		}
+	}
+
+	// Validate multiple branches.
+	if len(d.Branches) > 1 && d.List {
+		return errors.Errorf("the option %q cannot be used with multiple instances of %q", pkg.FlagList, pkg.FlagBranch)
+	}
+
+	// Validate the offset.
+	if d.Offset != 0 && d.List {
+		return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagOffset, pkg.FlagList)
+	}
+
+	// Validate the bump type.
+	if len(d.Bump) != 0 && d.List {
+		return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagBump, pkg.FlagList)
+	}
+
+	return nil
+}
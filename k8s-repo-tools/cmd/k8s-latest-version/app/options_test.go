@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestValidateData(t *testing.T) {
+	const validToken = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		expectedError bool
+	}{
+		{
+			name: "valid: reading tags from stdin",
+			data: &pkg.Data{},
+		},
+		{
+			name: "valid: reading tags from a repository without a token",
+			data: &pkg.Data{
+				Dest: []string{"org/dest"},
+			},
+		},
+		{
+			name: "valid: reading tags from a repository with a token",
+			data: &pkg.Data{
+				Dest:  []string{"org/dest"},
+				Token: validToken,
+			},
+		},
+		{
+			name: "valid: known bump type",
+			data: &pkg.Data{
+				Bump: bumpPreRelease,
+			},
+		},
+		{
+			name: "invalid: unknown bump type",
+			data: &pkg.Data{
+				Bump: "major",
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: JSON output format",
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+			},
+		},
+		{
+			name: "invalid: unknown output format",
+			data: &pkg.Data{
+				OutputFormat: "yaml",
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: channel directory",
+			data: &pkg.Data{
+				ChannelDir:   "/path/to/dir",
+				OutputFormat: pkg.OutputFormatText,
+			},
+		},
+		{
+			name: "invalid: channel directory and branch",
+			data: &pkg.Data{
+				ChannelDir: "/path/to/dir",
+				Branch:     pkg.PrefixBranch + "1.17",
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: channel bucket",
+			data: &pkg.Data{
+				ChannelBucket: "gs://bucket/release",
+			},
+		},
+		{
+			name: "invalid: channel bucket is not a bucket",
+			data: &pkg.Data{
+				ChannelBucket: "/path/to/dir",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: channel bucket without a bucket name",
+			data: &pkg.Data{
+				ChannelBucket: "gs:///release",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: channel bucket and channel directory",
+			data: &pkg.Data{
+				ChannelBucket: "gs://bucket/release",
+				ChannelDir:    "/path/to/dir",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: channel bucket and bump",
+			data: &pkg.Data{
+				ChannelBucket: "gs://bucket/release",
+				Bump:          "patch",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: multiple branches and list",
+			data: &pkg.Data{
+				Branches: []string{pkg.PrefixBranch + "1.16", pkg.PrefixBranch + "1.17"},
+				List:     true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: negative offset",
+			data: &pkg.Data{
+				Offset: -1,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: offset and list",
+			data: &pkg.Data{
+				Offset: 1,
+				List:   true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: bump and list",
+			data: &pkg.Data{
+				Bump: bumpPatch,
+				List: true,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: both a GitHub and a local repository",
+			data: &pkg.Data{
+				Dest:   []string{"org/dest"},
+				GitDir: "/path/to/repo",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: repository is not formatted correctly",
+			data: &pkg.Data{
+				Dest: []string{"bar/"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: short token hash",
+			data: &pkg.Data{
+				Dest:  []string{"org/dest"},
+				Token: "282ef40c7d38cbfa",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateData(tt.data); (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// output is the structure of the JSON output.
+type output struct {
+	Tag           string `json:"tag"`
+	Major         uint   `json:"major"`
+	Minor         uint   `json:"minor"`
+	Patch         uint   `json:"patch"`
+	PreRelease    string `json:"preRelease"`
+	Branch        string `json:"branch,omitempty"`
+	MatchedBranch bool   `json:"matchedBranch"`
+}
+
+// formatOutput formats a list of tags in the output format from d.OutputFormat.
+// In JSON format a single tag is an object, while a list of tags is an array.
+func formatOutput(tags []string, d *pkg.Data) ([]byte, error) {
+	if d.OutputFormat != pkg.OutputFormatJSON {
+		return []byte(strings.Join(tags, "\n")), nil
+	}
+
+	branchV, err := branchToVersion(d)
+	if err != nil {
+		return nil, err
+	}
+
+	outs := make([]*output, len(tags))
+	for i, tag := range tags {
+		if outs[i], err = newOutput(tag, d.Branch, branchV); err != nil {
+			return nil, err
+		}
+	}
+	if !d.List && len(outs) == 1 {
+		return json.MarshalIndent(outs[0], "", "\t")
+	}
+	return json.MarshalIndent(outs, "", "\t")
+}
+
+// newOutput creates an output for a tag.
+func newOutput(tag, branch string, branchV *version.Version) (*output, error) {
+	v, err := pkg.TagToVersion(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &output{
+		Tag:        tag,
+		Major:      v.Major(),
+		Minor:      v.Minor(),
+		Patch:      v.Patch(),
+		PreRelease: v.PreRelease(),
+		Branch:     branch,
+	}
+	if branchV != nil {
+		out.MatchedBranch = v.Major() == branchV.Major() && v.Minor() == branchV.Minor()
+	}
+	return out, nil
+}
+
+// formatBranchesOutput formats the latest tag per branch in the output format from d.OutputFormat.
+// The text format is a table, while the JSON format is a map of branch to tag object.
+func formatBranchesOutput(result map[string]string, d *pkg.Data) ([]byte, error) {
+	branches := make([]string, 0, len(result))
+	for b := range result {
+		branches = append(branches, b)
+	}
+	sort.Strings(branches)
+
+	if d.OutputFormat != pkg.OutputFormatJSON {
+		var b bytes.Buffer
+		tabW := tabwriter.NewWriter(&b, 12, 0, 2, ' ', 0)
+		fmt.Fprintln(tabW, "BRANCH\tTAG")
+		for _, branch := range branches {
+			fmt.Fprintf(tabW, "%s\t%s\n", branch, result[branch])
+		}
+		tabW.Flush()
+		return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+	}
+
+	outs := map[string]*output{}
+	for _, branch := range branches {
+		bd := *d
+		bd.Branch = branch
+		branchV, err := branchToVersion(&bd)
+		if err != nil {
+			return nil, err
+		}
+		if outs[branch], err = newOutput(result[branch], branch, branchV); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(outs, "", "\t")
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestFormatOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		tags           []string
+		data           *pkg.Data
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name: "valid: text output",
+			tags: []string{"v1.17.4-rc.1"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatText,
+			},
+			expectedOutput: "v1.17.4-rc.1",
+		},
+		{
+			name: "valid: JSON output without a branch",
+			tags: []string{"v1.17.4-rc.1"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: `{
+	"tag": "v1.17.4-rc.1",
+	"major": 1,
+	"minor": 17,
+	"patch": 4,
+	"preRelease": "rc.1",
+	"matchedBranch": false
+}`,
+		},
+		{
+			name: "valid: JSON output with a matching branch",
+			tags: []string{"v1.17.3"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+				Branch:       pkg.PrefixBranch + "1.17",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: `{
+	"tag": "v1.17.3",
+	"major": 1,
+	"minor": 17,
+	"patch": 3,
+	"preRelease": "",
+	"branch": "release-1.17",
+	"matchedBranch": true
+}`,
+		},
+		{
+			name: "valid: JSON output with a bumped version that no longer matches the branch",
+			tags: []string{"v1.18.0"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+				Branch:       pkg.PrefixBranch + "1.17",
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: `{
+	"tag": "v1.18.0",
+	"major": 1,
+	"minor": 18,
+	"patch": 0,
+	"preRelease": "",
+	"branch": "release-1.17",
+	"matchedBranch": false
+}`,
+		},
+		{
+			name: "valid: text list output",
+			tags: []string{"v1.17.1", "v1.17.0"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatText,
+				List:         true,
+			},
+			expectedOutput: "v1.17.1\nv1.17.0",
+		},
+		{
+			name: "valid: JSON list output",
+			tags: []string{"v1.17.1"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+				List:         true,
+			},
+			expectedOutput: `[
+	{
+		"tag": "v1.17.1",
+		"major": 1,
+		"minor": 17,
+		"patch": 1,
+		"preRelease": "",
+		"matchedBranch": false
+	}
+]`,
+		},
+		{
+			name: "invalid: tag is not SemVer",
+			tags: []string{"foo"},
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := formatOutput(tt.tags, tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if string(output) != tt.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestFormatBranchesOutput(t *testing.T) {
+	result := map[string]string{
+		pkg.PrefixBranch + "1.17": "v1.17.1-rc.0",
+		pkg.PrefixBranch + "1.16": "v1.16.3",
+	}
+
+	tests := []struct {
+		name           string
+		data           *pkg.Data
+		expectedOutput string
+	}{
+		{
+			name: "valid: text output",
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatText,
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: `BRANCH        TAG
+release-1.16  v1.16.3
+release-1.17  v1.17.1-rc.0`,
+		},
+		{
+			name: "valid: JSON output",
+			data: &pkg.Data{
+				OutputFormat: pkg.OutputFormatJSON,
+				PrefixBranch: pkg.PrefixBranch,
+			},
+			expectedOutput: `{
+	"release-1.16": {
+		"tag": "v1.16.3",
+		"major": 1,
+		"minor": 16,
+		"patch": 3,
+		"preRelease": "",
+		"branch": "release-1.16",
+		"matchedBranch": true
+	},
+	"release-1.17": {
+		"tag": "v1.17.1-rc.0",
+		"major": 1,
+		"minor": 17,
+		"patch": 1,
+		"preRelease": "rc.0",
+		"branch": "release-1.17",
+		"matchedBranch": true
+	}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := formatBranchesOutput(result, tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(output) != tt.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expectedOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// repoResult holds the titles of the milestones that were created and updated
+// in a destination repository.
+type repoResult struct {
+	Repo    string   `json:"repo"`
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+}
+
+// process is responsible for all operations that the application performs.
+// It returns a result for every destination repository that was processed.
+func process(d *pkg.Data) ([]*repoResult, error) {
+
+	// Obtain the source milestones.
+	milestonesSrc, err := pkg.GitHubGetMilestones(d, d.SourceRepo())
+	if err != nil {
+		return nil, err
+	}
+	pkg.Logf("found %d milestone(s) in repository %q", len(milestonesSrc), d.SourceRepo())
+
+	dests := d.Dest
+	repoResults := make([]*repoResult, len(dests))
+	err = pkg.ForEachRepo(d, dests, func(i int, dest string, log *pkg.Logger) error {
+		var err error
+		repoResults[i], err = syncMilestones(d, dest, milestonesSrc, log)
+		return err
+	})
+	results := []*repoResult{}
+	for _, res := range repoResults {
+		if res != nil {
+			results = append(results, res)
+		}
+	}
+	return results, err
+}
+
+// syncMilestones creates the source milestones that are missing in a destination
+// repository and updates the ones that differ. Milestones that only exist in the
+// destination repository are kept.
+func syncMilestones(d *pkg.Data, dest string, milestonesSrc []*github.Milestone, log *pkg.Logger) (*repoResult, error) {
+	milestonesDest, err := pkg.GitHubGetMilestones(d, dest)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := map[string]*github.Milestone{}
+	for _, m := range milestonesDest {
+		byTitle[m.GetTitle()] = m
+	}
+
+	var create, update []*github.Milestone
+	for _, m := range milestonesSrc {
+		existing, found := byTitle[m.GetTitle()]
+		switch {
+		case !found:
+			log.V(1).Logf("milestone %q is missing in repository %q", m.GetTitle(), dest)
+			create = append(create, m)
+		case !milestonesEqual(m, existing):
+			log.V(1).Logf("milestone %q differs in repository %q", m.GetTitle(), dest)
+			update = append(update, m)
+		}
+	}
+	if len(create) == 0 && len(update) == 0 {
+		log.Logf("the milestones of repository %q are in sync with %q", dest, d.SourceRepo())
+		return nil, nil
+	}
+	log.Logf("found %d milestone(s) to create and %d milestone(s) to update in repository %q",
+		len(create), len(update), dest)
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create %d and update %d milestone(s) in repository %q?",
+		len(create), len(update), dest)
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return nil, nil
+	}
+
+	res := &repoResult{Repo: dest, Created: []string{}, Updated: []string{}}
+	for _, m := range create {
+		if _, err := pkg.GitHubCreateMilestone(d, dest, m, d.DryRun); err != nil {
+			return res, err
+		}
+		res.Created = append(res.Created, m.GetTitle())
+	}
+	for _, m := range update {
+		if err := pkg.GitHubEditMilestone(d, dest, byTitle[m.GetTitle()].GetNumber(), m, d.DryRun); err != nil {
+			return res, err
+		}
+		res.Updated = append(res.Updated, m.GetTitle())
+	}
+	return res, nil
+}
+
+// milestonesEqual returns true if two milestones have the same description,
+// due date and state.
+func milestonesEqual(a, b *github.Milestone) bool {
+	if a.GetDescription() != b.GetDescription() || a.GetState() != b.GetState() {
+		return false
+	}
+	if a.DueOn == nil || b.DueOn == nil {
+		return a.DueOn == nil && b.DueOn == nil
+	}
+	return a.DueOn.Equal(*b.DueOn)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	due := time.Date(2020, time.March, 24, 0, 0, 0, 0, time.UTC)
+	later := due.Add(7 * 24 * time.Hour)
+	newMilestone := func(number int, title, state string, dueOn *time.Time) *github.Milestone {
+		return &github.Milestone{
+			Number: github.Int(number),
+			Title:  github.String(title),
+			State:  github.String(state),
+			DueOn:  dueOn,
+		}
+	}
+	milestonesSrc := []*github.Milestone{
+		newMilestone(1, "v1.17", "closed", nil),
+		newMilestone(2, "v1.18", "open", &due),
+	}
+
+	tests := []struct {
+		name                string
+		milestonesA         []*github.Milestone
+		milestonesB         []*github.Milestone
+		methodErrorsSrc     map[string]bool
+		methodErrorsDest    map[string]bool
+		expectedResults     []*repoResult
+		expectedMilestonesA []*github.Milestone
+		expectedMilestonesB []*github.Milestone
+		expectedError       bool
+		skipDryRun          bool
+	}{
+		{
+			name:        "valid: create missing and update different milestones",
+			milestonesA: []*github.Milestone{},
+			milestonesB: []*github.Milestone{
+				newMilestone(1, "v1.18", "open", &later),
+				newMilestone(2, "next", "open", nil),
+				newMilestone(3, "v1.17", "closed", nil),
+			},
+			expectedResults: []*repoResult{
+				{Repo: "org/a", Created: []string{"v1.17", "v1.18"}, Updated: []string{}},
+				{Repo: "org/b", Created: []string{}, Updated: []string{"v1.18"}},
+			},
+			expectedMilestonesA: []*github.Milestone{
+				newMilestone(1, "v1.17", "closed", nil),
+				newMilestone(2, "v1.18", "open", &due),
+			},
+			expectedMilestonesB: []*github.Milestone{
+				newMilestone(1, "v1.18", "open", &due),
+				newMilestone(2, "next", "open", nil),
+				newMilestone(3, "v1.17", "closed", nil),
+			},
+		},
+		{
+			name: "valid: milestones are in sync",
+			milestonesA: []*github.Milestone{
+				newMilestone(1, "v1.17", "closed", nil),
+				newMilestone(2, "v1.18", "open", &due),
+			},
+			milestonesB: []*github.Milestone{
+				newMilestone(5, "v1.18", "open", &due),
+				newMilestone(6, "v1.17", "closed", nil),
+			},
+			expectedResults: []*repoResult{},
+			expectedMilestonesA: []*github.Milestone{
+				newMilestone(1, "v1.17", "closed", nil),
+				newMilestone(2, "v1.18", "open", &due),
+			},
+			expectedMilestonesB: []*github.Milestone{
+				newMilestone(5, "v1.18", "open", &due),
+				newMilestone(6, "v1.17", "closed", nil),
+			},
+		},
+		{
+			name:            "invalid: simulated error when getting the source milestones",
+			milestonesA:     []*github.Milestone{},
+			milestonesB:     []*github.Milestone{},
+			methodErrorsSrc: map[string]bool{http.MethodGet: true},
+			expectedError:   true,
+		},
+		{
+			name:             "invalid: simulated error when creating a milestone",
+			milestonesA:      []*github.Milestone{},
+			milestonesB:      []*github.Milestone{},
+			methodErrorsDest: map[string]bool{http.MethodPost: true},
+			expectedError:    true,
+			skipDryRun:       true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Source: []string{"org/src"},
+					Dest:   []string{"org/a", "org/b"},
+					DryRun: dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handlers modify it.
+				src := append([]*github.Milestone{}, milestonesSrc...)
+				milestonesA := append([]*github.Milestone{}, tt.milestonesA...)
+				milestonesB := append([]*github.Milestone{}, tt.milestonesB...)
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/src/milestones",
+					pkg.NewMilestoneHandler(&src, tt.methodErrorsSrc))
+				data.Transport.SetHandler("https://api.github.com/repos/org/a/milestones",
+					pkg.NewMilestoneHandler(&milestonesA, tt.methodErrorsDest))
+				data.Transport.SetHandler("https://api.github.com/repos/org/b/milestones",
+					pkg.NewMilestoneHandler(&milestonesB, tt.methodErrorsDest))
+
+				results, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(results, tt.expectedResults) {
+					t.Errorf("expected results:\n%+v\ngot:\n%+v", tt.expectedResults, results)
+				}
+
+				// In dry-run mode the milestones are not changed.
+				expectedA, expectedB := tt.expectedMilestonesA, tt.expectedMilestonesB
+				if dryRunVal {
+					expectedA, expectedB = tt.milestonesA, tt.milestonesB
+				}
+				if !reflect.DeepEqual(milestonesA, expectedA) {
+					t.Errorf("expected milestones in org/a:\n%v\ngot:\n%v", expectedA, milestonesA)
+				}
+				if !reflect.DeepEqual(milestonesB, expectedB) {
+					t.Errorf("expected milestones in org/b:\n%v\ngot:\n%v", expectedB, milestonesB)
+				}
+			})
+		}
+	}
+}
+
+func TestMilestonesEqual(t *testing.T) {
+	due := time.Date(2020, time.March, 24, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		a, b     *github.Milestone
+		expected bool
+	}{
+		{
+			name:     "equal without due dates",
+			a:        &github.Milestone{State: github.String("open")},
+			b:        &github.Milestone{State: github.String("open")},
+			expected: true,
+		},
+		{
+			name:     "equal due dates in different time zones",
+			a:        &github.Milestone{DueOn: &due},
+			b:        &github.Milestone{DueOn: func() *time.Time { t := due.In(time.FixedZone("PST", -8*3600)); return &t }()},
+			expected: true,
+		},
+		{
+			name: "missing due date",
+			a:    &github.Milestone{DueOn: &due},
+			b:    &github.Milestone{},
+		},
+		{
+			name: "different state",
+			a:    &github.Milestone{State: github.String("open")},
+			b:    &github.Milestone{State: github.String("closed")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := milestonesEqual(tt.a, tt.b); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
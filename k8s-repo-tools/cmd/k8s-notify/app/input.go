@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	toolRepoSync      = "k8s-repo-sync"
+	toolRepoFF        = "k8s-repo-ff"
+	toolCreateRelease = "k8s-create-release"
+)
+
+// section is the summary of a single output file.
+type section struct {
+	Tool    string   `json:"tool"`
+	Path    string   `json:"path"`
+	Lines   []string `json:"lines"`
+	Error   string   `json:"error,omitempty"`
+	Partial bool     `json:"partial,omitempty"`
+}
+
+// failed returns true if the tool that wrote the output file reported an
+// error or was interrupted.
+func (s *section) failed() bool {
+	return len(s.Error) != 0 || s.Partial
+}
+
+// repoSyncOutput is the output of k8s-repo-sync.
+type repoSyncOutput struct {
+	Refs      []*github.Reference `json:"refs"`
+	Conflicts []struct {
+		Ref        string `json:"ref"`
+		Updated    bool   `json:"updated"`
+		CompareURL string `json:"compareURL"`
+	} `json:"conflicts"`
+}
+
+// repoFFOutput is the output of k8s-repo-ff.
+type repoFFOutput struct {
+	OutputError *string                  `json:"outputError"`
+	Reference   *github.Reference        `json:"reference"`
+	Commit      *github.RepositoryCommit `json:"commit"`
+	Tag         *github.Reference        `json:"tag"`
+	Partial     bool                     `json:"partial"`
+}
+
+// createReleaseOutput is the output of k8s-create-release.
+type createReleaseOutput struct {
+	OutputError *string                   `json:"outputError"`
+	Release     *github.RepositoryRelease `json:"release"`
+	Assets      []*github.ReleaseAsset    `json:"assets"`
+	Partial     bool                      `json:"partial"`
+}
+
+// readInput reads an output file and returns its summary. A file is marked
+// as partial if the file itself says so or if a partial result marker exists
+// next to it.
+func readInput(path string) (*section, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the input file %q", path)
+	}
+	s, err := parseInput(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse the input file %q", path)
+	}
+	s.Path = path
+	if _, err := os.Stat(path + pkg.PartialMarkerSuffix); err == nil {
+		s.Partial = true
+	}
+	return s, nil
+}
+
+// parseInput detects the tool that wrote an output file from its format and
+// returns a summary of the contents.
+func parseInput(data []byte) (*section, error) {
+	data = bytes.TrimSpace(data)
+
+	// Older versions of k8s-repo-sync write a list of the new refs.
+	if bytes.HasPrefix(data, []byte("[")) {
+		out := &repoSyncOutput{}
+		if err := json.Unmarshal(data, &out.Refs); err != nil {
+			return nil, err
+		}
+		return summarizeRepoSync(out), nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["refs"]; ok {
+		out := &repoSyncOutput{}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, err
+		}
+		return summarizeRepoSync(out), nil
+	}
+	if _, ok := fields["release"]; ok {
+		out := &createReleaseOutput{}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, err
+		}
+		return summarizeCreateRelease(out), nil
+	}
+	if _, ok := fields["reference"]; ok {
+		out := &repoFFOutput{}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, err
+		}
+		return summarizeRepoFF(out), nil
+	}
+	return nil, errors.Errorf("unknown format. Supported are the outputs of %s, %s and %s",
+		toolRepoSync, toolRepoFF, toolCreateRelease)
+}
+
+func summarizeRepoSync(out *repoSyncOutput) *section {
+	s := &section{Tool: toolRepoSync, Lines: []string{}}
+	if len(out.Refs) == 0 {
+		s.Lines = append(s.Lines, "no new tags or branches")
+	}
+	for _, ref := range out.Refs {
+		kind, name := "branch", strings.TrimPrefix(ref.GetRef(), "refs/heads/")
+		if strings.HasPrefix(ref.GetRef(), "refs/tags/") {
+			kind, name = "tag", strings.TrimPrefix(ref.GetRef(), "refs/tags/")
+		}
+		s.Lines = append(s.Lines, fmt.Sprintf("created %s `%s` at `%s`", kind, name, shortSHA(ref.GetObject().GetSHA())))
+	}
+	for _, c := range out.Conflicts {
+		if c.Updated {
+			continue
+		}
+		s.Lines = append(s.Lines, fmt.Sprintf("`%s` points to different commits: %s", c.Ref, c.CompareURL))
+	}
+	return s
+}
+
+func summarizeRepoFF(out *repoFFOutput) *section {
+	s := &section{Tool: toolRepoFF, Lines: []string{}, Error: stringValue(out.OutputError), Partial: out.Partial}
+	branch := strings.TrimPrefix(out.Reference.GetRef(), "refs/heads/")
+	switch {
+	case len(out.Commit.GetSHA()) != 0:
+		s.Lines = append(s.Lines, fmt.Sprintf("fast-forwarded `%s` with merge commit `%s`", branch, shortSHA(out.Commit.GetSHA())))
+	case len(branch) != 0:
+		s.Lines = append(s.Lines, fmt.Sprintf("`%s` was not fast-forwarded", branch))
+	default:
+		s.Lines = append(s.Lines, "no release branch was fast-forwarded")
+	}
+	if out.Tag != nil {
+		s.Lines = append(s.Lines, fmt.Sprintf("created tag `%s` at `%s`",
+			strings.TrimPrefix(out.Tag.GetRef(), "refs/tags/"), shortSHA(out.Tag.GetObject().GetSHA())))
+	}
+	return s
+}
+
+func summarizeCreateRelease(out *createReleaseOutput) *section {
+	s := &section{Tool: toolCreateRelease, Lines: []string{}, Error: stringValue(out.OutputError), Partial: out.Partial}
+	if out.Release == nil {
+		s.Lines = append(s.Lines, "no release was created")
+		return s
+	}
+	line := fmt.Sprintf("release `%s`", out.Release.GetTagName())
+	if url := out.Release.GetHTMLURL(); len(url) != 0 {
+		line += ": " + url
+	}
+	s.Lines = append(s.Lines, line)
+	if len(out.Assets) != 0 {
+		names := make([]string, len(out.Assets))
+		for i, a := range out.Assets {
+			names[i] = a.GetName()
+		}
+		s.Lines = append(s.Lines, fmt.Sprintf("%d asset(s): %s", len(names), strings.Join(names, ", ")))
+	}
+	return s
+}
+
+// shortSHA returns the abbreviated form of a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
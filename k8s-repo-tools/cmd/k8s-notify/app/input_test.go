@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedSection *section
+		expectedError   bool
+	}{
+		{
+			name: "valid: k8s-repo-sync output",
+			input: `[
+	{"ref": "refs/heads/release-1.17", "object": {"sha": "b04b9fb3987b12045ac5b2b273f1b5b3a8a7c972"}},
+	{"ref": "refs/tags/v1.17.0", "object": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"}}
+]`,
+			expectedSection: &section{
+				Tool: toolRepoSync,
+				Lines: []string{
+					"created branch `release-1.17` at `b04b9fb`",
+					"created tag `v1.17.0` at `02a9c9f`",
+				},
+			},
+		},
+		{
+			name: "valid: k8s-repo-sync output with conflicts",
+			input: `{"refs": [{"ref": "refs/tags/v1.17.0", "object": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"}}], "conflicts": [
+	{"ref": "refs/heads/release-1.16", "sourceSHA": "b", "destSHA": "a", "updated": false, "compareURL": "https://github.com/org/dest/compare/a...org:src:b"},
+	{"ref": "refs/heads/release-1.15", "sourceSHA": "d", "destSHA": "c", "updated": true, "compareURL": "https://github.com/org/dest/compare/c...org:src:d"}
+]}`,
+			expectedSection: &section{
+				Tool: toolRepoSync,
+				Lines: []string{
+					"created tag `v1.17.0` at `02a9c9f`",
+					"`refs/heads/release-1.16` points to different commits: https://github.com/org/dest/compare/a...org:src:b",
+				},
+			},
+		},
+		{
+			name:  "valid: empty k8s-repo-sync output",
+			input: `{"refs": [], "conflicts": []}`,
+			expectedSection: &section{
+				Tool:  toolRepoSync,
+				Lines: []string{"no new tags or branches"},
+			},
+		},
+		{
+			name:  "valid: empty k8s-repo-sync output in the list format",
+			input: `[]`,
+			expectedSection: &section{
+				Tool:  toolRepoSync,
+				Lines: []string{"no new tags or branches"},
+			},
+		},
+		{
+			name:  "valid: k8s-repo-ff output",
+			input: `{"outputError": null, "reference": {"ref": "refs/heads/release-1.17"}, "commit": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"}}`,
+			expectedSection: &section{
+				Tool:  toolRepoFF,
+				Lines: []string{"fast-forwarded `release-1.17` with merge commit `02a9c9f`"},
+			},
+		},
+		{
+			name: "valid: k8s-repo-ff output with the next pre-release tag",
+			input: `{"outputError": null, "reference": {"ref": "refs/heads/release-1.17"}, "commit": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"},
+	"tag": {"ref": "refs/tags/v1.17.0-beta.2", "object": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"}}}`,
+			expectedSection: &section{
+				Tool: toolRepoFF,
+				Lines: []string{
+					"fast-forwarded `release-1.17` with merge commit `02a9c9f`",
+					"created tag `v1.17.0-beta.2` at `02a9c9f`",
+				},
+			},
+		},
+		{
+			name:  "valid: partial k8s-repo-ff output with an error",
+			input: `{"outputError": "interrupted", "reference": null, "commit": null, "partial": true}`,
+			expectedSection: &section{
+				Tool:    toolRepoFF,
+				Lines:   []string{"no release branch was fast-forwarded"},
+				Error:   "interrupted",
+				Partial: true,
+			},
+		},
+		{
+			name:  "valid: k8s-create-release output",
+			input: `{"outputError": null, "release": {"tag_name": "v1.17.0", "html_url": "https://github.com/org/repo/releases/tag/v1.17.0"}, "assets": [{"name": "a"}, {"name": "b"}]}`,
+			expectedSection: &section{
+				Tool: toolCreateRelease,
+				Lines: []string{
+					"release `v1.17.0`: https://github.com/org/repo/releases/tag/v1.17.0",
+					"2 asset(s): a, b",
+				},
+			},
+		},
+		{
+			name:          "invalid: unknown format",
+			input:         `{"foo": "bar"}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed JSON",
+			input:         `{`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseInput([]byte(tt.input))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(s, tt.expectedSection) {
+				t.Errorf("expected section:\n%+v\ngot:\n%+v", tt.expectedSection, s)
+			}
+		})
+	}
+}
+
+func TestReadInputPartialMarker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "k8s-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "output.json")
+	if err := ioutil.WriteFile(path, []byte("[]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path+pkg.PartialMarkerSuffix, []byte("interrupted\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := readInput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Partial || s.Path != path {
+		t.Errorf("expected a partial section for %q, got: %+v", path, s)
+	}
+}
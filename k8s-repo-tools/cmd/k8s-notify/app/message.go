@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultTitle is the title of a message if none is given.
+const defaultTitle = "k8s-repo-tools report"
+
+// summary is the summary of all output files.
+type summary struct {
+	Title    string     `json:"title"`
+	Failed   bool       `json:"failed"`
+	Sections []*section `json:"sections"`
+}
+
+func newSummary(title string, sections []*section) *summary {
+	if len(title) == 0 {
+		title = defaultTitle
+	}
+	s := &summary{Title: title, Sections: sections}
+	for _, sec := range sections {
+		if sec.failed() {
+			s.Failed = true
+		}
+	}
+	return s
+}
+
+// status returns a short status for the title of a message.
+func (s *summary) status() string {
+	if s.Failed {
+		return "FAILED"
+	}
+	return "OK"
+}
+
+// formatText formats the summary as text. bold is the Markdown marker
+// for bold text of the target, which differs between services. If bold
+// is empty the text is plain.
+func formatText(s *summary, bold string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s: %s%s\n", bold, s.Title, s.status(), bold)
+	for _, sec := range s.Sections {
+		fmt.Fprintf(&b, "\n%s%s%s (%s)\n", bold, sec.Tool, bold, sec.Path)
+		for _, line := range sec.Lines {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+		if len(sec.Error) != 0 {
+			fmt.Fprintf(&b, "- error: %s\n", sec.Error)
+		}
+		if sec.Partial {
+			b.WriteString("- the result is partial, because the tool was interrupted\n")
+		}
+	}
+	return b.String()
+}
+
+// slackPayload returns the payload for a Slack incoming webhook.
+func slackPayload(s *summary) interface{} {
+	return map[string]string{"text": formatText(s, "*")}
+}
+
+// teamsPayload returns the payload for a Microsoft Teams incoming webhook
+// as a legacy "MessageCard".
+func teamsPayload(s *summary) interface{} {
+	color := "2EB886"
+	if s.Failed {
+		color = "D00000"
+	}
+	return map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    fmt.Sprintf("%s: %s", s.Title, s.status()),
+		"themeColor": color,
+		// Teams renders single new lines as spaces.
+		"text": strings.Replace(formatText(s, "**"), "\n", "\n\n", -1),
+	}
+}
+
+// genericPayload returns the payload for a generic webhook. It has the
+// summary as structured data and as plain text.
+func genericPayload(s *summary) interface{} {
+	return struct {
+		*summary
+		Text string `json:"text"`
+	}{summary: s, Text: formatText(s, "")}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	Inputs          pkg.MultiString `flag:"input" usage:"Path to a JSON output file of a tool. Multiple instances of the flag are allowed" validate:"required"`
+	Title           string          `flag:"title" usage:"Title of the message"`
+	SlackWebhookURL string          `flag:"slack-webhook-url" value:"url" usage:"URL of a Slack incoming webhook"`
+	TeamsWebhookURL string          `flag:"teams-webhook-url" value:"url" usage:"URL of a Microsoft Teams incoming webhook"`
+	WebhookURL      string          `flag:"webhook-url" value:"url" usage:"URL of a generic webhook to which the summary is sent as a JSON object"`
+	EmailTo         pkg.MultiString `flag:"email-to" usage:"Email address to which the summary is sent. Multiple instances of the flag are allowed"`
+	EmailFrom       string          `flag:"email-from" usage:"Sender address of the email" validate:"requiredif=email-to"`
+	SMTPServer      string          `flag:"smtp-server" usage:"SMTP server in the format 'host:port' to use for sending email" validate:"requiredif=email-to,hostport"`
+	SMTPUsername    string          `flag:"smtp-username" usage:"Username for authentication with the SMTP server"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Plans are not supported, since nothing is written to repositories.
+	if len(d.PlanFile) != 0 || len(d.ApplyPlanFile) != 0 {
+		return errors.Errorf("the options %q and %q are not supported", pkg.FlagPlan, pkg.FlagApplyPlan)
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// At least one notification must be sent.
+	if len(d.WebhookURL) == 0 && len(d.SlackWebhookURL) == 0 && len(d.TeamsWebhookURL) == 0 && len(d.EmailTo) == 0 {
+		return errors.Errorf("at least one of the options %q, %q, %q or %q is required",
+			pkg.FlagSlackWebhookURL, pkg.FlagTeamsWebhookURL, pkg.FlagWebhookURL, pkg.FlagEmailTo)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestValidateData(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		expectedError bool
+	}{
+		{
+			name: "valid: Slack webhook",
+			data: &pkg.Data{
+				Inputs:          []string{"output.json"},
+				SlackWebhookURL: "https://hooks.slack.com/services/foo",
+			},
+		},
+		{
+			name: "valid: email",
+			data: &pkg.Data{
+				Inputs:     []string{"output.json"},
+				EmailTo:    []string{"a@example.com"},
+				EmailFrom:  "bot@example.com",
+				SMTPServer: "smtp.example.com:587",
+			},
+		},
+		{
+			name: "invalid: no input",
+			data: &pkg.Data{
+				SlackWebhookURL: "https://hooks.slack.com/services/foo",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: no destination",
+			data: &pkg.Data{
+				Inputs: []string{"output.json"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: SMTP server without a port",
+			data: &pkg.Data{
+				Inputs:     []string{"output.json"},
+				EmailTo:    []string{"a@example.com"},
+				EmailFrom:  "bot@example.com",
+				SMTPServer: "smtp.example.com",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateData(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
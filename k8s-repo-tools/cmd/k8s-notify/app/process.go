@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// destination is a service to which the summary is sent.
+type destination struct {
+	name string
+	send func(s *summary) error
+}
+
+// destinations returns the destinations that are configured with flags.
+func destinations(d *pkg.Data, client *http.Client) []destination {
+	result := []destination{}
+	webhook := func(url string, payload func(*summary) interface{}) func(*summary) error {
+		return func(s *summary) error {
+			return postWebhook(client, url, payload(s))
+		}
+	}
+	if len(d.SlackWebhookURL) != 0 {
+		result = append(result, destination{"Slack", webhook(d.SlackWebhookURL, slackPayload)})
+	}
+	if len(d.TeamsWebhookURL) != 0 {
+		result = append(result, destination{"Microsoft Teams", webhook(d.TeamsWebhookURL, teamsPayload)})
+	}
+	if len(d.WebhookURL) != 0 {
+		result = append(result, destination{"the generic webhook", webhook(d.WebhookURL, genericPayload)})
+	}
+	if len(d.EmailTo) != 0 {
+		result = append(result, destination{"email", func(s *summary) error { return sendEmail(d, s) }})
+	}
+	return result
+}
+
+// process is responsible for all operations that the application performs.
+// It reads the input files and sends the summary to all destinations. A
+// failure to send to one destination does not prevent sending to the others.
+func process(d *pkg.Data, client *http.Client) (*summary, error) {
+	sections := make([]*section, 0, len(d.Inputs))
+	for _, path := range d.Inputs {
+		s, err := readInput(path)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, s)
+	}
+	s := newSummary(d.Title, sections)
+
+	pkg.PrintSeparator()
+	pkg.Logf("summary:\n%s", formatText(s, ""))
+	pkg.PrintSeparator()
+
+	var failed int
+	dests := destinations(d, client)
+	for _, dest := range dests {
+		if d.DryRun {
+			pkg.Logf("%s: would send the summary to %s", pkg.PrefixDryRun, dest.name)
+			continue
+		}
+		pkg.Logf("sending the summary to %s", dest.name)
+		if err := dest.send(s); err != nil {
+			pkg.Warningf("could not send the summary to %s: %v", dest.name, err)
+			failed++
+		}
+	}
+	if failed != 0 {
+		return s, errors.Errorf("could not send the summary to %d of %d destination(s)", failed, len(dests))
+	}
+	return s, nil
+}
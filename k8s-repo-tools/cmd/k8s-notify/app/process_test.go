@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "output.json")
+	const contents = `[{"ref": "refs/tags/v1.17.0", "object": {"sha": "02a9c9f39a18ee40c37835c36c7c80e0797b0d85"}}]`
+	if err := ioutil.WriteFile(input, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		slackURL   = "https://hooks.slack.com/services/foo"
+		genericURL = "https://example.com/hook"
+	)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		status        int
+		expectedSent  []string
+		expectedError bool
+		skipDryRun    bool
+	}{
+		{
+			name: "valid: send to Slack, a generic webhook and email",
+			data: &pkg.Data{
+				SlackWebhookURL: slackURL,
+				WebhookURL:      genericURL,
+				EmailTo:         []string{"a@example.com"},
+				EmailFrom:       "bot@example.com",
+				SMTPServer:      "smtp.example.com:25",
+			},
+			status:       http.StatusOK,
+			expectedSent: []string{slackURL, genericURL, "a@example.com"},
+		},
+		{
+			name:          "invalid: webhook returned an error status",
+			data:          &pkg.Data{SlackWebhookURL: slackURL},
+			status:        http.StatusNotFound,
+			expectedSent:  []string{slackURL},
+			expectedError: true,
+			skipDryRun:    true,
+		},
+		{
+			name:          "invalid: missing input file",
+			data:          &pkg.Data{Inputs: []string{filepath.Join(dir, "missing.json")}, SlackWebhookURL: slackURL},
+			expectedError: true,
+		},
+	}
+
+	defer func(f func(string, smtp.Auth, string, []string, []byte) error) { sendMail = f }(sendMail)
+
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Nothing is sent in dry-run mode, so sending cannot fail.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				d := *tt.data
+				d.DryRun = dryRunVal
+				if len(d.Inputs) == 0 {
+					d.Inputs = []string{input}
+				}
+
+				sent := []string{}
+				handler := func(req *http.Request) (*http.Response, error) {
+					body, err := ioutil.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					if !strings.Contains(string(body), "v1.17.0") {
+						t.Errorf("expected the payload to contain the tag, got: %s", body)
+					}
+					sent = append(sent, req.URL.String())
+					return &http.Response{
+						StatusCode: tt.status,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("ok")),
+						Header:     http.Header{},
+					}, nil
+				}
+				transport := pkg.NewTransport()
+				transport.SetHandler(slackURL, handler)
+				transport.SetHandler(genericURL, handler)
+				sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+					if !strings.Contains(string(msg), "Subject: "+defaultTitle+": OK") {
+						t.Errorf("unexpected email:\n%s", msg)
+					}
+					sent = append(sent, to...)
+					return nil
+				}
+
+				_, err := process(&d, &http.Client{Transport: transport})
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+
+				// In dry-run mode nothing is sent.
+				expectedSent := tt.expectedSent
+				if dryRunVal || expectedSent == nil {
+					expectedSent = []string{}
+				}
+				if strings.Join(sent, ",") != strings.Join(expectedSent, ",") {
+					t.Errorf("expected sent %v, got %v", expectedSent, sent)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// smtpPasswordEnv is the environment variable with the password for the
+// SMTP server. It is not a flag, so that it does not end up in process lists.
+const smtpPasswordEnv = pkg.EnvPrefix + "SMTP_PASSWORD"
+
+// sendMail sends an email. It can be replaced in tests.
+var sendMail = smtp.SendMail
+
+// postWebhook sends a payload as JSON to a webhook URL.
+func postWebhook(client *http.Client, url string, payload interface{}) error {
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("received status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail sends the summary as a plain text email.
+func sendEmail(d *pkg.Data, s *summary) error {
+	host, _, err := net.SplitHostPort(d.SMTPServer)
+	if err != nil {
+		return errors.Wrapf(err, "invalid SMTP server %q", d.SMTPServer)
+	}
+	var auth smtp.Auth
+	if len(d.SMTPUsername) != 0 {
+		auth = smtp.PlainAuth("", d.SMTPUsername, os.Getenv(smtpPasswordEnv), host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", d.EmailFrom)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(d.EmailTo, ", "))
+	fmt.Fprintf(&b, "Subject: %s: %s\r\n", s.Title, s.status())
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(strings.Replace(formatText(s, ""), "\n", "\r\n", -1))
+
+	return sendMail(d.SMTPServer, auth, d.EmailFrom, d.EmailTo, []byte(b.String()))
+}
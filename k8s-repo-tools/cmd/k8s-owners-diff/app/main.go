@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-owners-diff"
+
+// exitCodeDrift is the exit code if drift was found.
+// It differs from the exit code for errors, so that periodic jobs
+// can tell them apart.
+const exitCodeDrift = 3
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-owners-diff is a tool for comparing the approvers and reviewers "+
+		"in the OWNERS files of two repositories or branches")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-owners-diff -source=org/repo -dest=org/repo -token=<token> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM.
+	stop := pkg.HandleSignals(&d)
+	defer stop()
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(&d, nil)
+	r, err := process(&d)
+	if err != nil && d.Interrupted() {
+		pkg.ExitInterrupted(err)
+	}
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Print the result to stdout.
+	buf, err := formatOutput(r, &d)
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+	fmt.Println(string(buf))
+
+	if len(r.Drift) != 0 {
+		pkg.Errorf("found drift in %d field(s)", len(r.Drift))
+		pkg.FinishRun(exitCodeDrift, nil)
+		os.Exit(exitCodeDrift)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Source       []string `flag:"source" usage:"Source GitHub repository in the format org/repo" validate:"required,repo"`
+	SourceBranch string   `flag:"source-branch" default:"master" usage:"Branch of the source repository" validate:"required"`
+	SourcePath   string   `flag:"source-path" usage:"Directory in the source repository. Defaults to the root of the repository" validate:"relpath"`
+	Dest         []string `flag:"dest" usage:"Destination GitHub repository in the format org/repo" validate:"required,repo"`
+	DestBranch   string   `flag:"dest-branch" default:"master" usage:"Branch of the destination repository" validate:"required"`
+	DestPath     string   `flag:"dest-path" usage:"Directory in the destination repository. Defaults to the root of the repository" validate:"relpath"`
+	OutputFormat string   `flag:"output-format" default:"text" usage:"Format of the output. One of \"text\" or \"json\"" validate:"oneof=text|json"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Comparing a location with itself is a mistake.
+	if d.SourceRepo() == d.DestRepo() && d.SourceBranch == d.DestBranch && d.SourcePath == d.DestPath {
+		return errors.Errorf("the options %q, %q and %q must not be the same as %q, %q and %q",
+			pkg.FlagSource, pkg.FlagSourceBranch, pkg.FlagSourcePath,
+			pkg.FlagDest, pkg.FlagDestBranch, pkg.FlagDestPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// formatOutput formats the report in the output format from d.OutputFormat.
+// The text format is a table of the drift, while the JSON format is the report object.
+func formatOutput(r *report, d *pkg.Data) ([]byte, error) {
+	if d.OutputFormat == pkg.OutputFormatJSON {
+		return json.MarshalIndent(r, "", "\t")
+	}
+	if len(r.Drift) == 0 {
+		return []byte(fmt.Sprintf("no drift found between %s and %s", r.Source, r.Dest)), nil
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "source: %s\ndest: %s\n\n", r.Source, r.Dest)
+	tabW := tabwriter.NewWriter(&b, 12, 0, 2, ' ', 0)
+	fmt.Fprintln(tabW, "FIELD\tONLY IN SOURCE\tONLY IN DEST")
+	for _, v := range r.Drift {
+		fmt.Fprintf(tabW, "%s\t%s\t%s\n", v.Field, formatNames(v.OnlyInSource), formatNames(v.OnlyInDest))
+	}
+	tabW.Flush()
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// formatNames joins a list of names with commas or returns "-" for an empty list.
+func formatNames(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	fileOwners        = "OWNERS"
+	fileOwnersAliases = "OWNERS_ALIASES"
+)
+
+// ownersFile is the subset of an OWNERS file that is compared.
+type ownersFile struct {
+	Approvers []string `json:"approvers,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// aliasesFile is the structure of an OWNERS_ALIASES file.
+type aliasesFile struct {
+	Aliases map[string][]string `json:"aliases,omitempty"`
+}
+
+// parseOwners parses the contents of an OWNERS file.
+func parseOwners(data []byte) (*ownersFile, error) {
+	f := &ownersFile{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse %s", fileOwners)
+	}
+	return f, nil
+}
+
+// parseAliases parses the contents of an OWNERS_ALIASES file.
+// The names of the aliases and their members are converted to lower case,
+// since GitHub handles are case insensitive.
+func parseAliases(data []byte) (map[string][]string, error) {
+	f := &aliasesFile{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse %s", fileOwnersAliases)
+	}
+	aliases := map[string][]string{}
+	for name, members := range f.Aliases {
+		aliases[strings.ToLower(name)] = normalize(members)
+	}
+	return aliases, nil
+}
+
+// resolve expands the aliases in a list of approvers or reviewers and returns
+// a sorted list of unique GitHub handles in lower case.
+func resolve(names []string, aliases map[string][]string) []string {
+	var result []string
+	for _, name := range normalize(names) {
+		if members, ok := aliases[name]; ok {
+			result = append(result, members...)
+			continue
+		}
+		result = append(result, name)
+	}
+	return normalize(result)
+}
+
+// normalize returns a sorted list of unique, lower case names.
+func normalize(names []string) []string {
+	set := map[string]bool{}
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); len(name) != 0 {
+			set[name] = true
+		}
+	}
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	aliases, err := parseAliases([]byte(`
+aliases:
+  SIG-Foo:
+  - Alice
+  - bob
+  sig-bar:
+  - carol
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{
+			name:     "valid: no names",
+			expected: []string{},
+		},
+		{
+			name:     "valid: aliases are expanded",
+			names:    []string{"sig-foo", "dave"},
+			expected: []string{"alice", "bob", "dave"},
+		},
+		{
+			name:     "valid: names are unique and in lower case",
+			names:    []string{"Carol", "sig-bar", "Sig-Foo", "alice"},
+			expected: []string{"alice", "bob", "carol"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := resolve(tt.names, aliases); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseOwners(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expected      *ownersFile
+		expectedError bool
+	}{
+		{
+			name: "valid: approvers and reviewers",
+			data: "approvers:\n- alice\nreviewers:\n- bob\nlabels:\n- area/foo\n",
+			expected: &ownersFile{
+				Approvers: []string{"alice"},
+				Reviewers: []string{"bob"},
+			},
+		},
+		{
+			name:          "invalid: malformed YAML",
+			data:          "approvers: [",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseOwners([]byte(tt.data))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err == nil && !reflect.DeepEqual(f, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// location is a directory in a branch of a repository.
+type location struct {
+	Repo   string
+	Branch string
+	Path   string
+}
+
+// String returns the location in the format "org/repo@branch[:path]".
+func (l location) String() string {
+	s := fmt.Sprintf("%s@%s", l.Repo, l.Branch)
+	if len(l.Path) != 0 {
+		s += ":" + l.Path
+	}
+	return s
+}
+
+// owners holds the approvers and reviewers of a location with the aliases resolved.
+type owners struct {
+	Approvers []string
+	Reviewers []string
+	Aliases   map[string][]string
+}
+
+// report is the result of a comparison.
+type report struct {
+	Source string   `json:"source"`
+	Dest   string   `json:"dest"`
+	Drift  []*drift `json:"drift"`
+}
+
+// drift is a list of GitHub handles that differ between the source and the destination.
+type drift struct {
+	Field        string   `json:"field"`
+	OnlyInSource []string `json:"onlyInSource"`
+	OnlyInDest   []string `json:"onlyInDest"`
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*report, error) {
+	source := location{Repo: d.SourceRepo(), Branch: d.SourceBranch, Path: d.SourcePath}
+	dest := location{Repo: d.DestRepo(), Branch: d.DestBranch, Path: d.DestPath}
+
+	sourceOwners, err := getOwners(d, source)
+	if err != nil {
+		return nil, err
+	}
+	if d.Interrupted() {
+		return nil, pkg.ErrInterrupted
+	}
+	destOwners, err := getOwners(d, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &report{Source: source.String(), Dest: dest.String(), Drift: []*drift{}}
+	add := func(field string, source, dest []string) {
+		onlyInSource, onlyInDest := diff(source, dest)
+		if len(onlyInSource) != 0 || len(onlyInDest) != 0 {
+			r.Drift = append(r.Drift, &drift{Field: field, OnlyInSource: onlyInSource, OnlyInDest: onlyInDest})
+		}
+	}
+	add("approvers", sourceOwners.Approvers, destOwners.Approvers)
+	add("reviewers", sourceOwners.Reviewers, destOwners.Reviewers)
+
+	names := map[string]bool{}
+	for name := range sourceOwners.Aliases {
+		names[name] = true
+	}
+	for name := range destOwners.Aliases {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		add("aliases/"+name, sourceOwners.Aliases[name], destOwners.Aliases[name])
+	}
+	return r, nil
+}
+
+// getOwners fetches the OWNERS file of a location and resolves its aliases.
+// The OWNERS_ALIASES file is read from the same directory and if it's missing
+// there, from the root of the repository. A missing OWNERS_ALIASES file is not an error.
+func getOwners(d *pkg.Data, l location) (*owners, error) {
+	content, _, err := pkg.GitHubGetFile(d, l.Repo, l.Branch, path.Join(l.Path, fileOwners))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get the %s file from %s", fileOwners, l)
+	}
+	f, err := parseOwners([]byte(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid file in %s", l)
+	}
+
+	aliasPaths := []string{path.Join(l.Path, fileOwnersAliases)}
+	if len(l.Path) != 0 {
+		aliasPaths = append(aliasPaths, fileOwnersAliases)
+	}
+	aliases := map[string][]string{}
+	for _, p := range aliasPaths {
+		content, _, err := pkg.GitHubGetFile(d, l.Repo, l.Branch, p)
+		if pkg.ErrorKindOf(err) == pkg.ErrorKindNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get the %s file from %s", fileOwnersAliases, l)
+		}
+		if aliases, err = parseAliases([]byte(content)); err != nil {
+			return nil, errors.Wrapf(err, "invalid file in %s", l)
+		}
+		break
+	}
+
+	return &owners{
+		Approvers: resolve(f.Approvers, aliases),
+		Reviewers: resolve(f.Reviewers, aliases),
+		Aliases:   aliases,
+	}, nil
+}
+
+// diff returns the names that are only in a and the names that are only in b.
+func diff(a, b []string) ([]string, []string) {
+	inA := map[string]bool{}
+	for _, name := range a {
+		inA[name] = true
+	}
+	inB := map[string]bool{}
+	for _, name := range b {
+		inB[name] = true
+	}
+	onlyInA, onlyInB := []string{}, []string{}
+	for _, name := range a {
+		if !inB[name] {
+			onlyInA = append(onlyInA, name)
+		}
+	}
+	for _, name := range b {
+		if !inA[name] {
+			onlyInB = append(onlyInB, name)
+		}
+	}
+	return onlyInA, onlyInB
+}
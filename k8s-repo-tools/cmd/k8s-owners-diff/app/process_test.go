@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	const aliases = `
+aliases:
+  sig-foo:
+  - alice
+  - bob
+`
+	sourceFiles := map[string]string{
+		"staging/api/OWNERS": "approvers:\n- sig-foo\nreviewers:\n- carol\n",
+		"OWNERS_ALIASES":     aliases,
+	}
+
+	tests := []struct {
+		name          string
+		sourcePath    string
+		destFiles     map[string]string
+		expected      []*drift
+		expectedError bool
+	}{
+		{
+			name:       "valid: no drift with aliases from the root of the source",
+			sourcePath: "staging/api",
+			destFiles: map[string]string{
+				"OWNERS":         "approvers:\n- Alice\n- bob\nreviewers:\n- carol\n",
+				"OWNERS_ALIASES": aliases,
+			},
+			expected: []*drift{},
+		},
+		{
+			name:       "valid: drift in approvers, reviewers and aliases",
+			sourcePath: "staging/api",
+			destFiles: map[string]string{
+				"OWNERS":         "approvers:\n- sig-foo\nreviewers:\n- dave\n",
+				"OWNERS_ALIASES": "aliases:\n  sig-foo:\n  - alice\n  sig-bar:\n  - erin\n",
+			},
+			expected: []*drift{
+				{Field: "approvers", OnlyInSource: []string{"bob"}, OnlyInDest: []string{}},
+				{Field: "reviewers", OnlyInSource: []string{"carol"}, OnlyInDest: []string{"dave"}},
+				{Field: "aliases/sig-bar", OnlyInSource: []string{}, OnlyInDest: []string{"erin"}},
+				{Field: "aliases/sig-foo", OnlyInSource: []string{"bob"}, OnlyInDest: []string{}},
+			},
+		},
+		{
+			name:       "valid: missing OWNERS_ALIASES file in the destination",
+			sourcePath: "staging/api",
+			destFiles: map[string]string{
+				"OWNERS": "approvers:\n- sig-foo\nreviewers:\n- carol\n",
+			},
+			expected: []*drift{
+				{Field: "approvers", OnlyInSource: []string{"alice", "bob"}, OnlyInDest: []string{"sig-foo"}},
+				{Field: "aliases/sig-foo", OnlyInSource: []string{"alice", "bob"}, OnlyInDest: []string{}},
+			},
+		},
+		{
+			name:          "invalid: missing OWNERS file in the source",
+			destFiles:     map[string]string{"OWNERS": "approvers:\n- alice\n"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed OWNERS file in the destination",
+			sourcePath:    "staging/api",
+			destFiles:     map[string]string{"OWNERS": "approvers: ["},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Source:       []string{"org/source"},
+				SourceBranch: pkg.BranchMaster,
+				SourcePath:   tt.sourcePath,
+				Dest:         []string{"org/dest"},
+				DestBranch:   pkg.BranchMaster,
+			}
+
+			// Create fake client and setup endpoint handlers.
+			commits := []*github.RepositoryContentFileOptions{}
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/source/contents",
+				pkg.NewContentsHandler(sourceFiles, &commits, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/dest/contents",
+				pkg.NewContentsHandler(tt.destFiles, &commits, map[string]bool{}))
+
+			r, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(r.Drift, tt.expected) {
+				t.Errorf("expected drift:\n%+v\ngot:\n%+v", tt.expected, r.Drift)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultPattern matches a SemVer with a "v" prefix such as "v1.18.0" or "v1.19.0-rc.1".
+const defaultPattern = `v[0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.]+)?`
+
+// autobumpConfig is the structure of the YAML file passed with the
+// --autobump-config flag.
+type autobumpConfig struct {
+	Files []*fileConfig `json:"files"`
+}
+
+// fileConfig is a file in which to update the version. If Pattern has a
+// capture group only the first group of each match is replaced, otherwise
+// the whole match is replaced.
+type fileConfig struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern,omitempty"`
+
+	regexp *regexp.Regexp
+}
+
+// loadAutobumpConfig reads and parses the config from a path or URL.
+func loadAutobumpConfig(d *pkg.Data) (*autobumpConfig, error) {
+	pkg.Logf("reading the autobump config from %q", d.AutobumpConfig)
+	data, err := pkg.ReadFromFileOrURL(d.AutobumpConfig, d.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return parseAutobumpConfig(data)
+}
+
+// parseAutobumpConfig parses and validates the contents of an autobump config.
+// Files without a pattern use the default SemVer pattern.
+func parseAutobumpConfig(data []byte) (*autobumpConfig, error) {
+	c := &autobumpConfig{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrap(err, "cannot parse autobump config")
+	}
+	if len(c.Files) == 0 {
+		return nil, errors.New("the autobump config must have at least one file in 'files'")
+	}
+	seen := map[string]bool{}
+	for i, f := range c.Files {
+		if len(f.Path) == 0 {
+			return nil, errors.Errorf("file %d in the autobump config must have a 'path'", i)
+		}
+		if seen[f.Path] {
+			return nil, errors.Errorf("duplicate file %q in the autobump config", f.Path)
+		}
+		seen[f.Path] = true
+		if len(f.Pattern) == 0 {
+			f.Pattern = defaultPattern
+		}
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern for file %q in the autobump config", f.Path)
+		}
+		f.regexp = re
+	}
+	return c, nil
+}
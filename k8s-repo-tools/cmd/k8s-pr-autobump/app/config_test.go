@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+)
+
+func TestParseAutobumpConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             string
+		expectedPatterns []string
+		expectedError    bool
+	}{
+		{
+			name:             "valid: default and custom patterns",
+			data:             "files:\n- path: VERSION\n- path: manifest.yaml\n  pattern: 'kubeadm:(\\S+)'\n",
+			expectedPatterns: []string{defaultPattern, `kubeadm:(\S+)`},
+		},
+		{
+			name:          "invalid: no files",
+			data:          "files: []\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing path",
+			data:          "files:\n- pattern: foo\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: duplicate path",
+			data:          "files:\n- path: VERSION\n- path: VERSION\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed pattern",
+			data:          "files:\n- path: VERSION\n  pattern: '('\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown field",
+			data:          "files:\n- path: VERSION\n  regexp: foo\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseAutobumpConfig([]byte(tt.data))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(cfg.Files) != len(tt.expectedPatterns) {
+				t.Fatalf("expected %d files, got %d", len(tt.expectedPatterns), len(cfg.Files))
+			}
+			for i, f := range cfg.Files {
+				if f.Pattern != tt.expectedPatterns[i] || f.regexp == nil {
+					t.Errorf("expected pattern %q for file %q, got %q", tt.expectedPatterns[i], f.Path, f.Pattern)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Source         []string        `flag:"source" usage:"Source org/repo from which to take the latest tag" validate:"required,repo"`
+	Dest           []string        `flag:"dest" usage:"Destination org/repo in which to update the files" validate:"required,repo"`
+	AutobumpConfig string          `flag:"autobump-config" usage:"Path or URL to a YAML file with the files in which to update the version" validate:"required"`
+	Branches       pkg.MultiString `flag:"branch" usage:"Use the latest tag for the MAJOR.MINOR of this branch in the format \"prefixMAJOR.MINOR\". Defaults to the latest MAJOR.MINOR of all tags" validate:"single"`
+	PrefixBranch   string          `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	StableOnly     bool            `flag:"stable-only" usage:"Ignore pre-release tags such as 'v1.17.4-rc.1'"`
+	BaseBranch     string          `flag:"base-branch" default:"master" usage:"Name of the branch to commit to. If a pull request is created this is the base branch of the pull request" validate:"required"`
+	Labels         pkg.MultiString `flag:"label" usage:"Label to add to created pull requests. Multiple instances of the flag are allowed"`
+	Output         string          `flag:"output" usage:"Path to a file that will be written with the tag, the changed files and the pull request"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	return pkg.ValidateOptions(d, &options{})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestValidateData(t *testing.T) {
+	const validToken = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		expectedError bool
+	}{
+		{
+			name: "valid: all fields are valid",
+			data: &pkg.Data{
+				Token:          validToken,
+				Source:         []string{"org/src"},
+				Dest:           []string{"org/dest"},
+				AutobumpConfig: "autobump.yaml",
+				BaseBranch:     pkg.BranchMaster,
+				Branches:       []string{"release-1.18"},
+			},
+		},
+		{
+			name: "invalid: missing autobump config",
+			data: &pkg.Data{
+				Token:      validToken,
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
+				BaseBranch: pkg.BranchMaster,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: malformed source repository",
+			data: &pkg.Data{
+				Token:          validToken,
+				Source:         []string{"src"},
+				Dest:           []string{"org/dest"},
+				AutobumpConfig: "autobump.yaml",
+				BaseBranch:     pkg.BranchMaster,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: multiple branches",
+			data: &pkg.Data{
+				Token:          validToken,
+				Source:         []string{"org/src"},
+				Dest:           []string{"org/dest"},
+				AutobumpConfig: "autobump.yaml",
+				BaseBranch:     pkg.BranchMaster,
+				Branches:       []string{"release-1.17", "release-1.18"},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateData(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
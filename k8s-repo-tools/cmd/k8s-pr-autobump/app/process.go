@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// result holds the tag to which the files were bumped, the paths of the files
+// that were changed and the branch and pull request with the changes.
+type result struct {
+	Tag         string              `json:"tag"`
+	Files       []string            `json:"files"`
+	Branch      *string             `json:"branch"`
+	PullRequest *github.PullRequest `json:"pullRequest"`
+}
+
+// process is responsible for all operations that the application performs.
+// The branch and the pull request in the result are nil if no file was changed.
+func process(d *pkg.Data, cfg *autobumpConfig) (*result, error) {
+
+	// Obtain the latest tag from the source repository.
+	tags, err := pkg.GitHubGetTags(d, d.SourceRepo())
+	if err != nil {
+		return nil, err
+	}
+	var branch string
+	if len(d.Branches) != 0 {
+		branch = d.Branches[0]
+	}
+	latest, err := findLatestTag(tags, branch, d.PrefixBranch, d.StableOnly)
+	if err != nil {
+		return nil, err
+	}
+	tag := strings.TrimPrefix(latest.GetRef(), "refs/tags/")
+	pkg.Logf("the latest tag in repository %q is %q", d.SourceRepo(), tag)
+
+	// Update the version in the files from the base branch.
+	res := &result{Tag: tag, Files: []string{}}
+	type update struct {
+		path, content, sha string
+	}
+	var updates []update
+	for _, f := range cfg.Files {
+		content, sha, err := pkg.GitHubGetFile(d, d.DestRepo(), d.BaseBranch, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		bumped := bumpVersion(content, f, tag)
+		if bumped == content {
+			pkg.V(1).Logf("the file %q is up to date", f.Path)
+			continue
+		}
+		updates = append(updates, update{path: f.Path, content: bumped, sha: sha})
+		res.Files = append(res.Files, f.Path)
+	}
+	if len(updates) == 0 {
+		pkg.Logf("all files in branch %q of repository %q are up to date with %q", d.BaseBranch, d.DestRepo(), tag)
+		return res, nil
+	}
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create a pull request that bumps %d file(s) to %q in repository %q?",
+		len(updates), tag, d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return res, nil
+	}
+
+	// Create a new branch from the HEAD of the base branch.
+	head := "autobump-" + tag
+	branches, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	var base *github.Reference
+	for _, b := range branches {
+		switch b.GetRef() {
+		case "refs/heads/" + head:
+			return nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the branch %q already exists in repository %q", head, d.DestRepo())
+		case "refs/heads/" + d.BaseBranch:
+			base = b
+		}
+	}
+	if base == nil {
+		return nil, errors.Errorf("could not find branch %q in repository %q", d.BaseBranch, d.DestRepo())
+	}
+	if _, err := pkg.GitHubCreateRef(d, d.DestRepo(), "refs/heads/"+head, base.GetObject().GetSHA(), d.DryRun); err != nil {
+		return nil, err
+	}
+	res.Branch = github.String(head)
+
+	// Commit the files to the new branch and create a pull request.
+	message := fmt.Sprintf("Bump %s to %s", d.SourceRepo(), tag)
+	for _, u := range updates {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		if err := pkg.GitHubUpdateFile(d, d.DestRepo(), head, u.path, message, u.content, u.sha, d.DryRun); err != nil {
+			return res, err
+		}
+	}
+	body := fmt.Sprintf("Bump the version to the latest tag %s from %s in:\n\n- %s\n",
+		tag, d.SourceRepo(), strings.Join(res.Files, "\n- "))
+	pr, err := pkg.GitHubCreatePullRequest(d, d.DestRepo(), d.BaseBranch, head, message, body, d.Labels, d.DryRun)
+	if err != nil {
+		return res, err
+	}
+	res.PullRequest = pr
+	return res, nil
+}
+
+// findLatestTag returns the latest tag for the MAJOR.MINOR of a branch. If the branch
+// is empty the latest MAJOR.MINOR of all tags is used. If stableOnly is true
+// pre-release tags are ignored.
+func findLatestTag(tags []*github.Reference, branch, prefix string, stableOnly bool) (*github.Reference, error) {
+	var candidates []*github.Reference
+	var latestV *version.Version
+	for _, tag := range tags {
+		v, err := pkg.TagRefToVersion(tag)
+		if err != nil {
+			pkg.V(2).Logf("skipping tag %q: %v", tag.GetRef(), err)
+			continue
+		}
+		if stableOnly && len(v.PreRelease()) != 0 {
+			continue
+		}
+		candidates = append(candidates, tag)
+		if latestV == nil || latestV.LessThan(v) {
+			latestV = v
+		}
+	}
+	if latestV == nil {
+		return nil, errors.New("could not find any SemVer tags")
+	}
+
+	branchV := latestV
+	if len(branch) != 0 {
+		var err error
+		branchV, err = pkg.BranchRefToVersion(&github.Reference{Ref: github.String("refs/heads/" + branch)}, prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pkg.FindLatestTag(candidates, branchV, false)
+}
+
+// bumpVersion replaces the versions that the pattern of a file matches with the tag.
+// Versions that are newer than the tag are kept, so that a file is never downgraded.
+func bumpVersion(content string, f *fileConfig, tag string) string {
+	tagV, err := pkg.TagToVersion(tag)
+	if err != nil {
+		return content
+	}
+	replace := func(old string) string {
+		if v, err := pkg.TagToVersion(old); err == nil && tagV.LessThan(v) {
+			pkg.Warningf("keeping version %q in file %q, since it is newer than %q", old, f.Path, tag)
+			return old
+		}
+		return tag
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range f.regexp.FindAllStringSubmatchIndex(content, -1) {
+		// Replace the first capture group if there is one, otherwise the whole match.
+		start, end := m[0], m[1]
+		if len(m) > 2 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(replace(content[start:end]))
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
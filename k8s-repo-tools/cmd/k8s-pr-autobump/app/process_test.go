@@ -0,0 +1,279 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	const shaMaster = "1111111111111111111111111111111111111111"
+	newRef := func(ref string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(shaMaster)}}
+	}
+	tagsSrc := []*github.Reference{
+		newRef("refs/tags/v1.17.4"),
+		newRef("refs/tags/v1.18.1"),
+		newRef("refs/tags/v1.19.0-rc.0"),
+	}
+	cfg, err := parseAutobumpConfig([]byte(`
+files:
+- path: VERSION
+- path: manifests/kubeadm.yaml
+  pattern: 'image: kubeadm:(\S+)'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                 string
+		branches             []string
+		stableOnly           bool
+		files                map[string]string
+		refsDest             []*github.Reference
+		methodErrorsContents map[string]bool
+		expectedTag          string
+		expectedFiles        map[string]string
+		expectedPullRequest  bool
+		expectedSteps        int
+		expectedError        bool
+		expectedErrorKind    pkg.ErrorKind
+	}{
+		{
+			name:       "valid: bump the files to the latest stable tag",
+			stableOnly: true,
+			files: map[string]string{
+				"VERSION":                "v1.17.4\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.17.4\nimage: etcd:v3.4.3\n",
+			},
+			refsDest:    []*github.Reference{newRef("refs/heads/master")},
+			expectedTag: "v1.18.1",
+			expectedFiles: map[string]string{
+				"VERSION":                "v1.18.1\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.18.1\nimage: etcd:v3.4.3\n",
+			},
+			expectedPullRequest: true,
+			expectedSteps:       4,
+		},
+		{
+			name: "valid: bump only the files that differ to the latest tag of a branch",
+			branches: []string{
+				"release-1.17",
+			},
+			files: map[string]string{
+				"VERSION":                "v1.17.3\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.17.4\n",
+			},
+			refsDest:    []*github.Reference{newRef("refs/heads/master")},
+			expectedTag: "v1.17.4",
+			expectedFiles: map[string]string{
+				"VERSION":                "v1.17.4\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.17.4\n",
+			},
+			expectedPullRequest: true,
+			expectedSteps:       3,
+		},
+		{
+			name: "valid: the files are up to date with the latest tag",
+			files: map[string]string{
+				"VERSION":                "v1.19.0-rc.0\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.19.0-rc.0\n",
+			},
+			expectedTag: "v1.19.0-rc.0",
+			expectedFiles: map[string]string{
+				"VERSION":                "v1.19.0-rc.0\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.19.0-rc.0\n",
+			},
+		},
+		{
+			name:       "invalid: the branch for the pull request already exists",
+			stableOnly: true,
+			files: map[string]string{
+				"VERSION":                "v1.17.4\n",
+				"manifests/kubeadm.yaml": "image: kubeadm:v1.17.4\n",
+			},
+			refsDest: []*github.Reference{
+				newRef("refs/heads/master"),
+				newRef("refs/heads/autobump-v1.18.1"),
+			},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name:              "invalid: missing file",
+			files:             map[string]string{"VERSION": "v1.17.4\n"},
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindNotFound,
+		},
+		{
+			name:                 "invalid: simulated error when getting the files",
+			files:                map[string]string{},
+			methodErrorsContents: map[string]bool{http.MethodGet: true},
+			expectedError:        true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				data := &pkg.Data{
+					Source:       []string{"org/src"},
+					Dest:         []string{"org/dest"},
+					Branches:     tt.branches,
+					PrefixBranch: pkg.PrefixBranch,
+					StableOnly:   tt.stableOnly,
+					BaseBranch:   pkg.BranchMaster,
+					Labels:       []string{"kind/cleanup"},
+					DryRun:       dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handlers modify it.
+				files := map[string]string{}
+				for k, v := range tt.files {
+					files[k] = v
+				}
+				refsSrc := append([]*github.Reference{}, tagsSrc...)
+				refsDest := append([]*github.Reference{}, tt.refsDest...)
+				commits := []*github.RepositoryContentFileOptions{}
+				prs := []*github.PullRequest{}
+				labels := []string{}
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/src/git/refs",
+					pkg.NewReferenceHandler(&refsSrc, map[string]bool{}))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/contents",
+					pkg.NewContentsHandler(files, &commits, tt.methodErrorsContents))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs",
+					pkg.NewReferenceHandler(&refsDest, map[string]bool{}))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/pulls",
+					pkg.NewPullRequestHandler(&prs, map[string]bool{}))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/issues",
+					pkg.NewIssueLabelsHandler(&labels, map[string]bool{}))
+
+				res, err := process(data, cfg)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					if kind := pkg.ErrorKindOf(err); len(tt.expectedErrorKind) != 0 && kind != tt.expectedErrorKind {
+						t.Errorf("expected error kind %q, got %q, error: %v", tt.expectedErrorKind, kind, err)
+					}
+					return
+				}
+				if res.Tag != tt.expectedTag {
+					t.Errorf("expected tag %q, got %q", tt.expectedTag, res.Tag)
+				}
+				if (res.PullRequest != nil) != tt.expectedPullRequest {
+					t.Errorf("expected pull request %v, got %v", tt.expectedPullRequest, res.PullRequest != nil)
+				}
+
+				// In dry-run mode nothing is written, but the writes are recorded in the plan.
+				if dryRunVal {
+					if steps := len(data.GetPlanSteps()); steps != tt.expectedSteps {
+						t.Errorf("expected %d plan steps, got %d", tt.expectedSteps, steps)
+					}
+					if len(commits) != 0 || len(prs) != 0 {
+						t.Errorf("expected no commits and pull requests in dry-run mode")
+					}
+					return
+				}
+				for path, expected := range tt.expectedFiles {
+					if files[path] != expected {
+						t.Errorf("expected file %q:\n%q\ngot:\n%q", path, expected, files[path])
+					}
+				}
+				for _, c := range commits {
+					if c.GetBranch() != "autobump-"+tt.expectedTag {
+						t.Errorf("expected a commit to branch %q, got %q", "autobump-"+tt.expectedTag, c.GetBranch())
+					}
+				}
+				if tt.expectedPullRequest && len(prs) != 1 {
+					t.Errorf("expected one pull request, got %d", len(prs))
+				}
+			})
+		}
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	cfg, err := parseAutobumpConfig([]byte(`
+files:
+- path: VERSION
+- path: manifest.yaml
+  pattern: 'kubeadm:(\S+)'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		file     *fileConfig
+		content  string
+		expected string
+	}{
+		{
+			name:     "valid: replace all matches of the default pattern",
+			file:     cfg.Files[0],
+			content:  "v1.17.0 and v1.18.0-beta.1\n",
+			expected: "v1.18.1 and v1.18.1\n",
+		},
+		{
+			name:     "valid: replace only the capture group",
+			file:     cfg.Files[1],
+			content:  "image: kubeadm:v1.17.0\nimage: etcd:v3.4.3\n",
+			expected: "image: kubeadm:v1.18.1\nimage: etcd:v3.4.3\n",
+		},
+		{
+			name:     "valid: newer versions are kept",
+			file:     cfg.Files[0],
+			content:  "v1.19.0\n",
+			expected: "v1.19.0\n",
+		},
+		{
+			name:     "valid: no matches",
+			file:     cfg.Files[1],
+			content:  "image: etcd:v3.4.3\n",
+			expected: "image: etcd:v3.4.3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bumpVersion(tt.content, tt.file, "v1.18.1"); got != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, got)
+			}
+		})
+	}
+}
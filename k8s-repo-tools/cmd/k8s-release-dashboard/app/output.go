@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// defaultTitle is the title of the dashboard if d.Title is empty.
+const defaultTitle = "Release dashboard"
+
+// formatOutput renders the dashboard in the output format from d.OutputFormat.
+// The text format is Markdown, the HTML format is a static page and the JSON
+// format is the dashboard object.
+func formatOutput(db *dashboard, d *pkg.Data) ([]byte, error) {
+	if len(db.Title) == 0 {
+		db.Title = defaultTitle
+	}
+	switch d.OutputFormat {
+	case pkg.OutputFormatJSON:
+		return json.MarshalIndent(db, "", "\t")
+	case pkg.OutputFormatHTML:
+		var b bytes.Buffer
+		if err := htmlTemplate.Execute(&b, db); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return formatMarkdown(db), nil
+}
+
+// formatMarkdown renders the dashboard as Markdown with a section per repository.
+func formatMarkdown(db *dashboard) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\nGenerated at %s.\n", db.Title, formatDate(db.GeneratedAt))
+	for _, r := range db.Repos {
+		fmt.Fprintf(&b, "\n## %s\n\n", r.Repo)
+		if r.LastRelease != nil {
+			fmt.Fprintf(&b, "Last release: %s (%s)\n\n", r.LastRelease.Tag, formatDate(r.LastRelease.Date))
+		} else {
+			fmt.Fprintf(&b, "Last release: -\n\n")
+		}
+
+		fmt.Fprintf(&b, "| Branch | Latest tag | Released | FF window |\n")
+		fmt.Fprintf(&b, "|--------|------------|----------|-----------|\n")
+		for _, s := range r.Branches {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", s.Branch, orDash(s.LatestTag), formatDatePtr(s.ReleaseDate), s.Window)
+		}
+
+		if len(r.PullRequests) == 0 {
+			fmt.Fprintf(&b, "\nNo open pull requests against release branches.\n")
+			continue
+		}
+		fmt.Fprintf(&b, "\n| Pull request | Branch | Author | Title |\n")
+		fmt.Fprintf(&b, "|--------------|--------|--------|-------|\n")
+		for _, pr := range r.PullRequests {
+			fmt.Fprintf(&b, "| [#%d](%s) | %s | @%s | %s |\n", pr.Number, pr.URL, pr.Base, pr.Author, escapeMarkdown(pr.Title))
+		}
+	}
+	return b.Bytes()
+}
+
+// formatDate formats a date as YYYY-MM-DD.
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// formatDatePtr formats a date as YYYY-MM-DD or returns "-" for nil.
+func formatDatePtr(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return formatDate(*t)
+}
+
+// orDash returns "-" for an empty string.
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
+
+// escapeMarkdown escapes characters that break a Markdown table cell.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// htmlTemplate renders the dashboard as a static HTML page.
+var htmlTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"date":    formatDate,
+	"datePtr": formatDatePtr,
+	"orDash":  orDash,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.open { color: #22863a; }
+.closed { color: #6a737d; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated at {{date .GeneratedAt}}.</p>
+{{- range .Repos}}
+<h2>{{.Repo}}</h2>
+<p>Last release: {{with .LastRelease}}{{.Tag}} ({{date .Date}}){{else}}-{{end}}</p>
+<table>
+<tr><th>Branch</th><th>Latest tag</th><th>Released</th><th>FF window</th></tr>
+{{- range .Branches}}
+<tr><td>{{.Branch}}</td><td>{{orDash .LatestTag}}</td><td>{{datePtr .ReleaseDate}}</td><td class="{{.Window}}">{{.Window}}</td></tr>
+{{- end}}
+</table>
+{{- if .PullRequests}}
+<table>
+<tr><th>Pull request</th><th>Branch</th><th>Author</th><th>Title</th></tr>
+{{- range .PullRequests}}
+<tr><td><a href="{{.URL}}">#{{.Number}}</a></td><td>{{.Base}}</td><td>@{{.Author}}</td><td>{{.Title}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No open pull requests against release branches.</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
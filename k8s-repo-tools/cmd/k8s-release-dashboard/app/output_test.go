@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestFormatOutput(t *testing.T) {
+	date := time.Date(2020, time.March, 24, 0, 0, 0, 0, time.UTC)
+	newDashboard := func() *dashboard {
+		return &dashboard{
+			GeneratedAt: date,
+			Repos: []*repoStatus{
+				{
+					Repo:        "org/a",
+					LastRelease: &release{Tag: "v1.18.0", Date: date},
+					Branches: []*branchStatus{
+						{Branch: "release-1.18", LatestTag: "v1.18.0", ReleaseDate: &date, Window: windowClosed},
+						{Branch: "release-1.19", Window: windowClosed},
+					},
+					PullRequests: []*pullRequest{
+						{Number: 1, Title: "Fix <foo> | bar", Base: "release-1.18", Author: "alice", URL: "https://github.com/org/a/pull/1"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected []string
+	}{
+		{
+			name:   "valid: markdown",
+			format: pkg.OutputFormatText,
+			expected: []string{
+				"# Release dashboard\n\nGenerated at 2020-03-24.\n",
+				"Last release: v1.18.0 (2020-03-24)",
+				"| release-1.18 | v1.18.0 | 2020-03-24 | closed |",
+				"| release-1.19 | - | - | closed |",
+				`| [#1](https://github.com/org/a/pull/1) | release-1.18 | @alice | Fix <foo> \| bar |`,
+			},
+		},
+		{
+			name:   "valid: html",
+			format: pkg.OutputFormatHTML,
+			expected: []string{
+				"<title>Release dashboard</title>",
+				"<td>release-1.19</td><td>-</td><td>-</td>",
+				"<td>Fix &lt;foo&gt; | bar</td>",
+			},
+		},
+		{
+			name:   "valid: json",
+			format: pkg.OutputFormatJSON,
+			expected: []string{
+				`"title": "Release dashboard"`,
+				`"releaseDate": null`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := formatOutput(newDashboard(), &pkg.Data{OutputFormat: tt.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range tt.expected {
+				if !strings.Contains(string(buf), e) {
+					t.Errorf("expected output to contain %q, got:\n%s", e, buf)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	windowOpen   = "open"
+	windowClosed = "closed"
+)
+
+// now is used for the time at which the dashboard is generated.
+var now = time.Now
+
+// dashboard holds the release state of all repositories.
+type dashboard struct {
+	Title       string        `json:"title"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Repos       []*repoStatus `json:"repos"`
+}
+
+// repoStatus is the release state of a repository.
+type repoStatus struct {
+	Repo         string          `json:"repo"`
+	LastRelease  *release        `json:"lastRelease"`
+	Branches     []*branchStatus `json:"branches"`
+	PullRequests []*pullRequest  `json:"pullRequests"`
+}
+
+// release is a published GitHub release.
+type release struct {
+	Tag  string    `json:"tag"`
+	Date time.Time `json:"date"`
+}
+
+// branchStatus is the state of a release branch.
+type branchStatus struct {
+	Branch      string     `json:"branch"`
+	LatestTag   string     `json:"latestTag"`
+	ReleaseDate *time.Time `json:"releaseDate"`
+	Window      string     `json:"window"`
+}
+
+// pullRequest is an open pull request against a release branch.
+type pullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Base   string `json:"base"`
+	Author string `json:"author"`
+	URL    string `json:"url"`
+}
+
+// process is responsible for all operations that the application performs.
+// It returns the release state of all repositories in d.Dest. No writes are performed.
+func process(d *pkg.Data) (*dashboard, error) {
+	minV := version.MustParseSemantic("v0.0.0")
+	if len(d.MinVersion) != 0 {
+		minV = version.MustParseSemantic(d.MinVersion)
+	}
+
+	db := &dashboard{Title: d.Title, GeneratedAt: now().UTC(), Repos: []*repoStatus{}}
+	repos := d.Dest
+	statuses := make([]*repoStatus, len(repos))
+	err := pkg.ForEachRepo(d, repos, func(i int, repo string, log *pkg.Logger) error {
+		var err error
+		statuses[i], err = getRepoStatus(d, repo, minV, log)
+		return err
+	})
+	for _, s := range statuses {
+		if s != nil {
+			db.Repos = append(db.Repos, s)
+		}
+	}
+	return db, err
+}
+
+// getRepoStatus returns the release state of a repository. Only release branches
+// that are not older than minV are included, starting from the latest branch.
+func getRepoStatus(d *pkg.Data, repo string, minV *version.Version, log *pkg.Logger) (*repoStatus, error) {
+	s := &repoStatus{Repo: repo, Branches: []*branchStatus{}, PullRequests: []*pullRequest{}}
+
+	tags, err := pkg.GitHubGetTags(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := pkg.GitHubGetBranches(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := pkg.GitHubGetReleases(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := pkg.GitHubGetPullRequests(d, repo, "open")
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the tags of the published releases to their dates.
+	releaseDates := map[string]time.Time{}
+	for _, r := range releases {
+		if r.GetDraft() || r.PublishedAt == nil {
+			continue
+		}
+		date := r.GetPublishedAt().Time
+		releaseDates[r.GetTagName()] = date
+		if s.LastRelease == nil || s.LastRelease.Date.Before(date) {
+			s.LastRelease = &release{Tag: r.GetTagName(), Date: date}
+		}
+	}
+
+	type versionRef struct {
+		v   *version.Version
+		ref *github.Reference
+	}
+	releaseBranches := []versionRef{}
+	// In strict mode fail on tags and branches that are not versioned.
+	if d.Strict {
+		if _, err := pkg.TrimTags(tags, minV, true); err != nil {
+			return nil, err
+		}
+	}
+	trimmedBranches, err := pkg.TrimBranches(branches, minV, d.PrefixBranch, d.Strict)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range trimmedBranches {
+		v, _ := pkg.BranchRefToVersion(ref, d.PrefixBranch)
+		releaseBranches = append(releaseBranches, versionRef{v: v, ref: ref})
+	}
+	sort.Slice(releaseBranches, func(i, j int) bool {
+		return releaseBranches[j].v.LessThan(releaseBranches[i].v)
+	})
+	for _, b := range releaseBranches {
+		bs := &branchStatus{
+			Branch: strings.TrimPrefix(b.ref.GetRef(), "refs/heads/"),
+			Window: windowClosed,
+		}
+		// A branch without tags cannot be in the fast-forward window.
+		if tag, err := pkg.FindLatestTag(tags, b.v, false); err == nil {
+			bs.LatestTag = strings.TrimPrefix(tag.GetRef(), "refs/tags/")
+			if date, ok := releaseDates[bs.LatestTag]; ok {
+				bs.ReleaseDate = &date
+			}
+			if tagV, err := pkg.TagRefToVersion(tag); err == nil && pkg.InFastForwardWindow(tagV, b.v) {
+				bs.Window = windowOpen
+			}
+		} else {
+			log.V(1).Logf("%v", err)
+		}
+		s.Branches = append(s.Branches, bs)
+	}
+
+	for _, pr := range prs {
+		if !strings.HasPrefix(pr.GetBase().GetRef(), d.PrefixBranch) {
+			continue
+		}
+		s.PullRequests = append(s.PullRequests, &pullRequest{
+			Number: pr.GetNumber(),
+			Title:  pr.GetTitle(),
+			Base:   pr.GetBase().GetRef(),
+			Author: pr.GetUser().GetLogin(),
+			URL:    pr.GetHTMLURL(),
+		})
+	}
+	sort.Slice(s.PullRequests, func(i, j int) bool {
+		return s.PullRequests[i].Number < s.PullRequests[j].Number
+	})
+	return s, nil
+}
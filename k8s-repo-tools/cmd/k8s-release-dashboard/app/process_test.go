@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	generatedAt := time.Date(2020, time.March, 30, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return generatedAt }
+	defer func() { now = time.Now }()
+
+	newRef := func(ref string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String("sha")}}
+	}
+	refsA := []*github.Reference{
+		newRef("refs/heads/master"),
+		newRef("refs/heads/release-1.16"),
+		newRef("refs/heads/release-1.17"),
+		newRef("refs/heads/release-1.18"),
+		newRef("refs/tags/v1.16.3"),
+		newRef("refs/tags/v1.17.4"),
+		newRef("refs/tags/v1.18.0-beta.1"),
+	}
+	refsB := []*github.Reference{
+		newRef("refs/heads/master"),
+		newRef("refs/heads/release-1.18"),
+	}
+	dateA := time.Date(2020, time.March, 12, 0, 0, 0, 0, time.UTC)
+	dateB := time.Date(2020, time.March, 24, 0, 0, 0, 0, time.UTC)
+	releasesA := []*github.RepositoryRelease{
+		{TagName: github.String("v1.17.4"), PublishedAt: &github.Timestamp{Time: dateA}},
+		{TagName: github.String("v1.18.0-beta.1"), PublishedAt: &github.Timestamp{Time: dateB}},
+		{TagName: github.String("v1.18.0-beta.2"), Draft: github.Bool(true)},
+	}
+	newPR := func(number int, base, state string) *github.PullRequest {
+		return &github.PullRequest{
+			Number:  github.Int(number),
+			Title:   github.String("Cherry pick"),
+			State:   github.String(state),
+			Base:    &github.PullRequestBranch{Ref: github.String(base)},
+			User:    &github.User{Login: github.String("alice")},
+			HTMLURL: github.String("https://github.com/org/a/pull/1"),
+		}
+	}
+	prsA := []*github.PullRequest{
+		newPR(3, "release-1.17", "open"),
+		newPR(2, "master", "open"),
+		newPR(1, "release-1.18", "open"),
+		newPR(4, "release-1.18", "closed"),
+	}
+
+	tests := []struct {
+		name              string
+		dest              []string
+		minVersion        string
+		methodErrors      map[string]bool
+		expectedDashboard *dashboard
+		expectedError     bool
+	}{
+		{
+			name:       "valid: multiple repositories",
+			dest:       []string{"org/a", "org/b"},
+			minVersion: "v1.17.0",
+			expectedDashboard: &dashboard{
+				Title:       "Releases",
+				GeneratedAt: generatedAt,
+				Repos: []*repoStatus{
+					{
+						Repo:        "org/a",
+						LastRelease: &release{Tag: "v1.18.0-beta.1", Date: dateB},
+						Branches: []*branchStatus{
+							{Branch: "release-1.18", LatestTag: "v1.18.0-beta.1", ReleaseDate: &dateB, Window: windowOpen},
+							{Branch: "release-1.17", LatestTag: "v1.17.4", ReleaseDate: &dateA, Window: windowClosed},
+						},
+						PullRequests: []*pullRequest{
+							{Number: 1, Title: "Cherry pick", Base: "release-1.18", Author: "alice", URL: "https://github.com/org/a/pull/1"},
+							{Number: 3, Title: "Cherry pick", Base: "release-1.17", Author: "alice", URL: "https://github.com/org/a/pull/1"},
+						},
+					},
+					{
+						Repo: "org/b",
+						Branches: []*branchStatus{
+							{Branch: "release-1.18", Window: windowClosed},
+						},
+						PullRequests: []*pullRequest{},
+					},
+				},
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the refs",
+			dest:          []string{"org/a"},
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Dest:         tt.dest,
+				MinVersion:   tt.minVersion,
+				PrefixBranch: pkg.PrefixBranch,
+				Title:        "Releases",
+			}
+
+			// Create fake client and setup endpoint handlers.
+			releasesB := []*github.RepositoryRelease{}
+			prsB := []*github.PullRequest{}
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/git/refs",
+				pkg.NewReferenceHandler(&refsA, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/b/git/refs",
+				pkg.NewReferenceHandler(&refsB, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/releases",
+				pkg.NewReleaseHandler(&releasesA, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/b/releases",
+				pkg.NewReleaseHandler(&releasesB, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/a/pulls",
+				pkg.NewPullRequestHandler(&prsA, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/b/pulls",
+				pkg.NewPullRequestHandler(&prsB, map[string]bool{}))
+
+			db, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(db, tt.expectedDashboard) {
+				t.Errorf("expected dashboard:\n%+v\ngot:\n%+v", tt.expectedDashboard, db)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The release tag is not validated as
+// SemVer, since this is one of the checks of the report.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Dest             []string `flag:"dest" usage:"The org/repo of the release to verify" validate:"required,repo"`
+	ReleaseTag       string   `flag:"release-tag" usage:"A SemVer tag from which to create a release" validate:"required"`
+	AssetManifest    string   `flag:"asset-manifest" usage:"Path or URL to a file with the expected release assets. Every line is an asset name, optionally preceded by its SHA256 checksum as written by 'sha256sum'"`
+	SignatureCommand string   `flag:"signature-command" usage:"A command to verify the signature of a release asset. It is called with the paths of the asset and of the signature for every asset that has a matching '<asset>.sig' asset"`
+	Output           string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	return pkg.ValidateOptions(d, &options{})
+}
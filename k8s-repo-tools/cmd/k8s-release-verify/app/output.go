@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"encoding/json"
@@ -26,9 +26,8 @@ import (
 
 // output is the output structure.
 type output struct {
-	OutputError *string                  `json:"outputError"`
-	Reference   *github.Reference        `json:"reference"`
-	Commit      *github.RepositoryCommit `json:"commit"`
+	OutputError *string `json:"outputError"`
+	*report
 }
 
 // formatOutput marshals the output to JSON.
@@ -47,15 +46,14 @@ func formatOutput(out *output, indent bool) ([]byte, error) {
 }
 
 // writeOutputToFile writes the output to the given filePath.
-func writeOutputToFile(filePath string, ref *github.Reference, commit *github.RepositoryCommit, outputError error) error {
+func writeOutputToFile(filePath string, r *report, outputError error) error {
 	var errorStr *string
 	if outputError != nil {
 		errorStr = github.String(outputError.Error())
 	}
 	out := &output{
 		OutputError: errorStr,
-		Reference:   ref,
-		Commit:      commit,
+		report:      r,
 	}
 	buf, err := formatOutput(out, true)
 	if err != nil {
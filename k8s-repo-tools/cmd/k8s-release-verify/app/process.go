@@ -0,0 +1,265 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	checkTagSemVer     = "tagSemVer"
+	checkTagExists     = "tagExists"
+	checkReleaseExists = "releaseExists"
+	checkReleaseBody   = "releaseBody"
+	checkReleaseFlags  = "releaseFlags"
+	checkAssets        = "assets"
+	checkChecksums     = "checksums"
+	checkSignatures    = "signatures"
+)
+
+// check is the result of a single verification.
+type check struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// report holds the results of all verifications of a release.
+// The release passes if all checks passed.
+type report struct {
+	Repo   string   `json:"repo"`
+	Tag    string   `json:"tag"`
+	Passed bool     `json:"passed"`
+	Checks []*check `json:"checks"`
+}
+
+// add adds the result of a check to the report.
+func (r *report) add(name string, passed bool, format string, a ...interface{}) {
+	r.Checks = append(r.Checks, &check{Name: name, Passed: passed, Message: fmt.Sprintf(format, a...)})
+	r.Passed = r.Passed && passed
+}
+
+// process is responsible for all operations that the application performs.
+// It returns a report with the results of the checks. An error is only returned
+// if the checks could not be performed.
+func process(d *pkg.Data) (*report, error) {
+	r := &report{Repo: d.DestRepo(), Tag: d.ReleaseTag, Passed: true, Checks: []*check{}}
+	tagRef := "refs/tags/" + d.ReleaseTag
+
+	// The tag must be SemVer.
+	v, err := version.ParseSemantic(d.ReleaseTag)
+	if err != nil {
+		r.add(checkTagSemVer, false, "the tag is not SemVer: %v", err)
+	} else {
+		r.add(checkTagSemVer, true, "the tag is SemVer")
+	}
+
+	// The tag must exist.
+	tags, err := pkg.GitHubGetTags(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	tagFound := false
+	for _, ref := range tags {
+		if ref.GetRef() == tagRef {
+			tagFound = true
+			break
+		}
+	}
+	if tagFound {
+		r.add(checkTagExists, true, "the tag exists")
+	} else {
+		r.add(checkTagExists, false, "the tag %q does not exist", tagRef)
+	}
+
+	// The release must exist. The remaining checks require a release.
+	release, err := pkg.GitHubGetRelease(d, d.DestRepo(), d.ReleaseTag)
+	if pkg.ErrorKindOf(err) == pkg.ErrorKindNotFound {
+		r.add(checkReleaseExists, false, "the release does not exist")
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.add(checkReleaseExists, true, "the release exists")
+
+	// The release must have release notes.
+	if len(strings.TrimSpace(release.GetBody())) == 0 {
+		r.add(checkReleaseBody, false, "the release body is empty")
+	} else {
+		r.add(checkReleaseBody, true, "the release body is not empty")
+	}
+
+	// The release must be published and only pre-release tags can be pre-releases.
+	switch {
+	case release.GetDraft():
+		r.add(checkReleaseFlags, false, "the release is a draft")
+	case v != nil && len(v.PreRelease()) != 0 && !release.GetPrerelease():
+		r.add(checkReleaseFlags, false, "the tag is a pre-release, but the release is not marked as a pre-release")
+	case v != nil && len(v.PreRelease()) == 0 && release.GetPrerelease():
+		r.add(checkReleaseFlags, false, "the tag is not a pre-release, but the release is marked as a pre-release")
+	default:
+		r.add(checkReleaseFlags, true, "the release flags match the tag")
+	}
+
+	assets := map[string]*github.ReleaseAsset{}
+	for i := range release.Assets {
+		assets[release.Assets[i].GetName()] = &release.Assets[i]
+	}
+
+	// Compare the assets with the manifest.
+	if len(d.AssetManifest) != 0 {
+		pkg.Logf("reading the asset manifest from %q", d.AssetManifest)
+		data, err := pkg.ReadFromFileOrURL(d.AssetManifest, d.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := pkg.ParseChecksumManifest(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyAssets(d, r, manifest, assets); err != nil {
+			return r, err
+		}
+	}
+
+	// Verify the signatures.
+	if len(d.SignatureCommand) != 0 {
+		if err := verifySignatures(d, r, assets); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// verifyAssets checks that the release has exactly the assets in the manifest and that
+// the assets with a checksum in the manifest match it.
+func verifyAssets(d *pkg.Data, r *report, manifest *pkg.ChecksumManifest, assets map[string]*github.ReleaseAsset) error {
+	names := make([]string, 0, len(assets))
+	for name := range assets {
+		names = append(names, name)
+	}
+	missing, unexpected := manifest.Compare(names)
+	if len(missing) == 0 && len(unexpected) == 0 {
+		r.add(checkAssets, true, "the assets match the manifest")
+	} else {
+		r.add(checkAssets, false, "missing assets: %v, unexpected assets: %v", missing, unexpected)
+	}
+
+	mismatched := []string{}
+	var verified int
+	for _, entry := range manifest.Assets {
+		asset, ok := assets[entry.Name]
+		if len(entry.SHA256) == 0 || !ok {
+			continue
+		}
+		if d.Interrupted() {
+			return pkg.ErrInterrupted
+		}
+		data, err := pkg.GitHubDownloadReleaseAsset(d, d.DestRepo(), asset)
+		if err != nil {
+			return err
+		}
+		if err := manifest.Verify(entry.Name, data); err != nil {
+			pkg.Warningf("%v", err)
+			mismatched = append(mismatched, entry.Name)
+		}
+		verified++
+	}
+	switch {
+	case len(mismatched) != 0:
+		r.add(checkChecksums, false, "assets with a mismatched size or SHA256 checksum: %v", mismatched)
+	case verified != 0:
+		r.add(checkChecksums, true, "the SHA256 checksums of %d asset(s) match the manifest", verified)
+	}
+	return nil
+}
+
+// verifySignatures calls d.SignatureCommand for every asset that has a matching
+// '<asset>.sig' asset. The check fails if no signatures are found.
+func verifySignatures(d *pkg.Data, r *report, assets map[string]*github.ReleaseAsset) error {
+	names := []string{}
+	for name := range assets {
+		if _, ok := assets[name+".sig"]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		r.add(checkSignatures, false, "no '<asset>.sig' signatures found")
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "k8s-release-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	invalid := []string{}
+	for _, name := range names {
+		if d.Interrupted() {
+			return pkg.ErrInterrupted
+		}
+		paths := []string{}
+		for _, asset := range []*github.ReleaseAsset{assets[name], assets[name+".sig"]} {
+			data, err := pkg.GitHubDownloadReleaseAsset(d, d.DestRepo(), asset)
+			if err != nil {
+				return err
+			}
+			// Asset names cannot contain path separators, but don't rely on that.
+			path := filepath.Join(dir, filepath.Base(asset.GetName()))
+			if err := ioutil.WriteFile(path, data, 0600); err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+		if err := pkg.RunCommand(d.SignatureCommand, nil, false, paths...); err != nil {
+			pkg.Warningf("could not verify the signature of asset %q: %v", name, err)
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) != 0 {
+		r.add(checkSignatures, false, "assets with an invalid signature: %v", invalid)
+	} else {
+		r.add(checkSignatures, true, "the signatures of %d asset(s) are valid", len(names))
+	}
+	return nil
+}
+
+// printReport prints the results of the checks.
+func printReport(r *report) {
+	pkg.PrintSeparator()
+	pkg.Logf("verification report for the release of tag %q in repository %q:", r.Tag, r.Repo)
+	for _, c := range r.Checks {
+		if c.Passed {
+			pkg.Logf("PASS %s: %s", c.Name, c.Message)
+		} else {
+			pkg.Warningf("FAIL %s: %s", c.Name, c.Message)
+		}
+	}
+	pkg.PrintSeparator()
+}
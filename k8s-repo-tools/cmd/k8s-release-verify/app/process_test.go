@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-release-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The SHA256 checksums of "foo" and "bar".
+	const (
+		sumFoo = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+		sumBar = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+	)
+	writeManifest := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	manifestValid := writeManifest("valid", sumFoo+"  kubeadm\n"+sumBar+" *kubectl\nkubeadm.sig\n")
+	manifestMismatch := writeManifest("mismatch", sumBar+"  kubeadm\nkubectl\nkubelet\n")
+
+	refs := []*github.Reference{
+		{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("sha")}},
+		{Ref: github.String("refs/tags/v1.18.0-rc.1"), Object: &github.GitObject{SHA: github.String("sha")}},
+		{Ref: github.String("refs/tags/foo"), Object: &github.GitObject{SHA: github.String("sha")}},
+	}
+	newAsset := func(id int64, name string) github.ReleaseAsset {
+		return github.ReleaseAsset{ID: github.Int64(id), Name: github.String(name)}
+	}
+	contents := map[int64][]byte{1: []byte("foo"), 2: []byte("bar"), 3: []byte("sig")}
+	releases := []*github.RepositoryRelease{
+		{
+			TagName:    github.String("v1.17.0"),
+			Body:       github.String("release notes"),
+			Draft:      github.Bool(false),
+			Prerelease: github.Bool(false),
+			Assets:     []github.ReleaseAsset{newAsset(1, "kubeadm"), newAsset(2, "kubectl"), newAsset(3, "kubeadm.sig")},
+		},
+		{
+			TagName:    github.String("v1.18.0-rc.1"),
+			Body:       github.String(""),
+			Draft:      github.Bool(true),
+			Prerelease: github.Bool(false),
+		},
+		{
+			TagName:    github.String("foo"),
+			Body:       github.String("release notes"),
+			Prerelease: github.Bool(true),
+		},
+	}
+
+	tests := []struct {
+		name             string
+		releaseTag       string
+		assetManifest    string
+		signatureCommand string
+		methodErrors     map[string]bool
+		expectedChecks   map[string]bool
+		expectedPassed   bool
+		expectedError    bool
+	}{
+		{
+			name:             "valid: all checks pass",
+			releaseTag:       "v1.17.0",
+			assetManifest:    manifestValid,
+			signatureCommand: "true",
+			expectedChecks: map[string]bool{
+				checkTagSemVer: true, checkTagExists: true, checkReleaseExists: true, checkReleaseBody: true,
+				checkReleaseFlags: true, checkAssets: true, checkChecksums: true, checkSignatures: true,
+			},
+			expectedPassed: true,
+		},
+		{
+			name:             "valid: mismatched assets, checksums and signatures",
+			releaseTag:       "v1.17.0",
+			assetManifest:    manifestMismatch,
+			signatureCommand: "false",
+			expectedChecks: map[string]bool{
+				checkTagSemVer: true, checkTagExists: true, checkReleaseExists: true, checkReleaseBody: true,
+				checkReleaseFlags: true, checkAssets: false, checkChecksums: false, checkSignatures: false,
+			},
+		},
+		{
+			name:       "valid: empty body and draft release",
+			releaseTag: "v1.18.0-rc.1",
+			expectedChecks: map[string]bool{
+				checkTagSemVer: true, checkTagExists: true, checkReleaseExists: true, checkReleaseBody: false,
+				checkReleaseFlags: false,
+			},
+		},
+		{
+			name:             "valid: non-SemVer tag without signatures",
+			releaseTag:       "foo",
+			signatureCommand: "true",
+			expectedChecks: map[string]bool{
+				checkTagSemVer: false, checkTagExists: true, checkReleaseExists: true, checkReleaseBody: true,
+				checkReleaseFlags: true, checkSignatures: false,
+			},
+		},
+		{
+			name:       "valid: missing tag and release",
+			releaseTag: "v1.19.0",
+			expectedChecks: map[string]bool{
+				checkTagSemVer: true, checkTagExists: false, checkReleaseExists: false,
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the tags",
+			releaseTag:    "v1.17.0",
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{
+				Dest:             []string{"org/dest"},
+				ReleaseTag:       tt.releaseTag,
+				AssetManifest:    tt.assetManifest,
+				SignatureCommand: tt.signatureCommand,
+			}
+
+			// Create fake client and setup endpoint handlers.
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs",
+				pkg.NewReferenceHandler(&refs, tt.methodErrors))
+			data.Transport.SetHandler("https://api.github.com/repos/org/dest/releases/tags",
+				pkg.NewReleaseHandler(&releases, map[string]bool{}))
+			data.Transport.SetHandler("https://api.github.com/repos/org/dest/releases/assets",
+				pkg.NewReleaseAssetDownloadHandler(contents, map[string]bool{}))
+
+			r, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			checks := map[string]bool{}
+			for _, c := range r.Checks {
+				checks[c.Name] = c.Passed
+			}
+			if !reflect.DeepEqual(checks, tt.expectedChecks) {
+				t.Errorf("expected checks:\n%v\ngot:\n%v", tt.expectedChecks, checks)
+			}
+			if r.Passed != tt.expectedPassed {
+				t.Errorf("expected passed %v, got %v", tt.expectedPassed, r.Passed)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	Dest         []string `flag:"dest" usage:"Comma separated list of org/repo to audit" validate:"required,repos"`
+	AuditPolicy  string   `flag:"audit-policy" usage:"Path or URL to a YAML file with the expected repository settings" validate:"required"`
+	OutputFormat string   `flag:"output-format" default:"text" usage:"Format of the output. One of \"text\" or \"json\"" validate:"oneof=text|json"`
+	Parallelism  int      `flag:"parallelism" default:"1" usage:"Number of repositories to process in parallel. With a value greater than 1 a failing repository does not stop the others" validate:"nonnegative"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	return pkg.ValidateOptions(d, &options{})
+}
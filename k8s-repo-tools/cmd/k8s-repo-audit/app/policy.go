@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	visibilityPublic  = "public"
+	visibilityPrivate = "private"
+)
+
+// auditPolicy is the structure of the YAML file passed with the
+// --audit-policy flag. Settings that are not set are not audited.
+type auditPolicy struct {
+	DefaultBranch     string         `json:"defaultBranch,omitempty"`
+	Visibility        string         `json:"visibility,omitempty"`
+	AllowMergeCommit  *bool          `json:"allowMergeCommit,omitempty"`
+	AllowSquashMerge  *bool          `json:"allowSquashMerge,omitempty"`
+	AllowRebaseMerge  *bool          `json:"allowRebaseMerge,omitempty"`
+	ProtectedBranches []branchPolicy `json:"protectedBranches,omitempty"`
+	Webhooks          *webhookPolicy `json:"webhooks,omitempty"`
+}
+
+// branchPolicy is the expected protection of a branch.
+type branchPolicy struct {
+	Name                    string   `json:"name"`
+	RequiredReviews         int      `json:"requiredReviews,omitempty"`
+	RequireCodeOwnerReviews bool     `json:"requireCodeOwnerReviews,omitempty"`
+	EnforceAdmins           bool     `json:"enforceAdmins,omitempty"`
+	RequiredStatusChecks    []string `json:"requiredStatusChecks,omitempty"`
+}
+
+// webhookPolicy is the expected list of webhooks. Webhooks are matched by
+// the prefix of their URL. If Allowed is not empty, webhooks that match
+// neither Required nor Allowed are violations.
+type webhookPolicy struct {
+	Required []string `json:"required,omitempty"`
+	Allowed  []string `json:"allowed,omitempty"`
+}
+
+// parseAuditPolicy parses and validates the contents of an audit policy.
+func parseAuditPolicy(data []byte) (*auditPolicy, error) {
+	p := &auditPolicy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, errors.Wrap(err, "cannot parse audit policy")
+	}
+	switch p.Visibility {
+	case "", visibilityPublic, visibilityPrivate:
+	default:
+		return nil, errors.Errorf("the 'visibility' in the audit policy must be one of %q or %q", visibilityPublic, visibilityPrivate)
+	}
+	seen := map[string]bool{}
+	for i, b := range p.ProtectedBranches {
+		if len(b.Name) == 0 {
+			return nil, errors.Errorf("protected branch %d in the audit policy must have a 'name'", i)
+		}
+		if seen[b.Name] {
+			return nil, errors.Errorf("duplicate protected branch %q in the audit policy", b.Name)
+		}
+		seen[b.Name] = true
+		if b.RequiredReviews < 0 {
+			return nil, errors.Errorf("protected branch %q in the audit policy cannot have negative 'requiredReviews'", b.Name)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestParseAuditPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		expectedPolicy *auditPolicy
+		expectedError  bool
+	}{
+		{
+			name: "valid: all settings",
+			policy: `
+defaultBranch: master
+visibility: private
+allowSquashMerge: false
+protectedBranches:
+- name: master
+  requiredReviews: 2
+  enforceAdmins: true
+webhooks:
+  required:
+  - https://prow.k8s.io/hook
+`,
+			expectedPolicy: &auditPolicy{
+				DefaultBranch:     "master",
+				Visibility:        visibilityPrivate,
+				AllowSquashMerge:  github.Bool(false),
+				ProtectedBranches: []branchPolicy{{Name: "master", RequiredReviews: 2, EnforceAdmins: true}},
+				Webhooks:          &webhookPolicy{Required: []string{"https://prow.k8s.io/hook"}},
+			},
+		},
+		{
+			name:           "valid: empty policy",
+			policy:         "",
+			expectedPolicy: &auditPolicy{},
+		},
+		{
+			name:          "invalid: unknown visibility",
+			policy:        "visibility: internal",
+			expectedError: true,
+		},
+		{
+			name: "invalid: protected branch without a name",
+			policy: `
+protectedBranches:
+- requiredReviews: 1
+`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown field",
+			policy:        "defaultBranches: master",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseAuditPolicy([]byte(tt.policy))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(p, tt.expectedPolicy) {
+				t.Errorf("expected policy:\n%+v\ngot:\n%+v", tt.expectedPolicy, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// report is the result of an audit.
+type report struct {
+	Repos      []string     `json:"repos"`
+	Violations []*violation `json:"violations"`
+}
+
+// violation is a repository setting that does not match the policy.
+type violation struct {
+	Repo     string `json:"repo"`
+	Setting  string `json:"setting"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*report, error) {
+	pkg.Logf("reading the audit policy from %q", d.AuditPolicy)
+	data, err := pkg.ReadFromFileOrURL(d.AuditPolicy, d.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := parseAuditPolicy(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &report{Repos: []string{}, Violations: []*violation{}}
+	repos := d.Dest
+	violations := make([][]*violation, len(repos))
+	audited := make([]bool, len(repos))
+	err = pkg.ForEachRepo(d, repos, func(i int, repo string, _ *pkg.Logger) error {
+		var err error
+		violations[i], err = audit(d, repo, policy)
+		audited[i] = err == nil
+		return err
+	})
+	for i, repo := range repos {
+		if audited[i] {
+			r.Repos = append(r.Repos, repo)
+			r.Violations = append(r.Violations, violations[i]...)
+		}
+	}
+	return r, err
+}
+
+// audit compares the settings of a repository with the policy.
+func audit(d *pkg.Data, repo string, policy *auditPolicy) ([]*violation, error) {
+	result := []*violation{}
+	add := func(setting, expected, actual string) {
+		result = append(result, &violation{Repo: repo, Setting: setting, Expected: expected, Actual: actual})
+	}
+
+	r, err := pkg.GitHubGetRepository(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(policy.DefaultBranch) != 0 && r.GetDefaultBranch() != policy.DefaultBranch {
+		add("defaultBranch", policy.DefaultBranch, r.GetDefaultBranch())
+	}
+	if len(policy.Visibility) != 0 {
+		visibility := visibilityPublic
+		if r.GetPrivate() {
+			visibility = visibilityPrivate
+		}
+		if visibility != policy.Visibility {
+			add("visibility", policy.Visibility, visibility)
+		}
+	}
+	for _, v := range []struct {
+		setting  string
+		expected *bool
+		actual   bool
+	}{
+		{"allowMergeCommit", policy.AllowMergeCommit, r.GetAllowMergeCommit()},
+		{"allowSquashMerge", policy.AllowSquashMerge, r.GetAllowSquashMerge()},
+		{"allowRebaseMerge", policy.AllowRebaseMerge, r.GetAllowRebaseMerge()},
+	} {
+		if v.expected != nil && *v.expected != v.actual {
+			add(v.setting, strconv.FormatBool(*v.expected), strconv.FormatBool(v.actual))
+		}
+	}
+
+	for _, b := range policy.ProtectedBranches {
+		if d.Interrupted() {
+			return nil, pkg.ErrInterrupted
+		}
+		prefix := fmt.Sprintf("protectedBranches/%s/", b.Name)
+		protection, err := pkg.GitHubGetBranchProtection(d, repo, b.Name)
+		if pkg.ErrorKindOf(err) == pkg.ErrorKindNotFound {
+			add(prefix+"protection", "protected", "not protected")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reviews := protection.RequiredPullRequestReviews
+		if reviews == nil {
+			reviews = &github.PullRequestReviewsEnforcement{}
+		}
+		if reviews.RequiredApprovingReviewCount < b.RequiredReviews {
+			add(prefix+"requiredReviews", fmt.Sprintf(">= %d", b.RequiredReviews), strconv.Itoa(reviews.RequiredApprovingReviewCount))
+		}
+		if b.RequireCodeOwnerReviews && !reviews.RequireCodeOwnerReviews {
+			add(prefix+"requireCodeOwnerReviews", "true", "false")
+		}
+		if b.EnforceAdmins && (protection.EnforceAdmins == nil || !protection.EnforceAdmins.Enabled) {
+			add(prefix+"enforceAdmins", "true", "false")
+		}
+		contexts := map[string]bool{}
+		if protection.RequiredStatusChecks != nil {
+			for _, c := range protection.RequiredStatusChecks.Contexts {
+				contexts[c] = true
+			}
+		}
+		for _, c := range b.RequiredStatusChecks {
+			if !contexts[c] {
+				add(prefix+"requiredStatusChecks", c, "missing")
+			}
+		}
+	}
+
+	if policy.Webhooks != nil {
+		hooks, err := pkg.GitHubGetHooks(d, repo)
+		if err != nil {
+			return nil, err
+		}
+		urls := make([]string, 0, len(hooks))
+		for _, h := range hooks {
+			url, _ := h.Config["url"].(string)
+			urls = append(urls, url)
+		}
+		for _, prefix := range policy.Webhooks.Required {
+			if !matchesAny([]string{prefix}, urls) {
+				add("webhooks", prefix, "missing")
+			}
+		}
+		if len(policy.Webhooks.Allowed) != 0 {
+			allowed := append(append([]string{}, policy.Webhooks.Required...), policy.Webhooks.Allowed...)
+			for _, url := range urls {
+				if !matchesAny(allowed, []string{url}) {
+					add("webhooks", "no other webhooks", url)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// matchesAny returns true if any of the URLs starts with any of the prefixes.
+func matchesAny(prefixes, urls []string) bool {
+	for _, p := range prefixes {
+		for _, u := range urls {
+			if strings.HasPrefix(u, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
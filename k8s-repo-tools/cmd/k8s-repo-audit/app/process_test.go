@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-repo-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const policy = `
+defaultBranch: master
+visibility: public
+allowMergeCommit: true
+allowRebaseMerge: false
+protectedBranches:
+- name: master
+  requiredReviews: 1
+  requireCodeOwnerReviews: true
+  requiredStatusChecks:
+  - tide
+- name: release-1.17
+webhooks:
+  required:
+  - https://prow.k8s.io/hook
+  allowed:
+  - https://example.com/
+`
+	policyPath := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(policyPath, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	compliantRepo := &github.Repository{
+		DefaultBranch:    github.String("master"),
+		Private:          github.Bool(false),
+		AllowMergeCommit: github.Bool(true),
+		AllowRebaseMerge: github.Bool(false),
+	}
+	compliantProtections := map[string]*github.Protection{
+		"master": {
+			RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+				RequiredApprovingReviewCount: 1,
+				RequireCodeOwnerReviews:      true,
+			},
+			RequiredStatusChecks: &github.RequiredStatusChecks{Contexts: []string{"tide", "other"}},
+		},
+		"release-1.17": {},
+	}
+	hook := func(url string) *github.Hook {
+		return &github.Hook{Config: map[string]interface{}{"url": url}}
+	}
+	compliantHooks := []*github.Hook{hook("https://prow.k8s.io/hook"), hook("https://example.com/foo")}
+
+	tests := []struct {
+		name               string
+		repo               *github.Repository
+		protections        map[string]*github.Protection
+		hooks              []*github.Hook
+		methodErrors       map[string]bool
+		expectedViolations []*violation
+		expectedError      bool
+	}{
+		{
+			name:               "valid: compliant repository",
+			repo:               compliantRepo,
+			protections:        compliantProtections,
+			hooks:              compliantHooks,
+			expectedViolations: []*violation{},
+		},
+		{
+			name: "valid: repository settings do not match",
+			repo: &github.Repository{
+				DefaultBranch:    github.String("main"),
+				Private:          github.Bool(true),
+				AllowMergeCommit: github.Bool(false),
+				AllowRebaseMerge: github.Bool(false),
+			},
+			protections: compliantProtections,
+			hooks:       compliantHooks,
+			expectedViolations: []*violation{
+				{Repo: "org/a", Setting: "defaultBranch", Expected: "master", Actual: "main"},
+				{Repo: "org/a", Setting: "visibility", Expected: "public", Actual: "private"},
+				{Repo: "org/a", Setting: "allowMergeCommit", Expected: "true", Actual: "false"},
+			},
+		},
+		{
+			name: "valid: branch protection does not match",
+			repo: compliantRepo,
+			protections: map[string]*github.Protection{
+				"master": {},
+			},
+			hooks: compliantHooks,
+			expectedViolations: []*violation{
+				{Repo: "org/a", Setting: "protectedBranches/master/requiredReviews", Expected: ">= 1", Actual: "0"},
+				{Repo: "org/a", Setting: "protectedBranches/master/requireCodeOwnerReviews", Expected: "true", Actual: "false"},
+				{Repo: "org/a", Setting: "protectedBranches/master/requiredStatusChecks", Expected: "tide", Actual: "missing"},
+				{Repo: "org/a", Setting: "protectedBranches/release-1.17/protection", Expected: "protected", Actual: "not protected"},
+			},
+		},
+		{
+			name:        "valid: webhooks do not match",
+			repo:        compliantRepo,
+			protections: compliantProtections,
+			hooks:       []*github.Hook{hook("https://unknown.com/hook")},
+			expectedViolations: []*violation{
+				{Repo: "org/a", Setting: "webhooks", Expected: "https://prow.k8s.io/hook", Actual: "missing"},
+				{Repo: "org/a", Setting: "webhooks", Expected: "no other webhooks", Actual: "https://unknown.com/hook"},
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the repository",
+			repo:          compliantRepo,
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &pkg.Data{Dest: []string{"org/a"}, AuditPolicy: policyPath}
+
+			// Create fake client and setup endpoint handlers.
+			api := "https://api.github.com/repos/org/a"
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler(api, pkg.NewRepositoryHandler(tt.repo, tt.methodErrors))
+			data.Transport.SetHandler(api+"/branches", pkg.NewProtectionHandler(tt.protections, map[string]bool{}))
+			data.Transport.SetHandler(api+"/hooks", pkg.NewHookHandler(tt.hooks, map[string]bool{}))
+
+			r, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(r.Violations, tt.expectedViolations) {
+				t.Errorf("expected violations:\n%s\ngot:\n%s", formatViolations(tt.expectedViolations), formatViolations(r.Violations))
+			}
+		})
+	}
+}
+
+func formatViolations(violations []*violation) string {
+	var result string
+	for _, v := range violations {
+		result += fmt.Sprintf("%+v\n", *v)
+	}
+	return result
+}
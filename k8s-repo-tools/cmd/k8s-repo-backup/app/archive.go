@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	refsFile       = "refs.json"
+	releasesFile   = "releases.json"
+	milestonesFile = "milestones.json"
+	assetsDir      = "assets"
+)
+
+// archive is the metadata of a repository in a backup archive. The layout of the
+// archive directory is:
+// - refs.json: the tags and branches.
+// - releases.json: the releases, including the metadata of the assets.
+// - milestones.json: the milestones.
+// - assets/<tag>/<name>: the contents of the release assets.
+type archive struct {
+	Refs       []*github.Reference
+	Releases   []*github.RepositoryRelease
+	Milestones []*github.Milestone
+}
+
+// files returns the file names of the archive with the objects stored in them.
+func (a *archive) files() map[string]interface{} {
+	return map[string]interface{}{
+		refsFile:       &a.Refs,
+		releasesFile:   &a.Releases,
+		milestonesFile: &a.Milestones,
+	}
+}
+
+// assetPath returns the path of a release asset in the archive directory.
+func assetPath(dir, tag, name string) string {
+	// Tags can contain '/' and asset names cannot, but don't rely on that.
+	return filepath.Join(dir, assetsDir, filepath.FromSlash(tag), filepath.Base(name))
+}
+
+// writeArchive writes the metadata of the archive to a directory.
+func writeArchive(dir string, a *archive) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "could not create the backup directory %q", dir)
+	}
+	for name, obj := range a.files() {
+		buf, err := json.MarshalIndent(obj, "", "\t")
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, buf, 0600); err != nil {
+			return errors.Wrapf(err, "could not write %q", path)
+		}
+	}
+	return nil
+}
+
+// readArchive reads the metadata of an archive from a directory.
+func readArchive(dir string) (*archive, error) {
+	a := &archive{}
+	for name, obj := range a.files() {
+		path := filepath.Join(dir, name)
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %q", path)
+		}
+		if err := json.Unmarshal(buf, obj); err != nil {
+			return nil, errors.Wrapf(err, "could not parse %q", path)
+		}
+	}
+	return a, nil
+}
@@ -14,41 +14,32 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest      []string `flag:"dest" usage:"The org/repo to back up or restore" validate:"required,repo"`
+	BackupDir string   `flag:"backup-dir" usage:"Path to the directory of the backup archive" validate:"required"`
+	Restore   bool     `flag:"restore" usage:"Restore the refs and releases from \"backup-dir\" instead of writing a backup to it"`
+	Output    string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
 // validateData validates the user input.
 func validateData(d *pkg.Data) error {
 	pkg.Logf("validating user input...")
 
-	// Validate empty options.
-	for k, v := range map[string]*string{
-		pkg.FlagDest:       &d.Dest,
-		pkg.FlagToken:      &d.Token,
-		pkg.FlagReleaseTag: &d.ReleaseTag,
-	} {
-		if err := pkg.ValidateEmptyOption(k, *v); err != nil {
-			return err
-		}
-	}
-
-	// Validate org/repo.
-	if err := pkg.ValidateRepo(pkg.FlagDest, d.Dest); err != nil {
-		return err
-	}
-
-	// Validate token.
-	if err := pkg.ValidateToken(pkg.FlagToken, d.Token); err != nil {
-		return err
-	}
-
-	// Validate release tag.
-	if err := pkg.ValidateReleaseTag(pkg.FlagReleaseTag, d.ReleaseTag); err != nil {
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
 		return err
 	}
 
-	return nil
+	return pkg.ValidateOptions(d, &options{})
 }
@@ -0,0 +1,272 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// result holds the objects that were backed up or restored.
+type result struct {
+	Refs       []string `json:"refs"`
+	Releases   []string `json:"releases"`
+	Assets     []string `json:"assets"`
+	Milestones []string `json:"milestones"`
+}
+
+func newResult() *result {
+	return &result{Refs: []string{}, Releases: []string{}, Assets: []string{}, Milestones: []string{}}
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*result, error) {
+	if d.Restore {
+		return processRestore(d)
+	}
+	return processBackup(d)
+}
+
+// processBackup writes the refs, releases and milestones of d.Dest to d.BackupDir.
+// Assets that already exist in the archive with the same size are not downloaded again.
+func processBackup(d *pkg.Data) (*result, error) {
+	res := newResult()
+	a := &archive{}
+
+	tags, err := pkg.GitHubGetTags(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	branches, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	a.Refs = append(tags, branches...)
+	if a.Releases, err = pkg.GitHubGetReleases(d, d.DestRepo()); err != nil {
+		return nil, err
+	}
+	if a.Milestones, err = pkg.GitHubGetMilestones(d, d.DestRepo()); err != nil {
+		return nil, err
+	}
+
+	pkg.Logf("writing %d ref(s), %d release(s) and %d milestone(s) to %q",
+		len(a.Refs), len(a.Releases), len(a.Milestones), d.BackupDir)
+	if err := writeArchive(d.BackupDir, a); err != nil {
+		return nil, err
+	}
+	for _, ref := range a.Refs {
+		res.Refs = append(res.Refs, ref.GetRef())
+	}
+	for _, release := range a.Releases {
+		res.Releases = append(res.Releases, release.GetTagName())
+	}
+	for _, m := range a.Milestones {
+		res.Milestones = append(res.Milestones, m.GetTitle())
+	}
+
+	// Download the assets.
+	for _, release := range a.Releases {
+		for i := range release.Assets {
+			if d.Interrupted() {
+				return res, pkg.ErrInterrupted
+			}
+			asset := &release.Assets[i]
+			path := assetPath(d.BackupDir, release.GetTagName(), asset.GetName())
+			if info, err := os.Stat(path); err == nil && info.Size() == int64(asset.GetSize()) {
+				pkg.V(1).Logf("skipping existing asset %q", path)
+				res.Assets = append(res.Assets, release.GetTagName()+"/"+asset.GetName())
+				continue
+			}
+			pkg.Logf("downloading asset %q of release %q", asset.GetName(), release.GetTagName())
+			data, err := pkg.GitHubDownloadReleaseAsset(d, d.DestRepo(), asset)
+			if err != nil {
+				return res, err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return res, errors.Wrapf(err, "could not create the directory for %q", path)
+			}
+			if err := ioutil.WriteFile(path, data, 0600); err != nil {
+				return res, errors.Wrapf(err, "could not write %q", path)
+			}
+			res.Assets = append(res.Assets, release.GetTagName()+"/"+asset.GetName())
+		}
+	}
+	return res, nil
+}
+
+// restoreRelease is a release from the archive that is restored with its assets.
+type restoreRelease struct {
+	archived *github.RepositoryRelease
+	existing *github.RepositoryRelease
+	assets   map[string]string
+}
+
+// processRestore creates the refs and releases from d.BackupDir that are missing
+// in d.Dest and uploads the release assets that are missing. Existing refs and
+// releases are never modified.
+func processRestore(d *pkg.Data) (*result, error) {
+	res := newResult()
+	a, err := readArchive(d.BackupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the missing refs.
+	tags, err := pkg.GitHubGetTags(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	branches, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, err
+	}
+	existingRefs := map[string]string{}
+	for _, ref := range append(tags, branches...) {
+		existingRefs[ref.GetRef()] = ref.GetObject().GetSHA()
+	}
+	missingRefs := []*github.Reference{}
+	for _, ref := range a.Refs {
+		sha, ok := existingRefs[ref.GetRef()]
+		if !ok {
+			missingRefs = append(missingRefs, ref)
+			continue
+		}
+		if sha != ref.GetObject().GetSHA() {
+			pkg.Warningf("ref %q points to %q in repository %q, but to %q in the backup; skipping",
+				ref.GetRef(), sha, d.DestRepo(), ref.GetObject().GetSHA())
+		}
+	}
+
+	// Find the missing releases and assets.
+	releases := []*restoreRelease{}
+	var missingReleases, missingAssets int
+	for _, archived := range a.Releases {
+		tag := archived.GetTagName()
+		r := &restoreRelease{archived: archived, assets: map[string]string{}}
+		existing, err := pkg.GitHubGetRelease(d, d.DestRepo(), tag)
+		if err != nil && pkg.ErrorKindOf(err) != pkg.ErrorKindNotFound {
+			return nil, err
+		}
+		if err == nil {
+			r.existing = existing
+		} else {
+			missingReleases++
+		}
+		for _, asset := range archived.Assets {
+			if r.existing != nil && hasAsset(r.existing, asset.GetName()) {
+				continue
+			}
+			path := assetPath(d.BackupDir, tag, asset.GetName())
+			if _, err := os.Stat(path); err != nil {
+				pkg.Warningf("asset %q of release %q is missing in the backup; skipping", asset.GetName(), tag)
+				continue
+			}
+			r.assets[asset.GetName()] = path
+			missingAssets++
+		}
+		if r.existing == nil || len(r.assets) != 0 {
+			releases = append(releases, r)
+		}
+	}
+
+	if len(missingRefs) == 0 && len(releases) == 0 {
+		pkg.Logf("nothing to restore in repository %q", d.DestRepo())
+		return res, nil
+	}
+
+	// List what will be restored.
+	pkg.PrintSeparator()
+	pkg.Logf("found %d ref(s), %d release(s) and %d asset(s) to restore in repository %q:",
+		len(missingRefs), missingReleases, missingAssets, d.DestRepo())
+	for _, ref := range missingRefs {
+		pkg.Logf("- ref %s", ref.GetRef())
+	}
+	for _, r := range releases {
+		if r.existing == nil {
+			pkg.Logf("- release %s", r.archived.GetTagName())
+		}
+		for name := range r.assets {
+			pkg.Logf("- asset %s/%s", r.archived.GetTagName(), name)
+		}
+	}
+	pkg.PrintSeparator()
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to restore %d ref(s), %d release(s) and %d asset(s) in repository %q?",
+		len(missingRefs), missingReleases, missingAssets, d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return res, nil
+	}
+
+	// Create the refs first, because the releases need the tags.
+	for _, ref := range missingRefs {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		if _, err := pkg.GitHubCreateRef(d, d.DestRepo(), ref.GetRef(), ref.GetObject().GetSHA(), d.DryRun); err != nil {
+			return res, err
+		}
+		res.Refs = append(res.Refs, ref.GetRef())
+	}
+
+	for _, r := range releases {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		tag := r.archived.GetTagName()
+		release := r.existing
+		if release == nil {
+			if release, err = pkg.GitHubCreateRelease(d, d.DestRepo(), r.archived, d.DryRun); err != nil {
+				return res, err
+			}
+			res.Releases = append(res.Releases, tag)
+		}
+		if len(r.assets) == 0 {
+			continue
+		}
+		uploaded, err := pkg.GitHubUploadReleaseAssets(d, d.DestRepo(), release, r.assets, d.DryRun)
+		for _, asset := range uploaded {
+			if _, ok := r.assets[asset.GetName()]; ok {
+				res.Assets = append(res.Assets, tag+"/"+asset.GetName())
+			}
+		}
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// hasAsset returns true if the release has an asset with the given name.
+func hasAsset(release *github.RepositoryRelease, name string) bool {
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
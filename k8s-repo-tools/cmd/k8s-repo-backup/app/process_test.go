@@ -0,0 +1,273 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func newRef(ref, sha string) *github.Reference {
+	return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+}
+
+func TestProcessBackup(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	refs := []*github.Reference{
+		newRef("refs/heads/master", "1"),
+		newRef("refs/tags/v1.17.0", "0"),
+	}
+	releases := []*github.RepositoryRelease{
+		{
+			TagName: github.String("v1.17.0"),
+			Assets: []github.ReleaseAsset{
+				{ID: github.Int64(1), Name: github.String("a.txt"), Size: github.Int(3)},
+			},
+		},
+	}
+	milestones := []*github.Milestone{
+		{Number: github.Int(1), Title: github.String("v1.17")},
+	}
+	contents := map[int64][]byte{1: []byte("foo")}
+
+	tests := []struct {
+		name           string
+		methodErrors   map[string]bool
+		expectedResult *result
+		expectedError  bool
+	}{
+		{
+			name: "valid: write a backup",
+			expectedResult: &result{
+				Refs:       []string{"refs/tags/v1.17.0", "refs/heads/master"},
+				Releases:   []string{"v1.17.0"},
+				Assets:     []string{"v1.17.0/a.txt"},
+				Milestones: []string{"v1.17"},
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the releases",
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "k8s-repo-backup")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			data := &pkg.Data{Dest: []string{"org/dest"}, BackupDir: dir}
+
+			// Create fake client and setup endpoint handlers.
+			api := "https://api.github.com/repos/org/dest/"
+			pkg.NewClient(data, pkg.NewTransport())
+			data.Transport.SetHandler(api+"git/refs", pkg.NewReferenceHandler(&refs, map[string]bool{}))
+			data.Transport.SetHandler(api+"releases", pkg.NewReleaseHandler(&releases, tt.methodErrors))
+			data.Transport.SetHandler(api+"releases/assets", pkg.NewReleaseAssetDownloadHandler(contents, map[string]bool{}))
+			data.Transport.SetHandler(api+"milestones", pkg.NewMilestoneHandler(&milestones, map[string]bool{}))
+
+			res, err := process(data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(res, tt.expectedResult) {
+				t.Errorf("expected result:\n%+v\ngot:\n%+v", tt.expectedResult, res)
+			}
+
+			// Read the archive back.
+			a, err := readArchive(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(a.Refs) != len(refs) || len(a.Releases) != len(releases) || len(a.Milestones) != len(milestones) {
+				t.Errorf("expected %d refs, %d releases and %d milestones, got %d, %d and %d",
+					len(refs), len(releases), len(milestones), len(a.Refs), len(a.Releases), len(a.Milestones))
+			}
+			buf, err := ioutil.ReadFile(assetPath(dir, "v1.17.0", "a.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != "foo" {
+				t.Errorf("expected asset contents %q, got %q", "foo", buf)
+			}
+		})
+	}
+}
+
+func TestProcessRestore(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-repo-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Write an archive with a release for v1.17.0 that has one asset.
+	a := &archive{
+		Refs: []*github.Reference{
+			newRef("refs/heads/master", "1"),
+			newRef("refs/tags/v1.17.0", "0"),
+		},
+		Releases: []*github.RepositoryRelease{
+			{
+				TagName: github.String("v1.17.0"),
+				Name:    github.String("v1.17.0"),
+				Assets:  []github.ReleaseAsset{{Name: github.String("a.txt")}},
+			},
+		},
+		Milestones: []*github.Milestone{},
+	}
+	if err := writeArchive(dir, a); err != nil {
+		t.Fatal(err)
+	}
+	path := assetPath(dir, "v1.17.0", "a.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		refs           []*github.Reference
+		releases       []*github.RepositoryRelease
+		methodErrors   map[string]bool
+		expectedResult *result
+		expectedError  bool
+		skipDryRun     bool
+	}{
+		{
+			name: "valid: restore the missing tag, release and asset",
+			refs: []*github.Reference{newRef("refs/heads/master", "2")},
+			expectedResult: &result{
+				Refs:       []string{"refs/tags/v1.17.0"},
+				Releases:   []string{"v1.17.0"},
+				Assets:     []string{"v1.17.0/a.txt"},
+				Milestones: []string{},
+			},
+		},
+		{
+			name: "valid: restore the missing asset of an existing release",
+			refs: a.Refs,
+			releases: []*github.RepositoryRelease{
+				{TagName: github.String("v1.17.0")},
+			},
+			expectedResult: &result{
+				Refs:       []string{},
+				Releases:   []string{},
+				Assets:     []string{"v1.17.0/a.txt"},
+				Milestones: []string{},
+			},
+		},
+		{
+			name: "valid: nothing to restore",
+			refs: a.Refs,
+			releases: []*github.RepositoryRelease{
+				{TagName: github.String("v1.17.0"), Assets: []github.ReleaseAsset{{Name: github.String("a.txt")}}},
+			},
+			expectedResult: newResult(),
+		},
+		{
+			name:          "invalid: simulated error when creating the release",
+			refs:          a.Refs,
+			methodErrors:  map[string]bool{http.MethodPost: true},
+			expectedError: true,
+			skipDryRun:    true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Dest:      []string{"org/dest"},
+					BackupDir: dir,
+					Restore:   true,
+					DryRun:    dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Copy the test data, since the handlers modify it.
+				refsDest := append([]*github.Reference{}, tt.refs...)
+				releasesDest := append([]*github.RepositoryRelease{}, tt.releases...)
+				uploaded := &github.RepositoryRelease{}
+
+				// Create fake client and setup endpoint handlers.
+				api := "https://api.github.com/repos/org/dest/"
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler(api+"git/refs", pkg.NewReferenceHandler(&refsDest, map[string]bool{}))
+				data.Transport.SetHandler(api+"releases", pkg.NewReleaseHandler(&releasesDest, tt.methodErrors))
+				// Releases in the fake API have the ID 0.
+				data.Transport.SetHandler("https://uploads.github.com/repos/org/dest/releases/0/assets",
+					pkg.NewReleaseAssetsHandler(uploaded, map[string]bool{}))
+
+				res, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(res, tt.expectedResult) {
+					t.Errorf("expected result:\n%+v\ngot:\n%+v", tt.expectedResult, res)
+				}
+
+				// In dry-run mode nothing is written.
+				expectedRefs, expectedAssets := len(tt.refs), 0
+				if !dryRunVal {
+					expectedRefs += len(tt.expectedResult.Refs)
+					expectedAssets = len(tt.expectedResult.Assets)
+				}
+				if len(refsDest) != expectedRefs {
+					t.Errorf("expected %d refs, got %d", expectedRefs, len(refsDest))
+				}
+				if len(uploaded.Assets) != expectedAssets {
+					t.Errorf("expected %d uploaded assets, got %d", expectedAssets, len(uploaded.Assets))
+				}
+			})
+		}
+	}
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"bytes"
@@ -46,6 +46,24 @@ func TestFormatOutput(t *testing.T) {
 			},
 			expectedBuf: []byte(`{"outputError":null,"reference":{"ref":null,"url":null,"object":null},"commit":{}}`),
 		},
+		{
+			name: "with the next pre-release tag",
+			out: &output{
+				Reference: &github.Reference{},
+				Commit:    &github.RepositoryCommit{},
+				Tag:       &github.Reference{Ref: github.String("refs/tags/v1.17.0-beta.2")},
+			},
+			expectedBuf: []byte(`{"outputError":null,"reference":{"ref":null,"url":null,"object":null},"commit":{},` +
+				`"tag":{"ref":"refs/tags/v1.17.0-beta.2","url":null,"object":null}}`),
+		},
+		{
+			name: "partial",
+			out: &output{
+				OutputError: github.String("interrupted by signal"),
+				Partial:     true,
+			},
+			expectedBuf: []byte(`{"outputError":"interrupted by signal","reference":null,"commit":null,"partial":true}`),
+		},
 	}
 
 	for _, tt := range tests {
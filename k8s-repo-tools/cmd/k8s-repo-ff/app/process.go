@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// process is responsible for all operations that the application performs
+// on the destination repository dest. It returns the fast-forwarded branch, the merge commit and, with
+// --tag-next-pre-release, the pre-release tag that was created.
+func process(d *pkg.Data, dest string) (*github.Reference, *github.RepositoryCommit, *github.Reference, error) {
+
+	pkg.Logf("using branch prefix %q", d.PrefixBranch)
+
+	// Obtain destination repository tags and branches.
+	tagsDest, branchesDest, err := d.Provider().GetTagsAndBranches(dest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Trim branches and tags that are not usable.
+	pkg.LogRefList("existing tags", dest, tagsDest)
+	pkg.LogRefList("existing branches", dest, branchesDest)
+
+	// Find the latest versioned branch.
+	latestBranch, err := pkg.FindLatestBranch(branchesDest, d.PrefixBranch, d.Strict)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pkg.Logf("found %q as the latest versioned branch", latestBranch.GetRef())
+
+	// Find the latest tag for this versioned branch.
+	latestBranchVer, _ := pkg.BranchRefToVersion(latestBranch, d.PrefixBranch)
+	latestTag, err := pkg.FindLatestTag(tagsDest, latestBranchVer, d.Strict)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pkg.Logf("found %q as the latest versioned tag for branch %q", latestTag.GetRef(), latestBranch.GetRef())
+
+	// Check if the latest tag falls within the fast-forward window.
+	latestTagVer, _ := pkg.TagRefToVersion(latestTag)
+	if !pkg.InFastForwardWindow(latestTagVer, latestBranchVer) {
+		minVersion, maxVersion := pkg.FastForwardWindow(latestBranchVer)
+		return nil, nil, nil, pkg.NewErrorf(pkg.ErrorKindWindowClosed,
+			"the latest versioned tag %q for branch %q does not fall within the fast-forward window: %s <= VER < %s",
+			latestTag.GetRef(), latestBranch, minVersion.String(), maxVersion.String())
+	}
+
+	// Find the HEAD of the master branch, which is used for the comparison
+	// and to verify the merge.
+	var masterSHA string
+	for _, b := range branchesDest {
+		if strings.TrimPrefix(b.GetRef(), "refs/heads/") == pkg.BranchMaster {
+			masterSHA = b.GetObject().GetSHA()
+			break
+		}
+	}
+	if len(masterSHA) == 0 {
+		return nil, nil, nil, errors.Errorf("the repository %q does not have a branch called %q", dest, pkg.BranchMaster)
+	}
+
+	// Compare the latest and the master branches.
+	cmp, err := d.Provider().CompareBranches(dest, latestBranch.GetRef(), masterSHA)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	switch cmp.GetStatus() {
+	case "identical":
+		return nil, nil, nil, pkg.NewErrorf(pkg.ErrorKindIdenticalBranches,
+			"the branches %q and %q are identical", pkg.BranchMaster, latestBranch.GetRef())
+	default:
+		break
+	}
+
+	pkg.Logf("branch comparison status between %q and %q is reported as %q and there are %d different commit(s)",
+		pkg.BranchMaster, latestBranch.GetRef(), cmp.GetStatus(), len(cmp.Commits))
+	if len(cmp.Commits) > 0 {
+		commitURLs := "list of commits from the comparison:"
+		for _, c := range cmp.Commits {
+			commitURLs += "\n" + c.GetHTMLURL()
+		}
+		pkg.Logf(commitURLs)
+	}
+	pkg.Logf("comparison URL:\n%s", cmp.GetHTMLURL())
+
+	// Find the next pre-release tag to create after the merge. If the next
+	// pre-release is not in the fast-forward window the branch is still
+	// fast-forwarded, but without a tag.
+	var nextTagRef string
+	if d.TagNextPreRelease {
+		nextTagRef, err = findNextPreReleaseTag(tagsDest, latestTagVer, latestBranchVer)
+		switch {
+		case pkg.ErrorKindOf(err) == pkg.ErrorKindWindowClosed:
+			pkg.Warningf("%v", err)
+		case err != nil:
+			return nil, nil, nil, err
+		default:
+			pkg.Logf("the tag %q will be created after the merge", nextTagRef)
+		}
+	}
+
+	var promptMessage string
+	var yes bool
+
+	// Prompt the user.
+	promptMessage = fmt.Sprintf("Do you want to fast-forward branch %q of repository %q?",
+		latestBranch.GetRef(), dest)
+	if len(nextTagRef) != 0 {
+		promptMessage = fmt.Sprintf("Do you want to fast-forward branch %q of repository %q and create tag %q?",
+			latestBranch.GetRef(), dest, nextTagRef)
+	}
+	if yes, err = d.Prompter().Confirm(promptMessage); err != nil {
+		return nil, nil, nil, err
+	} else if yes {
+		goto write
+	}
+	return nil, nil, nil, nil
+
+write:
+	// Merge the branches.
+	commitMessage := pkg.FormatMergeCommitMessage(latestBranch.GetRef(), pkg.BranchMaster)
+	commit, resp, err := d.Provider().MergeBranch(dest, latestBranch.GetRef(), pkg.BranchMaster, commitMessage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	mergeStatus := resp.StatusCode
+	switch mergeStatus {
+	case http.StatusCreated:
+		break
+	case http.StatusNoContent:
+		return nil, nil, nil, pkg.NewErrorf(pkg.ErrorKindNothingToMerge, "got status %d when merging branch %q into %q.",
+			mergeStatus, pkg.BranchMaster, latestBranch.GetRef())
+	default: // Should not happen?
+		return nil, nil, nil, errors.Errorf("unexpected status %d when merging branch %q into %q. "+
+			"Please verify if the branch is mergeable!",
+			mergeStatus, pkg.BranchMaster, latestBranch.GetRef())
+	}
+	pkg.Noticef("created commit with SHA %q in repository %q", commit.GetSHA(), dest)
+
+	if !d.DryRun {
+		if err := verifyMerge(d, dest, latestBranch.GetRef(), masterSHA); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Create the next pre-release tag at the merge commit.
+	if len(nextTagRef) == 0 {
+		return latestBranch, commit, nil, nil
+	}
+	tag, err := d.Provider().CreateRef(dest, nextTagRef, commit.GetSHA(), d.DryRun)
+	if err != nil {
+		return latestBranch, commit, nil, errors.Wrapf(err, "could not create the tag %q after the merge", nextTagRef)
+	}
+	pkg.Noticef("created tag %q at commit %q in repository %q", nextTagRef, commit.GetSHA(), dest)
+	return latestBranch, commit, tag, nil
+}
+
+// findNextPreReleaseTag returns the ref of the pre-release tag that follows the
+// latest tag of a versioned branch after a fast-forward. The tag must not exist.
+func findNextPreReleaseTag(tags []*github.Reference, latestTagVer, branchVer *version.Version) (string, error) {
+	next, err := pkg.NextFastForwardPreRelease(latestTagVer, branchVer)
+	if err != nil {
+		return "", pkg.NewErrorf(pkg.ErrorKindWindowClosed, "cannot create the next pre-release tag: %v", err)
+	}
+	ref := "refs/tags/v" + next.String()
+	for _, tag := range tags {
+		if tag.GetRef() == ref {
+			return "", pkg.NewErrorf(pkg.ErrorKindConflict, "the tag %q already exists", ref)
+		}
+	}
+	return ref, nil
+}
+
+// verifyMerge fetches the branch again after the merge and verifies that its HEAD
+// contains the HEAD of the master branch that was compared. This is not the case
+// if the branch was changed by someone else in the meantime, for example if it
+// was reset or force pushed.
+func verifyMerge(d *pkg.Data, dest, branch, masterSHA string) error {
+	ref, err := d.Provider().GetRef(dest, branch)
+	if err != nil {
+		return errors.Wrapf(err, "could not verify the merge into branch %q", branch)
+	}
+	branchSHA := ref.GetObject().GetSHA()
+	cmp, err := d.Provider().CompareBranches(dest, masterSHA, branchSHA)
+	if err != nil {
+		return errors.Wrapf(err, "could not verify the merge into branch %q", branch)
+	}
+	switch cmp.GetStatus() {
+	case "ahead", "identical":
+		pkg.Logf("verified that the HEAD %q of branch %q contains the commit %q of branch %q",
+			branchSHA, branch, masterSHA, pkg.BranchMaster)
+		return nil
+	}
+	return pkg.NewErrorf(pkg.ErrorKindVerificationFailed,
+		"the branch %q was not fast-forwarded: its HEAD %q does not contain the commit %q of branch %q "+
+			"that was compared (comparison status %q). The branch may have been changed during the merge",
+		branch, branchSHA, masterSHA, pkg.BranchMaster, cmp.GetStatus())
+}
@@ -14,9 +14,11 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -44,9 +46,12 @@ func TestProcess(t *testing.T) {
 		skipDryRun          bool
 		mergeStatus         int
 		mergeRequest        *github.RepositoryMergeRequest
+		verifyStatus        string
+		tagNextPreRelease   bool
 		expectedBranch      *github.Reference
 		expectedCommit      *github.RepositoryCommit
-		expectedError       error
+		expectedTag         *github.Reference
+		expectedError       pkg.ErrorKind
 	}{
 		{
 			name:            "invalid: return error obtaining refs",
@@ -54,7 +59,7 @@ func TestProcess(t *testing.T) {
 			commitsBranch:   []*github.RepositoryCommit{},
 			refsDest:        []*github.Reference{},
 			methodErrorsRef: map[string]bool{http.MethodGet: true},
-			expectedError:   &genericError{},
+			expectedError:   pkg.ErrorKindGeneric,
 		},
 		{
 			name:          "invalid: cannot find a SemVer tag for the latest release branch",
@@ -65,14 +70,14 @@ func TestProcess(t *testing.T) {
 				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
-			expectedError: &genericError{},
+			expectedError: pkg.ErrorKindGeneric,
 		},
 		{
 			name:          "invalid: return an error if there are no release branches",
 			commitsMaster: []*github.RepositoryCommit{},
 			commitsBranch: []*github.RepositoryCommit{},
 			refsDest:      []*github.Reference{},
-			expectedError: &releaseBranchError{},
+			expectedError: pkg.ErrorKindNoReleaseBranch,
 		},
 		{
 			name:          "invalid: there is a release branch and tags but not in the ff window",
@@ -83,7 +88,7 @@ func TestProcess(t *testing.T) {
 				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
-			expectedError: &fastForwardWindowError{},
+			expectedError: pkg.ErrorKindWindowClosed,
 		},
 		{
 			name:          "invalid: return error on identical branches",
@@ -94,7 +99,7 @@ func TestProcess(t *testing.T) {
 				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
-			expectedError: &identicalBranchesError{},
+			expectedError: pkg.ErrorKindIdenticalBranches,
 		},
 		{
 			name:          "valid: do not return error if master is behind",
@@ -132,7 +137,7 @@ func TestProcess(t *testing.T) {
 				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
 			methodErrorsCompare: map[string]bool{http.MethodGet: true},
-			expectedError:       &genericError{},
+			expectedError:       pkg.ErrorKindGeneric,
 		},
 		{
 			name: "invalid: return error merging branches",
@@ -149,7 +154,7 @@ func TestProcess(t *testing.T) {
 				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
 			methodErrorsMerge: map[string]bool{http.MethodPost: true},
-			expectedError:     &genericError{},
+			expectedError:     pkg.ErrorKindGeneric,
 			skipDryRun:        true,
 		},
 		{
@@ -172,7 +177,7 @@ func TestProcess(t *testing.T) {
 				CommitMessage: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster)),
 			},
 			mergeStatus:   http.StatusNoContent,
-			expectedError: &noContentError{},
+			expectedError: pkg.ErrorKindNothingToMerge,
 			skipDryRun:    true,
 		},
 		{
@@ -195,7 +200,7 @@ func TestProcess(t *testing.T) {
 				CommitMessage: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster)),
 			},
 			mergeStatus:   http.StatusPartialContent,
-			expectedError: &genericError{},
+			expectedError: pkg.ErrorKindGeneric,
 			skipDryRun:    true,
 		},
 		{
@@ -227,6 +232,95 @@ func TestProcess(t *testing.T) {
 				Object: &github.GitObject{SHA: github.String("1234567890")},
 			},
 		},
+		{
+			name: "valid: successful merge of branches with the next pre-release tag",
+			commitsMaster: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			commitsBranch: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			refsDest: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0-beta.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0-beta.1"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+			},
+			mergeRequest: &github.RepositoryMergeRequest{
+				Base:          github.String("refs/heads/release-1.17"),
+				Head:          github.String(pkg.BranchMaster),
+				CommitMessage: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster)),
+			},
+			mergeStatus:       http.StatusCreated,
+			tagNextPreRelease: true,
+			expectedCommit: &github.RepositoryCommit{
+				SHA:    github.String("dry-run-sha"),
+				Commit: &github.Commit{Message: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster))},
+			},
+			expectedBranch: &github.Reference{
+				Ref:    github.String("refs/heads/release-1.17"),
+				Object: &github.GitObject{SHA: github.String("1234567890")},
+			},
+			expectedTag: &github.Reference{
+				Ref:    github.String("refs/tags/v1.17.0-beta.2"),
+				Object: &github.GitObject{SHA: github.String("dry-run-sha")},
+			},
+		},
+		{
+			name: "valid: successful merge of branches without a tag after rc.0",
+			commitsMaster: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			commitsBranch: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			refsDest: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0-rc.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+			},
+			mergeRequest: &github.RepositoryMergeRequest{
+				Base:          github.String("refs/heads/release-1.17"),
+				Head:          github.String(pkg.BranchMaster),
+				CommitMessage: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster)),
+			},
+			mergeStatus:       http.StatusCreated,
+			tagNextPreRelease: true,
+			expectedCommit: &github.RepositoryCommit{
+				SHA:    github.String("dry-run-sha"),
+				Commit: &github.Commit{Message: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster))},
+			},
+			expectedBranch: &github.Reference{
+				Ref:    github.String("refs/heads/release-1.17"),
+				Object: &github.GitObject{SHA: github.String("1234567890")},
+			},
+		},
+		{
+			name: "invalid: the branch does not contain master after the merge",
+			commitsMaster: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			commitsBranch: []*github.RepositoryCommit{
+				&github.RepositoryCommit{SHA: github.String("some-sha")},
+			},
+			refsDest: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0-beta.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+			},
+			mergeRequest: &github.RepositoryMergeRequest{
+				Base:          github.String("refs/heads/release-1.17"),
+				Head:          github.String(pkg.BranchMaster),
+				CommitMessage: github.String(pkg.FormatMergeCommitMessage("refs/heads/release-1.17", pkg.BranchMaster)),
+			},
+			mergeStatus:   http.StatusCreated,
+			verifyStatus:  "diverged",
+			expectedError: pkg.ErrorKindVerificationFailed,
+			skipDryRun:    true,
+		},
 	}
 
 	// Make sure there are consistent results between dry-run and regular mode.
@@ -240,10 +334,11 @@ func TestProcess(t *testing.T) {
 				}
 
 				data := &pkg.Data{}
-				data.Dest = "org/dest"
+				data.Dest = []string{"org/dest"}
 				data.PrefixBranch = pkg.PrefixBranch
-				data.Force = true
+				data.SetPrompter(pkg.PrompterYes)
 				data.DryRun = dryRunVal
+				data.TagNextPreRelease = tt.tagNextPreRelease
 
 				if tt.methodErrorsRef == nil {
 					tt.methodErrorsRef = map[string]bool{}
@@ -271,17 +366,31 @@ func TestProcess(t *testing.T) {
 				data.Transport.SetHandler(testCommits, handlerCompare)
 				data.Transport.SetHandler(testMerges, handlerMerge)
 
-				ref, commit, err := process(data)
+				// The merge is verified by comparing the HEAD of master with the HEAD of the branch.
+				if len(tt.verifyStatus) == 0 {
+					tt.verifyStatus = "ahead"
+				}
+				data.Transport.SetHandler(testCommits+"/1234567890...", func(req *http.Request) (*http.Response, error) {
+					buf, err := json.Marshal(&github.CommitsComparison{Status: github.String(tt.verifyStatus)})
+					if err != nil {
+						return nil, err
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+						Header:     http.Header{},
+					}, nil
+				})
+
+				ref, commit, tag, err := process(data, data.DestRepo())
 				if err != nil {
 					pkg.Errorf("TEST: process error (%v): %v", reflect.TypeOf(err), err)
 				}
-				if (err != nil) != (tt.expectedError != nil) {
-					t.Fatalf("expected error %v, got %v", tt.expectedError != nil, err != nil)
+				if (err != nil) != (len(tt.expectedError) != 0) {
+					t.Fatalf("expected error %v, got %v", len(tt.expectedError) != 0, err != nil)
 				}
-				expectedErrorType := reflect.TypeOf(tt.expectedError)
-				errorType := reflect.TypeOf(err)
-				if expectedErrorType != errorType {
-					t.Errorf("expected error type %v, got %v, error: %v", expectedErrorType, errorType, err)
+				if errorKind := pkg.ErrorKindOf(err); errorKind != tt.expectedError {
+					t.Errorf("expected error kind %q, got %q, error: %v", tt.expectedError, errorKind, err)
 				}
 				if err != nil {
 					return
@@ -293,6 +402,9 @@ func TestProcess(t *testing.T) {
 				if !reflect.DeepEqual(commit, tt.expectedCommit) {
 					t.Errorf("expected commit:\n%v\ngot:\n%v\n", tt.expectedCommit, commit)
 				}
+				if !reflect.DeepEqual(tag, tt.expectedTag) {
+					t.Errorf("expected tag:\n%v\ngot:\n%v\n", tt.expectedTag, tag)
+				}
 			})
 		}
 	}
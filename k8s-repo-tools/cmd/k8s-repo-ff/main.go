@@ -17,81 +17,13 @@ limitations under the License.
 package main
 
 import (
-	"flag"
-	"fmt"
 	"os"
 
+	"k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-repo-ff/app"
 	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
-func printUsage() {
-	out := os.Stderr
-	fmt.Fprintln(out, "k8s-repo-ff is a tool for fast-forwarding a release branch "+
-		"to the master branch of a GitHub repository")
-	fmt.Fprintln(out, "\nusage:")
-	fmt.Fprintf(out, "  k8s-repo-ff -dest=org/repo -token=<token> <options>\n\n")
-	flag.CommandLine.PrintDefaults()
-}
-
 func main() {
-	// Set the default output writers.
-	pkg.SetLogWriters(os.Stdout, os.Stderr)
-
-	// Initialize the main data structure.
-	d := pkg.Data{}
-
-	// Manage flags and source.
-	flag.Usage = printUsage
-	flag.CommandLine.SetOutput(os.Stderr)
-	flagList := []string{
-		pkg.FlagDest,
-		pkg.FlagToken,
-		pkg.FlagPrefixBranch,
-		pkg.FlagTimeout,
-		pkg.FlagDryRun,
-		pkg.FlagForce,
-		pkg.FlagOutput,
-	}
-	pkg.SetupFlags(&d, flag.CommandLine, flagList, nil)
-	flag.Parse()
-
-	// Validate the user parameters.
-	if err := validateData(&d); err != nil {
-		pkg.PrintErrorAndExit(err)
-	}
-
-	// Print a warning in dry-run mode.
-	if d.DryRun {
-		pkg.PrintSeparator()
-		pkg.Warningf("running in %s mode. To enable repository writing operations pass --%s=false", pkg.PrefixDryRun, pkg.FlagDryRun)
-		pkg.PrintSeparator()
-	}
-
-	// Create an HTTP client and process the data.
-	pkg.NewClient(&d, nil)
-	ref, commit, err := process(&d)
-	if err != nil {
-		// Handle non-fatal errors.
-		switch err.(type) {
-		case *releaseBranchError:
-			break
-		case *fastForwardWindowError:
-			break
-		case *identicalBranchesError:
-			break
-		case *noContentError:
-			break
-		default:
-			pkg.PrintErrorAndExit(err)
-		}
-		pkg.Errorf(err.Error())
-	}
-
-	// Write the output to disk.
-	if len(d.Output) != 0 {
-		if err := writeOutputToFile(d.Output, ref, commit, err); err != nil {
-			pkg.PrintErrorAndExit(err)
-		}
-	}
-	pkg.Logf("done!")
+	app.Main(os.Args[1:])
+	pkg.FinishRun(0, nil)
 }
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// Name is the name of the tool.
+const Name = "k8s-repo-sync"
+
+func printUsage(fs *flag.FlagSet) {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-repo-sync is a tool for synchronizing tags and branches "+
+		"between GitHub repositories")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintf(out, "  k8s-repo-sync -source=org/repo -dest=org/repo "+
+		"-min-version=v1.17.0 -token <token> <options>\n\n")
+	fs.PrintDefaults()
+}
+
+// Main runs the tool with the given command line arguments.
+func Main(args []string) {
+	// Set the default output writers.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+
+	// Initialize the main data structure.
+	d := pkg.Data{}
+
+	// Manage flags and source.
+	fs := flag.NewFlagSet(Name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	fs.SetOutput(os.Stderr)
+	pkg.SetupOptions(&d, fs, &options{})
+	if err := pkg.ParseFlags(fs, args); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Apply a plan that was written in DRY-RUN mode.
+	if len(d.ApplyPlanFile) != 0 {
+		pkg.RunApplyPlan(&d, Name)
+		return
+	}
+
+	// Validate the user parameters.
+	if err := validateData(&d); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Send a Slack notification with the result when the run finishes.
+	if err := pkg.NotifySlackOnFinish(&d, Name, d.DestRepo()); err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Print a warning in dry-run mode.
+	if d.DryRun {
+		pkg.PrintSeparator()
+		pkg.Warningf("running in %s mode. To enable repository writing operations pass --%s=false", pkg.PrefixDryRun, pkg.FlagDryRun)
+		pkg.PrintSeparator()
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM.
+	stop := pkg.HandleSignals(&d)
+	defer stop()
+
+	// Create an HTTP client and process the data.
+	pkg.NewClient(&d, nil)
+
+	// Verify the token before writing.
+	if d.Preflight && !d.DryRun {
+		if err := d.Provider().PreflightToken(d.DestRepo()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	refs, divergent, err := process(&d, d.SourceRepo(), d.DestRepo())
+
+	// Add the written References to the job summary in GitHub Actions.
+	pkg.AddSummarySection(summaryTitle(&d), formatSummary(&d, refs, divergent))
+	if len(divergent) != 0 {
+		pkg.AddSummarySection(fmt.Sprintf("Conflicts between `%s` and `%s`", d.SourceRepo(), d.DestRepo()),
+			formatConflictsSummary(&d, divergent))
+	}
+	if err != nil && d.Interrupted() {
+		// Write the References that were created before the interrupt.
+		if len(d.Output) != 0 {
+			if outputErr := writeOutputToFile(&d, d.Output, refs, divergent); outputErr != nil {
+				pkg.PrintErrorAndExit(outputErr)
+			}
+			if markerErr := pkg.WritePartialMarker(d.Output, err); markerErr != nil {
+				pkg.PrintErrorAndExit(markerErr)
+			}
+		}
+		pkg.ExitInterrupted(err)
+	}
+	if err != nil {
+		pkg.PrintErrorAndExit(err)
+	}
+
+	// Write the output References to disk.
+	if len(d.Output) != 0 {
+		if err := writeOutputToFile(&d, d.Output, refs, divergent); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+
+	// Write the plan in DRY-RUN mode.
+	if d.DryRun && len(d.PlanFile) != 0 {
+		if err := pkg.WritePlanToFile(d.PlanFile, Name, d.GetPlanSteps()); err != nil {
+			pkg.PrintErrorAndExit(err)
+		}
+	}
+	pkg.Logf("done!")
+}
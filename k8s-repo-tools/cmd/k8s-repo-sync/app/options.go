@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	pkg.GiteaOptions
+	pkg.SlackOptions
+	Dest                 []string `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	Source               []string `flag:"source" usage:"Source org/repo from which to take tags and branches" validate:"required,repo"`
+	MinVersion           string   `flag:"min-version" usage:"All versions for tags and branches older than this SemVer will be ignored" validate:"required,semver"`
+	PrefixBranch         string   `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	Output               string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+	CacheDir             string   `flag:"cache-dir" usage:"Path to a directory in which to cache the tags and branches of repositories between runs. Refs that did not change are not downloaded again"`
+	GraphQL              bool     `flag:"graphql" usage:"Use the GitHub GraphQL API to fetch the tags and branches of a repository with one query and to create refs in batches. This needs fewer requests than the REST API"`
+	CompareSHA           bool     `flag:"compare-sha" usage:"Report refs that exist in the source and destination repositories, but point to different SHAs. Only useful if the destination repository mirrors the history of the source repository" validate:"requiredif=force-update"`
+	ForceUpdate          bool     `flag:"force-update" usage:"Update the divergent refs found with \"compare-sha\" in the destination repository to the SHAs of the source repository"`
+	RequireSourceRelease bool     `flag:"require-source-release" usage:"Only sync the tags that have a published GitHub release in the source repository. Tags without a release or with a draft release are skipped"`
+	Strict               bool     `flag:"strict" usage:"Fail instead of continuing with a warning when refs that are not valid versions are found or a repository has been renamed"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the Gitea options.
+	if err := pkg.ValidateGiteaOptions(d); err != nil {
+		return err
+	}
+
+	return nil
+}
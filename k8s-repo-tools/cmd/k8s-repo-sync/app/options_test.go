@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"io/ioutil"
@@ -37,8 +37,8 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.0",
 				Token:      validToken,
-				Source:     "org/src",
-				Dest:       "org/dest",
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
 			},
 		},
 		{
@@ -46,8 +46,8 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.0",
 				Token:      "v1." + validToken,
-				Source:     "org/src",
-				Dest:       "org/dest",
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
 			},
 		},
 		{
@@ -55,8 +55,6 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "",
 				Token:      "",
-				Source:     "",
-				Dest:       "",
 			},
 			expectedError: true,
 		},
@@ -65,8 +63,8 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.z",
 				Token:      validToken,
-				Source:     "org/src",
-				Dest:       "org/dest",
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
 			},
 			expectedError: true,
 		},
@@ -75,8 +73,8 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.0",
 				Token:      "282ef40c7d38cbfa",
-				Source:     "org/src",
-				Dest:       "org/dest",
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
 			},
 			expectedError: true,
 		},
@@ -85,8 +83,19 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.0",
 				Token:      "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz",
-				Source:     "org/src",
-				Dest:       "org/dest",
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/dest"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: force-update without compare-sha",
+			data: &pkg.Data{
+				MinVersion:  "v1.17.0",
+				Token:       validToken,
+				Source:      []string{"org/src"},
+				Dest:        []string{"org/dest"},
+				ForceUpdate: true,
 			},
 			expectedError: true,
 		},
@@ -95,8 +104,18 @@ func TestValidateData(t *testing.T) {
 			data: &pkg.Data{
 				MinVersion: "v1.17.0",
 				Token:      validToken,
-				Source:     "foo",
-				Dest:       "bar/",
+				Source:     []string{"foo"},
+				Dest:       []string{"bar/"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: multiple destination repositories",
+			data: &pkg.Data{
+				MinVersion: "v1.17.0",
+				Token:      validToken,
+				Source:     []string{"org/src"},
+				Dest:       []string{"org/a", "org/b"},
 			},
 			expectedError: true,
 		},
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// output is the output structure.
+type output struct {
+	// Refs are the tags and branches that were written to the destination repository.
+	Refs []*github.Reference `json:"refs"`
+	// Conflicts are the tags and branches that exist in both repositories,
+	// but point to different SHAs. They are only found with --compare-sha.
+	Conflicts []*conflict `json:"conflicts"`
+}
+
+// conflict is a ref that points to different SHAs in the source and destination
+// repositories, with the URL that compares the two SHAs.
+type conflict struct {
+	pkg.DivergentRef
+	CompareURL string `json:"compareURL"`
+}
+
+// newOutput returns the output for the written refs and the divergent refs.
+func newOutput(d *pkg.Data, refs []*github.Reference, divergent []pkg.DivergentRef) *output {
+	out := &output{Refs: refs, Conflicts: []*conflict{}}
+	if out.Refs == nil {
+		out.Refs = []*github.Reference{}
+	}
+	for _, ref := range divergent {
+		out.Conflicts = append(out.Conflicts, &conflict{
+			DivergentRef: ref,
+			CompareURL:   d.Provider().CompareURL(d.DestRepo(), ref.DestSHA, d.SourceRepo(), ref.SourceSHA),
+		})
+	}
+	return out
+}
+
+// formatOutput marshals the output to JSON.
+func formatOutput(out *output, indent bool) ([]byte, error) {
+	var buf []byte
+	var err error
+	if indent {
+		buf, err = json.MarshalIndent(out, "", "\t")
+	} else {
+		buf, err = json.Marshal(out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// writeOutputToFile writes the written refs and the divergent refs to the
+// given filePath.
+func writeOutputToFile(d *pkg.Data, filePath string, refs []*github.Reference, divergent []pkg.DivergentRef) error {
+	buf, err := formatOutput(newOutput(d, refs, divergent), true)
+	if err != nil {
+		return err
+	}
+
+	pkg.Logf("writing the resulted tags and branches to the file %q", filePath)
+	if err := pkg.WriteOutputFile(filePath, buf); err != nil {
+		return err
+	}
+	return nil
+}
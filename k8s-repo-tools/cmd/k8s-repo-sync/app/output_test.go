@@ -14,13 +14,14 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"bytes"
 	"testing"
 
 	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
 func TestFormatOutput(t *testing.T) {
@@ -31,16 +32,35 @@ func TestFormatOutput(t *testing.T) {
 		&github.Reference{Ref: github.String("/refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("123456780")}},
 	}
 
-	out, err := formatOutput(refs, false)
+	divergent := []pkg.DivergentRef{
+		{Ref: "refs/heads/release-1.15", SourceSHA: "123456780", DestSHA: "abcdef012", Updated: true},
+	}
+	d := &pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}}
+
+	out, err := formatOutput(newOutput(d, refs, divergent), false)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	expectedOut := []byte(`[{"ref":"/refs/tags/v1.16.0","url":null,"object":{"type":null,"sha":"123456780","url":null}},` +
+	expectedOut := []byte(`{"refs":[{"ref":"/refs/tags/v1.16.0","url":null,"object":{"type":null,"sha":"123456780","url":null}},` +
 		`{"ref":"/refs/tags/v1.17.0","url":null,"object":{"type":null,"sha":"123456780","url":null}},` +
 		`{"ref":"/refs/heads/release-1.16","url":null,"object":{"type":null,"sha":"123456780","url":null}},` +
-		`{"ref":"/refs/heads/release-1.17","url":null,"object":{"type":null,"sha":"123456780","url":null}}]`)
+		`{"ref":"/refs/heads/release-1.17","url":null,"object":{"type":null,"sha":"123456780","url":null}}],` +
+		`"conflicts":[{"ref":"refs/heads/release-1.15","sourceSHA":"123456780","destSHA":"abcdef012","updated":true,` +
+		`"compareURL":"https://github.com/org/dest/compare/abcdef012...org:src:123456780"}]}`)
+
+	if !bytes.Equal(out, expectedOut) {
+		t.Errorf("expected output:\n%s\n, got:\n%s\n", expectedOut, out)
+	}
+}
+
+func TestFormatOutputEmpty(t *testing.T) {
+	out, err := formatOutput(newOutput(&pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}}, nil, nil), false)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
 
+	expectedOut := []byte(`{"refs":[],"conflicts":[]}`)
 	if !bytes.Equal(out, expectedOut) {
 		t.Errorf("expected output:\n%s\n, got:\n%s\n", expectedOut, out)
 	}
@@ -0,0 +1,269 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// process is responsible for all operations that the application performs.
+// It syncs the source repository src with the destination repository dest.
+// It returns the refs that were written to the destination repository and,
+// if d.CompareSHA is set, the refs that point to different SHAs in both repositories.
+func process(d *pkg.Data, src, dest string) ([]*github.Reference, []pkg.DivergentRef, error) {
+
+	// The version should be already validated at this point.
+	minV := version.MustParseSemantic(d.MinVersion)
+	pkg.Logf("using minimum version %q", minV.String())
+	pkg.Logf("using branch prefix %q", d.PrefixBranch)
+
+	// Obtain the tags and branches of both repositories concurrently.
+	var tagsSrc, branchesSrc, tagsDest, branchesDest []*github.Reference
+	err := pkg.RunConcurrently(
+		func() (err error) {
+			tagsSrc, branchesSrc, err = d.SourceProvider().GetTagsAndBranches(src)
+			return err
+		},
+		func() (err error) {
+			tagsDest, branchesDest, err = d.Provider().GetTagsAndBranches(dest)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Trim branches and tags that are not usable.
+	tagsSrcTrimmed, err := pkg.TrimTags(tagsSrc, minV, d.Strict)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "repository %q", src)
+	}
+	branchesSrcTrimmed, err := pkg.TrimBranches(branchesSrc, minV, d.PrefixBranch, d.Strict)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "repository %q", src)
+	}
+	if d.RequireSourceRelease {
+		if tagsSrcTrimmed, err = filterReleasedTags(d, src, tagsSrcTrimmed); err != nil {
+			return nil, nil, err
+		}
+	}
+	pkg.LogRefList("existing tags", src, tagsSrcTrimmed)
+	pkg.LogRefList("existing branches", src, branchesSrcTrimmed)
+
+	// Trim branches and tags that are not usable.
+	tagsDestTrimmed, err := pkg.TrimTags(tagsDest, minV, d.Strict)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "repository %q", dest)
+	}
+	branchesDestTrimmed, err := pkg.TrimBranches(branchesDest, minV, d.PrefixBranch, d.Strict)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "repository %q", dest)
+	}
+	pkg.LogRefList("existing tags", dest, tagsDestTrimmed)
+	pkg.LogRefList("existing branches", dest, tagsDestTrimmed)
+
+	// Find new tags and branches.
+	newTags := pkg.FindNewRefs(tagsSrcTrimmed, tagsDestTrimmed)
+	newBranches := pkg.FindNewRefs(branchesSrcTrimmed, branchesDestTrimmed)
+
+	// Find tags and branches that exist in both repositories, but point to different SHAs.
+	var divergent []pkg.DivergentRef
+	if d.CompareSHA {
+		divergent = append(
+			pkg.NewRefSet(tagsSrcTrimmed).Divergent(pkg.NewRefSet(tagsDestTrimmed)),
+			pkg.NewRefSet(branchesSrcTrimmed).Divergent(pkg.NewRefSet(branchesDestTrimmed))...)
+		for _, ref := range divergent {
+			pkg.Warningf("the ref %q points to %q in repository %q, but to %q in repository %q",
+				ref.Ref, ref.SourceSHA, src, ref.DestSHA, dest)
+		}
+		if len(divergent) == 0 {
+			pkg.Logf("no divergent branches and tags for repository %q", dest)
+		}
+	}
+	updateDivergent := d.ForceUpdate && len(divergent) != 0
+
+	if len(newTags) == 0 && len(newBranches) == 0 && !updateDivergent {
+		pkg.Logf("no new branches and tags for repository %q", dest)
+		return newTags, divergent, nil
+	}
+
+	// Print summary of new refs.
+	pkg.PrintSeparator()
+	pkg.LogRefList("new tags", dest, newTags)
+	pkg.LogRefList("new branches", dest, newBranches)
+	if updateDivergent {
+		pkg.Logf("divergent refs to update in repository %q:", dest)
+		for _, ref := range divergent {
+			pkg.Logf("- %s: %s -> %s", ref.Ref, ref.DestSHA, ref.SourceSHA)
+		}
+	}
+	pkg.PrintSeparator()
+
+	var promptMessage, masterSHA string
+	var branchesDestSet, tagsDestSet *pkg.RefSet
+	var updatedRefs []*github.Reference
+	var yes bool
+
+	// Prompt the user.
+	promptMessage = fmt.Sprintf("Do you want to write these changes to repository %q?", dest)
+	if yes, err = d.Prompter().Confirm(promptMessage); err != nil {
+		return nil, nil, err
+	} else if yes {
+		goto write
+	}
+	goto exit
+
+write:
+	// Find the master SHA and use it for branch creation in the destination repository.
+	for _, b := range branchesDest {
+		if strings.TrimPrefix(b.GetRef(), "refs/heads/") == pkg.BranchMaster {
+			masterSHA = b.GetObject().GetSHA()
+			break
+		}
+	}
+	if len(masterSHA) == 0 {
+		return nil, nil, errors.Errorf("the repository %q does not have a branch called %q", dest, pkg.BranchMaster)
+	}
+
+	// Create branches in the destination repository.
+	if err := pkg.CreateNewBranches(d, dest, &branchesDest, newBranches, masterSHA); err != nil {
+		if err == pkg.ErrInterrupted {
+			return sortRefs(createdRefs(newBranches, branchesDest)), divergent, err
+		}
+		return nil, nil, err
+	}
+
+	if !d.DryRun {
+		// Fetch the branches again. this is not needed in dry-run mode, because
+		// pkg.CreateNewBranches() above manages that.
+		branchesDest, err = d.Provider().GetBranches(dest)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Update the list of new branches for the destination repository.
+	// This updates their SHAs, links and other properties.
+	branchesDestSet = pkg.NewRefSet(branchesDest)
+	for i := range newBranches {
+		if branch, ok := branchesDestSet.Lookup(newBranches[i].GetRef()); ok {
+			*newBranches[i] = *branch
+		}
+	}
+
+	if err := pkg.CreateNewTags(d, dest, &tagsDest, branchesDest, newTags, masterSHA); err != nil {
+		if err == pkg.ErrInterrupted {
+			return sortRefs(append(createdRefs(newTags, tagsDest), newBranches...)), divergent, err
+		}
+		return nil, nil, err
+	}
+
+	if !d.DryRun {
+		// Fetch the tags again. this is not needed in dry-run mode, because
+		// pkg.CreateNewTags() above manages that.
+		tagsDest, err = d.Provider().GetTags(dest)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Update the list of new tags for the destination repository.
+	// this updates their SHAs, links and other properties.
+	tagsDestSet = pkg.NewRefSet(tagsDest)
+	for i := range newTags {
+		if tag, ok := tagsDestSet.Lookup(newTags[i].GetRef()); ok {
+			*newTags[i] = *tag
+		}
+	}
+
+	// Update the divergent refs to the SHAs of the source repository.
+	if updateDivergent {
+		for i := range divergent {
+			if d.Interrupted() {
+				return sortRefs(append(append(newTags, newBranches...), updatedRefs...)), divergent, pkg.ErrInterrupted
+			}
+			ref, err := d.Provider().UpdateRef(dest, divergent[i].Ref, divergent[i].SourceSHA, d.DryRun)
+			if err != nil {
+				return nil, nil, err
+			}
+			divergent[i].Updated = true
+			updatedRefs = append(updatedRefs, ref)
+		}
+	}
+	if !d.DryRun {
+		pkg.Noticef("created %d branches and %d tags and updated %d refs in repository %q",
+			len(newBranches), len(newTags), len(updatedRefs), dest)
+	}
+
+exit:
+	// Sort and return.
+	return sortRefs(append(append(newTags, newBranches...), updatedRefs...)), divergent, nil
+}
+
+// sortRefs sorts a list of refs by name.
+func sortRefs(refs []*github.Reference) []*github.Reference {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].GetRef() < refs[j].GetRef()
+	})
+	return refs
+}
+
+// createdRefs returns the refs from newRefs that are present in destRefs.
+// It is used to obtain the list of refs that were created before an interrupt.
+func createdRefs(newRefs, destRefs []*github.Reference) []*github.Reference {
+	destSet := pkg.NewRefSet(destRefs)
+	result := []*github.Reference{}
+	for _, ref := range newRefs {
+		if dest, ok := destSet.Lookup(ref.GetRef()); ok {
+			result = append(result, dest)
+		}
+	}
+	return result
+}
+
+// filterReleasedTags returns the tags that have a published release in the
+// source repository src, so that tags which were pushed before their release was
+// published are not mirrored yet. Draft releases are not published.
+func filterReleasedTags(d *pkg.Data, src string, tags []*github.Reference) ([]*github.Reference, error) {
+	releases, err := pkg.GitHubGetReleases(d, src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find the published releases of repository %q", src)
+	}
+	published := map[string]bool{}
+	for _, r := range releases {
+		if !r.GetDraft() {
+			published[r.GetTagName()] = true
+		}
+	}
+	result := pkg.NewRefSet(tags).Filter(func(ref *github.Reference) bool {
+		tag := strings.TrimPrefix(ref.GetRef(), "refs/tags/")
+		if published[tag] {
+			return true
+		}
+		pkg.V(1).Logf("skipping tag %q, since it does not have a published release in repository %q", tag, src)
+		return false
+	}).Refs()
+	pkg.Logf("found %d of %d tag(s) with a published release in repository %q", len(result), len(tags), src)
+	return result, nil
+}
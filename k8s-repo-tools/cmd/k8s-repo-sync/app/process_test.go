@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"fmt"
@@ -38,6 +38,7 @@ func TestProcess(t *testing.T) {
 		data             *pkg.Data
 		refsSrc          []*github.Reference
 		refsDest         []*github.Reference
+		releasesSrc      []*github.RepositoryRelease
 		expectedRefs     []*github.Reference
 		methodErrorsSrc  map[string]bool
 		methodErrorsDest map[string]bool
@@ -151,6 +152,28 @@ func TestProcess(t *testing.T) {
 			methodErrorsDest: map[string]bool{http.MethodGet: true},
 			expectedError:    true,
 		},
+		{
+			name: "valid: only tags with a published release in the source",
+			data: &pkg.Data{MinVersion: "v1.17.0", RequireSourceRelease: true},
+			refsSrc: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.17.1"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.2"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.3"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+			},
+			refsDest: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("0000")}},
+			},
+			releasesSrc: []*github.RepositoryRelease{
+				{TagName: github.String("v1.17.1")},
+				{TagName: github.String("v1.17.2"), Draft: github.Bool(true)},
+			},
+			expectedRefs: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("0000")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.1"), Object: &github.GitObject{SHA: github.String("0000")}},
+			},
+		},
 		{
 			name: "invalid: cannot post refs to destination repo",
 			data: &pkg.Data{MinVersion: "v1.17.0"},
@@ -177,10 +200,10 @@ func TestProcess(t *testing.T) {
 				}
 
 				// Override/hardcode some values.
-				tt.data.Source = "org/src"
-				tt.data.Dest = "org/dest"
+				tt.data.Source = []string{"org/src"}
+				tt.data.Dest = []string{"org/dest"}
 				tt.data.PrefixBranch = pkg.PrefixBranch
-				tt.data.Force = true
+				tt.data.SetPrompter(pkg.PrompterYes)
 				tt.data.DryRun = dryRunVal
 
 				if tt.methodErrorsSrc == nil {
@@ -200,8 +223,10 @@ func TestProcess(t *testing.T) {
 				handlerDest := pkg.NewReferenceHandler(&tt.refsDest, tt.methodErrorsDest)
 				tt.data.Transport.SetHandler(testRefsSrc, handlerSrc)
 				tt.data.Transport.SetHandler(testRefsDest, handlerDest)
+				tt.data.Transport.SetHandler("https://api.github.com/repos/org/src/releases",
+					pkg.NewReleaseHandler(&tt.releasesSrc, nil))
 
-				refs, err := process(tt.data)
+				refs, _, err := process(tt.data, tt.data.SourceRepo(), tt.data.DestRepo())
 				if (err != nil) != tt.expectedError {
 					t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
 				}
@@ -216,3 +241,165 @@ func TestProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessGraphQL(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	refsSrc := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/tags/v1.16.2"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+		&github.Reference{Ref: github.String("refs/tags/v1.17.1"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+		&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+		&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+	}
+	refsDest := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("0000")}},
+	}
+	expected := []string{
+		"refs/heads/release-1.17@0000",
+		"refs/tags/v1.16.2@0000",
+		"refs/tags/v1.17.1@0000",
+	}
+
+	d := &pkg.Data{
+		MinVersion:   "v1.16.1",
+		Source:       []string{"org/src"},
+		Dest:         []string{"org/dest"},
+		PrefixBranch: pkg.PrefixBranch,
+		GraphQL:      true,
+	}
+	d.SetPrompter(pkg.PrompterYes)
+	pkg.NewClient(d, pkg.NewTransport())
+	repos := map[string]*[]*github.Reference{"org/src": &refsSrc, "org/dest": &refsDest}
+	d.Transport.SetHandler("https://api.github.com/graphql", pkg.NewGraphQLRefHandler(repos, nil))
+
+	refs, _, err := process(d, d.SourceRepo(), d.DestRepo())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make([]string, len(refs))
+	for i, ref := range refs {
+		got[i] = ref.GetRef() + "@" + ref.GetObject().GetSHA()
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected refs:\n%v\ngot:\n%v\n", expected, got)
+	}
+	if len(refsDest) != 4 {
+		t.Errorf("expected 4 refs in the destination repository, got %d", len(refsDest))
+	}
+}
+
+func TestProcessDivergent(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name              string
+		forceUpdate       bool
+		expectedRefs      []string
+		expectedDivergent []pkg.DivergentRef
+		expectedDest      []string
+	}{
+		{
+			name:         "valid: report divergent refs",
+			expectedRefs: []string{"refs/tags/v1.17.2@2222"},
+			expectedDivergent: []pkg.DivergentRef{
+				{Ref: "refs/tags/v1.17.1", SourceSHA: "1111", DestSHA: "2222"},
+				{Ref: "refs/heads/release-1.17", SourceSHA: "1111", DestSHA: "2222"},
+			},
+			expectedDest: []string{
+				"refs/heads/master@0000",
+				"refs/heads/release-1.17@2222",
+				"refs/tags/v1.16.2@1111",
+				"refs/tags/v1.17.1@2222",
+				"refs/tags/v1.17.2@2222",
+			},
+		},
+		{
+			name:         "valid: update divergent refs",
+			forceUpdate:  true,
+			expectedRefs: []string{"refs/heads/release-1.17@1111", "refs/tags/v1.17.1@1111", "refs/tags/v1.17.2@2222"},
+			expectedDivergent: []pkg.DivergentRef{
+				{Ref: "refs/tags/v1.17.1", SourceSHA: "1111", DestSHA: "2222", Updated: true},
+				{Ref: "refs/heads/release-1.17", SourceSHA: "1111", DestSHA: "2222", Updated: true},
+			},
+			expectedDest: []string{
+				"refs/heads/master@0000",
+				"refs/heads/release-1.17@1111",
+				"refs/tags/v1.16.2@1111",
+				"refs/tags/v1.17.1@1111",
+				"refs/tags/v1.17.2@2222",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refsSrc := []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.16.2"), Object: &github.GitObject{SHA: github.String("1111")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.1"), Object: &github.GitObject{SHA: github.String("1111")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.2"), Object: &github.GitObject{SHA: github.String("1111")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("1111")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1111")}},
+			}
+			refsDest := []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.16.2"), Object: &github.GitObject{SHA: github.String("1111")}},
+				&github.Reference{Ref: github.String("refs/tags/v1.17.1"), Object: &github.GitObject{SHA: github.String("2222")}},
+				&github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("0000")}},
+				&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("2222")}},
+			}
+			d := &pkg.Data{
+				MinVersion:   "v1.16.1",
+				Source:       []string{"org/src"},
+				Dest:         []string{"org/dest"},
+				PrefixBranch: pkg.PrefixBranch,
+				CompareSHA:   true,
+				ForceUpdate:  tt.forceUpdate,
+			}
+			d.SetPrompter(pkg.PrompterYes)
+			pkg.NewClient(d, pkg.NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/src/git/refs", pkg.NewReferenceHandler(&refsSrc, nil))
+			d.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs", pkg.NewReferenceHandler(&refsDest, nil))
+
+			refs, divergent, err := process(d, d.SourceRepo(), d.DestRepo())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := refStrings(refs); !reflect.DeepEqual(got, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%v\ngot:\n%v", tt.expectedRefs, got)
+			}
+			if !reflect.DeepEqual(divergent, tt.expectedDivergent) {
+				t.Errorf("expected divergent refs:\n%+v\ngot:\n%+v", tt.expectedDivergent, divergent)
+			}
+			if got := refStrings(sortRefs(refsDest)); !reflect.DeepEqual(got, tt.expectedDest) {
+				t.Errorf("expected destination refs:\n%v\ngot:\n%v", tt.expectedDest, got)
+			}
+		})
+	}
+}
+
+// refStrings formats refs as "ref@sha".
+func refStrings(refs []*github.Reference) []string {
+	result := make([]string, len(refs))
+	for i, ref := range refs {
+		result[i] = ref.GetRef() + "@" + ref.GetObject().GetSHA()
+	}
+	return result
+}
+
+func TestCreatedRefs(t *testing.T) {
+	newRefs := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/heads/release-1.16")},
+		&github.Reference{Ref: github.String("refs/heads/release-1.17")},
+	}
+	destRefs := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/heads/master")},
+		&github.Reference{Ref: github.String("refs/heads/release-1.16"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+	}
+	expected := []*github.Reference{destRefs[1]}
+	if refs := createdRefs(newRefs, destRefs); !reflect.DeepEqual(refs, expected) {
+		t.Errorf("expected refs: %v, got: %v", expected, refs)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// shortSHALength is the length of the abbreviated SHAs in the job summary.
+const shortSHALength = 7
+
+// summaryTitle returns the title of the job summary section.
+func summaryTitle(d *pkg.Data) string {
+	title := fmt.Sprintf("Tags and branches written to `%s`", d.DestRepo())
+	if d.DryRun {
+		title += fmt.Sprintf(" (%s)", pkg.PrefixDryRun)
+	}
+	return title
+}
+
+// formatSummary returns the Markdown job summary of the written refs, with a table
+// for the tags and one for the branches. Refs that were updated because they
+// diverged from the source repository are marked as updated.
+func formatSummary(d *pkg.Data, refs []*github.Reference, divergent []pkg.DivergentRef) string {
+	if len(refs) == 0 {
+		return fmt.Sprintf("No new tags and branches for `%s` in `%s`.\n", d.DestRepo(), d.SourceRepo())
+	}
+	updated := map[string]bool{}
+	for _, ref := range divergent {
+		updated[ref.Ref] = ref.Updated
+	}
+
+	var tags, branches []*github.Reference
+	for _, ref := range refs {
+		if strings.HasPrefix(ref.GetRef(), "refs/tags/") {
+			tags = append(tags, ref)
+		} else {
+			branches = append(branches, ref)
+		}
+	}
+
+	var sb strings.Builder
+	writeTable := func(title, column string, refs []*github.Reference) {
+		if len(refs) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "**%s** (%d)\n\n", title, len(refs))
+		fmt.Fprintf(&sb, "| %s | Commit | Change |\n|---|---|---|\n", column)
+		for _, ref := range refs {
+			name := strings.TrimPrefix(strings.TrimPrefix(ref.GetRef(), "refs/tags/"), "refs/heads/")
+			sha := ref.GetObject().GetSHA()
+			change := "created"
+			if updated[ref.GetRef()] {
+				change = "updated"
+			}
+			fmt.Fprintf(&sb, "| [%s](%s) | [`%s`](%s) | %s |\n", name, d.Provider().RefURL(d.DestRepo(), ref.GetRef()),
+				shortSHA(sha), d.Provider().CommitURL(d.DestRepo(), sha), change)
+		}
+		sb.WriteString("\n")
+	}
+	writeTable("Tags", "Tag", tags)
+	writeTable("Branches", "Branch", branches)
+	return sb.String()
+}
+
+// formatConflictsSummary returns the Markdown job summary of the refs that point
+// to different SHAs in the source and destination repositories, with links that
+// compare the two SHAs.
+func formatConflictsSummary(d *pkg.Data, divergent []pkg.DivergentRef) string {
+	var sb strings.Builder
+	sb.WriteString("| Ref | Source | Destination | Compare |\n|---|---|---|---|\n")
+	for _, c := range newOutput(d, nil, divergent).Conflicts {
+		fmt.Fprintf(&sb, "| `%s` | `%s` | `%s` | [compare](%s) |\n", c.Ref,
+			shortSHA(c.SourceSHA), shortSHA(c.DestSHA), c.CompareURL)
+	}
+	return sb.String()
+}
+
+// shortSHA returns the abbreviated form of a SHA.
+func shortSHA(sha string) string {
+	if len(sha) > shortSHALength {
+		return sha[:shortSHALength]
+	}
+	return sha
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestFormatSummary(t *testing.T) {
+	refs := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+		&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("abcdef1234")}},
+	}
+	divergent := []pkg.DivergentRef{
+		{Ref: "refs/heads/release-1.17", SourceSHA: "abcdef1234", DestSHA: "0000000000", Updated: true},
+	}
+
+	tests := []struct {
+		name            string
+		data            *pkg.Data
+		refs            []*github.Reference
+		divergent       []pkg.DivergentRef
+		expectedSummary string
+	}{
+		{
+			name: "valid: tags and branches",
+			data: &pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}},
+			refs: refs,
+			expectedSummary: "**Tags** (1)\n\n" +
+				"| Tag | Commit | Change |\n|---|---|---|\n" +
+				"| [v1.17.0](https://github.com/org/dest/tree/v1.17.0) | [`1234567`](https://github.com/org/dest/commit/1234567890) | created |\n\n" +
+				"**Branches** (1)\n\n" +
+				"| Branch | Commit | Change |\n|---|---|---|\n" +
+				"| [release-1.17](https://github.com/org/dest/tree/release-1.17) | [`abcdef1`](https://github.com/org/dest/commit/abcdef1234) | created |\n\n",
+		},
+		{
+			name:      "valid: updated divergent branch on GitHub Enterprise Server",
+			data:      &pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}, GitHubBaseURL: "https://ghe.example.com/api/v3/"},
+			refs:      refs[1:],
+			divergent: divergent,
+			expectedSummary: "**Branches** (1)\n\n" +
+				"| Branch | Commit | Change |\n|---|---|---|\n" +
+				"| [release-1.17](https://ghe.example.com/org/dest/tree/release-1.17) | [`abcdef1`](https://ghe.example.com/org/dest/commit/abcdef1234) | updated |\n\n",
+		},
+		{
+			name:            "valid: no refs",
+			data:            &pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}},
+			expectedSummary: "No new tags and branches for `org/dest` in `org/src`.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := formatSummary(tt.data, tt.refs, tt.divergent)
+			if summary != tt.expectedSummary {
+				t.Errorf("expected summary:\n%s\ngot:\n%s", tt.expectedSummary, summary)
+			}
+		})
+	}
+}
+
+func TestFormatConflictsSummary(t *testing.T) {
+	d := &pkg.Data{Source: []string{"org/src"}, Dest: []string{"org/dest"}}
+	divergent := []pkg.DivergentRef{
+		{Ref: "refs/tags/v1.17.0", SourceSHA: "1234567890", DestSHA: "abcdef1234"},
+	}
+
+	expectedSummary := "| Ref | Source | Destination | Compare |\n|---|---|---|---|\n" +
+		"| `refs/tags/v1.17.0` | `1234567` | `abcdef1` | [compare](https://github.com/org/dest/compare/abcdef1234...org:src:1234567890) |\n"
+	if summary := formatConflictsSummary(d, divergent); summary != expectedSummary {
+		t.Errorf("expected summary:\n%s\ngot:\n%s", expectedSummary, summary)
+	}
+}
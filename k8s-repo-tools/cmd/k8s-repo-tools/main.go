@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	assetdownload "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-asset-download/app"
+	branchcleanup "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-branch-cleanup/app"
+	branchcreate "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-branch-create/app"
+	changelog "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-changelog/app"
+	cherrypick "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-cherry-pick/app"
+	createrelease "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-create-release/app"
+	ffstatus "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-ff-status/app"
+	gomoddiff "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-gomod-diff/app"
+	gomodpincheck "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-gomod-pin-check/app"
+	issuemirror "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-issue-mirror/app"
+	labelsync "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-label-sync/app"
+	latestversion "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-latest-version/app"
+	milestonesync "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-milestone-sync/app"
+	notify "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-notify/app"
+	ownersdiff "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-owners-diff/app"
+	prautobump "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-pr-autobump/app"
+	releasedashboard "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-release-dashboard/app"
+	releaseverify "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-release-verify/app"
+	repoaudit "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-repo-audit/app"
+	repobackup "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-repo-backup/app"
+	repoff "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-repo-ff/app"
+	reposync "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-repo-sync/app"
+	rerunci "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-rerun-ci/app"
+	tagcreate "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-tag-create/app"
+	versionmatrix "k8s.io/kubeadm/k8s-repo-tools/cmd/k8s-version-matrix/app"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// command is a subcommand that runs one of the tools.
+type command struct {
+	name        string
+	tool        string
+	description string
+	main        func(args []string)
+}
+
+var commands = []command{
+	{"sync", reposync.Name, "Synchronize tags and branches between GitHub repositories", reposync.Main},
+	{"ff", repoff.Name, "Fast-forward a release branch to the master branch", repoff.Main},
+	{"create-release", createrelease.Name, "Create a GitHub release from a tag", createrelease.Main},
+	{"gomod-diff", gomoddiff.Name, "Compare the dependency versions of Go module files", gomoddiff.Main},
+	{"latest-version", latestversion.Name, "Obtain the latest SemVer from a list of tags", latestversion.Main},
+	{"branch-create", branchcreate.Name, "Cut a new release branch from the default branch", branchcreate.Main},
+	{"tag-create", tagcreate.Name, "Create a SemVer tag at a commit", tagcreate.Main},
+	{"changelog", changelog.Name, "Add the release notes for a tag to a CHANGELOG file", changelog.Main},
+	{"cherry-pick", cherrypick.Name, "Cherry-pick a merged pull request on release branches", cherrypick.Main},
+	{"milestone-sync", milestonesync.Name, "Mirror milestones from a source repository to destination repositories", milestonesync.Main},
+	{"label-sync", labelsync.Name, "Synchronize labels from a source repository or a label spec to destination repositories", labelsync.Main},
+	{"release-verify", releaseverify.Name, "Verify the tag, notes and assets of an existing GitHub release", releaseverify.Main},
+	{"branch-cleanup", branchcleanup.Name, "Delete old tags and stale branches according to a retention policy", branchcleanup.Main},
+	{"ff-status", ffstatus.Name, "Report the fast-forward state of release branches", ffstatus.Main},
+	{"repo-backup", repobackup.Name, "Back up and restore the refs, releases and milestones of a repository", repobackup.Main},
+	{"notify", notify.Name, "Send a summary of the output files of other tools to chat services or email", notify.Main},
+	{"version-matrix", versionmatrix.Name, "Compute the supported version skew matrix of components from tags", versionmatrix.Main},
+	{"repo-audit", repoaudit.Name, "Check the settings of repositories against a policy", repoaudit.Main},
+	{"owners-diff", ownersdiff.Name, "Compare the approvers and reviewers in the OWNERS files of two repositories", ownersdiff.Main},
+	{"issue-mirror", issuemirror.Name, "Mirror issues with selected labels from a source repository to a destination repository", issuemirror.Main},
+	{"pr-autobump", prautobump.Name, "Update the version in files to the latest tag of a repository with a pull request", prautobump.Main},
+	{"dashboard", releasedashboard.Name, "Render a static dashboard with the release state of repositories", releasedashboard.Main},
+	{"pin-check", gomodpincheck.Name, "Verify that go.mod replace pins are reachable from published tags", gomodpincheck.Main},
+	{"rerun-ci", rerunci.Name, "Re-run failed workflow runs and check suites on a branch or pull request", rerunci.Main},
+	{"asset-download", assetdownload.Name, "Download and verify the assets of a release", assetdownload.Main},
+	versionCommand,
+}
+
+// name is the name of the binary.
+const name = "k8s-repo-tools"
+
+var versionCommand = command{"version", "", "Print the version and exit", func([]string) {
+	pkg.PrintVersion(os.Stdout, name)
+}}
+
+func printUsage() {
+	out := os.Stderr
+	fmt.Fprintln(out, "k8s-repo-tools is a single binary for all tools in k8s-repo-tools")
+	fmt.Fprintln(out, "\nusage:")
+	fmt.Fprintln(out, "  k8s-repo-tools [global flags] <command> [flags]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, c := range commands {
+		if len(c.tool) == 0 {
+			fmt.Fprintf(out, "  %-16s %s\n", c.name, c.description)
+			continue
+		}
+		fmt.Fprintf(out, "  %-16s %s (%s)\n", c.name, c.description, c.tool)
+	}
+	fmt.Fprintln(out, "\nGlobal flags such as \"-v=1\" or \"-config=path\" are passed to the command and must use the '-flag=value' format.")
+	fmt.Fprintln(out, "Run 'k8s-repo-tools <command> -help' for the flags of a command.")
+}
+
+func main() {
+	cmd, args, err := parseArgs(os.Args[1:])
+	if err != nil {
+		printUsage()
+		pkg.PrintErrorAndExit(err)
+	}
+	if cmd == nil {
+		printUsage()
+		return
+	}
+	if len(cmd.tool) != 0 {
+		pkg.SetDefaultLogger(pkg.NewLogger().WithTool(cmd.tool))
+	}
+	cmd.main(args)
+	pkg.FinishRun(0, nil)
+}
+
+// parseArgs finds the command in a list of arguments and returns it with
+// the arguments for the command. Global flags that are passed before the command
+// are prepended to the arguments of the command. A nil command is returned
+// if help is requested.
+func parseArgs(args []string) (*command, []string, error) {
+	var global []string
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			switch strings.TrimLeft(arg, "-") {
+			case "h", "help":
+				return nil, nil, nil
+			case pkg.FlagVersion:
+				return &versionCommand, nil, nil
+			}
+			if !strings.Contains(arg, "=") && !isBoolFlag(arg) {
+				return nil, nil, errors.Errorf("global flag %q must use the '-flag=value' format", arg)
+			}
+			global = append(global, arg)
+			continue
+		}
+		if arg == "help" {
+			return nil, nil, nil
+		}
+		for j := range commands {
+			if commands[j].name == arg {
+				return &commands[j], append(global, args[i+1:]...), nil
+			}
+		}
+		return nil, nil, errors.Errorf("unknown command %q", arg)
+	}
+	return nil, nil, errors.New("missing command")
+}
+
+// isBoolFlag returns true if a flag without a value is a known boolean global flag.
+func isBoolFlag(arg string) bool {
+	return strings.TrimLeft(arg, "-") == pkg.FlagQuiet
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		expectedCommand string
+		expectedArgs    []string
+		expectedError   bool
+	}{
+		{
+			name:            "valid: command with flags",
+			args:            []string{"sync", "-dest=org/repo", "-dry-run=false"},
+			expectedCommand: "sync",
+			expectedArgs:    []string{"-dest=org/repo", "-dry-run=false"},
+		},
+		{
+			name:            "valid: global flags are prepended to the command flags",
+			args:            []string{"-v=1", "--quiet", "latest-version", "-branch=release-1.17"},
+			expectedCommand: "latest-version",
+			expectedArgs:    []string{"-v=1", "--quiet", "-branch=release-1.17"},
+		},
+		{
+			name:            "valid: command without flags",
+			args:            []string{"create-release"},
+			expectedCommand: "create-release",
+		},
+		{
+			name:            "valid: version flag",
+			args:            []string{"--version"},
+			expectedCommand: "version",
+		},
+		{
+			name:            "valid: version command",
+			args:            []string{"version"},
+			expectedCommand: "version",
+		},
+		{
+			name: "valid: help",
+			args: []string{"-help"},
+		},
+		{
+			name: "valid: help command",
+			args: []string{"-v=1", "help"},
+		},
+		{
+			name:          "invalid: missing command",
+			args:          []string{"-v=1"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown command",
+			args:          []string{"foo"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: global flag without a value",
+			args:          []string{"-v", "1", "ff"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args, err := parseArgs(tt.args)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			var name string
+			if cmd != nil {
+				name = cmd.name
+			}
+			if name != tt.expectedCommand {
+				t.Errorf("expected command %q, got %q", tt.expectedCommand, name)
+			}
+			if cmd != nil && !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
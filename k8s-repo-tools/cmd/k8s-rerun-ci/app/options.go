@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool. The workflow runs to re-run are
+// selected with the branches or with the pull request.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest              []string        `flag:"dest" usage:"Comma separated list of org/repo in which to re-run failed workflow runs and check suites" validate:"required,repos"`
+	Branches          pkg.MultiString `flag:"branch" usage:"Branch on which to re-run failed workflow runs and check suites. Multiple instances of the flag are allowed" validate:"requiredunless=pull-request-number,noempty"`
+	PullRequestNumber int             `flag:"pull-request-number" usage:"Number of a pull request on whose head commit to re-run failed workflow runs and check suites" validate:"nonnegative"`
+	Workflows         pkg.MultiString `flag:"workflow" usage:"Name of a GitHub Actions workflow or a GitHub App of a check suite to re-run. Multiple instances of the flag are allowed. Defaults to all"`
+	MaxAge            time.Duration   `flag:"max-age" usage:"Only re-run workflow runs that were created within this duration (e.g. '24h'). 0 re-runs all" validate:"nonnegative"`
+	Output            string          `flag:"output" usage:"Path to a file that will be written with the re-run workflow runs and check suites"`
+	Parallelism       int             `flag:"parallelism" default:"1" usage:"Number of repositories to process in parallel. With a value greater than 1 a failing repository does not stop the others" validate:"nonnegative"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	// Validate the options.
+	if err := pkg.ValidateOptions(d, &options{}); err != nil {
+		return err
+	}
+
+	// Validate the branches and the pull request.
+	if len(d.Branches) != 0 && d.PullRequestNumber != 0 {
+		return errors.Errorf("the options %q and %q cannot be used together", pkg.FlagBranch, pkg.FlagPullRequestNumber)
+	}
+	if d.PullRequestNumber != 0 && len(d.Dest) != 1 {
+		return errors.Errorf("the option %q requires a single repository in %q", pkg.FlagPullRequestNumber, pkg.FlagDest)
+	}
+
+	return nil
+}
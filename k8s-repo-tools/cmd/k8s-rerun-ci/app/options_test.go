@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestValidateData(t *testing.T) {
+	const validToken = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		expectedError bool
+	}{
+		{
+			name: "valid: branches in multiple repositories",
+			data: &pkg.Data{
+				Token:    validToken,
+				Dest:     []string{"org/a", "org/b"},
+				Branches: []string{"master", "release-1.17"},
+				MaxAge:   24 * time.Hour,
+			},
+		},
+		{
+			name: "valid: pull request",
+			data: &pkg.Data{
+				Token:             validToken,
+				Dest:              []string{"org/a"},
+				PullRequestNumber: 7,
+			},
+		},
+		{
+			name: "invalid: missing branch and pull request",
+			data: &pkg.Data{
+				Token: validToken,
+				Dest:  []string{"org/a"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: branch and pull request",
+			data: &pkg.Data{
+				Token:             validToken,
+				Dest:              []string{"org/a"},
+				Branches:          []string{"master"},
+				PullRequestNumber: 7,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: pull request in multiple repositories",
+			data: &pkg.Data{
+				Token:             validToken,
+				Dest:              []string{"org/a", "org/b"},
+				PullRequestNumber: 7,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: malformed repository",
+			data: &pkg.Data{
+				Token:    validToken,
+				Dest:     []string{"org"},
+				Branches: []string{"master"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: negative maximum age",
+			data: &pkg.Data{
+				Token:    validToken,
+				Dest:     []string{"org/a"},
+				Branches: []string{"master"},
+				MaxAge:   -time.Hour,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: plan without dry-run",
+			data: &pkg.Data{
+				Token:    validToken,
+				Dest:     []string{"org/a"},
+				Branches: []string{"master"},
+				PlanFile: "plan.json",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateData(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
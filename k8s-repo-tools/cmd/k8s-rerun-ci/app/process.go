@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+const (
+	kindWorkflowRun = "workflowRun"
+	kindCheckSuite  = "checkSuite"
+
+	// actionsAppSlug is the slug of the GitHub App that creates the check suites
+	// of workflow runs. These suites are re-run as workflow runs.
+	actionsAppSlug = "github-actions"
+)
+
+// failedConclusions are the conclusions of workflow runs and check suites that are re-run.
+var failedConclusions = map[string]bool{
+	"failure":   true,
+	"timed_out": true,
+}
+
+// now is used to find the workflow runs within the maximum age. It can be replaced in tests.
+var now = time.Now
+
+// rerun is a workflow run or a check suite that is re-run.
+type rerun struct {
+	Repo   string `json:"repo"`
+	Kind   string `json:"kind"`
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Branch string `json:"branch,omitempty"`
+	SHA    string `json:"sha"`
+	URL    string `json:"url,omitempty"`
+}
+
+// result holds the workflow runs and check suites that were re-run.
+type result struct {
+	Reruns []*rerun `json:"reruns"`
+}
+
+// target is a branch or the head of a pull request on which to look for failures.
+type target struct {
+	branch string
+	// sha is only set for a pull request. Only the runs for this commit are considered.
+	sha string
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*result, error) {
+	var since time.Time
+	if d.MaxAge != 0 {
+		since = now().Add(-d.MaxAge)
+	}
+
+	// Find the failed workflow runs and check suites in all repositories.
+	repos := d.Dest
+	repoReruns := make([][]*rerun, len(repos))
+	err := pkg.ForEachRepo(d, repos, func(i int, repo string, log *pkg.Logger) error {
+		targets, err := getTargets(d, repo)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			if d.Interrupted() {
+				return pkg.ErrInterrupted
+			}
+			runs, err := findFailedWorkflowRuns(d, repo, t, since, log)
+			if err != nil {
+				return err
+			}
+			suites, err := findFailedCheckSuites(d, repo, t, log)
+			if err != nil {
+				return err
+			}
+			repoReruns[i] = append(repoReruns[i], runs...)
+			repoReruns[i] = append(repoReruns[i], suites...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	reruns := []*rerun{}
+	for _, r := range repoReruns {
+		reruns = append(reruns, r...)
+	}
+
+	res := &result{Reruns: []*rerun{}}
+	if len(reruns) == 0 {
+		pkg.Logf("no failed workflow runs or check suites found")
+		return res, nil
+	}
+	pkg.Logf("found %d failed workflow run(s) and check suite(s) to re-run", len(reruns))
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to re-run %d failed workflow run(s) and check suite(s)?", len(reruns))
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, err
+	}
+	if !yes {
+		return res, nil
+	}
+
+	for _, r := range reruns {
+		if d.Interrupted() {
+			return res, pkg.ErrInterrupted
+		}
+		var err error
+		if r.Kind == kindWorkflowRun {
+			err = pkg.GitHubRerunWorkflowRun(d, r.Repo, r.ID, d.DryRun)
+		} else {
+			err = pkg.GitHubRerequestCheckSuite(d, r.Repo, r.ID, d.DryRun)
+		}
+		if err != nil {
+			return res, err
+		}
+		res.Reruns = append(res.Reruns, r)
+	}
+	return res, nil
+}
+
+// getTargets returns the branches of a repository on which to look for failures.
+// For a pull request it returns the head branch and the head commit.
+func getTargets(d *pkg.Data, repo string) ([]target, error) {
+	if d.PullRequestNumber == 0 {
+		targets := []target{}
+		for _, b := range d.Branches {
+			targets = append(targets, target{branch: b})
+		}
+		return targets, nil
+	}
+	pr, err := pkg.GitHubGetPullRequest(d, repo, d.PullRequestNumber)
+	if err != nil {
+		return nil, err
+	}
+	return []target{{branch: pr.GetHead().GetRef(), sha: pr.GetHead().GetSHA()}}, nil
+}
+
+// findFailedWorkflowRuns returns the failed workflow runs for a target.
+// Only the latest run of each workflow is considered, as older failures
+// were already superseded by a newer run.
+func findFailedWorkflowRuns(d *pkg.Data, repo string, t target, since time.Time, log *pkg.Logger) ([]*rerun, error) {
+	runs, err := pkg.GitHubGetWorkflowRuns(d, repo, t.branch, since)
+	if err != nil {
+		return nil, err
+	}
+	result := []*rerun{}
+	seen := map[int64]bool{}
+	for _, run := range runs {
+		if len(t.sha) != 0 && run.HeadSHA != t.sha {
+			continue
+		}
+		// The runs are sorted from the newest to the oldest.
+		if seen[run.WorkflowID] {
+			continue
+		}
+		seen[run.WorkflowID] = true
+		if !failedConclusions[run.Conclusion] || !matchesWorkflow(d.Workflows, run.Name) {
+			continue
+		}
+		log.V(1).Logf("workflow run %d of %q failed with conclusion %q", run.ID, run.Name, run.Conclusion)
+		result = append(result, &rerun{
+			Repo:   repo,
+			Kind:   kindWorkflowRun,
+			ID:     run.ID,
+			Name:   run.Name,
+			Branch: run.HeadBranch,
+			SHA:    run.HeadSHA,
+			URL:    run.HTMLURL,
+		})
+	}
+	return result, nil
+}
+
+// findFailedCheckSuites returns the failed check suites for the head commit of a target.
+// The check suites of GitHub Actions are skipped, as they are handled as workflow runs.
+func findFailedCheckSuites(d *pkg.Data, repo string, t target, log *pkg.Logger) ([]*rerun, error) {
+	ref := t.sha
+	if len(ref) == 0 {
+		ref = t.branch
+	}
+	suites, err := pkg.GitHubGetCheckSuites(d, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	result := []*rerun{}
+	for _, suite := range suites {
+		app := suite.GetApp()
+		if app.GetSlug() == actionsAppSlug || !failedConclusions[suite.GetConclusion()] {
+			continue
+		}
+		if !matchesWorkflow(d.Workflows, app.GetName()) && !matchesWorkflow(d.Workflows, app.GetSlug()) {
+			continue
+		}
+		log.V(1).Logf("check suite %d of %q failed with conclusion %q", suite.GetID(), app.GetName(), suite.GetConclusion())
+		result = append(result, &rerun{
+			Repo:   repo,
+			Kind:   kindCheckSuite,
+			ID:     suite.GetID(),
+			Name:   app.GetName(),
+			Branch: suite.GetHeadBranch(),
+			SHA:    suite.GetHeadSHA(),
+		})
+	}
+	return result, nil
+}
+
+// matchesWorkflow returns true if the list of workflows is empty or contains name.
+func matchesWorkflow(workflows []string, name string) bool {
+	if len(workflows) == 0 {
+		return true
+	}
+	for _, w := range workflows {
+		if w == name {
+			return true
+		}
+	}
+	return false
+}
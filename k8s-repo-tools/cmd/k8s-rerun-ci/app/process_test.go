@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	fixedNow := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixedNow }
+	defer func() { now = time.Now }()
+
+	// The runs are sorted from the newest to the oldest, like in the GitHub API.
+	newRuns := func() []*pkg.WorkflowRun {
+		return []*pkg.WorkflowRun{
+			{ID: 6, Name: "build", WorkflowID: 1, HeadBranch: "feature", HeadSHA: "sha3", Status: "completed", Conclusion: "failure", CreatedAt: fixedNow.Add(-time.Hour)},
+			{ID: 5, Name: "build", WorkflowID: 1, HeadBranch: "master", HeadSHA: "sha2", Status: "completed", Conclusion: "failure", CreatedAt: fixedNow.Add(-2 * time.Hour)},
+			{ID: 4, Name: "test", WorkflowID: 2, HeadBranch: "master", HeadSHA: "sha2", Status: "completed", Conclusion: "success", CreatedAt: fixedNow.Add(-2 * time.Hour)},
+			{ID: 3, Name: "build", WorkflowID: 1, HeadBranch: "master", HeadSHA: "sha1", Status: "completed", Conclusion: "failure", CreatedAt: fixedNow.Add(-3 * time.Hour)},
+			{ID: 2, Name: "lint", WorkflowID: 3, HeadBranch: "master", HeadSHA: "sha1", Status: "completed", Conclusion: "timed_out", CreatedAt: fixedNow.Add(-48 * time.Hour)},
+		}
+	}
+	newSuite := func(id int64, app, branch, sha, conclusion string) *github.CheckSuite {
+		return &github.CheckSuite{
+			ID:         github.Int64(id),
+			HeadBranch: github.String(branch),
+			HeadSHA:    github.String(sha),
+			Status:     github.String("completed"),
+			Conclusion: github.String(conclusion),
+			App:        &github.App{Name: github.String(app), Slug: github.String(app)},
+		}
+	}
+	newSuites := func() []*github.CheckSuite {
+		return []*github.CheckSuite{
+			newSuite(10, actionsAppSlug, "master", "sha2", "failure"),
+			newSuite(11, "prow", "master", "sha2", "failure"),
+			newSuite(12, "codecov", "master", "sha2", "success"),
+			newSuite(13, "prow", "feature", "sha3", "failure"),
+		}
+	}
+	prs := []*github.PullRequest{
+		{
+			Number: github.Int(7),
+			Head:   &github.PullRequestBranch{Ref: github.String("feature"), SHA: github.String("sha3")},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		branches          []string
+		pullRequestNumber int
+		workflows         []string
+		maxAge            time.Duration
+		methodErrors      map[string]bool
+		expectedReruns    []*rerun
+		expectedError     bool
+		skipDryRun        bool
+	}{
+		{
+			name:     "valid: re-run the latest failed run of each workflow and failed check suites",
+			branches: []string{"master"},
+			expectedReruns: []*rerun{
+				{Repo: "org/repo", Kind: kindWorkflowRun, ID: 5, Name: "build", Branch: "master", SHA: "sha2"},
+				{Repo: "org/repo", Kind: kindWorkflowRun, ID: 2, Name: "lint", Branch: "master", SHA: "sha1"},
+				{Repo: "org/repo", Kind: kindCheckSuite, ID: 11, Name: "prow", Branch: "master", SHA: "sha2"},
+			},
+		},
+		{
+			name:      "valid: filter by workflow and age",
+			branches:  []string{"master"},
+			workflows: []string{"build", "lint"},
+			maxAge:    24 * time.Hour,
+			expectedReruns: []*rerun{
+				{Repo: "org/repo", Kind: kindWorkflowRun, ID: 5, Name: "build", Branch: "master", SHA: "sha2"},
+			},
+		},
+		{
+			name:              "valid: re-run the failures of a pull request",
+			pullRequestNumber: 7,
+			expectedReruns: []*rerun{
+				{Repo: "org/repo", Kind: kindWorkflowRun, ID: 6, Name: "build", Branch: "feature", SHA: "sha3"},
+				{Repo: "org/repo", Kind: kindCheckSuite, ID: 13, Name: "prow", Branch: "feature", SHA: "sha3"},
+			},
+		},
+		{
+			name:           "valid: no failures on a branch",
+			branches:       []string{"release-1.17"},
+			expectedReruns: []*rerun{},
+		},
+		{
+			name:              "invalid: missing pull request",
+			pullRequestNumber: 8,
+			expectedError:     true,
+		},
+		{
+			name:          "invalid: cannot re-run",
+			branches:      []string{"master"},
+			methodErrors:  map[string]bool{http.MethodPost: true},
+			expectedError: true,
+			skipDryRun:    true,
+		},
+	}
+
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{
+					Dest:              []string{"org/repo"},
+					Branches:          tt.branches,
+					PullRequestNumber: tt.pullRequestNumber,
+					Workflows:         tt.workflows,
+					MaxAge:            tt.maxAge,
+					DryRun:            dryRunVal,
+				}
+				data.SetPrompter(pkg.PrompterYes)
+
+				// Create fake client and setup endpoint handlers.
+				runs, suites := newRuns(), newSuites()
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/repo/actions/runs",
+					pkg.NewWorkflowRunHandler(&runs, tt.methodErrors))
+				data.Transport.SetHandler("https://api.github.com/repos/org/repo/commits",
+					pkg.NewCheckSuiteHandler(&suites, tt.methodErrors))
+				data.Transport.SetHandler("https://api.github.com/repos/org/repo/check-suites",
+					pkg.NewCheckSuiteHandler(&suites, tt.methodErrors))
+				data.Transport.SetHandler("https://api.github.com/repos/org/repo/pulls",
+					pkg.NewPullRequestHandler(&prs, nil))
+
+				res, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if !reflect.DeepEqual(res.Reruns, tt.expectedReruns) {
+					t.Errorf("expected reruns:\n%+v\ngot:\n%+v", tt.expectedReruns, res.Reruns)
+				}
+
+				// Nothing is re-run in dry-run mode.
+				queued := []int64{}
+				for _, run := range runs {
+					if run.Status == "queued" {
+						queued = append(queued, run.ID)
+					}
+				}
+				for _, suite := range suites {
+					if suite.GetStatus() == "queued" {
+						queued = append(queued, suite.GetID())
+					}
+				}
+				expectedQueued := []int64{}
+				if !dryRunVal {
+					for _, r := range tt.expectedReruns {
+						expectedQueued = append(expectedQueued, r.ID)
+					}
+				}
+				if !reflect.DeepEqual(queued, expectedQueued) {
+					t.Errorf("expected queued IDs %v, got %v", expectedQueued, queued)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// options are the flags of the tool.
+type options struct {
+	pkg.TokenOptions
+	pkg.TimeoutOptions
+	pkg.DryRunOptions
+	pkg.ForceOptions
+	Dest         []string `flag:"dest" usage:"Destination org/repo to write tags and branches to" validate:"required,repo"`
+	ReleaseTag   string   `flag:"release-tag" usage:"A SemVer tag from which to create a release" validate:"required,semver"`
+	SHA          string   `flag:"sha" usage:"Full SHA of the commit to use. Defaults to the HEAD of the target branch" validate:"sha"`
+	TargetBranch string   `flag:"target-branch" usage:"Name of the branch on which the commit must be reachable. Defaults to the versioned branch that matches the MAJOR.MINOR of \"release-tag\" or \"master\""`
+	PrefixBranch string   `flag:"branch-prefix" default:"release-" usage:"Branch name prefix. Expected format is \"prefixMAJOR.MINOR\""`
+	Output       string   `flag:"output" usage:"Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects"`
+}
+
+// validateData validates the user input.
+func validateData(d *pkg.Data) error {
+	pkg.Logf("validating user input...")
+
+	// Validate the plan options.
+	if err := pkg.ValidatePlanOptions(d); err != nil {
+		return err
+	}
+
+	return pkg.ValidateOptions(d, &options{})
+}
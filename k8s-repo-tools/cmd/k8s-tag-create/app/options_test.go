@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestValidateData(t *testing.T) {
+	const validToken = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		data          *pkg.Data
+		expectedError bool
+	}{
+		{
+			name: "valid: all fields are valid",
+			data: &pkg.Data{
+				Token:      validToken,
+				Dest:       []string{"org/dest"},
+				ReleaseTag: "v1.17.1",
+			},
+		},
+		{
+			name: "valid: full SHA",
+			data: &pkg.Data{
+				Token:      validToken,
+				Dest:       []string{"org/dest"},
+				ReleaseTag: "v1.17.1",
+				SHA:        "b04b9fb3987b12045ac5b2b273f1b5b3a8a7c972",
+			},
+		},
+		{
+			name: "invalid: empty string arguments",
+			data: &pkg.Data{
+				Token: validToken,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: tag is not SemVer",
+			data: &pkg.Data{
+				Token:      validToken,
+				Dest:       []string{"org/dest"},
+				ReleaseTag: "foo",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: short SHA",
+			data: &pkg.Data{
+				Token:      validToken,
+				Dest:       []string{"org/dest"},
+				ReleaseTag: "v1.17.1",
+				SHA:        "b04b9fb",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateData(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
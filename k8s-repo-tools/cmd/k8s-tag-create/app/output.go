@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package app
 
 import (
 	"encoding/json"
@@ -24,14 +24,21 @@ import (
 	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
-// formatRefOutput marshals a list of Reference objects.
-func formatOutput(refs []*github.Reference, indent bool) ([]byte, error) {
+// output is the output structure.
+type output struct {
+	OutputError *string           `json:"outputError"`
+	Tag         *github.Reference `json:"tag"`
+	Branch      *github.Reference `json:"branch"`
+}
+
+// formatOutput marshals the output to JSON.
+func formatOutput(out *output, indent bool) ([]byte, error) {
 	var buf []byte
 	var err error
 	if indent {
-		buf, err = json.MarshalIndent(refs, "", "\t")
+		buf, err = json.MarshalIndent(out, "", "\t")
 	} else {
-		buf, err = json.Marshal(refs)
+		buf, err = json.Marshal(out)
 	}
 	if err != nil {
 		return nil, err
@@ -39,15 +46,23 @@ func formatOutput(refs []*github.Reference, indent bool) ([]byte, error) {
 	return buf, nil
 }
 
-// writeOutputToFile writes the list of Reference objects to the
-// given filePath.
-func writeOutputToFile(filePath string, refs []*github.Reference) error {
-	buf, err := formatOutput(refs, true)
+// writeOutputToFile writes the output to the given filePath.
+func writeOutputToFile(filePath string, tag, branch *github.Reference, outputError error) error {
+	var errorStr *string
+	if outputError != nil {
+		errorStr = github.String(outputError.Error())
+	}
+	out := &output{
+		OutputError: errorStr,
+		Tag:         tag,
+		Branch:      branch,
+	}
+	buf, err := formatOutput(out, true)
 	if err != nil {
 		return err
 	}
 
-	pkg.Logf("writing the resulted tags and branches to the file %q", filePath)
+	pkg.Logf("writing the resulted output to the file %q", filePath)
 	if err := ioutil.WriteFile(filePath, buf, 0600); err != nil {
 		return err
 	}
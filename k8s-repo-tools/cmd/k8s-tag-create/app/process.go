@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// process is responsible for all operations that the application performs.
+// It returns the new tag and the branch on which the tagged commit is reachable.
+func process(d *pkg.Data) (*github.Reference, *github.Reference, error) {
+	tagRef := "refs/tags/" + d.ReleaseTag
+
+	// Obtain destination repository tags and branches.
+	tagsDest, err := pkg.GitHubGetTags(d, d.DestRepo())
+	if err != nil {
+		return nil, nil, err
+	}
+	branchesDest, err := pkg.GitHubGetBranches(d, d.DestRepo())
+	if err != nil {
+		return nil, nil, err
+	}
+	pkg.LogRefList("existing tags", d.DestRepo(), tagsDest)
+	pkg.LogRefList("existing branches", d.DestRepo(), branchesDest)
+
+	// The tag must not exist.
+	for _, ref := range tagsDest {
+		if ref.GetRef() == tagRef {
+			return nil, nil, pkg.NewErrorf(pkg.ErrorKindConflict, "the tag %q already exists in repository %q", tagRef, d.DestRepo())
+		}
+	}
+
+	// Find the branch on which the commit must be reachable.
+	branch, err := findTargetBranch(d, tagRef, branchesDest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Use the HEAD of the branch or verify that the commit is reachable on the branch.
+	sha := d.SHA
+	if len(sha) == 0 {
+		sha = branch.GetObject().GetSHA()
+		pkg.Logf("using the HEAD %q of branch %q", sha, branch.GetRef())
+	} else if sha != branch.GetObject().GetSHA() {
+		cmp, err := pkg.GitHubCompareBranches(d, d.DestRepo(), branch.GetRef(), sha)
+		if err != nil {
+			return nil, nil, err
+		}
+		// If the commit is reachable on the branch, the commit is behind the branch.
+		switch cmp.GetStatus() {
+		case "behind", "identical":
+			pkg.Logf("commit %q is reachable on branch %q", sha, branch.GetRef())
+		default:
+			return nil, nil, errors.Errorf("commit %q is not reachable on branch %q; comparison status is %q",
+				sha, branch.GetRef(), cmp.GetStatus())
+		}
+	}
+
+	// Prompt the user.
+	promptMessage := fmt.Sprintf("Do you want to create tag %q from commit %q in repository %q?", tagRef, sha, d.DestRepo())
+	yes, err := d.Prompter().Confirm(promptMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !yes {
+		return nil, branch, nil
+	}
+
+	// Create the tag.
+	tag, err := pkg.GitHubCreateRef(d, d.DestRepo(), tagRef, sha, d.DryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tag, branch, nil
+}
+
+// findTargetBranch returns the branch from d.TargetBranch. If d.TargetBranch is empty it
+// returns the versioned branch that matches the MAJOR.MINOR of the tag or the master branch.
+func findTargetBranch(d *pkg.Data, tagRef string, branches []*github.Reference) (*github.Reference, error) {
+	name := d.TargetBranch
+	if len(name) == 0 {
+		tag := &github.Reference{Ref: github.String(tagRef)}
+		if branch := pkg.FindBranchForTag(tag, d.PrefixBranch, branches); branch != nil {
+			pkg.Logf("found matching branch %q for tag %q", branch.GetRef(), tagRef)
+			return branch, nil
+		}
+		name = pkg.BranchMaster
+	}
+	for _, branch := range branches {
+		if branch.GetRef() == "refs/heads/"+name {
+			pkg.Logf("using branch %q for tag %q", branch.GetRef(), tagRef)
+			return branch, nil
+		}
+	}
+	return nil, errors.Errorf("could not find branch %q in repository %q", name, d.DestRepo())
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	const (
+		shaMaster = "1111111111111111111111111111111111111111"
+		shaBranch = "2222222222222222222222222222222222222222"
+		shaOther  = "3333333333333333333333333333333333333333"
+	)
+	newRef := func(ref, sha string) *github.Reference {
+		return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+	}
+	commit := func(sha string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha)}
+	}
+	refsDest := []*github.Reference{
+		newRef("refs/heads/master", shaMaster),
+		newRef("refs/heads/release-1.17", shaBranch),
+		newRef("refs/tags/v1.17.0", shaBranch),
+	}
+
+	tests := []struct {
+		name                string
+		releaseTag          string
+		sha                 string
+		targetBranch        string
+		commitsBranch       []*github.RepositoryCommit
+		commitsHead         []*github.RepositoryCommit
+		methodErrorsRef     map[string]bool
+		methodErrorsCompare map[string]bool
+		expectedTag         *github.Reference
+		expectedBranch      *github.Reference
+		expectedError       bool
+		expectedErrorKind   pkg.ErrorKind
+		skipDryRun          bool
+	}{
+		{
+			name:           "valid: tag the HEAD of the matching release branch",
+			releaseTag:     "v1.17.1",
+			expectedTag:    newRef("refs/tags/v1.17.1", shaBranch),
+			expectedBranch: newRef("refs/heads/release-1.17", shaBranch),
+		},
+		{
+			name:           "valid: tag the HEAD of master if there is no matching release branch",
+			releaseTag:     "v1.18.0-alpha.1",
+			expectedTag:    newRef("refs/tags/v1.18.0-alpha.1", shaMaster),
+			expectedBranch: newRef("refs/heads/master", shaMaster),
+		},
+		{
+			name:           "valid: tag a commit that is reachable on the target branch",
+			releaseTag:     "v1.18.0-alpha.1",
+			sha:            shaOther,
+			targetBranch:   "master",
+			commitsBranch:  []*github.RepositoryCommit{commit(shaOther)},
+			commitsHead:    []*github.RepositoryCommit{commit(shaOther), commit(shaMaster)},
+			expectedTag:    newRef("refs/tags/v1.18.0-alpha.1", shaOther),
+			expectedBranch: newRef("refs/heads/master", shaMaster),
+		},
+		{
+			name:          "invalid: the commit is not reachable on the target branch",
+			releaseTag:    "v1.17.1",
+			sha:           shaOther,
+			commitsBranch: []*github.RepositoryCommit{commit(shaBranch), commit(shaOther)},
+			commitsHead:   []*github.RepositoryCommit{commit(shaBranch)},
+			expectedError: true,
+		},
+		{
+			name:              "invalid: the tag already exists",
+			releaseTag:        "v1.17.0",
+			expectedError:     true,
+			expectedErrorKind: pkg.ErrorKindConflict,
+		},
+		{
+			name:          "invalid: the target branch does not exist",
+			releaseTag:    "v1.17.1",
+			targetBranch:  "release-1.16",
+			expectedError: true,
+		},
+		{
+			name:                "invalid: return error comparing the commit",
+			releaseTag:          "v1.17.1",
+			sha:                 shaOther,
+			methodErrorsCompare: map[string]bool{http.MethodGet: true},
+			expectedError:       true,
+		},
+		{
+			name:            "invalid: return error creating the tag",
+			releaseTag:      "v1.17.1",
+			methodErrorsRef: map[string]bool{http.MethodPost: true},
+			expectedError:   true,
+			skipDryRun:      true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				// Some operations like POST will always return non-error in dry-run mode.
+				// Skip such tests.
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+
+				data := &pkg.Data{}
+				data.Dest = []string{"org/dest"}
+				data.PrefixBranch = pkg.PrefixBranch
+				data.ReleaseTag = tt.releaseTag
+				data.SHA = tt.sha
+				data.TargetBranch = tt.targetBranch
+				data.SetPrompter(pkg.PrompterYes)
+				data.DryRun = dryRunVal
+
+				refs := append([]*github.Reference{}, refsDest...)
+
+				// Create fake client and setup endpoint handlers.
+				pkg.NewClient(data, pkg.NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs",
+					pkg.NewReferenceHandler(&refs, tt.methodErrorsRef))
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/compare",
+					pkg.NewCompareHandler(&tt.commitsBranch, &tt.commitsHead, tt.methodErrorsCompare))
+
+				tag, branch, err := process(data)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					if kind := pkg.ErrorKindOf(err); len(tt.expectedErrorKind) != 0 && kind != tt.expectedErrorKind {
+						t.Errorf("expected error kind %q, got %q, error: %v", tt.expectedErrorKind, kind, err)
+					}
+					return
+				}
+				if !reflect.DeepEqual(tag, tt.expectedTag) {
+					t.Errorf("expected tag:\n%v\ngot:\n%v", tt.expectedTag, tag)
+				}
+				if !reflect.DeepEqual(branch, tt.expectedBranch) {
+					t.Errorf("expected branch:\n%v\ngot:\n%v", tt.expectedBranch, branch)
+				}
+
+				// In dry-run mode the tag is not created.
+				expectedRefs := refsDest
+				if !dryRunVal {
+					expectedRefs = append(append([]*github.Reference{}, refsDest...), tt.expectedTag)
+				}
+				if !reflect.DeepEqual(refs, expectedRefs) {
+					t.Errorf("expected refs:\n%v\ngot:\n%v", expectedRefs, refs)
+				}
+			})
+		}
+	}
+}
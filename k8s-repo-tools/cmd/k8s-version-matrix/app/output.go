@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// formatOutput formats the matrix in the output format from d.OutputFormat.
+// The text format is a Markdown table, while the JSON format is the matrix object.
+func formatOutput(m *matrix, d *pkg.Data) ([]byte, error) {
+	if d.OutputFormat == pkg.OutputFormatJSON {
+		return json.MarshalIndent(m, "", "\t")
+	}
+
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(m.Components, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("---|", len(m.Components)) + "\n")
+	for _, r := range m.Rows {
+		cells := []string{r.Minor + ".x"}
+		for _, c := range r.Supported {
+			if len(c.Minors) == 0 {
+				cells = append(cells, "-")
+				continue
+			}
+			minors := make([]string, len(c.Minors))
+			for i := range c.Minors {
+				minors[i] = c.Minors[i] + ".x"
+			}
+			cells = append(cells, strings.Join(minors, ", "))
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n")), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestFormatOutput(t *testing.T) {
+	m := &matrix{
+		Components: []string{"kubeadm", "kubelet"},
+		Rows: []*row{
+			{
+				Minor: "v1.17", Version: "v1.17.4",
+				Supported: []*cell{
+					{Component: "kubelet", Minors: []string{"v1.17", "v1.16"}, Versions: []string{"v1.17.4", "v1.16.8"}},
+				},
+			},
+			{
+				Minor: "v1.16", Version: "v1.16.8",
+				Supported: []*cell{
+					{Component: "kubelet", Minors: []string{}, Versions: []string{}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		outputFormat   string
+		matrix         *matrix
+		expectedOutput string
+	}{
+		{
+			name:   "valid: Markdown table",
+			matrix: m,
+			expectedOutput: "| kubeadm | kubelet |\n" +
+				"|---|---|\n" +
+				"| v1.17.x | v1.17.x, v1.16.x |\n" +
+				"| v1.16.x | - |",
+		},
+		{
+			name:         "valid: JSON",
+			outputFormat: pkg.OutputFormatJSON,
+			matrix:       &matrix{Components: m.Components, Rows: m.Rows[1:]},
+			expectedOutput: `{
+	"components": [
+		"kubeadm",
+		"kubelet"
+	],
+	"rows": [
+		{
+			"minor": "v1.16",
+			"version": "v1.16.8",
+			"supported": [
+				{
+					"component": "kubelet",
+					"minors": [],
+					"versions": []
+				}
+			]
+		}
+	]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := formatOutput(tt.matrix, &pkg.Data{OutputFormat: tt.outputFormat})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != tt.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tt.expectedOutput, buf)
+			}
+		})
+	}
+}
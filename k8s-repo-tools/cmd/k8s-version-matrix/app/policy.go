@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// skewPolicy is the structure of the YAML file passed with the
+// --skew-policy flag. The first component is the one for which the
+// supported versions of the other components are computed.
+type skewPolicy struct {
+	Components []component `json:"components"`
+}
+
+// component is a single component of a skew policy. MinSkew and MaxSkew
+// are the offsets of the supported MINOR versions relative to the MINOR
+// version of the first component. For example, MinSkew -1 and MaxSkew 0
+// means that the same MINOR and one MINOR older are supported.
+type component struct {
+	Name    string `json:"name"`
+	Repo    string `json:"repo,omitempty"`
+	MinSkew int    `json:"minSkew"`
+	MaxSkew int    `json:"maxSkew"`
+}
+
+// defaultSkewPolicy returns the kubeadm version skew policy. kubeadm can
+// deploy a control plane and kubelets of the same MINOR version as kubeadm
+// or one MINOR version older.
+func defaultSkewPolicy() *skewPolicy {
+	return &skewPolicy{
+		Components: []component{
+			{Name: "kubeadm"},
+			{Name: "control plane", MinSkew: -1, MaxSkew: 0},
+			{Name: "kubelet", MinSkew: -1, MaxSkew: 0},
+		},
+	}
+}
+
+// parseSkewPolicy parses and validates the contents of a skew policy.
+func parseSkewPolicy(data []byte) (*skewPolicy, error) {
+	p := &skewPolicy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, errors.Wrap(err, "cannot parse skew policy")
+	}
+	if len(p.Components) < 2 {
+		return nil, errors.New("the skew policy must contain at least two components")
+	}
+	seen := map[string]bool{}
+	for i, c := range p.Components {
+		if len(c.Name) == 0 {
+			return nil, errors.Errorf("component %d in the skew policy must have a 'name'", i)
+		}
+		if seen[c.Name] {
+			return nil, errors.Errorf("duplicate component %q in the skew policy", c.Name)
+		}
+		seen[c.Name] = true
+		if c.MinSkew > c.MaxSkew {
+			return nil, errors.Errorf("component %q in the skew policy has a 'minSkew' greater than its 'maxSkew'", c.Name)
+		}
+	}
+	return p, nil
+}
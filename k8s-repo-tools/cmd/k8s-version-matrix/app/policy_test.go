@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSkewPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		expectedPolicy *skewPolicy
+		expectedError  bool
+	}{
+		{
+			name: "valid: two components",
+			policy: `
+components:
+- name: kubeadm
+  repo: kubernetes/kubernetes
+- name: kubelet
+  minSkew: -2
+`,
+			expectedPolicy: &skewPolicy{
+				Components: []component{
+					{Name: "kubeadm", Repo: "kubernetes/kubernetes"},
+					{Name: "kubelet", MinSkew: -2},
+				},
+			},
+		},
+		{
+			name: "invalid: a single component",
+			policy: `
+components:
+- name: kubeadm
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid: duplicate component",
+			policy: `
+components:
+- name: kubeadm
+- name: kubeadm
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid: minimum skew is greater than the maximum skew",
+			policy: `
+components:
+- name: kubeadm
+- name: kubelet
+  minSkew: 1
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid: unknown field",
+			policy: `
+components:
+- name: kubeadm
+- name: kubelet
+  skew: 1
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseSkewPolicy([]byte(tt.policy))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(p, tt.expectedPolicy) {
+				t.Errorf("expected policy:\n%+v\ngot:\n%+v", tt.expectedPolicy, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+// matrix is the version skew matrix. There is a row for every MINOR
+// version of the first component.
+type matrix struct {
+	Components []string `json:"components"`
+	Rows       []*row   `json:"rows"`
+}
+
+// row is the latest version of a MINOR of the first component and the
+// supported versions of the other components.
+type row struct {
+	Minor     string  `json:"minor"`
+	Version   string  `json:"version"`
+	Supported []*cell `json:"supported"`
+}
+
+// cell holds the supported MINOR versions of a component and their latest
+// versions in descending order.
+type cell struct {
+	Component string   `json:"component"`
+	Minors    []string `json:"minors"`
+	Versions  []string `json:"versions"`
+}
+
+// process is responsible for all operations that the application performs.
+func process(d *pkg.Data) (*matrix, error) {
+	minV := version.MustParseSemantic("v0.0.0")
+	if len(d.MinVersion) != 0 {
+		minV = version.MustParseSemantic(d.MinVersion)
+	}
+
+	policy := defaultSkewPolicy()
+	if len(d.SkewPolicy) != 0 {
+		pkg.Logf("reading the skew policy from %q", d.SkewPolicy)
+		data, err := pkg.ReadFromFileOrURL(d.SkewPolicy, d.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if policy, err = parseSkewPolicy(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get the latest stable version of every MINOR for each repository.
+	// Components in the same repository share the tags.
+	minorsByRepo := map[string]map[string]*version.Version{}
+	for _, c := range policy.Components {
+		repo := c.Repo
+		if len(repo) == 0 {
+			repo = d.DestRepo()
+		}
+		if len(repo) == 0 {
+			return nil, errors.Errorf("component %q has no 'repo' and %q is not set", c.Name, pkg.FlagDest)
+		}
+		if _, ok := minorsByRepo[repo]; ok {
+			continue
+		}
+		if d.Interrupted() {
+			return nil, pkg.ErrInterrupted
+		}
+		tags, err := pkg.GitHubGetTags(d, repo)
+		if err != nil {
+			return nil, err
+		}
+		minorsByRepo[repo] = latestMinors(tags)
+	}
+	repoOf := func(c component) string {
+		if len(c.Repo) != 0 {
+			return c.Repo
+		}
+		return d.DestRepo()
+	}
+
+	m := &matrix{Components: []string{}, Rows: []*row{}}
+	for _, c := range policy.Components {
+		m.Components = append(m.Components, c.Name)
+	}
+
+	// Create a row for every MINOR of the first component in descending order.
+	primary := policy.Components[0]
+	primaryMinors := minorsByRepo[repoOf(primary)]
+	versions := make([]*version.Version, 0, len(primaryMinors))
+	for _, v := range primaryMinors {
+		if v.LessThan(minV) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[j].LessThan(versions[i])
+	})
+	for _, v := range versions {
+		r := &row{Minor: minorString(v.Major(), v.Minor()), Version: "v" + v.String(), Supported: []*cell{}}
+		for _, c := range policy.Components[1:] {
+			minors := minorsByRepo[repoOf(c)]
+			cl := &cell{Component: c.Name, Minors: []string{}, Versions: []string{}}
+			for skew := c.MaxSkew; skew >= c.MinSkew; skew-- {
+				minor := int(v.Minor()) + skew
+				if minor < 0 {
+					continue
+				}
+				latest, ok := minors[minorString(v.Major(), uint(minor))]
+				if !ok {
+					continue
+				}
+				cl.Minors = append(cl.Minors, minorString(v.Major(), uint(minor)))
+				cl.Versions = append(cl.Versions, "v"+latest.String())
+			}
+			r.Supported = append(r.Supported, cl)
+		}
+		m.Rows = append(m.Rows, r)
+	}
+	return m, nil
+}
+
+// latestMinors returns the latest stable version of every MAJOR.MINOR
+// from a list of tags. Tags that are not SemVer are skipped.
+func latestMinors(tags []*github.Reference) map[string]*version.Version {
+	result := map[string]*version.Version{}
+	for _, ref := range tags {
+		v, err := pkg.TagRefToVersion(ref)
+		if err != nil {
+			pkg.V(1).Logf("skipping tag: %v", err)
+			continue
+		}
+		if len(v.PreRelease()) != 0 {
+			continue
+		}
+		key := minorString(v.Major(), v.Minor())
+		if latest, ok := result[key]; !ok || latest.LessThan(v) {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// minorString formats a MAJOR.MINOR version with a "v" prefix.
+func minorString(major, minor uint) string {
+	return fmt.Sprintf("v%d.%d", major, minor)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/kubeadm/k8s-repo-tools/pkg"
+)
+
+func TestProcess(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	pkg.SetLogWriters(os.Stdout, os.Stderr)
+	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-version-matrix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The tool is in org/tool and the runtime is in org/runtime.
+	const policy = `
+components:
+- name: tool
+  repo: org/tool
+- name: runtime
+  repo: org/runtime
+  minSkew: -1
+  maxSkew: 1
+`
+	policyPath := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(policyPath, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newRefs := func(tags ...string) []*github.Reference {
+		refs := []*github.Reference{}
+		for _, tag := range tags {
+			refs = append(refs, &github.Reference{Ref: github.String("refs/tags/" + tag), Object: &github.GitObject{SHA: github.String(tag)}})
+		}
+		return refs
+	}
+	refsK8s := newRefs("v1.16.0", "v1.16.8", "v1.17.0", "v1.17.4", "v1.18.0-rc.1", "foo")
+	refsTool := newRefs("v1.16.0", "v1.17.0", "v1.17.1")
+	refsRuntime := newRefs("v1.16.2", "v1.18.0")
+
+	tests := []struct {
+		name           string
+		data           *pkg.Data
+		methodErrors   map[string]bool
+		expectedMatrix *matrix
+		expectedError  bool
+	}{
+		{
+			name: "valid: default kubeadm skew policy",
+			data: &pkg.Data{Dest: []string{"org/k8s"}},
+			expectedMatrix: &matrix{
+				Components: []string{"kubeadm", "control plane", "kubelet"},
+				Rows: []*row{
+					{
+						Minor: "v1.17", Version: "v1.17.4",
+						Supported: []*cell{
+							{Component: "control plane", Minors: []string{"v1.17", "v1.16"}, Versions: []string{"v1.17.4", "v1.16.8"}},
+							{Component: "kubelet", Minors: []string{"v1.17", "v1.16"}, Versions: []string{"v1.17.4", "v1.16.8"}},
+						},
+					},
+					{
+						Minor: "v1.16", Version: "v1.16.8",
+						Supported: []*cell{
+							{Component: "control plane", Minors: []string{"v1.16"}, Versions: []string{"v1.16.8"}},
+							{Component: "kubelet", Minors: []string{"v1.16"}, Versions: []string{"v1.16.8"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid: minimum version",
+			data: &pkg.Data{Dest: []string{"org/k8s"}, MinVersion: "v1.17.0"},
+			expectedMatrix: &matrix{
+				Components: []string{"kubeadm", "control plane", "kubelet"},
+				Rows: []*row{
+					{
+						Minor: "v1.17", Version: "v1.17.4",
+						Supported: []*cell{
+							{Component: "control plane", Minors: []string{"v1.17", "v1.16"}, Versions: []string{"v1.17.4", "v1.16.8"}},
+							{Component: "kubelet", Minors: []string{"v1.17", "v1.16"}, Versions: []string{"v1.17.4", "v1.16.8"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid: skew policy with components in different repositories",
+			data: &pkg.Data{SkewPolicy: policyPath},
+			expectedMatrix: &matrix{
+				Components: []string{"tool", "runtime"},
+				Rows: []*row{
+					{
+						Minor: "v1.17", Version: "v1.17.1",
+						Supported: []*cell{
+							{Component: "runtime", Minors: []string{"v1.18", "v1.16"}, Versions: []string{"v1.18.0", "v1.16.2"}},
+						},
+					},
+					{
+						Minor: "v1.16", Version: "v1.16.0",
+						Supported: []*cell{
+							{Component: "runtime", Minors: []string{"v1.16"}, Versions: []string{"v1.16.2"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:          "invalid: simulated error when getting the tags",
+			data:          &pkg.Data{Dest: []string{"org/k8s"}},
+			methodErrors:  map[string]bool{http.MethodGet: true},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing skew policy",
+			data:          &pkg.Data{SkewPolicy: filepath.Join(dir, "missing.yaml")},
+			expectedError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d: %s", i, tt.name), func(t *testing.T) {
+			// Create fake client and setup endpoint handlers.
+			pkg.NewClient(tt.data, pkg.NewTransport())
+			for repo, refs := range map[string][]*github.Reference{"k8s": refsK8s, "tool": refsTool, "runtime": refsRuntime} {
+				refs := refs
+				tt.data.Transport.SetHandler("https://api.github.com/repos/org/"+repo+"/git/refs",
+					pkg.NewReferenceHandler(&refs, tt.methodErrors))
+			}
+
+			m, err := process(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(m, tt.expectedMatrix) {
+				t.Errorf("expected matrix:\n%s\ngot:\n%s", mustFormat(tt.expectedMatrix), mustFormat(m))
+			}
+		})
+	}
+}
+
+func mustFormat(m *matrix) []byte {
+	buf, err := formatOutput(m, &pkg.Data{OutputFormat: pkg.OutputFormatJSON})
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
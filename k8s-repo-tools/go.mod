@@ -8,4 +8,5 @@ require (
 	golang.org/x/mod v0.2.0
 	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
 	k8s.io/apimachinery v0.17.2
+	sigs.k8s.io/yaml v1.1.0
 )
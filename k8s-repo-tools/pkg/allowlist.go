@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// allowedDestTransport is an http.RoundTripper that refuses write requests to
+// repositories that do not match one of the --allowed-dest patterns, so that a
+// wrong --dest value cannot write to an unexpected repository.
+type allowedDestTransport struct {
+	base     http.RoundTripper
+	patterns []string
+}
+
+var _ http.RoundTripper = &allowedDestTransport{}
+
+// newAllowedDestTransport creates an allowedDestTransport that sends the
+// allowed requests using base.
+func newAllowedDestTransport(base http.RoundTripper, patterns []string) *allowedDestTransport {
+	return &allowedDestTransport{base: base, patterns: patterns}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *allowedDestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.base.RoundTrip(req)
+	}
+	// GraphQL requests do not have a repository in their path. Mutations are
+	// checked by their callers with checkAllowedDest.
+	if repo := repoFromPath(req.URL.Path); len(repo) != 0 {
+		if err := checkAllowedDest(t.patterns, repo); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// checkAllowedDest returns an error if patterns is not empty and repo does not
+// match any of the patterns.
+func checkAllowedDest(patterns []string, repo string) error {
+	if len(patterns) == 0 || matchAllowedDest(patterns, repo) {
+		return nil
+	}
+	return NewErrorf(ErrorKindNotAllowed, "writing to the repository %q is not allowed by --%s=%s",
+		repo, FlagAllowedDest, strings.Join(patterns, ","))
+}
+
+// matchAllowedDest returns true if repo matches one of the patterns. Like
+// repository names in GitHub the match is case-insensitive.
+func matchAllowedDest(patterns []string, repo string) bool {
+	repo = strings.ToLower(repo)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAllowedDest checks if a comma separated list of patterns for
+// --allowed-dest are valid patterns of the format 'org/repo'.
+func ValidateAllowedDest(option, value string) error {
+	for _, p := range SplitRepos(value) {
+		if strings.Count(p, "/") != 1 {
+			return errors.Errorf("the pattern %q of the option %q must be of the format 'org/repo'", p, option)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return errors.Wrapf(err, "invalid pattern %q for the option %q", p, option)
+		}
+	}
+	return nil
+}
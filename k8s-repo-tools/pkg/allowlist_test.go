@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	stderrors "errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestAllowedDestTransport(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		allowedDest   string
+		repo          string
+		graphQL       bool
+		expectedRefs  int
+		expectedError bool
+	}{
+		{
+			name:         "valid: all repositories are allowed by default",
+			repo:         "org/repo",
+			expectedRefs: 2,
+		},
+		{
+			name:         "valid: the repository matches a pattern",
+			allowedDest:  "other/repo,ORG/*",
+			repo:         "org/repo",
+			expectedRefs: 2,
+		},
+		{
+			name:          "invalid: the repository does not match any pattern",
+			allowedDest:   "other/repo,org/repo-*",
+			repo:          "org/repo",
+			expectedRefs:  1,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: the repository does not match any pattern with GraphQL",
+			allowedDest:   "other/*",
+			repo:          "org/repo",
+			graphQL:       true,
+			expectedRefs:  1,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := []*github.Reference{newTestRef("refs/heads/master", "sha0", "commit")}
+			d := &Data{AllowedDest: tt.allowedDest, GraphQL: tt.graphQL, Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/"+tt.repo+"/git/refs", NewReferenceHandler(&refs, nil))
+			d.Transport.SetHandler("https://api.github.com/graphql",
+				NewGraphQLRefHandler(map[string]*[]*github.Reference{tt.repo: &refs}, nil))
+
+			// Reads are always allowed.
+			if _, err := GitHubGetRef(d, tt.repo, "refs/heads/master"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var err error
+			newRef := newTestRef("refs/tags/v1.0.0", "sha1", "commit")
+			if tt.graphQL {
+				err = gitHubCreateRefsGraphQL(d, tt.repo, &[]*github.Reference{}, []*github.Reference{newRef})
+			} else {
+				_, err = GitHubCreateRef(d, tt.repo, newRef.GetRef(), newRef.GetObject().GetSHA(), false)
+			}
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil && !stderrors.Is(err, ErrNotAllowed) {
+				t.Errorf("expected a %q error, got: %v", ErrorKindNotAllowed, err)
+			}
+			if len(refs) != tt.expectedRefs {
+				t.Errorf("expected %d refs, got %d", tt.expectedRefs, len(refs))
+			}
+		})
+	}
+}
+
+func TestValidateAllowedDest(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedError bool
+	}{
+		{
+			name: "valid: empty",
+		},
+		{
+			name:  "valid: repositories and patterns",
+			value: "org/repo, org/*,*/repo-[a-z]",
+		},
+		{
+			name:          "invalid: missing the org",
+			value:         "org/repo,repo",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: bad pattern",
+			value:         "org/repo-[",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAllowedDest(FlagAllowedDest, tt.value)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// envStepSummary is the environment variable with the path of the job
+	// summary file of the current step in GitHub Actions.
+	envStepSummary = "GITHUB_STEP_SUMMARY"
+)
+
+// runAnnotations holds the state of the GitHub Actions annotation output mode.
+// Warnings and errors are written as workflow commands when they are logged and
+// are collected together with notices for the job summary.
+type runAnnotations struct {
+	sync.Mutex
+
+	tool        string
+	summaryFile string
+	finish      sync.Once
+
+	notices  []string
+	warnings []string
+	errors   []string
+}
+
+var (
+	// annotations is the state of the annotation output mode or nil if it is disabled.
+	annotations *runAnnotations
+
+	// changes are the changes that were written to repositories in this run.
+	changes      []string
+	changesMutex sync.Mutex
+
+	// summarySections are the sections that tools added to the job summary
+	// and summaryTool is the name of the tool for the job summary.
+	summarySections []summarySection
+	summaryTool     string
+	summaryMutex    sync.Mutex
+)
+
+// summarySection is a Markdown section of the job summary.
+type summarySection struct {
+	title string
+	body  string
+}
+
+// applyAnnotations enables the annotation output mode if --annotations is set.
+// The job summary is written to the file from the GITHUB_STEP_SUMMARY environment
+// variable, if it is set.
+func applyAnnotations(fs *flag.FlagSet) {
+	summaryMutex.Lock()
+	summaryTool = filepath.Base(fs.Name())
+	summaryMutex.Unlock()
+
+	f := fs.Lookup(FlagAnnotations)
+	if f == nil || f.Value.String() != "true" {
+		return
+	}
+	annotations = &runAnnotations{
+		tool:        filepath.Base(fs.Name()),
+		summaryFile: os.Getenv(envStepSummary),
+	}
+}
+
+// escapeWorkflowCommand escapes the message of a workflow command, so that
+// a message with multiple lines results in a single annotation.
+func escapeWorkflowCommand(msg string) string {
+	msg = strings.Replace(msg, "%", "%25", -1)
+	msg = strings.Replace(msg, "\r", "%0D", -1)
+	return strings.Replace(msg, "\n", "%0A", -1)
+}
+
+// annotate writes a workflow command for an annotation of the given level,
+// which is one of "notice", "warning" or "error". The workflow command is written
+// to stderr, which is also read by the runner, so that stdout only contains the
+// result of a tool.
+func annotate(level, msg string) {
+	a := annotations
+	if a == nil {
+		return
+	}
+	a.Lock()
+	switch level {
+	case "notice":
+		a.notices = append(a.notices, msg)
+	case "warning":
+		a.warnings = append(a.warnings, msg)
+	case "error":
+		a.errors = append(a.errors, msg)
+	}
+	a.Unlock()
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	fmt.Fprintf(stderr, "::%s::%s\n", level, escapeWorkflowCommand(msg))
+}
+
+// recordChange records a change that was written to a repository for the
+// job summary and notifications. The message is formatted as Markdown.
+func recordChange(f string, a ...interface{}) {
+	changesMutex.Lock()
+	defer changesMutex.Unlock()
+	changes = append(changes, fmt.Sprintf(f, a...))
+}
+
+// recordedChanges returns the changes that were written to repositories in this run.
+func recordedChanges() []string {
+	changesMutex.Lock()
+	defer changesMutex.Unlock()
+	return append([]string{}, changes...)
+}
+
+// AddSummarySection adds a section with a title and a Markdown body, such as
+// a table of the results of a tool, to the job summary of the run in GitHub
+// Actions. The sections are written by FinishRun to the file from the
+// GITHUB_STEP_SUMMARY environment variable. If the annotation output mode is
+// enabled they are part of its job summary, otherwise they are written even
+// without it. Outside of GitHub Actions the sections are not written.
+func AddSummarySection(title, body string) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	summarySections = append(summarySections, summarySection{title: title, body: body})
+}
+
+// takeSummarySections returns the sections of the job summary and removes
+// them, so that they are only written once.
+func takeSummarySections() []summarySection {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	sections := summarySections
+	summarySections = nil
+	return sections
+}
+
+// writeSummarySections writes the sections of the job summary to sb.
+func writeSummarySections(sb *strings.Builder, sections []summarySection) {
+	for _, s := range sections {
+		fmt.Fprintf(sb, "#### %s\n\n%s\n\n", s.title, strings.TrimRight(s.body, "\n"))
+	}
+}
+
+// summary returns the Markdown job summary of the run with its exit code and error.
+func (a *runAnnotations) summary(exitCode int, err error) string {
+	changes := recordedChanges()
+	sections := takeSummarySections()
+	a.Lock()
+	defer a.Unlock()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", a.tool)
+	switch {
+	case exitCode == 0:
+		sb.WriteString("**Result:** succeeded\n\n")
+	case err != nil:
+		fmt.Fprintf(&sb, "**Result:** failed with exit status %d and error kind `%s`\n\n", exitCode, ErrorKindOf(err))
+	default:
+		fmt.Fprintf(&sb, "**Result:** failed with exit status %d\n\n", exitCode)
+	}
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "#### %s\n\n", title)
+		for _, item := range items {
+			// Indent the continuation lines of an item so that it stays in the list.
+			fmt.Fprintf(&sb, "- %s\n", strings.Replace(item, "\n", "\n  ", -1))
+		}
+		sb.WriteString("\n")
+	}
+	writeList("Changes", changes)
+	writeSummarySections(&sb, sections)
+	writeList("Notices", a.notices)
+	writeList("Warnings", a.warnings)
+	writeList("Errors", a.errors)
+	return sb.String()
+}
+
+// write appends the job summary to the summary file.
+func (a *runAnnotations) write(summary string) error {
+	return appendSummary(a.summaryFile, summary)
+}
+
+// appendSummary appends a job summary to a summary file.
+func appendSummary(path, summary string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "could not open the job summary file %q", path)
+	}
+	if _, err := file.WriteString(summary); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "could not write the job summary file %q", path)
+	}
+	return errors.Wrapf(file.Close(), "could not write the job summary file %q", path)
+}
+
+// finishAnnotations writes the job summary of the run with its exit code and
+// error if the annotation output mode is enabled and the job summary file is
+// known. Only the first call has an effect. Failing to write the job summary
+// does not fail the run and only logs a warning. Without the annotation output
+// mode only the sections added with AddSummarySection are written.
+func finishAnnotations(exitCode int, err error) {
+	a := annotations
+	if a == nil {
+		finishSummarySections()
+		return
+	}
+	if len(a.summaryFile) == 0 {
+		return
+	}
+	a.finish.Do(func() {
+		if writeErr := a.write(a.summary(exitCode, err)); writeErr != nil {
+			Warningf("%v", writeErr)
+		}
+	})
+}
+
+// finishSummarySections writes the sections added with AddSummarySection
+// under the name of the tool if the job summary file is known.
+func finishSummarySections() {
+	path := os.Getenv(envStepSummary)
+	if len(path) == 0 {
+		return
+	}
+	sections := takeSummarySections()
+	if len(sections) == 0 {
+		return
+	}
+	summaryMutex.Lock()
+	tool := summaryTool
+	summaryMutex.Unlock()
+
+	var sb strings.Builder
+	if len(tool) != 0 {
+		fmt.Fprintf(&sb, "### %s\n\n", tool)
+	}
+	writeSummarySections(&sb, sections)
+	if err := appendSummary(path, sb.String()); err != nil {
+		Warningf("%v", err)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEscapeWorkflowCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		expected string
+	}{
+		{
+			name:     "valid: no special characters",
+			msg:      "ref \"v1.0.0\" is missing",
+			expected: "ref \"v1.0.0\" is missing",
+		},
+		{
+			name:     "valid: percent and new lines are escaped",
+			msg:      "100%\r\nfailed\nretry",
+			expected: "100%25%0D%0Afailed%0Aretry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeWorkflowCommand(tt.msg); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunAnnotations(t *testing.T) {
+	defer func() { annotations, changes = nil, nil }()
+	defer SetLogWriters(GetLogWriters())
+
+	tests := []struct {
+		name            string
+		exitCode        int
+		err             error
+		expectedStderr  string
+		expectedSummary string
+	}{
+		{
+			name: "valid: successful run",
+			expectedStderr: "::notice::created 1 tags\n" +
+				"::warning::the ref \"v1.0.0\" points to%0Aanother commit\n",
+			expectedSummary: "previous step\n" +
+				"### k8s-repo-sync\n\n" +
+				"**Result:** succeeded\n\n" +
+				"#### Changes\n\n" +
+				"- created ref `refs/tags/v1.0.0` from commit `sha0` in repository `org/repo`\n\n" +
+				"#### Notices\n\n" +
+				"- created 1 tags\n\n" +
+				"#### Warnings\n\n" +
+				"- the ref \"v1.0.0\" points to\n  another commit\n\n",
+		},
+		{
+			name:     "valid: failed run",
+			exitCode: 1,
+			err:      NewErrorf(ErrorKindConflict, "ref %q already exists", "v1.0.0"),
+			expectedStderr: "::notice::created 1 tags\n" +
+				"::warning::the ref \"v1.0.0\" points to%0Aanother commit\n" +
+				"::error::ref \"v1.0.0\" already exists\n",
+			expectedSummary: "previous step\n" +
+				"### k8s-repo-sync\n\n" +
+				"**Result:** failed with exit status 1 and error kind `Conflict`\n\n" +
+				"#### Changes\n\n" +
+				"- created ref `refs/tags/v1.0.0` from commit `sha0` in repository `org/repo`\n\n" +
+				"#### Notices\n\n" +
+				"- created 1 tags\n\n" +
+				"#### Warnings\n\n" +
+				"- the ref \"v1.0.0\" points to\n  another commit\n\n" +
+				"#### Errors\n\n" +
+				"- ref \"v1.0.0\" already exists\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "annotations")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			summaryFile := filepath.Join(dir, "summary.md")
+			// The job summary is appended to the summary of previous steps.
+			if err := ioutil.WriteFile(summaryFile, []byte("previous step\n"), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer func(v string, ok bool) {
+				if ok {
+					os.Setenv(envStepSummary, v)
+				} else {
+					os.Unsetenv(envStepSummary)
+				}
+			}(os.LookupEnv(envStepSummary))
+			os.Setenv(envStepSummary, summaryFile)
+
+			fs := flag.NewFlagSet("k8s-repo-sync", flag.ContinueOnError)
+			fs.Bool(FlagAnnotations, true, "")
+			applyAnnotations(fs)
+			changes = nil
+
+			stderr := &bytes.Buffer{}
+			SetLogWriters(ioutil.Discard, stderr)
+			SetLogFormat(LogFormatJSON)
+			defer SetLogFormat(LogFormatText)
+
+			recordChange("created ref `%s` from commit `%s` in repository `%s`", "refs/tags/v1.0.0", "sha0", "org/repo")
+			Noticef("created %d tags", 1)
+			Warningf("the ref %q points to\nanother commit", "v1.0.0")
+			if tt.err != nil {
+				logf(stderr, "error", nil, nil, "%+v", tt.err)
+				annotate("error", tt.err.Error())
+			}
+			FinishRun(tt.exitCode, tt.err)
+			// Only the first call writes the job summary.
+			FinishRun(0, nil)
+
+			var gotStderr bytes.Buffer
+			for _, line := range bytes.SplitAfter(stderr.Bytes(), []byte("\n")) {
+				if bytes.HasPrefix(line, []byte("::")) {
+					gotStderr.Write(line)
+				}
+			}
+			if gotStderr.String() != tt.expectedStderr {
+				t.Errorf("expected workflow commands:\n%s\ngot:\n%s", tt.expectedStderr, gotStderr.String())
+			}
+			summary, err := ioutil.ReadFile(summaryFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(summary) != tt.expectedSummary {
+				t.Errorf("expected job summary:\n%s\ngot:\n%s", tt.expectedSummary, summary)
+			}
+		})
+	}
+}
+
+func TestAddSummarySection(t *testing.T) {
+	defer func() { annotations, summarySections, summaryTool = nil, nil, "" }()
+
+	dir, err := ioutil.TempDir("", "summary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	summaryFile := filepath.Join(dir, "summary.md")
+	defer func(v string, ok bool) {
+		if ok {
+			os.Setenv(envStepSummary, v)
+		} else {
+			os.Unsetenv(envStepSummary)
+		}
+	}(os.LookupEnv(envStepSummary))
+	os.Setenv(envStepSummary, summaryFile)
+
+	// The sections are written without the annotation output mode.
+	fs := flag.NewFlagSet("k8s-repo-sync", flag.ContinueOnError)
+	fs.Bool(FlagAnnotations, false, "")
+	applyAnnotations(fs)
+
+	AddSummarySection("Tags", "| Tag |\n|---|\n| v1.0.0 |\n")
+	AddSummarySection("Branches", "none")
+	FinishRun(0, nil)
+	// Only the first call writes the sections.
+	FinishRun(0, nil)
+
+	expectedSummary := "### k8s-repo-sync\n\n" +
+		"#### Tags\n\n| Tag |\n|---|\n| v1.0.0 |\n\n" +
+		"#### Branches\n\nnone\n\n"
+	summary, err := ioutil.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(summary) != expectedSummary {
+		t.Errorf("expected job summary:\n%s\ngot:\n%s", expectedSummary, summary)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// benchmarkSizes are the approximate numbers of refs in the synthetic ref sets.
+var benchmarkSizes = []int{100, 1000, 10000}
+
+// newBenchmarkTags returns about n tag refs that look like the tags of a
+// Kubernetes repository: every MINOR has pre-releases and PATCH releases.
+func newBenchmarkTags(n int) []*github.Reference {
+	preReleases := []string{"alpha.0", "alpha.1", "alpha.2", "beta.0", "beta.1", "rc.0", "rc.1"}
+	const patches = 13
+
+	refs := make([]*github.Reference, 0, n)
+	for i := 0; len(refs) < n; i++ {
+		major, minor := 1+i/100, i%100
+		for _, pre := range preReleases {
+			refs = append(refs, newTestRef(fmt.Sprintf("refs/tags/v%d.%d.0-%s", major, minor, pre), fmt.Sprintf("%040x", len(refs)), "commit"))
+		}
+		for patch := 0; patch < patches && len(refs) < n; patch++ {
+			refs = append(refs, newTestRef(fmt.Sprintf("refs/tags/v%d.%d.%d", major, minor, patch), fmt.Sprintf("%040x", len(refs)), "commit"))
+		}
+	}
+	return refs
+}
+
+func BenchmarkTrimTags(b *testing.B) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	minV := version.MustParseSemantic("v1.10.0")
+	for _, n := range benchmarkSizes {
+		refs := newBenchmarkTags(n)
+		b.Run(fmt.Sprintf("refs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				TrimTags(refs, minV, false)
+			}
+		})
+	}
+}
+
+func BenchmarkFindNewRefs(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		src := newBenchmarkTags(n)
+		// The destination is missing every tenth ref of the source.
+		dest := make([]*github.Reference, 0, len(src))
+		for i, ref := range src {
+			if i%10 != 0 {
+				dest = append(dest, ref)
+			}
+		}
+		b.Run(fmt.Sprintf("refs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FindNewRefs(src, dest)
+			}
+		})
+	}
+}
+
+func BenchmarkFindReleaseNotesSinceRef(b *testing.B) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	for _, n := range benchmarkSizes {
+		refs := newBenchmarkTags(n)
+		// The last tags are the ones that are searched for in a nightly job.
+		tags := refs[len(refs)-20:]
+		b.Run(fmt.Sprintf("refs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := FindReleaseNotesSinceRef(tags[i%len(tags)], refs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReferenceHandler(b *testing.B) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	for _, n := range benchmarkSizes {
+		refs := newBenchmarkTags(n)
+		d := &Data{}
+		NewClient(d, NewTransport())
+		d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs", NewReferenceHandler(&refs, nil))
+		b.Run(fmt.Sprintf("refs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tags, err := GitHubGetTags(d, "org/repo")
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(tags) != len(refs) {
+					b.Fatalf("expected %d tags, got %d", len(refs), len(tags))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureSuffix is the suffix of the name of a detached signature of a release asset.
+const SignatureSuffix = ".sig"
+
+// ChecksumEntry is a release asset in a checksum manifest.
+type ChecksumEntry struct {
+	Name string `json:"name"`
+	// Size is the size of the asset in bytes. It is -1 if the size is unknown,
+	// such as for manifests in the format written by 'sha256sum'.
+	Size int64 `json:"size"`
+	// SHA256 is the checksum of the asset. It is empty if only the name of the
+	// asset is known.
+	SHA256 string `json:"sha256"`
+	// Signature is the name of the asset with the detached signature of this asset.
+	Signature string `json:"signature,omitempty"`
+}
+
+// ChecksumManifest lists the name, size, SHA256 checksum and signature of release assets.
+type ChecksumManifest struct {
+	Assets []*ChecksumEntry `json:"assets"`
+}
+
+// NewChecksumEntry returns a ChecksumEntry for an asset with the given contents.
+func NewChecksumEntry(name string, data []byte) *ChecksumEntry {
+	sum := sha256.Sum256(data)
+	return &ChecksumEntry{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// NewChecksumManifest generates a checksum manifest for the local files of the release
+// assets in am, sorted by name. An asset named '<asset>.sig' is the signature of '<asset>'.
+func NewChecksumManifest(am assetMap) (*ChecksumManifest, error) {
+	names := make([]string, 0, len(am))
+	for name := range am {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m := &ChecksumManifest{Assets: make([]*ChecksumEntry, 0, len(names))}
+	for _, name := range names {
+		entry, err := checksumFile(name, am[name])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := am[name+SignatureSuffix]; ok {
+			entry.Signature = name + SignatureSuffix
+		}
+		m.Assets = append(m.Assets, entry)
+	}
+	return m, nil
+}
+
+// checksumFile returns a ChecksumEntry for the file at path without reading
+// the whole file into memory.
+func checksumFile(name, path string) (*ChecksumEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the file %q of asset %q", path, name)
+	}
+	return &ChecksumEntry{Name: name, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// ParseChecksumManifest parses a checksum manifest. Both the JSON format written by
+// ChecksumManifest.Marshal and the format of ParseAssetManifest are supported. In the
+// latter format the sizes are unknown and the checksums are optional.
+func ParseChecksumManifest(data []byte) (*ChecksumManifest, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		m := &ChecksumManifest{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, errors.Wrap(err, "could not parse the checksum manifest")
+		}
+		seen := map[string]bool{}
+		for _, entry := range m.Assets {
+			if len(entry.Name) == 0 {
+				return nil, errors.New("the checksum manifest has an asset without a name")
+			}
+			if !regexpSHA256.MatchString(entry.SHA256) {
+				return nil, errors.Errorf("the asset %q has a malformed SHA256 checksum %q in the checksum manifest", entry.Name, entry.SHA256)
+			}
+			if seen[entry.Name] {
+				return nil, errors.Errorf("the asset %q is listed more than once in the checksum manifest", entry.Name)
+			}
+			seen[entry.Name] = true
+		}
+		return m, nil
+	}
+
+	entries, err := ParseAssetManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	m := &ChecksumManifest{Assets: make([]*ChecksumEntry, 0, len(entries))}
+	for _, entry := range entries {
+		m.Assets = append(m.Assets, &ChecksumEntry{Name: entry.Name, Size: -1, SHA256: entry.SHA256})
+	}
+	return m, nil
+}
+
+// Marshal returns the manifest in JSON format.
+func (m *ChecksumManifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "\t")
+}
+
+// SHA256Sums returns the checksums of the manifest in the format written by 'sha256sum'.
+// Assets without a checksum are skipped.
+func (m *ChecksumManifest) SHA256Sums() []byte {
+	var buf bytes.Buffer
+	for _, entry := range m.Assets {
+		if len(entry.SHA256) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s  %s\n", entry.SHA256, entry.Name)
+	}
+	return buf.Bytes()
+}
+
+// Entry returns the entry for the asset with the given name or nil if the
+// manifest does not list it.
+func (m *ChecksumManifest) Entry(name string) *ChecksumEntry {
+	for _, entry := range m.Assets {
+		if entry.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Verify verifies that the contents of the asset with the given name match the
+// size and checksum in the manifest, if they are known. An error of kind
+// ErrorKindVerificationFailed is returned if they do not match or the manifest
+// does not list the asset.
+func (m *ChecksumManifest) Verify(name string, data []byte) error {
+	entry := m.Entry(name)
+	if entry == nil {
+		return NewErrorf(ErrorKindVerificationFailed, "the asset %q is not listed in the checksum manifest", name)
+	}
+	got := NewChecksumEntry(name, data)
+	if entry.Size >= 0 && got.Size != entry.Size {
+		return NewErrorf(ErrorKindVerificationFailed, "the size of asset %q is %d bytes, expected %d bytes", name, got.Size, entry.Size)
+	}
+	if len(entry.SHA256) != 0 && got.SHA256 != entry.SHA256 {
+		return NewErrorf(ErrorKindVerificationFailed, "the SHA256 checksum of asset %q is %s, expected %s", name, got.SHA256, entry.SHA256)
+	}
+	return nil
+}
+
+// Compare returns the assets of the manifest that are missing from names and the
+// names that the manifest does not list, both sorted.
+func (m *ChecksumManifest) Compare(names []string) ([]string, []string) {
+	missing, unexpected := []string{}, []string{}
+	inNames := map[string]bool{}
+	for _, name := range names {
+		inNames[name] = true
+		if m.Entry(name) == nil {
+			unexpected = append(unexpected, name)
+		}
+	}
+	for _, entry := range m.Assets {
+		if !inNames[entry.Name] {
+			missing = append(missing, entry.Name)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The SHA256 checksums of "foo" and "bar".
+const (
+	testSumFoo = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	testSumBar = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+)
+
+func TestNewChecksumManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	am := assetMap{}
+	for name, content := range map[string]string{"kubeadm": "foo", "kubeadm.sig": "bar"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		am[name] = path
+	}
+
+	m, err := NewChecksumManifest(am)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &ChecksumManifest{Assets: []*ChecksumEntry{
+		{Name: "kubeadm", Size: 3, SHA256: testSumFoo, Signature: "kubeadm.sig"},
+		{Name: "kubeadm.sig", Size: 3, SHA256: testSumBar},
+	}}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected manifest:\n%+v\ngot:\n%+v", expected.Assets, m.Assets)
+	}
+
+	// The manifest must be parsed to the same value.
+	buf, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseChecksumManifest(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("expected parsed manifest:\n%+v\ngot:\n%+v", expected.Assets, parsed.Assets)
+	}
+	if sums, expected := string(m.SHA256Sums()), testSumFoo+"  kubeadm\n"+testSumBar+"  kubeadm.sig\n"; sums != expected {
+		t.Errorf("expected checksums:\n%s\ngot:\n%s", expected, sums)
+	}
+
+	am["missing"] = filepath.Join(dir, "missing")
+	if _, err := NewChecksumManifest(am); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestParseChecksumManifest(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             string
+		expectedManifest *ChecksumManifest
+		expectedError    bool
+	}{
+		{
+			name: "valid: JSON format",
+			data: `{"assets": [{"name": "kubeadm", "size": 3, "sha256": "` + testSumFoo + `"}]}`,
+			expectedManifest: &ChecksumManifest{Assets: []*ChecksumEntry{
+				{Name: "kubeadm", Size: 3, SHA256: testSumFoo},
+			}},
+		},
+		{
+			name: "valid: sha256sum format",
+			data: testSumFoo + "  kubeadm\n" + testSumBar + " *kubectl\nREADME.md\n",
+			expectedManifest: &ChecksumManifest{Assets: []*ChecksumEntry{
+				{Name: "kubeadm", Size: -1, SHA256: testSumFoo},
+				{Name: "kubectl", Size: -1, SHA256: testSumBar},
+				{Name: "README.md", Size: -1},
+			}},
+		},
+		{
+			name:          "invalid: malformed checksum in JSON format",
+			data:          `{"assets": [{"name": "kubeadm", "size": 3, "sha256": "foo"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: duplicate asset in JSON format",
+			data:          `{"assets": [{"name": "kubeadm", "sha256": "` + testSumFoo + `"}, {"name": "kubeadm", "sha256": "` + testSumFoo + `"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: asset without a name in JSON format",
+			data:          `{"assets": [{"sha256": "` + testSumFoo + `"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed JSON",
+			data:          `{"assets": `,
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseChecksumManifest([]byte(tt.data))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(m, tt.expectedManifest) {
+				t.Errorf("expected manifest:\n%+v\ngot:\n%+v", tt.expectedManifest.Assets, m.Assets)
+			}
+		})
+	}
+}
+
+func TestChecksumManifestVerify(t *testing.T) {
+	m := &ChecksumManifest{Assets: []*ChecksumEntry{
+		{Name: "kubeadm", Size: 3, SHA256: testSumFoo},
+		{Name: "kubectl", Size: -1, SHA256: testSumBar},
+		{Name: "README.md", Size: -1},
+	}}
+
+	tests := []struct {
+		name          string
+		asset         string
+		data          string
+		expectedError bool
+	}{
+		{
+			name:  "valid: size and checksum match",
+			asset: "kubeadm",
+			data:  "foo",
+		},
+		{
+			name:  "valid: checksum matches and the size is unknown",
+			asset: "kubectl",
+			data:  "bar",
+		},
+		{
+			name:  "valid: size and checksum are unknown",
+			asset: "README.md",
+			data:  "baz",
+		},
+		{
+			name:          "invalid: size mismatch",
+			asset:         "kubeadm",
+			data:          "fooo",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: checksum mismatch",
+			asset:         "kubeadm",
+			data:          "bar",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: asset is not listed",
+			asset:         "kubelet",
+			data:          "foo",
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.Verify(tt.asset, []byte(tt.data))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil && ErrorKindOf(err) != ErrorKindVerificationFailed {
+				t.Errorf("expected error kind %q, got %q", ErrorKindVerificationFailed, ErrorKindOf(err))
+			}
+		})
+	}
+
+	missing, unexpected := m.Compare([]string{"kubectl", "kubelet", "kubeadm"})
+	if !reflect.DeepEqual(missing, []string{"README.md"}) || !reflect.DeepEqual(unexpected, []string{"kubelet"}) {
+		t.Errorf("expected missing [README.md] and unexpected [kubelet], got %v and %v", missing, unexpected)
+	}
+}
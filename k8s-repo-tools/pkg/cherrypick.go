@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// GitHubCherryPick creates the branch head from the HEAD of the base branch and
+// cherry-picks the commits with the given SHAs on it in order. It returns the
+// reference of the new branch.
+//
+// The GitHub API does not support cherry-picks, so every commit is applied with
+// the Git Data API: a temporary commit with the tree of the branch and the parent
+// of the cherry-picked commit is created on head, the cherry-picked commit is merged
+// into it and a commit with the resulting tree is created on top of the branch.
+// Merge conflicts are returned as errors of kind ErrorKindConflict. If a commit
+// cannot be cherry-picked the branch head is deleted.
+func GitHubCherryPick(d *Data, repo, base, head string, shas []string, dryRun bool) (*github.Reference, error) {
+	headRef := "refs/heads/" + head
+	if dryRun {
+		Logf("%s: would cherry-pick %d commit(s) on branch %q from %q in repository %q", PrefixDryRun, len(shas), head, base, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCherryPick, Repo: repo, Base: base, Head: head, SHAs: shas})
+		return &github.Reference{Ref: github.String(headRef)}, nil
+	}
+
+	baseRef, err := GitHubGetRef(d, repo, "refs/heads/"+base)
+	if err != nil {
+		return nil, err
+	}
+	sha := baseRef.GetObject().GetSHA()
+	baseCommit, err := GitHubGetCommit(d, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	tree := baseCommit.GetTree().GetSHA()
+	if _, err := GitHubCreateRef(d, repo, headRef, sha, false); err != nil {
+		return nil, err
+	}
+
+	sha, err = cherryPickCommits(d, repo, base, head, sha, tree, shas)
+	if err != nil {
+		// Do not leave a partially cherry-picked branch behind.
+		if delErr := GitHubDeleteRef(d, repo, headRef, false); delErr != nil {
+			Warningf("could not delete branch %q after a failed cherry-pick: %v", head, delErr)
+		}
+		return nil, err
+	}
+	Logf("branch %q now points to commit %q", head, sha)
+	return &github.Reference{Ref: github.String(headRef), Object: &github.GitObject{SHA: github.String(sha)}}, nil
+}
+
+// cherryPickCommits applies the commits with the given SHAs on the branch head,
+// which points to the commit sha with the given tree. It returns the SHA of the
+// last cherry-picked commit.
+func cherryPickCommits(d *Data, repo, base, head, sha, tree string, shas []string) (string, error) {
+	headRef := "refs/heads/" + head
+	for _, pick := range shas {
+		if d.Interrupted() {
+			return "", ErrInterrupted
+		}
+		Logf("cherry-picking commit %q on branch %q", pick, head)
+		commit, err := GitHubGetCommit(d, repo, pick)
+		if err != nil {
+			return "", err
+		}
+		if len(commit.Parents) != 1 {
+			return "", errors.Errorf("cannot cherry-pick commit %q with %d parents", pick, len(commit.Parents))
+		}
+
+		// Merge the commit into a temporary commit with the tree of the branch.
+		temp, err := gitHubCreateCommit(d, repo, &github.Commit{
+			Message: github.String("temporary commit for cherry-picking " + pick),
+			Tree:    &github.Tree{SHA: github.String(tree)},
+			Parents: []github.Commit{{SHA: commit.Parents[0].SHA}},
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := gitHubUpdateRef(d, repo, headRef, temp.GetSHA()); err != nil {
+			return "", err
+		}
+		merge, resp, err := GitHubMergeBranch(d, repo, head, pick, "cherry-pick "+pick)
+		if err != nil {
+			if ErrorKindOf(err) == ErrorKindConflict {
+				return "", NewErrorf(ErrorKindConflict, "commit %q cannot be cherry-picked on branch %q without conflicts", pick, base)
+			}
+			return "", err
+		}
+		if resp.StatusCode != http.StatusCreated {
+			return "", errors.Errorf("unexpected status %d when cherry-picking commit %q", resp.StatusCode, pick)
+		}
+		mergeCommit, err := GitHubGetCommit(d, repo, merge.GetSHA())
+		if err != nil {
+			return "", err
+		}
+
+		// Create the cherry-picked commit on top of the branch.
+		tree = mergeCommit.GetTree().GetSHA()
+		picked, err := gitHubCreateCommit(d, repo, &github.Commit{
+			Message: github.String(fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimSpace(commit.GetMessage()), pick)),
+			Tree:    &github.Tree{SHA: github.String(tree)},
+			Parents: []github.Commit{{SHA: github.String(sha)}},
+			Author:  commit.Author,
+		})
+		if err != nil {
+			return "", err
+		}
+		sha = picked.GetSHA()
+		if err := gitHubUpdateRef(d, repo, headRef, sha); err != nil {
+			return "", err
+		}
+	}
+	return sha, nil
+}
+
+// GitHubGetCommit obtains a Git commit from a GitHub repository.
+func GitHubGetCommit(d *Data, repo, sha string) (*github.Commit, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting commit %q from repository %q", sha, repo)
+	var commit *github.Commit
+	err := retry(d, fmt.Sprintf("getting commit %q", sha), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		commit, resp, err = d.client.Git().GetCommit(ctx, ownerRepo[0], ownerRepo[1], sha)
+		return resp, err
+	})
+	return commit, err
+}
+
+func gitHubCreateCommit(d *Data, repo string, commit *github.Commit) (*github.Commit, error) {
+	ownerRepo := strings.Split(repo, "/")
+	var created *github.Commit
+	// A commit that is created twice is not referenced and can be retried without a check.
+	err := retryWrite(d, "creating a commit", nil, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		created, resp, err = d.client.Git().CreateCommit(ctx, ownerRepo[0], ownerRepo[1], commit)
+		return resp, err
+	})
+	return created, err
+}
+
+func gitHubUpdateRef(d *Data, repo, ref, sha string) error {
+	ownerRepo := strings.Split(repo, "/")
+	r := &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha)}}
+	// Forcing a ref to a commit is idempotent and can be retried without a check.
+	return retryWrite(d, fmt.Sprintf("updating ref %q", ref), nil, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Git().UpdateRef(ctx, ownerRepo[0], ownerRepo[1], r, true)
+		return resp, err
+	})
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// Client is the GitHub API client of Data. The services have the methods of the
+// services of a go-github Client that the package uses, so that embedders of the
+// package can supply their own implementation with Data.SetClient, for example to
+// mock the GitHub API without a fake Transport. NewClient sets a Client that uses
+// a go-github Client.
+type Client interface {
+	Checks() ChecksService
+	Git() GitService
+	Issues() IssuesService
+	PullRequests() PullRequestsService
+	Repositories() RepositoriesService
+	Search() SearchService
+	Users() UsersService
+
+	// NewRequest, NewUploadRequest and Do are used for the endpoints that
+	// go-github does not support, such as the GitHub Actions API.
+	NewRequest(method, urlStr string, body interface{}) (*http.Request, error)
+	NewUploadRequest(urlStr string, reader io.Reader, size int64, mediaType string) (*http.Request, error)
+	Do(ctx context.Context, req *http.Request, v interface{}) (*github.Response, error)
+	// BaseURL returns the base URL of the REST API.
+	BaseURL() *url.URL
+	// UploadURL returns the base URL for uploading release assets.
+	UploadURL() *url.URL
+}
+
+// ChecksService is the part of the checks API that the package uses.
+// It is implemented by *github.ChecksService.
+type ChecksService interface {
+	GetCheckSuite(ctx context.Context, owner, repo string, checkSuiteID int64) (*github.CheckSuite, *github.Response, error)
+	ListCheckSuitesForRef(ctx context.Context, owner, repo, ref string, opt *github.ListCheckSuiteOptions) (*github.ListCheckSuiteResults, *github.Response, error)
+	ReRequestCheckSuite(ctx context.Context, owner, repo string, checkSuiteID int64) (*github.Response, error)
+}
+
+// GitService is the part of the Git database API that the package uses.
+// It is implemented by *github.GitService.
+type GitService interface {
+	CreateCommit(ctx context.Context, owner, repo string, commit *github.Commit) (*github.Commit, *github.Response, error)
+	CreateRef(ctx context.Context, owner, repo string, ref *github.Reference) (*github.Reference, *github.Response, error)
+	DeleteRef(ctx context.Context, owner, repo string, ref string) (*github.Response, error)
+	GetCommit(ctx context.Context, owner, repo string, sha string) (*github.Commit, *github.Response, error)
+	GetRef(ctx context.Context, owner, repo string, ref string) (*github.Reference, *github.Response, error)
+	GetRefs(ctx context.Context, owner, repo string, ref string) ([]*github.Reference, *github.Response, error)
+	GetTag(ctx context.Context, owner, repo string, sha string) (*github.Tag, *github.Response, error)
+	UpdateRef(ctx context.Context, owner, repo string, ref *github.Reference, force bool) (*github.Reference, *github.Response, error)
+}
+
+// IssuesService is the part of the issues, labels and milestones API that the package uses.
+// It is implemented by *github.IssuesService.
+type IssuesService interface {
+	AddLabelsToIssue(ctx context.Context, owner, repo string, number int, labels []string) ([]*github.Label, *github.Response, error)
+	Create(ctx context.Context, owner, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	CreateComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	CreateLabel(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error)
+	CreateMilestone(ctx context.Context, owner, repo string, milestone *github.Milestone) (*github.Milestone, *github.Response, error)
+	DeleteLabel(ctx context.Context, owner, repo string, name string) (*github.Response, error)
+	Edit(ctx context.Context, owner, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	EditLabel(ctx context.Context, owner, repo string, name string, label *github.Label) (*github.Label, *github.Response, error)
+	EditMilestone(ctx context.Context, owner, repo string, number int, milestone *github.Milestone) (*github.Milestone, *github.Response, error)
+	GetLabel(ctx context.Context, owner, repo string, name string) (*github.Label, *github.Response, error)
+	ListByRepo(ctx context.Context, owner, repo string, opt *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	ListComments(ctx context.Context, owner, repo string, number int, opt *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error)
+	ListLabels(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.Label, *github.Response, error)
+	ListMilestones(ctx context.Context, owner, repo string, opt *github.MilestoneListOptions) ([]*github.Milestone, *github.Response, error)
+}
+
+// PullRequestsService is the part of the pull requests API that the package uses.
+// It is implemented by *github.PullRequestsService.
+type PullRequestsService interface {
+	Create(ctx context.Context, owner, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
+	Get(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error)
+	List(ctx context.Context, owner, repo string, opt *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	ListCommits(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+}
+
+// RepositoriesService is the part of the repositories and releases API that the package uses.
+// It is implemented by *github.RepositoriesService.
+type RepositoriesService interface {
+	CreateFile(ctx context.Context, owner, repo, path string, opt *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	DownloadReleaseAsset(ctx context.Context, owner, repo string, id int64) (rc io.ReadCloser, redirectURL string, err error)
+	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	GetBranchProtection(ctx context.Context, owner, repo, branch string) (*github.Protection, *github.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opt *github.RepositoryContentGetOptions) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error)
+	GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error)
+	ListHooks(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.Hook, *github.Response, error)
+	ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error)
+	ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	Merge(ctx context.Context, owner, repo string, request *github.RepositoryMergeRequest) (*github.RepositoryCommit, *github.Response, error)
+	UpdateBranchProtection(ctx context.Context, owner, repo, branch string, preq *github.ProtectionRequest) (*github.Protection, *github.Response, error)
+	UpdateFile(ctx context.Context, owner, repo, path string, opt *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+}
+
+// SearchService is the part of the search API that the package uses.
+// It is implemented by *github.SearchService.
+type SearchService interface {
+	Issues(ctx context.Context, query string, opt *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+}
+
+// UsersService is the part of the users API that the package uses.
+// It is implemented by *github.UsersService.
+type UsersService interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+
+var _ ChecksService = &github.ChecksService{}
+var _ GitService = &github.GitService{}
+var _ IssuesService = &github.IssuesService{}
+var _ PullRequestsService = &github.PullRequestsService{}
+var _ RepositoriesService = &github.RepositoriesService{}
+var _ SearchService = &github.SearchService{}
+var _ UsersService = &github.UsersService{}
+
+// gitHubClient is the Client that uses a go-github Client.
+type gitHubClient struct {
+	c *github.Client
+}
+
+var _ Client = &gitHubClient{}
+
+// NewGitHubClient returns a Client that uses a go-github Client.
+func NewGitHubClient(c *github.Client) Client {
+	return &gitHubClient{c: c}
+}
+
+func (c *gitHubClient) Checks() ChecksService             { return c.c.Checks }
+func (c *gitHubClient) Git() GitService                   { return c.c.Git }
+func (c *gitHubClient) Issues() IssuesService             { return c.c.Issues }
+func (c *gitHubClient) PullRequests() PullRequestsService { return c.c.PullRequests }
+func (c *gitHubClient) Repositories() RepositoriesService { return c.c.Repositories }
+func (c *gitHubClient) Search() SearchService             { return c.c.Search }
+func (c *gitHubClient) Users() UsersService               { return c.c.Users }
+
+func (c *gitHubClient) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	return c.c.NewRequest(method, urlStr, body)
+}
+
+func (c *gitHubClient) NewUploadRequest(urlStr string, reader io.Reader, size int64, mediaType string) (*http.Request, error) {
+	return c.c.NewUploadRequest(urlStr, reader, size, mediaType)
+}
+
+func (c *gitHubClient) Do(ctx context.Context, req *http.Request, v interface{}) (*github.Response, error) {
+	return c.c.Do(ctx, req, v)
+}
+
+func (c *gitHubClient) BaseURL() *url.URL {
+	return c.c.BaseURL
+}
+
+func (c *gitHubClient) UploadURL() *url.URL {
+	return c.c.UploadURL
+}
+
+// Client returns the GitHub API client. It is the one set with SetClient or
+// the one created by NewClient.
+func (d *Data) Client() Client {
+	return d.client
+}
+
+// SetClient replaces the GitHub API client that NewClient created.
+func (d *Data) SetClient(c Client) {
+	d.client = c
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// fakeClient is a Client with a fake Git service. Calls to other services panic.
+type fakeClient struct {
+	Client
+	git *fakeGitService
+}
+
+func (c *fakeClient) Git() GitService {
+	return c.git
+}
+
+// fakeGitService is a GitService that manages refs in memory.
+type fakeGitService struct {
+	GitService
+	refs map[string]*github.Reference
+}
+
+func (s *fakeGitService) GetRef(ctx context.Context, owner, repo, ref string) (*github.Reference, *github.Response, error) {
+	r, ok := s.refs[owner+"/"+repo+":"+ref]
+	if !ok {
+		resp := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		return nil, resp, &github.ErrorResponse{Response: resp.Response, Message: "Not Found"}
+	}
+	return r, &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+}
+
+func (s *fakeGitService) CreateRef(ctx context.Context, owner, repo string, ref *github.Reference) (*github.Reference, *github.Response, error) {
+	s.refs[owner+"/"+repo+":"+ref.GetRef()] = ref
+	return ref, &github.Response{Response: &http.Response{StatusCode: http.StatusCreated}}, nil
+}
+
+func TestSetClient(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	d := &Data{Timeout: time.Minute}
+	NewClient(d, NewTransport())
+	git := &fakeGitService{refs: map[string]*github.Reference{}}
+	d.SetClient(&fakeClient{git: git})
+	if _, ok := d.Client().(*fakeClient); !ok {
+		t.Fatalf("expected the fake client, got %T", d.Client())
+	}
+
+	ref, err := GitHubCreateRef(d, "org/repo", "refs/tags/v1.17.0", "sha", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.GetObject().GetSHA() != "sha" {
+		t.Errorf("expected SHA %q, got %q", "sha", ref.GetObject().GetSHA())
+	}
+	if _, err := GitHubGetRef(d, "org/repo", "refs/tags/v1.17.0"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := GitHubGetRef(d, "org/repo", "refs/tags/v1.18.0"); ErrorKindOf(err) != ErrorKindNotFound {
+		t.Errorf("expected an error of kind %q, got %v", ErrorKindNotFound, err)
+	}
+}
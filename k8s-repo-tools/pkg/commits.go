@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+)
+
+var (
+	// mergeCommitRegexp matches the message of a merge commit of a pull request.
+	mergeCommitRegexp = regexp.MustCompile(`^Merge pull request #([0-9]+) from `)
+	// squashCommitRegexp matches the title of a squashed or rebased pull request,
+	// which ends with the number of the pull request.
+	squashCommitRegexp = regexp.MustCompile(`\(#([0-9]+)\)$`)
+)
+
+// RangeCommit is a commit in a range of commits between two refs.
+type RangeCommit struct {
+	SHA string `json:"sha"`
+	// Title is the first line of the commit message. For a merge commit
+	// of a pull request it is the title of the pull request.
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	// PullRequest is the number of the pull request that the commit merged,
+	// or 0 if the commit message does not refer to a pull request.
+	PullRequest int    `json:"pullRequest,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// GitHubListCommitsBetween returns the commits that are reachable from head but not
+// from base, such as two tags, from the oldest to the newest. The number of the pull
+// request of every commit is extracted from its message.
+func GitHubListCommitsBetween(d *Data, repo, base, head string) ([]*RangeCommit, error) {
+	Logf("listing the commits between %q and %q in repository %q", base, head, repo)
+	cmp, err := GitHubCompareBranches(d, repo, base, head)
+	if err != nil {
+		return nil, err
+	}
+	return RangeCommitsFromComparison(cmp), nil
+}
+
+// RangeCommitsFromComparison returns the commits of a comparison as RangeCommits.
+func RangeCommitsFromComparison(cmp *github.CommitsComparison) []*RangeCommit {
+	result := make([]*RangeCommit, 0, len(cmp.Commits))
+	for _, c := range cmp.Commits {
+		title, number := ParsePullRequestCommitMessage(c.GetCommit().GetMessage())
+		author := c.GetAuthor().GetLogin()
+		if len(author) == 0 {
+			author = c.GetCommit().GetAuthor().GetName()
+		}
+		result = append(result, &RangeCommit{
+			SHA:         c.GetSHA(),
+			Title:       title,
+			Author:      author,
+			PullRequest: number,
+			URL:         c.GetHTMLURL(),
+		})
+	}
+	return result
+}
+
+// ParsePullRequestCommitMessage returns the title of a commit message and the number
+// of the pull request that the commit merged, or 0 if there is none. Merge commits
+// such as "Merge pull request #123 from user/branch" followed by the title of the
+// pull request, and squashed commits with a title such as "Fix a bug (#123)" are
+// recognized.
+func ParsePullRequestCommitMessage(message string) (string, int) {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	title := strings.TrimSpace(lines[0])
+	if m := mergeCommitRegexp.FindStringSubmatch(title); m != nil {
+		number, _ := strconv.Atoi(m[1])
+		// The title of the pull request follows after an empty line.
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); len(line) != 0 {
+				return line, number
+			}
+		}
+		return title, number
+	}
+	if m := squashCommitRegexp.FindStringSubmatch(title); m != nil {
+		number, _ := strconv.Atoi(m[1])
+		return title, number
+	}
+	return title, 0
+}
+
+// PullRequestNumbers returns the numbers of the pull requests of a list of
+// commits in the order of the commits, without duplicates. It returns nil
+// if none of the commits refers to a pull request.
+func PullRequestNumbers(commits []*RangeCommit) []int {
+	var result []int
+	seen := map[int]bool{}
+	for _, c := range commits {
+		if c.PullRequest == 0 || seen[c.PullRequest] {
+			continue
+		}
+		seen[c.PullRequest] = true
+		result = append(result, c.PullRequest)
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestParsePullRequestCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        string
+		expectedTitle  string
+		expectedNumber int
+	}{
+		{
+			name:           "valid: merge commit",
+			message:        "Merge pull request #123 from user/branch\n\nFix a bug in the foo\n\nLonger description",
+			expectedTitle:  "Fix a bug in the foo",
+			expectedNumber: 123,
+		},
+		{
+			name:           "valid: merge commit without a title",
+			message:        "Merge pull request #123 from user/branch",
+			expectedTitle:  "Merge pull request #123 from user/branch",
+			expectedNumber: 123,
+		},
+		{
+			name:           "valid: squashed commit",
+			message:        "Fix a bug in the foo (#456)\n\n* first commit\n* second commit (#1)",
+			expectedTitle:  "Fix a bug in the foo (#456)",
+			expectedNumber: 456,
+		},
+		{
+			name:          "valid: commit without a pull request",
+			message:       "Fix a bug in the foo\n\nSee #789",
+			expectedTitle: "Fix a bug in the foo",
+		},
+		{
+			name: "valid: empty message",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, number := ParsePullRequestCommitMessage(tt.message)
+			if title != tt.expectedTitle {
+				t.Errorf("expected title %q, got %q", tt.expectedTitle, title)
+			}
+			if number != tt.expectedNumber {
+				t.Errorf("expected number %d, got %d", tt.expectedNumber, number)
+			}
+		})
+	}
+}
+
+func TestGitHubListCommitsBetween(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	newCommit := func(sha, login, name, message string) github.RepositoryCommit {
+		c := github.RepositoryCommit{
+			SHA: github.String(sha),
+			Commit: &github.Commit{
+				Message: github.String(message),
+				Author:  &github.CommitAuthor{Name: github.String(name)},
+			},
+		}
+		if len(login) != 0 {
+			c.Author = &github.User{Login: github.String(login)}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name              string
+		commits           []github.RepositoryCommit
+		expectedCommits   []*RangeCommit
+		expectedPRNumbers []int
+	}{
+		{
+			name: "valid: commits with and without pull requests",
+			commits: []github.RepositoryCommit{
+				newCommit("sha1", "foo", "Foo", "Merge pull request #1 from foo/bar\n\nAdd bar"),
+				newCommit("sha2", "", "Baz", "Update the docs"),
+				newCommit("sha3", "foo", "Foo", "Fix bar (#2)"),
+				newCommit("sha4", "foo", "Foo", "Merge pull request #1 from foo/bar\n\nAdd bar"),
+			},
+			expectedCommits: []*RangeCommit{
+				{SHA: "sha1", Title: "Add bar", Author: "foo", PullRequest: 1},
+				{SHA: "sha2", Title: "Update the docs", Author: "Baz"},
+				{SHA: "sha3", Title: "Fix bar (#2)", Author: "foo", PullRequest: 2},
+				{SHA: "sha4", Title: "Add bar", Author: "foo", PullRequest: 1},
+			},
+			expectedPRNumbers: []int{1, 2},
+		},
+		{
+			name:            "valid: no commits",
+			expectedCommits: []*RangeCommit{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const compareURL = "https://api.github.com/repos/org/repo/compare/v1.17.0...v1.17.1"
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler(compareURL, func(req *http.Request) (*http.Response, error) {
+				buf, err := json.Marshal(&github.CommitsComparison{
+					Status:       github.String("ahead"),
+					TotalCommits: github.Int(len(tt.commits)),
+					Commits:      tt.commits,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				}, nil
+			})
+
+			commits, err := GitHubListCommitsBetween(d, "org/repo", "v1.17.0", "v1.17.1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(commits, tt.expectedCommits) {
+				expected, _ := json.Marshal(tt.expectedCommits)
+				got, _ := json.Marshal(commits)
+				t.Errorf("expected commits:\n%s\ngot:\n%s", expected, got)
+			}
+			if numbers := PullRequestNumbers(commits); !reflect.DeepEqual(numbers, tt.expectedPRNumbers) {
+				t.Errorf("expected pull requests %v, got %v", tt.expectedPRNumbers, numbers)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RunConcurrently calls all functions in parallel and waits for them to return.
+// If more than one function fails, the error of the first failing function in the
+// argument order is returned, so that the reported error does not depend on timing.
+func RunConcurrently(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	wg.Add(len(fns))
+	for i := range fns {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fns[i]()
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachRepo calls fn for every repository with the index of the repository and
+// a copy of the default Logger with the repository, so that the messages of
+// repositories that are processed concurrently can be told apart. The package
+// level log functions, which the GitHub helpers use, do not have this field.
+// By default the repositories are processed in order and the first error is returned.
+// If d.Parallelism is greater than one, up to d.Parallelism repositories are processed
+// concurrently and a failing repository does not stop the others. The errors of all
+// failed repositories are then aggregated in a single error that names the repositories.
+// In both cases ErrInterrupted is returned if the process is interrupted before all
+// repositories are processed.
+func ForEachRepo(d *Data, repos []string, fn func(i int, repo string, log *Logger) error) error {
+	if d.Parallelism <= 1 {
+		for i, repo := range repos {
+			if d.Interrupted() {
+				return ErrInterrupted
+			}
+			if err := fn(i, repo, DefaultLogger().WithRepo(repo)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	Logf("processing %d repositories with up to %d in parallel", len(repos), d.Parallelism)
+	errs := make([]error, len(repos))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < d.Parallelism && w < len(repos); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				log := DefaultLogger().WithRepo(repos[i])
+				if errs[i] = fn(i, repos[i], log); errs[i] != nil {
+					log.Warningf("processing repository %q failed: %v", repos[i], errs[i])
+				}
+			}
+		}()
+	}
+	interrupted := false
+	for i := range repos {
+		if d.Interrupted() {
+			interrupted = true
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []string
+	var firstErr error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if stderrors.Is(err, ErrInterrupted) {
+			interrupted = true
+			continue
+		}
+		if firstErr == nil {
+			firstErr = errors.Wrapf(err, "repository %q", repos[i])
+		}
+		failed = append(failed, fmt.Sprintf("%s: %v", repos[i], err))
+	}
+	switch {
+	case interrupted:
+		return ErrInterrupted
+	case len(failed) == 0:
+		return nil
+	case len(failed) == 1:
+		return firstErr
+	}
+	return errors.Errorf("%d of %d repositories failed:\n%s", len(failed), len(repos), strings.Join(failed, "\n"))
+}
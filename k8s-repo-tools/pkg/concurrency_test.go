@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+
+	tests := []struct {
+		name          string
+		fns           []func() error
+		expectedError error
+	}{
+		{
+			name: "valid: no functions",
+		},
+		{
+			name: "valid: all functions succeed",
+			fns: []func() error{
+				func() error { return nil },
+				func() error { return nil },
+			},
+		},
+		{
+			name: "invalid: the error of the first function is returned even if it fails last",
+			fns: []func() error{
+				func() error { time.Sleep(20 * time.Millisecond); return errFirst },
+				func() error { return errSecond },
+			},
+			expectedError: errFirst,
+		},
+		{
+			name: "invalid: a single failing function",
+			fns: []func() error{
+				func() error { return nil },
+				func() error { return errSecond },
+			},
+			expectedError: errSecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RunConcurrently(tt.fns...); err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	// All functions must run to completion, even if one of them fails.
+	var calls int32
+	inc := func() error { atomic.AddInt32(&calls, 1); return errFirst }
+	if err := RunConcurrently(inc, inc, inc); err != errFirst {
+		t.Errorf("expected error %v, got %v", errFirst, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestForEachRepo(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	repos := []string{"org/a", "org/b", "org/c", "org/d"}
+	failing := func(names ...string) func(int, string) error {
+		return func(_ int, repo string) error {
+			for _, name := range names {
+				if repo == name {
+					return errors.New("failed")
+				}
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name          string
+		parallelism   int
+		fn            func(int, string) error
+		expectedCalls int32
+		expectedError string
+	}{
+		{
+			name:          "valid: serial",
+			fn:            failing(),
+			expectedCalls: 4,
+		},
+		{
+			name:          "valid: parallel",
+			parallelism:   2,
+			fn:            failing(),
+			expectedCalls: 4,
+		},
+		{
+			name:          "invalid: serial processing stops at the first error",
+			parallelism:   1,
+			fn:            failing("org/b", "org/c"),
+			expectedCalls: 2,
+			expectedError: "failed",
+		},
+		{
+			name:          "invalid: parallel processing names a single failed repository",
+			parallelism:   3,
+			fn:            failing("org/c"),
+			expectedCalls: 4,
+			expectedError: `repository "org/c": failed`,
+		},
+		{
+			name:          "invalid: parallel processing aggregates the errors",
+			parallelism:   3,
+			fn:            failing("org/b", "org/d"),
+			expectedCalls: 4,
+			expectedError: "2 of 4 repositories failed:\norg/b: failed\norg/d: failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Parallelism: tt.parallelism}
+
+			// Track the number of calls that run at the same time.
+			var calls, running, maxRunning int32
+			var mu sync.Mutex
+			err := ForEachRepo(d, repos, func(i int, repo string, log *Logger) error {
+				atomic.AddInt32(&calls, 1)
+				if !strings.Contains(log.prefix(), "repo="+repo) {
+					t.Errorf("expected a Logger with repository %q, got prefix %q", repo, log.prefix())
+				}
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				if repos[i] != repo {
+					t.Errorf("expected repository %q at index %d, got %q", repos[i], i, repo)
+				}
+				return tt.fn(i, repo)
+			})
+
+			var errStr string
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			limit := int32(tt.parallelism)
+			if limit < 1 {
+				limit = 1
+			}
+			if maxRunning > limit {
+				t.Errorf("expected at most %d parallel calls, got %d", limit, maxRunning)
+			}
+		})
+	}
+}
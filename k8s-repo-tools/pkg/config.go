@@ -0,0 +1,329 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// EnvPrefix is the prefix of environment variables that can be used
+// to set flag values. For example, K8S_REPO_TOOLS_DEST sets --dest.
+const EnvPrefix = "K8S_REPO_TOOLS_"
+
+// envAliases maps flag names to additional environment variables
+// that are used if the EnvPrefix variable of a flag is not set. They
+// are added by SetupOptions from the env tags of the options.
+var envAliases = map[string]string{}
+
+// ParseFlags parses the flags in a FlagSet and then applies the values
+// from environment variables and from the YAML file passed with --config.
+// Flags passed on the command line have precedence over environment
+// variables, which have precedence over the values in the file.
+func ParseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Record the flags passed on the command line before flags are set
+	// from other sources.
+	cmdFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		cmdFlags[f.Name] = true
+	})
+	if f := fs.Lookup(FlagVersion); f != nil && f.Value.String() == "true" {
+		PrintVersionAndExit(fs.Name())
+	}
+	if err := applyEnv(fs, os.LookupEnv); err != nil {
+		return err
+	}
+	if f := fs.Lookup(FlagConfig); f != nil && len(f.Value.String()) != 0 {
+		data, err := ioutil.ReadFile(f.Value.String())
+		if err != nil {
+			return errors.Wrapf(err, "could not read the config file %q", f.Value.String())
+		}
+		if err := applyConfig(fs, filepath.Base(fs.Name()), data); err != nil {
+			return err
+		}
+	}
+	if err := applyTokenFile(fs, os.Stdin, cmdFlags); err != nil {
+		return err
+	}
+	if f := fs.Lookup(FlagQuiet); f != nil {
+		SetQuiet(f.Value.String() == "true")
+	}
+	if f := fs.Lookup(FlagVerbosity); f != nil {
+		v, err := strconv.Atoi(f.Value.String())
+		if err != nil {
+			return errors.Wrapf(err, "could not parse the value of %q", FlagVerbosity)
+		}
+		SetVerbosity(v)
+	}
+	if err := applyHTTP(fs); err != nil {
+		return err
+	}
+	if err := applyMetrics(fs); err != nil {
+		return err
+	}
+	if err := applyTracing(fs); err != nil {
+		return err
+	}
+	applyAnnotations(fs)
+	if f := fs.Lookup(FlagAllowedDest); f != nil {
+		if err := ValidateAllowedDest(FlagAllowedDest, f.Value.String()); err != nil {
+			return err
+		}
+	}
+	if f := fs.Lookup(FlagLogFormat); f != nil {
+		return SetLogFormat(f.Value.String())
+	}
+	return nil
+}
+
+// applyHTTP configures the HTTP transport from the values of
+// --https-proxy, --ca-bundle and the --http-* connection flags.
+func applyHTTP(fs *flag.FlagSet) error {
+	opts := DefaultHTTPOptions()
+	lookup := func(name string) (interface{}, bool) {
+		f := fs.Lookup(name)
+		if f == nil {
+			return nil, false
+		}
+		g, ok := f.Value.(flag.Getter)
+		if !ok {
+			return nil, false
+		}
+		return g.Get(), true
+	}
+	if v, ok := lookup(FlagHTTPSProxy); ok {
+		opts.Proxy = v.(string)
+	}
+	if v, ok := lookup(FlagCABundle); ok {
+		opts.CABundle = v.(string)
+	}
+	if v, ok := lookup(FlagHTTPMaxIdleConns); ok {
+		opts.MaxIdleConns = v.(int)
+	}
+	if v, ok := lookup(FlagHTTPMaxIdleConnsPerHost); ok {
+		opts.MaxIdleConnsPerHost = v.(int)
+	}
+	if v, ok := lookup(FlagHTTPMaxConnsPerHost); ok {
+		opts.MaxConnsPerHost = v.(int)
+	}
+	if v, ok := lookup(FlagHTTPIdleConnTimeout); ok {
+		opts.IdleConnTimeout = v.(time.Duration)
+	}
+	if v, ok := lookup(FlagHTTPDisableKeepAlives); ok {
+		opts.DisableKeepAlives = v.(bool)
+	}
+	if v, ok := lookup(FlagHTTPDisableHTTP2); ok {
+		opts.DisableHTTP2 = v.(bool)
+	}
+	if opts.MaxIdleConns < 0 || opts.MaxIdleConnsPerHost < 0 || opts.MaxConnsPerHost < 0 || opts.IdleConnTimeout < 0 {
+		return errors.Errorf("the options %q, %q, %q and %q cannot be negative",
+			FlagHTTPMaxIdleConns, FlagHTTPMaxIdleConnsPerHost, FlagHTTPMaxConnsPerHost, FlagHTTPIdleConnTimeout)
+	}
+	return ConfigureHTTPWithOptions(opts)
+}
+
+// applyTokenFile sets the token flag from the file passed with --token-file.
+// A file can contain multiple tokens, one per line. cmdFlags are the names of
+// the flags passed on the command line. A token passed on the command line
+// cannot be used together with a token file, but the tokens from the file
+// replace a token from an environment variable or from the config file.
+func applyTokenFile(fs *flag.FlagSet, stdin io.Reader, cmdFlags map[string]bool) error {
+	f := fs.Lookup(FlagTokenFile)
+	if f == nil || len(f.Value.String()) == 0 {
+		return nil
+	}
+	if cmdFlags[FlagToken] {
+		return errors.Errorf("the options %q and %q cannot be used together", FlagToken, FlagTokenFile)
+	}
+	tokens, err := ReadTokenFile(f.Value.String(), stdin)
+	if err != nil {
+		return err
+	}
+	if t := fs.Lookup(FlagToken); t != nil {
+		if v, ok := t.Value.(tokenValue); ok {
+			v.reset()
+		}
+	}
+	for _, token := range tokens {
+		if err := fs.Set(FlagToken, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTokenFile reads a list of tokens, one per line, from a file, or from
+// stdin if the path is "-". Leading and trailing whitespace of the lines,
+// such as a trailing carriage return, is trimmed and empty lines are skipped.
+func ReadTokenFile(path string, stdin io.Reader) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the token file %q", path)
+	}
+	tokens := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		token := strings.TrimSpace(line)
+		if len(token) == 0 {
+			continue
+		}
+		if strings.ContainsAny(token, " \t") {
+			return nil, errors.Errorf("the token file %q must contain a single token per line", path)
+		}
+		tokens = append(tokens, token)
+	}
+	if len(tokens) == 0 {
+		return nil, errors.Errorf("the token file %q is empty", path)
+	}
+	return tokens, nil
+}
+
+// EnvName returns the name of the environment variable for a flag.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// applyEnv sets the flags in a FlagSet that were not passed on the command
+// line from environment variables. Flags that allow multiple instances
+// accept a comma separated list.
+func applyEnv(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) error {
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || setFlags[f.Name] {
+			return
+		}
+		name := EnvName(f.Name)
+		value, ok := lookupEnv(name)
+		if !ok {
+			if name, ok = envAliases[f.Name]; ok {
+				value, ok = lookupEnv(name)
+			}
+		}
+		if !ok {
+			return
+		}
+
+		values := []string{value}
+		switch f.Value.(type) {
+		case *multiString, *assetMap, tokenValue:
+			values = strings.Split(value, ",")
+		}
+		for _, v := range values {
+			if setErr := fs.Set(f.Name, v); setErr != nil {
+				err = errors.Wrapf(setErr, "could not set the value of %q from the environment variable %q", f.Name, name)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// applyConfig sets the flags in a FlagSet from YAML data that maps flag names
+// to values. A key matching the tool name can hold a map of values only
+// for this tool, which have precedence over the top level values.
+// Keys that are not flags of the tool are skipped, so that a single file
+// can be shared between tools.
+func applyConfig(fs *flag.FlagSet, tool string, data []byte) error {
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return errors.Wrap(err, "could not parse the config file")
+	}
+
+	// Flags passed on the command line are not overridden.
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	values := map[string]interface{}{}
+	for k, v := range cfg {
+		if _, ok := v.(map[string]interface{}); ok {
+			continue
+		}
+		values[k] = v
+	}
+	if toolCfg, ok := cfg[tool].(map[string]interface{}); ok {
+		for k, v := range toolCfg {
+			values[k] = v
+		}
+	}
+
+	// Sort the keys to apply the values in a deterministic order.
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == FlagConfig || setFlags[k] {
+			continue
+		}
+		if fs.Lookup(k) == nil {
+			V(2).Logf("skipping config key %q that is not a flag of %q", k, tool)
+			continue
+		}
+		// Lists are applied by setting each element, such as for flags
+		// that allow multiple instances.
+		list, ok := values[k].([]interface{})
+		if !ok {
+			list = []interface{}{values[k]}
+		}
+		for _, v := range list {
+			if err := fs.Set(k, configValueToString(v)); err != nil {
+				return errors.Wrapf(err, "could not set the value of %q from the config file", k)
+			}
+		}
+	}
+	return nil
+}
+
+// configValueToString converts a YAML value to a string that can be passed to flag.Set.
+func configValueToString(v interface{}) string {
+	switch t := v.(type) {
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
@@ -0,0 +1,458 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfigOptions are the flags of TestParseFlags.
+type testConfigOptions struct {
+	DryRunOptions
+	TimeoutOptions
+	Dest         []string    `flag:"dest" usage:"A list of repositories"`
+	Offset       int         `flag:"offset" usage:"An int flag"`
+	PrefixBranch string      `flag:"branch-prefix" default:"release-" usage:"A string flag with a default value"`
+	IgnorePaths  multiString `flag:"ignore-path" usage:"A flag that can be passed multiple times"`
+}
+
+// testEnvOptions are the flags of TestApplyEnv.
+type testEnvOptions struct {
+	TokenOptions
+	DryRunOptions
+	Dest        []string    `flag:"dest" usage:"A list of repositories"`
+	IgnorePaths multiString `flag:"ignore-path" usage:"A flag that can be passed multiple times"`
+}
+
+// withHTTPDefaults sets the default values of the HTTP connection flags in
+// the expected data of a test.
+func withHTTPDefaults(d *Data) *Data {
+	d.HTTPMaxIdleConns = DefaultHTTPMaxIdleConns
+	d.HTTPMaxIdleConnsPerHost = DefaultHTTPMaxIdleConnsPerHost
+	d.HTTPIdleConnTimeout = DefaultHTTPIdleConnTimeout
+	return d
+}
+
+func TestParseFlags(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "k8s-repo-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const config = `
+dest: org/dest
+dry-run: false
+timeout: 30s
+offset: 2
+ignore-path:
+- Golang
+- k8s.io/klog
+unknown-flag: foo
+k8s-test-tool:
+  dest: org/tool-dest
+  branch-prefix: tool-
+another-tool:
+  dest: org/another-dest
+`
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedData  *Data
+		expectedError bool
+	}{
+		{
+			name: "valid: no config",
+			args: []string{"-dest=org/cli"},
+			expectedData: &Data{
+				LogFormat:    LogFormatText,
+				Verbosity:    DefaultVerbosity,
+				Dest:         []string{"org/cli"},
+				DryRun:       true,
+				Timeout:      time.Second * 20,
+				PrefixBranch: PrefixBranch,
+				IgnorePaths:  multiString{},
+			},
+		},
+		{
+			name: "valid: values from the config with tool overrides",
+			args: []string{"-config=" + configPath},
+			expectedData: &Data{
+				LogFormat:    LogFormatText,
+				Verbosity:    DefaultVerbosity,
+				Config:       configPath,
+				Dest:         []string{"org/tool-dest"},
+				DryRun:       false,
+				Timeout:      time.Second * 30,
+				Offset:       2,
+				PrefixBranch: "tool-",
+				IgnorePaths:  multiString{"Golang", "k8s.io/klog"},
+			},
+		},
+		{
+			name: "valid: command line flags have precedence",
+			args: []string{"-config=" + configPath, "-dest=org/cli", "-dry-run=true", "-ignore-path=foo"},
+			expectedData: &Data{
+				LogFormat:    LogFormatText,
+				Verbosity:    DefaultVerbosity,
+				Config:       configPath,
+				Dest:         []string{"org/cli"},
+				DryRun:       true,
+				Timeout:      time.Second * 30,
+				Offset:       2,
+				PrefixBranch: "tool-",
+				IgnorePaths:  multiString{"foo"},
+			},
+		},
+		{
+			name:          "invalid: missing config file",
+			args:          []string{"-config=" + filepath.Join(dir, "missing.yaml")},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{IgnorePaths: multiString{}}
+			fs := flag.NewFlagSet("k8s-test-tool", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			SetupOptions(d, fs, &testConfigOptions{})
+
+			err := ParseFlags(fs, tt.args)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(d, withHTTPDefaults(tt.expectedData)) {
+				t.Errorf("expected data:\n%+v\ngot:\n%+v", tt.expectedData, d)
+			}
+		})
+	}
+}
+
+func TestApplyConfigInvalidValue(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	d := &Data{}
+	fs := flag.NewFlagSet("k8s-test-tool", flag.ContinueOnError)
+	SetupOptions(d, fs, &TimeoutOptions{})
+	if err := applyConfig(fs, "k8s-test-tool", []byte("timeout: foo")); err == nil {
+		t.Errorf("expected an error for an invalid duration")
+	}
+}
+
+func TestApplyHTTP(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer ConfigureHTTP("", "")
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError bool
+	}{
+		{
+			name: "valid: default connection settings",
+		},
+		{
+			name: "valid: custom connection settings",
+			args: []string{"-http-max-idle-conns-per-host=4", "-http-idle-conn-timeout=5s", "-http-disable-http2"},
+		},
+		{
+			name:          "invalid: negative connection limit",
+			args:          []string{"-http-max-conns-per-host=-1"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("k8s-test-tool", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			SetupOptions(&Data{}, fs, &struct{}{})
+			err := ParseFlags(fs, tt.args)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		env           map[string]string
+		expectedData  *Data
+		expectedError bool
+	}{
+		{
+			name: "valid: values from the environment",
+			env: map[string]string{
+				"K8S_REPO_TOOLS_DEST":        "org/env",
+				"K8S_REPO_TOOLS_DRY_RUN":     "false",
+				"K8S_REPO_TOOLS_IGNORE_PATH": "Golang,k8s.io/klog",
+				"GITHUB_TOKEN":               "github-token",
+			},
+			expectedData: &Data{
+				LogFormat:   LogFormatText,
+				Verbosity:   DefaultVerbosity,
+				Retries:     DefaultRetries,
+				RetryWait:   DefaultRetryWait,
+				Dest:        []string{"org/env"},
+				Token:       "github-token",
+				Tokens:      multiString{"github-token"},
+				IgnorePaths: multiString{"Golang", "k8s.io/klog"},
+			},
+		},
+		{
+			name: "valid: prefixed token variable has precedence over GITHUB_TOKEN",
+			env: map[string]string{
+				"K8S_REPO_TOOLS_TOKEN": "prefixed-token",
+				"GITHUB_TOKEN":         "github-token",
+			},
+			expectedData: &Data{
+				LogFormat:   LogFormatText,
+				Verbosity:   DefaultVerbosity,
+				Retries:     DefaultRetries,
+				RetryWait:   DefaultRetryWait,
+				Token:       "prefixed-token",
+				Tokens:      multiString{"prefixed-token"},
+				DryRun:      true,
+				IgnorePaths: multiString{},
+			},
+		},
+		{
+			name: "valid: comma separated list of tokens",
+			env: map[string]string{
+				"GITHUB_TOKEN": "token-a,token-b",
+			},
+			expectedData: &Data{
+				LogFormat:   LogFormatText,
+				Verbosity:   DefaultVerbosity,
+				Retries:     DefaultRetries,
+				RetryWait:   DefaultRetryWait,
+				Token:       "token-a",
+				Tokens:      multiString{"token-a", "token-b"},
+				DryRun:      true,
+				IgnorePaths: multiString{},
+			},
+		},
+		{
+			name: "valid: command line flags have precedence",
+			args: []string{"-dest=org/cli"},
+			env: map[string]string{
+				"K8S_REPO_TOOLS_DEST": "org/env",
+			},
+			expectedData: &Data{
+				LogFormat:   LogFormatText,
+				Verbosity:   DefaultVerbosity,
+				Retries:     DefaultRetries,
+				RetryWait:   DefaultRetryWait,
+				Dest:        []string{"org/cli"},
+				DryRun:      true,
+				IgnorePaths: multiString{},
+			},
+		},
+		{
+			name: "invalid: value cannot be parsed",
+			env: map[string]string{
+				"K8S_REPO_TOOLS_DRY_RUN": "foo",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{IgnorePaths: multiString{}}
+			fs := flag.NewFlagSet("k8s-test-tool", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			SetupOptions(d, fs, &testEnvOptions{})
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			lookupEnv := func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			}
+			err := applyEnv(fs, lookupEnv)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(d, withHTTPDefaults(tt.expectedData)) {
+				t.Errorf("expected data:\n%+v\ngot:\n%+v", tt.expectedData, d)
+			}
+		})
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "k8s-repo-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name           string
+		contents       string
+		stdin          string
+		useStdin       bool
+		expectedTokens []string
+		expectedError  bool
+	}{
+		{
+			name:           "valid: token with a trailing new line",
+			contents:       "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab\n",
+			expectedTokens: []string{"282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"},
+		},
+		{
+			name:           "valid: token from stdin",
+			stdin:          "  282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab\r\n",
+			useStdin:       true,
+			expectedTokens: []string{"282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"},
+		},
+		{
+			name:           "valid: multiple tokens with empty lines",
+			contents:       "foo\n\nbar\r\n",
+			expectedTokens: []string{"foo", "bar"},
+		},
+		{
+			name:          "invalid: empty file",
+			contents:      "\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: multiple tokens in a line",
+			contents:      "foo bar\n",
+			expectedError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "-"
+			if !tt.useStdin {
+				path = filepath.Join(dir, fmt.Sprintf("token-%d", i))
+				if err := ioutil.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tokens, err := ReadTokenFile(path, strings.NewReader(tt.stdin))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(tokens, tt.expectedTokens) {
+				t.Errorf("expected tokens %q, got %q", tt.expectedTokens, tokens)
+			}
+		})
+	}
+
+	// A missing file is an error.
+	if _, err := ReadTokenFile(filepath.Join(dir, "missing"), nil); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestApplyTokenFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		env            map[string]string
+		expectedToken  string
+		expectedTokens []string
+		expectedError  bool
+	}{
+		{
+			name:          "valid: token from stdin",
+			args:          []string{"-token-file=-"},
+			expectedToken: "foo",
+		},
+		{
+			name:          "valid: no token file",
+			args:          []string{"-token=bar"},
+			expectedToken: "bar",
+		},
+		{
+			name:          "invalid: token and token file",
+			args:          []string{"-token=bar", "-token-file=-"},
+			expectedToken: "bar",
+			expectedError: true,
+		},
+		{
+			name:           "valid: token file overrides the token from the environment",
+			args:           []string{"-token-file=-"},
+			env:            map[string]string{"GITHUB_TOKEN": "bar"},
+			expectedToken:  "foo",
+			expectedTokens: []string{"foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{}
+			fs := flag.NewFlagSet("k8s-test-tool", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			SetupOptions(d, fs, &TokenOptions{})
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			cmdFlags := map[string]bool{}
+			fs.Visit(func(f *flag.Flag) {
+				cmdFlags[f.Name] = true
+			})
+			lookupEnv := func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			}
+			if err := applyEnv(fs, lookupEnv); err != nil {
+				t.Fatal(err)
+			}
+			err := applyTokenFile(fs, strings.NewReader("foo\n"), cmdFlags)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if d.Token != tt.expectedToken {
+				t.Errorf("expected token %q, got %q", tt.expectedToken, d.Token)
+			}
+			if tt.expectedTokens != nil && !reflect.DeepEqual([]string(d.Tokens), tt.expectedTokens) {
+				t.Errorf("expected tokens %q, got %q", tt.expectedTokens, d.Tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// DepsDevURL is the base URL of the deps.dev (https://deps.dev) API.
+const DepsDevURL = "https://api.deps.dev/v3"
+
+// depsDevVersion is a subset of the deps.dev response for a package version.
+type depsDevVersion struct {
+	Licenses []string `json:"licenses"`
+}
+
+// DepsDevGetLicenses returns a sorted list of SPDX license identifiers
+// for a version of a Go module.
+func DepsDevGetLicenses(client *http.Client, baseURL, path, version string) ([]string, error) {
+	u := fmt.Sprintf("%s/systems/go/packages/%s/versions/%s",
+		baseURL, url.PathEscape(path), url.PathEscape(version))
+
+	V(2).Logf("querying licenses for %s@%s", path, version)
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("received status %d querying licenses for %s@%s",
+			resp.StatusCode, path, version)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read response body")
+	}
+	v := depsDevVersion{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, errors.Wrap(err, "could not parse the deps.dev response")
+	}
+
+	licenses := append([]string{}, v.Licenses...)
+	sort.Strings(licenses)
+	return licenses, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDepsDevGetLicenses(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		expectedLicenses []string
+		expectedError    bool
+	}{
+		{
+			name:             "valid: found licenses",
+			status:           http.StatusOK,
+			body:             `{"licenses":["MIT","Apache-2.0"]}`,
+			expectedLicenses: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			name:             "valid: no licenses",
+			status:           http.StatusOK,
+			body:             `{}`,
+			expectedLicenses: []string{},
+		},
+		{
+			name:          "invalid: version not found",
+			status:        http.StatusNotFound,
+			body:          `{}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed response",
+			status:        http.StatusOK,
+			body:          `foo`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestURL string
+			transport := NewTransport()
+			transport.SetHandler(DepsDevURL, func(req *http.Request) (*http.Response, error) {
+				requestURL = req.URL.String()
+				return &http.Response{
+					StatusCode: tt.status,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+					Header:     http.Header{},
+				}, nil
+			})
+			client := &http.Client{Transport: transport}
+
+			licenses, err := DepsDevGetLicenses(client, DepsDevURL, "k8s.io/klog", "v1.0.0")
+			expectedURL := DepsDevURL + "/systems/go/packages/k8s.io%2Fklog/versions/v1.0.0"
+			if requestURL != expectedURL {
+				t.Errorf("expected request URL %q, got %q", expectedURL, requestURL)
+			}
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(licenses, tt.expectedLicenses) {
+				t.Errorf("expected licenses %v, got %v", tt.expectedLicenses, licenses)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// ErrorKind is the kind of a failure.
+type ErrorKind string
+
+const (
+	// ErrorKindGeneric is the kind of errors that do not have a more specific kind.
+	ErrorKindGeneric ErrorKind = "Generic"
+	// ErrorKindNotFound is returned when a GitHub object does not exist.
+	ErrorKindNotFound ErrorKind = "NotFound"
+	// ErrorKindUnauthorized is returned when the token is invalid or lacks permissions.
+	ErrorKindUnauthorized ErrorKind = "Unauthorized"
+	// ErrorKindRateLimited is returned when the GitHub API rate limit is exceeded.
+	ErrorKindRateLimited ErrorKind = "RateLimited"
+	// ErrorKindConflict is returned when a GitHub object already exists or
+	// cannot be modified, for example a merge conflict.
+	ErrorKindConflict ErrorKind = "Conflict"
+	// ErrorKindNoReleaseBranch is returned when a versioned release branch is not found.
+	ErrorKindNoReleaseBranch ErrorKind = "NoReleaseBranch"
+	// ErrorKindWindowClosed is returned when the latest tag of a release branch
+	// is outside of the fast-forward window.
+	ErrorKindWindowClosed ErrorKind = "WindowClosed"
+	// ErrorKindIdenticalBranches is returned when two branches are identical.
+	ErrorKindIdenticalBranches ErrorKind = "IdenticalBranches"
+	// ErrorKindNothingToMerge is returned when a merge does not create a commit.
+	ErrorKindNothingToMerge ErrorKind = "NothingToMerge"
+	// ErrorKindRepositoryMoved is returned in strict mode when a repository was
+	// renamed or transferred to another owner.
+	ErrorKindRepositoryMoved ErrorKind = "RepositoryMoved"
+	// ErrorKindVerificationFailed is returned when the result of a write
+	// does not match the expected state of the repository.
+	ErrorKindVerificationFailed ErrorKind = "VerificationFailed"
+	// ErrorKindNotAllowed is returned when a write to a repository is refused
+	// because the repository does not match --allowed-dest.
+	ErrorKindNotAllowed ErrorKind = "NotAllowed"
+	// ErrorKindNotSupported is returned when a Provider does not support an operation.
+	ErrorKindNotSupported ErrorKind = "NotSupported"
+	// ErrorKindInterrupted is returned when the process received SIGINT or SIGTERM.
+	ErrorKindInterrupted ErrorKind = "Interrupted"
+)
+
+// Error is an error with a kind. Use errors.Is with one of the Err* values,
+// or ErrorKindOf, to find the kind of an error.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+// NewError wraps err in an Error of the given kind.
+func NewError(kind ErrorKind, err error) *Error {
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Kind)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the wrapped error. It is used by errors.Cause.
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+// Is returns true if target is an *Error of the same kind.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+// Values that can be used with errors.Is to check the kind of an error.
+var (
+	ErrNotFound           = &Error{Kind: ErrorKindNotFound}
+	ErrUnauthorized       = &Error{Kind: ErrorKindUnauthorized}
+	ErrRateLimited        = &Error{Kind: ErrorKindRateLimited}
+	ErrConflict           = &Error{Kind: ErrorKindConflict}
+	ErrNoReleaseBranch    = &Error{Kind: ErrorKindNoReleaseBranch}
+	ErrWindowClosed       = &Error{Kind: ErrorKindWindowClosed}
+	ErrIdenticalBranches  = &Error{Kind: ErrorKindIdenticalBranches}
+	ErrNothingToMerge     = &Error{Kind: ErrorKindNothingToMerge}
+	ErrRepositoryMoved    = &Error{Kind: ErrorKindRepositoryMoved}
+	ErrNotAllowed         = &Error{Kind: ErrorKindNotAllowed}
+	ErrVerificationFailed = &Error{Kind: ErrorKindVerificationFailed}
+	ErrNotSupported       = &Error{Kind: ErrorKindNotSupported}
+)
+
+// ErrorKindOf returns the kind of an error. It returns ErrorKindGeneric
+// for errors without a kind and an empty kind for a nil error.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e.Kind
+	}
+	return ErrorKindGeneric
+}
+
+// wrapGitHubError wraps an error returned by the GitHub API in an Error
+// with a kind that matches the response.
+func wrapGitHubError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return err
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if stderrors.As(err, &rateLimitErr) || stderrors.As(err, &abuseErr) {
+		return NewError(ErrorKindRateLimited, err)
+	}
+	if isSecondaryRateLimit(nil, err) {
+		var respErr *github.ErrorResponse
+		stderrors.As(err, &respErr)
+		return NewError(ErrorKindRateLimited, newAPIError(err, respErr))
+	}
+
+	var respErr *github.ErrorResponse
+	if !stderrors.As(err, &respErr) || respErr.Response == nil {
+		return err
+	}
+	err = newAPIError(err, respErr)
+	switch respErr.Response.StatusCode {
+	case http.StatusNotFound:
+		return NewError(ErrorKindNotFound, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return NewError(ErrorKindUnauthorized, err)
+	case http.StatusTooManyRequests:
+		return NewError(ErrorKindRateLimited, err)
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return NewError(ErrorKindConflict, err)
+	}
+	return err
+}
+
+// NewErrorf creates an Error of the given kind with a formatted message.
+func NewErrorf(kind ErrorKind, format string, args ...interface{}) *Error {
+	return NewError(kind, errors.Errorf(format, args...))
+}
+
+// APIError is a failed GitHub API request with the details from the response
+// body, which go-github does not include in its error message, and the rate limit
+// of the token at the time of the request.
+type APIError struct {
+	Method           string
+	URL              string
+	StatusCode       int
+	Message          string
+	DocumentationURL string
+	Errors           []github.Error
+	// RateLimitRemaining is the number of remaining requests or -1 if unknown.
+	RateLimitRemaining int
+	// RateLimitReset is the time when the rate limit resets or the zero time if unknown.
+	RateLimitReset time.Time
+
+	err error
+}
+
+// newAPIError creates an APIError with the details of respErr that wraps err.
+func newAPIError(err error, respErr *github.ErrorResponse) *APIError {
+	resp := respErr.Response
+	e := &APIError{
+		StatusCode:         resp.StatusCode,
+		Message:            respErr.Message,
+		DocumentationURL:   respErr.DocumentationURL,
+		Errors:             respErr.Errors,
+		RateLimitRemaining: -1,
+		err:                err,
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		// Drop the query, which can hold secrets such as a client_secret.
+		u := *resp.Request.URL
+		u.RawQuery = ""
+		e.Method = resp.Request.Method
+		e.URL = u.String()
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining)); err == nil {
+		e.RateLimitRemaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64); err == nil {
+		e.RateLimitReset = time.Unix(reset, 0)
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	if len(e.Method) != 0 {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.URL)
+	}
+	fmt.Fprintf(&b, "%d", e.StatusCode)
+	if len(e.Message) != 0 {
+		fmt.Fprintf(&b, " %s", e.Message)
+	}
+	if len(e.Errors) != 0 {
+		details := make([]string, len(e.Errors))
+		for i, ge := range e.Errors {
+			details[i] = formatGitHubError(ge)
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, "; "))
+	}
+	if len(e.DocumentationURL) != 0 {
+		fmt.Fprintf(&b, ", see %s", e.DocumentationURL)
+	}
+	if e.RateLimitRemaining >= 0 {
+		fmt.Fprintf(&b, ", rate limit: %d requests remaining", e.RateLimitRemaining)
+		if !e.RateLimitReset.IsZero() {
+			fmt.Fprintf(&b, " until %s", e.RateLimitReset.UTC().Format(time.RFC3339))
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the wrapped go-github error.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Cause returns the wrapped go-github error. It is used by errors.Cause.
+func (e *APIError) Cause() error {
+	return e.err
+}
+
+// formatGitHubError formats a validation error from a GitHub API response.
+func formatGitHubError(e github.Error) string {
+	var fields []string
+	if len(e.Resource) != 0 {
+		fields = append(fields, "resource "+e.Resource)
+	}
+	if len(e.Field) != 0 {
+		fields = append(fields, "field "+e.Field)
+	}
+	if len(e.Code) != 0 && e.Code != "custom" {
+		fields = append(fields, "code "+e.Code)
+	}
+	if len(e.Message) != 0 {
+		fields = append(fields, e.Message)
+	}
+	return strings.Join(fields, ", ")
+}
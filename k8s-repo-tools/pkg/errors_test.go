@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+func newTestErrorResponse(status int) error {
+	return &github.ErrorResponse{
+		Response: &http.Response{StatusCode: status, Request: &http.Request{}},
+		Message:  http.StatusText(status),
+	}
+}
+
+func TestErrorKindOf(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedKind ErrorKind
+		expectedIs   error
+	}{
+		{
+			name: "nil error",
+		},
+		{
+			name:         "error without a kind",
+			err:          errors.New("foo"),
+			expectedKind: ErrorKindGeneric,
+		},
+		{
+			name:         "error with a kind",
+			err:          NewErrorf(ErrorKindWindowClosed, "foo"),
+			expectedKind: ErrorKindWindowClosed,
+			expectedIs:   ErrWindowClosed,
+		},
+		{
+			name:         "wrapped error with a kind",
+			err:          errors.Wrap(NewErrorf(ErrorKindIdenticalBranches, "foo"), "bar"),
+			expectedKind: ErrorKindIdenticalBranches,
+			expectedIs:   ErrIdenticalBranches,
+		},
+		{
+			name:         "interrupted",
+			err:          errors.Wrap(ErrInterrupted, "bar"),
+			expectedKind: ErrorKindInterrupted,
+			expectedIs:   ErrInterrupted,
+		},
+		{
+			name:         "GitHub API not found",
+			err:          wrapGitHubError(newTestErrorResponse(http.StatusNotFound)),
+			expectedKind: ErrorKindNotFound,
+			expectedIs:   ErrNotFound,
+		},
+		{
+			name:         "GitHub API forbidden",
+			err:          wrapGitHubError(newTestErrorResponse(http.StatusForbidden)),
+			expectedKind: ErrorKindUnauthorized,
+			expectedIs:   ErrUnauthorized,
+		},
+		{
+			name:         "GitHub API unprocessable entity",
+			err:          wrapGitHubError(newTestErrorResponse(http.StatusUnprocessableEntity)),
+			expectedKind: ErrorKindConflict,
+			expectedIs:   ErrConflict,
+		},
+		{
+			name:         "GitHub API rate limit",
+			err:          wrapGitHubError(&github.RateLimitError{Response: &http.Response{Request: &http.Request{}}}),
+			expectedKind: ErrorKindRateLimited,
+			expectedIs:   ErrRateLimited,
+		},
+		{
+			name:         "GitHub API server error",
+			err:          wrapGitHubError(newTestErrorResponse(http.StatusInternalServerError)),
+			expectedKind: ErrorKindGeneric,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := ErrorKindOf(tt.err); kind != tt.expectedKind {
+				t.Errorf("expected kind %q, got %q", tt.expectedKind, kind)
+			}
+			if tt.expectedIs != nil && !stderrors.Is(tt.err, tt.expectedIs) {
+				t.Errorf("expected errors.Is(%v, %v) to be true", tt.err, tt.expectedIs)
+			}
+			if tt.err != nil && stderrors.Is(tt.err, ErrNothingToMerge) {
+				t.Errorf("expected errors.Is(%v, %v) to be false", tt.err, ErrNothingToMerge)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	reqURL, _ := url.Parse("https://api.github.com/repos/org/repo/git/refs?client_secret=foo")
+	newResponse := func(header map[string]string) *http.Response {
+		h := http.Header{}
+		for k, v := range header {
+			h.Set(k, v)
+		}
+		return &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Request:    &http.Request{Method: http.MethodPost, URL: reqURL},
+			Header:     h,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedMessage string
+	}{
+		{
+			name: "message and documentation URL",
+			err: &github.ErrorResponse{
+				Response:         newResponse(nil),
+				Message:          "Reference already exists",
+				DocumentationURL: "https://docs.github.com/rest/git/refs#create-a-reference",
+			},
+			expectedMessage: "POST https://api.github.com/repos/org/repo/git/refs: 422 Reference already exists, " +
+				"see https://docs.github.com/rest/git/refs#create-a-reference",
+		},
+		{
+			name: "validation errors and rate limit",
+			err: &github.ErrorResponse{
+				Response: newResponse(map[string]string{
+					headerRateLimitRemaining: "4321",
+					headerRateLimitReset:     "1600000000",
+				}),
+				Message: "Validation Failed",
+				Errors: []github.Error{
+					{Resource: "Release", Field: "tag_name", Code: "already_exists"},
+					{Resource: "Release", Code: "custom", Message: "Published releases must have a valid tag"},
+				},
+			},
+			expectedMessage: "POST https://api.github.com/repos/org/repo/git/refs: 422 Validation Failed " +
+				"(resource Release, field tag_name, code already_exists; resource Release, Published releases must have a valid tag), " +
+				"rate limit: 4321 requests remaining until 2020-09-13T12:26:40Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapGitHubError(tt.err)
+			if err.Error() != tt.expectedMessage {
+				t.Errorf("expected message:\n%s\ngot:\n%s", tt.expectedMessage, err.Error())
+			}
+			var apiErr *APIError
+			if !stderrors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %T", err)
+			}
+			var respErr *github.ErrorResponse
+			if !stderrors.As(err, &respErr) || respErr != tt.err {
+				t.Errorf("expected the APIError to wrap the go-github error")
+			}
+			if kind := ErrorKindOf(err); kind != ErrorKindConflict {
+				t.Errorf("expected kind %q, got %q", ErrorKindConflict, kind)
+			}
+		})
+	}
+}
@@ -17,9 +17,8 @@ limitations under the License.
 package pkg
 
 import (
-	"flag"
-	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -45,6 +44,14 @@ const (
 	FlagDryRun = "dry-run"
 	// FlagForce ...
 	FlagForce = "force"
+	// FlagPlan ...
+	FlagPlan = "plan"
+	// FlagApplyPlan ...
+	FlagApplyPlan = "apply-plan"
+	// FlagYes ...
+	FlagYes = "yes"
+	// FlagPreflight ...
+	FlagPreflight = "preflight"
 	// FlagTimeout ...
 	FlagTimeout = "timeout"
 	// FlagReleaseTag ...
@@ -53,71 +60,283 @@ const (
 	FlagReleaseNotesToolPath = "release-notes-tool-path"
 	// FlagReleaseNotesPath ...
 	FlagReleaseNotesPath = "release-notes-path"
+	// FlagReleaseNotesFromPullRequests ...
+	FlagReleaseNotesFromPullRequests = "release-notes-from-pull-requests"
+	// FlagReleaseNotesLabel ...
+	FlagReleaseNotesLabel = "release-notes-label"
+	// FlagReleaseNotesMilestone ...
+	FlagReleaseNotesMilestone = "release-notes-milestone"
 	// FlagBuildCommand ...
 	FlagBuildCommand = "build-command"
 	// FlagReleaseAsset ...
 	FlagReleaseAsset = "release-asset"
+	// FlagOCIRepository ...
+	FlagOCIRepository = "oci-repository"
+	// FlagOCIUsername ...
+	FlagOCIUsername = "oci-username"
+	// FlagOCIPassword ...
+	FlagOCIPassword = "oci-password"
+	// FlagBuildWorkflow ...
+	FlagBuildWorkflow = "build-workflow"
+	// FlagBuildArtifactsDir ...
+	FlagBuildArtifactsDir = "build-artifacts-dir"
+	// FlagBuildTimeout ...
+	FlagBuildTimeout = "build-timeout"
 	// FlagTargetIssue ...
-	FlagTargetIssue = ""
+	FlagTargetIssue = "target-issue"
 	// FlagIgnorePath ...
-	FlagIgnorePath = ""
+	FlagIgnorePath = "ignore-path"
+	// FlagComparisonConfig ...
+	FlagComparisonConfig = "comparison-config"
+	// FlagCheckVulnerabilities ...
+	FlagCheckVulnerabilities = "check-vulnerabilities"
+	// FlagCheckLicenses ...
+	FlagCheckLicenses = "check-licenses"
+	// FlagGroupByNamespace ...
+	FlagGroupByNamespace = "group-by-namespace"
+	// FlagBump ...
+	FlagBump = "bump"
+	// FlagStableOnly ...
+	FlagStableOnly = "stable-only"
+	// FlagOutputFormat ...
+	FlagOutputFormat = "output-format"
+	// FlagGitDir ...
+	FlagGitDir = "git-dir"
+	// FlagList ...
+	FlagList = "list"
+	// FlagChannelDir ...
+	FlagChannelDir = "channel-dir"
+	// FlagChannelBucket ...
+	FlagChannelBucket = "channel-bucket"
+	// FlagOffset ...
+	FlagOffset = "offset"
+	// FlagStrict ...
+	FlagStrict = "strict"
+	// FlagAllowedDest ...
+	FlagAllowedDest = "allowed-dest"
+	// FlagMetricsFile ...
+	FlagMetricsFile = "metrics-file"
+	// FlagMetricsPushgateway ...
+	FlagMetricsPushgateway = "metrics-pushgateway"
+	// FlagAnnotations ...
+	FlagAnnotations = "annotations"
+	// FlagOTLPEndpoint ...
+	FlagOTLPEndpoint = "otlp-endpoint"
+	// FlagConfig ...
+	FlagConfig = "config"
+	// FlagTokenFile ...
+	FlagTokenFile = "token-file"
+	// FlagLogFormat ...
+	FlagLogFormat = "log-format"
+	// FlagVerbosity ...
+	FlagVerbosity = "v"
+	// FlagQuiet ...
+	FlagQuiet = "quiet"
+	// FlagRetries ...
+	FlagRetries = "retries"
+	// FlagRetryWait ...
+	FlagRetryWait = "retry-wait"
+	// FlagVersion ...
+	FlagVersion = "version"
+	// FlagHTTPSProxy ...
+	FlagHTTPSProxy = "https-proxy"
+	// FlagCABundle ...
+	FlagCABundle = "ca-bundle"
+	// FlagHTTPMaxIdleConns ...
+	FlagHTTPMaxIdleConns = "http-max-idle-conns"
+	// FlagHTTPMaxIdleConnsPerHost ...
+	FlagHTTPMaxIdleConnsPerHost = "http-max-idle-conns-per-host"
+	// FlagHTTPMaxConnsPerHost ...
+	FlagHTTPMaxConnsPerHost = "http-max-conns-per-host"
+	// FlagHTTPIdleConnTimeout ...
+	FlagHTTPIdleConnTimeout = "http-idle-conn-timeout"
+	// FlagHTTPDisableKeepAlives ...
+	FlagHTTPDisableKeepAlives = "http-disable-keep-alives"
+	// FlagHTTPDisableHTTP2 ...
+	FlagHTTPDisableHTTP2 = "http-disable-http2"
+	// FlagGiteaURL ...
+	FlagGiteaURL = "gitea-url"
+	// FlagGiteaToken ...
+	FlagGiteaToken = "gitea-token"
+	// FlagGitHubBaseURL ...
+	FlagGitHubBaseURL = "github-base-url"
+	// FlagGitHubUploadURL ...
+	FlagGitHubUploadURL = "github-upload-url"
+	// FlagReleaseBranch ...
+	FlagReleaseBranch = "release-branch"
+	// FlagProtectBranch ...
+	FlagProtectBranch = "protect-branch"
+	// FlagSHA ...
+	FlagSHA = "sha"
+	// FlagTargetBranch ...
+	FlagTargetBranch = "target-branch"
+	// FlagBaseBranch ...
+	FlagBaseBranch = "base-branch"
+	// FlagPullRequest ...
+	FlagPullRequest = "pull-request"
+	// FlagPullRequestNumber ...
+	FlagPullRequestNumber = "pull-request-number"
+	// FlagLabel ...
+	FlagLabel = "label"
+	// FlagLabelSpec ...
+	FlagLabelSpec = "label-spec"
+	// FlagPrune ...
+	FlagPrune = "prune"
+	// FlagAssetManifest ...
+	FlagAssetManifest = "asset-manifest"
+	// FlagSignatureCommand ...
+	FlagSignatureCommand = "signature-command"
+	// FlagKeepPatches ...
+	FlagKeepPatches = "keep-patches"
+	// FlagPreReleaseMaxAge ...
+	FlagPreReleaseMaxAge = "pre-release-max-age"
+	// FlagBranchMaxAge ...
+	FlagBranchMaxAge = "branch-max-age"
+	// FlagDeleteMerged ...
+	FlagDeleteMerged = "delete-merged"
+	// FlagBackupDir ...
+	FlagBackupDir = "backup-dir"
+	// FlagRestore ...
+	FlagRestore = "restore"
+	// FlagInput ...
+	FlagInput = "input"
+	// FlagTitle ...
+	FlagTitle = "title"
+	// FlagWebhookURL ...
+	FlagWebhookURL = "webhook-url"
+	// FlagSlackWebhookURL ...
+	FlagSlackWebhookURL = "slack-webhook-url"
+	// FlagSlackTemplate ...
+	FlagSlackTemplate = "slack-template"
+	// FlagTeamsWebhookURL ...
+	FlagTeamsWebhookURL = "teams-webhook-url"
+	// FlagEmailTo ...
+	FlagEmailTo = "email-to"
+	// FlagEmailFrom ...
+	FlagEmailFrom = "email-from"
+	// FlagSMTPServer ...
+	FlagSMTPServer = "smtp-server"
+	// FlagSMTPUsername ...
+	FlagSMTPUsername = "smtp-username"
+	// FlagSkewPolicy ...
+	FlagSkewPolicy = "skew-policy"
+	// FlagAuditPolicy ...
+	FlagAuditPolicy = "audit-policy"
+	// FlagSourceBranch ...
+	FlagSourceBranch = "source-branch"
+	// FlagDestBranch ...
+	FlagDestBranch = "dest-branch"
+	// FlagSourcePath ...
+	FlagSourcePath = "source-path"
+	// FlagDestPath ...
+	FlagDestPath = "dest-path"
+	// FlagAutobumpConfig ...
+	FlagAutobumpConfig = "autobump-config"
+	// FlagWorkflow ...
+	FlagWorkflow = "workflow"
+	// FlagMaxAge ...
+	FlagMaxAge = "max-age"
+	// FlagDownloadDir ...
+	FlagDownloadDir = "download-dir"
+	// FlagChecksumsAsset ...
+	FlagChecksumsAsset = "checksums-asset"
+	// FlagChecksumManifest ...
+	FlagChecksumManifest = "checksum-manifest"
+	// FlagCacheDir ...
+	FlagCacheDir = "cache-dir"
+	// FlagCompareSHA ...
+	FlagCompareSHA = "compare-sha"
+	// FlagForceUpdate ...
+	FlagForceUpdate = "force-update"
+	// FlagGraphQL ...
+	FlagGraphQL = "graphql"
+	// FlagRequestTimeout ...
+	FlagRequestTimeout = "request-timeout"
+	// FlagRunDeadline ...
+	FlagRunDeadline = "run-deadline"
+	// FlagParallelism ...
+	FlagParallelism = "parallelism"
+	// FlagRequireSourceRelease ...
+	FlagRequireSourceRelease = "require-source-release"
+	// FlagTagNextPreRelease ...
+	FlagTagNextPreRelease = "tag-next-pre-release"
+	// FlagMilestone ...
+	FlagMilestone = "milestone"
 )
 
-var defaultFlagDescriptions = map[string]string{
-	FlagDest:   "Destination org/repo to write tags and branches to",
-	FlagSource: "Source org/repo from which to take tags and branches",
+// CommonOptions are the flags of all tools. SetupOptions registers them
+// in addition to the flags of a tool.
+type CommonOptions struct {
+	Version                 bool          `flag:"version" usage:"Print the version of the tool and exit"`
+	Config                  string        `flag:"config" usage:"Path to a YAML file with flag values. Flags passed on the command line have precedence"`
+	Verbosity               int           `flag:"v" default:"2" usage:"Log verbosity. 0 shows only important messages, 1 adds progress details and 2 adds per-reference details"`
+	Quiet                   bool          `flag:"quiet" usage:"Do not log informational messages, so that stdout only contains the result. Warnings and errors are still written to stderr"`
+	LogFormat               string        `flag:"log-format" default:"text" usage:"Format of the log output. One of \"text\" or \"json\""`
+	HTTPSProxy              string        `flag:"https-proxy" usage:"URL of a proxy to use for all HTTP requests. By default the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used"`
+	CABundle                string        `flag:"ca-bundle" usage:"Path to a PEM file with CA certificates to trust in addition to the system CA certificates"`
+	MetricsFile             string        `flag:"metrics-file" usage:"Path to a file to write metrics of the run to in the Prometheus text format, for example for the textfile collector of the node exporter"`
+	MetricsPushgateway      string        `flag:"metrics-pushgateway" usage:"URL of a Prometheus Pushgateway to push metrics of the run to"`
+	Annotations             bool          `flag:"annotations" usage:"Write warnings and errors as GitHub Actions workflow commands and a job summary of the run to the file from GITHUB_STEP_SUMMARY"`
+	OTLPEndpoint            string        `flag:"otlp-endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"Base URL of an OpenTelemetry collector to export traces of the run to with OTLP over HTTP, such as 'http://localhost:4318'. Defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable"`
+	AllowedDest             string        `flag:"allowed-dest" usage:"Comma separated list of repositories of the format 'org/repo' that can be written to. Patterns such as 'org/*' are allowed. Writes to other repositories fail. By default all repositories can be written to"`
+	HTTPMaxIdleConns        int           `flag:"http-max-idle-conns" default:"100" usage:"Maximum number of idle HTTP connections to all hosts. 0 means no limit"`
+	HTTPMaxIdleConnsPerHost int           `flag:"http-max-idle-conns-per-host" default:"32" usage:"Maximum number of idle HTTP connections per host that are kept for reuse"`
+	HTTPMaxConnsPerHost     int           `flag:"http-max-conns-per-host" usage:"Maximum number of HTTP connections per host, including connections that are in use. 0 means no limit"`
+	HTTPIdleConnTimeout     time.Duration `flag:"http-idle-conn-timeout" default:"1m30s" usage:"Time after which idle HTTP connections are closed. 0 means no timeout"`
+	HTTPDisableKeepAlives   bool          `flag:"http-disable-keep-alives" usage:"Close every HTTP connection after a single request"`
+	HTTPDisableHTTP2        bool          `flag:"http-disable-http2" usage:"Only use HTTP/1.1, also for servers that support HTTP/2"`
 }
 
-// GetDefaultFlagDescriptions ...
-func GetDefaultFlagDescriptions() map[string]string {
-	result := map[string]string{}
-	for k, v := range defaultFlagDescriptions {
-		result[k] = v
-	}
-	return result
+// TokenOptions are the flags of tools that call the GitHub API.
+type TokenOptions struct {
+	Token           string        `flag:"token" value:"token" env:"GITHUB_TOKEN" validate:"required,token" usage:"Token to use for authentication with the GitHub API. Write permissions are required for the destination repository. Can also be set with the GITHUB_TOKEN environment variable. Multiple instances of the flag are allowed, in which case the next token is used when the rate limit of the current token is exhausted"`
+	TokenFile       string        `flag:"token-file" usage:"Path to a file containing the token. '-' reads the token from stdin. Cannot be used together with \"token\""`
+	Retries         int           `flag:"retries" default:"3" usage:"Number of times to retry a GitHub API call that failed with a network error, a server error or because of rate limiting"`
+	RetryWait       time.Duration `flag:"retry-wait" default:"2s" usage:"Wait time before the first retry of a GitHub API call. The wait time doubles after every retry"`
+	GitHubBaseURL   string        `flag:"github-base-url" value:"url" usage:"Base URL of a GitHub Enterprise Server instance (e.g. 'https://github.example.com/'). By default github.com is used"`
+	GitHubUploadURL string        `flag:"github-upload-url" value:"url" usage:"Upload URL of a GitHub Enterprise Server instance. Defaults to the value of \"github-base-url\""`
 }
 
-// SetupFlags ...
-func SetupFlags(d *Data, fs *flag.FlagSet, flags []string, flagDescriptions map[string]string) {
-	if flagDescriptions == nil {
-		flagDescriptions = defaultFlagDescriptions
-	}
-	for _, f := range flags {
-		switch f {
-		case FlagDest:
-			fs.StringVar(&d.Dest, FlagDest, "", flagDescriptions[FlagDest])
-		case FlagSource:
-			fs.StringVar(&d.Source, FlagSource, "", flagDescriptions[FlagSource])
-		case FlagMinVersion:
-			fs.StringVar(&d.MinVersion, FlagMinVersion, "", "All versions for tags and branches older than this SemVer will be ignored")
-		case FlagToken:
-			fs.StringVar(&d.Token, FlagToken, "", "Token to use for authentication with the GitHub API. Write permissions are required for the destination repository")
-		case FlagBranch:
-			fs.StringVar(&d.Branch, FlagBranch, "", "Branch to use in the format \"prefixMAJOR.MINOR\"")
-		case FlagPrefixBranch:
-			fs.StringVar(&d.PrefixBranch, FlagPrefixBranch, PrefixBranch, "Branch name prefix. Expected format is \"prefixMAJOR.MINOR\"")
-		case FlagOutput:
-			fs.StringVar(&d.Output, FlagOutput, "", "Path to a file that will be written with a list of new tags and branches as GitHub API JSON objects")
-		case FlagTimeout:
-			fs.DurationVar(&d.Timeout, FlagTimeout, time.Second*20, "Timeout for client connections to remote servers")
-		case FlagDryRun:
-			fs.BoolVar(&d.DryRun, FlagDryRun, true, fmt.Sprintf("In %s mode repository writing operations are disabled", PrefixDryRun))
-		case FlagForce:
-			fs.BoolVar(&d.Force, FlagForce, false, "Skip the confirmation prompt before writing to the destination repository")
-		case FlagReleaseTag:
-			fs.StringVar(&d.ReleaseTag, FlagReleaseTag, "", "A SemVer tag from which to create a release")
-		case FlagReleaseNotesToolPath:
-			fs.StringVar(&d.ReleaseNotesToolPath, FlagReleaseNotesToolPath, "", "Path to the release notes tool binary")
-		case FlagReleaseNotesPath:
-			fs.StringVar(&d.ReleaseNotesPath, FlagReleaseNotesPath, "", fmt.Sprintf("Path to a text file containing release notes. Overrides the usage of %q", FlagReleaseNotesToolPath))
-		case FlagBuildCommand:
-			fs.StringVar(&d.BuildCommand, FlagBuildCommand, "", "A command to execute for build the release assets")
-		case FlagReleaseAsset:
-			fs.Var(&d.ReleaseAssets, FlagReleaseAsset, "A release asset to upload to the GitHub release. Must be formatted as 'assetName=filePath'. Multiple instances of the flag are allowed")
-		case FlagIgnorePath:
-			fs.Var(&d.IgnorePaths, FlagIgnorePath, "A dependency path to ignore from the source Gomod (e.g. 'Golang', 'k8s.io/klog'). Multiple instances of the flag are allowed")
-		}
-	}
+// TimeoutOptions are the flags of tools that send requests to remote servers.
+type TimeoutOptions struct {
+	Timeout     time.Duration `flag:"request-timeout" deprecated:"timeout" default:"20s" usage:"Timeout for a single request to a remote server. Uploading and downloading release assets uses a timeout of at least 30m0s"`
+	RunDeadline time.Duration `flag:"run-deadline" usage:"Maximum duration of the whole run. When it is exceeded the tool stops like on SIGINT, after the current write operation. 0 means no deadline"`
+}
+
+// DryRunOptions are the flags of tools that write to repositories.
+type DryRunOptions struct {
+	DryRun        bool   `flag:"dry-run" default:"true" usage:"In DRY-RUN mode repository writing operations are disabled"`
+	PlanFile      string `flag:"plan" usage:"Path to a JSON file where to write the plan of all write operations in DRY-RUN mode"`
+	ApplyPlanFile string `flag:"apply-plan" usage:"Path to a plan written with \"plan\". Only the write operations from the plan are performed"`
+	Preflight     bool   `flag:"preflight" usage:"Verify that the token has push access to the destination repository before performing any writes"`
+}
+
+// ForceOptions are the flags of tools that ask for confirmation before
+// writing to repositories.
+type ForceOptions struct {
+	Force bool `flag:"force" value:"force" alias:"yes" usage:"Skip the confirmation prompt before writing to the destination repository"`
+}
+
+// GiteaOptions are the flags of tools that can write to a repository on a
+// Gitea server.
+type GiteaOptions struct {
+	GiteaURL   string `flag:"gitea-url" value:"url" usage:"URL of a Gitea server (e.g. 'https://gitea.example.com/') that hosts the destination repository. The source repository is always read from GitHub. Requires \"gitea-token\""`
+	GiteaToken string `flag:"gitea-token" validate:"requiredif=gitea-url" usage:"Token to use for authentication with the Gitea API. Write permissions are required for the destination repository"`
+}
+
+// PullRequestNotesOptions are the flags of tools that can generate release
+// notes from the titles of merged pull requests.
+type PullRequestNotesOptions struct {
+	PullRequestNotes     bool        `flag:"release-notes-from-pull-requests" usage:"Generate the release notes from the titles of the pull requests that were merged in the branch of the release since the previous release. Can be used instead of \"release-notes-tool-path\" for repositories that the release notes tool does not support"`
+	PullRequestLabels    multiString `flag:"release-notes-label" usage:"Only include pull requests with this label in the release notes of \"release-notes-from-pull-requests\". Multiple instances of the flag are allowed"`
+	PullRequestMilestone string      `flag:"release-notes-milestone" usage:"Only include pull requests of this milestone in the release notes of \"release-notes-from-pull-requests\""`
+}
+
+// SlackOptions are the flags of tools that send a Slack notification with
+// the result of a run.
+type SlackOptions struct {
+	SlackWebhookURL string `flag:"slack-webhook-url" value:"url" usage:"URL of a Slack incoming webhook"`
+	SlackTemplate   string `flag:"slack-template" usage:"Path or URL to a Go template for the text of the Slack message that is sent to \"slack-webhook-url\". See the README for the available fields"`
 }
 
 // ValidateRepo checks if a repository string is of the format 'org/repo'.
@@ -130,6 +349,55 @@ func ValidateRepo(option, repo string) error {
 	return nil
 }
 
+// SplitRepos splits a comma separated list of repositories of the format 'org/repo'.
+// Empty items are skipped.
+func SplitRepos(value string) []string {
+	repos := []string{}
+	for _, repo := range strings.Split(value, ",") {
+		if repo = strings.TrimSpace(repo); len(repo) != 0 {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+// ValidateSingleRepo checks if a list of repositories has a single repository
+// of the format 'org/repo'. It is used by the tools that do not accept multiple
+// repositories for an option.
+func ValidateSingleRepo(option string, repos []string) error {
+	switch len(repos) {
+	case 0:
+		return errors.Errorf("the option %q cannot be empty", option)
+	case 1:
+		return ValidateRepo(option, repos[0])
+	}
+	return errors.Errorf("the option %q only accepts a single repository", option)
+}
+
+// ValidateRepos checks if a list of repositories has at least one repository
+// and if all repositories are of the format 'org/repo'.
+func ValidateRepos(option string, repos []string) error {
+	if len(repos) == 0 {
+		return errors.Errorf("the option %q cannot be empty", option)
+	}
+	for _, repo := range repos {
+		if err := ValidateRepo(option, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateParallelism checks if --force is passed when repositories are
+// processed in parallel, since the confirmation prompts of multiple
+// repositories would be interleaved.
+func ValidateParallelism(d *Data) error {
+	if d.Parallelism > 1 && !d.Force {
+		return errors.Errorf("the option %q requires %q if greater than 1", FlagParallelism, FlagForce)
+	}
+	return nil
+}
+
 // ValidateTargetIssue validates if the given issue is of format 'org/repo#issue'
 func ValidateTargetIssue(option, issue string) error {
 	const orgRepoIssue = `[A-Za-z0-9_.-]+\/[A-Za-z0-9_.-]+#[1-9][0-9]+`
@@ -141,13 +409,46 @@ func ValidateTargetIssue(option, issue string) error {
 	return nil
 }
 
+// tokenFormats are the formats of GitHub tokens:
+// - a 40 character HEX string with an optional version prefix (legacy tokens)
+// - "ghp_", "gho_", "ghu_", "ghs_" or "ghr_" followed by at least 36 alphanumeric characters
+// (personal access tokens, OAuth tokens, GitHub App user, installation and refresh tokens)
+// - "github_pat_" followed by 22 and 59 alphanumeric characters separated by "_"
+// (fine-grained personal access tokens)
+var tokenFormats = []*regexp.Regexp{
+	regexp.MustCompile(`^(v[0-9]\.)?[0-9a-f]{40}$`),
+	regexp.MustCompile(`^gh[pousr]_[A-Za-z0-9]{36,}$`),
+	regexp.MustCompile(`^github_pat_[A-Za-z0-9]{22}_[A-Za-z0-9]{59}$`),
+}
+
 // ValidateToken checks if a GitHub token is valid.
 func ValidateToken(option, token string) error {
-	const tokenFormat = `(v[0-9]\.)?[0-9a-f]{40}`
-	var regexpTokenFormat = regexp.MustCompile(tokenFormat)
-	if !regexpTokenFormat.MatchString(token) {
-		return errors.Errorf("the option %q must be a 40 character HEX string "+
-			"with an optional version prefix: %s", option, tokenFormat)
+	for _, f := range tokenFormats {
+		if f.MatchString(token) {
+			return nil
+		}
+	}
+	return errors.Errorf("the option %q must be a GitHub token such as a personal access token "+
+		"('ghp_...' or 'github_pat_...'), an installation token ('ghs_...') or a 40 character HEX string "+
+		"with an optional version prefix", option)
+}
+
+// ValidateTokens checks if a list of GitHub tokens is valid.
+func ValidateTokens(option string, tokens []string) error {
+	for i, token := range tokens {
+		if err := ValidateToken(option, token); err != nil {
+			return errors.Wrapf(err, "invalid token at position %d", i+1)
+		}
+	}
+	return nil
+}
+
+// ValidateSHA checks if a string is a full SHA of a commit.
+func ValidateSHA(option, sha string) error {
+	const shaFormat = `^[0-9a-f]{40}$`
+	var regexpSHAFormat = regexp.MustCompile(shaFormat)
+	if !regexpSHAFormat.MatchString(sha) {
+		return errors.Errorf("the option %q must be a full commit SHA: %s", option, shaFormat)
 	}
 	return nil
 }
@@ -160,6 +461,18 @@ func ValidateEmptyOption(option, value string) error {
 	return nil
 }
 
+// ValidateSingleOption checks if a list option has a single non-empty value.
+// It is used for the list options that hold a path or URL instead of a repository.
+func ValidateSingleOption(option string, values []string) error {
+	switch len(values) {
+	case 0:
+		return errors.Errorf("the option %q cannot be empty", option)
+	case 1:
+		return ValidateEmptyOption(option, values[0])
+	}
+	return errors.Errorf("the option %q only accepts a single value", option)
+}
+
 // ValidateReleaseTag validates if a tag is SemVer.
 func ValidateReleaseTag(option, value string) error {
 	if _, err := version.ParseSemantic(value); err != nil {
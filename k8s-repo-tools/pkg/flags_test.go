@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		expectedError bool
+	}{
+		{
+			name:  "valid: HEX token",
+			token: "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab",
+		},
+		{
+			name:  "valid: version prefixed HEX token",
+			token: "v1.282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab",
+		},
+		{
+			name:  "valid: personal access token",
+			token: "ghp_" + strings.Repeat("aB3", 12),
+		},
+		{
+			name:  "valid: OAuth token",
+			token: "gho_" + strings.Repeat("aB3", 12),
+		},
+		{
+			name:  "valid: GitHub App user token",
+			token: "ghu_" + strings.Repeat("aB3", 12),
+		},
+		{
+			name:  "valid: installation token",
+			token: "ghs_" + strings.Repeat("aB3", 12),
+		},
+		{
+			name:  "valid: refresh token",
+			token: "ghr_" + strings.Repeat("aB3", 12),
+		},
+		{
+			name:  "valid: longer installation token",
+			token: "ghs_" + strings.Repeat("aB3", 20),
+		},
+		{
+			name:  "valid: fine-grained personal access token",
+			token: "github_pat_" + strings.Repeat("a", 22) + "_" + strings.Repeat("B", 59),
+		},
+		{
+			name:          "invalid: empty token",
+			token:         "",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: short HEX token",
+			token:         "282ef40c7d38cbfa",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: HEX token with an extra character",
+			token:         "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71abc",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: HEX token with upper case characters",
+			token:         "282EF40C7D38CBFAFE7D6EBE91CDFBBCBE5D71AB",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unknown prefix",
+			token:         "ghx_" + strings.Repeat("aB3", 12),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: truncated personal access token",
+			token:         "ghp_" + strings.Repeat("aB3", 11),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: personal access token with a trailing space",
+			token:         "ghp_" + strings.Repeat("aB3", 12) + " ",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: personal access token with a quote",
+			token:         "'ghp_" + strings.Repeat("aB3", 12) + "'",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: truncated fine-grained personal access token",
+			token:         "github_pat_" + strings.Repeat("a", 22) + "_" + strings.Repeat("B", 58),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: fine-grained personal access token without the separator",
+			token:         "github_pat_" + strings.Repeat("a", 82),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToken(FlagToken, tt.token)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestValidateRepo(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedError bool
+	}{
+		{
+			name:  "valid: repository",
+			value: "org/repo",
+		},
+		{
+			name:          "invalid: malformed repository",
+			value:         "repo",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRepo("dest", tt.value)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestValidateRepos(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedRepos []string
+		expectedError bool
+	}{
+		{
+			name:          "valid: single repository",
+			value:         "org/repo",
+			expectedRepos: []string{"org/repo"},
+		},
+		{
+			name:          "valid: multiple repositories with spaces and empty items",
+			value:         "org/a, org/b,,",
+			expectedRepos: []string{"org/a", "org/b"},
+		},
+		{
+			name:          "invalid: empty value",
+			value:         " , ",
+			expectedRepos: []string{},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed repository",
+			value:         "org/a,b",
+			expectedRepos: []string{"org/a", "b"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRepos("dest", SplitRepos(tt.value))
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if repos := SplitRepos(tt.value); !reflect.DeepEqual(repos, tt.expectedRepos) {
+				t.Errorf("expected repos %v, got %v", tt.expectedRepos, repos)
+			}
+		})
+	}
+}
+
+func TestValidateSingleRepo(t *testing.T) {
+	tests := []struct {
+		name          string
+		repos         []string
+		expectedError bool
+	}{
+		{
+			name:  "valid: single repository",
+			repos: []string{"org/repo"},
+		},
+		{
+			name:          "invalid: no repositories",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed repository",
+			repos:         []string{"repo"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: multiple repositories",
+			repos:         []string{"org/a", "org/b"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSingleRepo("dest", tt.repos)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestValidateSingleOption(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []string
+		expectedError bool
+	}{
+		{
+			name:   "valid: single value",
+			values: []string{"./go.mod"},
+		},
+		{
+			name:          "invalid: no values",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: empty value",
+			values:        []string{""},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: multiple values",
+			values:        []string{"./a/go.mod", "./b/go.mod"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSingleOption("source", tt.values)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// giteaProvider is the Provider for a Gitea server. It supports syncing refs
+// and creating releases. Forcing refs to other commits, comparing and merging
+// branches are not supported. Errors of the Gitea API have the same kinds as
+// errors of the GitHub API.
+type giteaProvider struct {
+	d *Data
+}
+
+var _ Provider = &giteaProvider{}
+
+func (p *giteaProvider) Name() string {
+	return "Gitea"
+}
+
+func (p *giteaProvider) RefURL(repo, ref string) string {
+	base := strings.TrimSuffix(p.d.GiteaURL, "/") + "/" + repo
+	if strings.HasPrefix(ref, "refs/tags/") {
+		return base + "/src/tag/" + strings.TrimPrefix(ref, "refs/tags/")
+	}
+	return base + "/src/branch/" + strings.TrimPrefix(ref, "refs/heads/")
+}
+
+func (p *giteaProvider) CommitURL(repo, sha string) string {
+	return strings.TrimSuffix(p.d.GiteaURL, "/") + "/" + repo + "/commit/" + sha
+}
+
+// CompareURL compares the commits in repo, since Gitea cannot compare commits
+// of different repositories.
+func (p *giteaProvider) CompareURL(repo, base, headRepo, head string) string {
+	return strings.TrimSuffix(p.d.GiteaURL, "/") + "/" + repo + "/compare/" + base + "..." + head
+}
+
+// giteaRepoPath returns the API path of a repository.
+func giteaRepoPath(repo string) string {
+	ownerRepo := strings.Split(repo, "/")
+	return fmt.Sprintf("repos/%s/%s", url.PathEscape(ownerRepo[0]), url.PathEscape(ownerRepo[1]))
+}
+
+// request sends a request to the Gitea API and decodes the JSON response into
+// out if it is not nil. Failed requests return a *github.ErrorResponse.
+func (p *giteaProvider) request(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) (*github.Response, error) {
+	u := strings.TrimSuffix(p.d.GiteaURL, "/") + "/api/v1/" + path
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if len(contentType) != 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(p.d.GiteaToken) != 0 {
+		req.Header.Set("Authorization", "token "+p.d.GiteaToken)
+	}
+	resp, err := NewHTTPClient(0).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	r := &github.Response{Response: resp}
+	if err := github.CheckResponse(resp); err != nil {
+		return r, err
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return r, errors.Wrapf(err, "could not parse the response of %s %s", method, u)
+		}
+	}
+	return r, nil
+}
+
+// requestJSON is like request, but sends in as a JSON body if it is not nil.
+func (p *giteaProvider) requestJSON(ctx context.Context, method, path string, in, out interface{}) (*github.Response, error) {
+	if in == nil {
+		return p.request(ctx, method, path, "", nil, out)
+	}
+	buf, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	return p.request(ctx, method, path, "application/json", bytes.NewReader(buf), out)
+}
+
+func (p *giteaProvider) PreflightToken(repos ...string) error {
+	Logf("verifying the permissions of the token")
+	var user struct {
+		Login string `json:"login"`
+	}
+	err := retry(p.d, "getting the authenticated user", func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodGet, "user", nil, &user)
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not authenticate with the token")
+	}
+	Logf("the token belongs to user %q", user.Login)
+
+	for _, repo := range repos {
+		var r github.Repository
+		err := retry(p.d, fmt.Sprintf("getting repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			return p.requestJSON(ctx, http.MethodGet, giteaRepoPath(repo), nil, &r)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not get repository %q with the token", repo)
+		}
+		if !r.GetPermissions()["push"] {
+			return NewErrorf(ErrorKindUnauthorized, "the token does not have push access to repository %q", repo)
+		}
+		V(1).Logf("the token has push access to repository %q", repo)
+	}
+	return nil
+}
+
+// getRefs obtains the refs of a repository that start with the prefix refs.
+func (p *giteaProvider) getRefs(repo, refs string) ([]*github.Reference, error) {
+	Logf("getting %q from repository %q", refs, repo)
+	var r []*github.Reference
+	var resp *github.Response
+	err := retry(p.d, fmt.Sprintf("getting %q from repository %q", refs, repo), func(ctx context.Context) (*github.Response, error) {
+		var err error
+		resp, err = p.requestJSON(ctx, http.MethodGet, giteaRepoPath(repo)+"/git/"+refs, nil, &r)
+		return resp, err
+	})
+	// handle not found by returning an empty list
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return []*github.Reference{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Gitea also returns refs that only share a prefix of the name.
+	result := []*github.Reference{}
+	for _, ref := range r {
+		if strings.HasPrefix(ref.GetRef(), refs+"/") {
+			result = append(result, ref)
+		}
+	}
+	return result, nil
+}
+
+func (p *giteaProvider) GetTags(repo string) ([]*github.Reference, error) {
+	return p.getRefs(repo, "refs/tags")
+}
+
+func (p *giteaProvider) GetBranches(repo string) ([]*github.Reference, error) {
+	return p.getRefs(repo, "refs/heads")
+}
+
+func (p *giteaProvider) GetTagsAndBranches(repo string) ([]*github.Reference, []*github.Reference, error) {
+	var tags, branches []*github.Reference
+	err := RunConcurrently(
+		func() (err error) {
+			tags, err = p.GetTags(repo)
+			return err
+		},
+		func() (err error) {
+			branches, err = p.GetBranches(repo)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tags, branches, nil
+}
+
+func (p *giteaProvider) GetRef(repo, ref string) (*github.Reference, error) {
+	Logf("getting ref %q from repository %q", ref, repo)
+	var r []*github.Reference
+	err := retry(p.d, fmt.Sprintf("getting ref %q", ref), func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodGet, giteaRepoPath(repo)+"/git/"+ref, nil, &r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range r {
+		if existing.GetRef() == ref {
+			return existing, nil
+		}
+	}
+	return nil, NewErrorf(ErrorKindNotFound, "ref %q not found in repository %q", ref, repo)
+}
+
+// giteaCreateRefRequest returns the API path and body for creating a branch or a tag.
+func giteaCreateRefRequest(repo, ref, sha string) (string, interface{}, error) {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return giteaRepoPath(repo) + "/branches", map[string]string{
+			"new_branch_name": strings.TrimPrefix(ref, "refs/heads/"),
+			"old_ref_name":    sha,
+		}, nil
+	case strings.HasPrefix(ref, "refs/tags/"):
+		return giteaRepoPath(repo) + "/tags", map[string]string{
+			"tag_name": strings.TrimPrefix(ref, "refs/tags/"),
+			"target":   sha,
+		}, nil
+	}
+	return "", nil, NewErrorf(ErrorKindNotSupported, "creating ref %q is not supported by Gitea, only branches and tags", ref)
+}
+
+func (p *giteaProvider) CreateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	newRef := github.Reference{
+		Ref: github.String(ref),
+		Object: &github.GitObject{
+			SHA: github.String(sha),
+		},
+	}
+	if dryRun {
+		Logf("%s: would create ref %q from commit %q in repository %q", PrefixDryRun, ref, sha, repo)
+		p.d.recordPlanStep(PlanStep{Action: PlanActionCreateRef, Repo: repo, Ref: ref, SHA: sha})
+		return &newRef, nil
+	}
+	if err := checkAllowedDest(SplitRepos(p.d.AllowedDest), repo); err != nil {
+		return nil, err
+	}
+	path, body, err := giteaCreateRefRequest(repo, ref, sha)
+	if err != nil {
+		return nil, err
+	}
+	Logf("creating ref %q from commit %q in repository %q", ref, sha, repo)
+	// Before retrying check if the ref was already created from the same commit.
+	check := func() bool {
+		existing, err := p.GetRef(repo, ref)
+		return err == nil && existing.GetObject().GetSHA() == sha
+	}
+	err = retryWrite(p.d, fmt.Sprintf("creating ref %q", ref), check, func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodPost, path, body, nil)
+	})
+	if err != nil && stderrors.Is(err, ErrConflict) {
+		// A ref that already exists at the same commit is not an error so that re-runs converge.
+		existing, getErr := p.GetRef(repo, ref)
+		if getErr != nil {
+			return nil, err
+		}
+		if existing.GetObject().GetSHA() != sha {
+			return nil, NewErrorf(ErrorKindConflict, "ref %q already exists in repository %q at commit %q instead of %q",
+				ref, repo, existing.GetObject().GetSHA(), sha)
+		}
+		Logf("ref %q already exists at commit %q in repository %q", ref, sha, repo)
+		return existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordRefsCreated(1)
+	recordChange("created ref `%s` from commit `%s` in repository `%s`", ref, sha, repo)
+	return &newRef, nil
+}
+
+func (p *giteaProvider) CreateRefs(repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	return createRefs(p, p.d, repo, refsDest, refs)
+}
+
+func (p *giteaProvider) UpdateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	return nil, NewErrorf(ErrorKindNotSupported, "forcing ref %q to another commit is not supported by Gitea", ref)
+}
+
+func (p *giteaProvider) DeleteRef(repo, ref string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would delete ref %q from repository %q", PrefixDryRun, ref, repo)
+		p.d.recordPlanStep(PlanStep{Action: PlanActionDeleteRef, Repo: repo, Ref: ref})
+		return nil
+	}
+	if err := checkAllowedDest(SplitRepos(p.d.AllowedDest), repo); err != nil {
+		return err
+	}
+	var path string
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		path = giteaRepoPath(repo) + "/branches/" + url.PathEscape(strings.TrimPrefix(ref, "refs/heads/"))
+	case strings.HasPrefix(ref, "refs/tags/"):
+		path = giteaRepoPath(repo) + "/tags/" + url.PathEscape(strings.TrimPrefix(ref, "refs/tags/"))
+	default:
+		return NewErrorf(ErrorKindNotSupported, "deleting ref %q is not supported by Gitea, only branches and tags", ref)
+	}
+	Logf("deleting ref %q from repository %q", ref, repo)
+	// Before retrying check if the ref was already deleted.
+	check := func() bool {
+		_, err := p.GetRef(repo, ref)
+		return stderrors.Is(err, ErrNotFound)
+	}
+	err := retryWrite(p.d, fmt.Sprintf("deleting ref %q", ref), check, func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodDelete, path, nil, nil)
+	})
+	if err == nil {
+		recordChange("deleted ref `%s` from repository `%s`", ref, repo)
+	}
+	return err
+}
+
+func (p *giteaProvider) CompareBranches(repo, base, head string) (*github.CommitsComparison, error) {
+	return nil, NewErrorf(ErrorKindNotSupported, "comparing %q and %q is not supported by Gitea", base, head)
+}
+
+func (p *giteaProvider) MergeBranch(repo, base, head, commitMessage string) (*github.RepositoryCommit, *github.Response, error) {
+	return nil, nil, NewErrorf(ErrorKindNotSupported, "merging %q into %q is not supported by Gitea", head, base)
+}
+
+func (p *giteaProvider) GetRelease(repo, tag string) (*github.RepositoryRelease, error) {
+	V(1).Logf("getting release from tag %q in repository %q", tag, repo)
+	release := &github.RepositoryRelease{}
+	err := retry(p.d, fmt.Sprintf("getting release from tag %q", tag), func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodGet, giteaRepoPath(repo)+"/releases/tags/"+url.PathEscape(tag), nil, release)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+func (p *giteaProvider) GetCreateRelease(repo, tag, body string, dryRun bool) (*github.RepositoryRelease, error) {
+	Logf("checking if tag %q exists", tag)
+	if _, err := p.GetRef(repo, "refs/tags/"+tag); err != nil {
+		return nil, err
+	}
+	release, err := p.GetRelease(repo, tag)
+	if err == nil {
+		return release, nil
+	}
+	// Don't treat "not found" as an error
+	if !stderrors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	// Check if this is a pre-release
+	v, err := version.ParseSemantic(tag)
+	if err != nil {
+		return nil, err
+	}
+	newRelease := &github.RepositoryRelease{
+		TagName:    github.String(tag),
+		Name:       github.String(tag),
+		Body:       github.String(body),
+		Draft:      github.Bool(false),
+		Prerelease: github.Bool(len(v.PreRelease()) > 0),
+	}
+	if dryRun {
+		Logf("%s: would create a release for tag %q in repository %q", PrefixDryRun, tag, repo)
+		p.d.recordPlanStep(PlanStep{Action: PlanActionCreateRelease, Repo: repo, Tag: tag, Body: body})
+		return newRelease, nil
+	}
+	if err := checkAllowedDest(SplitRepos(p.d.AllowedDest), repo); err != nil {
+		return nil, err
+	}
+
+	Logf("creating release for tag %q in repository %q", tag, repo)
+	release = &github.RepositoryRelease{}
+	// Before retrying check if the release was already created.
+	check := func() bool {
+		existing, err := p.GetRelease(repo, tag)
+		if err != nil {
+			return false
+		}
+		release = existing
+		return true
+	}
+	err = retryWrite(p.d, fmt.Sprintf("creating release for tag %q", tag), check, func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodPost, giteaRepoPath(repo)+"/releases", newRelease, release)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordChange("created release for tag `%s` in repository `%s`", tag, repo)
+	return release, nil
+}
+
+// getReleaseAssets obtains the assets of a release.
+func (p *giteaProvider) getReleaseAssets(repo string, id int64) ([]*github.ReleaseAsset, error) {
+	var assets []*github.ReleaseAsset
+	err := retry(p.d, fmt.Sprintf("getting the assets of release %d", id), func(ctx context.Context) (*github.Response, error) {
+		return p.requestJSON(ctx, http.MethodGet, fmt.Sprintf("%s/releases/%d/assets", giteaRepoPath(repo), id), nil, &assets)
+	})
+	return assets, err
+}
+
+func (p *giteaProvider) UploadReleaseAssets(repo string, release *github.RepositoryRelease, am assetMap, dryRun bool) ([]*github.ReleaseAsset, error) {
+	assets, names := newReleaseAssetNames(release, am)
+	if !dryRun {
+		if err := checkAllowedDest(SplitRepos(p.d.AllowedDest), repo); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range names {
+		v := am[k]
+		if dryRun {
+			Logf("%s: would upload asset %q from path %q", PrefixDryRun, k, v)
+			p.d.recordPlanStep(PlanStep{Action: PlanActionUploadAsset, Repo: repo, Tag: release.GetTagName(), Asset: k, Path: v})
+			assets = append(assets, &github.ReleaseAsset{Name: github.String(k)})
+			continue
+		}
+
+		// Stop before the next upload if the process was interrupted.
+		if p.d.Interrupted() {
+			return assets, ErrInterrupted
+		}
+
+		file, err := os.Open(v)
+		if err != nil {
+			return nil, err
+		}
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if stat.IsDir() {
+			file.Close()
+			return nil, errors.Errorf("the asset path %q is a directory", v)
+		}
+
+		// Upload the file as a multipart form, which Gitea requires.
+		Logf("uploading asset %q from path %q (%s)", k, v, formatBytes(stat.Size()))
+		path := fmt.Sprintf("%s/releases/%d/assets?name=%s", giteaRepoPath(repo), release.GetID(), url.QueryEscape(k))
+		start := time.Now()
+		var releaseAsset *github.ReleaseAsset
+		// Before retrying check if the asset was already uploaded.
+		check := func() bool {
+			existing, err := p.getReleaseAssets(repo, release.GetID())
+			if err != nil {
+				return false
+			}
+			for _, a := range existing {
+				if a.GetName() == k {
+					releaseAsset = a
+					return true
+				}
+			}
+			return false
+		}
+		err = retryTransfer(p.d, fmt.Sprintf("uploading asset %q", k), true, check, func(ctx context.Context) (*github.Response, error) {
+			// Rewind the file in case this is a retry.
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			// Stream the file and log the progress of large uploads.
+			body := newProgressReader(file, fmt.Sprintf("uploading asset %q", k), stat.Size())
+			pr, pw := io.Pipe()
+			mw := multipart.NewWriter(pw)
+			go func() {
+				part, err := mw.CreateFormFile("attachment", k)
+				if err == nil {
+					_, err = io.Copy(part, body)
+				}
+				if err == nil {
+					err = mw.Close()
+				}
+				pw.CloseWithError(err)
+			}()
+			releaseAsset = &github.ReleaseAsset{}
+			resp, err := p.request(ctx, http.MethodPost, path, mw.FormDataContentType(), pr, releaseAsset)
+			pr.Close()
+			return resp, err
+		})
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		p.d.recordAssetUpload(AssetUpload{Name: k, Size: stat.Size(), Duration: time.Since(start)})
+		assets = append(assets, releaseAsset)
+	}
+	return assets, nil
+}
+
+// ValidateGiteaOptions validates the options of a Gitea destination repository
+// that depend on other options. The token is validated by GiteaOptions.
+func ValidateGiteaOptions(d *Data) error {
+	if len(d.GiteaURL) != 0 && d.ForceUpdate {
+		return errors.Errorf("the option %q is not supported with %q", FlagForceUpdate, FlagGiteaURL)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// fakeGitea is a minimal Gitea API server with refs, releases and assets.
+type fakeGitea struct {
+	sync.Mutex
+	refs     []*github.Reference
+	releases []*github.RepositoryRelease
+	uploads  map[string]string
+}
+
+func (g *fakeGitea) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.Lock()
+	defer g.Unlock()
+	reply := func(status int, v interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	if req.Header.Get("Authorization") != "token gitea-token" {
+		reply(http.StatusUnauthorized, map[string]string{"message": "unauthorized"})
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1/repos/org/repo")
+	switch {
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "/git/"):
+		var refs []*github.Reference
+		for _, ref := range g.refs {
+			if strings.HasPrefix(ref.GetRef(), strings.TrimPrefix(path, "/git/")) {
+				refs = append(refs, ref)
+			}
+		}
+		if len(refs) == 0 {
+			reply(http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		reply(http.StatusOK, refs)
+	case req.Method == http.MethodPost && (path == "/branches" || path == "/tags"):
+		var body map[string]string
+		json.NewDecoder(req.Body).Decode(&body)
+		ref, sha := "refs/heads/"+body["new_branch_name"], body["old_ref_name"]
+		if path == "/tags" {
+			ref, sha = "refs/tags/"+body["tag_name"], body["target"]
+		}
+		for _, existing := range g.refs {
+			if existing.GetRef() == ref {
+				reply(http.StatusConflict, map[string]string{"message": "already exists"})
+				return
+			}
+		}
+		g.refs = append(g.refs, newTestRef(ref, sha, "commit"))
+		reply(http.StatusCreated, map[string]string{})
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "/releases/tags/"):
+		for _, r := range g.releases {
+			if r.GetTagName() == strings.TrimPrefix(path, "/releases/tags/") {
+				reply(http.StatusOK, r)
+				return
+			}
+		}
+		reply(http.StatusNotFound, map[string]string{"message": "not found"})
+	case req.Method == http.MethodPost && path == "/releases":
+		r := &github.RepositoryRelease{}
+		json.NewDecoder(req.Body).Decode(r)
+		r.ID = github.Int64(int64(len(g.releases) + 1))
+		g.releases = append(g.releases, r)
+		reply(http.StatusCreated, r)
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/assets"):
+		file, _, err := req.FormFile("attachment")
+		if err != nil {
+			reply(http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		name := req.URL.Query().Get("name")
+		g.uploads[name] = string(data)
+		reply(http.StatusCreated, &github.ReleaseAsset{ID: github.Int64(1), Name: github.String(name)})
+	default:
+		reply(http.StatusNotFound, map[string]string{"message": "not found"})
+	}
+}
+
+func TestGiteaProviderRefs(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	g := &fakeGitea{
+		refs: []*github.Reference{
+			newTestRef("refs/heads/master", "sha0", "commit"),
+			newTestRef("refs/heads/master-old", "sha1", "commit"),
+			newTestRef("refs/tags/v1.0.0", "sha0", "commit"),
+		},
+	}
+	server := httptest.NewServer(g)
+	defer server.Close()
+	d := &Data{GiteaURL: server.URL + "/", GiteaToken: "gitea-token", Timeout: time.Minute}
+	p := d.Provider()
+	if p.Name() != "Gitea" {
+		t.Fatalf("expected the Gitea provider, got %q", p.Name())
+	}
+
+	tags, branches, err := p.GetTagsAndBranches("org/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, expected := refsToString(tags), "refs/tags/v1.0.0 sha0 commit\n"; got != expected {
+		t.Errorf("expected tags:\n%s\ngot:\n%s", expected, got)
+	}
+	expectedBranches := "refs/heads/master sha0 commit\nrefs/heads/master-old sha1 commit\n"
+	if got := refsToString(branches); got != expectedBranches {
+		t.Errorf("expected branches:\n%s\ngot:\n%s", expectedBranches, got)
+	}
+
+	tests := []struct {
+		name          string
+		ref           string
+		sha           string
+		expectedError error
+	}{
+		{
+			name: "valid: create a branch",
+			ref:  "refs/heads/release-1.18",
+			sha:  "sha1",
+		},
+		{
+			name: "valid: create a tag",
+			ref:  "refs/tags/v1.18.0",
+			sha:  "sha1",
+		},
+		{
+			name: "valid: the branch already exists at the same commit",
+			ref:  "refs/heads/master",
+			sha:  "sha0",
+		},
+		{
+			name:          "invalid: the tag already exists at another commit",
+			ref:           "refs/tags/v1.0.0",
+			sha:           "sha1",
+			expectedError: ErrConflict,
+		},
+		{
+			name:          "invalid: only branches and tags are supported",
+			ref:           "refs/notes/v1.0.0",
+			sha:           "sha1",
+			expectedError: ErrNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := p.CreateRef("org/repo", tt.ref, tt.sha, false)
+			if tt.expectedError != nil {
+				if !stderrors.Is(err, tt.expectedError) {
+					t.Fatalf("expected a %v error, got: %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ref.GetRef() != tt.ref || ref.GetObject().GetSHA() != tt.sha {
+				t.Errorf("expected ref %q at %q, got %q at %q", tt.ref, tt.sha, ref.GetRef(), ref.GetObject().GetSHA())
+			}
+			existing, err := p.GetRef("org/repo", tt.ref)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if existing.GetObject().GetSHA() != tt.sha {
+				t.Errorf("expected ref %q at %q, got %q", tt.ref, tt.sha, existing.GetObject().GetSHA())
+			}
+		})
+	}
+
+	if _, err := p.UpdateRef("org/repo", "refs/tags/v1.0.0", "sha1", false); !stderrors.Is(err, ErrNotSupported) {
+		t.Errorf("expected a %v error, got: %v", ErrNotSupported, err)
+	}
+	if _, err := p.GetRef("org/repo", "refs/heads/missing"); !stderrors.Is(err, ErrNotFound) {
+		t.Errorf("expected a %v error, got: %v", ErrNotFound, err)
+	}
+	d.GiteaToken = "invalid"
+	if _, err := p.GetRef("org/repo", "refs/heads/master"); !stderrors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected a %v error, got: %v", ErrUnauthorized, err)
+	}
+}
+
+func TestGiteaProviderReleases(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "gitea")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	assetPath := filepath.Join(dir, "kubeadm")
+	if err := ioutil.WriteFile(assetPath, []byte("binary"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g := &fakeGitea{
+		refs:    []*github.Reference{newTestRef("refs/tags/v1.18.0-rc.0", "sha0", "commit")},
+		uploads: map[string]string{},
+	}
+	server := httptest.NewServer(g)
+	defer server.Close()
+	d := &Data{GiteaURL: server.URL, GiteaToken: "gitea-token", Timeout: time.Minute}
+	p := d.Provider()
+
+	if _, err := p.GetCreateRelease("org/repo", "v1.18.0", "notes", false); !stderrors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a %v error for a missing tag, got: %v", ErrNotFound, err)
+	}
+	release, err := p.GetCreateRelease("org/repo", "v1.18.0-rc.0", "notes", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if release.GetID() != 1 || release.GetBody() != "notes" || !release.GetPrerelease() {
+		t.Errorf("unexpected release: %s", release)
+	}
+	// The existing release is returned.
+	if release, err = p.GetCreateRelease("org/repo", "v1.18.0-rc.0", "other notes", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if release.GetID() != 1 || len(g.releases) != 1 {
+		t.Errorf("expected the existing release, got: %s", release)
+	}
+
+	assets, err := p.UploadReleaseAssets("org/repo", release, assetMap{"kubeadm": assetPath}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(assets) != 1 || assets[0].GetName() != "kubeadm" {
+		t.Errorf("unexpected assets: %v", assets)
+	}
+	if g.uploads["kubeadm"] != "binary" {
+		t.Errorf("expected the uploaded asset %q, got %q", "binary", g.uploads["kubeadm"])
+	}
+}
+
+func TestValidateGiteaOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          *Data
+		expectedError bool
+	}{
+		{
+			name: "valid: no Gitea server",
+			data: &Data{ForceUpdate: true},
+		},
+		{
+			name: "valid: Gitea server with a token",
+			data: &Data{GiteaURL: "https://gitea.example.com", GiteaToken: "token"},
+		},
+		{
+			name:          "invalid: forced updates are not supported",
+			data:          &Data{GiteaURL: "https://gitea.example.com", GiteaToken: "token", ForceUpdate: true},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGiteaOptions(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
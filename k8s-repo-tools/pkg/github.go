@@ -17,22 +17,43 @@ limitations under the License.
 package pkg
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/version"
 )
 
 // GitHubGetRefs obtains a list of References from a GitHub repository.
 func GitHubGetRefs(d *Data, repo string, refs string) ([]*github.Reference, error) {
 	Logf("getting %q from repository %q", refs, repo)
+	if d.GraphQL {
+		_, r, err := gitHubGetRefsGraphQL(d, repo, refs)
+		return r[refs], err
+	}
+	if len(d.CacheDir) != 0 {
+		return gitHubGetRefsCached(d, repo, refs)
+	}
 	ownerRepo := strings.Split(repo, "/")
 
-	ctx, cancel := d.CreateContext()
-	defer cancel()
-	r, resp, err := d.client.Git.GetRefs(ctx, ownerRepo[0], ownerRepo[1], refs)
+	var r []*github.Reference
+	var resp *github.Response
+	err := retry(d, fmt.Sprintf("getting %q from repository %q", refs, repo), func(ctx context.Context) (*github.Response, error) {
+		var err error
+		r, resp, err = d.client.Git().GetRefs(ctx, ownerRepo[0], ownerRepo[1], refs)
+		return resp, err
+	})
 	// handle not found by returning an empty list
 	if resp != nil && resp.StatusCode == http.StatusNotFound {
 		return []*github.Reference{}, nil
@@ -63,85 +84,189 @@ func GitHubCreateRef(d *Data, repo, ref, sha string, dryRun bool) (*github.Refer
 	}
 	if dryRun {
 		Logf("%s: would create ref %q from commit %q in repository %q", PrefixDryRun, ref, sha, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateRef, Repo: repo, Ref: ref, SHA: sha})
 		return &newRef, nil
 	}
 	ownerRepo := strings.Split(repo, "/")
-	ctx, cancel := d.CreateContext()
-	defer cancel()
 	Logf("creating ref %q from commit %q in repository %q", ref, sha, repo)
-	_, _, err := d.client.Git.CreateRef(ctx, ownerRepo[0], ownerRepo[1], &newRef)
+	// Before retrying check if the ref was already created from the same commit.
+	check := func() bool {
+		existing, err := GitHubGetRef(d, repo, ref)
+		return err == nil && existing.GetObject().GetSHA() == sha
+	}
+	err := retryWrite(d, fmt.Sprintf("creating ref %q", ref), check, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Git().CreateRef(ctx, ownerRepo[0], ownerRepo[1], &newRef)
+		return resp, err
+	})
+	if err != nil && stderrors.Is(err, ErrConflict) {
+		// A ref that already exists at the same commit, for example from a previous
+		// run that did not complete, is not an error so that re-runs converge.
+		existing, getErr := GitHubGetRef(d, repo, ref)
+		if getErr != nil {
+			return nil, err
+		}
+		if existing.GetObject().GetSHA() != sha {
+			return nil, NewErrorf(ErrorKindConflict, "ref %q already exists in repository %q at commit %q instead of %q",
+				ref, repo, existing.GetObject().GetSHA(), sha)
+		}
+		Logf("ref %q already exists at commit %q in repository %q", ref, sha, repo)
+		return existing, nil
+	}
+	if err == nil {
+		recordRefsCreated(1)
+		recordChange("created ref `%s` from commit `%s` in repository `%s`", ref, sha, repo)
+	}
 	return &newRef, err
 }
 
+// GitHubUpdateRef forces an existing Reference in a GitHub repository to point to a commit.
+func GitHubUpdateRef(d *Data, repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	updatedRef := github.Reference{
+		Ref: github.String(ref),
+		Object: &github.GitObject{
+			SHA: github.String(sha),
+		},
+	}
+	if dryRun {
+		Logf("%s: would update ref %q to commit %q in repository %q", PrefixDryRun, ref, sha, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionUpdateRef, Repo: repo, Ref: ref, SHA: sha})
+		return &updatedRef, nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("updating ref %q to commit %q in repository %q", ref, sha, repo)
+	// Forcing a ref to a commit is idempotent and can be retried without a check.
+	var r *github.Reference
+	err := retryWrite(d, fmt.Sprintf("updating ref %q", ref), nil, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		r, resp, err = d.client.Git().UpdateRef(ctx, ownerRepo[0], ownerRepo[1], &updatedRef, true)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordChange("updated ref `%s` to commit `%s` in repository `%s`", ref, sha, repo)
+	return r, nil
+}
+
 // GitHubGetRef obtains a Reference from a GitHub repository.
 func GitHubGetRef(d *Data, repo, ref string) (*github.Reference, error) {
 	ownerRepo := strings.Split(repo, "/")
-	ctx, cancel := d.CreateContext()
-	defer cancel()
 	Logf("getting ref %q from repository %q", ref, repo)
-	r, _, err := d.client.Git.GetRef(ctx, ownerRepo[0], ownerRepo[1], ref)
+	var r *github.Reference
+	err := retry(d, fmt.Sprintf("getting ref %q", ref), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		r, resp, err = d.client.Git().GetRef(ctx, ownerRepo[0], ownerRepo[1], ref)
+		return resp, err
+	})
 	return r, err
 }
 
-// GitHubCreateNewBranches goes trough a list of branches and creates them
-// based on the HEAD of the master branch.
-func GitHubCreateNewBranches(
-	d *Data,
-	repo string,
-	branchesDest *[]*github.Reference,
-	newBranches []*github.Reference,
-	masterSHA string) error {
-
-	for _, branch := range newBranches {
-		// In dry-run mode just append the new ref to the given list of destination refs.
-		if d.DryRun {
-			ref, _ := GitHubCreateRef(d, repo, branch.GetRef(), masterSHA, true)
-			*branchesDest = append(*branchesDest, ref)
-			continue
-		}
+// GitHubDeleteRef deletes a Reference from a GitHub repository.
+func GitHubDeleteRef(d *Data, repo, ref string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would delete ref %q from repository %q", PrefixDryRun, ref, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionDeleteRef, Repo: repo, Ref: ref})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("deleting ref %q from repository %q", ref, repo)
+	// Before retrying check if the ref was already deleted.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		_, resp, _ := d.client.Git().GetRef(ctx, ownerRepo[0], ownerRepo[1], ref)
+		return resp != nil && resp.StatusCode == http.StatusNotFound
+	}
+	err := retryWrite(d, fmt.Sprintf("deleting ref %q", ref), check, func(ctx context.Context) (*github.Response, error) {
+		return d.client.Git().DeleteRef(ctx, ownerRepo[0], ownerRepo[1], ref)
+	})
+	if err == nil {
+		recordChange("deleted ref `%s` from repository `%s`", ref, repo)
+	}
+	return err
+}
 
-		// Always create new branches from "master".
-		if _, err := GitHubCreateRef(d, repo, branch.GetRef(), masterSHA, false); err != nil {
-			return err
+// GitHubGetRefDate obtains the date of a Reference. For annotated tags this is the
+// date of the tag, for other references the committer date of the commit.
+func GitHubGetRefDate(d *Data, repo string, ref *github.Reference) (time.Time, error) {
+	ownerRepo := strings.Split(repo, "/")
+	sha := ref.GetObject().GetSHA()
+	if ref.GetObject().GetType() == "tag" {
+		V(2).Logf("getting annotated tag %q from repository %q", sha, repo)
+		var tag *github.Tag
+		err := retry(d, fmt.Sprintf("getting annotated tag %q", sha), func(ctx context.Context) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			tag, resp, err = d.client.Git().GetTag(ctx, ownerRepo[0], ownerRepo[1], sha)
+			return resp, err
+		})
+		if err != nil {
+			return time.Time{}, err
 		}
+		return tag.GetTagger().GetDate(), nil
 	}
-	return nil
+	commit, err := GitHubGetCommit(d, repo, sha)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.GetCommitter().GetDate(), nil
 }
 
-// GitHubCreateNewTags goes trough a list of tags and creates
-// them for matching versioned branch from a list of branches.
-// If no matching branch is found the SHA of master is used.
-func GitHubCreateNewTags(
-	d *Data,
-	repo string,
-	tagsDest *[]*github.Reference,
-	branches, newTags []*github.Reference,
-	masterSHA string) error {
-
-	for _, tag := range newTags {
-		sha := FindBranchHEADForTag(tag, d.PrefixBranch, masterSHA, branches)
+// compareCommitsPerPage is the number of commits that are requested per page
+// of a comparison.
+const compareCommitsPerPage = 100
 
-		// In dry-run mode just append the new ref to the given list of destination refs.
-		if d.DryRun {
-			ref, _ := GitHubCreateRef(d, repo, tag.GetRef(), sha, true)
-			*tagsDest = append(*tagsDest, ref)
-			continue
+// GitHubCompareBranches compares a couple of branches or SHAs of a GitHub repository.
+// Without pagination the GitHub API only returns the first 250 commits of a comparison,
+// so the pages are requested until the commits of all pages match the total number
+// of commits.
+func GitHubCompareBranches(d *Data, repo, base, head string) (*github.CommitsComparison, error) {
+	ownerRepo := strings.Split(repo, "/")
+	var cmp *github.CommitsComparison
+	for page := 1; page != 0; {
+		var pageCmp *github.CommitsComparison
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("comparing %q and %q", base, head), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			pageCmp, resp, err = gitHubCompareCommitsPage(ctx, d, ownerRepo[0], ownerRepo[1], base, head, page)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		if _, err := GitHubCreateRef(d, repo, tag.GetRef(), sha, false); err != nil {
-			return err
+		if cmp == nil {
+			cmp = pageCmp
+		} else {
+			cmp.Commits = append(cmp.Commits, pageCmp.Commits...)
 		}
+		if len(pageCmp.Commits) == 0 || len(cmp.Commits) >= cmp.GetTotalCommits() {
+			break
+		}
+		V(1).Logf("fetched %d of %d commits comparing %q and %q", len(cmp.Commits), cmp.GetTotalCommits(), base, head)
+		page = resp.NextPage
+	}
+	if len(cmp.Commits) < cmp.GetTotalCommits() {
+		Warningf("only %d of %d commits comparing %q and %q could be fetched", len(cmp.Commits), cmp.GetTotalCommits(), base, head)
 	}
-	return nil
+	return cmp, nil
 }
 
-// GitHubCompareBranches compares a couple of branches or SHAs of a GitHub repository.
-func GitHubCompareBranches(d *Data, repo, base, head string) (*github.CommitsComparison, error) {
-	ctx, cancel := d.CreateContext()
-	defer cancel()
-	ownerRepo := strings.Split(repo, "/")
-	cmp, _, err := d.client.Repositories.CompareCommits(ctx, ownerRepo[0], ownerRepo[1], base, head)
-	return cmp, err
+// gitHubCompareCommitsPage requests a page of a comparison. Unlike
+// RepositoriesService.CompareCommits it passes the page to the GitHub API.
+func gitHubCompareCommitsPage(ctx context.Context, d *Data, owner, repo, base, head string, page int) (*github.CommitsComparison, *github.Response, error) {
+	u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?page=%d&per_page=%d", owner, repo, base, head, page, compareCommitsPerPage)
+	req, err := d.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmp := &github.CommitsComparison{}
+	resp, err := d.client.Do(ctx, req, cmp)
+	if err != nil {
+		return nil, resp, err
+	}
+	return cmp, resp, nil
 }
 
 // GitHubMergeBranch merges head into the base branch and creates a merge commit.
@@ -151,6 +276,7 @@ func GitHubMergeBranch(d *Data, repo, base, head, commitMessage string) (*github
 	// return fake results on dry-run
 	if d.DryRun {
 		Logf("%s: would create a merge commit in repository %q", PrefixDryRun, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionMerge, Repo: repo, Base: base, Head: head, Message: commitMessage})
 		commit := &github.RepositoryCommit{
 			SHA:    github.String("dry-run-sha"),
 			Commit: &github.Commit{Message: github.String(commitMessage)},
@@ -163,8 +289,6 @@ func GitHubMergeBranch(d *Data, repo, base, head, commitMessage string) (*github
 		return commit, resp, nil
 	}
 
-	ctx, cancel := d.CreateContext()
-	defer cancel()
 	ownerRepo := strings.Split(repo, "/")
 	req := github.RepositoryMergeRequest{
 		Base:          github.String(base),
@@ -172,7 +296,19 @@ func GitHubMergeBranch(d *Data, repo, base, head, commitMessage string) (*github
 		CommitMessage: github.String(commitMessage),
 	}
 	Logf("merging %q into %q for repository %q", head, base, repo)
-	return d.client.Repositories.Merge(ctx, ownerRepo[0], ownerRepo[1], &req)
+	// A merge is idempotent. If a previous attempt was applied, the retry
+	// returns a status 204 because there is nothing to merge.
+	var commit *github.RepositoryCommit
+	var resp *github.Response
+	err := retryWrite(d, fmt.Sprintf("merging %q into %q", head, base), nil, func(ctx context.Context) (*github.Response, error) {
+		var err error
+		commit, resp, err = d.client.Repositories().Merge(ctx, ownerRepo[0], ownerRepo[1], &req)
+		return resp, err
+	})
+	if err == nil && commit != nil {
+		recordChange("merged `%s` into `%s` with commit `%s` in repository `%s`", head, base, commit.GetSHA(), repo)
+	}
+	return commit, resp, err
 }
 
 // GitHubGetCreateRelease first checks if a tag exists and obtains a release from this tag.
@@ -181,17 +317,25 @@ func GitHubGetCreateRelease(d *Data, repo, tag string, body string, dryRun bool)
 	ownerRepo := strings.Split(repo, "/")
 
 	Logf("checking if tag %q exists", tag)
-	ctx, cancel := d.CreateContext()
-	defer cancel()
-	_, _, err := d.client.Git.GetRef(ctx, ownerRepo[0], ownerRepo[1], "refs/tags/"+tag)
+	err := retry(d, fmt.Sprintf("checking if tag %q exists", tag), func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Git().GetRef(ctx, ownerRepo[0], ownerRepo[1], "refs/tags/"+tag)
+		return resp, err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	Logf("getting release from tag %q", tag)
-	ctx, cancel = d.CreateContext()
-	defer cancel()
-	release, resp, err := d.client.Repositories.GetReleaseByTag(ctx, ownerRepo[0], ownerRepo[1], tag)
+	var release *github.RepositoryRelease
+	var resp *github.Response
+	err = retry(d, fmt.Sprintf("getting release from tag %q", tag), func(ctx context.Context) (*github.Response, error) {
+		var err error
+		release, resp, err = d.client.Repositories().GetReleaseByTag(ctx, ownerRepo[0], ownerRepo[1], tag)
+		return resp, err
+	})
+	if resp == nil {
+		return nil, err
+	}
 	if resp.StatusCode == http.StatusOK {
 		return release, nil
 	}
@@ -220,21 +364,126 @@ func GitHubGetCreateRelease(d *Data, repo, tag string, body string, dryRun bool)
 
 	if dryRun {
 		Logf("%s: would create a release for tag %q in repository %q", PrefixDryRun, tag, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateRelease, Repo: repo, Tag: tag, Body: body})
 		return release, nil
 	}
 
 	Logf("creating release for tag %q", tag)
-	ctx, cancel = d.CreateContext()
-	defer cancel()
-	release, _, err = d.client.Repositories.CreateRelease(ctx, ownerRepo[0], ownerRepo[1], release)
+	newRelease := release
+	// Before retrying check if the release was already created.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		existing, _, err := d.client.Repositories().GetReleaseByTag(ctx, ownerRepo[0], ownerRepo[1], tag)
+		if err != nil {
+			return false
+		}
+		release = existing
+		return true
+	}
+	err = retryWrite(d, fmt.Sprintf("creating release for tag %q", tag), check, func(ctx context.Context) (*github.Response, error) {
+		created, resp, err := d.client.Repositories().CreateRelease(ctx, ownerRepo[0], ownerRepo[1], newRelease)
+		if err == nil {
+			release = created
+		}
+		return resp, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	recordChange("created release for tag `%s` in repository `%s`", tag, repo)
 	return release, err
 }
 
 // GitHubUploadReleaseAssets uploads files to a GitHub repository.
 func GitHubUploadReleaseAssets(d *Data, repo string, release *github.RepositoryRelease, am assetMap, dryRun bool) ([]*github.ReleaseAsset, error) {
+	assets, names := newReleaseAssetNames(release, am)
+	newReleaseAssets := make([]*github.ReleaseAsset, len(names))
+	var i int
+	for _, k := range names {
+		v := am[k]
+		// Handle dry run.
+		if dryRun {
+			Logf("%s: would upload asset %q from path %q", PrefixDryRun, k, v)
+			d.recordPlanStep(PlanStep{Action: PlanActionUploadAsset, Repo: repo, Tag: release.GetTagName(), Asset: k, Path: v})
+			newReleaseAssets[i] = &github.ReleaseAsset{Name: github.String(k)}
+			i++
+			continue
+		}
+
+		// Stop before the next upload if the process was interrupted.
+		if d.Interrupted() {
+			assets = append(assets, newReleaseAssets[:i]...)
+			return assets, ErrInterrupted
+		}
+
+		// Open the file.
+		file, err := os.Open(v)
+		if err != nil {
+			return nil, err
+		}
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if stat.IsDir() {
+			file.Close()
+			return nil, errors.Errorf("the asset path %q is a directory", v)
+		}
+
+		// Upload the file as asset.
+		Logf("uploading asset %q from path %q (%s)", k, v, formatBytes(stat.Size()))
+		ownerRepo := strings.Split(repo, "/")
+		u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?name=%s", ownerRepo[0], ownerRepo[1], release.GetID(), url.QueryEscape(k))
+		start := time.Now()
+		var releaseAsset *github.ReleaseAsset
+		// Before retrying check if the asset was already uploaded.
+		check := func() bool {
+			ctx, cancel := d.CreateContext()
+			defer cancel()
+			existing, _, err := d.client.Repositories().ListReleaseAssets(ctx, ownerRepo[0], ownerRepo[1], release.GetID(), &github.ListOptions{PerPage: 100})
+			if err != nil {
+				return false
+			}
+			for _, a := range existing {
+				if a.GetName() == k {
+					releaseAsset = a
+					return true
+				}
+			}
+			return false
+		}
+		err = retryTransfer(d, fmt.Sprintf("uploading asset %q", k), true, check, func(ctx context.Context) (*github.Response, error) {
+			// Rewind the file in case this is a retry.
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			// Stream the file and log the progress of large uploads.
+			body := newProgressReader(file, fmt.Sprintf("uploading asset %q", k), stat.Size())
+			req, err := d.client.NewUploadRequest(u, body, stat.Size(), "application/octet-stream")
+			if err != nil {
+				return nil, err
+			}
+			releaseAsset = &github.ReleaseAsset{}
+			return d.client.Do(ctx, req, releaseAsset)
+		})
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		d.recordAssetUpload(AssetUpload{Name: k, Size: stat.Size(), Duration: time.Since(start)})
+		newReleaseAssets[i] = releaseAsset
+		i++
+	}
+	assets = append(assets, newReleaseAssets...)
+	return assets, nil
+}
+
+// newReleaseAssetNames returns the existing assets of a release and the sorted
+// names of the assets in am that do not exist yet, so that they are uploaded
+// in a deterministic order.
+func newReleaseAssetNames(release *github.RepositoryRelease, am assetMap) ([]*github.ReleaseAsset, []string) {
 	// Get the existing list of assets, but convert them to a list of pointers
 	assets := make([]*github.ReleaseAsset, len(release.Assets))
 	for i := range release.Assets {
@@ -243,8 +492,8 @@ func GitHubUploadReleaseAssets(d *Data, repo string, release *github.RepositoryR
 	Logf("found %d existing assets in release", len(assets))
 
 	// Only upload new files.
-	assetsNew := map[string]string{}
-	for k, v := range am {
+	names := []string{}
+	for k := range am {
 		exists := false
 		for _, a := range assets {
 			if k == a.GetName() {
@@ -253,50 +502,1018 @@ func GitHubUploadReleaseAssets(d *Data, repo string, release *github.RepositoryR
 			}
 		}
 		if exists {
-			Logf("skipping existing asset %q", k)
+			V(2).Logf("skipping existing asset %q", k)
 			continue
 		}
-		assetsNew[k] = v
+		names = append(names, k)
 	}
-	Logf("found %d new assets", len(assetsNew))
+	Logf("found %d new assets", len(names))
+	sort.Strings(names)
+	return assets, names
+}
 
-	newReleaseAssets := make([]*github.ReleaseAsset, len(assetsNew))
-	var i int
-	for k, v := range assetsNew {
-		// Handle dry run.
-		if dryRun {
-			Logf("%s: would upload asset %q from path %q", PrefixDryRun, k, v)
-			newReleaseAssets[i] = &github.ReleaseAsset{Name: github.String(k)}
-			i++
-			continue
+// GitHubGetReleases obtains all releases from a GitHub repository.
+func GitHubGetReleases(d *Data, repo string) ([]*github.RepositoryRelease, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting releases from repository %q", repo)
+	result := []*github.RepositoryRelease{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		var releases []*github.RepositoryRelease
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting releases from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			releases, resp, err = d.client.Repositories().ListReleases(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, releases...)
+		if resp == nil || resp.NextPage == 0 {
+			break
 		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
 
-		// Open the file.
-		file, err := os.Open(v)
+// GitHubCreateRelease creates a release with the tag, name, body, draft and pre-release
+// fields of release. Unlike GitHubGetCreateRelease it does not check if the tag exists.
+func GitHubCreateRelease(d *Data, repo string, release *github.RepositoryRelease, dryRun bool) (*github.RepositoryRelease, error) {
+	tag := release.GetTagName()
+	// Releases without a name are shown with the name of the tag.
+	name := release.GetName()
+	if len(name) == 0 {
+		name = tag
+	}
+	newRelease := &github.RepositoryRelease{
+		TagName:    github.String(tag),
+		Name:       github.String(name),
+		Body:       github.String(release.GetBody()),
+		Draft:      github.Bool(release.GetDraft()),
+		Prerelease: github.Bool(release.GetPrerelease()),
+	}
+	if dryRun {
+		Logf("%s: would create a release for tag %q in repository %q", PrefixDryRun, tag, repo)
+		d.recordPlanStep(PlanStep{
+			Action:     PlanActionCreateRelease,
+			Repo:       repo,
+			Tag:        tag,
+			Title:      newRelease.GetName(),
+			Body:       newRelease.GetBody(),
+			Draft:      newRelease.GetDraft(),
+			Prerelease: newRelease.GetPrerelease(),
+		})
+		return newRelease, nil
+	}
+
+	ownerRepo := strings.Split(repo, "/")
+	Logf("creating release for tag %q in repository %q", tag, repo)
+	created := newRelease
+	// Before retrying check if the release was already created.
+	check := func() bool {
+		existing, err := GitHubGetRelease(d, repo, tag)
+		if err != nil {
+			return false
+		}
+		created = existing
+		return true
+	}
+	err := retryWrite(d, fmt.Sprintf("creating release for tag %q", tag), check, func(ctx context.Context) (*github.Response, error) {
+		r, resp, err := d.client.Repositories().CreateRelease(ctx, ownerRepo[0], ownerRepo[1], newRelease)
+		if err == nil {
+			created = r
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordChange("created release for tag `%s` in repository `%s`", tag, repo)
+	return created, nil
+}
+
+// GitHubGetRelease obtains the release for a tag. Unlike GitHubGetCreateRelease
+// a missing release is not created and a NotFound error is returned.
+func GitHubGetRelease(d *Data, repo, tag string) (*github.RepositoryRelease, error) {
+	ownerRepo := strings.Split(repo, "/")
+	V(1).Logf("getting release from tag %q in repository %q", tag, repo)
+	var release *github.RepositoryRelease
+	err := retry(d, fmt.Sprintf("getting release from tag %q", tag), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		release, resp, err = d.client.Repositories().GetReleaseByTag(ctx, ownerRepo[0], ownerRepo[1], tag)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// GitHubDownloadReleaseAsset downloads the contents of a release asset.
+func GitHubDownloadReleaseAsset(d *Data, repo string, asset *github.ReleaseAsset) ([]byte, error) {
+	ownerRepo := strings.Split(repo, "/")
+	V(1).Logf("downloading asset %q from repository %q", asset.GetName(), repo)
+	var data []byte
+	var redirectURL string
+	err := retryTransfer(d, fmt.Sprintf("downloading asset %q", asset.GetName()), false, nil, func(ctx context.Context) (*github.Response, error) {
+		rc, url, err := d.client.Repositories().DownloadReleaseAsset(ctx, ownerRepo[0], ownerRepo[1], asset.GetID())
 		if err != nil {
+			// DownloadReleaseAsset does not return a Response. Use the one from
+			// the error, so that only retryable errors are retried.
+			if errResp, ok := err.(*github.ErrorResponse); ok {
+				return &github.Response{Response: errResp.Response}, err
+			}
 			return nil, err
 		}
+		redirectURL = url
+		if rc == nil {
+			return nil, nil
+		}
+		defer rc.Close()
+		data, err = ioutil.ReadAll(rc)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The asset is stored outside of the GitHub API.
+	if len(redirectURL) != 0 {
+		return ReadFromURL(redirectURL, d.transferTimeout())
+	}
+	return data, nil
+}
+
+// GitHubCreateIssueComment posts a comment to a GitHub issue of the format 'org/repo#issue'.
+func GitHubCreateIssueComment(d *Data, issue, body string, dryRun bool) (*github.IssueComment, error) {
+	repoNumber := strings.Split(issue, "#")
+	if len(repoNumber) != 2 {
+		return nil, errors.Errorf("malformed issue %q; expected format is 'org/repo#issue'", issue)
+	}
+	number, err := strconv.Atoi(repoNumber[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the number of issue %q", issue)
+	}
+
+	comment := &github.IssueComment{Body: github.String(body)}
+	if dryRun {
+		Logf("%s: would create a comment in issue %q", PrefixDryRun, issue)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateComment, Issue: issue, Body: body})
+		return comment, nil
+	}
+
+	ownerRepo := strings.Split(repoNumber[0], "/")
+	Logf("creating a comment in issue %q", issue)
+	since := time.Now().Add(-time.Minute)
+	newComment := comment
+	// Before retrying check if a comment with the same body was already created.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		opt := &github.IssueListCommentsOptions{Since: &since, ListOptions: github.ListOptions{PerPage: 100}}
+		existing, _, err := d.client.Issues().ListComments(ctx, ownerRepo[0], ownerRepo[1], number, opt)
+		if err != nil {
+			return false
+		}
+		for _, c := range existing {
+			if c.GetBody() == body {
+				comment = c
+				return true
+			}
+		}
+		return false
+	}
+	err = retryWrite(d, fmt.Sprintf("creating a comment in issue %q", issue), check, func(ctx context.Context) (*github.Response, error) {
+		created, resp, err := d.client.Issues().CreateComment(ctx, ownerRepo[0], ownerRepo[1], number, newComment)
+		if err == nil {
+			comment = created
+		}
+		return resp, err
+	})
+	return comment, err
+}
 
-		opt := github.UploadOptions{
-			Name:      k,
-			MediaType: "application/octet-stream",
+// GitHubGetIssues obtains all open and closed issues from a GitHub repository.
+// If labels are given only issues that have all of the labels are returned.
+// Pull requests are not included.
+func GitHubGetIssues(d *Data, repo string, labels []string) ([]*github.Issue, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting issues with labels %v from repository %q", labels, repo)
+	result := []*github.Issue{}
+	opt := &github.IssueListByRepoOptions{State: "all", Labels: labels, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		var issues []*github.Issue
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting issues from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			issues, resp, err = d.client.Issues().ListByRepo(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				result = append(result, issue)
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
 		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
 
-		// Upload the file as asset.
-		Logf("uploading asset %q from path %q", k, v)
+// GitHubCreateIssue creates an issue with the given title, body and labels in a GitHub repository.
+func GitHubCreateIssue(d *Data, repo, title, body string, labels []string, dryRun bool) (*github.Issue, error) {
+	issue := &github.Issue{Title: github.String(title), Body: github.String(body)}
+	if dryRun {
+		Logf("%s: would create issue %q in repository %q", PrefixDryRun, title, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateIssue, Repo: repo, Title: title, Body: body, Labels: labels})
+		return issue, nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("creating issue %q in repository %q", title, repo)
+	request := &github.IssueRequest{Title: github.String(title), Body: github.String(body)}
+	if len(labels) != 0 {
+		request.Labels = &labels
+	}
+	since := time.Now().Add(-time.Minute)
+	// Before retrying check if an issue with the same title and body was already created.
+	check := func() bool {
 		ctx, cancel := d.CreateContext()
-		ownerRepo := strings.Split(repo, "/")
-		releaseAsset, _, err := d.client.Repositories.UploadReleaseAsset(ctx, ownerRepo[0], ownerRepo[1], release.GetID(), &opt, file)
+		defer cancel()
+		opt := &github.IssueListByRepoOptions{State: "all", Since: since, ListOptions: github.ListOptions{PerPage: 100}}
+		existing, _, err := d.client.Issues().ListByRepo(ctx, ownerRepo[0], ownerRepo[1], opt)
+		if err != nil {
+			return false
+		}
+		for _, e := range existing {
+			if e.GetTitle() == title && e.GetBody() == body {
+				issue = e
+				return true
+			}
+		}
+		return false
+	}
+	err := retryWrite(d, fmt.Sprintf("creating issue %q", title), check, func(ctx context.Context) (*github.Response, error) {
+		created, resp, err := d.client.Issues().Create(ctx, ownerRepo[0], ownerRepo[1], request)
+		if err == nil {
+			issue = created
+		}
+		return resp, err
+	})
+	return issue, err
+}
+
+// GitHubEditIssue updates the title and the state of the issue with the given number
+// in a GitHub repository. The state must be "open" or "closed".
+func GitHubEditIssue(d *Data, repo string, number int, title, state string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would update issue #%d in repository %q", PrefixDryRun, number, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionEditIssue, Repo: repo, Number: number, Title: title, State: state})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("updating issue #%d in repository %q", number, repo)
+	request := &github.IssueRequest{Title: github.String(title), State: github.String(state)}
+	// Editing an issue is idempotent and can be retried without a check.
+	return retryWrite(d, fmt.Sprintf("updating issue #%d", number), nil, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Issues().Edit(ctx, ownerRepo[0], ownerRepo[1], number, request)
+		return resp, err
+	})
+}
+
+// GitHubGetWorkflowRuns obtains the GitHub Actions workflow runs for a branch of a
+// GitHub repository, from the newest to the oldest. If since is not zero, runs that
+// were created before it are not included.
+func GitHubGetWorkflowRuns(d *Data, repo, branch string, since time.Time) ([]*WorkflowRun, error) {
+	Logf("getting workflow runs for branch %q from repository %q", branch, repo)
+	result := []*WorkflowRun{}
+	query := url.Values{}
+	query.Set("branch", branch)
+	query.Set("per_page", "100")
+	for page := 1; ; {
+		query.Set("page", strconv.Itoa(page))
+		u := fmt.Sprintf("repos/%s/actions/runs?%s", repo, query.Encode())
+		var runs workflowRuns
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting workflow runs from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			req, err := d.client.NewRequest(http.MethodGet, u, nil)
+			if err != nil {
+				return nil, err
+			}
+			runs = workflowRuns{}
+			resp, err = d.client.Do(ctx, req, &runs)
+			return resp, err
+		})
 		if err != nil {
-			cancel()
-			file.Close()
 			return nil, err
 		}
-		cancel()
-		file.Close()
-		newReleaseAssets[i] = releaseAsset
-		i++
+		for _, run := range runs.WorkflowRuns {
+			if !since.IsZero() && run.CreatedAt.Before(since) {
+				return result, nil
+			}
+			result = append(result, run)
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
 	}
-	assets = append(assets, newReleaseAssets...)
-	return assets, nil
+	return result, nil
+}
+
+// GitHubRerunWorkflowRun re-runs a GitHub Actions workflow run in a GitHub repository.
+func GitHubRerunWorkflowRun(d *Data, repo string, id int64, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would re-run workflow run %d in repository %q", PrefixDryRun, id, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionRerunWorkflow, Repo: repo, ID: id})
+		return nil
+	}
+	Logf("re-running workflow run %d in repository %q", id, repo)
+	// Before retrying check if the run was already started again.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		req, err := d.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/actions/runs/%d", repo, id), nil)
+		if err != nil {
+			return false
+		}
+		run := &WorkflowRun{}
+		if _, err := d.client.Do(ctx, req, run); err != nil {
+			return false
+		}
+		return run.Status != "completed"
+	}
+	return retryWrite(d, fmt.Sprintf("re-running workflow run %d", id), check, func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodPost, fmt.Sprintf("repos/%s/actions/runs/%d/rerun", repo, id), nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.client.Do(ctx, req, nil)
+	})
+}
+
+// GitHubDispatchWorkflow triggers a workflow_dispatch event for a GitHub Actions workflow
+// of a GitHub repository on the given ref. The workflow can be a file name or an ID.
+// If check is not nil it is called before retrying, to find out if the workflow was
+// already dispatched.
+func GitHubDispatchWorkflow(d *Data, repo, workflow, ref string, check retryCheckFunc) error {
+	Logf("dispatching workflow %q on ref %q in repository %q", workflow, ref, repo)
+	u := fmt.Sprintf("repos/%s/actions/workflows/%s/dispatches", repo, url.PathEscape(workflow))
+	body := map[string]string{"ref": ref}
+	return retryWrite(d, fmt.Sprintf("dispatching workflow %q", workflow), check, func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodPost, u, body)
+		if err != nil {
+			return nil, err
+		}
+		return d.client.Do(ctx, req, nil)
+	})
+}
+
+// GitHubGetWorkflowDispatchRuns obtains the latest runs of a GitHub Actions workflow of a
+// GitHub repository that were triggered by a workflow_dispatch event on the given ref,
+// from the newest to the oldest.
+func GitHubGetWorkflowDispatchRuns(d *Data, repo, workflow, ref string) ([]*WorkflowRun, error) {
+	V(1).Logf("getting the runs of workflow %q for ref %q from repository %q", workflow, ref, repo)
+	query := url.Values{}
+	query.Set("event", "workflow_dispatch")
+	query.Set("branch", ref)
+	u := fmt.Sprintf("repos/%s/actions/workflows/%s/runs?%s", repo, url.PathEscape(workflow), query.Encode())
+	var runs workflowRuns
+	err := retry(d, fmt.Sprintf("getting the runs of workflow %q", workflow), func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		runs = workflowRuns{}
+		return d.client.Do(ctx, req, &runs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs.WorkflowRuns, nil
+}
+
+// GitHubGetWorkflowRun obtains a GitHub Actions workflow run of a GitHub repository.
+func GitHubGetWorkflowRun(d *Data, repo string, id int64) (*WorkflowRun, error) {
+	V(1).Logf("getting workflow run %d from repository %q", id, repo)
+	run := &WorkflowRun{}
+	err := retry(d, fmt.Sprintf("getting workflow run %d", id), func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/actions/runs/%d", repo, id), nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.client.Do(ctx, req, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// GitHubGetArtifacts obtains the artifacts of a GitHub Actions workflow run of a GitHub repository.
+func GitHubGetArtifacts(d *Data, repo string, runID int64) ([]*Artifact, error) {
+	Logf("getting the artifacts of workflow run %d from repository %q", runID, repo)
+	result := []*Artifact{}
+	for page := 1; ; {
+		u := fmt.Sprintf("repos/%s/actions/runs/%d/artifacts?per_page=100&page=%d", repo, runID, page)
+		var list artifacts
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting the artifacts of workflow run %d", runID), func(ctx context.Context) (*github.Response, error) {
+			req, err := d.client.NewRequest(http.MethodGet, u, nil)
+			if err != nil {
+				return nil, err
+			}
+			list = artifacts{}
+			resp, err = d.client.Do(ctx, req, &list)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list.Artifacts...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubDownloadArtifact downloads the ZIP archive of a GitHub Actions artifact.
+// The API redirects to a pre-signed URL outside of the GitHub API, which is
+// requested without the GitHub credentials.
+func GitHubDownloadArtifact(d *Data, repo string, artifact *Artifact) ([]byte, error) {
+	V(1).Logf("downloading artifact %q from repository %q", artifact.Name, repo)
+	client := *d.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	u := fmt.Sprintf("repos/%s/actions/artifacts/%d/zip", repo, artifact.ID)
+	var data []byte
+	var redirectURL string
+	err := retryTransfer(d, fmt.Sprintf("downloading artifact %q", artifact.Name), false, nil, func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusFound {
+			redirectURL = resp.Header.Get("Location")
+			return &github.Response{Response: resp}, nil
+		}
+		if err := github.CheckResponse(resp); err != nil {
+			return &github.Response{Response: resp}, err
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		return &github.Response{Response: resp}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(redirectURL) != 0 {
+		return ReadFromURL(redirectURL, d.transferTimeout())
+	}
+	return data, nil
+}
+
+// GitHubGetCheckSuites obtains the check suites for a ref of a GitHub repository.
+// The ref can be a commit SHA, a branch or a tag.
+func GitHubGetCheckSuites(d *Data, repo, ref string) ([]*github.CheckSuite, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting check suites for ref %q from repository %q", ref, repo)
+	result := []*github.CheckSuite{}
+	opt := &github.ListCheckSuiteOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		var suites *github.ListCheckSuiteResults
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting check suites from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			suites, resp, err = d.client.Checks().ListCheckSuitesForRef(ctx, ownerRepo[0], ownerRepo[1], ref, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, suites.CheckSuites...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubRerequestCheckSuite re-requests a check suite in a GitHub repository,
+// which makes the GitHub App of the suite run its checks again.
+func GitHubRerequestCheckSuite(d *Data, repo string, id int64, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would re-request check suite %d in repository %q", PrefixDryRun, id, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionRerequestCheckSuite, Repo: repo, ID: id})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("re-requesting check suite %d in repository %q", id, repo)
+	// Before retrying check if the suite was already requested again.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		suite, _, err := d.client.Checks().GetCheckSuite(ctx, ownerRepo[0], ownerRepo[1], id)
+		if err != nil {
+			return false
+		}
+		return suite.GetStatus() != "completed"
+	}
+	return retryWrite(d, fmt.Sprintf("re-requesting check suite %d", id), check, func(ctx context.Context) (*github.Response, error) {
+		return d.client.Checks().ReRequestCheckSuite(ctx, ownerRepo[0], ownerRepo[1], id)
+	})
+}
+
+// GitHubGetRepository obtains a GitHub repository of the format 'org/repo'.
+func GitHubGetRepository(d *Data, repo string) (*github.Repository, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting repository %q", repo)
+	var r *github.Repository
+	err := retry(d, fmt.Sprintf("getting repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		r, resp, err = d.client.Repositories().Get(ctx, ownerRepo[0], ownerRepo[1])
+		return resp, err
+	})
+	return r, err
+}
+
+// GitHubProtectBranch applies branch protection to a branch of a GitHub repository.
+// Pull requests to the branch require an approving review.
+func GitHubProtectBranch(d *Data, repo, branch string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would protect branch %q in repository %q", PrefixDryRun, branch, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionProtectBranch, Repo: repo, Branch: branch})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("protecting branch %q in repository %q", branch, repo)
+	protection := &github.ProtectionRequest{
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcementRequest{
+			RequiredApprovingReviewCount: 1,
+		},
+	}
+	// Updating the protection of a branch is idempotent and can be retried without a check.
+	return retryWrite(d, fmt.Sprintf("protecting branch %q", branch), nil, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Repositories().UpdateBranchProtection(ctx, ownerRepo[0], ownerRepo[1], branch, protection)
+		return resp, err
+	})
+}
+
+// GitHubGetBranchProtection obtains the protection of a branch of a GitHub repository.
+// If the branch is not protected an error of kind ErrorKindNotFound is returned.
+func GitHubGetBranchProtection(d *Data, repo, branch string) (*github.Protection, error) {
+	ownerRepo := strings.Split(repo, "/")
+	V(1).Logf("getting the protection of branch %q in repository %q", branch, repo)
+	var protection *github.Protection
+	err := retry(d, fmt.Sprintf("getting the protection of branch %q", branch), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		protection, resp, err = d.client.Repositories().GetBranchProtection(ctx, ownerRepo[0], ownerRepo[1], branch)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return protection, nil
+}
+
+// GitHubGetHooks obtains all webhooks of a GitHub repository.
+func GitHubGetHooks(d *Data, repo string) ([]*github.Hook, error) {
+	ownerRepo := strings.Split(repo, "/")
+	V(1).Logf("getting webhooks from repository %q", repo)
+	result := []*github.Hook{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		var hooks []*github.Hook
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting webhooks from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			hooks, resp, err = d.client.Repositories().ListHooks(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, hooks...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubGetFile obtains the contents and the blob SHA of a file from a branch of a
+// GitHub repository. If the file does not exist an error of kind ErrorKindNotFound
+// is returned.
+func GitHubGetFile(d *Data, repo, branch, path string) (string, string, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting file %q from branch %q of repository %q", path, branch, repo)
+	var file *github.RepositoryContent
+	err := retry(d, fmt.Sprintf("getting file %q", path), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		opt := &github.RepositoryContentGetOptions{Ref: branch}
+		file, _, resp, err = d.client.Repositories().GetContents(ctx, ownerRepo[0], ownerRepo[1], path, opt)
+		return resp, err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if file == nil {
+		return "", "", errors.Errorf("the path %q in repository %q is not a file", path, repo)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "could not decode the contents of file %q", path)
+	}
+	return content, file.GetSHA(), nil
+}
+
+// GitHubUpdateFile commits the contents of a file to a branch of a GitHub repository.
+// If sha is empty the file is created, otherwise sha must be the blob SHA of the
+// file that is updated.
+func GitHubUpdateFile(d *Data, repo, branch, path, message, content, sha string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would update file %q in branch %q of repository %q", PrefixDryRun, path, branch, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionUpdateFile, Repo: repo, Branch: branch, Path: path,
+			Message: message, Body: content, SHA: sha})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("updating file %q in branch %q of repository %q", path, branch, repo)
+	opt := &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Content: []byte(content),
+		Branch:  github.String(branch),
+	}
+	if len(sha) != 0 {
+		opt.SHA = github.String(sha)
+	}
+	// Before retrying check if the file already has the same contents.
+	check := func() bool {
+		existing, _, err := GitHubGetFile(d, repo, branch, path)
+		return err == nil && existing == content
+	}
+	return retryWrite(d, fmt.Sprintf("updating file %q", path), check, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		if opt.SHA == nil {
+			_, resp, err = d.client.Repositories().CreateFile(ctx, ownerRepo[0], ownerRepo[1], path, opt)
+		} else {
+			_, resp, err = d.client.Repositories().UpdateFile(ctx, ownerRepo[0], ownerRepo[1], path, opt)
+		}
+		return resp, err
+	})
+}
+
+// GitHubCreatePullRequest creates a pull request from the head branch into the base
+// branch of a GitHub repository. If labels are given they are added to the pull request.
+func GitHubCreatePullRequest(d *Data, repo, base, head, title, body string, labels []string, dryRun bool) (*github.PullRequest, error) {
+	pr := &github.PullRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+		Base:  &github.PullRequestBranch{Ref: github.String(base)},
+		Head:  &github.PullRequestBranch{Ref: github.String(head)},
+	}
+	if dryRun {
+		Logf("%s: would create a pull request from %q into %q in repository %q", PrefixDryRun, head, base, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreatePullRequest, Repo: repo, Base: base, Head: head,
+			Message: title, Body: body, Labels: labels})
+		return pr, nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("creating a pull request from %q into %q in repository %q", head, base, repo)
+	newPR := &github.NewPullRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+		Base:  github.String(base),
+		Head:  github.String(head),
+	}
+	// Before retrying check if a pull request for the same head branch was already created.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		opt := &github.PullRequestListOptions{State: "open", Head: ownerRepo[0] + ":" + head, Base: base}
+		existing, _, err := d.client.PullRequests().List(ctx, ownerRepo[0], ownerRepo[1], opt)
+		if err != nil || len(existing) == 0 {
+			return false
+		}
+		pr = existing[0]
+		return true
+	}
+	err := retryWrite(d, fmt.Sprintf("creating a pull request from %q", head), check, func(ctx context.Context) (*github.Response, error) {
+		created, resp, err := d.client.PullRequests().Create(ctx, ownerRepo[0], ownerRepo[1], newPR)
+		if err == nil {
+			pr = created
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	Logf("created pull request %s", pr.GetHTMLURL())
+	recordChange("created pull request %s", pr.GetHTMLURL())
+
+	if len(labels) != 0 {
+		Logf("adding labels %v to pull request #%d", labels, pr.GetNumber())
+		// Adding labels is idempotent and can be retried without a check.
+		err = retryWrite(d, fmt.Sprintf("adding labels to pull request #%d", pr.GetNumber()), nil, func(ctx context.Context) (*github.Response, error) {
+			_, resp, err := d.client.Issues().AddLabelsToIssue(ctx, ownerRepo[0], ownerRepo[1], pr.GetNumber(), labels)
+			return resp, err
+		})
+		if err != nil {
+			return pr, err
+		}
+	}
+	return pr, nil
+}
+
+// GitHubGetPullRequests obtains the pull requests with the given state from a
+// GitHub repository. The state must be "open", "closed" or "all".
+func GitHubGetPullRequests(d *Data, repo, state string) ([]*github.PullRequest, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting %s pull requests from repository %q", state, repo)
+	result := []*github.PullRequest{}
+	opt := &github.PullRequestListOptions{State: state, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		var prs []*github.PullRequest
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting pull requests from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			prs, resp, err = d.client.PullRequests().List(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, prs...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubGetPullRequest obtains a pull request from a GitHub repository.
+func GitHubGetPullRequest(d *Data, repo string, number int) (*github.PullRequest, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting pull request #%d from repository %q", number, repo)
+	var pr *github.PullRequest
+	err := retry(d, fmt.Sprintf("getting pull request #%d", number), func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		pr, resp, err = d.client.PullRequests().Get(ctx, ownerRepo[0], ownerRepo[1], number)
+		return resp, err
+	})
+	return pr, err
+}
+
+// GitHubGetPullRequestCommits obtains the commits of a pull request from a GitHub repository.
+func GitHubGetPullRequestCommits(d *Data, repo string, number int) ([]*github.RepositoryCommit, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting the commits of pull request #%d from repository %q", number, repo)
+	result := []*github.RepositoryCommit{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		var commits []*github.RepositoryCommit
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting the commits of pull request #%d", number), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			commits, resp, err = d.client.PullRequests().ListCommits(ctx, ownerRepo[0], ownerRepo[1], number, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, commits...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubGetMilestones obtains all open and closed milestones from a GitHub repository.
+func GitHubGetMilestones(d *Data, repo string) ([]*github.Milestone, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting milestones from repository %q", repo)
+	result := []*github.Milestone{}
+	opt := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		var milestones []*github.Milestone
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting milestones from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			milestones, resp, err = d.client.Issues().ListMilestones(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, milestones...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubGetReleaseMilestone returns the milestone of a release tag from a GitHub repository.
+// The title of the milestone is the tag, such as "v1.18.0", or the tag without the "v"
+// prefix. It returns nil if the repository does not have such a milestone.
+func GitHubGetReleaseMilestone(d *Data, repo, tag string) (*github.Milestone, error) {
+	milestones, err := GitHubGetMilestones(d, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range milestones {
+		if m.GetTitle() == tag || m.GetTitle() == strings.TrimPrefix(tag, "v") {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// GitHubCreateMilestone creates a milestone with the title, description, due date and
+// state of m in a GitHub repository.
+func GitHubCreateMilestone(d *Data, repo string, m *github.Milestone, dryRun bool) (*github.Milestone, error) {
+	newMilestone := &github.Milestone{
+		Title:       m.Title,
+		Description: m.Description,
+		DueOn:       m.DueOn,
+		State:       m.State,
+	}
+	if dryRun {
+		Logf("%s: would create milestone %q in repository %q", PrefixDryRun, m.GetTitle(), repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateMilestone, Repo: repo, Title: m.GetTitle(),
+			Body: m.GetDescription(), DueOn: m.DueOn, State: m.GetState()})
+		return newMilestone, nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("creating milestone %q in repository %q", m.GetTitle(), repo)
+	created := newMilestone
+	// Before retrying check if the milestone was already created.
+	check := func() bool {
+		existing, err := GitHubGetMilestones(d, repo)
+		if err != nil {
+			return false
+		}
+		for _, e := range existing {
+			if e.GetTitle() == m.GetTitle() {
+				created = e
+				return true
+			}
+		}
+		return false
+	}
+	err := retryWrite(d, fmt.Sprintf("creating milestone %q", m.GetTitle()), check, func(ctx context.Context) (*github.Response, error) {
+		c, resp, err := d.client.Issues().CreateMilestone(ctx, ownerRepo[0], ownerRepo[1], newMilestone)
+		if err == nil {
+			created = c
+		}
+		return resp, err
+	})
+	return created, err
+}
+
+// GitHubEditMilestone updates the milestone with the given number in a GitHub repository
+// with the title, description, due date and state of m.
+func GitHubEditMilestone(d *Data, repo string, number int, m *github.Milestone, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would update milestone %q in repository %q", PrefixDryRun, m.GetTitle(), repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionEditMilestone, Repo: repo, Number: number, Title: m.GetTitle(),
+			Body: m.GetDescription(), DueOn: m.DueOn, State: m.GetState()})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("updating milestone %q in repository %q", m.GetTitle(), repo)
+	edit := &github.Milestone{
+		Title:       m.Title,
+		Description: m.Description,
+		DueOn:       m.DueOn,
+		State:       m.State,
+	}
+	// Editing a milestone is idempotent and can be retried without a check.
+	return retryWrite(d, fmt.Sprintf("updating milestone %q", m.GetTitle()), nil, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Issues().EditMilestone(ctx, ownerRepo[0], ownerRepo[1], number, edit)
+		return resp, err
+	})
+}
+
+// GitHubGetLabels obtains all labels from a GitHub repository.
+func GitHubGetLabels(d *Data, repo string) ([]*github.Label, error) {
+	ownerRepo := strings.Split(repo, "/")
+	Logf("getting labels from repository %q", repo)
+	result := []*github.Label{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		var labels []*github.Label
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("getting labels from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			labels, resp, err = d.client.Issues().ListLabels(ctx, ownerRepo[0], ownerRepo[1], opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, labels...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GitHubCreateLabel creates a label with the name, color and description of l
+// in a GitHub repository.
+func GitHubCreateLabel(d *Data, repo string, l *github.Label, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would create label %q in repository %q", PrefixDryRun, l.GetName(), repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionCreateLabel, Repo: repo, Name: l.GetName(),
+			Color: l.GetColor(), Body: l.GetDescription()})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("creating label %q in repository %q", l.GetName(), repo)
+	newLabel := &github.Label{Name: l.Name, Color: l.Color, Description: l.Description}
+	// Before retrying check if the label was already created.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		_, _, err := d.client.Issues().GetLabel(ctx, ownerRepo[0], ownerRepo[1], l.GetName())
+		return err == nil
+	}
+	return retryWrite(d, fmt.Sprintf("creating label %q", l.GetName()), check, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Issues().CreateLabel(ctx, ownerRepo[0], ownerRepo[1], newLabel)
+		return resp, err
+	})
+}
+
+// GitHubEditLabel updates the label with the given name in a GitHub repository with
+// the name, color and description of l.
+func GitHubEditLabel(d *Data, repo, name string, l *github.Label, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would update label %q in repository %q", PrefixDryRun, name, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionEditLabel, Repo: repo, Ref: name, Name: l.GetName(),
+			Color: l.GetColor(), Body: l.GetDescription()})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("updating label %q in repository %q", name, repo)
+	edit := &github.Label{Name: l.Name, Color: l.Color, Description: l.Description}
+	// Before retrying check if the label was already updated.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		existing, _, err := d.client.Issues().GetLabel(ctx, ownerRepo[0], ownerRepo[1], l.GetName())
+		return err == nil && existing.GetName() == l.GetName() && existing.GetColor() == l.GetColor() &&
+			existing.GetDescription() == l.GetDescription()
+	}
+	return retryWrite(d, fmt.Sprintf("updating label %q", name), check, func(ctx context.Context) (*github.Response, error) {
+		_, resp, err := d.client.Issues().EditLabel(ctx, ownerRepo[0], ownerRepo[1], name, edit)
+		return resp, err
+	})
+}
+
+// GitHubDeleteLabel deletes the label with the given name from a GitHub repository.
+// The label is removed from all issues and pull requests.
+func GitHubDeleteLabel(d *Data, repo, name string, dryRun bool) error {
+	if dryRun {
+		Logf("%s: would delete label %q from repository %q", PrefixDryRun, name, repo)
+		d.recordPlanStep(PlanStep{Action: PlanActionDeleteLabel, Repo: repo, Name: name})
+		return nil
+	}
+	ownerRepo := strings.Split(repo, "/")
+	Logf("deleting label %q from repository %q", name, repo)
+	// Before retrying check if the label was already deleted.
+	check := func() bool {
+		ctx, cancel := d.CreateContext()
+		defer cancel()
+		_, resp, err := d.client.Issues().GetLabel(ctx, ownerRepo[0], ownerRepo[1], name)
+		return err != nil && resp != nil && resp.StatusCode == http.StatusNotFound
+	}
+	return retryWrite(d, fmt.Sprintf("deleting label %q", name), check, func(ctx context.Context) (*github.Response, error) {
+		return d.client.Issues().DeleteLabel(ctx, ownerRepo[0], ownerRepo[1], name)
+	})
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package pkg
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -24,7 +26,9 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 )
@@ -131,7 +135,7 @@ func TestGitHubGetCreateRelease(t *testing.T) {
 				}
 
 				// Override/hardcode some values.
-				tt.data.Dest = "org/dest"
+				tt.data.Dest = []string{"org/dest"}
 				tt.data.PrefixBranch = PrefixBranch
 				tt.data.Force = true
 				tt.data.DryRun = dryRunVal
@@ -154,7 +158,7 @@ func TestGitHubGetCreateRelease(t *testing.T) {
 				tt.data.Transport.SetHandler(testRefs, handlerRefs)
 				tt.data.Transport.SetHandler(testReleases, handlerReleases)
 
-				rel, err := GitHubGetCreateRelease(tt.data, tt.data.Dest, tt.data.ReleaseTag, tt.releaseBody, dryRunVal)
+				rel, err := GitHubGetCreateRelease(tt.data, tt.data.DestRepo(), tt.data.ReleaseTag, tt.releaseBody, dryRunVal)
 				if (err != nil) != tt.expectedError {
 					t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
 				}
@@ -270,7 +274,7 @@ func TestGitHubUploadReleaseAssets(t *testing.T) {
 
 				// Override/hardcode some values.
 				data := &Data{}
-				data.Dest = "org/dest"
+				data.Dest = []string{"org/dest"}
 				data.PrefixBranch = PrefixBranch
 				data.Force = true
 				data.DryRun = dryRunVal
@@ -294,7 +298,7 @@ func TestGitHubUploadReleaseAssets(t *testing.T) {
 					handlerReleaseAssets,
 				)
 
-				assets, err := GitHubUploadReleaseAssets(data, data.Dest, &release, am, dryRunVal)
+				assets, err := GitHubUploadReleaseAssets(data, data.DestRepo(), &release, am, dryRunVal)
 				if (err != nil) != tt.expectedError {
 					t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
 				}
@@ -314,6 +318,270 @@ func TestGitHubUploadReleaseAssets(t *testing.T) {
 	}
 }
 
+func TestGitHubCreateRef(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		existingRefs  []*github.Reference
+		expectedRefs  []*github.Reference
+		expectedKind  ErrorKind
+		expectedError bool
+	}{
+		{
+			name:         "valid: create a ref",
+			existingRefs: []*github.Reference{},
+			expectedRefs: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("sha1")}},
+			},
+		},
+		{
+			name:         "valid: the ref already exists at the same commit",
+			existingRefs: []*github.Reference{newTestRef("refs/tags/v1.17.0", "sha1", "commit")},
+			expectedRefs: []*github.Reference{newTestRef("refs/tags/v1.17.0", "sha1", "commit")},
+		},
+		{
+			name:          "invalid: the ref already exists at another commit",
+			existingRefs:  []*github.Reference{newTestRef("refs/tags/v1.17.0", "sha2", "commit")},
+			expectedRefs:  []*github.Reference{newTestRef("refs/tags/v1.17.0", "sha2", "commit")},
+			expectedKind:  ErrorKindConflict,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Timeout: time.Minute}
+			refs := tt.existingRefs
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs", NewReferenceHandler(&refs, nil))
+
+			ref, err := GitHubCreateRef(d, "org/repo", "refs/tags/v1.17.0", "sha1", false)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if kind := ErrorKindOf(err); kind != tt.expectedKind {
+				t.Errorf("expected error kind %q, got %q", tt.expectedKind, kind)
+			}
+			if err == nil && ref.GetObject().GetSHA() != "sha1" {
+				t.Errorf("expected ref at commit %q, got %q", "sha1", ref.GetObject().GetSHA())
+			}
+			if !reflect.DeepEqual(refs, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", refsToString(tt.expectedRefs), refsToString(refs))
+			}
+		})
+	}
+}
+
+func TestGitHubGetReleaseMilestone(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name              string
+		milestones        []*github.Milestone
+		expectedMilestone *github.Milestone
+		expectedError     bool
+	}{
+		{
+			name: "valid: milestone with the title of the tag",
+			milestones: []*github.Milestone{
+				&github.Milestone{Number: github.Int(1), Title: github.String("v1.17.0")},
+				&github.Milestone{Number: github.Int(2), Title: github.String("v1.18.0")},
+			},
+			expectedMilestone: &github.Milestone{Number: github.Int(2), Title: github.String("v1.18.0")},
+		},
+		{
+			name: "valid: milestone with the title of the tag without the v prefix",
+			milestones: []*github.Milestone{
+				&github.Milestone{Number: github.Int(1), Title: github.String("1.18.0")},
+			},
+			expectedMilestone: &github.Milestone{Number: github.Int(1), Title: github.String("1.18.0")},
+		},
+		{
+			name: "valid: no milestone for the tag",
+			milestones: []*github.Milestone{
+				&github.Milestone{Number: github.Int(1), Title: github.String("v1.18.1")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/milestones", NewMilestoneHandler(&tt.milestones, nil))
+
+			m, err := GitHubGetReleaseMilestone(d, "org/repo", "v1.18.0")
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(m, tt.expectedMilestone) {
+				t.Errorf("expected milestone:\n%v\ngot:\n%v", tt.expectedMilestone, m)
+			}
+		})
+	}
+}
+
+func TestGitHubCompareBranches(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name             string
+		totalCommits     int
+		expectedCommits  int
+		expectedRequests int
+	}{
+		{
+			name:             "valid: a single page",
+			totalCommits:     42,
+			expectedCommits:  42,
+			expectedRequests: 1,
+		},
+		{
+			name:             "valid: more commits than the limit of a comparison without pagination",
+			totalCommits:     260,
+			expectedCommits:  260,
+			expectedRequests: 3,
+		},
+		{
+			name:             "valid: no commits",
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const compareURL = "https://api.github.com/repos/org/repo/compare/"
+			var requests int
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler(compareURL, func(req *http.Request) (*http.Response, error) {
+				requests++
+				page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+				perPage, _ := strconv.Atoi(req.URL.Query().Get("per_page"))
+				cmp := &github.CommitsComparison{
+					Status:       github.String("ahead"),
+					TotalCommits: github.Int(tt.totalCommits),
+				}
+				for i := (page - 1) * perPage; i < page*perPage && i < tt.totalCommits; i++ {
+					cmp.Commits = append(cmp.Commits, github.RepositoryCommit{SHA: github.String(strconv.Itoa(i))})
+				}
+				header := http.Header{}
+				if page*perPage < tt.totalCommits {
+					header.Set("Link", fmt.Sprintf(`<%sbase...head?page=%d>; rel="next"`, compareURL, page+1))
+				}
+				buf, err := json.Marshal(cmp)
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+					Header:     header,
+				}, nil
+			})
+
+			cmp, err := GitHubCompareBranches(d, "org/repo", "base", "head")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cmp.Commits) != tt.expectedCommits {
+				t.Errorf("expected %d commits, got %d", tt.expectedCommits, len(cmp.Commits))
+			}
+			for i, c := range cmp.Commits {
+				if c.GetSHA() != strconv.Itoa(i) {
+					t.Fatalf("expected commit %d to have SHA %q, got %q", i, strconv.Itoa(i), c.GetSHA())
+				}
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestGitHubCreateIssueComment(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name             string
+		issue            string
+		body             string
+		skipDryRun       bool
+		methodErrors     map[string]bool
+		expectedComments int
+		expectedError    bool
+	}{
+		{
+			name:             "valid: create a comment",
+			issue:            "org/dest#123",
+			body:             "foo",
+			expectedComments: 1,
+		},
+		{
+			name:          "invalid: malformed issue",
+			issue:         "org/dest",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: issue number is not a number",
+			issue:         "org/dest#foo",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: simulate error creating a comment",
+			issue:         "org/dest#123",
+			methodErrors:  map[string]bool{http.MethodPost: true},
+			skipDryRun:    true,
+			expectedError: true,
+		},
+	}
+
+	// Make sure there are consistent results between dry-run and regular mode.
+	for _, dryRunVal := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s (dryRun=%v)", tt.name, dryRunVal), func(t *testing.T) {
+				if tt.skipDryRun && dryRunVal {
+					t.Skip()
+				}
+				if tt.methodErrors == nil {
+					tt.methodErrors = map[string]bool{}
+				}
+
+				// Create fake client and setup endpoint handlers.
+				data := &Data{}
+				comments := []*github.IssueComment{}
+				NewClient(data, NewTransport())
+				data.Transport.SetHandler("https://api.github.com/repos/org/dest/issues/123/comments",
+					NewIssueCommentHandler(&comments, tt.methodErrors))
+
+				comment, err := GitHubCreateIssueComment(data, tt.issue, tt.body, dryRunVal)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+				}
+				if err != nil {
+					return
+				}
+				if comment.GetBody() != tt.body {
+					t.Errorf("expected comment body %q, got %q", tt.body, comment.GetBody())
+				}
+				if dryRunVal {
+					return
+				}
+				if len(comments) != tt.expectedComments {
+					t.Errorf("expected %d comments, got %d", tt.expectedComments, len(comments))
+				}
+			})
+		}
+	}
+}
+
 // createTempTestFiles takes an assetMap and creates a new assetMap that points
 // to real files from a temporary directory.
 func createTempTestFiles(am assetMap) (assetMap, string, error) {
@@ -337,3 +605,28 @@ func createTempTestFiles(am assetMap) (assetMap, string, error) {
 	}
 	return newAssetMap, dir, nil
 }
+
+func TestGitHubEnterprise(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	refs := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+	}
+	d := &Data{GitHubBaseURL: "https://github.example.com"}
+	NewClient(d, NewTransport())
+	d.Transport.SetHandler("https://github.example.com/api/v3/repos/org/dest/git/refs", NewReferenceHandler(&refs, nil))
+
+	if got, expected := d.client.BaseURL().String(), "https://github.example.com/api/v3/"; got != expected {
+		t.Errorf("expected base URL %q, got %q", expected, got)
+	}
+	if got, expected := d.client.UploadURL().String(), "https://github.example.com/api/v3/"; got != expected {
+		t.Errorf("expected upload URL %q, got %q", expected, got)
+	}
+	tags, err := GitHubGetTags(d, "org/dest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, refs) {
+		t.Errorf("expected tags %v, got %v", refs, tags)
+	}
+}
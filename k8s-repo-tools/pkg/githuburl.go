@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// GitHubURLScheme is the scheme of locations of the format
+// 'github://org/repo@ref/path' that ReadFromFileOrURL reads from a GitHub
+// repository. '@ref' is optional and defaults to the default branch.
+const GitHubURLScheme = "github"
+
+// urlTokenHosts are the hosts to which ReadFromURL sends the token set
+// with ConfigureURLToken.
+var urlTokenHosts = map[string]bool{
+	"api.github.com":            true,
+	"raw.githubusercontent.com": true,
+}
+
+var (
+	urlToken      string
+	urlTokenMutex sync.RWMutex
+)
+
+// ConfigureURLToken sets a GitHub token that ReadFromURL sends to
+// api.github.com and raw.githubusercontent.com, so that files of private
+// repositories can be read. An empty token disables authentication.
+func ConfigureURLToken(token string) {
+	urlTokenMutex.Lock()
+	defer urlTokenMutex.Unlock()
+	urlToken = token
+}
+
+func getURLToken() string {
+	urlTokenMutex.RLock()
+	defer urlTokenMutex.RUnlock()
+	return urlToken
+}
+
+// setURLToken adds the token set with ConfigureURLToken to a request if
+// the request is sent with HTTPS to one of the urlTokenHosts.
+func setURLToken(req *http.Request) {
+	token := getURLToken()
+	if len(token) == 0 || req.URL.Scheme != "https" || !urlTokenHosts[strings.ToLower(req.URL.Hostname())] {
+		return
+	}
+	req.Header.Set("Authorization", "token "+token)
+}
+
+// GitHubURL is a file in a GitHub repository.
+type GitHubURL struct {
+	// Repo is the repository of the format 'org/repo'.
+	Repo string
+	// Ref is a tag, branch or commit SHA. Empty means the default branch.
+	Ref string
+	// Path is the path of the file in the repository.
+	Path string
+}
+
+// IsGitHubURL returns true if a location uses the GitHubURLScheme.
+func IsGitHubURL(location string) bool {
+	return strings.HasPrefix(location, GitHubURLScheme+"://")
+}
+
+// ParseGitHubURL parses a location of the format 'github://org/repo@ref/path'.
+// The ref cannot contain '/'.
+func ParseGitHubURL(location string) (*GitHubURL, error) {
+	if !IsGitHubURL(location) {
+		return nil, errors.Errorf("the location %q does not start with %s://", location, GitHubURLScheme)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(location, GitHubURLScheme+"://"), "/", 3)
+	if len(parts) != 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return nil, errors.Errorf("the location %q must be of the format '%s://org/repo@ref/path'", location, GitHubURLScheme)
+	}
+	u := &GitHubURL{Path: parts[2]}
+	repo := parts[1]
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, u.Ref = repo[:i], repo[i+1:]
+		if len(u.Ref) == 0 {
+			return nil, errors.Errorf("the location %q has an empty ref", location)
+		}
+	}
+	u.Repo = parts[0] + "/" + repo
+	if err := ValidateRepo(location, u.Repo); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// RawURL returns the raw.githubusercontent.com URL of the file. HEAD is
+// used for the default branch.
+func (u *GitHubURL) RawURL() string {
+	ref := u.Ref
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", u.Repo, ref, strings.TrimPrefix(u.Path, "/"))
+}
+
+// String returns the location of the file in the GitHubURLScheme.
+func (u *GitHubURL) String() string {
+	repo := u.Repo
+	if len(u.Ref) != 0 {
+		repo += "@" + u.Ref
+	}
+	return fmt.Sprintf("%s://%s/%s", GitHubURLScheme, repo, u.Path)
+}
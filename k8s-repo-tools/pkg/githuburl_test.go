@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseGitHubURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		location       string
+		expectedURL    *GitHubURL
+		expectedRawURL string
+		expectedError  bool
+	}{
+		{
+			name:           "valid: with ref",
+			location:       "github://org/repo@v1.0.0/go.mod",
+			expectedURL:    &GitHubURL{Repo: "org/repo", Ref: "v1.0.0", Path: "go.mod"},
+			expectedRawURL: "https://raw.githubusercontent.com/org/repo/v1.0.0/go.mod",
+		},
+		{
+			name:           "valid: default branch and nested path",
+			location:       "github://org/repo/staging/src/go.mod",
+			expectedURL:    &GitHubURL{Repo: "org/repo", Path: "staging/src/go.mod"},
+			expectedRawURL: "https://raw.githubusercontent.com/org/repo/HEAD/staging/src/go.mod",
+		},
+		{
+			name:          "invalid: other scheme",
+			location:      "https://github.com/org/repo",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing path",
+			location:      "github://org/repo@v1.0.0",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing repo",
+			location:      "github://org/go.mod",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: empty ref",
+			location:      "github://org/repo@/go.mod",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: empty org",
+			location:      "github:///repo/go.mod",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := ParseGitHubURL(tt.location)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(u, tt.expectedURL) {
+				t.Errorf("expected %+v, got %+v", tt.expectedURL, u)
+			}
+			if u.RawURL() != tt.expectedRawURL {
+				t.Errorf("expected raw URL %q, got %q", tt.expectedRawURL, u.RawURL())
+			}
+			if u.String() != tt.location {
+				t.Errorf("expected location %q, got %q", tt.location, u.String())
+			}
+		})
+	}
+}
+
+func TestSetURLToken(t *testing.T) {
+	const token = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+
+	tests := []struct {
+		name     string
+		token    string
+		url      string
+		expected string
+	}{
+		{
+			name:     "raw.githubusercontent.com",
+			token:    token,
+			url:      "https://raw.githubusercontent.com/org/repo/HEAD/go.mod",
+			expected: "token " + token,
+		},
+		{
+			name:     "api.github.com",
+			token:    token,
+			url:      "https://API.github.com/repos/org/repo/contents/go.mod",
+			expected: "token " + token,
+		},
+		{
+			name:  "no token",
+			url:   "https://raw.githubusercontent.com/org/repo/HEAD/go.mod",
+			token: "",
+		},
+		{
+			name:  "other host",
+			token: token,
+			url:   "https://example.com/go.mod",
+		},
+		{
+			name:  "not HTTPS",
+			token: token,
+			url:   "http://raw.githubusercontent.com/org/repo/HEAD/go.mod",
+		},
+	}
+
+	defer ConfigureURLToken("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureURLToken(tt.token)
+			req, err := http.NewRequest("GET", tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			setURLToken(req)
+			if got := req.Header.Get("Authorization"); got != tt.expected {
+				t.Errorf("expected Authorization header %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReadFromFileOrURLGitHubURL(t *testing.T) {
+	if _, err := ReadFromFileOrURL("github://org/go.mod", -1); err == nil {
+		t.Error("expected an error for a malformed GitHub location")
+	}
+}
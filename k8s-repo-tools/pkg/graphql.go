@@ -0,0 +1,295 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+var (
+	// graphQLPageSize is the number of refs per prefix that are fetched with one query.
+	graphQLPageSize = 100
+	// graphQLBatchSize is the number of refs that are created with one mutation.
+	graphQLBatchSize = 25
+)
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the body of a GraphQL response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Type    string `json:"type,omitempty"`
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+// graphQLRefConnection is a page of refs with the same prefix.
+type graphQLRefConnection struct {
+	Nodes []struct {
+		Name   string `json:"name"`
+		Target struct {
+			Typename string `json:"__typename"`
+			OID      string `json:"oid"`
+		} `json:"target"`
+	} `json:"nodes"`
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+// graphQLURL returns the URL of the GraphQL endpoint for the REST API base URL
+// of the client. For GitHub Enterprise Server '/api/v3/' becomes '/api/graphql'.
+func graphQLURL(d *Data) string {
+	u := *d.client.BaseURL()
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+	} else {
+		u.Path += "graphql"
+	}
+	return u.String()
+}
+
+// gitHubGraphQL sends a GraphQL query and decodes the data of the response in result.
+// Errors in the response are returned as a single error.
+func gitHubGraphQL(ctx context.Context, d *Data, query string, vars map[string]interface{}, result interface{}) (*github.Response, error) {
+	req, err := d.client.NewRequest(http.MethodPost, graphQLURL(d), &graphQLRequest{Query: query, Variables: vars})
+	if err != nil {
+		return nil, err
+	}
+	body := &graphQLResponse{}
+	resp, err := d.client.Do(ctx, req, body)
+	if err != nil {
+		return resp, err
+	}
+	if len(body.Errors) != 0 {
+		msgs := make([]string, len(body.Errors))
+		for i, e := range body.Errors {
+			msgs[i] = e.Message
+		}
+		return resp, errors.Errorf("GraphQL request failed: %s", strings.Join(msgs, "; "))
+	}
+	if result == nil {
+		return resp, nil
+	}
+	return resp, json.Unmarshal(body.Data, result)
+}
+
+// normalizeRefPrefix returns a prefix like 'refs/tags' in the format
+// 'refs/tags/' that is expected by the GraphQL API.
+func normalizeRefPrefix(prefix string) string {
+	prefix = "refs/" + strings.TrimPrefix(prefix, "refs/")
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// gitHubGetRefsGraphQL obtains the ID of a repository and its refs for a list of
+// prefixes such as 'refs/tags'. All prefixes are fetched with the same query,
+// so a single request is made per page of refs instead of one per prefix.
+// The refs are returned in a map with the original prefixes as keys.
+func gitHubGetRefsGraphQL(d *Data, repo string, prefixes ...string) (string, map[string][]*github.Reference, error) {
+	ownerRepo := strings.Split(repo, "/")
+	result := map[string][]*github.Reference{}
+	cursors := make([]string, len(prefixes))
+	pending := make([]int, len(prefixes))
+	for i := range prefixes {
+		result[prefixes[i]] = []*github.Reference{}
+		pending[i] = i
+	}
+
+	var id string
+	for first := true; first || len(pending) != 0; first = false {
+		// Build a query with one aliased connection per pending prefix.
+		vars := map[string]interface{}{"owner": ownerRepo[0], "name": ownerRepo[1]}
+		var params, fields strings.Builder
+		if len(pending) != 0 {
+			params.WriteString(", $first: Int!")
+			vars["first"] = graphQLPageSize
+		}
+		for _, i := range pending {
+			fmt.Fprintf(&params, ", $p%d: String!, $c%d: String", i, i)
+			fmt.Fprintf(&fields, " r%[1]d: refs(refPrefix: $p%[1]d, first: $first, after: $c%[1]d) {"+
+				" nodes { name target { __typename oid } } pageInfo { hasNextPage endCursor } }", i)
+			vars[fmt.Sprintf("p%d", i)] = normalizeRefPrefix(prefixes[i])
+			if len(cursors[i]) != 0 {
+				vars[fmt.Sprintf("c%d", i)] = cursors[i]
+			}
+		}
+		query := fmt.Sprintf("query($owner: String!, $name: String!%s) "+
+			"{ repository(owner: $owner, name: $name) { id%s } }", params.String(), fields.String())
+
+		var data struct {
+			Repository map[string]json.RawMessage `json:"repository"`
+		}
+		err := retry(d, fmt.Sprintf("getting refs from repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			return gitHubGraphQL(ctx, d, query, vars, &data)
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if data.Repository == nil {
+			return "", nil, errors.Errorf("could not find repository %q", repo)
+		}
+		if err := json.Unmarshal(data.Repository["id"], &id); err != nil {
+			return "", nil, errors.Wrapf(err, "could not parse the ID of repository %q", repo)
+		}
+
+		var next []int
+		for _, i := range pending {
+			conn := graphQLRefConnection{}
+			if err := json.Unmarshal(data.Repository[fmt.Sprintf("r%d", i)], &conn); err != nil {
+				return "", nil, errors.Wrapf(err, "could not parse %q from repository %q", prefixes[i], repo)
+			}
+			prefix := normalizeRefPrefix(prefixes[i])
+			for _, n := range conn.Nodes {
+				result[prefixes[i]] = append(result[prefixes[i]], &github.Reference{
+					Ref: github.String(prefix + n.Name),
+					Object: &github.GitObject{
+						SHA:  github.String(n.Target.OID),
+						Type: github.String(strings.ToLower(n.Target.Typename)),
+					},
+				})
+			}
+			if conn.PageInfo.HasNextPage {
+				cursors[i] = conn.PageInfo.EndCursor
+				next = append(next, i)
+			}
+		}
+		pending = next
+	}
+	return id, result, nil
+}
+
+// GitHubGetTagsAndBranches obtains the tags and branches of a GitHub repository.
+// If d.GraphQL is set both are fetched with the same GraphQL query, otherwise
+// two REST requests are made concurrently.
+func GitHubGetTagsAndBranches(d *Data, repo string) ([]*github.Reference, []*github.Reference, error) {
+	if d.GraphQL {
+		Logf("getting tags and branches from repository %q", repo)
+		_, refs, err := gitHubGetRefsGraphQL(d, repo, "refs/tags", "refs/heads")
+		if err != nil {
+			return nil, nil, err
+		}
+		return refs["refs/tags"], refs["refs/heads"], nil
+	}
+	var tags, branches []*github.Reference
+	err := RunConcurrently(
+		func() (err error) {
+			tags, err = GitHubGetTags(d, repo)
+			return err
+		},
+		func() (err error) {
+			branches, err = GitHubGetBranches(d, repo)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tags, branches, nil
+}
+
+// gitHubCreateRefsGraphQL creates refs in a GitHub repository with GraphQL
+// mutations of up to graphQLBatchSize refs each. Created refs are appended
+// to refsDest. If the process is interrupted ErrInterrupted is returned
+// before creating the next batch.
+func gitHubCreateRefsGraphQL(d *Data, repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	if err := checkAllowedDest(SplitRepos(d.AllowedDest), repo); err != nil {
+		return err
+	}
+	id, _, err := gitHubGetRefsGraphQL(d, repo)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(refs); start += graphQLBatchSize {
+		if d.Interrupted() {
+			return ErrInterrupted
+		}
+		end := start + graphQLBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		batch := refs[start:end]
+
+		// Build a mutation with one aliased createRef per ref.
+		vars := map[string]interface{}{}
+		var params, fields strings.Builder
+		for i, ref := range batch {
+			Logf("creating ref %q from commit %q in repository %q", ref.GetRef(), ref.GetObject().GetSHA(), repo)
+			if i > 0 {
+				params.WriteString(", ")
+			}
+			fmt.Fprintf(&params, "$i%d: CreateRefInput!", i)
+			fmt.Fprintf(&fields, " r%[1]d: createRef(input: $i%[1]d) { ref { name } }", i)
+			vars[fmt.Sprintf("i%d", i)] = map[string]string{
+				"repositoryId": id,
+				"name":         ref.GetRef(),
+				"oid":          ref.GetObject().GetSHA(),
+			}
+		}
+		mutation := fmt.Sprintf("mutation(%s) {%s }", params.String(), fields.String())
+
+		// Before retrying check if all refs were already created from the same commits.
+		check := func() bool {
+			for _, ref := range batch {
+				existing, err := GitHubGetRef(d, repo, ref.GetRef())
+				if err != nil || existing.GetObject().GetSHA() != ref.GetObject().GetSHA() {
+					return false
+				}
+			}
+			return true
+		}
+		desc := fmt.Sprintf("creating %d refs in repository %q", len(batch), repo)
+		err := retryWrite(d, desc, check, func(ctx context.Context) (*github.Response, error) {
+			return gitHubGraphQL(ctx, d, mutation, vars, nil)
+		})
+		if err == nil {
+			recordRefsCreated(len(batch))
+			for _, ref := range batch {
+				recordChange("created ref `%s` from commit `%s` in repository `%s`", ref.GetRef(), ref.GetObject().GetSHA(), repo)
+			}
+		} else if !d.Interrupted() && check() {
+			// The refs that already exist at the same commits, for example from a previous
+			// run that did not complete, are not an error so that re-runs converge.
+			Logf("all %d refs already exist at the same commits in repository %q", len(batch), repo)
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		*refsDest = append(*refsDest, batch...)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func newTestRef(ref, sha, typ string) *github.Reference {
+	return &github.Reference{Ref: github.String(ref), Object: &github.GitObject{SHA: github.String(sha), Type: github.String(typ)}}
+}
+
+func refsToString(refs []*github.Reference) string {
+	var b strings.Builder
+	for _, ref := range refs {
+		fmt.Fprintf(&b, "%s %s %s\n", ref.GetRef(), ref.GetObject().GetSHA(), ref.GetObject().GetType())
+	}
+	return b.String()
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		expectedURL string
+	}{
+		{
+			name:        "valid: github.com",
+			expectedURL: "https://api.github.com/graphql",
+		},
+		{
+			name:        "valid: GitHub Enterprise Server",
+			baseURL:     "https://github.example.com/api/v3/",
+			expectedURL: "https://github.example.com/api/graphql",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{GitHubBaseURL: tt.baseURL}
+			NewClient(d, NewTransport())
+			if got := graphQLURL(d); got != tt.expectedURL {
+				t.Errorf("expected URL %q, got %q", tt.expectedURL, got)
+			}
+		})
+	}
+}
+
+func TestGitHubGetTagsAndBranchesGraphQL(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	defer func(size int) { graphQLPageSize = size }(graphQLPageSize)
+	graphQLPageSize = 2
+
+	refs := []*github.Reference{
+		newTestRef("refs/tags/v1.17.0", "sha1", "tag"),
+		newTestRef("refs/tags/v1.17.1", "sha2", "commit"),
+		newTestRef("refs/tags/v1.18.0", "sha3", "tag"),
+		newTestRef("refs/tags/v1.18.1", "sha4", "commit"),
+		newTestRef("refs/tags/v1.19.0", "sha5", "commit"),
+		newTestRef("refs/heads/master", "sha6", "commit"),
+	}
+
+	tests := []struct {
+		name             string
+		repo             string
+		expectedTags     []*github.Reference
+		expectedBranches []*github.Reference
+		expectedRequests int
+		expectedError    bool
+	}{
+		{
+			name:             "valid: tags and branches are fetched with the same queries",
+			repo:             "org/repo",
+			expectedTags:     refs[:5],
+			expectedBranches: refs[5:],
+			expectedRequests: 3,
+		},
+		{
+			name:             "invalid: missing repository",
+			repo:             "org/missing",
+			expectedRequests: 1,
+			expectedError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{GraphQL: true}
+			var requests int
+			handler := NewGraphQLRefHandler(map[string]*[]*github.Reference{"org/repo": &refs}, nil)
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/graphql", func(req *http.Request) (*http.Response, error) {
+				requests++
+				return handler(req)
+			})
+
+			tags, branches, err := GitHubGetTagsAndBranches(d, tt.repo)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tags, tt.expectedTags) {
+				t.Errorf("expected tags:\n%s\ngot:\n%s", refsToString(tt.expectedTags), refsToString(tags))
+			}
+			if !reflect.DeepEqual(branches, tt.expectedBranches) {
+				t.Errorf("expected branches:\n%s\ngot:\n%s", refsToString(tt.expectedBranches), refsToString(branches))
+			}
+		})
+	}
+}
+
+func TestGitHubCreateRefsGraphQL(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	defer func(size int) { graphQLBatchSize = size }(graphQLBatchSize)
+	graphQLBatchSize = 2
+
+	tests := []struct {
+		name             string
+		existingRefs     []*github.Reference
+		newRefs          []*github.Reference
+		expectedRefs     []*github.Reference
+		expectedCreated  int
+		expectedRequests int
+		expectedError    bool
+	}{
+		{
+			name:         "valid: refs are created in batches",
+			existingRefs: []*github.Reference{newTestRef("refs/heads/master", "sha0", "commit")},
+			newRefs: []*github.Reference{
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+				newTestRef("refs/tags/v1.18.0", "sha2", "commit"),
+				newTestRef("refs/tags/v1.19.0", "sha3", "commit"),
+			},
+			expectedRefs: []*github.Reference{
+				newTestRef("refs/heads/master", "sha0", "commit"),
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+				newTestRef("refs/tags/v1.18.0", "sha2", "commit"),
+				newTestRef("refs/tags/v1.19.0", "sha3", "commit"),
+			},
+			expectedCreated:  3,
+			expectedRequests: 3,
+		},
+		{
+			name:             "valid: no refs to create",
+			existingRefs:     []*github.Reference{},
+			expectedRefs:     []*github.Reference{},
+			expectedRequests: 0,
+		},
+		{
+			name:         "valid: a ref that already exists at the same commit is skipped",
+			existingRefs: []*github.Reference{newTestRef("refs/tags/v1.18.0", "sha2", "commit")},
+			newRefs: []*github.Reference{
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+				newTestRef("refs/tags/v1.18.0", "sha2", "commit"),
+				newTestRef("refs/tags/v1.19.0", "sha3", "commit"),
+			},
+			expectedRefs: []*github.Reference{
+				newTestRef("refs/tags/v1.18.0", "sha2", "commit"),
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+				newTestRef("refs/tags/v1.19.0", "sha3", "commit"),
+			},
+			expectedCreated:  3,
+			expectedRequests: 3,
+		},
+		{
+			name:         "invalid: a ref that already exists at another commit fails its batch",
+			existingRefs: []*github.Reference{newTestRef("refs/tags/v1.18.0", "sha9", "commit")},
+			newRefs: []*github.Reference{
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+				newTestRef("refs/tags/v1.18.0", "sha2", "commit"),
+				newTestRef("refs/tags/v1.19.0", "sha3", "commit"),
+			},
+			expectedRefs: []*github.Reference{
+				newTestRef("refs/tags/v1.18.0", "sha9", "commit"),
+				newTestRef("refs/tags/v1.17.0", "sha1", "commit"),
+			},
+			expectedRequests: 2,
+			expectedError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{GraphQL: true, Timeout: time.Minute}
+			refs := tt.existingRefs
+			var requests int
+			handler := NewGraphQLRefHandler(map[string]*[]*github.Reference{"org/repo": &refs}, nil)
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/graphql", func(req *http.Request) (*http.Response, error) {
+				requests++
+				return handler(req)
+			})
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs", NewReferenceHandler(&refs, nil))
+
+			created := []*github.Reference{}
+			err := gitHubCreateRefsGraphQL(d, "org/repo", &created, tt.newRefs)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+			if len(created) != tt.expectedCreated {
+				t.Errorf("expected %d created refs, got %d", tt.expectedCreated, len(created))
+			}
+			if !reflect.DeepEqual(refs, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", refsToString(tt.expectedRefs), refsToString(refs))
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultHTTPMaxIdleConns ...
+	DefaultHTTPMaxIdleConns = 100
+	// DefaultHTTPMaxIdleConnsPerHost is higher than the default of net/http, which
+	// keeps only two idle connections per host. Most requests go to the GitHub API,
+	// so bursts of parallel requests would otherwise open new connections every time.
+	DefaultHTTPMaxIdleConnsPerHost = 32
+	// DefaultHTTPIdleConnTimeout ...
+	DefaultHTTPIdleConnTimeout = 90 * time.Second
+)
+
+var (
+	httpTransport      http.RoundTripper = newDefaultHTTPTransport()
+	httpTransportMutex sync.RWMutex
+)
+
+// HTTPOptions holds the settings of the transport that is used by all HTTP clients
+// created with NewHTTPClient.
+type HTTPOptions struct {
+	// Proxy is the URL of an HTTP(S) proxy; if empty the proxy is taken from
+	// the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+	Proxy string
+	// CABundle is the path to a PEM file with CA certificates that are trusted
+	// in addition to the system CA certificates.
+	CABundle string
+	// MaxIdleConns is the maximum number of idle connections to all hosts.
+	// Zero means no limit.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost is the maximum number of connections per host, including
+	// connections that are in use. Zero means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is the time after which idle connections are closed.
+	// Zero means no timeout.
+	IdleConnTimeout time.Duration
+	// DisableKeepAlives closes every connection after a single request.
+	DisableKeepAlives bool
+	// DisableHTTP2 only uses HTTP/1.1.
+	DisableHTTP2 bool
+}
+
+// DefaultHTTPOptions returns the default settings of the HTTP transport.
+func DefaultHTTPOptions() HTTPOptions {
+	return HTTPOptions{
+		MaxIdleConns:        DefaultHTTPMaxIdleConns,
+		MaxIdleConnsPerHost: DefaultHTTPMaxIdleConnsPerHost,
+		IdleConnTimeout:     DefaultHTTPIdleConnTimeout,
+	}
+}
+
+// ConfigureHTTP configures the transport that is used by all HTTP clients
+// created with NewHTTPClient with the given proxy and CA bundle and the
+// default values of all other HTTPOptions.
+func ConfigureHTTP(proxy, caBundle string) error {
+	opts := DefaultHTTPOptions()
+	opts.Proxy = proxy
+	opts.CABundle = caBundle
+	return ConfigureHTTPWithOptions(opts)
+}
+
+// ConfigureHTTPWithOptions configures the transport that is used by all HTTP
+// clients created with NewHTTPClient.
+func ConfigureHTTPWithOptions(opts HTTPOptions) error {
+	transport, err := newHTTPTransport(opts)
+	if err != nil {
+		return err
+	}
+	httpTransportMutex.Lock()
+	defer httpTransportMutex.Unlock()
+	httpTransport = transport
+	return nil
+}
+
+// newHTTPTransport creates a transport from HTTPOptions.
+func newHTTPTransport(opts HTTPOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	if opts.DisableHTTP2 {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade.
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if len(opts.Proxy) != 0 {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || len(proxyURL.Scheme) == 0 || len(proxyURL.Host) == 0 {
+			return nil, errors.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if len(opts.CABundle) != 0 {
+		pool, err := newCertPool(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return transport, nil
+}
+
+// newDefaultHTTPTransport creates a transport with DefaultHTTPOptions.
+func newDefaultHTTPTransport() http.RoundTripper {
+	transport, err := newHTTPTransport(DefaultHTTPOptions())
+	if err != nil {
+		// The default options do not include a proxy or a CA bundle, which could fail.
+		panic(err)
+	}
+	return transport
+}
+
+// newCertPool returns the system cert pool with the certificates
+// from a PEM file appended to it.
+func newCertPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the CA bundle %q", path)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		V(1).Warningf("could not load the system CA certificates, using only %q: %v", path, err)
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no PEM certificates found in the CA bundle %q", path)
+	}
+	return pool, nil
+}
+
+// NewHTTPClient returns an HTTP client that uses the transport configured
+// with ConfigureHTTP. A zero timeout means no timeout.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	httpTransportMutex.RLock()
+	defer httpTransportMutex.RUnlock()
+	return &http.Client{Transport: httpTransport, Timeout: timeout}
+}
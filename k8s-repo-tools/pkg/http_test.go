@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigureHTTP(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer ConfigureHTTP("", "")
+
+	dir, err := ioutil.TempDir("", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A TLS server with a certificate that is not trusted by the system.
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "tls")
+	}))
+	defer tlsServer.Close()
+	caBundle := filepath.Join(dir, "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := ioutil.WriteFile(caBundle, pemData, 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidBundle := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalidBundle, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A server that acts as a proxy for plain HTTP requests.
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "proxy %s", r.URL.Host)
+	}))
+	defer proxyServer.Close()
+
+	tests := []struct {
+		name          string
+		proxy         string
+		caBundle      string
+		url           string
+		expectedBody  string
+		expectedError bool
+	}{
+		{
+			name:         "valid: request through a proxy",
+			proxy:        proxyServer.URL,
+			url:          "http://example.invalid/",
+			expectedBody: "proxy example.invalid",
+		},
+		{
+			name:         "valid: request with a custom CA",
+			caBundle:     caBundle,
+			url:          tlsServer.URL,
+			expectedBody: "tls",
+		},
+		{
+			name:          "invalid: proxy is not a URL",
+			proxy:         "example.invalid",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: missing CA bundle",
+			caBundle:      filepath.Join(dir, "missing.pem"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: CA bundle without certificates",
+			caBundle:      invalidBundle,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConfigureHTTP(tt.proxy, tt.caBundle)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			body, err := readURL(NewHTTPClient(5*time.Second), tt.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func readURL(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestNewHTTPTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          HTTPOptions
+		expectedHTTP2 bool
+	}{
+		{
+			name:          "valid: default options",
+			opts:          DefaultHTTPOptions(),
+			expectedHTTP2: true,
+		},
+		{
+			name: "valid: custom connection pool",
+			opts: HTTPOptions{
+				MaxIdleConns:        10,
+				MaxIdleConnsPerHost: 5,
+				MaxConnsPerHost:     8,
+				IdleConnTimeout:     time.Second,
+			},
+			expectedHTTP2: true,
+		},
+		{
+			name: "valid: disable keep-alives and HTTP/2",
+			opts: HTTPOptions{
+				MaxIdleConnsPerHost: 2,
+				DisableKeepAlives:   true,
+				DisableHTTP2:        true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newHTTPTransport(tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if transport.MaxIdleConns != tt.opts.MaxIdleConns ||
+				transport.MaxIdleConnsPerHost != tt.opts.MaxIdleConnsPerHost ||
+				transport.MaxConnsPerHost != tt.opts.MaxConnsPerHost ||
+				transport.IdleConnTimeout != tt.opts.IdleConnTimeout ||
+				transport.DisableKeepAlives != tt.opts.DisableKeepAlives {
+				t.Fatalf("the transport does not match the options %+v", tt.opts)
+			}
+			http2 := transport.ForceAttemptHTTP2 && transport.TLSNextProto == nil
+			if http2 != tt.expectedHTTP2 {
+				t.Fatalf("expected HTTP/2 %v, got %v", tt.expectedHTTP2, http2)
+			}
+		})
+	}
+}
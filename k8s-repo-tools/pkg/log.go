@@ -32,13 +32,48 @@ import (
 )
 
 var (
-	logMutex = &sync.Mutex{}
-	stdout   io.Writer
-	stderr   io.Writer
+	logMutex  = &sync.Mutex{}
+	stdout    io.Writer
+	stderr    io.Writer
+	logFormat = LogFormatText
+	verbosity = DefaultVerbosity
+	quiet     bool
+
+	// defaultLogger is used by the package level log functions.
+	defaultLogger = NewLogger()
+
+	// finishHooks are called by FinishRun.
+	finishHooks []func(exitCode int, err error)
 
 	lineSeparator = strings.Repeat("*", 79)
 )
 
+const (
+	// DefaultVerbosity is the default log verbosity, which shows all messages
+	// including per-reference details. Lower values suppress details.
+	DefaultVerbosity = 2
+	// LogFormatText ...
+	LogFormatText = "text"
+	// LogFormatJSON ...
+	LogFormatJSON = "json"
+
+	// LogFieldTool is the log field with the name of a tool.
+	LogFieldTool = "tool"
+	// LogFieldRepo is the log field with a repository of the format 'org/repo'.
+	LogFieldRepo = "repo"
+	// LogFieldRef is the log field with a tag, branch or reference.
+	LogFieldRef = "ref"
+)
+
+// logLine is a single line of log output in JSON format.
+type logLine struct {
+	Level  string                 `json:"level"`
+	Time   string                 `json:"time"`
+	Caller string                 `json:"caller"`
+	Msg    string                 `json:"msg"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
 // SetLogWriters ...
 func SetLogWriters(out, err io.Writer) {
 	logMutex.Lock()
@@ -54,36 +89,307 @@ func GetLogWriters() (io.Writer, io.Writer) {
 	return stdout, stderr
 }
 
-func getLogPrefix(t string, f string) string {
-	const layout = "15:04:05.000000"
+// SetLogFormat sets the format of the log output. It must be
+// LogFormatText or LogFormatJSON.
+func SetLogFormat(format string) error {
+	if format != LogFormatText && format != LogFormatJSON {
+		return errors.Errorf("unknown log format %q, must be %q or %q", format, LogFormatText, LogFormatJSON)
+	}
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logFormat = format
+	return nil
+}
+
+// SetVerbosity sets the log verbosity. Messages logged with V(level)
+// are only shown if level is less or equal to the verbosity.
+func SetVerbosity(v int) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	verbosity = v
+}
+
+// SetQuiet enables or disables quiet mode, in which informational messages
+// are not logged. This leaves stdout only for the result of a tool.
+func SetQuiet(q bool) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	quiet = q
+}
+
+func isQuiet() bool {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return quiet
+}
+
+func getVerbosity() int {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return verbosity
+}
+
+// Verbose is returned by V and can be used for leveled logging.
+type Verbose bool
+
+// V returns a Verbose that only logs if level is less or equal to the
+// verbosity. Level 0 is always shown, level 1 is for general progress
+// details and level 2 is for per-reference and per-item details.
+func V(level int) Verbose {
+	return Verbose(level <= getVerbosity())
+}
+
+// Logf is like the package level Logf, but only logs if the verbosity level is enabled.
+func (v Verbose) Logf(f string, a ...interface{}) {
+	if v {
+		logf(stdout, "info", DefaultLogger(), nil, f, a...)
+	}
+}
+
+// Warningf is like the package level Warningf, but only logs if the verbosity level is enabled.
+func (v Verbose) Warningf(f string, a ...interface{}) {
+	if v {
+		logf(stderr, "warning", DefaultLogger(), nil, f, a...)
+		annotate("warning", fmt.Sprintf(f, a...))
+	}
+}
+
+// logField is a field of a Logger.
+type logField struct {
+	key   string
+	value interface{}
+}
+
+// Logger logs messages with fields that tell what a message is about,
+// such as the tool, repository and reference. This keeps the output of
+// repositories that are processed concurrently attributable. In text
+// format the fields are a prefix of the message in the order they were
+// added, in JSON format they are added to the "fields" object.
+// A nil Logger does not log.
+type Logger struct {
+	fields []logField
+}
+
+// NewLogger returns a Logger without fields.
+func NewLogger() *Logger {
+	return &Logger{}
+}
+
+// DefaultLogger returns the Logger used by the package level log functions.
+func DefaultLogger() *Logger {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return defaultLogger
+}
+
+// SetDefaultLogger sets the Logger used by the package level log functions,
+// for example to add the name of the tool to all messages.
+func SetDefaultLogger(l *Logger) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	defaultLogger = l
+}
+
+// With returns a copy of the Logger with an additional field. A field
+// with the same key is replaced.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	if l == nil {
+		return nil
+	}
+	result := &Logger{fields: make([]logField, 0, len(l.fields)+1)}
+	for _, f := range l.fields {
+		if f.key != key {
+			result.fields = append(result.fields, f)
+		}
+	}
+	result.fields = append(result.fields, logField{key: key, value: value})
+	return result
+}
+
+// WithTool returns a copy of the Logger with the name of a tool.
+func (l *Logger) WithTool(tool string) *Logger {
+	return l.With(LogFieldTool, tool)
+}
+
+// WithRepo returns a copy of the Logger with a repository.
+func (l *Logger) WithRepo(repo string) *Logger {
+	return l.With(LogFieldRepo, repo)
+}
+
+// WithRef returns a copy of the Logger with a tag, branch or reference.
+func (l *Logger) WithRef(ref string) *Logger {
+	return l.With(LogFieldRef, ref)
+}
+
+// V returns the Logger if level is less or equal to the verbosity and
+// nil otherwise, like the package level V.
+func (l *Logger) V(level int) *Logger {
+	if level > getVerbosity() {
+		return nil
+	}
+	return l
+}
+
+// Logf is like the package level Logf with the fields of the Logger.
+func (l *Logger) Logf(f string, a ...interface{}) {
+	if l != nil {
+		logf(stdout, "info", l, nil, f, a...)
+	}
+}
+
+// Noticef is like the package level Noticef with the fields of the Logger.
+func (l *Logger) Noticef(f string, a ...interface{}) {
+	if l != nil {
+		logf(stdout, "info", l, nil, f, a...)
+		annotate("notice", l.prefix()+fmt.Sprintf(f, a...))
+	}
+}
+
+// Warningf is like the package level Warningf with the fields of the Logger.
+func (l *Logger) Warningf(f string, a ...interface{}) {
+	if l != nil {
+		logf(stderr, "warning", l, nil, f, a...)
+		annotate("warning", l.prefix()+fmt.Sprintf(f, a...))
+	}
+}
+
+// Errorf is like the package level Errorf with the fields of the Logger.
+func (l *Logger) Errorf(f string, a ...interface{}) {
+	if l != nil {
+		logf(stderr, "error", l, nil, f, a...)
+		annotate("error", l.prefix()+fmt.Sprintf(f, a...))
+	}
+}
+
+// prefix returns the fields of the Logger in text format, such as
+// "[tool=k8s-repo-sync repo=org/repo] ", or an empty string if there
+// are no fields.
+func (l *Logger) prefix() string {
+	if l == nil || len(l.fields) == 0 {
+		return ""
+	}
+	str := make([]string, len(l.fields))
+	for i, f := range l.fields {
+		str[i] = fmt.Sprintf("%s=%v", f.key, f.value)
+	}
+	return "[" + strings.Join(str, " ") + "] "
+}
+
+// fieldMap returns the fields of the Logger merged with extra fields
+// for the JSON format. The extra fields have precedence.
+func (l *Logger) fieldMap(extra map[string]interface{}) map[string]interface{} {
+	if l == nil || len(l.fields) == 0 {
+		return extra
+	}
+	result := make(map[string]interface{}, len(l.fields)+len(extra))
+	for _, f := range l.fields {
+		result[f.key] = f.value
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	return result
+}
+
+func getLogFormat() string {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return logFormat
+}
+
+// logf writes a log line with the given level and the fields of l and
+// fields. The caller is the function that called the exported log function
+// that called logf.
+func logf(w io.Writer, level string, l *Logger, fields map[string]interface{}, f string, a ...interface{}) {
+	if level == "info" && isQuiet() {
+		return
+	}
 	_, fn, line, _ := runtime.Caller(2)
-	fn = filepath.Base(fn)
-	return fmt.Sprintf("%s %s %s:%d %s\n", t, time.Now().Format(layout), fn, line, f)
+	caller := fmt.Sprintf("%s:%d", filepath.Base(fn), line)
+	msg := fmt.Sprintf(f, a...)
+	now := time.Now()
+
+	if getLogFormat() == LogFormatJSON {
+		buf, err := json.Marshal(&logLine{
+			Level:  level,
+			Time:   now.Format(time.RFC3339Nano),
+			Caller: caller,
+			Msg:    msg,
+			Fields: l.fieldMap(fields),
+		})
+		if err != nil {
+			buf = []byte(fmt.Sprintf(`{"level":"error","msg":%q}`, err.Error()))
+		}
+		fmt.Fprintln(w, string(buf))
+		return
+	}
+
+	const layout = "15:04:05.000000"
+	fmt.Fprintf(w, "%s %s %s %s%s\n", strings.ToUpper(level[:1]), now.Format(layout), caller, l.prefix(), msg)
 }
 
 // Logf ...
 func Logf(f string, a ...interface{}) {
-	fmt.Fprintf(stdout, getLogPrefix("I", f), a...)
+	logf(stdout, "info", DefaultLogger(), nil, f, a...)
+}
+
+// Noticef is like Logf, but the message is also a notice annotation
+// in the annotation output mode.
+func Noticef(f string, a ...interface{}) {
+	logf(stdout, "info", DefaultLogger(), nil, f, a...)
+	annotate("notice", fmt.Sprintf(f, a...))
 }
 
 // Warningf ...
 func Warningf(f string, a ...interface{}) {
-	fmt.Fprintf(stderr, getLogPrefix("W", f), a...)
+	logf(stderr, "warning", DefaultLogger(), nil, f, a...)
+	annotate("warning", fmt.Sprintf(f, a...))
 }
 
 // Errorf ...
 func Errorf(f string, a ...interface{}) {
-	fmt.Fprintf(stderr, getLogPrefix("E", f), a...)
+	logf(stderr, "error", DefaultLogger(), nil, f, a...)
+	annotate("error", fmt.Sprintf(f, a...))
 }
 
 // PrintErrorAndExit ...
 func PrintErrorAndExit(err error) {
-	Errorf("%+v", errors.WithStack(err))
+	// The annotation has no stack trace.
+	logf(stderr, "error", DefaultLogger(), nil, "%+v", errors.WithStack(err))
+	annotate("error", err.Error())
+	FinishRun(1, err)
 	os.Exit(1)
 }
 
-// PrintSeparator ...
+// OnFinish registers a function that is called by FinishRun with the exit
+// code and error of the run.
+func OnFinish(fn func(exitCode int, err error)) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	finishHooks = append(finishHooks, fn)
+}
+
+// FinishRun must be called when a tool exits with its exit code and error.
+// It calls the functions registered with OnFinish only once and writes the
+// metrics and the job summary of the run if they are enabled.
+func FinishRun(exitCode int, err error) {
+	logMutex.Lock()
+	hooks := finishHooks
+	finishHooks = nil
+	logMutex.Unlock()
+	for _, fn := range hooks {
+		fn(exitCode, err)
+	}
+	finishMetrics(exitCode, err)
+	finishTracing(exitCode, err)
+	finishAnnotations(exitCode, err)
+}
+
+// PrintSeparator prints a line separator in text log format.
 func PrintSeparator() {
+	if getLogFormat() == LogFormatJSON {
+		return
+	}
 	fmt.Fprintln(stderr, lineSeparator)
 }
 
@@ -91,10 +397,15 @@ func PrintSeparator() {
 // and "sha" fields.
 func LogRefList(msg, repo string, refs []*github.Reference) {
 	str := make([]string, len(refs))
+	subsets := make([]referenceSubset, len(refs))
 	for i, ref := range refs {
-		r := referenceSubset{Ref: ref.GetRef(), SHA: ref.GetObject().GetSHA()}
-		buf, _ := json.Marshal(&r)
+		subsets[i] = referenceSubset{Ref: ref.GetRef(), SHA: ref.GetObject().GetSHA()}
+		buf, _ := json.Marshal(&subsets[i])
 		str[i] = string(buf)
 	}
-	Logf(msg+" for %s: %v", repo, str)
+	if getLogFormat() == LogFormatJSON {
+		logf(stdout, "info", DefaultLogger(), map[string]interface{}{LogFieldRepo: repo, "refs": subsets}, "%s", msg)
+		return
+	}
+	logf(stdout, "info", DefaultLogger(), nil, msg+" for %s: %v", repo, str)
 }
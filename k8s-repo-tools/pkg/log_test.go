@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestLogFormat(t *testing.T) {
+	defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer SetLogFormat(LogFormatText)
+
+	var out, errOut bytes.Buffer
+	SetLogWriters(&out, &errOut)
+
+	// Text format.
+	if err := SetLogFormat(LogFormatText); err != nil {
+		t.Fatal(err)
+	}
+	Logf("hello %s", "world")
+	Warningf("careful")
+	PrintSeparator()
+	if !regexp.MustCompile(`^I \d\d:\d\d:\d\d\.\d{6} log_test\.go:\d+ hello world\n$`).MatchString(out.String()) {
+		t.Errorf("unexpected text output: %q", out.String())
+	}
+	if !strings.HasPrefix(errOut.String(), "W ") || !strings.HasSuffix(errOut.String(), lineSeparator+"\n") {
+		t.Errorf("unexpected text error output: %q", errOut.String())
+	}
+
+	// JSON format.
+	out.Reset()
+	errOut.Reset()
+	if err := SetLogFormat(LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	Errorf("failed %d times", 2)
+	PrintSeparator()
+	LogRefList("found refs", "org/repo", []*github.Reference{
+		&github.Reference{Ref: github.String("refs/tags/v1.0.0"), Object: &github.GitObject{SHA: github.String("1234")}},
+	})
+
+	line := logLine{}
+	if err := json.Unmarshal(errOut.Bytes(), &line); err != nil {
+		t.Fatalf("could not parse JSON error output %q: %v", errOut.String(), err)
+	}
+	if line.Level != "error" || line.Msg != "failed 2 times" || !strings.HasPrefix(line.Caller, "log_test.go:") {
+		t.Errorf("unexpected JSON error output: %+v", line)
+	}
+
+	line = logLine{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("could not parse JSON output %q: %v", out.String(), err)
+	}
+	expectedFields := map[string]interface{}{
+		"repo": "org/repo",
+		"refs": []interface{}{map[string]interface{}{"ref": "refs/tags/v1.0.0", "sha": "1234"}},
+	}
+	if line.Level != "info" || line.Msg != "found refs" || !reflect.DeepEqual(line.Fields, expectedFields) {
+		t.Errorf("unexpected JSON output: %+v", line)
+	}
+
+	// Unknown format.
+	if err := SetLogFormat("yaml"); err == nil {
+		t.Errorf("expected an error for an unknown log format")
+	}
+}
+
+func TestVerbosity(t *testing.T) {
+	defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer SetVerbosity(DefaultVerbosity)
+
+	var out, errOut bytes.Buffer
+	SetLogWriters(&out, &errOut)
+
+	SetVerbosity(1)
+	V(0).Logf("level 0")
+	V(1).Logf("level 1")
+	V(2).Logf("level 2")
+	V(2).Warningf("level 2 warning")
+	Warningf("warning")
+
+	if strings.Count(out.String(), "\n") != 2 || strings.Contains(out.String(), "level 2") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if strings.Count(errOut.String(), "\n") != 1 || strings.Contains(errOut.String(), "level 2") {
+		t.Errorf("unexpected error output: %q", errOut.String())
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer SetQuiet(false)
+
+	var out, errOut bytes.Buffer
+	SetLogWriters(&out, &errOut)
+
+	SetQuiet(true)
+	Logf("info")
+	V(0).Logf("info")
+	Warningf("warning")
+	Errorf("error")
+
+	if out.Len() != 0 {
+		t.Errorf("expected empty output, got: %q", out.String())
+	}
+	if strings.Count(errOut.String(), "\n") != 2 {
+		t.Errorf("unexpected error output: %q", errOut.String())
+	}
+}
+
+func TestLogger(t *testing.T) {
+	defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer SetLogFormat(LogFormatText)
+	defer SetVerbosity(DefaultVerbosity)
+	defer SetDefaultLogger(DefaultLogger())
+
+	var out, errOut bytes.Buffer
+	SetLogWriters(&out, &errOut)
+
+	// Text format. The fields are a prefix in the order they were added
+	// and a field with the same key is replaced.
+	l := NewLogger().WithTool("k8s-test").WithRepo("org/a").WithRef("v1.0.0").WithRepo("org/b")
+	l.Logf("hello")
+	l.Warningf("careful")
+	expected := regexp.MustCompile(`^I \d\d:\d\d:\d\d\.\d{6} log_test\.go:\d+ \[tool=k8s-test ref=v1.0.0 repo=org/b\] hello\n$`)
+	if !expected.MatchString(out.String()) {
+		t.Errorf("unexpected text output: %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "[tool=k8s-test ref=v1.0.0 repo=org/b] careful") {
+		t.Errorf("unexpected text error output: %q", errOut.String())
+	}
+
+	// The package level functions use the default logger.
+	out.Reset()
+	SetDefaultLogger(NewLogger().WithTool("k8s-test"))
+	Logf("hello")
+	if !strings.HasSuffix(out.String(), " [tool=k8s-test] hello\n") {
+		t.Errorf("unexpected output of the default logger: %q", out.String())
+	}
+
+	// Leveled logging.
+	out.Reset()
+	SetVerbosity(1)
+	l.V(1).Logf("level 1")
+	l.V(2).Logf("level 2")
+	if strings.Count(out.String(), "\n") != 1 || strings.Contains(out.String(), "level 2") {
+		t.Errorf("unexpected leveled output: %q", out.String())
+	}
+
+	// JSON format. The fields of the logger are merged with the fields of
+	// the message.
+	out.Reset()
+	if err := SetLogFormat(LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	LogRefList("found refs", "org/repo", nil)
+	line := logLine{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("could not parse JSON output %q: %v", out.String(), err)
+	}
+	expectedFields := map[string]interface{}{
+		"tool": "k8s-test",
+		"repo": "org/repo",
+		"refs": []interface{}{},
+	}
+	if line.Msg != "found refs" || !strings.HasPrefix(line.Caller, "log_test.go:") || !reflect.DeepEqual(line.Fields, expectedFields) {
+		t.Errorf("unexpected JSON output: %+v", line)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AssetManifestEntry is an expected release asset with an optional SHA256 checksum.
+type AssetManifestEntry struct {
+	Name   string
+	SHA256 string
+}
+
+var regexpSHA256 = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ParseAssetManifest parses an asset manifest. Every line is an asset name, optionally
+// preceded by its SHA256 checksum in the format written by 'sha256sum'.
+// Empty lines and lines starting with '#' are skipped.
+func ParseAssetManifest(data []byte) ([]AssetManifestEntry, error) {
+	entries := []AssetManifestEntry{}
+	seen := map[string]bool{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var entry AssetManifestEntry
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 1:
+			entry.Name = fields[0]
+		case 2:
+			entry.SHA256 = strings.ToLower(fields[0])
+			// 'sha256sum' marks files that were read in binary mode with '*'.
+			entry.Name = strings.TrimPrefix(fields[1], "*")
+			if !regexpSHA256.MatchString(entry.SHA256) {
+				return nil, errors.Errorf("line %d of the asset manifest has a malformed SHA256 checksum %q", i+1, fields[0])
+			}
+		default:
+			return nil, errors.Errorf("line %d of the asset manifest must be of the format '[sha256] name'", i+1)
+		}
+		if seen[entry.Name] {
+			return nil, errors.Errorf("the asset %q is listed more than once in the asset manifest", entry.Name)
+		}
+		seen[entry.Name] = true
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("the asset manifest does not list any assets")
+	}
+	return entries, nil
+}
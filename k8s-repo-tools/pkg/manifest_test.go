@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAssetManifest(t *testing.T) {
+	const sum = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+	tests := []struct {
+		name            string
+		data            []byte
+		expectedEntries []AssetManifestEntry
+		expectedError   bool
+	}{
+		{
+			name: "valid: names with and without checksums",
+			data: []byte("# assets\n\n" + sum + "  kubeadm\n2C26B46B68FFC68FF99B453C1D30413413422D706483BFA0F98A5E886266E7AE *kubectl\nkubelet\n"),
+			expectedEntries: []AssetManifestEntry{
+				{Name: "kubeadm", SHA256: sum},
+				{Name: "kubectl", SHA256: sum},
+				{Name: "kubelet"},
+			},
+		},
+		{
+			name:          "invalid: no assets",
+			data:          []byte("# assets\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed checksum",
+			data:          []byte("abc kubeadm\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: too many fields",
+			data:          []byte(sum + " kubeadm kubectl\n"),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: duplicate asset",
+			data:          []byte("kubeadm\n" + sum + " kubeadm\n"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseAssetManifest(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if !reflect.DeepEqual(entries, tt.expectedEntries) {
+				t.Errorf("expected entries:\n%+v\ngot:\n%+v", tt.expectedEntries, entries)
+			}
+		})
+	}
+}
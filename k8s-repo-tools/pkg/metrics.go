@@ -0,0 +1,262 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// metricsPrefix is the prefix of the names of all metrics.
+	metricsPrefix = "k8s_repo_tools_"
+	// metricsPushTimeout is the timeout for pushing the metrics to a Pushgateway.
+	metricsPushTimeout = 30 * time.Second
+)
+
+// runMetrics holds the metrics of a tool run, which are written in the
+// Prometheus text format when the tool exits.
+type runMetrics struct {
+	sync.Mutex
+
+	tool        string
+	file        string
+	pushgateway string
+	start       time.Time
+	finish      sync.Once
+
+	// apiRequests maps the method and status code of GitHub API requests to their number.
+	apiRequests        map[apiRequestKey]int
+	refsCreated        int
+	rateLimitRemaining int
+}
+
+// apiRequestKey are the labels of the API request metric.
+type apiRequestKey struct {
+	method string
+	code   string
+}
+
+var (
+	// metrics are the metrics of the current run or nil if metrics are disabled.
+	metrics *runMetrics
+	// metricsNow returns the current time and can be replaced in tests.
+	metricsNow = time.Now
+)
+
+// newRunMetrics creates the metrics of a run of tool that are written to file
+// and pushed to the Pushgateway at the URL pushgateway.
+func newRunMetrics(tool, file, pushgateway string) *runMetrics {
+	return &runMetrics{
+		tool:               tool,
+		file:               file,
+		pushgateway:        pushgateway,
+		start:              metricsNow(),
+		apiRequests:        map[apiRequestKey]int{},
+		rateLimitRemaining: -1,
+	}
+}
+
+// applyMetrics enables the metrics if --metrics-file or --metrics-pushgateway are set.
+func applyMetrics(fs *flag.FlagSet) error {
+	var file, pushgateway string
+	if f := fs.Lookup(FlagMetricsFile); f != nil {
+		file = f.Value.String()
+	}
+	if f := fs.Lookup(FlagMetricsPushgateway); f != nil {
+		pushgateway = f.Value.String()
+	}
+	if len(file) == 0 && len(pushgateway) == 0 {
+		return nil
+	}
+	if len(pushgateway) != 0 && !isValidURL(pushgateway) {
+		return errors.Errorf("the option %q must be an HTTP or HTTPS URL", FlagMetricsPushgateway)
+	}
+	metrics = newRunMetrics(filepath.Base(fs.Name()), file, pushgateway)
+	return nil
+}
+
+// recordAPIRequest records a GitHub API request with its response.
+func (m *runMetrics) recordAPIRequest(method string, resp *http.Response, err error) {
+	m.Lock()
+	defer m.Unlock()
+	key := apiRequestKey{method: method, code: "error"}
+	if err == nil {
+		key.code = strconv.Itoa(resp.StatusCode)
+		if remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining)); err == nil {
+			m.rateLimitRemaining = remaining
+		}
+	}
+	m.apiRequests[key]++
+}
+
+// recordRefsCreated records the number of refs that were created.
+func recordRefsCreated(n int) {
+	if metrics == nil {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+	metrics.refsCreated += n
+}
+
+// metricsTransport is an http.RoundTripper that records the GitHub API requests
+// in the metrics of the run.
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics *runMetrics
+}
+
+var _ http.RoundTripper = &metricsTransport{}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	t.metrics.recordAPIRequest(req.Method, resp, err)
+	return resp, err
+}
+
+// format writes the metrics in the Prometheus text format. A non-zero exit code
+// and the kind of err are recorded as the result of the run.
+func (m *runMetrics) format(exitCode int, err error) []byte {
+	m.Lock()
+	defer m.Unlock()
+
+	now := metricsNow()
+	var b bytes.Buffer
+	tool := labels("tool", m.tool)
+	metric := func(name, typ, help string, values ...string) {
+		fmt.Fprintf(&b, "# HELP %s%s %s\n", metricsPrefix, name, help)
+		fmt.Fprintf(&b, "# TYPE %s%s %s\n", metricsPrefix, name, typ)
+		for _, v := range values {
+			fmt.Fprintf(&b, "%s%s%s\n", metricsPrefix, name, v)
+		}
+	}
+	value := func(l string, v float64) string {
+		return l + " " + strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	metric("run_duration_seconds", "gauge", "Duration of the last run of the tool in seconds.",
+		value(tool, now.Sub(m.start).Seconds()))
+	metric("run_finish_timestamp_seconds", "gauge", "Unix time when the last run of the tool finished.",
+		value(tool, float64(now.Unix())))
+	var success float64
+	if exitCode == 0 && err == nil {
+		success = 1
+	}
+	metric("run_success", "gauge", "Whether the last run of the tool succeeded.", value(tool, success))
+	metric("run_exit_code", "gauge", "Exit status of the last run of the tool.", value(tool, float64(exitCode)))
+	if err != nil {
+		metric("run_error", "gauge", "Kind of the error of the last run of the tool.",
+			value(labels("tool", m.tool, "kind", string(ErrorKindOf(err))), 1))
+	}
+	metric("refs_created_total", "counter", "Number of refs that were created in the last run of the tool.",
+		value(tool, float64(m.refsCreated)))
+
+	keys := make([]apiRequestKey, 0, len(m.apiRequests))
+	for k := range m.apiRequests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	requests := make([]string, len(keys))
+	for i, k := range keys {
+		requests[i] = value(labels("tool", m.tool, "method", k.method, "code", k.code), float64(m.apiRequests[k]))
+	}
+	metric("api_requests_total", "counter", "Number of GitHub API requests in the last run of the tool by method and status code.",
+		requests...)
+	if m.rateLimitRemaining >= 0 {
+		metric("rate_limit_remaining", "gauge", "Remaining requests of the GitHub API rate limit at the end of the last run of the tool.",
+			value(tool, float64(m.rateLimitRemaining)))
+	}
+	return b.Bytes()
+}
+
+// labels formats pairs of label names and values.
+func labels(kv ...string) string {
+	pairs := make([]string, 0, len(kv)/2)
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", kv[i], r.Replace(kv[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// write writes the metrics to the metrics file and pushes them to the Pushgateway.
+// The file is replaced atomically, so that the textfile collector of the node
+// exporter never reads a partial file.
+func (m *runMetrics) write(data []byte) error {
+	if len(m.file) != 0 {
+		tmp := m.file + ".tmp"
+		if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+			return errors.Wrapf(err, "could not write the metrics file %q", tmp)
+		}
+		if err := os.Rename(tmp, m.file); err != nil {
+			return errors.Wrapf(err, "could not write the metrics file %q", m.file)
+		}
+	}
+	if len(m.pushgateway) != 0 {
+		// PUT replaces all metrics of the job in the Pushgateway.
+		u := strings.TrimSuffix(m.pushgateway, "/") + "/metrics/job/" + url.PathEscape(m.tool)
+		req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrap(err, "could not create the Pushgateway request")
+		}
+		req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+		resp, err := NewHTTPClient(metricsPushTimeout).Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "could not push the metrics to %q", u)
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return errors.Errorf("could not push the metrics to %q: received status %d", u, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// finishMetrics writes the metrics of the run with its exit code and error if
+// metrics are enabled. Only the first call has an effect. Failing to write the
+// metrics does not fail the run and only logs a warning.
+func finishMetrics(exitCode int, err error) {
+	m := metrics
+	if m == nil {
+		return
+	}
+	m.finish.Do(func() {
+		if writeErr := m.write(m.format(exitCode, err)); writeErr != nil {
+			Warningf("%v", writeErr)
+		}
+	})
+}
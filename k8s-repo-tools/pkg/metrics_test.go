@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestRunMetrics(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer func(now func() time.Time) { metricsNow = now }(metricsNow)
+	defer func() { metrics = nil }()
+
+	start := time.Unix(1600000000, 0)
+	tests := []struct {
+		name           string
+		exitCode       int
+		err            error
+		expectedOutput string
+	}{
+		{
+			name: "valid: successful run",
+			expectedOutput: `# HELP k8s_repo_tools_run_duration_seconds Duration of the last run of the tool in seconds.
+# TYPE k8s_repo_tools_run_duration_seconds gauge
+k8s_repo_tools_run_duration_seconds{tool="k8s-repo-sync"} 90
+# HELP k8s_repo_tools_run_finish_timestamp_seconds Unix time when the last run of the tool finished.
+# TYPE k8s_repo_tools_run_finish_timestamp_seconds gauge
+k8s_repo_tools_run_finish_timestamp_seconds{tool="k8s-repo-sync"} 1600000090
+# HELP k8s_repo_tools_run_success Whether the last run of the tool succeeded.
+# TYPE k8s_repo_tools_run_success gauge
+k8s_repo_tools_run_success{tool="k8s-repo-sync"} 1
+# HELP k8s_repo_tools_run_exit_code Exit status of the last run of the tool.
+# TYPE k8s_repo_tools_run_exit_code gauge
+k8s_repo_tools_run_exit_code{tool="k8s-repo-sync"} 0
+# HELP k8s_repo_tools_refs_created_total Number of refs that were created in the last run of the tool.
+# TYPE k8s_repo_tools_refs_created_total counter
+k8s_repo_tools_refs_created_total{tool="k8s-repo-sync"} 1
+# HELP k8s_repo_tools_api_requests_total Number of GitHub API requests in the last run of the tool by method and status code.
+# TYPE k8s_repo_tools_api_requests_total counter
+k8s_repo_tools_api_requests_total{tool="k8s-repo-sync",method="GET",code="200"} 1
+k8s_repo_tools_api_requests_total{tool="k8s-repo-sync",method="POST",code="200"} 1
+# HELP k8s_repo_tools_rate_limit_remaining Remaining requests of the GitHub API rate limit at the end of the last run of the tool.
+# TYPE k8s_repo_tools_rate_limit_remaining gauge
+k8s_repo_tools_rate_limit_remaining{tool="k8s-repo-sync"} 4321
+`,
+		},
+		{
+			name:     "valid: failed run",
+			exitCode: 1,
+			err:      NewErrorf(ErrorKindRateLimited, "foo"),
+			expectedOutput: `# HELP k8s_repo_tools_run_duration_seconds Duration of the last run of the tool in seconds.
+# TYPE k8s_repo_tools_run_duration_seconds gauge
+k8s_repo_tools_run_duration_seconds{tool="k8s-repo-sync"} 90
+# HELP k8s_repo_tools_run_finish_timestamp_seconds Unix time when the last run of the tool finished.
+# TYPE k8s_repo_tools_run_finish_timestamp_seconds gauge
+k8s_repo_tools_run_finish_timestamp_seconds{tool="k8s-repo-sync"} 1600000090
+# HELP k8s_repo_tools_run_success Whether the last run of the tool succeeded.
+# TYPE k8s_repo_tools_run_success gauge
+k8s_repo_tools_run_success{tool="k8s-repo-sync"} 0
+# HELP k8s_repo_tools_run_exit_code Exit status of the last run of the tool.
+# TYPE k8s_repo_tools_run_exit_code gauge
+k8s_repo_tools_run_exit_code{tool="k8s-repo-sync"} 1
+# HELP k8s_repo_tools_run_error Kind of the error of the last run of the tool.
+# TYPE k8s_repo_tools_run_error gauge
+k8s_repo_tools_run_error{tool="k8s-repo-sync",kind="RateLimited"} 1
+# HELP k8s_repo_tools_refs_created_total Number of refs that were created in the last run of the tool.
+# TYPE k8s_repo_tools_refs_created_total counter
+k8s_repo_tools_refs_created_total{tool="k8s-repo-sync"} 1
+# HELP k8s_repo_tools_api_requests_total Number of GitHub API requests in the last run of the tool by method and status code.
+# TYPE k8s_repo_tools_api_requests_total counter
+k8s_repo_tools_api_requests_total{tool="k8s-repo-sync",method="GET",code="200"} 1
+k8s_repo_tools_api_requests_total{tool="k8s-repo-sync",method="POST",code="200"} 1
+# HELP k8s_repo_tools_rate_limit_remaining Remaining requests of the GitHub API rate limit at the end of the last run of the tool.
+# TYPE k8s_repo_tools_rate_limit_remaining gauge
+k8s_repo_tools_rate_limit_remaining{tool="k8s-repo-sync"} 4321
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "metrics")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			var pushed []byte
+			var pushPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pushPath = r.URL.Path
+				pushed, _ = ioutil.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			fs := flag.NewFlagSet("k8s-repo-sync", flag.ContinueOnError)
+			fs.String(FlagMetricsFile, filepath.Join(dir, "metrics.prom"), "")
+			fs.String(FlagMetricsPushgateway, server.URL, "")
+			metricsNow = func() time.Time { return start }
+			if err := applyMetrics(fs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Record the requests of the run.
+			refs := []*github.Reference{newTestRef("refs/heads/master", "sha0", "commit")}
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs", func(req *http.Request) (*http.Response, error) {
+				resp, err := NewReferenceHandler(&refs, nil)(req)
+				if resp != nil {
+					resp.Header.Set(headerRateLimitRemaining, "4321")
+				}
+				return resp, err
+			})
+			if _, err := GitHubCreateRef(d, "org/repo", "refs/tags/v1.0.0", "sha0", false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := GitHubGetRef(d, "org/repo", "refs/tags/v1.0.0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			metricsNow = func() time.Time { return start.Add(90 * time.Second) }
+			FinishRun(tt.exitCode, tt.err)
+			// Only the first call writes the metrics.
+			FinishRun(0, nil)
+
+			output, err := ioutil.ReadFile(filepath.Join(dir, "metrics.prom"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(output) != tt.expectedOutput {
+				t.Errorf("expected metrics file:\n%s\ngot:\n%s", tt.expectedOutput, output)
+			}
+			if string(pushed) != tt.expectedOutput {
+				t.Errorf("expected pushed metrics:\n%s\ngot:\n%s", tt.expectedOutput, pushed)
+			}
+			if pushPath != "/metrics/job/k8s-repo-sync" {
+				t.Errorf("expected push to %q, got %q", "/metrics/job/k8s-repo-sync", pushPath)
+			}
+		})
+	}
+}
+
+func TestApplyMetrics(t *testing.T) {
+	defer func() { metrics = nil }()
+
+	tests := []struct {
+		name            string
+		file            string
+		pushgateway     string
+		expectedEnabled bool
+		expectedError   bool
+	}{
+		{
+			name: "valid: metrics are disabled by default",
+		},
+		{
+			name:            "valid: metrics file",
+			file:            "metrics.prom",
+			expectedEnabled: true,
+		},
+		{
+			name:          "invalid: Pushgateway is not a URL",
+			pushgateway:   "pushgateway:9091",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics = nil
+			fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+			fs.String(FlagMetricsFile, tt.file, "")
+			fs.String(FlagMetricsPushgateway, tt.pushgateway, "")
+			err := applyMetrics(fs)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if (metrics != nil) != tt.expectedEnabled {
+				t.Errorf("expected metrics enabled %v, got %v", tt.expectedEnabled, metrics != nil)
+			}
+		})
+	}
+}
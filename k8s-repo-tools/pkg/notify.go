@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// slackTextMaxLength is the maximum length of the text of a Slack section block.
+	slackTextMaxLength = 3000
+
+	// defaultSlackTemplate is the default template of the text of a Slack message.
+	defaultSlackTemplate = `*{{.Tool}}* {{if .Success}}succeeded{{else}}failed{{end}} for repository ` + "`{{.Repo}}`" + `
+{{range .Changes}}• {{.}}
+{{end}}{{if .Error}}*Error ({{.ErrorKind}}):* {{.Error}}
+{{end}}`
+)
+
+// Notification is the result of a run of a tool that is passed to the
+// template of a notification.
+type Notification struct {
+	Tool      string
+	Repo      string
+	Success   bool
+	ExitCode  int
+	ErrorKind ErrorKind
+	Error     string
+	// Changes are the changes that were written to repositories, such as created refs.
+	Changes []string
+	// RunURL is the URL of the GitHub Actions run, if the tool runs in GitHub Actions.
+	RunURL string
+}
+
+// NewNotification creates a Notification for the run of tool for the repository
+// repo with its exit code and error.
+func NewNotification(tool, repo string, exitCode int, err error) *Notification {
+	n := &Notification{
+		Tool:      tool,
+		Repo:      repo,
+		Success:   exitCode == 0,
+		ExitCode:  exitCode,
+		ErrorKind: ErrorKindOf(err),
+		Changes:   recordedChanges(),
+	}
+	if err != nil {
+		n.Error = err.Error()
+	}
+	server, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if len(server) != 0 && len(repository) != 0 && len(runID) != 0 {
+		n.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+	}
+	return n
+}
+
+// status returns a short status for the title of a notification.
+func (n *Notification) status() string {
+	if n.Success {
+		return "OK"
+	}
+	return "FAILED"
+}
+
+// SlackNotifier sends notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	webhookURL string
+	template   *template.Template
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier for the webhook URL. The text of the
+// messages is rendered with a Go template from the file or URL tmpl or with
+// a default template if tmpl is empty.
+func NewSlackNotifier(webhookURL, tmpl string, client *http.Client) (*SlackNotifier, error) {
+	text := defaultSlackTemplate
+	if len(tmpl) != 0 {
+		data, err := ReadFromFileOrURL(tmpl, client.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read the Slack template %q", tmpl)
+		}
+		text = string(data)
+	}
+	t, err := template.New("slack").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the Slack template")
+	}
+	return &SlackNotifier{webhookURL: webhookURL, template: t, client: client}, nil
+}
+
+// slackText is a text object of the Slack Block Kit.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackBlock is a layout block of the Slack Block Kit.
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+// slackMessage is the payload of a Slack incoming webhook. Text is shown in
+// notifications, where blocks are not rendered.
+type slackMessage struct {
+	Text   string        `json:"text"`
+	Blocks []*slackBlock `json:"blocks"`
+}
+
+// message renders the Slack message for a notification. It has a header with
+// the status, a section with the text from the template and a link to the run.
+func (s *SlackNotifier) message(n *Notification) (*slackMessage, error) {
+	var buf bytes.Buffer
+	if err := s.template.Execute(&buf, n); err != nil {
+		return nil, errors.Wrap(err, "could not execute the Slack template")
+	}
+	text := strings.TrimSpace(buf.String())
+	if len(text) > slackTextMaxLength {
+		text = text[:slackTextMaxLength-3] + "..."
+	}
+	title := fmt.Sprintf("%s: %s", n.Tool, n.status())
+	msg := &slackMessage{
+		Text:   title,
+		Blocks: []*slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: title}}},
+	}
+	if len(text) != 0 {
+		msg.Blocks = append(msg.Blocks, &slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+	if len(n.RunURL) != 0 {
+		msg.Blocks = append(msg.Blocks, &slackBlock{
+			Type:     "context",
+			Elements: []*slackText{{Type: "mrkdwn", Text: fmt.Sprintf("<%s|View the run>", n.RunURL)}},
+		})
+	}
+	return msg, nil
+}
+
+// Notify sends a notification to the webhook.
+func (s *SlackNotifier) Notify(n *Notification) error {
+	msg, err := s.message(n)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.webhookURL, "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		return errors.Wrap(err, "could not send the Slack notification")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("could not send the Slack notification: received status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NotifySlackOnFinish sends a Slack notification with the result of the run of
+// tool for the repository repo when the run finishes, if --slack-webhook-url is
+// set. In dry-run mode the notification is only logged. Failing to send the
+// notification does not fail the run and only logs a warning.
+func NotifySlackOnFinish(d *Data, tool, repo string) error {
+	if len(d.SlackWebhookURL) == 0 {
+		return nil
+	}
+	s, err := NewSlackNotifier(d.SlackWebhookURL, d.SlackTemplate, NewHTTPClient(d.Timeout))
+	if err != nil {
+		return err
+	}
+	dryRun := d.DryRun
+	OnFinish(func(exitCode int, err error) {
+		n := NewNotification(tool, repo, exitCode, err)
+		if dryRun {
+			Logf("%s: would send a Slack notification with status %s", PrefixDryRun, n.status())
+			return
+		}
+		Logf("sending a Slack notification with status %s", n.status())
+		if err := s.Notify(n); err != nil {
+			Warningf("%v", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSlackNotifier(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer func() { changes = nil }()
+	for _, env := range []string{"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID"} {
+		v, ok := os.LookupEnv(env)
+		defer func(env, v string, ok bool) {
+			if ok {
+				os.Setenv(env, v)
+			} else {
+				os.Unsetenv(env)
+			}
+		}(env, v, ok)
+		os.Unsetenv(env)
+	}
+
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	templateFile := filepath.Join(dir, "slack.tmpl")
+	if err := ioutil.WriteFile(templateFile, []byte("{{.Tool}} exited with {{.ExitCode}}"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		template        string
+		exitCode        int
+		err             error
+		runURL          bool
+		dryRun          bool
+		status          int
+		expectedPayload string
+		expectedError   bool
+	}{
+		{
+			name: "valid: successful run with the default template",
+			expectedPayload: `{"text":"k8s-repo-sync: OK","blocks":[` +
+				`{"type":"header","text":{"type":"plain_text","text":"k8s-repo-sync: OK"}},` +
+				`{"type":"section","text":{"type":"mrkdwn","text":"*k8s-repo-sync* succeeded for repository ` +
+				"`org/repo`" + `\n• created ref ` + "`refs/tags/v1.0.0`" + `"}}]}`,
+		},
+		{
+			name:     "valid: failed run with a link to the run",
+			exitCode: 1,
+			err:      NewErrorf(ErrorKindConflict, "ref %q already exists", "v1.0.0"),
+			runURL:   true,
+			expectedPayload: `{"text":"k8s-repo-sync: FAILED","blocks":[` +
+				`{"type":"header","text":{"type":"plain_text","text":"k8s-repo-sync: FAILED"}},` +
+				`{"type":"section","text":{"type":"mrkdwn","text":"*k8s-repo-sync* failed for repository ` +
+				"`org/repo`" + `\n• created ref ` + "`refs/tags/v1.0.0`" + `\n*Error (Conflict):* ref \"v1.0.0\" already exists"}},` +
+				`{"type":"context","elements":[{"type":"mrkdwn","text":"\u003chttps://github.com/org/ci/actions/runs/42|View the run\u003e"}]}]}`,
+		},
+		{
+			name:     "valid: custom template",
+			template: templateFile,
+			exitCode: 3,
+			expectedPayload: `{"text":"k8s-repo-sync: FAILED","blocks":[` +
+				`{"type":"header","text":{"type":"plain_text","text":"k8s-repo-sync: FAILED"}},` +
+				`{"type":"section","text":{"type":"mrkdwn","text":"k8s-repo-sync exited with 3"}}]}`,
+		},
+		{
+			name:   "valid: nothing is sent in dry-run mode",
+			dryRun: true,
+		},
+		{
+			name: "valid: a failure to send is not an error",
+			expectedPayload: `{"text":"k8s-repo-sync: OK","blocks":[` +
+				`{"type":"header","text":{"type":"plain_text","text":"k8s-repo-sync: OK"}},` +
+				`{"type":"section","text":{"type":"mrkdwn","text":"*k8s-repo-sync* succeeded for repository ` +
+				"`org/repo`" + `\n• created ref ` + "`refs/tags/v1.0.0`" + `"}}]}`,
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:          "invalid: missing template file",
+			template:      filepath.Join(dir, "missing.tmpl"),
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				payload, _ = ioutil.ReadAll(req.Body)
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+			}))
+			defer server.Close()
+
+			if tt.runURL {
+				os.Setenv("GITHUB_SERVER_URL", "https://github.com")
+				os.Setenv("GITHUB_REPOSITORY", "org/ci")
+				os.Setenv("GITHUB_RUN_ID", "42")
+			} else {
+				os.Unsetenv("GITHUB_RUN_ID")
+			}
+			changes = nil
+			recordChange("created ref `%s`", "refs/tags/v1.0.0")
+
+			d := &Data{SlackWebhookURL: server.URL, SlackTemplate: tt.template, DryRun: tt.dryRun, Timeout: time.Minute}
+			err := NotifySlackOnFinish(d, "k8s-repo-sync", "org/repo")
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			FinishRun(tt.exitCode, tt.err)
+			if string(payload) != tt.expectedPayload {
+				t.Errorf("expected payload:\n%s\ngot:\n%s", tt.expectedPayload, payload)
+			}
+		})
+	}
+}
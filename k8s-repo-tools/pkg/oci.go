@@ -0,0 +1,422 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// OCIArtifactType is the artifact type of the OCI manifest of release assets.
+	OCIArtifactType = "application/vnd.k8s.release.assets.v1"
+	// ociManifestMediaType is the media type of an OCI image manifest.
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// ociEmptyMediaType is the media type of the empty config of an artifact.
+	ociEmptyMediaType = "application/vnd.oci.empty.v1+json"
+	// ociLayerMediaType is the media type of the files of an artifact. It is
+	// the default of ORAS, so that 'oras pull' writes the files by name.
+	ociLayerMediaType = "application/vnd.oci.image.layer.v1.tar"
+	// ociAnnotationTitle is the annotation with the file name of a layer.
+	ociAnnotationTitle = "org.opencontainers.image.title"
+
+	// OCIChecksumsFile is the name of the file in the artifact that lists the
+	// SHA256 checksums of the release assets.
+	OCIChecksumsFile = "SHA256SUMS"
+)
+
+// ociRepositoryRegexp matches a repository in a registry such as 'ghcr.io/org/kubeadm'.
+var ociRepositoryRegexp = regexp.MustCompile(`^[a-zA-Z0-9.-]+(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)+$`)
+
+// ociDescriptor describes a blob in an OCI registry.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociManifest is an OCI image manifest for an artifact.
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ociBlob is a blob to push. Blobs are either files or contents in memory.
+type ociBlob struct {
+	ociDescriptor
+	path    string
+	content []byte
+}
+
+// open returns a reader for the contents of the blob.
+func (b *ociBlob) open() (io.ReadCloser, error) {
+	if len(b.path) != 0 {
+		return os.Open(b.path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(b.content)), nil
+}
+
+// ValidateOCIRepository validates a repository such as 'ghcr.io/org/kubeadm'.
+func ValidateOCIRepository(flag, repository string) error {
+	if !ociRepositoryRegexp.MatchString(repository) {
+		return errors.Errorf("the value %q of --%s must be a repository in a registry such as 'ghcr.io/org/name'",
+			repository, flag)
+	}
+	return nil
+}
+
+// OCITag returns the tag of an OCI artifact for a release tag. '+' is not valid
+// in OCI tags and is replaced with '_'.
+func OCITag(releaseTag string) string {
+	return strings.Replace(releaseTag, "+", "_", -1)
+}
+
+// PushOCIArtifact pushes the release assets in am as an OCI artifact to repository,
+// such as 'ghcr.io/org/kubeadm', with the tag for releaseTag. A file with the SHA256
+// checksums of the assets is added to the artifact. releaseURL is added as an annotation
+// of the manifest. The reference of the artifact with its digest is returned.
+func PushOCIArtifact(d *Data, repository, releaseTag, releaseURL string, am assetMap, dryRun bool) (string, error) {
+	tag := OCITag(releaseTag)
+	if dryRun {
+		Logf("%s: would push %d release asset(s) as an OCI artifact to %s:%s", PrefixDryRun, len(am), repository, tag)
+		return "", nil
+	}
+	r := newOCIRegistry(NewHTTPClient(d.transferTimeout()), repository, d.OCIUsername, d.OCIPassword)
+	ref, err := r.pushArtifact(tag, releaseTag, releaseURL, am)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not push the OCI artifact %s:%s", repository, tag)
+	}
+	recordChange("pushed the OCI artifact `%s`", ref)
+	return ref, nil
+}
+
+// ociRegistry pushes blobs and manifests to a repository of an OCI registry
+// using the OCI distribution API.
+type ociRegistry struct {
+	client     *http.Client
+	baseURL    string
+	repository string
+	name       string
+	username   string
+	password   string
+	// authorization is the Authorization header for the requests. It is
+	// obtained from the challenge of the first unauthorized response.
+	authorization string
+}
+
+// newOCIRegistry creates an ociRegistry for a repository such as 'ghcr.io/org/kubeadm'.
+func newOCIRegistry(client *http.Client, repository, username, password string) *ociRegistry {
+	s := strings.SplitN(repository, "/", 2)
+	host := s[0]
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	return &ociRegistry{
+		client:     client,
+		baseURL:    "https://" + host,
+		repository: repository,
+		name:       s[1],
+		username:   username,
+		password:   password,
+	}
+}
+
+// pushArtifact pushes the assets in am and the checksums file as layers of a
+// manifest with the given tag.
+func (r *ociRegistry) pushArtifact(tag, version, releaseURL string, am assetMap) (string, error) {
+	names := make([]string, 0, len(am))
+	for name := range am {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checksums strings.Builder
+	blobs := []*ociBlob{}
+	for _, name := range names {
+		b, err := newOCIFileBlob(name, am[name])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&checksums, "%s  %s\n", strings.TrimPrefix(b.Digest, "sha256:"), name)
+		blobs = append(blobs, b)
+	}
+	blobs = append(blobs, newOCIBlob(ociLayerMediaType, OCIChecksumsFile, []byte(checksums.String())))
+	config := newOCIBlob(ociEmptyMediaType, "", []byte("{}"))
+
+	manifest := &ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		ArtifactType:  OCIArtifactType,
+		Config:        config.ociDescriptor,
+		Layers:        []ociDescriptor{},
+		Annotations: map[string]string{
+			"org.opencontainers.image.version": version,
+		},
+	}
+	if len(releaseURL) != 0 {
+		manifest.Annotations["org.opencontainers.image.url"] = releaseURL
+	}
+	for _, b := range append([]*ociBlob{config}, blobs...) {
+		if err := r.pushBlob(b); err != nil {
+			return "", err
+		}
+		if b != config {
+			manifest.Layers = append(manifest.Layers, b.ociDescriptor)
+		}
+	}
+
+	buf, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256Digest(buf)
+	Logf("pushing the OCI manifest %s:%s with %d layer(s)", r.repository, tag, len(manifest.Layers))
+	resp, err := r.do(http.MethodPut, r.baseURL+"/v2/"+r.name+"/manifests/"+tag, ociManifestMediaType, int64(len(buf)),
+		func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(buf)), nil })
+	if err != nil {
+		return "", err
+	}
+	if err := checkOCIResponse(resp, http.StatusCreated, "pushing the manifest"); err != nil {
+		return "", err
+	}
+	return r.repository + "@" + digest, nil
+}
+
+// newOCIBlob creates a blob with contents in memory. If title is not empty
+// it is set as the file name of the blob.
+func newOCIBlob(mediaType, title string, content []byte) *ociBlob {
+	b := &ociBlob{
+		ociDescriptor: ociDescriptor{MediaType: mediaType, Digest: sha256Digest(content), Size: int64(len(content))},
+		content:       content,
+	}
+	if len(title) != 0 {
+		b.Annotations = map[string]string{ociAnnotationTitle: title}
+	}
+	return b
+}
+
+// newOCIFileBlob creates a blob for a file. The digest of the file is computed
+// without reading the whole file in memory.
+func newOCIFileBlob(name, path string) (*ociBlob, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %q", path)
+	}
+	return &ociBlob{
+		ociDescriptor: ociDescriptor{
+			MediaType:   ociLayerMediaType,
+			Digest:      "sha256:" + hex.EncodeToString(h.Sum(nil)),
+			Size:        size,
+			Annotations: map[string]string{ociAnnotationTitle: filepath.Base(name)},
+		},
+		path: path,
+	}, nil
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// pushBlob uploads a blob with a monolithic upload, unless it already exists.
+func (r *ociRegistry) pushBlob(b *ociBlob) error {
+	resp, err := r.do(http.MethodHead, r.baseURL+"/v2/"+r.name+"/blobs/"+b.Digest, "", 0, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		V(1).Logf("the blob %s already exists in %s", b.Digest, r.repository)
+		return nil
+	}
+
+	V(1).Logf("pushing the blob %s (%d bytes) to %s", b.Digest, b.Size, r.repository)
+	resp, err = r.do(http.MethodPost, r.baseURL+"/v2/"+r.name+"/blobs/uploads/", "", 0, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkOCIResponse(resp, http.StatusAccepted, "starting the upload of blob "+b.Digest); err != nil {
+		return err
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return errors.Wrap(err, "the registry did not return the location for the upload")
+	}
+	query := location.Query()
+	query.Set("digest", b.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = r.do(http.MethodPut, location.String(), "application/octet-stream", b.Size, b.open)
+	if err != nil {
+		return err
+	}
+	return checkOCIResponse(resp, http.StatusCreated, "uploading blob "+b.Digest)
+}
+
+// do sends a request with the Authorization header of the registry. If the
+// registry answers with 401 Unauthorized, the header is obtained from the
+// challenge of the response and the request is sent again.
+func (r *ociRegistry) do(method, u, contentType string, size int64, body func() (io.ReadCloser, error)) (*http.Response, error) {
+	for i := 0; ; i++ {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			if req.Body, err = body(); err != nil {
+				return nil, err
+			}
+			req.ContentLength = size
+		}
+		if len(contentType) != 0 {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if len(r.authorization) != 0 {
+			req.Header.Set("Authorization", r.authorization)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || i > 0 {
+			return resp, nil
+		}
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if r.authorization, err = r.authorize(challenge); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// authorize returns the Authorization header for a WWW-Authenticate challenge.
+// For a Bearer challenge a token is requested from the realm of the challenge.
+// https://docs.docker.com/registry/spec/auth/token/
+func (r *ociRegistry) authorize(challenge string) (string, error) {
+	scheme, params := parseAuthChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if len(r.username) == 0 {
+			return "", NewErrorf(ErrorKindUnauthorized, "the registry of %s requires a username and a password", r.repository)
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(r.username, r.password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", NewErrorf(ErrorKindUnauthorized, "unsupported authentication challenge %q from the registry of %s", challenge, r.repository)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || len(params["realm"]) == 0 {
+		return "", errors.Errorf("invalid realm in the authentication challenge %q", challenge)
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push", r.name))
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(r.username) != 0 {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", NewError(ErrorKindUnauthorized, ociResponseError(resp, "requesting a registry token"))
+	}
+	t := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", errors.Wrap(err, "could not decode the registry token")
+	}
+	if len(t.Token) == 0 {
+		t.Token = t.AccessToken
+	}
+	return "Bearer " + t.Token, nil
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header such as
+// 'Bearer realm="https://ghcr.io/token",service="ghcr.io"'.
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	s := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(s) != 2 {
+		return s[0], params
+	}
+	for _, p := range strings.Split(s[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return s[0], params
+}
+
+// checkOCIResponse closes the body of a registry response and returns an
+// error if the status is not the expected status.
+func checkOCIResponse(resp *http.Response, status int, action string) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		return ociResponseError(resp, action)
+	}
+	return nil
+}
+
+// ociResponseError returns an error with the status and the body of a registry response.
+func ociResponseError(resp *http.Response, action string) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	err := errors.Errorf("received status %d %s: %s", resp.StatusCode, action, strings.TrimSpace(string(body)))
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return NewError(ErrorKindUnauthorized, err)
+	}
+	return err
+}
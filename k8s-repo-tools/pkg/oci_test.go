@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is a minimal OCI registry that requires a Bearer token.
+type fakeRegistry struct {
+	sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	// pushes counts the uploaded blobs.
+	pushes int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	if req.URL.Path == "/token" {
+		user, pass, _ := req.BasicAuth()
+		if user != "user" || pass != "pass" || req.URL.Query().Get("scope") != "repository:org/kubeadm:pull,push" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"token":"registry-token"}`))
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer registry-token" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="https://`+req.Host+`/token",service="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/v2/org/kubeadm")
+	switch {
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "/blobs/"):
+		if _, ok := f.blobs[strings.TrimPrefix(path, "/blobs/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case req.Method == http.MethodPost && path == "/blobs/uploads/":
+		w.Header().Set("Location", "/v2/org/kubeadm/blobs/uploads/1?state=x")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && path == "/blobs/uploads/1":
+		data, _ := ioutil.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if req.URL.Query().Get("state") != "x" || sha256Digest(data) != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.blobs[digest] = data
+		f.pushes++
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "/manifests/"):
+		data, _ := ioutil.ReadAll(req.Body)
+		m := &ociManifest{}
+		if err := json.Unmarshal(data, m); err != nil || req.Header.Get("Content-Type") != ociManifestMediaType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, l := range append([]ociDescriptor{m.Config}, m.Layers...) {
+			if _, ok := f.blobs[l.Digest]; !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("MANIFEST_BLOB_UNKNOWN"))
+				return
+			}
+		}
+		f.manifests[strings.TrimPrefix(path, "/manifests/")] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestOCIPushArtifact(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "oci")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	am := assetMap{}
+	for name, content := range map[string]string{"kubeadm": "binary", "kubeadm.spdx.json": `{"spdxVersion":"SPDX-2.3"}`} {
+		am[name] = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(am[name], []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	f := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	server := httptest.NewTLSServer(f)
+	defer server.Close()
+	repository := strings.TrimPrefix(server.URL, "https://") + "/org/kubeadm"
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedPushes int
+		expectedError  bool
+	}{
+		{
+			name:          "invalid: wrong credentials",
+			username:      "user",
+			password:      "wrong",
+			expectedError: true,
+		},
+		{
+			name:           "valid: the config, the assets and the checksums are pushed",
+			username:       "user",
+			password:       "pass",
+			expectedPushes: 4,
+		},
+		{
+			name:           "valid: existing blobs are not pushed again",
+			username:       "user",
+			password:       "pass",
+			expectedPushes: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newOCIRegistry(server.Client(), repository, tt.username, tt.password)
+			ref, err := r.pushArtifact(OCITag("v1.18.0+k8s"), "v1.18.0+k8s", "https://github.com/org/repo/releases/tag/v1.18.0", am)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got: %v", tt.expectedError, err)
+			}
+			if f.pushes != tt.expectedPushes {
+				t.Errorf("expected %d pushed blobs, got %d", tt.expectedPushes, f.pushes)
+			}
+			if tt.expectedError {
+				return
+			}
+
+			data, ok := f.manifests["v1.18.0_k8s"]
+			if !ok {
+				t.Fatalf("expected a manifest for tag %q", "v1.18.0_k8s")
+			}
+			if expected := repository + "@" + sha256Digest(data); ref != expected {
+				t.Errorf("expected reference %q, got %q", expected, ref)
+			}
+			m := &ociManifest{}
+			if err := json.Unmarshal(data, m); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.ArtifactType != OCIArtifactType || m.Config.MediaType != ociEmptyMediaType {
+				t.Errorf("unexpected manifest: %s", data)
+			}
+			titles := []string{}
+			for _, l := range m.Layers {
+				titles = append(titles, l.Annotations[ociAnnotationTitle])
+			}
+			if got, expected := strings.Join(titles, ","), "kubeadm,kubeadm.spdx.json,SHA256SUMS"; got != expected {
+				t.Errorf("expected layers %q, got %q", expected, got)
+			}
+			checksums := string(f.blobs[m.Layers[2].Digest])
+			expectedChecksums := strings.TrimPrefix(sha256Digest([]byte("binary")), "sha256:") + "  kubeadm\n" +
+				strings.TrimPrefix(sha256Digest([]byte(`{"spdxVersion":"SPDX-2.3"}`)), "sha256:") + "  kubeadm.spdx.json\n"
+			if checksums != expectedChecksums {
+				t.Errorf("expected checksums:\n%s\ngot:\n%s", expectedChecksums, checksums)
+			}
+		})
+	}
+}
+
+func TestValidateOCIRepository(t *testing.T) {
+	tests := []struct {
+		repository    string
+		expectedError bool
+	}{
+		{repository: "ghcr.io/org/kubeadm"},
+		{repository: "localhost:5000/kubeadm"},
+		{repository: "registry.k8s.io/kubeadm/release-assets"},
+		{repository: "ghcr.io", expectedError: true},
+		{repository: "ghcr.io/Org/kubeadm", expectedError: true},
+		{repository: "https://ghcr.io/org/kubeadm", expectedError: true},
+		{repository: "ghcr.io/org/kubeadm:v1.18.0", expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			err := ValidateOCIRepository(FlagOCIRepository, tt.repository)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
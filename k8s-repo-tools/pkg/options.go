@@ -0,0 +1,468 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The struct tags of an options struct. An options struct declares the
+// flags of a tool and how they are validated. Every field must have the
+// same name and type as a field of Data, which holds the flag value:
+//
+//	type options struct {
+//		pkg.TokenOptions
+//		Source     []string `flag:"source" usage:"The org/repo to read from" validate:"required,repo"`
+//		ReleaseTag string   `flag:"release-tag" usage:"The release tag" validate:"semver"`
+//	}
+//
+// The flags of embedded structs, such as TokenOptions, are declared like the
+// flags of the options struct. A field without a flag tag that has the name
+// of a field of an embedded struct changes the tags of this flag that it sets:
+//
+//	type options struct {
+//		pkg.TokenOptions
+//		Token string `validate:"token"`
+//	}
+//
+// The values of the fields of an options struct are not used.
+const (
+	// OptionTagFlag is the name of the flag. It is required.
+	OptionTagFlag = "flag"
+	// OptionTagDefault is the default value of the flag, in the format of
+	// the value passed on the command line.
+	OptionTagDefault = "default"
+	// OptionTagUsage is the description of the flag. It is required.
+	OptionTagUsage = "usage"
+	// OptionTagEnv is an environment variable that is read if the one
+	// with EnvPrefix is not set.
+	OptionTagEnv = "env"
+	// OptionTagValue is the kind of value of a flag that is not stored like
+	// the type of its field. It is one of the OptionValue constants.
+	OptionTagValue = "value"
+	// OptionTagAlias is another name of the flag.
+	OptionTagAlias = "alias"
+	// OptionTagDeprecated is an old name of the flag that is still accepted.
+	OptionTagDeprecated = "deprecated"
+	// OptionTagValidate is a comma separated list of validation rules.
+	OptionTagValidate = "validate"
+)
+
+// The values of OptionTagValue.
+const (
+	// OptionValueURL is a string that must be an absolute HTTP(S) URL.
+	OptionValueURL = "url"
+	// OptionValueToken is a string that holds the first token. All tokens
+	// passed with the flag are stored in Data.Tokens.
+	OptionValueToken = "token"
+	// OptionValueForce is a bool that also skips all confirmation prompts.
+	OptionValueForce = "force"
+)
+
+// The validation rules of OptionTagValidate. The rules that make an option
+// required are always applied. Other rules are skipped for options that are
+// empty.
+const (
+	// OptionRuleRequired fails if the option is empty.
+	OptionRuleRequired = "required"
+	// OptionRuleRequiredIf fails if the option is empty and the option
+	// after '=' is not, such as 'requiredif=gitea-url'.
+	OptionRuleRequiredIf = "requiredif"
+	// OptionRuleRequiredUnless fails if the option and the option after
+	// '=' are empty, such as 'requiredunless=comparison-config'.
+	OptionRuleRequiredUnless = "requiredunless"
+	// OptionRuleSingle checks if a list option has a single non-empty value.
+	OptionRuleSingle = "single"
+	// OptionRuleNoEmpty checks if no value of a list option is empty.
+	OptionRuleNoEmpty = "noempty"
+	// OptionRuleRepo checks if the option is of the format 'org/repo'.
+	// A list of repositories must have a single repository.
+	OptionRuleRepo = "repo"
+	// OptionRuleRepos checks if the option is a list or a comma separated
+	// list of repositories of the format 'org/repo'.
+	OptionRuleRepos = "repos"
+	// OptionRuleSemVer checks if the option is a SemVer tag.
+	OptionRuleSemVer = "semver"
+	// OptionRuleSHA checks if the option is a full commit SHA.
+	OptionRuleSHA = "sha"
+	// OptionRuleToken checks if all tokens passed with the option are
+	// GitHub tokens.
+	OptionRuleToken = "token"
+	// OptionRuleIssue checks if the option is of the format 'org/repo#issue'.
+	OptionRuleIssue = "issue"
+	// OptionRuleGitHubURL checks the values of a list option that are of the
+	// format 'github://org/repo@ref/path'. Other values are not checked.
+	OptionRuleGitHubURL = "githuburl"
+	// OptionRuleOCIRepo checks if the option is a repository in an OCI
+	// registry such as 'ghcr.io/org/name'.
+	OptionRuleOCIRepo = "ocirepo"
+	// OptionRuleBucket checks if the option is a bucket and an optional
+	// path such as 'gs://bucket/path' or 's3://bucket/path'.
+	OptionRuleBucket = "bucket"
+	// OptionRuleRelPath checks if the option is a path relative to the
+	// root of a repository.
+	OptionRuleRelPath = "relpath"
+	// OptionRuleHostPort checks if the option is of the format 'host:port'.
+	OptionRuleHostPort = "hostport"
+	// OptionRulePositive checks if a numeric option is greater than zero.
+	OptionRulePositive = "positive"
+	// OptionRuleNonNegative checks if a numeric option is not negative.
+	OptionRuleNonNegative = "nonnegative"
+	// OptionRuleOneOf checks if the option is one of the values after
+	// '=' separated by '|', such as 'oneof=text|json'.
+	OptionRuleOneOf = "oneof"
+)
+
+// option is a field of an options struct.
+type option struct {
+	field      string
+	flag       string
+	def        string
+	usage      string
+	env        string
+	value      string
+	alias      string
+	deprecated string
+	validate   []string
+}
+
+// parseOptions returns the options declared by the fields of opts, which
+// must be a pointer to an options struct. It panics if opts is not valid,
+// since this is a programming error.
+func parseOptions(opts interface{}) []option {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("options must be a pointer to a struct, got %T", opts))
+	}
+	return parseOptionsType(v.Elem().Type())
+}
+
+// parseOptionsType returns the options declared by the fields of the
+// options struct t, including the fields of embedded structs.
+func parseOptionsType(t reflect.Type) []option {
+	dataType := reflect.TypeOf(Data{})
+	result := []option{}
+	overrides := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			if f.Type.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("the embedded field %s of %s must be a struct", f.Name, t))
+			}
+			result = append(result, parseOptionsType(f.Type)...)
+			continue
+		}
+		df, ok := dataType.FieldByName(f.Name)
+		if !ok || df.Type != f.Type {
+			panic(fmt.Sprintf("the field %s of %s must have the same name and type as a field of Data", f.Name, t))
+		}
+		if _, ok := f.Tag.Lookup(OptionTagFlag); !ok {
+			overrides = append(overrides, f)
+			continue
+		}
+		o := option{
+			field:      f.Name,
+			flag:       f.Tag.Get(OptionTagFlag),
+			def:        f.Tag.Get(OptionTagDefault),
+			usage:      f.Tag.Get(OptionTagUsage),
+			env:        f.Tag.Get(OptionTagEnv),
+			value:      f.Tag.Get(OptionTagValue),
+			alias:      f.Tag.Get(OptionTagAlias),
+			deprecated: f.Tag.Get(OptionTagDeprecated),
+			validate:   splitOptionRules(f.Tag.Get(OptionTagValidate)),
+		}
+		if len(o.flag) == 0 {
+			panic(fmt.Sprintf("the field %s of %s has an empty %q tag", f.Name, t, OptionTagFlag))
+		}
+		result = append(result, o)
+	}
+
+	// Apply the tags of fields that change the flags of embedded structs.
+	for _, f := range overrides {
+		o := findOption(result, func(o option) bool { return o.field == f.Name })
+		if o == nil {
+			panic(fmt.Sprintf("the field %s of %s has no %q tag", f.Name, t, OptionTagFlag))
+		}
+		if v, ok := f.Tag.Lookup(OptionTagDefault); ok {
+			o.def = v
+		}
+		if v, ok := f.Tag.Lookup(OptionTagUsage); ok {
+			o.usage = v
+		}
+		if v, ok := f.Tag.Lookup(OptionTagEnv); ok {
+			o.env = v
+		}
+		if v, ok := f.Tag.Lookup(OptionTagValidate); ok {
+			o.validate = splitOptionRules(v)
+		}
+	}
+	return result
+}
+
+// findOption returns a pointer to the first option for which match
+// returns true, or nil.
+func findOption(options []option, match func(option) bool) *option {
+	for i := range options {
+		if match(options[i]) {
+			return &options[i]
+		}
+	}
+	return nil
+}
+
+// splitOptionRules splits the comma separated rules of OptionTagValidate.
+func splitOptionRules(rules string) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	return strings.Split(rules, ",")
+}
+
+// SetupOptions registers the flags of CommonOptions and the flags declared
+// by opts in a FlagSet. opts must be a pointer to an options struct.
+func SetupOptions(d *Data, fs *flag.FlagSet, opts interface{}) {
+	data := reflect.ValueOf(d).Elem()
+	for _, o := range append(parseOptions(&CommonOptions{}), parseOptions(opts)...) {
+		setupOption(d, fs, o, data.FieldByName(o.field).Addr().Interface())
+	}
+}
+
+// setupOption registers the flag of an option and its alias and deprecated
+// name. p is a pointer to the field of Data that holds the value.
+func setupOption(d *Data, fs *flag.FlagSet, o option, p interface{}) {
+	if len(o.usage) == 0 {
+		panic(fmt.Sprintf("the flag %q has no %q tag", o.flag, OptionTagUsage))
+	}
+	switch o.value {
+	case "":
+		switch p := p.(type) {
+		case *string:
+			fs.StringVar(p, o.flag, "", o.usage)
+		case *bool:
+			fs.BoolVar(p, o.flag, false, o.usage)
+		case *int:
+			fs.IntVar(p, o.flag, 0, o.usage)
+		case *time.Duration:
+			fs.DurationVar(p, o.flag, 0, o.usage)
+		case *[]string:
+			fs.Var(&repoListValue{value: p}, o.flag, o.usage)
+		case flag.Value:
+			fs.Var(p, o.flag, o.usage)
+		default:
+			panic(fmt.Sprintf("the flag %q has the unsupported type %T", o.flag, p))
+		}
+	case OptionValueURL:
+		fs.Var(urlValue{p.(*string)}, o.flag, o.usage)
+	case OptionValueToken:
+		fs.Var(tokenValue{p.(*string), &d.Tokens}, o.flag, o.usage)
+	case OptionValueForce:
+		if p != &d.Force {
+			panic(fmt.Sprintf("the flag %q of kind %q must be stored in Data.Force", o.flag, o.value))
+		}
+		fs.Var(forceValue{d}, o.flag, o.usage)
+	default:
+		panic(fmt.Sprintf("the flag %q has the unknown %q tag %q", o.flag, OptionTagValue, o.value))
+	}
+
+	f := fs.Lookup(o.flag)
+	if len(o.def) != 0 {
+		if err := f.Value.Set(o.def); err != nil {
+			panic(fmt.Sprintf("invalid default value %q of the flag %q: %v", o.def, o.flag, err))
+		}
+		f.DefValue = o.def
+		// The first value passed on the command line replaces the default.
+		if r, ok := f.Value.(*repoListValue); ok {
+			r.set = false
+		}
+	}
+	if len(o.alias) != 0 {
+		fs.Var(f.Value, o.alias, fmt.Sprintf("Alias for %q", o.flag))
+	}
+	if len(o.deprecated) != 0 {
+		fs.Var(f.Value, o.deprecated, fmt.Sprintf("Deprecated: use %q", o.flag))
+	}
+	if len(o.env) != 0 {
+		envAliases[o.flag] = o.env
+	}
+}
+
+// ValidateOptions validates the values of the flags declared by opts with
+// the rules of their validate tags. opts must be a pointer to an options
+// struct. The options are validated in the order of the fields.
+func ValidateOptions(d *Data, opts interface{}) error {
+	data := reflect.ValueOf(d).Elem()
+	options := append(parseOptions(&CommonOptions{}), parseOptions(opts)...)
+	for _, o := range options {
+		value := data.FieldByName(o.field)
+		empty := isZeroValue(value)
+		for _, rule := range o.validate {
+			rule, arg := splitOptionRule(rule)
+			switch rule {
+			case OptionRuleRequired, OptionRuleRequiredIf, OptionRuleRequiredUnless:
+				if !empty {
+					continue
+				}
+				if err := validateRequiredOption(data, options, o.flag, rule, arg); err != nil {
+					return err
+				}
+				continue
+			}
+			if empty {
+				continue
+			}
+			if err := validateOptionRule(d, o.flag, rule, arg, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateRequiredOption validates an empty option with a rule that can
+// make it required. The option arg of the rule is looked up in options.
+func validateRequiredOption(data reflect.Value, options []option, name, rule, arg string) error {
+	if rule == OptionRuleRequired {
+		return errors.Errorf("the option %q cannot be empty", name)
+	}
+	other := findOption(options, func(o option) bool { return o.flag == arg })
+	if other == nil {
+		panic(fmt.Sprintf("the rule %q of the flag %q refers to the unknown flag %q", rule, name, arg))
+	}
+	otherEmpty := isZeroValue(data.FieldByName(other.field))
+	switch {
+	case rule == OptionRuleRequiredIf && !otherEmpty:
+		return errors.Errorf("the option %q requires %q", arg, name)
+	case rule == OptionRuleRequiredUnless && otherEmpty:
+		return errors.Errorf("one of the options %q or %q is required", name, arg)
+	}
+	return nil
+}
+
+// validateOptionRule validates the non-empty value of a flag with a single rule.
+func validateOptionRule(d *Data, name, rule, arg string, value reflect.Value) error {
+	switch rule {
+	case OptionRuleToken:
+		return ValidateTokens(name, d.GetTokens())
+	case OptionRulePositive, OptionRuleNonNegative:
+		switch value.Kind() {
+		case reflect.Int, reflect.Int64:
+			if rule == OptionRulePositive && value.Int() <= 0 {
+				return errors.Errorf("the option %q must be greater than zero", name)
+			}
+			if value.Int() < 0 {
+				return errors.Errorf("the option %q cannot be negative", name)
+			}
+			return nil
+		}
+	}
+
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String {
+		values := value.Convert(reflect.TypeOf([]string{})).Interface().([]string)
+		switch rule {
+		case OptionRuleRepo:
+			return ValidateSingleRepo(name, values)
+		case OptionRuleRepos:
+			return ValidateRepos(name, values)
+		case OptionRuleSingle:
+			return ValidateSingleOption(name, values)
+		case OptionRuleNoEmpty:
+			for _, v := range values {
+				if err := ValidateEmptyOption(name, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		case OptionRuleGitHubURL:
+			for _, v := range values {
+				if !IsGitHubURL(v) {
+					continue
+				}
+				if _, err := ParseGitHubURL(v); err != nil {
+					return errors.Wrapf(err, "invalid value of the option %q", name)
+				}
+			}
+			return nil
+		}
+	}
+	if value.Kind() != reflect.String {
+		panic(fmt.Sprintf("the rule %q cannot be used for the flag %q of type %s", rule, name, value.Type()))
+	}
+	s := value.String()
+	switch rule {
+	case OptionRuleRepo:
+		return ValidateRepo(name, s)
+	case OptionRuleRepos:
+		return ValidateRepos(name, SplitRepos(s))
+	case OptionRuleSemVer:
+		return ValidateReleaseTag(name, s)
+	case OptionRuleSHA:
+		return ValidateSHA(name, s)
+	case OptionRuleIssue:
+		return ValidateTargetIssue(name, s)
+	case OptionRuleOCIRepo:
+		return ValidateOCIRepository(name, s)
+	case OptionRuleBucket:
+		if !IsObjectStoragePath(s) || len(strings.Split(s, "/")[2]) == 0 {
+			return errors.Errorf("the option %q must be a bucket and an optional path in the form 'gs://bucket/path' or 's3://bucket/path'", name)
+		}
+		return nil
+	case OptionRuleRelPath:
+		if strings.HasPrefix(s, "/") || strings.Contains(s, "..") {
+			return errors.Errorf("the option %q must be a path relative to the root of the repository, got %q", name, s)
+		}
+		return nil
+	case OptionRuleHostPort:
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			return errors.Wrapf(err, "the option %q must be in the format 'host:port'", name)
+		}
+		return nil
+	case OptionRuleOneOf:
+		values := strings.Split(arg, "|")
+		for _, v := range values {
+			if s == v {
+				return nil
+			}
+		}
+		return errors.Errorf("the option %q must be one of %q", name, values)
+	}
+	panic(fmt.Sprintf("unknown validation rule %q of the flag %q", rule, name))
+}
+
+// splitOptionRule splits a validation rule of the format 'rule=arg'.
+func splitOptionRule(rule string) (string, string) {
+	if i := strings.Index(rule, "="); i >= 0 {
+		return rule[:i], rule[i+1:]
+	}
+	return rule, ""
+}
+
+// isZeroValue returns true if a flag value is empty. Unlike
+// reflect.Value.IsZero it treats empty slices and maps as empty.
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
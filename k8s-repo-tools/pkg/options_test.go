@@ -0,0 +1,372 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOptions struct {
+	TokenOptions
+	TimeoutOptions
+	ForceOptions
+	Token        string        `validate:"token"`
+	Source       []string      `flag:"source" usage:"The repository to test" validate:"required,repo"`
+	ReleaseTag   string        `flag:"release-tag" usage:"A release tag" validate:"semver"`
+	OutputFormat string        `flag:"output-format" usage:"A format" default:"json" validate:"oneof=text|json"`
+	Bump         string        `flag:"test-bump" usage:"A string flag" env:"TEST_BUMP"`
+	Parallelism  int           `flag:"test-parallelism" usage:"An int flag" default:"4" validate:"positive"`
+	Offset       int           `flag:"test-offset" usage:"An int flag that can be zero" validate:"nonnegative"`
+	RunDeadline  time.Duration `flag:"test-deadline" usage:"A duration flag" default:"1m"`
+	Labels       multiString   `flag:"test-label" usage:"A flag that can be passed multiple times" validate:"noempty"`
+	Branches     multiString   `flag:"test-branch" usage:"A flag with a single value" validate:"single"`
+	GiteaURL     string        `flag:"test-url" value:"url" usage:"A URL flag"`
+	GiteaToken   string        `flag:"test-url-token" usage:"A flag that is required with test-url" validate:"requiredif=test-url"`
+	Dest         []string      `flag:"dest" usage:"A flag that is required without test-config" validate:"requiredunless=test-config,repos"`
+	SkewPolicy   string        `flag:"test-config" usage:"A path"`
+}
+
+func TestSetupOptions(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer delete(envAliases, "test-bump")
+
+	d := &Data{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	SetupOptions(d, fs, &testOptions{})
+
+	// The common flags and the flags of embedded structs are registered.
+	for name, expected := range map[string]string{
+		FlagSource:           "The repository to test",
+		FlagTokenFile:        "",
+		FlagVerbosity:        "",
+		FlagHTTPMaxIdleConns: "",
+		FlagRequestTimeout:   "",
+		FlagTimeout:          `Deprecated: use "request-timeout"`,
+		FlagYes:              `Alias for "force"`,
+		"test-bump":          "A string flag",
+		"test-parallelism":   "An int flag",
+	} {
+		f := fs.Lookup(name)
+		if f == nil {
+			t.Errorf("the flag %q is not registered", name)
+			continue
+		}
+		if len(expected) != 0 && f.Usage != expected {
+			t.Errorf("expected the usage of %q to be %q, got %q", name, expected, f.Usage)
+		}
+	}
+	for name, expected := range map[string]string{
+		"test-bump":      "TEST_BUMP",
+		FlagToken:        "GITHUB_TOKEN",
+		FlagOTLPEndpoint: "OTEL_EXPORTER_OTLP_ENDPOINT",
+	} {
+		if envAliases[name] != expected {
+			t.Errorf("expected the environment variable %q for %q, got %q", expected, name, envAliases[name])
+		}
+	}
+
+	// The default values are applied.
+	if d.OutputFormat != "json" || d.Parallelism != 4 || d.RunDeadline != time.Minute || d.Timeout != DefaultRequestTimeout {
+		t.Errorf("unexpected default values: %q, %d, %v, %v", d.OutputFormat, d.Parallelism, d.RunDeadline, d.Timeout)
+	}
+	if f := fs.Lookup("test-parallelism"); f.DefValue != "4" {
+		t.Errorf("expected the default value %q, got %q", "4", f.DefValue)
+	}
+
+	// The flags are bound to the fields of Data.
+	args := []string{
+		"-source=org/repo",
+		"-token=foo",
+		"-token=bar",
+		"-timeout=1s",
+		"-yes",
+		"-test-bump=minor",
+		"-test-parallelism=2",
+		"-test-label=a",
+		"-test-label=b",
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if d.SourceRepo() != "org/repo" || d.Bump != "minor" || d.Parallelism != 2 || d.Timeout != time.Second || !d.Force {
+		t.Errorf("unexpected values: %q, %q, %d, %v, %v", d.SourceRepo(), d.Bump, d.Parallelism, d.Timeout, d.Force)
+	}
+	if d.Token != "foo" || !reflect.DeepEqual(d.Tokens, multiString{"foo", "bar"}) {
+		t.Errorf("unexpected tokens: %q, %v", d.Token, d.Tokens)
+	}
+	if !reflect.DeepEqual(d.Labels, multiString{"a", "b"}) {
+		t.Errorf("expected labels %v, got %v", []string{"a", "b"}, d.Labels)
+	}
+	if err := fs.Set("test-url", "foo"); err == nil {
+		t.Errorf("expected an error for a value of %q that is not a URL", "test-url")
+	}
+}
+
+func TestCommonOptionDefaults(t *testing.T) {
+	d := &Data{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	SetupOptions(d, fs, &struct {
+		TokenOptions
+		TimeoutOptions
+	}{})
+
+	// The default values in the tags match the constants.
+	expected := &Data{
+		Verbosity:               DefaultVerbosity,
+		LogFormat:               LogFormatText,
+		HTTPMaxIdleConns:        DefaultHTTPMaxIdleConns,
+		HTTPMaxIdleConnsPerHost: DefaultHTTPMaxIdleConnsPerHost,
+		HTTPIdleConnTimeout:     DefaultHTTPIdleConnTimeout,
+		Retries:                 DefaultRetries,
+		RetryWait:               DefaultRetryWait,
+		Timeout:                 DefaultRequestTimeout,
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("expected data:\n%+v\ngot:\n%+v", expected, d)
+	}
+	if usage := fs.Lookup(FlagRequestTimeout).Usage; !strings.HasSuffix(usage, DefaultTransferTimeout.String()) {
+		t.Errorf("expected the usage of %q to end with %v, got %q", FlagRequestTimeout, DefaultTransferTimeout, usage)
+	}
+}
+
+func TestSetupOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts interface{}
+	}{
+		{
+			name: "not a pointer",
+			opts: testOptions{},
+		},
+		{
+			name: "missing flag tag",
+			opts: &struct {
+				Source string `usage:"foo"`
+			}{},
+		},
+		{
+			name: "unknown field",
+			opts: &struct {
+				Unknown string `flag:"unknown" usage:"foo"`
+			}{},
+		},
+		{
+			name: "type that does not match Data",
+			opts: &struct {
+				Source int `flag:"source"`
+			}{},
+		},
+		{
+			name: "missing usage",
+			opts: &struct {
+				Bump string `flag:"test-bump"`
+			}{},
+		},
+		{
+			name: "field without a flag that is not embedded",
+			opts: &struct {
+				TimeoutOptions
+				Bump string `usage:"foo"`
+			}{},
+		},
+		{
+			name: "unknown value",
+			opts: &struct {
+				Bump string `flag:"test-bump" usage:"foo" value:"foo"`
+			}{},
+		},
+		{
+			name: "invalid default value",
+			opts: &struct {
+				Parallelism int `flag:"test-parallelism" usage:"foo" default:"foo"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			SetupOptions(&Data{}, fs, tt.opts)
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	const validToken = "282ef40c7d38cbfafe7d6ebe91cdfbbcbe5d71ab"
+
+	tests := []struct {
+		name          string
+		data          *Data
+		expectedError bool
+	}{
+		{
+			name: "valid: all options",
+			data: &Data{
+				Source:       []string{"org/repo"},
+				Dest:         []string{"org/dest"},
+				Token:        validToken,
+				ReleaseTag:   "v1.17.0",
+				OutputFormat: "text",
+				Parallelism:  1,
+			},
+		},
+		{
+			name: "valid: empty options are only validated if required",
+			data: &Data{
+				Source: []string{"org/repo"},
+				Dest:   []string{"org/dest"},
+			},
+		},
+		{
+			name: "valid: option that is only required without another option",
+			data: &Data{
+				Source:     []string{"org/repo"},
+				SkewPolicy: "policy.yaml",
+			},
+		},
+		{
+			name: "valid: option that is required with another option",
+			data: &Data{
+				Source:     []string{"org/repo"},
+				Dest:       []string{"org/dest"},
+				GiteaURL:   "https://gitea.example.com",
+				GiteaToken: "token",
+			},
+		},
+		{
+			name:          "invalid: missing required option",
+			data:          &Data{},
+			expectedError: true,
+		},
+		{
+			name: "invalid: malformed repository",
+			data: &Data{
+				Source: []string{"org"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: malformed token",
+			data: &Data{
+				Source: []string{"org/repo"},
+				Dest:   []string{"org/dest"},
+				Token:  "foo",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: malformed second token",
+			data: &Data{
+				Source: []string{"org/repo"},
+				Dest:   []string{"org/dest"},
+				Token:  validToken,
+				Tokens: multiString{validToken, "foo"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: release tag is not SemVer",
+			data: &Data{
+				Source:     []string{"org/repo"},
+				Dest:       []string{"org/dest"},
+				ReleaseTag: "foo",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: value is not one of the allowed values",
+			data: &Data{
+				Source:       []string{"org/repo"},
+				Dest:         []string{"org/dest"},
+				OutputFormat: "yaml",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: missing option that is required without another option",
+			data: &Data{
+				Source: []string{"org/repo"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: missing option that is required with another option",
+			data: &Data{
+				Source:   []string{"org/repo"},
+				Dest:     []string{"org/dest"},
+				GiteaURL: "https://gitea.example.com",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: empty value of a list",
+			data: &Data{
+				Source: []string{"org/repo"},
+				Dest:   []string{"org/dest"},
+				Labels: multiString{"a", ""},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: multiple values of a single value option",
+			data: &Data{
+				Source:   []string{"org/repo"},
+				Dest:     []string{"org/dest"},
+				Branches: multiString{"a", "b"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: negative value of a non-negative option",
+			data: &Data{
+				Source: []string{"org/repo"},
+				Dest:   []string{"org/dest"},
+				Offset: -1,
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid: negative value",
+			data: &Data{
+				Source:      []string{"org/repo"},
+				Dest:        []string{"org/dest"},
+				Parallelism: -1,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOptions(tt.data, &testOptions{})
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// OSVQueryURL is the endpoint of the OSV (https://osv.dev) API
+// for querying the vulnerabilities of a package version.
+const OSVQueryURL = "https://api.osv.dev/v1/query"
+
+// osvQuery is the request body of an OSV query.
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+// osvResponse is a subset of the response body of an OSV query.
+type osvResponse struct {
+	Vulns []struct {
+		ID string `json:"id"`
+	} `json:"vulns"`
+}
+
+// OSVGetVulnerabilities returns a sorted list of known vulnerability IDs
+// for a version of a Go module. An empty list means that there are no
+// known vulnerabilities.
+func OSVGetVulnerabilities(client *http.Client, url, path, version string) ([]string, error) {
+	query := osvQuery{
+		Package: osvPackage{Name: path, Ecosystem: "Go"},
+		Version: version,
+	}
+	buf, err := json.Marshal(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	V(2).Logf("querying vulnerabilities for %s@%s", path, version)
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("received status %d querying vulnerabilities for %s@%s",
+			resp.StatusCode, path, version)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read response body")
+	}
+	r := osvResponse{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, errors.Wrap(err, "could not parse the OSV response")
+	}
+
+	ids := make([]string, len(r.Vulns))
+	for i := range r.Vulns {
+		ids[i] = r.Vulns[i].ID
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
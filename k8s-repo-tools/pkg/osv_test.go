@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOSVGetVulnerabilities(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedIDs   []string
+		expectedError bool
+	}{
+		{
+			name:        "valid: found vulnerabilities",
+			status:      http.StatusOK,
+			body:        `{"vulns":[{"id":"GO-2021-0002"},{"id":"GO-2020-0001"}]}`,
+			expectedIDs: []string{"GO-2020-0001", "GO-2021-0002"},
+		},
+		{
+			name:        "valid: no vulnerabilities",
+			status:      http.StatusOK,
+			body:        `{}`,
+			expectedIDs: []string{},
+		},
+		{
+			name:          "invalid: unexpected status",
+			status:        http.StatusBadRequest,
+			body:          `{}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed response",
+			status:        http.StatusOK,
+			body:          `foo`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query osvQuery
+			transport := NewTransport()
+			transport.SetHandler(OSVQueryURL, func(req *http.Request) (*http.Response, error) {
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				if err := json.Unmarshal(body, &query); err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: tt.status,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+					Header:     http.Header{},
+				}, nil
+			})
+			client := &http.Client{Transport: transport}
+
+			ids, err := OSVGetVulnerabilities(client, OSVQueryURL, "k8s.io/klog", "v1.0.0")
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			expectedQuery := osvQuery{
+				Package: osvPackage{Name: "k8s.io/klog", Ecosystem: "Go"},
+				Version: "v1.0.0",
+			}
+			if query != expectedQuery {
+				t.Errorf("expected query %+v, got %+v", expectedQuery, query)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// PlanAction is the type of a write operation in a plan.
+type PlanAction string
+
+const (
+	// PlanActionCreateRef creates a ref from a commit.
+	PlanActionCreateRef PlanAction = "createRef"
+	// PlanActionUpdateRef forces an existing ref to point to a commit.
+	PlanActionUpdateRef PlanAction = "updateRef"
+	// PlanActionDeleteRef deletes a ref.
+	PlanActionDeleteRef PlanAction = "deleteRef"
+	// PlanActionMerge merges a head branch into a base branch.
+	PlanActionMerge PlanAction = "merge"
+	// PlanActionCreateRelease creates a release from a tag.
+	PlanActionCreateRelease PlanAction = "createRelease"
+	// PlanActionUploadAsset uploads a file as an asset of the release for a tag.
+	PlanActionUploadAsset PlanAction = "uploadAsset"
+	// PlanActionCreateComment creates a comment in an issue.
+	PlanActionCreateComment PlanAction = "createComment"
+	// PlanActionProtectBranch applies branch protection to a branch.
+	PlanActionProtectBranch PlanAction = "protectBranch"
+	// PlanActionUpdateFile commits the contents of a file to a branch.
+	PlanActionUpdateFile PlanAction = "updateFile"
+	// PlanActionCreatePullRequest creates a pull request from a head branch into a base branch.
+	PlanActionCreatePullRequest PlanAction = "createPullRequest"
+	// PlanActionCherryPick creates a branch from a base branch and cherry-picks commits on it.
+	PlanActionCherryPick PlanAction = "cherryPick"
+	// PlanActionCreateMilestone creates a milestone.
+	PlanActionCreateMilestone PlanAction = "createMilestone"
+	// PlanActionEditMilestone updates the title, description, due date and state of a milestone.
+	PlanActionEditMilestone PlanAction = "editMilestone"
+	// PlanActionCreateLabel creates a label.
+	PlanActionCreateLabel PlanAction = "createLabel"
+	// PlanActionEditLabel updates the name, color and description of the label in Ref.
+	PlanActionEditLabel PlanAction = "editLabel"
+	// PlanActionDeleteLabel deletes a label.
+	PlanActionDeleteLabel PlanAction = "deleteLabel"
+	// PlanActionCreateIssue creates an issue.
+	PlanActionCreateIssue PlanAction = "createIssue"
+	// PlanActionEditIssue updates the title and state of an issue.
+	PlanActionEditIssue PlanAction = "editIssue"
+	// PlanActionRerunWorkflow re-runs a GitHub Actions workflow run.
+	PlanActionRerunWorkflow PlanAction = "rerunWorkflow"
+	// PlanActionRerequestCheckSuite re-requests a check suite.
+	PlanActionRerequestCheckSuite PlanAction = "rerequestCheckSuite"
+)
+
+// PlanStep is a single write operation in a plan.
+type PlanStep struct {
+	Action     PlanAction `json:"action"`
+	Repo       string     `json:"repo,omitempty"`
+	Ref        string     `json:"ref,omitempty"`
+	SHA        string     `json:"sha,omitempty"`
+	Base       string     `json:"base,omitempty"`
+	Head       string     `json:"head,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	Tag        string     `json:"tag,omitempty"`
+	Body       string     `json:"body,omitempty"`
+	Asset      string     `json:"asset,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	Issue      string     `json:"issue,omitempty"`
+	Branch     string     `json:"branch,omitempty"`
+	SHAs       []string   `json:"shas,omitempty"`
+	Labels     []string   `json:"labels,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	State      string     `json:"state,omitempty"`
+	DueOn      *time.Time `json:"dueOn,omitempty"`
+	Number     int        `json:"number,omitempty"`
+	ID         int64      `json:"id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Color      string     `json:"color,omitempty"`
+	Draft      bool       `json:"draft,omitempty"`
+	Prerelease bool       `json:"prerelease,omitempty"`
+}
+
+// String returns a short description of the step.
+func (s PlanStep) String() string {
+	switch s.Action {
+	case PlanActionCreateRef:
+		return fmt.Sprintf("create ref %q from commit %q in repository %q", s.Ref, s.SHA, s.Repo)
+	case PlanActionUpdateRef:
+		return fmt.Sprintf("update ref %q to commit %q in repository %q", s.Ref, s.SHA, s.Repo)
+	case PlanActionDeleteRef:
+		return fmt.Sprintf("delete ref %q from repository %q", s.Ref, s.Repo)
+	case PlanActionMerge:
+		return fmt.Sprintf("merge %q into %q in repository %q", s.Head, s.Base, s.Repo)
+	case PlanActionCreateRelease:
+		return fmt.Sprintf("create a release for tag %q in repository %q", s.Tag, s.Repo)
+	case PlanActionUploadAsset:
+		return fmt.Sprintf("upload asset %q from path %q to the release for tag %q in repository %q", s.Asset, s.Path, s.Tag, s.Repo)
+	case PlanActionCreateComment:
+		return fmt.Sprintf("create a comment in issue %q", s.Issue)
+	case PlanActionProtectBranch:
+		return fmt.Sprintf("protect branch %q in repository %q", s.Branch, s.Repo)
+	case PlanActionUpdateFile:
+		return fmt.Sprintf("update file %q in branch %q of repository %q", s.Path, s.Branch, s.Repo)
+	case PlanActionCreatePullRequest:
+		return fmt.Sprintf("create a pull request from %q into %q in repository %q", s.Head, s.Base, s.Repo)
+	case PlanActionCreateMilestone:
+		return fmt.Sprintf("create milestone %q in repository %q", s.Title, s.Repo)
+	case PlanActionEditMilestone:
+		return fmt.Sprintf("update milestone %q in repository %q", s.Title, s.Repo)
+	case PlanActionCreateLabel:
+		return fmt.Sprintf("create label %q in repository %q", s.Name, s.Repo)
+	case PlanActionEditLabel:
+		return fmt.Sprintf("update label %q in repository %q", s.Ref, s.Repo)
+	case PlanActionDeleteLabel:
+		return fmt.Sprintf("delete label %q from repository %q", s.Name, s.Repo)
+	case PlanActionCreateIssue:
+		return fmt.Sprintf("create issue %q in repository %q", s.Title, s.Repo)
+	case PlanActionEditIssue:
+		return fmt.Sprintf("update issue #%d in repository %q", s.Number, s.Repo)
+	case PlanActionRerunWorkflow:
+		return fmt.Sprintf("re-run workflow run %d in repository %q", s.ID, s.Repo)
+	case PlanActionRerequestCheckSuite:
+		return fmt.Sprintf("re-request check suite %d in repository %q", s.ID, s.Repo)
+	case PlanActionCherryPick:
+		return fmt.Sprintf("cherry-pick %d commit(s) on branch %q from %q in repository %q", len(s.SHAs), s.Head, s.Base, s.Repo)
+	}
+	return fmt.Sprintf("unknown action %q", s.Action)
+}
+
+// milestone returns the milestone of a step.
+func (s PlanStep) milestone() *github.Milestone {
+	return &github.Milestone{
+		Title:       github.String(s.Title),
+		Description: github.String(s.Body),
+		DueOn:       s.DueOn,
+		State:       github.String(s.State),
+	}
+}
+
+// label returns the label of a step.
+func (s PlanStep) label() *github.Label {
+	return &github.Label{
+		Name:        github.String(s.Name),
+		Color:       github.String(s.Color),
+		Description: github.String(s.Body),
+	}
+}
+
+// release returns the release of a step.
+func (s PlanStep) release() *github.RepositoryRelease {
+	return &github.RepositoryRelease{
+		TagName:    github.String(s.Tag),
+		Name:       github.String(s.Title),
+		Body:       github.String(s.Body),
+		Draft:      github.Bool(s.Draft),
+		Prerelease: github.Bool(s.Prerelease),
+	}
+}
+
+// Plan is a list of write operations that a tool would perform.
+// It is written in DRY-RUN mode and can be applied later.
+type Plan struct {
+	Tool  string     `json:"tool"`
+	Steps []PlanStep `json:"steps"`
+}
+
+// recordPlanStep adds a step to the plan of d.
+func (d *Data) recordPlanStep(step PlanStep) {
+	recordMutex.Lock()
+	defer recordMutex.Unlock()
+	d.planSteps = append(d.planSteps, step)
+}
+
+// GetPlanSteps returns the write operations that were recorded in DRY-RUN mode.
+func (d *Data) GetPlanSteps() []PlanStep {
+	recordMutex.Lock()
+	defer recordMutex.Unlock()
+	return append([]PlanStep{}, d.planSteps...)
+}
+
+// ValidatePlanOptions validates the options for writing and applying a plan.
+func ValidatePlanOptions(d *Data) error {
+	if len(d.PlanFile) != 0 && len(d.ApplyPlanFile) != 0 {
+		return errors.Errorf("the options %q and %q cannot be used together", FlagPlan, FlagApplyPlan)
+	}
+	if len(d.PlanFile) != 0 && !d.DryRun {
+		return errors.Errorf("the option %q can only be used in %s mode", FlagPlan, PrefixDryRun)
+	}
+	if len(d.ApplyPlanFile) != 0 && d.DryRun {
+		return errors.Errorf("the option %q requires --%s=false", FlagApplyPlan, FlagDryRun)
+	}
+	return nil
+}
+
+// WritePlanToFile writes the plan for a tool to the given path.
+func WritePlanToFile(path, tool string, steps []PlanStep) error {
+	plan := Plan{Tool: tool, Steps: steps}
+	if plan.Steps == nil {
+		plan.Steps = []PlanStep{}
+	}
+	buf, err := json.MarshalIndent(plan, "", "\t")
+	if err != nil {
+		return err
+	}
+	Logf("writing a plan with %d steps to the file %q", len(plan.Steps), path)
+	if err := ioutil.WriteFile(path, buf, 0600); err != nil {
+		return errors.Wrapf(err, "could not write the plan %q", path)
+	}
+	return nil
+}
+
+// ReadPlanFromFile reads a plan from the given path and checks
+// that it was written by the given tool.
+func ReadPlanFromFile(path, tool string) (*Plan, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the plan %q", path)
+	}
+	plan := &Plan{}
+	if err := json.Unmarshal(buf, plan); err != nil {
+		return nil, errors.Wrapf(err, "could not parse the plan %q", path)
+	}
+	if plan.Tool != tool {
+		return nil, errors.Errorf("the plan %q was written by %q and cannot be applied by %q", path, plan.Tool, tool)
+	}
+	return plan, nil
+}
+
+// ApplyPlanFromFile reads a plan from d.ApplyPlanFile and applies it after
+// a confirmation prompt, which is answered by d.Prompter().
+// If d.Preflight is set the token is verified before the prompt.
+func ApplyPlanFromFile(d *Data, tool string) error {
+	plan, err := ReadPlanFromFile(d.ApplyPlanFile, tool)
+	if err != nil {
+		return err
+	}
+	if len(plan.Steps) == 0 {
+		Logf("the plan %q is empty", d.ApplyPlanFile)
+		return nil
+	}
+	if d.Preflight {
+		if err := d.Provider().PreflightToken(planRepos(plan.Steps)...); err != nil {
+			return err
+		}
+	}
+
+	PrintSeparator()
+	Logf("the plan %q has %d steps:", d.ApplyPlanFile, len(plan.Steps))
+	for i, step := range plan.Steps {
+		Logf("%d: %s", i+1, step)
+	}
+	PrintSeparator()
+
+	yes, err := d.Prompter().Confirm(fmt.Sprintf("Do you want to apply the plan %q?", d.ApplyPlanFile))
+	if err != nil {
+		return err
+	}
+	if !yes {
+		return nil
+	}
+	return ApplyPlan(d, plan)
+}
+
+// ApplyPlan executes the steps of a plan in order. It stops at the first
+// step that fails.
+func ApplyPlan(d *Data, plan *Plan) error {
+	for i, step := range plan.Steps {
+		if d.Interrupted() {
+			return ErrInterrupted
+		}
+		Logf("applying step %d/%d: %s", i+1, len(plan.Steps), step)
+		if err := applyPlanStep(d, step); err != nil {
+			return errors.Wrapf(err, "could not apply step %d: %s", i+1, step)
+		}
+	}
+	return nil
+}
+
+func applyPlanStep(d *Data, step PlanStep) error {
+	switch step.Action {
+	case PlanActionCreateRef:
+		_, err := d.Provider().CreateRef(step.Repo, step.Ref, step.SHA, false)
+		return err
+	case PlanActionUpdateRef:
+		_, err := d.Provider().UpdateRef(step.Repo, step.Ref, step.SHA, false)
+		return err
+	case PlanActionDeleteRef:
+		return d.Provider().DeleteRef(step.Repo, step.Ref, false)
+	case PlanActionMerge:
+		_, resp, err := d.Provider().MergeBranch(step.Repo, step.Base, step.Head, step.Message)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			Warningf("nothing to merge, %q is already merged into %q", step.Head, step.Base)
+		}
+		return nil
+	case PlanActionCreateRelease:
+		// Steps with a title were recorded by GitHubCreateRelease and hold all fields of the release.
+		if len(step.Title) != 0 {
+			_, err := GitHubCreateRelease(d, step.Repo, step.release(), false)
+			return err
+		}
+		_, err := d.Provider().GetCreateRelease(step.Repo, step.Tag, step.Body, false)
+		return err
+	case PlanActionUploadAsset:
+		release, err := d.Provider().GetCreateRelease(step.Repo, step.Tag, "", false)
+		if err != nil {
+			return err
+		}
+		_, err = d.Provider().UploadReleaseAssets(step.Repo, release, assetMap{step.Asset: step.Path}, false)
+		return err
+	case PlanActionCreateComment:
+		_, err := GitHubCreateIssueComment(d, step.Issue, step.Body, false)
+		return err
+	case PlanActionProtectBranch:
+		return GitHubProtectBranch(d, step.Repo, step.Branch, false)
+	case PlanActionUpdateFile:
+		return GitHubUpdateFile(d, step.Repo, step.Branch, step.Path, step.Message, step.Body, step.SHA, false)
+	case PlanActionCreateMilestone:
+		_, err := GitHubCreateMilestone(d, step.Repo, step.milestone(), false)
+		return err
+	case PlanActionEditMilestone:
+		return GitHubEditMilestone(d, step.Repo, step.Number, step.milestone(), false)
+	case PlanActionCreateLabel:
+		return GitHubCreateLabel(d, step.Repo, step.label(), false)
+	case PlanActionEditLabel:
+		return GitHubEditLabel(d, step.Repo, step.Ref, step.label(), false)
+	case PlanActionDeleteLabel:
+		return GitHubDeleteLabel(d, step.Repo, step.Name, false)
+	case PlanActionCreateIssue:
+		_, err := GitHubCreateIssue(d, step.Repo, step.Title, step.Body, step.Labels, false)
+		return err
+	case PlanActionEditIssue:
+		return GitHubEditIssue(d, step.Repo, step.Number, step.Title, step.State, false)
+	case PlanActionRerunWorkflow:
+		return GitHubRerunWorkflowRun(d, step.Repo, step.ID, false)
+	case PlanActionRerequestCheckSuite:
+		return GitHubRerequestCheckSuite(d, step.Repo, step.ID, false)
+	case PlanActionCherryPick:
+		_, err := GitHubCherryPick(d, step.Repo, step.Base, step.Head, step.SHAs, false)
+		return err
+	case PlanActionCreatePullRequest:
+		_, err := GitHubCreatePullRequest(d, step.Repo, step.Base, step.Head, step.Message, step.Body, step.Labels, false)
+		return err
+	}
+	return errors.Errorf("unknown action %q", step.Action)
+}
+
+// RunApplyPlan validates the options for applying a plan, creates a client
+// and applies the plan from d.ApplyPlanFile. It exits on errors.
+func RunApplyPlan(d *Data, tool string) {
+	if err := ValidatePlanOptions(d); err != nil {
+		PrintErrorAndExit(err)
+	}
+	if err := ValidateEmptyOption(FlagToken, d.Token); err != nil {
+		PrintErrorAndExit(err)
+	}
+
+	stop := HandleSignals(d)
+	defer stop()
+
+	NewClient(d, nil)
+	if err := ApplyPlanFromFile(d, tool); err != nil {
+		if d.Interrupted() {
+			ExitInterrupted(err)
+		}
+		PrintErrorAndExit(err)
+	}
+	Logf("done!")
+}
+
+// planRepos returns the repositories that the plan steps push to.
+// Comments do not require push access and are skipped.
+func planRepos(steps []PlanStep) []string {
+	repos := []string{}
+	seen := map[string]bool{}
+	for _, step := range steps {
+		if step.Action == PlanActionCreateComment || seen[step.Repo] {
+			continue
+		}
+		seen[step.Repo] = true
+		repos = append(repos, step.Repo)
+	}
+	return repos
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestPlan(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plan.json")
+
+	newBranches := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/heads/release-1.17")},
+		&github.Reference{Ref: github.String("refs/heads/release-1.18")},
+	}
+
+	// Record the plan in dry-run mode.
+	d := &Data{DryRun: true}
+	var branchesDest []*github.Reference
+	if err := CreateNewBranches(d, "org/dest", &branchesDest, newBranches, "1234567890"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedSteps := []PlanStep{
+		{Action: PlanActionCreateRef, Repo: "org/dest", Ref: "refs/heads/release-1.17", SHA: "1234567890"},
+		{Action: PlanActionCreateRef, Repo: "org/dest", Ref: "refs/heads/release-1.18", SHA: "1234567890"},
+	}
+	if steps := d.GetPlanSteps(); !reflect.DeepEqual(steps, expectedSteps) {
+		t.Fatalf("expected steps:\n%+v\ngot:\n%+v", expectedSteps, steps)
+	}
+
+	// Write and read the plan.
+	if err := WritePlanToFile(path, "k8s-test-tool", d.GetPlanSteps()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ReadPlanFromFile(path, "k8s-other-tool"); err == nil {
+		t.Fatal("expected an error for a plan of another tool")
+	}
+	plan, err := ReadPlanFromFile(path, "k8s-test-tool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(plan.Steps, expectedSteps) {
+		t.Fatalf("expected steps:\n%+v\ngot:\n%+v", expectedSteps, plan.Steps)
+	}
+
+	// Apply the plan.
+	refs := []*github.Reference{}
+	d = &Data{}
+	NewClient(d, NewTransport())
+	d.Transport.SetHandler("https://api.github.com/repos/org/dest/git/refs", NewReferenceHandler(&refs, nil))
+	if err := ApplyPlan(d, plan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRefs := []*github.Reference{
+		&github.Reference{Ref: github.String("refs/heads/release-1.17"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+		&github.Reference{Ref: github.String("refs/heads/release-1.18"), Object: &github.GitObject{SHA: github.String("1234567890")}},
+	}
+	if !reflect.DeepEqual(refs, expectedRefs) {
+		t.Errorf("expected refs:\n%v\ngot:\n%v", expectedRefs, refs)
+	}
+}
+
+func TestValidatePlanOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          *Data
+		expectedError bool
+	}{
+		{
+			name: "valid: write a plan in dry-run mode",
+			data: &Data{PlanFile: "plan.json", DryRun: true},
+		},
+		{
+			name: "valid: apply a plan",
+			data: &Data{ApplyPlanFile: "plan.json"},
+		},
+		{
+			name:          "invalid: write a plan without dry-run mode",
+			data:          &Data{PlanFile: "plan.json"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: apply a plan in dry-run mode",
+			data:          &Data{ApplyPlanFile: "plan.json", DryRun: true},
+			expectedError: true,
+		},
+		{
+			name:          "invalid: write and apply a plan",
+			data:          &Data{PlanFile: "plan.json", ApplyPlanFile: "plan.json", DryRun: true},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePlanOptions(tt.data)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	// headerOAuthScopes is the response header in which GitHub lists the OAuth scopes of a classic token.
+	headerOAuthScopes = "X-OAuth-Scopes"
+
+	scopeRepo       = "repo"
+	scopePublicRepo = "public_repo"
+)
+
+// GitHubPreflightToken verifies that the token can be used for writing to the given repositories
+// before any writes are performed. It fails if the token cannot authenticate, if a classic token
+// is missing the OAuth scopes for writing to a repository or if the token user does not have
+// push access to a repository.
+func GitHubPreflightToken(d *Data, repos ...string) error {
+	Logf("verifying the permissions of the token")
+
+	// Installation tokens of GitHub Apps cannot access the user endpoint. In that case only
+	// the repository permissions are checked.
+	var user *github.User
+	var resp *github.Response
+	err := retry(d, "getting the authenticated user", func(ctx context.Context) (*github.Response, error) {
+		var err error
+		user, resp, err = d.client.Users().Get(ctx, "")
+		return resp, err
+	})
+	var scopes []string
+	var hasScopes bool
+	switch {
+	case err == nil:
+		Logf("the token belongs to user %q", user.GetLogin())
+		scopes, hasScopes = parseOAuthScopes(resp.Header)
+	case resp != nil && resp.StatusCode == http.StatusForbidden:
+		V(1).Logf("could not get the authenticated user; assuming an installation token")
+	default:
+		return errors.Wrap(err, "could not authenticate with the token")
+	}
+
+	// Fine-grained tokens and installation tokens do not have OAuth scopes.
+	if hasScopes && !hasScope(scopes, scopeRepo) && !hasScope(scopes, scopePublicRepo) {
+		return NewErrorf(ErrorKindUnauthorized, "the token is missing the OAuth scope %q or %q; found scopes: %q",
+			scopeRepo, scopePublicRepo, strings.Join(scopes, ", "))
+	}
+
+	for _, repo := range repos {
+		r, err := GitHubGetRepository(d, repo)
+		if err != nil {
+			return errors.Wrapf(err, "could not get repository %q with the token", repo)
+		}
+		if hasScopes && r.GetPrivate() && !hasScope(scopes, scopeRepo) {
+			return NewErrorf(ErrorKindUnauthorized, "the token is missing the OAuth scope %q, which is required for the private repository %q",
+				scopeRepo, repo)
+		}
+		if !r.GetPermissions()["push"] {
+			return NewErrorf(ErrorKindUnauthorized, "the token does not have push access to repository %q", repo)
+		}
+		V(1).Logf("the token has push access to repository %q", repo)
+	}
+	return nil
+}
+
+// parseOAuthScopes returns the OAuth scopes from a response header and
+// if the header was present.
+func parseOAuthScopes(header http.Header) ([]string, bool) {
+	if _, ok := header[http.CanonicalHeaderKey(headerOAuthScopes)]; !ok {
+		return nil, false
+	}
+	scopes := []string{}
+	for _, s := range strings.Split(header.Get(headerOAuthScopes), ",") {
+		if s = strings.TrimSpace(s); len(s) != 0 {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, true
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
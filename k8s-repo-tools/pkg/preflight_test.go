@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func newPreflightHandler(status int, header http.Header, body interface{}) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     header,
+			Request:    req,
+		}, nil
+	}
+}
+
+func TestGitHubPreflightToken(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	scopes := func(s string) http.Header {
+		return http.Header{http.CanonicalHeaderKey(headerOAuthScopes): []string{s}}
+	}
+
+	tests := []struct {
+		name          string
+		userStatus    int
+		userHeader    http.Header
+		repoStatus    int
+		repo          *github.Repository
+		expectedError ErrorKind
+	}{
+		{
+			name:       "valid: classic token with the repo scope and push access",
+			userStatus: http.StatusOK,
+			userHeader: scopes("repo, read:org"),
+			repoStatus: http.StatusOK,
+			repo: &github.Repository{
+				Private:     github.Bool(true),
+				Permissions: &map[string]bool{"push": true},
+			},
+		},
+		{
+			name:       "valid: classic token with the public_repo scope for a public repository",
+			userStatus: http.StatusOK,
+			userHeader: scopes("public_repo"),
+			repoStatus: http.StatusOK,
+			repo:       &github.Repository{Permissions: &map[string]bool{"push": true}},
+		},
+		{
+			name:       "valid: fine-grained token without scopes",
+			userStatus: http.StatusOK,
+			repoStatus: http.StatusOK,
+			repo:       &github.Repository{Permissions: &map[string]bool{"push": true}},
+		},
+		{
+			name:       "valid: installation token that cannot access the user",
+			userStatus: http.StatusForbidden,
+			repoStatus: http.StatusOK,
+			repo:       &github.Repository{Permissions: &map[string]bool{"push": true}},
+		},
+		{
+			name:          "invalid: bad credentials",
+			userStatus:    http.StatusUnauthorized,
+			expectedError: ErrorKindUnauthorized,
+		},
+		{
+			name:          "invalid: classic token without scopes",
+			userStatus:    http.StatusOK,
+			userHeader:    scopes(""),
+			expectedError: ErrorKindUnauthorized,
+		},
+		{
+			name:       "invalid: classic token with the public_repo scope for a private repository",
+			userStatus: http.StatusOK,
+			userHeader: scopes("public_repo"),
+			repoStatus: http.StatusOK,
+			repo: &github.Repository{
+				Private:     github.Bool(true),
+				Permissions: &map[string]bool{"push": true},
+			},
+			expectedError: ErrorKindUnauthorized,
+		},
+		{
+			name:          "invalid: no push access",
+			userStatus:    http.StatusOK,
+			userHeader:    scopes("repo"),
+			repoStatus:    http.StatusOK,
+			repo:          &github.Repository{Permissions: &map[string]bool{"pull": true}},
+			expectedError: ErrorKindUnauthorized,
+		},
+		{
+			name:          "invalid: repository not found",
+			userStatus:    http.StatusOK,
+			userHeader:    scopes("repo"),
+			repoStatus:    http.StatusNotFound,
+			repo:          &github.Repository{},
+			expectedError: ErrorKindNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/user",
+				newPreflightHandler(tt.userStatus, tt.userHeader, &github.User{Login: github.String("user")}))
+			d.Transport.SetHandler("https://api.github.com/repos/org/dest",
+				newPreflightHandler(tt.repoStatus, nil, tt.repo))
+
+			err := GitHubPreflightToken(d, "org/dest")
+			if kind := ErrorKindOf(err); kind != tt.expectedError {
+				t.Fatalf("expected error kind %q, got %q, error: %v", tt.expectedError, kind, err)
+			}
+		})
+	}
+}
+
+func TestPlanRepos(t *testing.T) {
+	steps := []PlanStep{
+		{Action: PlanActionCreateRef, Repo: "org/a"},
+		{Action: PlanActionCreateComment, Repo: "org/b"},
+		{Action: PlanActionCreateRef, Repo: "org/a"},
+		{Action: PlanActionCreateRelease, Repo: "org/c"},
+	}
+	repos := planRepos(steps)
+	if len(repos) != 2 || repos[0] != "org/a" || repos[1] != "org/c" {
+		t.Errorf("expected repos [org/a org/c], got %v", repos)
+	}
+}
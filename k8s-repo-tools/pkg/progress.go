@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress messages.
+var progressInterval = 10 * time.Second
+
+// AssetUpload holds the size of an uploaded asset and the time it took
+// to upload it.
+type AssetUpload struct {
+	Name     string
+	Size     int64
+	Duration time.Duration
+}
+
+// progressReader is an io.Reader that logs the progress of reading size bytes
+// from r, such as the percentage, the throughput and the remaining time.
+type progressReader struct {
+	r     io.Reader
+	desc  string
+	size  int64
+	read  int64
+	start time.Time
+	last  time.Time
+	now   func() time.Time
+}
+
+// newProgressReader returns a progressReader for r. desc is prefixed to
+// every progress message.
+func newProgressReader(r io.Reader, desc string, size int64) *progressReader {
+	now := time.Now()
+	return &progressReader{r: r, desc: desc, size: size, start: now, last: now, now: time.Now}
+}
+
+// Read implements io.Reader.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if now := p.now(); now.Sub(p.last) >= progressInterval && p.read < p.size {
+		p.last = now
+		Logf("%s: %s", p.desc, p.progress(now))
+	}
+	return n, err
+}
+
+// progress formats the progress at the time now.
+func (p *progressReader) progress(now time.Time) string {
+	elapsed := now.Sub(p.start)
+	var percent float64
+	if p.size > 0 {
+		percent = float64(p.read) * 100 / float64(p.size)
+	}
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.read) / elapsed.Seconds()
+	}
+	eta := "unknown"
+	if rate > 0 {
+		eta = (time.Duration(float64(p.size-p.read)/rate) * time.Second).Round(time.Second).String()
+	}
+	return fmt.Sprintf("%.1f%% (%s of %s, %s/s, ETA %s)",
+		percent, formatBytes(p.read), formatBytes(p.size), formatBytes(int64(rate)), eta)
+}
+
+// formatBytes formats a number of bytes with a binary unit such as "12.3 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// recordAssetUpload adds an uploaded asset to the list of uploads of d.
+func (d *Data) recordAssetUpload(upload AssetUpload) {
+	recordMutex.Lock()
+	defer recordMutex.Unlock()
+	d.assetUploads = append(d.assetUploads, upload)
+}
+
+// GetAssetUploads returns the assets that were uploaded.
+func (d *Data) GetAssetUploads() []AssetUpload {
+	recordMutex.Lock()
+	defer recordMutex.Unlock()
+	return append([]AssetUpload{}, d.assetUploads...)
+}
+
+// LogAssetUploads prints the size, the upload time and the throughput
+// of every uploaded asset.
+func LogAssetUploads(uploads []AssetUpload) {
+	if len(uploads) == 0 {
+		return
+	}
+	Logf("uploaded %d assets:", len(uploads))
+	for _, u := range uploads {
+		var rate int64
+		if u.Duration > 0 {
+			rate = int64(float64(u.Size) / u.Duration.Seconds())
+		}
+		Logf("  %s: %s in %s (%s/s)", u.Name, formatBytes(u.Size), u.Duration.Round(time.Millisecond), formatBytes(rate))
+	}
+}
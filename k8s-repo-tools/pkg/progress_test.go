@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{bytes: 0, expected: "0 B"},
+		{bytes: 1023, expected: "1023 B"},
+		{bytes: 1024, expected: "1.0 KiB"},
+		{bytes: 1536, expected: "1.5 KiB"},
+		{bytes: 300 * 1024 * 1024, expected: "300.0 MiB"},
+		{bytes: 5 * 1024 * 1024 * 1024, expected: "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := formatBytes(tt.bytes); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	tests := []struct {
+		name             string
+		size             int
+		chunk            int
+		step             time.Duration
+		expectedMessages []string
+	}{
+		{
+			name:  "valid: progress of a slow read is logged",
+			size:  4096,
+			chunk: 1024,
+			step:  progressInterval,
+			expectedMessages: []string{
+				"upload: 25.0% (1.0 KiB of 4.0 KiB, 102 B/s, ETA 30s)",
+				"upload: 50.0% (2.0 KiB of 4.0 KiB, 102 B/s, ETA 20s)",
+				"upload: 75.0% (3.0 KiB of 4.0 KiB, 102 B/s, ETA 10s)",
+			},
+		},
+		{
+			name:  "valid: progress of a fast read is not logged",
+			size:  4096,
+			chunk: 1024,
+			step:  time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			SetLogWriters(&out, ioutil.Discard)
+			defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+			p := newProgressReader(bytes.NewReader(make([]byte, tt.size)), "upload", int64(tt.size))
+			now := p.start
+			p.now = func() time.Time {
+				now = now.Add(tt.step)
+				return now
+			}
+			buf := make([]byte, tt.chunk)
+			for {
+				if _, err := p.Read(buf); err != nil {
+					break
+				}
+			}
+
+			var messages []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if i := strings.Index(line, "upload: "); i != -1 {
+					messages = append(messages, line[i:])
+				}
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.expectedMessages, "\n") {
+				t.Errorf("expected messages:\n%s\ngot:\n%s", strings.Join(tt.expectedMessages, "\n"), strings.Join(messages, "\n"))
+			}
+			if p.read != int64(tt.size) {
+				t.Errorf("expected %d bytes to be read, got %d", tt.size, p.read)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prompter asks the user to confirm an action before it is executed.
+type Prompter interface {
+	// Confirm shows message and returns true if the action is confirmed.
+	Confirm(message string) (bool, error)
+}
+
+var (
+	// PrompterYes is a Prompter that confirms all actions without asking.
+	PrompterYes Prompter = autoPrompter(true)
+	// PrompterNo is a Prompter that declines all actions without asking.
+	PrompterNo Prompter = autoPrompter(false)
+)
+
+// autoPrompter is a Prompter that always returns the same answer.
+type autoPrompter bool
+
+func (p autoPrompter) Confirm(message string) (bool, error) {
+	Logf("%s [y/n]: answering %q automatically", message, map[bool]string{true: "y", false: "n"}[bool(p)])
+	return bool(p), nil
+}
+
+// forceValue is a type that implements the flag.Value interface for the
+// --force and --yes flags. Setting it to true sets d.Force and replaces the
+// Prompter of d with PrompterYes, so that all confirmation prompts are skipped.
+type forceValue struct {
+	d *Data
+}
+
+func (f forceValue) String() string {
+	if f.d == nil {
+		return "false"
+	}
+	return strconv.FormatBool(f.d.Force)
+}
+
+func (f forceValue) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	f.d.Force = v
+	if v {
+		f.d.SetPrompter(PrompterYes)
+	} else if f.d.prompter == PrompterYes {
+		f.d.SetPrompter(nil)
+	}
+	return nil
+}
+
+func (f forceValue) Get() interface{} {
+	return f.d.Force
+}
+
+// IsBoolFlag allows passing the flag without a value.
+func (f forceValue) IsBoolFlag() bool {
+	return true
+}
+
+// interactivePrompter is a Prompter that reads the answer from in.
+// A nil in or out means the reader and writer set with SetPromptIO.
+type interactivePrompter struct {
+	in  io.Reader
+	out io.Writer
+}
+
+// NewInteractivePrompter returns a Prompter that writes the message to out
+// and reads the answer from in. It returns an error if in is a file that is
+// not a terminal, such as stdin in CI, instead of waiting for an answer.
+func NewInteractivePrompter(in io.Reader, out io.Writer) Prompter {
+	return &interactivePrompter{in: in, out: out}
+}
+
+func (p *interactivePrompter) Confirm(message string) (bool, error) {
+	in, out := p.in, p.out
+	if in == nil {
+		in = promptInput
+	}
+	if out == nil {
+		out = promptOutput
+	}
+	if !isInteractive(in) {
+		return false, errors.Errorf("cannot show the confirmation prompt %q, because stdin is not a terminal. "+
+			"Pass --%s to skip the prompt", message, FlagYes)
+	}
+	reader := bufio.NewReader(in)
+	fmt.Fprintf(out, message+" [y/n]: ")
+	resp, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || len(resp) == 0) {
+		return false, errors.Wrap(err, "could not read the answer to the confirmation prompt")
+	}
+	resp = strings.ToLower(strings.TrimSpace(resp))
+	if resp == "y" || resp == "yes" {
+		return true, nil
+	}
+	return false, nil
+}
+
+// Prompter returns the Prompter that asks for the confirmation of actions.
+// It is the one set with SetPrompter or an interactive Prompter that uses
+// the reader and writer set with SetPromptIO.
+func (d *Data) Prompter() Prompter {
+	if d.prompter == nil {
+		return &interactivePrompter{}
+	}
+	return d.prompter
+}
+
+// SetPrompter replaces the Prompter, for example with PrompterNo in tests.
+// Passing --force or --yes sets it to PrompterYes.
+func (d *Data) SetPrompter(p Prompter) {
+	d.prompter = p
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrompter(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer SetPromptIO(os.Stdin, os.Stdout)
+
+	var out bytes.Buffer
+	tests := []struct {
+		name           string
+		prompter       func() Prompter
+		expectedResult bool
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name:           "valid: yes",
+			prompter:       func() Prompter { return PrompterYes },
+			expectedResult: true,
+		},
+		{
+			name:     "valid: no",
+			prompter: func() Prompter { return PrompterNo },
+		},
+		{
+			name: "valid: interactive with the given reader and writer",
+			prompter: func() Prompter {
+				return NewInteractivePrompter(strings.NewReader("y\n"), &out)
+			},
+			expectedResult: true,
+			expectedOutput: "continue? [y/n]: ",
+		},
+		{
+			name: "valid: default of Data uses the prompt IO",
+			prompter: func() Prompter {
+				SetPromptIO(strings.NewReader("no\n"), &out)
+				return (&Data{}).Prompter()
+			},
+			expectedOutput: "continue? [y/n]: ",
+		},
+		{
+			name: "valid: set on Data",
+			prompter: func() Prompter {
+				d := &Data{}
+				d.SetPrompter(PrompterYes)
+				return d.Prompter()
+			},
+			expectedResult: true,
+		},
+		{
+			name:           "valid: --force sets PrompterYes",
+			prompter:       func() Prompter { return parseForce(t, "--force") },
+			expectedResult: true,
+		},
+		{
+			name:           "valid: --yes sets PrompterYes",
+			prompter:       func() Prompter { return parseForce(t, "--yes") },
+			expectedResult: true,
+		},
+		{
+			name: "valid: --yes=false keeps the interactive Prompter",
+			prompter: func() Prompter {
+				SetPromptIO(strings.NewReader("y\n"), &out)
+				return parseForce(t, "--yes", "--yes=false")
+			},
+			expectedResult: true,
+			expectedOutput: "continue? [y/n]: ",
+		},
+		{
+			name: "invalid: interactive without input",
+			prompter: func() Prompter {
+				return NewInteractivePrompter(strings.NewReader(""), &out)
+			},
+			expectedOutput: "continue? [y/n]: ",
+			expectedError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			result, err := tt.prompter().Confirm("continue?")
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if result != tt.expectedResult {
+				t.Errorf("expected result: %v, got: %v", tt.expectedResult, result)
+			}
+			if out.String() != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, out.String())
+			}
+		})
+	}
+}
+
+// parseForce parses args with the flags of ForceOptions and returns the Prompter of the Data.
+func parseForce(t *testing.T, args ...string) Prompter {
+	d := &Data{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	SetupOptions(d, fs, &ForceOptions{})
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("could not parse %v: %v", args, err)
+	}
+	return d.Prompter()
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// Provider is a service that hosts git repositories, such as GitHub. The tools
+// read and write repositories through the Provider of Data, so that other
+// services can be supported without changing the tools. All providers use the
+// types of the GitHub API for refs, comparisons, commits and releases.
+type Provider interface {
+	// Name returns the name of the service, such as "GitHub".
+	Name() string
+	// RefURL returns the URL of a tag or branch such as "refs/tags/v1.0.0"
+	// in the web interface of the service.
+	RefURL(repo, ref string) string
+	// CommitURL returns the URL of a commit in the web interface of the service.
+	CommitURL(repo, sha string) string
+	// CompareURL returns the URL that compares the commit base of repo with
+	// the commit head of headRepo in the web interface of the service.
+	CompareURL(repo, base, headRepo, head string) string
+	// PreflightToken verifies that the token can be used for writing to the repositories.
+	PreflightToken(repos ...string) error
+
+	// GetTags obtains the tags of a repository.
+	GetTags(repo string) ([]*github.Reference, error)
+	// GetBranches obtains the branches of a repository.
+	GetBranches(repo string) ([]*github.Reference, error)
+	// GetTagsAndBranches obtains the tags and branches of a repository.
+	GetTagsAndBranches(repo string) ([]*github.Reference, []*github.Reference, error)
+	// GetRef obtains a ref of a repository.
+	GetRef(repo, ref string) (*github.Reference, error)
+	// CreateRef creates a ref from a commit. A ref that already exists at the
+	// same commit is not an error.
+	CreateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error)
+	// CreateRefs creates refs and appends the created refs to refsDest. If the
+	// process is interrupted ErrInterrupted is returned.
+	CreateRefs(repo string, refsDest *[]*github.Reference, refs []*github.Reference) error
+	// UpdateRef forces an existing ref to point to a commit.
+	UpdateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error)
+	// DeleteRef deletes a ref.
+	DeleteRef(repo, ref string, dryRun bool) error
+
+	// CompareBranches compares two branches or commits.
+	CompareBranches(repo, base, head string) (*github.CommitsComparison, error)
+	// MergeBranch merges head into base. The status of the response is
+	// http.StatusCreated for a merge commit and http.StatusNoContent if
+	// there was nothing to merge.
+	MergeBranch(repo, base, head, commitMessage string) (*github.RepositoryCommit, *github.Response, error)
+
+	// GetRelease obtains the release for a tag. A missing release is a NotFound error.
+	GetRelease(repo, tag string) (*github.RepositoryRelease, error)
+	// GetCreateRelease obtains the release for an existing tag and creates it if it is missing.
+	GetCreateRelease(repo, tag, body string, dryRun bool) (*github.RepositoryRelease, error)
+	// UploadReleaseAssets uploads files as assets of a release.
+	UploadReleaseAssets(repo string, release *github.RepositoryRelease, am assetMap, dryRun bool) ([]*github.ReleaseAsset, error)
+}
+
+// Provider returns the Provider of the destination repository. It is the one
+// set with SetProvider, Gitea if d.GiteaURL is set and GitHub otherwise.
+func (d *Data) Provider() Provider {
+	switch {
+	case d.provider != nil:
+		return d.provider
+	case len(d.GiteaURL) != 0:
+		return &giteaProvider{d: d}
+	}
+	return &gitHubProvider{d: d}
+}
+
+// SourceProvider returns the Provider of the source repository, which is
+// always on GitHub.
+func (d *Data) SourceProvider() Provider {
+	return &gitHubProvider{d: d}
+}
+
+// SetProvider sets the Provider of the destination repository.
+func (d *Data) SetProvider(p Provider) {
+	d.provider = p
+}
+
+// gitHubProvider is the Provider for GitHub and GitHub Enterprise Server.
+type gitHubProvider struct {
+	d *Data
+}
+
+var _ Provider = &gitHubProvider{}
+
+func (p *gitHubProvider) Name() string {
+	return "GitHub"
+}
+
+// webURL returns the URL of a repository in the web interface, which is
+// the base URL without the API path on GitHub Enterprise Server.
+func (p *gitHubProvider) webURL(repo string) string {
+	base := "https://github.com"
+	if len(p.d.GitHubBaseURL) != 0 {
+		base = strings.TrimSuffix(strings.TrimSuffix(p.d.GitHubBaseURL, "/"), "/api/v3")
+	}
+	return base + "/" + repo
+}
+
+func (p *gitHubProvider) RefURL(repo, ref string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/tags/"), "refs/heads/")
+	return p.webURL(repo) + "/tree/" + name
+}
+
+func (p *gitHubProvider) CommitURL(repo, sha string) string {
+	return p.webURL(repo) + "/commit/" + sha
+}
+
+func (p *gitHubProvider) CompareURL(repo, base, headRepo, head string) string {
+	// Commits of another repository in the same network are written as
+	// "owner:repo:sha".
+	if headRepo != repo {
+		head = strings.Replace(headRepo, "/", ":", 1) + ":" + head
+	}
+	return p.webURL(repo) + "/compare/" + base + "..." + head
+}
+
+func (p *gitHubProvider) PreflightToken(repos ...string) error {
+	return GitHubPreflightToken(p.d, repos...)
+}
+
+func (p *gitHubProvider) GetTags(repo string) ([]*github.Reference, error) {
+	return GitHubGetTags(p.d, repo)
+}
+
+func (p *gitHubProvider) GetBranches(repo string) ([]*github.Reference, error) {
+	return GitHubGetBranches(p.d, repo)
+}
+
+func (p *gitHubProvider) GetTagsAndBranches(repo string) ([]*github.Reference, []*github.Reference, error) {
+	return GitHubGetTagsAndBranches(p.d, repo)
+}
+
+func (p *gitHubProvider) GetRef(repo, ref string) (*github.Reference, error) {
+	return GitHubGetRef(p.d, repo, ref)
+}
+
+func (p *gitHubProvider) CreateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	return GitHubCreateRef(p.d, repo, ref, sha, dryRun)
+}
+
+// CreateRefs creates the refs with batched GraphQL mutations if d.GraphQL
+// is set and with one REST request per ref otherwise.
+func (p *gitHubProvider) CreateRefs(repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	if p.d.GraphQL {
+		return gitHubCreateRefsGraphQL(p.d, repo, refsDest, refs)
+	}
+	return createRefs(p, p.d, repo, refsDest, refs)
+}
+
+func (p *gitHubProvider) UpdateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	return GitHubUpdateRef(p.d, repo, ref, sha, dryRun)
+}
+
+func (p *gitHubProvider) DeleteRef(repo, ref string, dryRun bool) error {
+	return GitHubDeleteRef(p.d, repo, ref, dryRun)
+}
+
+func (p *gitHubProvider) CompareBranches(repo, base, head string) (*github.CommitsComparison, error) {
+	return GitHubCompareBranches(p.d, repo, base, head)
+}
+
+func (p *gitHubProvider) MergeBranch(repo, base, head, commitMessage string) (*github.RepositoryCommit, *github.Response, error) {
+	return GitHubMergeBranch(p.d, repo, base, head, commitMessage)
+}
+
+func (p *gitHubProvider) GetRelease(repo, tag string) (*github.RepositoryRelease, error) {
+	return GitHubGetRelease(p.d, repo, tag)
+}
+
+func (p *gitHubProvider) GetCreateRelease(repo, tag, body string, dryRun bool) (*github.RepositoryRelease, error) {
+	return GitHubGetCreateRelease(p.d, repo, tag, body, dryRun)
+}
+
+func (p *gitHubProvider) UploadReleaseAssets(repo string, release *github.RepositoryRelease, am assetMap, dryRun bool) ([]*github.ReleaseAsset, error) {
+	return GitHubUploadReleaseAssets(p.d, repo, release, am, dryRun)
+}
+
+// createRefs creates refs one by one with the CreateRef method of a Provider
+// and appends the created refs to refsDest. If the process is interrupted
+// ErrInterrupted is returned before creating the next ref.
+func createRefs(p Provider, d *Data, repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	for _, r := range refs {
+		if d.Interrupted() {
+			return ErrInterrupted
+		}
+		ref, err := p.CreateRef(repo, r.GetRef(), r.GetObject().GetSHA(), false)
+		if err != nil {
+			return err
+		}
+		*refsDest = append(*refsDest, ref)
+	}
+	return nil
+}
+
+// CreateNewBranches goes trough a list of branches and creates them
+// based on the HEAD of the master branch. Created branches are appended to
+// branchesDest. If the process is interrupted ErrInterrupted is returned
+// before creating the next branch.
+func CreateNewBranches(
+	d *Data,
+	repo string,
+	branchesDest *[]*github.Reference,
+	newBranches []*github.Reference,
+	masterSHA string) error {
+
+	// Always create new branches from "master".
+	refs := make([]*github.Reference, len(newBranches))
+	for i, branch := range newBranches {
+		refs[i] = &github.Reference{Ref: branch.Ref, Object: &github.GitObject{SHA: github.String(masterSHA)}}
+	}
+	return createNewRefs(d, repo, branchesDest, refs)
+}
+
+// CreateNewTags goes trough a list of tags and creates
+// them for matching versioned branch from a list of branches.
+// If no matching branch is found the SHA of master is used.
+// Created tags are appended to tagsDest. If the process is interrupted
+// ErrInterrupted is returned before creating the next tag.
+func CreateNewTags(
+	d *Data,
+	repo string,
+	tagsDest *[]*github.Reference,
+	branches, newTags []*github.Reference,
+	masterSHA string) error {
+
+	refs := make([]*github.Reference, len(newTags))
+	for i, tag := range newTags {
+		sha := FindBranchHEADForTag(tag, d.PrefixBranch, masterSHA, branches)
+		refs[i] = &github.Reference{Ref: tag.Ref, Object: &github.GitObject{SHA: github.String(sha)}}
+	}
+	return createNewRefs(d, repo, tagsDest, refs)
+}
+
+// createNewRefs creates refs with the Provider of d. In dry-run mode the new
+// refs are just appended to the given list of destination refs.
+func createNewRefs(d *Data, repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	p := d.Provider()
+	if d.DryRun {
+		for _, r := range refs {
+			ref, _ := p.CreateRef(repo, r.GetRef(), r.GetObject().GetSHA(), true)
+			*refsDest = append(*refsDest, ref)
+		}
+		return nil
+	}
+	return p.CreateRefs(repo, refsDest, refs)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// fakeProvider is a Provider that records the created refs. Methods that
+// are not implemented panic.
+type fakeProvider struct {
+	Provider
+	d       *Data
+	created []string
+}
+
+func (p *fakeProvider) CreateRef(repo, ref, sha string, dryRun bool) (*github.Reference, error) {
+	if !dryRun {
+		p.created = append(p.created, fmt.Sprintf("%s %s %s", repo, ref, sha))
+	}
+	return newTestRef(ref, sha, "commit"), nil
+}
+
+func (p *fakeProvider) CreateRefs(repo string, refsDest *[]*github.Reference, refs []*github.Reference) error {
+	return createRefs(p, p.d, repo, refsDest, refs)
+}
+
+func TestCreateNewRefs(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	branches := []*github.Reference{
+		newTestRef("refs/heads/master", "sha0", "commit"),
+		newTestRef("refs/heads/release-1.17", "sha1", "commit"),
+	}
+	newBranches := []*github.Reference{newTestRef("refs/heads/release-1.18", "", "")}
+	newTags := []*github.Reference{
+		newTestRef("refs/tags/v1.17.1", "", ""),
+		newTestRef("refs/tags/v1.18.0", "", ""),
+	}
+
+	tests := []struct {
+		name            string
+		dryRun          bool
+		expectedCreated []string
+		expectedDest    string
+	}{
+		{
+			name: "valid: the refs are created with the provider",
+			expectedCreated: []string{
+				"org/dest refs/heads/release-1.18 sha0",
+				"org/dest refs/tags/v1.17.1 sha1",
+				"org/dest refs/tags/v1.18.0 sha0",
+			},
+			expectedDest: "refs/heads/release-1.18 sha0 commit\nrefs/tags/v1.17.1 sha1 commit\nrefs/tags/v1.18.0 sha0 commit\n",
+		},
+		{
+			name:         "valid: nothing is created in dry-run mode",
+			dryRun:       true,
+			expectedDest: "refs/heads/release-1.18 sha0 commit\nrefs/tags/v1.17.1 sha1 commit\nrefs/tags/v1.18.0 sha0 commit\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{DryRun: tt.dryRun, PrefixBranch: "release-"}
+			p := &fakeProvider{d: d}
+			d.SetProvider(p)
+
+			var dest []*github.Reference
+			if err := CreateNewBranches(d, "org/dest", &dest, newBranches, "sha0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := CreateNewTags(d, "org/dest", &dest, branches, newTags, "sha0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(p.created, tt.expectedCreated) {
+				t.Errorf("expected created refs %v, got %v", tt.expectedCreated, p.created)
+			}
+			if got := refsToString(dest); got != tt.expectedDest {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", tt.expectedDest, got)
+			}
+		})
+	}
+}
+
+func TestProviderWebURLs(t *testing.T) {
+	tests := []struct {
+		name               string
+		data               *Data
+		expectedRefURL     string
+		expectedCommitURL  string
+		expectedCompareURL string
+	}{
+		{
+			name:               "valid: GitHub",
+			data:               &Data{},
+			expectedRefURL:     "https://github.com/org/dest/tree/v1.0.0",
+			expectedCommitURL:  "https://github.com/org/dest/commit/sha1",
+			expectedCompareURL: "https://github.com/org/dest/compare/sha1...org:src:sha2",
+		},
+		{
+			name:               "valid: GitHub Enterprise Server",
+			data:               &Data{GitHubBaseURL: "https://ghe.example.com/api/v3/"},
+			expectedRefURL:     "https://ghe.example.com/org/dest/tree/v1.0.0",
+			expectedCommitURL:  "https://ghe.example.com/org/dest/commit/sha1",
+			expectedCompareURL: "https://ghe.example.com/org/dest/compare/sha1...org:src:sha2",
+		},
+		{
+			name:               "valid: Gitea",
+			data:               &Data{GiteaURL: "https://gitea.example.com/"},
+			expectedRefURL:     "https://gitea.example.com/org/dest/src/tag/v1.0.0",
+			expectedCommitURL:  "https://gitea.example.com/org/dest/commit/sha1",
+			expectedCompareURL: "https://gitea.example.com/org/dest/compare/sha1...sha2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.data.Provider()
+			if got := p.RefURL("org/dest", "refs/tags/v1.0.0"); got != tt.expectedRefURL {
+				t.Errorf("expected ref URL %q, got %q", tt.expectedRefURL, got)
+			}
+			if got := p.CommitURL("org/dest", "sha1"); got != tt.expectedCommitURL {
+				t.Errorf("expected commit URL %q, got %q", tt.expectedCommitURL, got)
+			}
+			if got := p.CompareURL("org/dest", "sha1", "org/src", "sha2"); got != tt.expectedCompareURL {
+				t.Errorf("expected compare URL %q, got %q", tt.expectedCompareURL, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// maxSearchResults is the maximum number of results that the GitHub search API returns for a query.
+const maxSearchResults = 1000
+
+// PullRequestQuery selects the merged pull requests of a branch.
+type PullRequestQuery struct {
+	// Branch is the branch that the pull requests target, such as "release-1.17".
+	Branch string
+	// Labels are labels that every pull request must have.
+	Labels []string
+	// Milestone is the title of a milestone that every pull request must be in.
+	Milestone string
+}
+
+// String returns the GitHub search query of q for the pull requests of repo that were
+// merged after since and not after until.
+func (q *PullRequestQuery) String(repo string, since, until time.Time) string {
+	parts := []string{
+		"repo:" + repo,
+		"is:pr",
+		"is:merged",
+		"base:" + q.Branch,
+		fmt.Sprintf("merged:%s..%s", since.Add(time.Second).UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339)),
+	}
+	for _, label := range q.Labels {
+		parts = append(parts, fmt.Sprintf("label:%q", label))
+	}
+	if len(q.Milestone) != 0 {
+		parts = append(parts, fmt.Sprintf("milestone:%q", q.Milestone))
+	}
+	return strings.Join(parts, " ")
+}
+
+// GitHubFindMergedPullRequests returns the pull requests matching q that were merged
+// in the range of commits between base and head, such as the commits of two tags.
+// The range is determined by the commit dates of base and the newest commit of head,
+// so that pull requests that were cherry-picked to a release branch are found even
+// if their merge commits do not refer to them.
+func GitHubFindMergedPullRequests(d *Data, repo, base, head string, q *PullRequestQuery) ([]*github.Issue, error) {
+	cmp, err := GitHubCompareBranches(d, repo, base, head)
+	if err != nil {
+		return nil, err
+	}
+	result := []*github.Issue{}
+	if len(cmp.Commits) == 0 {
+		Logf("no commits between %q and %q in repository %q", base, head, repo)
+		return result, nil
+	}
+	since := cmp.GetBaseCommit().GetCommit().GetCommitter().GetDate()
+	until := cmp.Commits[len(cmp.Commits)-1].GetCommit().GetCommitter().GetDate()
+	if !until.After(since) {
+		return result, nil
+	}
+
+	query := q.String(repo, since, until)
+	Logf("searching pull requests with the query %q", query)
+	opt := &github.SearchOptions{Sort: "created", Order: "asc", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		var res *github.IssuesSearchResult
+		var resp *github.Response
+		err := retry(d, fmt.Sprintf("searching pull requests in repository %q", repo), func(ctx context.Context) (*github.Response, error) {
+			var err error
+			res, resp, err = d.client.Search().Issues(ctx, query, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range res.Issues {
+			result = append(result, &res.Issues[i])
+		}
+		if res.GetIncompleteResults() || res.GetTotal() > maxSearchResults {
+			Warningf("the search for pull requests in repository %q returned incomplete results", repo)
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	Logf("found %d pull requests", len(result))
+	return result, nil
+}
+
+// FormatPullRequestReleaseNotes returns Markdown release notes with the title, number
+// and author of every pull request.
+func FormatPullRequestReleaseNotes(prs []*github.Issue) string {
+	if len(prs) == 0 {
+		return "No changes since the previous release.\n"
+	}
+	var b strings.Builder
+	b.WriteString("## Changes\n\n")
+	for _, pr := range prs {
+		fmt.Fprintf(&b, "- %s (#%d, @%s)\n", strings.TrimSpace(pr.GetTitle()), pr.GetNumber(), pr.GetUser().GetLogin())
+	}
+	return b.String()
+}
+
+// generatePullRequestReleaseNotes returns release notes generated from the pull
+// requests that were merged in branch between the commits startSHA and endSHA.
+func generatePullRequestReleaseNotes(d *Data, repo, branch, startSHA, endSHA string) (string, error) {
+	q := &PullRequestQuery{
+		Branch:    branch,
+		Labels:    d.PullRequestLabels,
+		Milestone: d.PullRequestMilestone,
+	}
+	prs, err := GitHubFindMergedPullRequests(d, repo, startSHA, endSHA, q)
+	if err != nil {
+		return "", err
+	}
+	return FormatPullRequestReleaseNotes(prs), nil
+}
+
+// ValidatePullRequestNotesOptions validates the options for generating release notes
+// from pull requests.
+func ValidatePullRequestNotesOptions(d *Data) error {
+	if !d.PullRequestNotes {
+		if len(d.PullRequestLabels) != 0 || len(d.PullRequestMilestone) != 0 {
+			return errors.Errorf("the options %q and %q require %q",
+				FlagReleaseNotesLabel, FlagReleaseNotesMilestone, FlagReleaseNotesFromPullRequests)
+		}
+		return nil
+	}
+	if len(d.ReleaseNotesToolPath) != 0 {
+		return errors.Errorf("the options %q and %q cannot be used together",
+			FlagReleaseNotesToolPath, FlagReleaseNotesFromPullRequests)
+	}
+	if len(d.GiteaURL) != 0 {
+		return errors.Errorf("the option %q is not supported with %q", FlagReleaseNotesFromPullRequests, FlagGiteaURL)
+	}
+	return nil
+}
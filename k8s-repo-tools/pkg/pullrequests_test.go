@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestGitHubFindMergedPullRequests(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	since := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(48 * time.Hour)
+	newCommit := func(sha string, date time.Time) github.RepositoryCommit {
+		return github.RepositoryCommit{
+			SHA:    github.String(sha),
+			Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &date}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		commits          []github.RepositoryCommit
+		query            *PullRequestQuery
+		prs              int
+		expectedQuery    string
+		expectedPRs      int
+		expectedRequests int
+	}{
+		{
+			name:    "valid: pull requests with labels and a milestone on multiple pages",
+			commits: []github.RepositoryCommit{newCommit("sha2", since.Add(time.Hour)), newCommit("sha3", until)},
+			query: &PullRequestQuery{
+				Branch:    "release-1.17",
+				Labels:    []string{"cherry-pick-approved", "kind/bug"},
+				Milestone: "v1.17",
+			},
+			prs: 150,
+			expectedQuery: `repo:org/repo is:pr is:merged base:release-1.17 merged:2020-01-01T00:00:01Z..2020-01-03T00:00:00Z ` +
+				`label:"cherry-pick-approved" label:"kind/bug" milestone:"v1.17"`,
+			expectedPRs:      150,
+			expectedRequests: 2,
+		},
+		{
+			name:        "valid: no commits between the tags",
+			query:       &PullRequestQuery{Branch: "release-1.17"},
+			prs:         10,
+			expectedPRs: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/compare/sha1...sha3", func(req *http.Request) (*http.Response, error) {
+				buf, err := json.Marshal(&github.CommitsComparison{
+					BaseCommit:   &github.RepositoryCommit{Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &since}}},
+					TotalCommits: github.Int(len(tt.commits)),
+					Commits:      tt.commits,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBuffer(buf))}, nil
+			})
+			var requests int
+			d.Transport.SetHandler("https://api.github.com/search/issues", func(req *http.Request) (*http.Response, error) {
+				requests++
+				if q := req.URL.Query().Get("q"); q != tt.expectedQuery {
+					t.Errorf("expected query %q, got %q", tt.expectedQuery, q)
+				}
+				page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+				if page == 0 {
+					page = 1
+				}
+				res := &github.IssuesSearchResult{Total: github.Int(tt.prs), IncompleteResults: github.Bool(false)}
+				for i := (page - 1) * 100; i < page*100 && i < tt.prs; i++ {
+					res.Issues = append(res.Issues, github.Issue{Number: github.Int(i + 1)})
+				}
+				header := http.Header{}
+				if page*100 < tt.prs {
+					header.Set("Link", fmt.Sprintf(`<https://api.github.com/search/issues?page=%d>; rel="next"`, page+1))
+				}
+				buf, err := json.Marshal(res)
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBuffer(buf)), Header: header}, nil
+			})
+
+			prs, err := GitHubFindMergedPullRequests(d, "org/repo", "sha1", "sha3", tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(prs) != tt.expectedPRs {
+				t.Errorf("expected %d pull requests, got %d", tt.expectedPRs, len(prs))
+			}
+			for i, pr := range prs {
+				if pr.GetNumber() != i+1 {
+					t.Fatalf("expected pull request %d to have number %d, got %d", i, i+1, pr.GetNumber())
+				}
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d search requests, got %d", tt.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestFormatPullRequestReleaseNotes(t *testing.T) {
+	tests := []struct {
+		name          string
+		prs           []*github.Issue
+		expectedNotes string
+	}{
+		{
+			name: "valid: pull requests",
+			prs: []*github.Issue{
+				{Number: github.Int(12), Title: github.String("Fix a bug "), User: &github.User{Login: github.String("foo")}},
+				{Number: github.Int(34), Title: github.String("Add a feature"), User: &github.User{Login: github.String("bar")}},
+			},
+			expectedNotes: "## Changes\n\n- Fix a bug (#12, @foo)\n- Add a feature (#34, @bar)\n",
+		},
+		{
+			name:          "valid: no pull requests",
+			expectedNotes: "No changes since the previous release.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if notes := FormatPullRequestReleaseNotes(tt.prs); notes != tt.expectedNotes {
+				t.Errorf("expected notes:\n%s\ngot:\n%s", tt.expectedNotes, notes)
+			}
+		})
+	}
+}
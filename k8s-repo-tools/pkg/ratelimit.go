@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+const (
+	// defaultSecondaryRateLimitWait is the wait time after a secondary rate limit
+	// error without a Retry-After header, as recommended by the GitHub documentation.
+	defaultSecondaryRateLimitWait = time.Minute
+)
+
+var (
+	// minWriteDelay and maxWriteDelay bound the delay between writes after
+	// a secondary rate limit error.
+	minWriteDelay = time.Second
+	maxWriteDelay = 30 * time.Second
+
+	// writeDelay is the delay between the starts of two writes. It is zero until
+	// a write fails because of a secondary rate limit and doubles after every such failure.
+	writeDelay      time.Duration
+	lastWrite       time.Time
+	writeDelayMutex sync.Mutex
+)
+
+// isSecondaryRateLimit returns true if a GitHub API call failed because of a
+// secondary rate limit, which GitHub applies to bursts of requests and in
+// particular to bursts of writes. Older GitHub versions call it abuse rate limit.
+func isSecondaryRateLimit(resp *github.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if stderrors.As(err, &abuseErr) {
+		return true
+	}
+	var respErr *github.ErrorResponse
+	if !stderrors.As(err, &respErr) || respErr.Response == nil || respErr.Response.StatusCode != http.StatusForbidden {
+		return false
+	}
+	msg := strings.ToLower(respErr.Message + " " + respErr.DocumentationURL)
+	return strings.Contains(msg, "secondary rate limit") ||
+		strings.Contains(msg, "secondary-rate-limits") ||
+		strings.Contains(msg, "abuse")
+}
+
+// secondaryRateLimitWait returns the wait time before retrying a call that failed
+// because of a secondary rate limit, as requested by the Retry-After header.
+func secondaryRateLimitWait(resp *github.Response, err error) time.Duration {
+	var abuseErr *github.AbuseRateLimitError
+	if stderrors.As(err, &abuseErr) && abuseErr.RetryAfter != nil {
+		return *abuseErr.RetryAfter
+	}
+	if resp != nil && resp.Response != nil {
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec >= 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return defaultSecondaryRateLimitWait
+}
+
+// slowDownWrites doubles the delay between writes within the bounds of
+// minWriteDelay and maxWriteDelay and returns the new delay.
+func slowDownWrites() time.Duration {
+	writeDelayMutex.Lock()
+	defer writeDelayMutex.Unlock()
+	writeDelay *= 2
+	if writeDelay < minWriteDelay {
+		writeDelay = minWriteDelay
+	}
+	if writeDelay > maxWriteDelay {
+		writeDelay = maxWriteDelay
+	}
+	return writeDelay
+}
+
+// waitForWrite waits until the delay between writes has passed since the
+// start of the last write. It returns false if the wait was stopped because
+// the process was interrupted.
+func (d *Data) waitForWrite() bool {
+	writeDelayMutex.Lock()
+	now := time.Now()
+	start := lastWrite.Add(writeDelay)
+	if start.Before(now) {
+		start = now
+	}
+	// Reserve the start time, so that concurrent writes are spaced out as well.
+	lastWrite = start
+	writeDelayMutex.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		V(1).Logf("waiting %v before the next write to stay below the secondary rate limit", wait)
+		s := startSpan("write delay", spanKindInternal)
+		defer s.finish(nil)
+		return d.sleep(wait)
+	}
+	return true
+}
+
+// resetWriteDelay removes the delay between writes.
+func resetWriteDelay() {
+	writeDelayMutex.Lock()
+	defer writeDelayMutex.Unlock()
+	writeDelay = 0
+	lastWrite = time.Time{}
+}
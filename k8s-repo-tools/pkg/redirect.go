@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxRedirects is the number of redirects that are followed for a single request.
+const maxRedirects = 3
+
+// redirectTransport is an http.RoundTripper that follows the redirects of the GitHub
+// API for repositories that were renamed or transferred to another owner. The API
+// answers such requests with 301 Moved Permanently and a location that refers to
+// the repository by its ID. Unlike http.Client, which sends POST requests again as
+// GET requests after a 301, the requests are sent again with the same method and
+// body, so that writes are not lost.
+type redirectTransport struct {
+	sync.Mutex
+
+	base http.RoundTripper
+	// strict fails requests to moved repositories with ErrRepositoryMoved.
+	strict bool
+	// moved maps the names of moved repositories to their new names.
+	moved map[string]string
+}
+
+var _ http.RoundTripper = &redirectTransport{}
+
+// newRedirectTransport creates a redirectTransport that sends requests using base.
+func newRedirectTransport(base http.RoundTripper, strict bool) *redirectTransport {
+	return &redirectTransport{
+		base:   base,
+		strict: strict,
+		moved:  map[string]string{},
+	}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	for i := 0; i < maxRedirects; i++ {
+		if err != nil || resp.StatusCode != http.StatusMovedPermanently {
+			return resp, err
+		}
+		location, locErr := resp.Location()
+		if locErr != nil {
+			return resp, nil
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := t.handleMoved(req, location); err != nil {
+			return nil, err
+		}
+
+		r := req.Clone(req.Context())
+		r.URL = location
+		r.Host = ""
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.Errorf("the repository for %s %q has moved to %q and the request cannot be sent again",
+					req.Method, req.URL, location)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		req = r
+		resp, err = t.base.RoundTrip(req)
+	}
+	if err == nil && resp.StatusCode == http.StatusMovedPermanently {
+		resp.Body.Close()
+		return nil, errors.Errorf("stopped after %d redirects for %s %q", maxRedirects, req.Method, req.URL)
+	}
+	return resp, err
+}
+
+// handleMoved resolves the new name of the repository of a request that was
+// answered with a redirect to location and warns once per repository. In strict
+// mode it returns an error instead.
+func (t *redirectTransport) handleMoved(req *http.Request, location *url.URL) error {
+	repo := repoFromPath(req.URL.Path)
+	if len(repo) == 0 {
+		return nil
+	}
+
+	t.Lock()
+	newRepo, found := t.moved[repo]
+	t.Unlock()
+	if !found {
+		newRepo = t.resolveMoved(req, location)
+		t.Lock()
+		t.moved[repo] = newRepo
+		t.Unlock()
+		if !t.strict {
+			Warningf("REPOSITORY MOVED: the repository %q has been renamed or transferred to %q; "+
+				"following the redirect, but the configuration should be updated to use the new name", repo, newRepo)
+		}
+	}
+	if t.strict {
+		return NewErrorf(ErrorKindRepositoryMoved, "the repository %q has been renamed or transferred to %q", repo, newRepo)
+	}
+	return nil
+}
+
+// resolveMoved returns the name of the repository that location refers to.
+// If the name cannot be resolved it returns the location.
+func (t *redirectTransport) resolveMoved(req *http.Request, location *url.URL) string {
+	if repo := repoFromPath(location.Path); len(repo) != 0 {
+		return repo
+	}
+
+	// A location such as https://api.github.com/repositories/1234/git/refs refers to
+	// the repository by its ID, which has to be looked up to find its name.
+	idx := strings.Index(location.Path, "/repositories/")
+	if idx == -1 {
+		return location.String()
+	}
+	id := strings.SplitN(location.Path[idx+len("/repositories/"):], "/", 2)[0]
+	repoURL := *location
+	repoURL.Path = location.Path[:idx] + "/repositories/" + id
+	repoURL.RawPath = ""
+	repoURL.RawQuery = ""
+
+	r, err := http.NewRequest(http.MethodGet, repoURL.String(), nil)
+	if err != nil {
+		return location.String()
+	}
+	r = r.WithContext(req.Context())
+	for _, h := range []string{"Accept", "Authorization", "User-Agent"} {
+		if v := req.Header.Get(h); len(v) != 0 {
+			r.Header.Set(h, v)
+		}
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		V(1).Warningf("could not resolve the moved repository %q: %v", repoURL.String(), err)
+		return location.String()
+	}
+	defer resp.Body.Close()
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&repo) != nil || len(repo.FullName) == 0 {
+		V(1).Warningf("could not resolve the moved repository %q: received status %d", repoURL.String(), resp.StatusCode)
+		return location.String()
+	}
+	return repo.FullName
+}
+
+// repoFromPath returns the "owner/name" of the repository in an API path
+// such as /repos/owner/name/git/refs, or an empty string.
+func repoFromPath(path string) string {
+	idx := strings.Index(path, "/repos/")
+	if idx == -1 {
+		return ""
+	}
+	parts := strings.SplitN(path[idx+len("/repos/"):], "/", 3)
+	if len(parts) < 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
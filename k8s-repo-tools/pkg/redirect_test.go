@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	stderrors "errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestRedirectTransport(t *testing.T) {
+	const (
+		oldRepoURL = "https://api.github.com/repos/org/old"
+		newRepoURL = "https://api.github.com/repositories/1"
+	)
+
+	tests := []struct {
+		name             string
+		strict           bool
+		movedTo          string
+		expectedRefs     string
+		expectedWarnings int
+		expectedError    bool
+	}{
+		{
+			name:             "valid: follow the redirect to a repository ID",
+			movedTo:          newRepoURL,
+			expectedRefs:     "refs/heads/main 1 commit\nrefs/tags/v1.0.0 1 commit\nrefs/tags/v1.1.0 2 \n",
+			expectedWarnings: 1,
+		},
+		{
+			name:             "valid: follow the redirect to a repository name",
+			movedTo:          "https://api.github.com/repos/org/new",
+			expectedRefs:     "refs/heads/main 1 commit\nrefs/tags/v1.0.0 1 commit\nrefs/tags/v1.1.0 2 \n",
+			expectedWarnings: 1,
+		},
+		{
+			name:          "invalid: moved repository in strict mode",
+			strict:        true,
+			movedTo:       newRepoURL,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			SetLogWriters(ioutil.Discard, &stderr)
+			defer SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+			refs := []*github.Reference{
+				newTestRef("refs/heads/main", "1", "commit"),
+				newTestRef("refs/tags/v1.0.0", "1", "commit"),
+			}
+			d := &Data{Strict: tt.strict, Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler(oldRepoURL, NewMovedRepositoryHandler(oldRepoURL, tt.movedTo))
+			d.Transport.SetHandler(newRepoURL, NewRepositoryHandler(&github.Repository{FullName: github.String("org/new")}, nil))
+			d.Transport.SetHandler(tt.movedTo+"/git/refs", NewReferenceHandler(&refs, nil))
+
+			// A write must be sent again with the same method and body.
+			_, err := GitHubCreateRef(d, "org/old", "refs/tags/v1.1.0", "2", false)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				if !stderrors.Is(err, ErrRepositoryMoved) {
+					t.Fatalf("expected a %s error, got: %v", ErrorKindRepositoryMoved, err)
+				}
+				return
+			}
+			tags, err := GitHubGetTags(d, "org/old")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			branches, err := GitHubGetBranches(d, "org/old")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := refsToString(append(branches, tags...)); got != tt.expectedRefs {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", tt.expectedRefs, got)
+			}
+			warnings := strings.Count(stderr.String(), "REPOSITORY MOVED")
+			if warnings != tt.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d:\n%s", tt.expectedWarnings, warnings, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), `"org/new"`) {
+				t.Errorf("expected the new repository name in the warning:\n%s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRepoFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/repos/org/repo/git/refs", expected: "org/repo"},
+		{path: "/api/v3/repos/org/repo", expected: "org/repo"},
+		{path: "/repositories/1/git/refs", expected: ""},
+		{path: "/repos/org", expected: ""},
+	}
+	for _, tt := range tests {
+		if got := repoFromPath(tt.path); got != tt.expected {
+			t.Errorf("expected %q for %q, got %q", tt.expected, tt.path, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// refCache is a list of refs of a repository together with the ETag of the
+// response from which it was obtained.
+type refCache struct {
+	ETag string              `json:"etag"`
+	Refs []*github.Reference `json:"refs"`
+}
+
+// refCachePath returns the path of the cache file for the refs of a repository,
+// which is of the format '<dir>/<org>/<repo>/<refs>.json'.
+func refCachePath(dir, repo, refs string) string {
+	name := strings.Replace(strings.TrimPrefix(refs, "refs/"), "/", "_", -1) + ".json"
+	return filepath.Join(dir, filepath.FromSlash(repo), name)
+}
+
+// readRefCache reads a cache file. It returns nil if the file does not exist.
+func readRefCache(path string) (*refCache, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &refCache{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, errors.Wrapf(err, "could not parse the ref cache %q", path)
+	}
+	return c, nil
+}
+
+// writeRefCache writes a cache file.
+func writeRefCache(path string, c *refCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// gitHubGetRefsCached is like GitHubGetRefs, but stores the refs in d.CacheDir and
+// sends the ETag of the cached refs with the request. If the refs did not change,
+// GitHub responds with 304 Not Modified, which does not count against the rate
+// limit, and the cached refs are returned.
+func gitHubGetRefsCached(d *Data, repo string, refs string) ([]*github.Reference, error) {
+	path := refCachePath(d.CacheDir, repo, refs)
+	cache, err := readRefCache(path)
+	if err != nil {
+		Warningf("ignoring the ref cache: %v", err)
+		cache = nil
+	}
+
+	ownerRepo := strings.Split(repo, "/")
+	u := fmt.Sprintf("repos/%s/%s/git/refs/%s", ownerRepo[0], ownerRepo[1], url.QueryEscape(strings.TrimPrefix(refs, "refs/")))
+	var raw json.RawMessage
+	var resp *github.Response
+	err = retry(d, fmt.Sprintf("getting %q from repository %q", refs, repo), func(ctx context.Context) (*github.Response, error) {
+		req, err := d.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if cache != nil && len(cache.ETag) != 0 {
+			req.Header.Set("If-None-Match", cache.ETag)
+		}
+		raw = nil
+		resp, err = d.client.Do(ctx, req, &raw)
+		if resp != nil && resp.StatusCode == http.StatusNotModified {
+			return resp, nil
+		}
+		return resp, err
+	})
+	// handle not found by returning an empty list
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return []*github.Reference{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		Logf("using the cached %q of repository %q, since they did not change", refs, repo)
+		return cache.Refs, nil
+	}
+
+	// The response is a single ref if only one ref matches.
+	result := []*github.Reference{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		ref := &github.Reference{}
+		if err := json.Unmarshal(raw, ref); err != nil {
+			return nil, errors.Wrapf(err, "could not parse %q from repository %q", refs, repo)
+		}
+		result = []*github.Reference{ref}
+	}
+
+	if etag := resp.Header.Get("ETag"); len(etag) != 0 {
+		if err := writeRefCache(path, &refCache{ETag: etag, Refs: result}); err != nil {
+			Warningf("could not write the ref cache: %v", err)
+		}
+	}
+	return result, nil
+}
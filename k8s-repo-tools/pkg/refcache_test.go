@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestGitHubGetRefsCached(t *testing.T) {
+	// Swap these two lines to enable debug logging.
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "refcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	refs := []*github.Reference{
+		{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("sha1")}},
+		{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("sha2")}},
+	}
+	tagsV17 := []*github.Reference{refs[0]}
+	tagV18 := &github.Reference{Ref: github.String("refs/tags/v1.18.0"), Object: &github.GitObject{SHA: github.String("sha3")}}
+
+	// The steps run in order and share the cache directory.
+	tests := []struct {
+		name           string
+		refs           string
+		setup          func()
+		expectedRefs   []*github.Reference
+		expectedStatus int
+	}{
+		{
+			name:           "cold cache: the refs are downloaded",
+			refs:           "refs/tags",
+			expectedRefs:   tagsV17,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "warm cache: the cached refs are returned",
+			refs:           "refs/tags",
+			expectedRefs:   tagsV17,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "the refs of another kind are cached separately",
+			refs:           "refs/heads",
+			expectedRefs:   []*github.Reference{refs[1]},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "changed refs are downloaded again",
+			refs:           "refs/tags",
+			setup:          func() { refs = append(refs, tagV18) },
+			expectedRefs:   []*github.Reference{refs[0], tagV18},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "a malformed cache file is ignored",
+			refs: "refs/tags",
+			setup: func() {
+				path := refCachePath(dir, "org/repo", "refs/tags")
+				if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectedRefs:   []*github.Reference{refs[0], tagV18},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "the cache is updated after a malformed cache file",
+			refs:           "refs/tags",
+			expectedRefs:   []*github.Reference{refs[0], tagV18},
+			expectedStatus: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			d := &Data{CacheDir: dir}
+
+			// Record the status of the response.
+			var status int
+			handler := NewReferenceHandler(&refs, nil)
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs",
+				func(req *http.Request) (*http.Response, error) {
+					resp, err := handler(req)
+					if resp != nil {
+						status = resp.StatusCode
+					}
+					return resp, err
+				})
+
+			got, err := GitHubGetRefs(d, "org/repo", tt.refs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if !reflect.DeepEqual(got, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%+v\ngot:\n%+v", tt.expectedRefs, got)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "org", "repo", "tags.json")); err != nil {
+				t.Errorf("expected the cache file to exist: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// RefSet is a set of refs indexed by their name, such as 'refs/tags/v1.18.0'.
+// Lookups are constant time and the refs keep the order in which they were added.
+type RefSet struct {
+	refs  []*github.Reference
+	index map[string]int
+}
+
+// NewRefSet creates a RefSet from a list of refs. If multiple refs have the
+// same name the last one is kept.
+func NewRefSet(refs []*github.Reference) *RefSet {
+	s := &RefSet{refs: make([]*github.Reference, 0, len(refs)), index: make(map[string]int, len(refs))}
+	for _, ref := range refs {
+		s.Add(ref)
+	}
+	return s
+}
+
+// Add adds a ref to the set. A ref with the same name is replaced.
+func (s *RefSet) Add(ref *github.Reference) {
+	if i, ok := s.index[ref.GetRef()]; ok {
+		s.refs[i] = ref
+		return
+	}
+	s.index[ref.GetRef()] = len(s.refs)
+	s.refs = append(s.refs, ref)
+}
+
+// Lookup returns the ref with the given name.
+func (s *RefSet) Lookup(name string) (*github.Reference, bool) {
+	i, ok := s.index[name]
+	if !ok {
+		return nil, false
+	}
+	return s.refs[i], true
+}
+
+// Has returns true if the set has a ref with the given name.
+func (s *RefSet) Has(name string) bool {
+	_, ok := s.index[name]
+	return ok
+}
+
+// Len returns the number of refs in the set.
+func (s *RefSet) Len() int {
+	return len(s.refs)
+}
+
+// Refs returns the refs of the set in the order in which they were added.
+func (s *RefSet) Refs() []*github.Reference {
+	return append([]*github.Reference{}, s.refs...)
+}
+
+// Diff returns the refs of s that have no ref with the same name in other.
+func (s *RefSet) Diff(other *RefSet) *RefSet {
+	result := NewRefSet(nil)
+	for _, ref := range s.refs {
+		if !other.Has(ref.GetRef()) {
+			result.Add(ref)
+		}
+	}
+	return result
+}
+
+// Intersect returns the refs of s that have a ref with the same name in other.
+func (s *RefSet) Intersect(other *RefSet) *RefSet {
+	result := NewRefSet(nil)
+	for _, ref := range s.refs {
+		if other.Has(ref.GetRef()) {
+			result.Add(ref)
+		}
+	}
+	return result
+}
+
+// Union returns the refs of s followed by the refs of other. Like with Add, a ref
+// of other replaces a ref of s with the same name.
+func (s *RefSet) Union(other *RefSet) *RefSet {
+	result := NewRefSet(s.refs)
+	for _, ref := range other.refs {
+		result.Add(ref)
+	}
+	return result
+}
+
+// Filter returns the refs of s for which keep returns true.
+func (s *RefSet) Filter(keep func(*github.Reference) bool) *RefSet {
+	result := NewRefSet(nil)
+	for _, ref := range s.refs {
+		if keep(ref) {
+			result.Add(ref)
+		}
+	}
+	return result
+}
+
+// FilterSemVer returns the tags of s that are SemVer and are newer or equal than minV.
+// If minV is nil all SemVer tags are returned. Tags that are not SemVer are skipped with
+// a warning, or returned in an UnparsableRefsError if strict is set.
+func (s *RefSet) FilterSemVer(minV *version.Version, strict bool) (*RefSet, error) {
+	result := NewRefSet(nil)
+	unparsable := unparsableRefs{strict: strict}
+	for _, ref := range s.refs {
+		v, err := TagRefToVersion(ref)
+		if err != nil {
+			unparsable.add(ref, err)
+			continue
+		}
+		if minV != nil && v.LessThan(minV) {
+			V(2).Warningf("skipping ref %s; version is older than the minimum version", ref.GetRef())
+			continue
+		}
+		result.Add(ref)
+	}
+	if err := unparsable.err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FilterBranches returns the branches of s of the format prefixMAJOR.MINOR whose
+// MAJOR.MINOR is newer or equal than the one of minV. If minV is nil all such branches
+// are returned. Branches without the prefix, such as the master branch, are skipped
+// with a warning. Branches with the prefix that are not versioned are skipped with a
+// warning, or returned in an UnparsableRefsError if strict is set.
+func (s *RefSet) FilterBranches(prefix string, minV *version.Version, strict bool) (*RefSet, error) {
+	result := NewRefSet(nil)
+	unparsable := unparsableRefs{strict: strict}
+	for _, ref := range s.refs {
+		v, err := parseBranchRef(ref, prefix, &unparsable)
+		if err != nil {
+			continue
+		}
+		if minV != nil && (v.Major() < minV.Major() || (minV.Major() == v.Major() && v.Minor() < minV.Minor())) {
+			V(2).Warningf("the MAJOR.MINOR in ref %q is older than the minimum version; skipping...", ref.GetRef())
+			continue
+		}
+		result.Add(ref)
+	}
+	if err := unparsable.err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LatestPerMinor returns the latest SemVer tag of s for every MAJOR.MINOR, from the
+// latest to the oldest MAJOR.MINOR. If multiple tags have the same version the first
+// one is used. Tags that are not SemVer are skipped with a warning, or returned in an
+// UnparsableRefsError if strict is set.
+func (s *RefSet) LatestPerMinor(strict bool) (*RefSet, error) {
+	tags, err := s.FilterSemVer(nil, strict)
+	if err != nil {
+		return nil, err
+	}
+	type minorKey struct{ major, minor uint }
+	latest := map[minorKey]*github.Reference{}
+	versions := map[minorKey]*version.Version{}
+	for _, ref := range tags.refs {
+		v, _ := TagRefToVersion(ref)
+		key := minorKey{v.Major(), v.Minor()}
+		if current, ok := versions[key]; !ok || current.LessThan(v) {
+			latest[key] = ref
+			versions[key] = v
+		}
+	}
+	keys := make([]minorKey, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return versions[keys[j]].LessThan(versions[keys[i]])
+	})
+	result := NewRefSet(nil)
+	for _, key := range keys {
+		result.Add(latest[key])
+	}
+	return result, nil
+}
+
+// LatestBranch returns the branch of s with the latest prefixMAJOR.MINOR. If strict is
+// set, branches with the prefix that are not versioned are returned in an
+// UnparsableRefsError. An error of kind ErrorKindNoReleaseBranch is returned if s has
+// no such branches.
+func (s *RefSet) LatestBranch(prefix string, strict bool) (*github.Reference, error) {
+	branches, err := s.FilterBranches(prefix, nil, strict)
+	if err != nil {
+		return nil, err
+	}
+	var result *github.Reference
+	var latest *version.Version
+	for _, ref := range branches.refs {
+		v, _ := BranchRefToVersion(ref, prefix)
+		if latest == nil || latest.LessThan(v) {
+			latest = v
+			result = ref
+		}
+	}
+	if result == nil {
+		return nil, NewErrorf(ErrorKindNoReleaseBranch, "could not find any branches of the format %sMAJOR.MINOR", prefix)
+	}
+	return result, nil
+}
+
+// DivergentRef is a ref that exists in two repositories, but points to
+// different objects.
+type DivergentRef struct {
+	Ref       string `json:"ref"`
+	SourceSHA string `json:"sourceSHA"`
+	DestSHA   string `json:"destSHA"`
+	// Updated is true if the ref in the destination repository was updated
+	// to point to the object of the source repository.
+	Updated bool `json:"updated"`
+}
+
+// Divergent returns the refs of s that have a ref with the same name in dest
+// which points to a different SHA.
+func (s *RefSet) Divergent(dest *RefSet) []DivergentRef {
+	result := []DivergentRef{}
+	for _, ref := range s.refs {
+		destRef, ok := dest.Lookup(ref.GetRef())
+		if !ok || destRef.GetObject().GetSHA() == ref.GetObject().GetSHA() {
+			continue
+		}
+		result = append(result, DivergentRef{
+			Ref:       ref.GetRef(),
+			SourceSHA: ref.GetObject().GetSHA(),
+			DestSHA:   destRef.GetObject().GetSHA(),
+		})
+	}
+	return result
+}
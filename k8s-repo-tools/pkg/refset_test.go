@@ -0,0 +1,222 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestRefSet(t *testing.T) {
+	v17 := &github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("sha1")}}
+	v18 := &github.Reference{Ref: github.String("refs/tags/v1.18.0"), Object: &github.GitObject{SHA: github.String("sha2")}}
+	v18New := &github.Reference{Ref: github.String("refs/tags/v1.18.0"), Object: &github.GitObject{SHA: github.String("sha3")}}
+	master := &github.Reference{Ref: github.String("refs/heads/master"), Object: &github.GitObject{SHA: github.String("sha4")}}
+
+	tests := []struct {
+		name         string
+		fn           func() []*github.Reference
+		expectedRefs []*github.Reference
+	}{
+		{
+			name:         "valid: refs keep their order",
+			fn:           func() []*github.Reference { return NewRefSet([]*github.Reference{v18, master, v17}).Refs() },
+			expectedRefs: []*github.Reference{v18, master, v17},
+		},
+		{
+			name:         "valid: a ref with the same name is replaced in place",
+			fn:           func() []*github.Reference { return NewRefSet([]*github.Reference{v17, v18, master, v18New}).Refs() },
+			expectedRefs: []*github.Reference{v17, v18New, master},
+		},
+		{
+			name:         "valid: empty set",
+			fn:           func() []*github.Reference { return NewRefSet(nil).Refs() },
+			expectedRefs: []*github.Reference{},
+		},
+		{
+			name: "valid: diff",
+			fn: func() []*github.Reference {
+				return NewRefSet([]*github.Reference{v17, v18, master}).Diff(NewRefSet([]*github.Reference{v18New})).Refs()
+			},
+			expectedRefs: []*github.Reference{v17, master},
+		},
+		{
+			name: "valid: intersect keeps the refs of the receiver",
+			fn: func() []*github.Reference {
+				return NewRefSet([]*github.Reference{v17, v18, master}).Intersect(NewRefSet([]*github.Reference{v18New, master})).Refs()
+			},
+			expectedRefs: []*github.Reference{v18, master},
+		},
+		{
+			name: "valid: union replaces refs of the receiver with the same name",
+			fn: func() []*github.Reference {
+				return NewRefSet([]*github.Reference{v17, v18}).Union(NewRefSet([]*github.Reference{master, v18New})).Refs()
+			},
+			expectedRefs: []*github.Reference{v17, v18New, master},
+		},
+		{
+			name: "valid: filter",
+			fn: func() []*github.Reference {
+				return NewRefSet([]*github.Reference{v17, master, v18}).Filter(func(ref *github.Reference) bool {
+					return ref.GetObject().GetSHA() != "sha4"
+				}).Refs()
+			},
+			expectedRefs: []*github.Reference{v17, v18},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if refs := tt.fn(); !reflect.DeepEqual(refs, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", refsToString(tt.expectedRefs), refsToString(refs))
+			}
+		})
+	}
+
+	t.Run("valid: lookup", func(t *testing.T) {
+		s := NewRefSet([]*github.Reference{v17, master})
+		if ref, ok := s.Lookup("refs/heads/master"); !ok || ref != master {
+			t.Errorf("expected to find %q, got %v", master.GetRef(), ref)
+		}
+		if _, ok := s.Lookup("refs/tags/v1.18.0"); ok {
+			t.Errorf("expected to not find %q", v18.GetRef())
+		}
+		if s.Len() != 2 {
+			t.Errorf("expected length 2, got %d", s.Len())
+		}
+	})
+	t.Run("valid: divergent", func(t *testing.T) {
+		src := NewRefSet([]*github.Reference{v17, v18, master})
+		dest := NewRefSet([]*github.Reference{v17, v18New})
+		expected := []DivergentRef{{Ref: "refs/tags/v1.18.0", SourceSHA: "sha2", DestSHA: "sha3"}}
+		if got := src.Divergent(dest); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected divergent refs %+v, got %+v", expected, got)
+		}
+		if got := src.Divergent(src); len(got) != 0 {
+			t.Errorf("expected no divergent refs, got %+v", got)
+		}
+	})
+}
+
+func TestRefSetVersions(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	newRef := func(name string) *github.Reference {
+		return &github.Reference{Ref: github.String(name), Object: &github.GitObject{SHA: github.String("sha")}}
+	}
+	v1170 := newRef("refs/tags/v1.17.0")
+	v1171 := newRef("refs/tags/v1.17.1")
+	v1180rc := newRef("refs/tags/v1.18.0-rc.1")
+	v1180 := newRef("refs/tags/v1.18.0")
+	v1160 := newRef("refs/tags/v1.16.0")
+	badTag := newRef("refs/tags/foo")
+	release117 := newRef("refs/heads/release-1.17")
+	release118 := newRef("refs/heads/release-1.18")
+	badBranch := newRef("refs/heads/release-foo")
+	master := newRef("refs/heads/master")
+	minV := version.MustParseSemantic("v1.17.0")
+
+	tests := []struct {
+		name          string
+		fn            func() (*RefSet, error)
+		expectedRefs  []*github.Reference
+		expectedError bool
+	}{
+		{
+			name: "valid: SemVer tags newer than the minimum version",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{v1160, v1170, badTag, v1180rc}).FilterSemVer(minV, false)
+			},
+			expectedRefs: []*github.Reference{v1170, v1180rc},
+		},
+		{
+			name: "valid: all SemVer tags without a minimum version",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{v1160, v1170, badTag}).FilterSemVer(nil, false)
+			},
+			expectedRefs: []*github.Reference{v1160, v1170},
+		},
+		{
+			name: "invalid: tag that is not SemVer in strict mode",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{v1170, badTag}).FilterSemVer(minV, true)
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: versioned branches newer than the minimum version",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{master, release117, release118, badBranch}).FilterBranches(PrefixBranch, version.MustParseSemantic("v1.18.0"), false)
+			},
+			expectedRefs: []*github.Reference{release118},
+		},
+		{
+			name: "invalid: branch with the prefix that is not versioned in strict mode",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{master, release117, badBranch}).FilterBranches(PrefixBranch, nil, true)
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid: latest tag per MINOR from the latest MINOR",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{v1170, v1180rc, v1160, v1171, badTag, v1180}).LatestPerMinor(false)
+			},
+			expectedRefs: []*github.Reference{v1180, v1171, v1160},
+		},
+		{
+			name: "invalid: latest tag per MINOR in strict mode",
+			fn: func() (*RefSet, error) {
+				return NewRefSet([]*github.Reference{v1170, badTag}).LatestPerMinor(true)
+			},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.fn()
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if refs := s.Refs(); !reflect.DeepEqual(refs, tt.expectedRefs) {
+				t.Errorf("expected refs:\n%s\ngot:\n%s", refsToString(tt.expectedRefs), refsToString(refs))
+			}
+		})
+	}
+
+	t.Run("valid: latest branch", func(t *testing.T) {
+		ref, err := NewRefSet([]*github.Reference{master, release118, release117}).LatestBranch(PrefixBranch, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ref != release118 {
+			t.Errorf("expected branch %q, got %q", release118.GetRef(), ref.GetRef())
+		}
+	})
+	t.Run("invalid: no versioned branch", func(t *testing.T) {
+		_, err := NewRefSet([]*github.Reference{master}).LatestBranch(PrefixBranch, false)
+		if ErrorKindOf(err) != ErrorKindNoReleaseBranch {
+			t.Errorf("expected an error of kind %q, got %v", ErrorKindNoReleaseBranch, err)
+		}
+	})
+}
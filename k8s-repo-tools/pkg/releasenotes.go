@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// GenerateReleaseNotes returns the release notes for a tag of a repository.
+// If d.ReleaseNotesPath is set the release notes are read from this file.
+// If d.ReleaseNotesToolPath is set the release notes tool is used to generate
+// them from the commits between the previous release and the tag.
+// If d.PullRequestNotes is set they are generated from the titles of the pull
+// requests that were merged between the previous release and the tag.
+// If neither is set an empty string is returned.
+func GenerateReleaseNotes(d *Data, repo, tag string) (string, error) {
+	var outputPath string
+
+	if len(d.ReleaseNotesPath) != 0 {
+		outputPath = d.ReleaseNotesPath
+	} else if len(d.ReleaseNotesToolPath) != 0 || d.PullRequestNotes {
+
+		// Get the start and end SHA to use for the release notes tool.
+		startSHA, endSHA, err := FindReleaseNotesSHAs(d, repo, tag)
+		if err != nil {
+			return "", err
+		}
+
+		// If a branch does not exist for this tag use "master"
+		v := version.MustParseSemantic(tag)
+		branch := fmt.Sprintf("%s%d.%d", d.PrefixBranch, v.Major(), v.Minor())
+		if _, err := GitHubGetRef(d, repo, "refs/heads/"+branch); err != nil {
+			branch = BranchMaster
+		}
+
+		if len(d.ReleaseNotesToolPath) == 0 {
+			return generatePullRequestReleaseNotes(d, repo, branch, startSHA, endSHA)
+		}
+
+		// Run the release notes tool.
+		outputPath, err = runReleaseNotesTool(d, repo, branch, startSHA, endSHA)
+		if len(outputPath) != 0 {
+			defer os.Remove(outputPath)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(outputPath) == 0 {
+		return "", nil
+	}
+	return readReleaseNotes(outputPath, d.DryRun)
+}
+
+// FindReleaseNotesSHAs returns the start and end SHA that the release notes tool
+// should use for a tag of a repository.
+func FindReleaseNotesSHAs(d *Data, repo, tag string) (string, string, error) {
+	Logf("finding which commits to use for the release notes tool")
+
+	// Find the reference of the user provided release tag.
+	endRef, err := GitHubGetRef(d, repo, "refs/tags/"+tag)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Fetch all tag references for the repository.
+	refs, err := GitHubGetTags(d, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Find which reference to use for the start tag.
+	startRef, err := FindReleaseNotesSinceRef(endRef, refs)
+	if err != nil {
+		return "", "", err
+	}
+
+	startSHA := startRef.GetObject().GetSHA()
+	endSHA := endRef.GetObject().GetSHA()
+	Logf("found start SHA %s and end SHA %s", startSHA, endSHA)
+	return startSHA, endSHA, nil
+}
+
+// RunCommand runs a command with the given environment and arguments.
+// The output of the command is written to the log writers.
+// In DRY-RUN mode the command is only logged.
+func RunCommand(cmdPath string, environment []string, dryRun bool, args ...string) error {
+	if dryRun {
+		Logf("%s: would run command: %s", PrefixDryRun, cmdPath)
+		Logf("%s: using arguments: %v", PrefixDryRun, args)
+		return nil
+	}
+	Logf("running command: %s", cmdPath)
+	Logf("using arguments: %v", args)
+
+	cmd := exec.Command(cmdPath, args...)
+	stdout, stderr := GetLogWriters()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), environment...)
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func runReleaseNotesTool(d *Data, repo, branch, startSHA, endSHA string) (string, error) {
+	Logf("will now run the release notes tool at %q", d.ReleaseNotesToolPath)
+
+	// Allocate a temporary file path.
+	file, err := ioutil.TempFile("", "release-notes")
+	if err != nil {
+		return "", err
+	}
+	outputPath := file.Name()
+	file.Close()
+	Logf("using output path %q", outputPath)
+
+	// Prepare arguments.
+	ownerRepo := strings.Split(repo, "/")
+	args := []string{
+		"--start-sha=" + startSHA,
+		"--end-sha=" + endSHA,
+		"--output=" + outputPath,
+		"--github-org=" + ownerRepo[0],
+		"--github-repo=" + ownerRepo[1],
+		"--required-author=" + `""`,
+		"--branch=" + branch,
+		"--toc",
+	}
+	if err := RunCommand(d.ReleaseNotesToolPath, []string{"GITHUB_TOKEN=" + d.Token}, d.DryRun, args...); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+func readReleaseNotes(outputPath string, dryRun bool) (string, error) {
+	if dryRun {
+		Logf("%s: would read the release notes from %q", PrefixDryRun, outputPath)
+		return "dry-run-release-notes", nil
+	}
+	Logf("reading the release notes from %q", outputPath)
+	body, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
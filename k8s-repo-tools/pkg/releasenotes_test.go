@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package pkg
 
 import (
 	"fmt"
@@ -24,17 +24,16 @@ import (
 	"testing"
 
 	"github.com/google/go-github/v29/github"
-	"k8s.io/kubeadm/k8s-repo-tools/pkg"
 )
 
-func TestGetReleaseNotesToolSHAs(t *testing.T) {
+func TestFindReleaseNotesSHAs(t *testing.T) {
 	// Swap these two lines to enable debug logging.
-	pkg.SetLogWriters(os.Stdout, os.Stderr)
-	pkg.SetLogWriters(ioutil.Discard, ioutil.Discard)
+	SetLogWriters(os.Stdout, os.Stderr)
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
 
 	tests := []struct {
 		name             string
-		data             *pkg.Data
+		data             *Data
 		refs             []*github.Reference
 		expectedStartSHA string
 		expectedEndSHA   string
@@ -44,7 +43,7 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 	}{
 		{
 			name: "valid: found start SHA and end SHA for a MINOR release",
-			data: &pkg.Data{ReleaseTag: "v1.16.0"},
+			data: &Data{ReleaseTag: "v1.16.0"},
 			refs: []*github.Reference{
 				&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 				&github.Reference{Ref: github.String("refs/tags/v1.16.0"), Object: &github.GitObject{SHA: github.String("1234567891")}},
@@ -55,7 +54,7 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 		},
 		{
 			name: "valid: expect start and end SHA to match for an alpha.0 release",
-			data: &pkg.Data{ReleaseTag: "v1.17.0-alpha.0"},
+			data: &Data{ReleaseTag: "v1.17.0-alpha.0"},
 			refs: []*github.Reference{
 				&github.Reference{Ref: github.String("refs/tags/v1.17.0-alpha.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
@@ -64,7 +63,7 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 		},
 		{
 			name: "invalid: no matching ref for tag",
-			data: &pkg.Data{ReleaseTag: "v1.16.0"},
+			data: &Data{ReleaseTag: "v1.16.0"},
 			refs: []*github.Reference{
 				&github.Reference{Ref: github.String("refs/tags/v1.15.0"), Object: &github.GitObject{SHA: github.String("1234567892")}},
 			},
@@ -72,7 +71,7 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 		},
 		{
 			name: "invalid: expect error on non-SemVer tag",
-			data: &pkg.Data{ReleaseTag: "foo"},
+			data: &Data{ReleaseTag: "foo"},
 			refs: []*github.Reference{
 				&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
@@ -80,7 +79,7 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 		},
 		{
 			name: "invalid: expect error on simulated 404 GET",
-			data: &pkg.Data{ReleaseTag: "v1.17.0"},
+			data: &Data{ReleaseTag: "v1.17.0"},
 			refs: []*github.Reference{
 				&github.Reference{Ref: github.String("refs/tags/v1.17.0"), Object: &github.GitObject{SHA: github.String("1234567890")}},
 			},
@@ -100,8 +99,8 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 				}
 
 				// Override/hardcode some values.
-				tt.data.Dest = "org/dest"
-				tt.data.PrefixBranch = pkg.PrefixBranch
+				tt.data.Dest = []string{"org/dest"}
+				tt.data.PrefixBranch = PrefixBranch
 				tt.data.Force = true
 				tt.data.DryRun = dryRunVal
 
@@ -110,12 +109,12 @@ func TestGetReleaseNotesToolSHAs(t *testing.T) {
 				}
 
 				// Create fake client and setup endpoint handlers.
-				pkg.NewClient(tt.data, pkg.NewTransport())
+				NewClient(tt.data, NewTransport())
 				const testRefs = "https://api.github.com/repos/org/dest/git/refs"
-				handler := pkg.NewReferenceHandler(&tt.refs, tt.methodErrors)
+				handler := NewReferenceHandler(&tt.refs, tt.methodErrors)
 				tt.data.Transport.SetHandler(testRefs, handler)
 
-				startSHA, endSHA, err := getReleaseNotesToolSHAs(tt.data)
+				startSHA, endSHA, err := FindReleaseNotesSHAs(tt.data, tt.data.DestRepo(), tt.data.ReleaseTag)
 				if (err != nil) != tt.expectedError {
 					t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
 				}
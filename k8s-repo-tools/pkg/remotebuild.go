@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// workflowRunCompleted is the status of a workflow run that has finished.
+	workflowRunCompleted = "completed"
+	// workflowRunSuccess is the conclusion of a workflow run that succeeded.
+	workflowRunSuccess = "success"
+
+	// remoteBuildClockSkew is subtracted from the time at which a workflow was
+	// dispatched when looking for its run, to allow for a clock difference
+	// between the local host and GitHub.
+	remoteBuildClockSkew = time.Minute
+)
+
+// remoteBuildPollInterval is the time between checks of the status of a remote
+// build. It can be replaced in tests.
+var remoteBuildPollInterval = 15 * time.Second
+
+// remoteBuildNow returns the current time. It can be replaced in tests.
+var remoteBuildNow = time.Now
+
+// RunRemoteBuild builds the release assets with a GitHub Actions workflow instead of
+// a local command. It dispatches the workflow of repo on ref, waits up to timeout for
+// the run to complete and extracts the ZIP archives of all artifacts of the run in dir.
+// The run is returned if it was found.
+func RunRemoteBuild(d *Data, repo, workflow, ref, dir string, timeout time.Duration, dryRun bool) (*WorkflowRun, error) {
+	if dryRun {
+		Logf("%s: would dispatch workflow %q on ref %q in repository %q and extract its artifacts in %q",
+			PrefixDryRun, workflow, ref, repo, dir)
+		return nil, nil
+	}
+
+	dispatched := remoteBuildNow().Add(-remoteBuildClockSkew)
+	deadline := remoteBuildNow().Add(timeout)
+	findRun := func() (*WorkflowRun, error) {
+		runs, err := GitHubGetWorkflowDispatchRuns(d, repo, workflow, ref)
+		if err != nil {
+			return nil, err
+		}
+		// The runs are sorted from the newest to the oldest.
+		if len(runs) != 0 && !runs[0].CreatedAt.Before(dispatched) {
+			return runs[0], nil
+		}
+		return nil, nil
+	}
+	check := func() bool {
+		run, err := findRun()
+		return err == nil && run != nil
+	}
+	if err := GitHubDispatchWorkflow(d, repo, workflow, ref, check); err != nil {
+		return nil, err
+	}
+
+	// The API does not return the run of a dispatched workflow, so wait
+	// for a new run to appear.
+	var run *WorkflowRun
+	for {
+		var err error
+		if run, err = findRun(); err != nil {
+			return nil, err
+		}
+		if run != nil {
+			break
+		}
+		if err := waitForRemoteBuild(d, deadline, timeout); err != nil {
+			return nil, errors.Wrapf(err, "could not find the run of workflow %q", workflow)
+		}
+	}
+	Logf("waiting for workflow run %d to complete: %s", run.ID, run.HTMLURL)
+	s := startSpan("remote build", spanKindInternal)
+	s.setAttribute("github.workflow_run.id", run.ID)
+	for run.Status != workflowRunCompleted {
+		if err := waitForRemoteBuild(d, deadline, timeout); err != nil {
+			s.finish(err)
+			return run, errors.Wrapf(err, "workflow run %s did not complete", run.HTMLURL)
+		}
+		latest, err := GitHubGetWorkflowRun(d, repo, run.ID)
+		if err != nil {
+			s.finish(err)
+			return run, err
+		}
+		if latest.Status != run.Status {
+			V(1).Logf("workflow run %d is %s", run.ID, latest.Status)
+		}
+		run = latest
+	}
+	if run.Conclusion != workflowRunSuccess {
+		err := errors.Errorf("workflow run %s completed with conclusion %q", run.HTMLURL, run.Conclusion)
+		s.finish(err)
+		return run, err
+	}
+	s.finish(nil)
+	Logf("workflow run %d completed successfully", run.ID)
+
+	// Extract the artifacts of the run.
+	artifacts, err := GitHubGetArtifacts(d, repo, run.ID)
+	if err != nil {
+		return run, err
+	}
+	if len(artifacts) == 0 {
+		Warningf("workflow run %s has no artifacts", run.HTMLURL)
+	}
+	for _, a := range artifacts {
+		if a.Expired {
+			return run, errors.Errorf("artifact %q of workflow run %s has expired", a.Name, run.HTMLURL)
+		}
+		data, err := GitHubDownloadArtifact(d, repo, a)
+		if err != nil {
+			return run, err
+		}
+		Logf("extracting artifact %q in %q", a.Name, dir)
+		if err := extractZip(data, dir); err != nil {
+			return run, errors.Wrapf(err, "could not extract artifact %q", a.Name)
+		}
+	}
+	return run, nil
+}
+
+// waitForRemoteBuild waits for the next check of a remote build. It returns an error
+// if the deadline was exceeded or if the process was interrupted.
+func waitForRemoteBuild(d *Data, deadline time.Time, timeout time.Duration) error {
+	if !remoteBuildNow().Before(deadline) {
+		return errors.Errorf("timed out after %v", timeout)
+	}
+	if !d.sleep(remoteBuildPollInterval) {
+		return ErrInterrupted
+	}
+	return nil
+}
+
+// extractZip extracts the files of a ZIP archive in dir. Files with paths
+// outside of dir are rejected.
+func extractZip(data []byte, dir string) error {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range r.File {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return errors.Errorf("the file %q is outside of the target directory", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipFile(f, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes a single file of a ZIP archive to path.
+func extractZipFile(f *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestZip returns a ZIP archive with the given files.
+func newTestZip(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// newRemoteBuildHandler creates a HTTPHandler that simulates the GitHub Actions API
+// for a workflow that completes with conclusion after a few status checks. The
+// artifact archives redirect to downloadURL.
+func newRemoteBuildHandler(conclusion, downloadURL string, dispatches *int) HTTPHandler {
+	var mu sync.Mutex
+	var run *WorkflowRun
+	checks := 0
+	return func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var result interface{}
+		status := http.StatusOK
+		header := http.Header{}
+		path := req.URL.Path
+		switch {
+		case req.Method == http.MethodPost && strings.HasSuffix(path, "/workflows/build.yml/dispatches"):
+			*dispatches++
+			run = &WorkflowRun{ID: 1, Event: "workflow_dispatch", HeadBranch: "v1.18.0",
+				Status: "queued", HTMLURL: "https://github.com/org/repo/actions/runs/1", CreatedAt: time.Now()}
+			status = http.StatusNoContent
+		case strings.HasSuffix(path, "/workflows/build.yml/runs"):
+			list := &workflowRuns{WorkflowRuns: []*WorkflowRun{}}
+			if run != nil && req.URL.Query().Get("branch") == run.HeadBranch {
+				list.WorkflowRuns = append(list.WorkflowRuns, run)
+			}
+			result = list
+		case strings.HasSuffix(path, "/runs/1"):
+			checks++
+			if checks > 1 {
+				run.Status, run.Conclusion = "completed", conclusion
+			} else {
+				run.Status = "in_progress"
+			}
+			result = run
+		case strings.HasSuffix(path, "/runs/1/artifacts"):
+			result = &artifacts{TotalCount: 1, Artifacts: []*Artifact{{ID: 2, Name: "binaries"}}}
+		case strings.HasSuffix(path, "/artifacts/2/zip"):
+			status = http.StatusFound
+			header.Set("Location", downloadURL)
+		default:
+			status = http.StatusNotFound
+		}
+		buf, _ := json.Marshal(result)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     header,
+			Request:    req,
+		}, nil
+	}
+}
+
+func TestRunRemoteBuild(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer func(interval time.Duration) { remoteBuildPollInterval = interval }(remoteBuildPollInterval)
+	remoteBuildPollInterval = time.Millisecond
+
+	archive := newTestZip(t, map[string]string{"bin/kubeadm": "binary", "checksums.txt": "sum"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		conclusion         string
+		timeout            time.Duration
+		dryRun             bool
+		expectedDispatches int
+		expectedFiles      map[string]string
+		expectedError      bool
+	}{
+		{
+			name:               "valid: the artifacts of the run are extracted",
+			conclusion:         "success",
+			timeout:            time.Minute,
+			expectedDispatches: 1,
+			expectedFiles:      map[string]string{"bin/kubeadm": "binary", "checksums.txt": "sum"},
+		},
+		{
+			name:               "invalid: the run failed",
+			conclusion:         "failure",
+			timeout:            time.Minute,
+			expectedDispatches: 1,
+			expectedError:      true,
+		},
+		{
+			name:               "invalid: the run did not complete in time",
+			conclusion:         "success",
+			timeout:            0,
+			expectedDispatches: 1,
+			expectedError:      true,
+		},
+		{
+			name:    "valid: nothing is dispatched in dry-run mode",
+			timeout: time.Minute,
+			dryRun:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "remote-build")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			d := &Data{Token: "token", Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			var dispatches int
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/actions",
+				newRemoteBuildHandler(tt.conclusion, server.URL, &dispatches))
+
+			_, err = RunRemoteBuild(d, "org/repo", "build.yml", "v1.18.0", dir, tt.timeout, tt.dryRun)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got: %v", tt.expectedError, err)
+			}
+			if dispatches != tt.expectedDispatches {
+				t.Errorf("expected %d dispatches, got %d", tt.expectedDispatches, dispatches)
+			}
+			for name, content := range tt.expectedFiles {
+				buf, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(buf) != content {
+					t.Errorf("expected %q in %q, got %q", content, name, buf)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectedError bool
+	}{
+		{
+			name:  "valid: files in nested directories",
+			files: map[string]string{"a/b/c.txt": "c", "d.txt": "d"},
+		},
+		{
+			name:          "invalid: a file outside of the target directory",
+			files:         map[string]string{"../escape.txt": "x"},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "zip")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			err = extractZip(newTestZip(t, tt.files), filepath.Join(dir, "out"))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got: %v", tt.expectedError, err)
+			}
+			if tt.expectedError {
+				return
+			}
+			for name, content := range tt.files {
+				buf, err := ioutil.ReadFile(filepath.Join(dir, "out", name))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(buf) != content {
+					t.Errorf("expected %q in %q, got %q", content, name, buf)
+				}
+			}
+		})
+	}
+}
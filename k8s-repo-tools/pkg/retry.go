@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+const (
+	// DefaultRetries is the default number of retries for GitHub API calls.
+	DefaultRetries = 3
+	// DefaultRetryWait is the default wait time before the first retry.
+	DefaultRetryWait = 2 * time.Second
+)
+
+// retryFunc is a function that makes a single GitHub API call.
+type retryFunc func(ctx context.Context) (*github.Response, error)
+
+// retryCheckFunc is called before retrying a write operation. It returns true
+// if the write was already applied, for example because the connection failed
+// after the server received the request.
+type retryCheckFunc func() bool
+
+// retry calls fn with a new context from d.CreateContext() and retries it up to
+// d.Retries times if it fails with a retryable error. The wait time between
+// retries starts at d.RetryWait and doubles after every retry.
+func retry(d *Data, description string, fn retryFunc) error {
+	return doRetry(d, description, d.CreateContext, false, nil, fn)
+}
+
+// retryWrite is like retry, but uses contexts from d.CreateWriteContext().
+// If check is not nil it is called before every retry and no retry is made
+// if it returns true. After a write failed because of a secondary rate limit,
+// all following writes are spaced out.
+func retryWrite(d *Data, description string, check retryCheckFunc, fn retryFunc) error {
+	return doRetry(d, description, d.CreateWriteContext, true, check, fn)
+}
+
+// retryTransfer is like retry, but uses the longer timeout for uploading and
+// downloading release assets. If write is true the contexts are not canceled
+// if the process is interrupted, and writes are spaced out like with retryWrite.
+func retryTransfer(d *Data, description string, write bool, check retryCheckFunc, fn retryFunc) error {
+	if write {
+		return doRetry(d, description, d.createTransferWriteContext, true, check, fn)
+	}
+	return doRetry(d, description, d.createTransferContext, false, check, fn)
+}
+
+func doRetry(d *Data, description string, createContext func() (context.Context, context.CancelFunc), write bool, check retryCheckFunc, fn retryFunc) error {
+	wait := d.RetryWait
+	for i := 0; ; i++ {
+		if write && !d.waitForWrite() {
+			return ErrInterrupted
+		}
+		ctx, cancel := createContext()
+		resp, err := fn(ctx)
+		cancel()
+		secondary := isSecondaryRateLimit(resp, err)
+		if secondary && write {
+			Warningf("%s hit the secondary rate limit, slowing down writes to one every %v", description, slowDownWrites())
+		}
+		if err == nil || i >= d.Retries || !(secondary || isRetryable(resp, err)) || d.Interrupted() {
+			return wrapGitHubError(err)
+		}
+
+		retryWait := wait
+		if secondary {
+			if w := secondaryRateLimitWait(resp, err); w > retryWait {
+				retryWait = w
+			}
+		}
+		Warningf("%s failed, retrying in %v (%d/%d): %v", description, retryWait, i+1, d.Retries, err)
+		s := startSpan("retry wait", spanKindInternal)
+		s.setAttribute("retry.description", description)
+		s.setAttribute("retry.attempt", i+1)
+		s.setAttribute("github.secondary_rate_limit", secondary)
+		slept := d.sleep(retryWait)
+		s.finish(nil)
+		if !slept {
+			return wrapGitHubError(err)
+		}
+		wait *= 2
+
+		if check != nil && check() {
+			V(1).Logf("%s was already applied, skipping the retry", description)
+			return nil
+		}
+	}
+}
+
+// isRetryable returns true if a GitHub API call failed with a network error,
+// a server error or because of rate limiting.
+func isRetryable(resp *github.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.Canceled || stderrors.Is(err, ErrRepositoryMoved) {
+		return false
+	}
+	if resp == nil || resp.Response == nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests
+}
+
+// sleep waits for the given duration. It returns false if the wait was
+// stopped because the process was interrupted.
+func (d *Data) sleep(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	if d.ctx == nil {
+		<-timer.C
+		return true
+	}
+	select {
+	case <-timer.C:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
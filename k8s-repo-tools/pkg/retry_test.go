@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+func newTestResponse(status int) *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: status}}
+}
+
+func TestRetry(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		retries       int
+		responses     []*github.Response
+		check         retryCheckFunc
+		expectedCalls int
+		expectedError bool
+	}{
+		{
+			name:          "valid: success without retries",
+			retries:       3,
+			responses:     []*github.Response{newTestResponse(http.StatusOK)},
+			expectedCalls: 1,
+		},
+		{
+			name:    "valid: success after retrying a network error and a server error",
+			retries: 3,
+			responses: []*github.Response{
+				nil,
+				newTestResponse(http.StatusBadGateway),
+				newTestResponse(http.StatusOK),
+			},
+			expectedCalls: 3,
+		},
+		{
+			name:    "valid: check finds that the write was already applied",
+			retries: 3,
+			responses: []*github.Response{
+				nil,
+				newTestResponse(http.StatusOK),
+			},
+			check:         func() bool { return true },
+			expectedCalls: 1,
+		},
+		{
+			name:    "invalid: retries are exhausted",
+			retries: 2,
+			responses: []*github.Response{
+				newTestResponse(http.StatusTooManyRequests),
+				newTestResponse(http.StatusTooManyRequests),
+				newTestResponse(http.StatusTooManyRequests),
+				newTestResponse(http.StatusOK),
+			},
+			expectedCalls: 3,
+			expectedError: true,
+		},
+		{
+			name:    "invalid: client errors are not retried",
+			retries: 3,
+			responses: []*github.Response{
+				newTestResponse(http.StatusUnprocessableEntity),
+				newTestResponse(http.StatusOK),
+			},
+			expectedCalls: 1,
+			expectedError: true,
+		},
+		{
+			name:    "invalid: no retries",
+			retries: 0,
+			responses: []*github.Response{
+				nil,
+				newTestResponse(http.StatusOK),
+			},
+			expectedCalls: 1,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Timeout: time.Second, Retries: tt.retries, RetryWait: time.Millisecond}
+			var calls int
+			fn := func(ctx context.Context) (*github.Response, error) {
+				resp := tt.responses[calls]
+				calls++
+				if resp == nil || resp.StatusCode != http.StatusOK {
+					return resp, errors.New("simulated error")
+				}
+				return resp, nil
+			}
+			var err error
+			if tt.check != nil {
+				err = retryWrite(d, "test", tt.check, fn)
+			} else {
+				err = retry(d, "test", fn)
+			}
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func newSecondaryRateLimitError(retryAfter string) (*github.Response, error) {
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	if len(retryAfter) != 0 {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return &github.Response{Response: resp}, &github.ErrorResponse{
+		Response:         resp,
+		Message:          "You have exceeded a secondary rate limit. Please wait a few minutes before you try again.",
+		DocumentationURL: "https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits",
+	}
+}
+
+func TestRetrySecondaryRateLimit(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer func(min, max time.Duration) {
+		minWriteDelay, maxWriteDelay = min, max
+		resetWriteDelay()
+	}(minWriteDelay, maxWriteDelay)
+	minWriteDelay, maxWriteDelay = time.Millisecond, 4*time.Millisecond
+
+	tests := []struct {
+		name               string
+		write              bool
+		retries            int
+		limitedCalls       int
+		expectedCalls      int
+		expectedWriteDelay time.Duration
+		expectedError      bool
+	}{
+		{
+			name:          "valid: read is retried without slowing down writes",
+			retries:       3,
+			limitedCalls:  1,
+			expectedCalls: 2,
+		},
+		{
+			name:               "valid: write is retried and following writes are slowed down",
+			write:              true,
+			retries:            3,
+			limitedCalls:       2,
+			expectedCalls:      3,
+			expectedWriteDelay: 2 * time.Millisecond,
+		},
+		{
+			name:               "invalid: retries are exhausted and the delay is capped",
+			write:              true,
+			retries:            3,
+			limitedCalls:       5,
+			expectedCalls:      4,
+			expectedWriteDelay: 4 * time.Millisecond,
+			expectedError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetWriteDelay()
+			d := &Data{Timeout: time.Second, Retries: tt.retries, RetryWait: time.Millisecond}
+			var calls int
+			fn := func(ctx context.Context) (*github.Response, error) {
+				calls++
+				if calls <= tt.limitedCalls {
+					return newSecondaryRateLimitError("0")
+				}
+				return newTestResponse(http.StatusOK), nil
+			}
+			var err error
+			if tt.write {
+				err = retryWrite(d, "test", nil, fn)
+			} else {
+				err = retry(d, "test", fn)
+			}
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil && ErrorKindOf(err) != ErrorKindRateLimited {
+				t.Errorf("expected an error of kind %s, got %s", ErrorKindRateLimited, ErrorKindOf(err))
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+			if writeDelay != tt.expectedWriteDelay {
+				t.Errorf("expected a write delay of %v, got %v", tt.expectedWriteDelay, writeDelay)
+			}
+		})
+	}
+}
+
+func TestSecondaryRateLimitWait(t *testing.T) {
+	retryAfter := 5 * time.Second
+	resp, err := newSecondaryRateLimitError("7")
+	respNoHeader, errNoHeader := newSecondaryRateLimitError("")
+
+	tests := []struct {
+		name     string
+		resp     *github.Response
+		err      error
+		expected time.Duration
+	}{
+		{
+			name:     "abuse rate limit error with retry after",
+			err:      &github.AbuseRateLimitError{RetryAfter: &retryAfter},
+			expected: retryAfter,
+		},
+		{
+			name:     "secondary rate limit with Retry-After header",
+			resp:     resp,
+			err:      err,
+			expected: 7 * time.Second,
+		},
+		{
+			name:     "secondary rate limit without Retry-After header",
+			resp:     respNoHeader,
+			err:      errNoHeader,
+			expected: defaultSecondaryRateLimitWait,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secondaryRateLimitWait(tt.resp, tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// NormalizeVersion completes a version with a missing MINOR or PATCH component,
+// such as "v1.17" or "v1.17-rc.1", to "v1.17.0" and "v1.17.0-rc.1". The pre-release
+// and build metadata are kept as they are, so that their dots are not mistaken
+// for the dots of the MAJOR.MINOR.PATCH components.
+func NormalizeVersion(s string) string {
+	core, suffix := s, ""
+	if i := strings.IndexAny(s, "-+"); i != -1 {
+		core, suffix = s[:i], s[i:]
+	}
+	for n := strings.Count(core, "."); n < 2; n++ {
+		core += ".0"
+	}
+	return core + suffix
+}
+
+// ParseVersion parses a SemVer version with an optional "v" prefix after
+// normalizing it with NormalizeVersion.
+func ParseVersion(s string) (*version.Version, error) {
+	return version.ParseSemantic(NormalizeVersion(s))
+}
+
+// BranchToVersion converts the name of a versioned branch such as "release-1.17"
+// or "refs/heads/release-1.17" with the given prefix to a Version.
+func BranchToVersion(branch, prefix string) (*version.Version, error) {
+	name := strings.TrimPrefix(branch, "refs/heads/")
+	if !strings.HasPrefix(name, prefix) {
+		return nil, errors.Errorf("the branch %q does not have the prefix %q", branch, prefix)
+	}
+	v, err := ParseVersion(strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse versioned branch %q", branch)
+	}
+	return v, nil
+}
+
+// EqualVersions returns true if a and b have the same precedence. Unlike
+// comparing their strings, this ignores the build metadata.
+func EqualVersions(a, b *version.Version) bool {
+	return !a.LessThan(b) && !b.LessThan(a)
+}
+
+// NextPatch returns the next PATCH version after v: v1.17.3 -> v1.17.4.
+// A pre-release becomes its release: v1.17.4-rc.1 -> v1.17.4.
+func NextPatch(v *version.Version) *version.Version {
+	patch := v.Patch()
+	if len(v.PreRelease()) == 0 {
+		patch++
+	}
+	return newVersion(v.Major(), v.Minor(), patch, "")
+}
+
+// NextMinor returns the next MINOR version after v: v1.17.3 -> v1.18.0.
+// A pre-release of a .0 version becomes its release: v1.18.0-rc.1 -> v1.18.0.
+func NextMinor(v *version.Version) *version.Version {
+	minor, patch := v.Minor(), v.Patch()
+	if len(v.PreRelease()) == 0 || patch != 0 {
+		minor++
+		patch = 0
+	}
+	return newVersion(v.Major(), minor, patch, "")
+}
+
+// NextPreRelease returns the next pre-release after v: v1.18.0-beta.1 -> v1.18.0-beta.2.
+// v must be a pre-release of the format "name.N".
+func NextPreRelease(v *version.Version) (*version.Version, error) {
+	pre := v.PreRelease()
+	if len(pre) == 0 {
+		return nil, errors.Errorf("cannot bump the pre-release of %q, which is not a pre-release", v)
+	}
+	idx := strings.LastIndex(pre, ".")
+	if idx == -1 {
+		return nil, errors.Errorf("the pre-release of %q must be of the format 'name.N'", v)
+	}
+	n, err := strconv.Atoi(pre[idx+1:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "the pre-release of %q must end with a number", v)
+	}
+	return newVersion(v.Major(), v.Minor(), v.Patch(), fmt.Sprintf("%s.%d", pre[:idx], n+1)), nil
+}
+
+// PreviousRelease returns the release that precedes v and that is used as the start of
+// the release notes range for v. It returns nil if the previous release cannot be computed
+// from v alone. See FindReleaseNotesSinceRef for the lookup of such releases.
+//
+// version           | returned version | comment
+// --------------------------------------------------
+// v1.17.0-alpha.0   | nil              | no previous release
+// v1.17.0-alpha.1   | v1.16.0          | previous MINOR
+// v1.17.0-<pre>     | nil              | depends on the existing pre-releases
+// v1.17.0           | v1.16.0          | previous MINOR
+// v1.17.1           | v1.17.0          | previous PATCH
+// v2.0.0            | nil              | depends on the existing MINOR releases of v1
+func PreviousRelease(v *version.Version) *version.Version {
+	if pre := v.PreRelease(); len(pre) != 0 {
+		if pre != "alpha.1" {
+			return nil
+		}
+	} else if v.Patch() != 0 {
+		return newVersion(v.Major(), v.Minor(), v.Patch()-1, "")
+	}
+	if v.Minor() == 0 {
+		return nil
+	}
+	return newVersion(v.Major(), v.Minor()-1, 0, "")
+}
+
+// FastForwardWindow returns the fast-forward window for a versioned branch with
+// the version branchV. A tag x is within the window if min <= x < max, where:
+// - min is (branchV.MAJOR).(branchV.MINOR).0-beta.0
+// - max is (branchV.MAJOR).(branchV.MINOR).0-rc.1
+// https://github.com/kubernetes/sig-release/blob/d6a4a0c/release-engineering/role-handbooks/branch-manager.md#branch-fast-forward
+func FastForwardWindow(branchV *version.Version) (min, max *version.Version) {
+	min = newVersion(branchV.Major(), branchV.Minor(), 0, "beta.0")
+	max = newVersion(branchV.Major(), branchV.Minor(), 0, "rc.1")
+	return min, max
+}
+
+// InFastForwardWindow returns true if the tag version tagV is within the
+// fast-forward window of a versioned branch with the version branchV.
+func InFastForwardWindow(tagV, branchV *version.Version) bool {
+	min, max := FastForwardWindow(branchV)
+	return tagV.AtLeast(min) && tagV.LessThan(max)
+}
+
+// NextFastForwardPreRelease returns the pre-release to tag on a versioned branch with
+// the version branchV after it was fast-forwarded, where tagV is the latest tag of
+// the branch: v1.18.0-beta.1 -> v1.18.0-beta.2. Following the branch manager handbook,
+// fast-forwards only bump the pre-releases in the fast-forward window. It returns an
+// error if the next pre-release is not in the window, such as v1.18.0-rc.1 after
+// v1.18.0-rc.0, which is tagged by the release managers.
+func NextFastForwardPreRelease(tagV, branchV *version.Version) (*version.Version, error) {
+	if !InFastForwardWindow(tagV, branchV) {
+		return nil, errors.Errorf("the tag %q is not within the fast-forward window of branch version %q", tagV, branchV)
+	}
+	next, err := NextPreRelease(tagV)
+	if err != nil {
+		return nil, err
+	}
+	if !InFastForwardWindow(next, branchV) {
+		min, max := FastForwardWindow(branchV)
+		return nil, errors.Errorf("the next pre-release %q after %q does not fall within the fast-forward window: %s <= VER < %s",
+			next, tagV, min, max)
+	}
+	return next, nil
+}
+
+func newVersion(major, minor, patch uint, pre string) *version.Version {
+	s := fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+	if len(pre) != 0 {
+		s += "-" + pre
+	}
+	return version.MustParseSemantic(s)
+}
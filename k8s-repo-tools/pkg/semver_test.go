@@ -0,0 +1,523 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestNextPatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion string
+	}{
+		{
+			name:            "valid: release",
+			version:         "v1.17.3",
+			expectedVersion: "1.17.4",
+		},
+		{
+			name:            "valid: .0 release",
+			version:         "v1.18.0",
+			expectedVersion: "1.18.1",
+		},
+		{
+			name:            "valid: pre-release becomes its release",
+			version:         "v1.17.4-rc.1",
+			expectedVersion: "1.17.4",
+		},
+		{
+			name:            "valid: build metadata is dropped",
+			version:         "v1.17.3+foo",
+			expectedVersion: "1.17.4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NextPatch(version.MustParseSemantic(tt.version))
+			if v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestNextMinor(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion string
+	}{
+		{
+			name:            "valid: release",
+			version:         "v1.17.3",
+			expectedVersion: "1.18.0",
+		},
+		{
+			name:            "valid: .0 release",
+			version:         "v1.17.0",
+			expectedVersion: "1.18.0",
+		},
+		{
+			name:            "valid: pre-release of a .0 version becomes its release",
+			version:         "v1.18.0-rc.1",
+			expectedVersion: "1.18.0",
+		},
+		{
+			name:            "valid: alpha.0 of a .0 version becomes its release",
+			version:         "v1.18.0-alpha.0",
+			expectedVersion: "1.18.0",
+		},
+		{
+			name:            "valid: pre-release of a PATCH version",
+			version:         "v1.17.4-rc.1",
+			expectedVersion: "1.18.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NextMinor(version.MustParseSemantic(tt.version))
+			if v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestNextPreRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion string
+		expectedError   bool
+	}{
+		{
+			name:            "valid: beta",
+			version:         "v1.18.0-beta.1",
+			expectedVersion: "1.18.0-beta.2",
+		},
+		{
+			name:            "valid: alpha.0",
+			version:         "v1.18.0-alpha.0",
+			expectedVersion: "1.18.0-alpha.1",
+		},
+		{
+			name:            "valid: multi-digit number",
+			version:         "v1.18.0-rc.9",
+			expectedVersion: "1.18.0-rc.10",
+		},
+		{
+			name:            "valid: name with a dot",
+			version:         "v1.18.0-foo.bar.1",
+			expectedVersion: "1.18.0-foo.bar.2",
+		},
+		{
+			name:          "invalid: not a pre-release",
+			version:       "v1.17.3",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: pre-release without a number",
+			version:       "v1.18.0-beta",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: pre-release that does not end with a number",
+			version:       "v1.18.0-beta.foo",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NextPreRelease(version.MustParseSemantic(tt.version))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err != nil {
+				return
+			}
+			if v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestPreviousRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion string
+	}{
+		{
+			name:            "valid: previous PATCH",
+			version:         "v1.17.1",
+			expectedVersion: "1.17.0",
+		},
+		{
+			name:            "valid: previous MINOR",
+			version:         "v1.17.0",
+			expectedVersion: "1.16.0",
+		},
+		{
+			name:            "valid: alpha.1 uses the previous MINOR",
+			version:         "v1.17.0-alpha.1",
+			expectedVersion: "1.16.0",
+		},
+		{
+			name:    "valid: alpha.0 has no previous release",
+			version: "v1.17.0-alpha.0",
+		},
+		{
+			name:    "valid: other pre-releases depend on the existing pre-releases",
+			version: "v1.17.0-beta.1",
+		},
+		{
+			name:    "valid: MAJOR release depends on the existing MINOR releases",
+			version: "v2.0.0",
+		},
+		{
+			name:    "valid: alpha.1 of a MAJOR release depends on the existing MINOR releases",
+			version: "v2.0.0-alpha.1",
+		},
+		{
+			name:            "valid: PATCH of a MAJOR release",
+			version:         "v2.0.1",
+			expectedVersion: "2.0.0",
+		},
+		{
+			name:    "valid: v0.0.0",
+			version: "v0.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := PreviousRelease(version.MustParseSemantic(tt.version))
+			if len(tt.expectedVersion) == 0 {
+				if v != nil {
+					t.Fatalf("expected nil version, got %q", v.String())
+				}
+				return
+			}
+			if v == nil {
+				t.Fatalf("expected version %q, got nil", tt.expectedVersion)
+			}
+			if v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestInFastForwardWindow(t *testing.T) {
+	tests := []struct {
+		name           string
+		tag            string
+		branch         string
+		expectedResult bool
+	}{
+		{
+			name:   "not in window: alpha",
+			tag:    "v1.18.0-alpha.3",
+			branch: "v1.18.0",
+		},
+		{
+			name:           "in window: beta.0",
+			tag:            "v1.18.0-beta.0",
+			branch:         "v1.18.0",
+			expectedResult: true,
+		},
+		{
+			name:           "in window: beta.2",
+			tag:            "v1.18.0-beta.2",
+			branch:         "v1.18.0",
+			expectedResult: true,
+		},
+		{
+			name:           "in window: rc.0",
+			tag:            "v1.18.0-rc.0",
+			branch:         "v1.18.0",
+			expectedResult: true,
+		},
+		{
+			name:   "not in window: rc.1",
+			tag:    "v1.18.0-rc.1",
+			branch: "v1.18.0",
+		},
+		{
+			name:   "not in window: release",
+			tag:    "v1.18.0",
+			branch: "v1.18.0",
+		},
+		{
+			name:   "not in window: tag for a different MINOR",
+			tag:    "v1.17.0-beta.1",
+			branch: "v1.18.0",
+		},
+		{
+			name:           "in window: PATCH of the branch version is ignored",
+			tag:            "v1.18.0-beta.1",
+			branch:         "v1.18.5",
+			expectedResult: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := InFastForwardWindow(version.MustParseSemantic(tt.tag), version.MustParseSemantic(tt.branch))
+			if result != tt.expectedResult {
+				t.Errorf("expected result %v, got %v", tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestNextFastForwardPreRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		tag             string
+		branch          string
+		expectedVersion string
+		expectedError   bool
+	}{
+		{
+			name:            "valid: beta.0 -> beta.1",
+			tag:             "v1.18.0-beta.0",
+			branch:          "v1.18.0",
+			expectedVersion: "1.18.0-beta.1",
+		},
+		{
+			name:            "valid: beta.2 -> beta.3",
+			tag:             "v1.18.0-beta.2",
+			branch:          "v1.18.0",
+			expectedVersion: "1.18.0-beta.3",
+		},
+		{
+			name:          "invalid: rc.1 after rc.0 is not in the window",
+			tag:           "v1.18.0-rc.0",
+			branch:        "v1.18.0",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: the tag is not in the window",
+			tag:           "v1.18.0-alpha.3",
+			branch:        "v1.18.0",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NextFastForwardPreRelease(version.MustParseSemantic(tt.tag), version.MustParseSemantic(tt.branch))
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err == nil && v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestFastForwardWindow(t *testing.T) {
+	min, max := FastForwardWindow(version.MustParseSemantic("v1.18.0"))
+	if min.String() != "1.18.0-beta.0" {
+		t.Errorf("expected min version %q, got %q", "1.18.0-beta.0", min.String())
+	}
+	if max.String() != "1.18.0-rc.1" {
+		t.Errorf("expected max version %q, got %q", "1.18.0-rc.1", max.String())
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name               string
+		version            string
+		expectedNormalized string
+		expectedVersion    string
+		expectedError      bool
+	}{
+		{
+			name:               "valid: full version with v prefix",
+			version:            "v1.17.3",
+			expectedNormalized: "v1.17.3",
+			expectedVersion:    "1.17.3",
+		},
+		{
+			name:               "valid: full version without v prefix",
+			version:            "1.17.3",
+			expectedNormalized: "1.17.3",
+			expectedVersion:    "1.17.3",
+		},
+		{
+			name:               "valid: missing PATCH",
+			version:            "v1.17",
+			expectedNormalized: "v1.17.0",
+			expectedVersion:    "1.17.0",
+		},
+		{
+			name:               "valid: missing MINOR and PATCH",
+			version:            "v2",
+			expectedNormalized: "v2.0.0",
+			expectedVersion:    "2.0.0",
+		},
+		{
+			name:               "valid: pre-release",
+			version:            "v1.17.0-rc.1",
+			expectedNormalized: "v1.17.0-rc.1",
+			expectedVersion:    "1.17.0-rc.1",
+		},
+		{
+			name:               "valid: pre-release with missing PATCH",
+			version:            "v1.17-rc.1",
+			expectedNormalized: "v1.17.0-rc.1",
+			expectedVersion:    "1.17.0-rc.1",
+		},
+		{
+			name:               "valid: build metadata",
+			version:            "v1.17.3+build.1.2",
+			expectedNormalized: "v1.17.3+build.1.2",
+			expectedVersion:    "1.17.3+build.1.2",
+		},
+		{
+			name:               "valid: build metadata with missing PATCH",
+			version:            "v1.17+build.1",
+			expectedNormalized: "v1.17.0+build.1",
+			expectedVersion:    "1.17.0+build.1",
+		},
+		{
+			name:               "valid: pre-release and build metadata with missing PATCH",
+			version:            "v1.17-beta.0+build.1",
+			expectedNormalized: "v1.17.0-beta.0+build.1",
+			expectedVersion:    "1.17.0-beta.0+build.1",
+		},
+		{
+			name:               "invalid: empty version",
+			version:            "",
+			expectedNormalized: ".0.0",
+			expectedError:      true,
+		},
+		{
+			name:               "invalid: not a version",
+			version:            "foo",
+			expectedNormalized: "foo.0.0",
+			expectedError:      true,
+		},
+		{
+			name:               "invalid: too many components",
+			version:            "v1.17.3.4",
+			expectedNormalized: "v1.17.3.4",
+			expectedError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if normalized := NormalizeVersion(tt.version); normalized != tt.expectedNormalized {
+				t.Errorf("expected normalized version %q, got %q", tt.expectedNormalized, normalized)
+			}
+			v, err := ParseVersion(tt.version)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err == nil && v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestBranchToVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		branch          string
+		prefix          string
+		expectedVersion string
+		expectedError   bool
+	}{
+		{
+			name:            "valid: branch name",
+			branch:          "release-1.17",
+			prefix:          "release-",
+			expectedVersion: "1.17.0",
+		},
+		{
+			name:            "valid: branch ref",
+			branch:          "refs/heads/release-1.17",
+			prefix:          "release-",
+			expectedVersion: "1.17.0",
+		},
+		{
+			name:            "valid: prefix characters in the version are kept",
+			branch:          "k8s-8.1",
+			prefix:          "k8s-",
+			expectedVersion: "8.1.0",
+		},
+		{
+			name:            "valid: v prefix after the branch prefix",
+			branch:          "release-v1.17",
+			prefix:          "release-",
+			expectedVersion: "1.17.0",
+		},
+		{
+			name:            "valid: empty prefix",
+			branch:          "1.17",
+			expectedVersion: "1.17.0",
+		},
+		{
+			name:          "invalid: the prefix is not at the start",
+			branch:        "foo-release-1.17",
+			prefix:        "release-",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: not a versioned branch",
+			branch:        "release-foo",
+			prefix:        "release-",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := BranchToVersion(tt.branch, tt.prefix)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err == nil && v.String() != tt.expectedVersion {
+				t.Errorf("expected version %q, got %q", tt.expectedVersion, v.String())
+			}
+		})
+	}
+}
+
+func TestEqualVersions(t *testing.T) {
+	a := version.MustParseSemantic("v1.17.0+build.1")
+	if !EqualVersions(a, version.MustParseSemantic("v1.17.0")) {
+		t.Errorf("expected versions with different build metadata to be equal")
+	}
+	if EqualVersions(a, version.MustParseSemantic("v1.17.0-rc.1")) {
+		t.Errorf("expected a release and its pre-release to differ")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// ExitCodeInterrupted is the exit code of the tools when they are stopped
+// by SIGINT or SIGTERM.
+const ExitCodeInterrupted = 130
+
+// PartialMarkerSuffix is appended to the path of an output file to create
+// a marker file when the output file contains partial results.
+const PartialMarkerSuffix = ".partial"
+
+// ErrInterrupted is returned when an operation is stopped because the
+// process received SIGINT or SIGTERM.
+var ErrInterrupted = NewError(ErrorKindInterrupted, errors.New("interrupted by signal"))
+
+// HandleSignals cancels the base context of d on the first SIGINT or SIGTERM,
+// or when d.RunDeadline is exceeded. Contexts created with d.CreateContext()
+// are canceled, while write operations that are in-flight are allowed to finish.
+// A second signal exits immediately. The returned function stops the signal handling.
+func HandleSignals(d *Data) func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if d.RunDeadline > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), d.RunDeadline)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	d.ctx = ctx
+
+	ch := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case s := <-ch:
+			Warningf("received %s, stopping after the current write operation. Send it again to exit immediately", s)
+			cancel()
+		case <-ctx.Done():
+			if ctx.Err() != context.DeadlineExceeded {
+				return
+			}
+			Warningf("the run deadline of %v was exceeded, stopping after the current write operation", d.RunDeadline)
+		case <-done:
+			return
+		}
+		select {
+		case s := <-ch:
+			Errorf("received %s again, exiting", s)
+			os.Exit(ExitCodeInterrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+		cancel()
+	}
+}
+
+// Interrupted returns true if the base context of d was canceled by a signal.
+func (d *Data) Interrupted() bool {
+	return d.ctx != nil && d.ctx.Err() != nil
+}
+
+// WritePartialMarker writes a marker file next to the output file at the given
+// path, to denote that the output file contains partial results.
+func WritePartialMarker(path string, reason error) error {
+	markerPath := path + PartialMarkerSuffix
+	Warningf("writing the partial result marker %q", markerPath)
+	if err := WriteOutputFile(markerPath, []byte(fmt.Sprintf("%v\n", reason))); err != nil {
+		return errors.Wrapf(err, "could not write the partial result marker %q", markerPath)
+	}
+	return nil
+}
+
+// ExitInterrupted prints an error and exits with ExitCodeInterrupted.
+func ExitInterrupted(err error) {
+	Errorf("%v", err)
+	FinishRun(ExitCodeInterrupted, err)
+	os.Exit(ExitCodeInterrupted)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestHandleSignals(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	d := &Data{Timeout: time.Second}
+	if d.Interrupted() {
+		t.Fatal("expected a Data without signal handling to not be interrupted")
+	}
+
+	stop := HandleSignals(d)
+	defer stop()
+
+	ctx, cancel := d.CreateContext()
+	defer cancel()
+	writeCtx, writeCancel := d.CreateWriteContext()
+	defer writeCancel()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("could not send signal: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the context to be canceled")
+	}
+	if !d.Interrupted() {
+		t.Error("expected Data to be interrupted")
+	}
+	if writeCtx.Err() != nil {
+		t.Errorf("expected the write context to not be canceled, got: %v", writeCtx.Err())
+	}
+}
+
+func TestHandleSignalsRunDeadline(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	d := &Data{Timeout: time.Minute, RunDeadline: 50 * time.Millisecond}
+	stop := HandleSignals(d)
+	defer stop()
+
+	ctx, cancel := d.CreateContext()
+	defer cancel()
+	writeCtx, writeCancel := d.CreateWriteContext()
+	defer writeCancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the context to be canceled")
+	}
+	if !d.Interrupted() {
+		t.Error("expected Data to be interrupted after the run deadline")
+	}
+	if writeCtx.Err() != nil {
+		t.Errorf("expected the write context to not be canceled, got: %v", writeCtx.Err())
+	}
+}
+
+func TestCreateNewRefsInterrupted(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	d := &Data{Timeout: time.Second}
+	stop := HandleSignals(d)
+	defer stop()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("could not send signal: %v", err)
+	}
+	for i := 0; !d.Interrupted(); i++ {
+		if i == 500 {
+			t.Fatal("timeout waiting for Data to be interrupted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	newRefs := []*github.Reference{{Ref: github.String("refs/heads/release-1.17")}}
+	var dest []*github.Reference
+	if err := CreateNewBranches(d, "org/dest", &dest, newRefs, "sha"); err != ErrInterrupted {
+		t.Errorf("expected ErrInterrupted for branches, got: %v", err)
+	}
+	if err := CreateNewTags(d, "org/dest", &dest, nil, newRefs, "sha"); err != ErrInterrupted {
+		t.Errorf("expected ErrInterrupted for tags, got: %v", err)
+	}
+	if len(dest) != 0 {
+		t.Errorf("expected no refs to be created, got: %v", dest)
+	}
+}
+
+func TestWritePartialMarker(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "signal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "output.json")
+	if err := WritePartialMarker(path, ErrInterrupted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf, err := ioutil.ReadFile(path + PartialMarkerSuffix)
+	if err != nil {
+		t.Fatalf("could not read marker: %v", err)
+	}
+	if string(buf) != ErrInterrupted.Error()+"\n" {
+		t.Errorf("unexpected marker contents: %q", buf)
+	}
+}
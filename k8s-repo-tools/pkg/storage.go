@@ -0,0 +1,317 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SchemeS3 is the prefix of output paths that are objects in an S3 bucket.
+	SchemeS3 = "s3://"
+	// SchemeGCS is the prefix of output paths that are objects in a GCS bucket.
+	SchemeGCS = "gs://"
+
+	// storageTimeout is the timeout for uploading an object to a bucket.
+	storageTimeout = 5 * time.Minute
+
+	// gcsDefaultURL is the base URL of the Google Cloud Storage JSON API.
+	gcsDefaultURL = "https://storage.googleapis.com"
+	// gcsDefaultMetadataHost is the host of the GCE metadata server.
+	gcsDefaultMetadataHost = "metadata.google.internal"
+)
+
+// storageNow returns the current time for signing requests. Tests can replace it.
+var storageNow = time.Now
+
+// IsObjectStoragePath returns true if path is an s3:// or gs:// URL.
+func IsObjectStoragePath(path string) bool {
+	return strings.HasPrefix(path, SchemeS3) || strings.HasPrefix(path, SchemeGCS)
+}
+
+// WriteOutputFile writes buf to path. path is either a local file or an
+// object in a bucket in the form s3://bucket/key or gs://bucket/key.
+// The credentials for the bucket are discovered from the environment.
+func WriteOutputFile(path string, buf []byte) error {
+	return writeFile(path, buf, "application/json")
+}
+
+// WriteTextFile is like WriteOutputFile, but objects are written as plain text.
+func WriteTextFile(path string, buf []byte) error {
+	return writeFile(path, buf, "text/plain; charset=utf-8")
+}
+
+func writeFile(path string, buf []byte, contentType string) error {
+	if !IsObjectStoragePath(path) {
+		return ioutil.WriteFile(path, buf, 0600)
+	}
+	_, err := doObjectRequest(http.MethodPut, path, buf, contentType)
+	return errors.Wrapf(err, "could not upload %q", path)
+}
+
+// ReadStorageFile reads a local file or an object in a bucket in the form
+// s3://bucket/key or gs://bucket/key. If the file or the object does not
+// exist an error of kind ErrorKindNotFound is returned.
+func ReadStorageFile(path string) ([]byte, error) {
+	if !IsObjectStoragePath(path) {
+		buf, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, NewError(ErrorKindNotFound, err)
+		}
+		return buf, err
+	}
+	buf, err := doObjectRequest(http.MethodGet, path, nil, "")
+	return buf, errors.Wrapf(err, "could not download %q", path)
+}
+
+// doObjectRequest reads or writes the object at path and returns the body of the response.
+func doObjectRequest(method, path string, buf []byte, contentType string) ([]byte, error) {
+	scheme := SchemeGCS
+	if strings.HasPrefix(path, SchemeS3) {
+		scheme = SchemeS3
+	}
+	bucket, key, err := splitObjectPath(path, scheme)
+	if err != nil {
+		return nil, err
+	}
+	client := NewHTTPClient(storageTimeout)
+	if scheme == SchemeS3 {
+		return s3ObjectRequest(client, os.Getenv, method, bucket, key, buf, contentType)
+	}
+	return gcsObjectRequest(client, os.Getenv, method, bucket, key, buf, contentType)
+}
+
+// splitObjectPath splits an object URL into a bucket and a key.
+func splitObjectPath(path, scheme string) (string, string, error) {
+	s := strings.SplitN(strings.TrimPrefix(path, scheme), "/", 2)
+	if len(s) != 2 || len(s[0]) == 0 || len(s[1]) == 0 || strings.HasSuffix(s[1], "/") {
+		return "", "", errors.Errorf("the object path %q must be in the form %sbucket/key", path, scheme)
+	}
+	return s[0], s[1], nil
+}
+
+// s3ObjectRequest reads (GET) or writes (PUT) an object in S3 or in an S3 compatible
+// service with a request signed with AWS Signature Version 4. The credentials are read from
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and the optional AWS_SESSION_TOKEN.
+// The region is read from AWS_REGION or AWS_DEFAULT_REGION. AWS_ENDPOINT_URL
+// can point to an S3 compatible service that uses path-style URLs.
+func s3ObjectRequest(client *http.Client, getenv func(string) string, method, bucket, key string, buf []byte, contentType string) ([]byte, error) {
+	accessKey, secretKey := getenv("AWS_ACCESS_KEY_ID"), getenv("AWS_SECRET_ACCESS_KEY")
+	if len(accessKey) == 0 || len(secretKey) == 0 {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	region := getenv("AWS_REGION")
+	if len(region) == 0 {
+		region = getenv("AWS_DEFAULT_REGION")
+	}
+	if len(region) == 0 {
+		region = "us-east-1"
+	}
+
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, awsURIEncode(key))
+	if endpoint := getenv("AWS_ENDPOINT_URL"); len(endpoint) != 0 {
+		objectURL = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), bucket, awsURIEncode(key))
+	}
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	if len(contentType) != 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token := getenv("AWS_SESSION_TOKEN"); len(token) != 0 {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signAWSV4(req, buf, accessKey, secretKey, region, "s3", storageNow())
+	return doStorageRequest(client, req)
+}
+
+// signAWSV4 signs req with AWS Signature Version 4. All headers of req and
+// the host are signed.
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+func signAWSV4(req *http.Request, payload []byte, accessKey, secretKey, region, service string, now time.Time) {
+	payloadHash := sha256.Sum256(payload)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, s := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode encodes every byte of s except unreserved characters and "/".
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// gcsObjectRequest reads (GET) or writes (PUT) an object in Google Cloud Storage. The access token is
+// read from GOOGLE_OAUTH_ACCESS_TOKEN or requested from the GCE metadata server
+// (GCE_METADATA_HOST). STORAGE_EMULATOR_HOST can point to a GCS emulator, in
+// which case no token is used.
+func gcsObjectRequest(client *http.Client, getenv func(string) string, method, bucket, key string, buf []byte, contentType string) ([]byte, error) {
+	baseURL, token := gcsDefaultURL, getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if host := getenv("STORAGE_EMULATOR_HOST"); len(host) != 0 {
+		baseURL = strings.TrimSuffix(host, "/")
+		if !strings.Contains(baseURL, "://") {
+			baseURL = "http://" + baseURL
+		}
+	} else if len(token) == 0 {
+		var err error
+		if token, err = gcsMetadataToken(client, getenv); err != nil {
+			return nil, err
+		}
+	}
+
+	// Objects are uploaded with a POST request of the JSON API.
+	objectURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", baseURL, url.PathEscape(bucket), url.PathEscape(key))
+	if method == http.MethodPut {
+		method = http.MethodPost
+		objectURL = fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+			baseURL, url.PathEscape(bucket), url.QueryEscape(key))
+	}
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	if len(contentType) != 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return doStorageRequest(client, req)
+}
+
+// gcsMetadataToken requests an access token for the default service account
+// from the GCE metadata server.
+func gcsMetadataToken(client *http.Client, getenv func(string) string) (string, error) {
+	host := getenv("GCE_METADATA_HOST")
+	if len(host) == 0 {
+		host = gcsDefaultMetadataHost
+	}
+	req, err := http.NewRequest(http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "GOOGLE_OAUTH_ACCESS_TOKEN is not set and the GCE metadata server is not available")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("received status %d requesting a token from the GCE metadata server", resp.StatusCode)
+	}
+	t := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", errors.Wrap(err, "could not decode the token from the GCE metadata server")
+	}
+	return t.AccessToken, nil
+}
+
+// doStorageRequest sends req and returns the response body. If the status
+// is not 2xx an error with the response body is returned. The error is of
+// kind ErrorKindNotFound for 404 Not Found.
+func doStorageRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NewErrorf(ErrorKindNotFound, "received status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("received status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
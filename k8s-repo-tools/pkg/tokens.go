@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// tokenTransport is an http.RoundTripper that authenticates requests with
+// one of multiple tokens. When the rate limit of the current token is exhausted
+// it rotates to the next token with remaining requests.
+type tokenTransport struct {
+	sync.Mutex
+
+	base    http.RoundTripper
+	tokens  []string
+	current int
+	// resets holds the time at which the rate limit of an exhausted token is reset.
+	resets map[int]time.Time
+	now    func() time.Time
+}
+
+var _ http.RoundTripper = &tokenTransport{}
+
+// newTokenTransport creates a tokenTransport that sends requests using base.
+func newTokenTransport(tokens []string, base http.RoundTripper) *tokenTransport {
+	return &tokenTransport{
+		base:   base,
+		tokens: tokens,
+		resets: map[int]time.Time{},
+		now:    time.Now,
+	}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface. A request that failed because
+// the rate limit of the current token is exhausted is sent again with the next token
+// if the request body can be read again.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		idx, token := t.token()
+		r := req.Clone(req.Context())
+		r.Header.Set("Authorization", "token "+token)
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if err != nil || resp.Header.Get(headerRateLimitRemaining) != "0" {
+			return resp, err
+		}
+		if !t.rotate(idx, resp.Header.Get(headerRateLimitReset)) {
+			return resp, nil
+		}
+
+		// Remove the reset time of the exhausted token from the response, so that the
+		// go-github client does not block the requests that are made with the next token.
+		resp.Header.Del(headerRateLimitReset)
+		if resp.StatusCode < http.StatusBadRequest || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// token returns the index and the value of the current token.
+func (t *tokenTransport) token() (int, string) {
+	t.Lock()
+	defer t.Unlock()
+	return t.current, t.tokens[t.current]
+}
+
+// rotate marks the token at idx as exhausted until the Unix time in reset
+// and selects the next token that is not exhausted. It returns false if
+// all tokens are exhausted.
+func (t *tokenTransport) rotate(idx int, reset string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	resetTime := t.now().Add(time.Hour)
+	if sec, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		resetTime = time.Unix(sec, 0)
+	}
+	t.resets[idx] = resetTime
+
+	// Another request might have rotated the token already.
+	if t.current != idx {
+		return true
+	}
+	now := t.now()
+	for i := 1; i < len(t.tokens); i++ {
+		next := (idx + i) % len(t.tokens)
+		if r, ok := t.resets[next]; ok && now.Before(r) {
+			continue
+		}
+		delete(t.resets, next)
+		t.current = next
+		Warningf("the rate limit of token %d is exhausted until %s, using token %d",
+			idx+1, resetTime.Format(time.RFC3339), next+1)
+		return true
+	}
+	return false
+}
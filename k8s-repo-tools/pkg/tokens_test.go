@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenTransport(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name              string
+		tokens            []string
+		exhausted         map[string]bool
+		method            string
+		expectedTokens    []string
+		expectedRequests  int
+		expectedError     bool
+		expectedErrorKind ErrorKind
+	}{
+		{
+			name:             "valid: first token is not exhausted",
+			tokens:           []string{"a", "b"},
+			method:           http.MethodGet,
+			expectedTokens:   []string{"a", "a"},
+			expectedRequests: 2,
+		},
+		{
+			name:             "valid: rotate to the next token",
+			tokens:           []string{"a", "b", "c"},
+			exhausted:        map[string]bool{"a": true},
+			method:           http.MethodGet,
+			expectedTokens:   []string{"a", "b", "b"},
+			expectedRequests: 3,
+		},
+		{
+			name:             "valid: rotate over multiple exhausted tokens",
+			tokens:           []string{"a", "b", "c"},
+			exhausted:        map[string]bool{"a": true, "b": true},
+			method:           http.MethodGet,
+			expectedTokens:   []string{"a", "b", "c", "c"},
+			expectedRequests: 4,
+		},
+		{
+			name:             "valid: the request body is sent again with the next token",
+			tokens:           []string{"a", "b"},
+			exhausted:        map[string]bool{"a": true},
+			method:           http.MethodPost,
+			expectedTokens:   []string{"a", "b", "b"},
+			expectedRequests: 3,
+		},
+		{
+			name:              "invalid: all tokens are exhausted",
+			tokens:            []string{"a", "b"},
+			exhausted:         map[string]bool{"a": true, "b": true},
+			method:            http.MethodGet,
+			expectedTokens:    []string{"a", "b"},
+			expectedRequests:  2,
+			expectedError:     true,
+			expectedErrorKind: ErrorKindRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset := time.Now().Add(time.Hour).Unix()
+			tokens := []string{}
+			transport := NewTransport()
+			transport.SetHandler("https://api.github.com/repos/org/dest/git/refs", func(req *http.Request) (*http.Response, error) {
+				token := strings.TrimPrefix(req.Header.Get("Authorization"), "token ")
+				tokens = append(tokens, token)
+				if req.Method == http.MethodPost {
+					body, err := ioutil.ReadAll(req.Body)
+					if err != nil || !strings.Contains(string(body), "refs/tags/v1.0.0") {
+						t.Errorf("unexpected request body %q, error: %v", body, err)
+					}
+				}
+				header := http.Header{}
+				header.Set(headerRateLimitReset, strconv.FormatInt(reset, 10))
+				if tt.exhausted[token] {
+					header.Set(headerRateLimitRemaining, "0")
+					return &http.Response{
+						StatusCode: http.StatusForbidden,
+						Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"API rate limit exceeded for user"}`)),
+						Header:     header,
+						Request:    req,
+					}, nil
+				}
+				header.Set(headerRateLimitRemaining, "10")
+				body := `{"ref":"refs/tags/v1.0.0","object":{"sha":"sha"}}`
+				if req.Method == http.MethodGet {
+					body = "[" + body + "]"
+				}
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+					Header:     header,
+					Request:    req,
+				}, nil
+			})
+
+			d := &Data{Tokens: tt.tokens, Token: tt.tokens[0], Timeout: time.Second}
+			NewClient(d, transport)
+
+			var err error
+			for i := 0; i < 2 && err == nil; i++ {
+				if tt.method == http.MethodGet {
+					_, err = GitHubGetTags(d, "org/dest")
+				} else {
+					_, err = GitHubCreateRef(d, "org/dest", "refs/tags/v1.0.0", "sha", false)
+				}
+			}
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if kind := ErrorKindOf(err); tt.expectedError && kind != tt.expectedErrorKind {
+				t.Errorf("expected error kind %q, got %q", tt.expectedErrorKind, kind)
+			}
+			if len(tokens) != tt.expectedRequests || !reflect.DeepEqual(tokens, tt.expectedTokens) {
+				t.Errorf("expected tokens %v, got %v", tt.expectedTokens, tokens)
+			}
+		})
+	}
+}
+
+func TestTokenTransportRotate(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	now := time.Unix(1000, 0)
+	tt := newTokenTransport([]string{"a", "b"}, nil)
+	tt.now = func() time.Time { return now }
+
+	if !tt.rotate(0, "2000") {
+		t.Fatal("expected rotation to the second token")
+	}
+	if idx, _ := tt.token(); idx != 1 {
+		t.Fatalf("expected token 1, got %d", idx)
+	}
+	if tt.rotate(1, "3000") {
+		t.Fatal("expected no rotation if all tokens are exhausted")
+	}
+
+	// The first token can be used again after its reset time.
+	now = time.Unix(2500, 0)
+	if !tt.rotate(1, "3000") {
+		t.Fatal("expected rotation to the first token after its reset time")
+	}
+	if idx, _ := tt.token(); idx != 0 {
+		t.Fatalf("expected token 0, got %d", idx)
+	}
+}
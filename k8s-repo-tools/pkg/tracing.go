@@ -0,0 +1,325 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// tracingScope is the instrumentation scope of all spans.
+	tracingScope = "k8s.io/kubeadm/k8s-repo-tools"
+	// tracingExportTimeout is the timeout for exporting the spans.
+	tracingExportTimeout = 30 * time.Second
+
+	// The kinds and status codes of spans in OTLP.
+	spanKindInternal = 1
+	spanKindClient   = 3
+	spanStatusOK     = 1
+	spanStatusError  = 2
+)
+
+// runTracer records the spans of a tool run, which are exported with OTLP
+// over HTTP in the JSON encoding when the tool exits. The run has a root span
+// and all other spans, such as the GitHub API calls, are its children.
+type runTracer struct {
+	sync.Mutex
+
+	tool     string
+	endpoint string
+	traceID  string
+	root     *span
+	spans    []*span
+	finish   sync.Once
+}
+
+// span is a single timed operation of a run.
+type span struct {
+	tracer   *runTracer
+	id       string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	status   int
+	message  string
+}
+
+var (
+	// tracer is the tracer of the current run or nil if tracing is disabled.
+	tracer *runTracer
+	// tracingNow returns the current time and can be replaced in tests.
+	tracingNow = time.Now
+)
+
+// newTraceID returns a random hex encoded ID of n bytes.
+func newTraceID(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// applyTracing enables tracing if --otlp-endpoint is set.
+func applyTracing(fs *flag.FlagSet) error {
+	f := fs.Lookup(FlagOTLPEndpoint)
+	if f == nil || len(f.Value.String()) == 0 {
+		return nil
+	}
+	endpoint := f.Value.String()
+	if !isValidURL(endpoint) {
+		return errors.Errorf("the option %q must be an HTTP or HTTPS URL", FlagOTLPEndpoint)
+	}
+	t := &runTracer{
+		tool:     filepath.Base(fs.Name()),
+		endpoint: strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		traceID:  newTraceID(16),
+	}
+	t.root = t.startSpan(nil, t.tool, spanKindInternal)
+	tracer = t
+	return nil
+}
+
+// startSpan starts a span as a child of the root span of the run. It returns
+// nil if tracing is disabled, which is a valid span that records nothing.
+func startSpan(name string, kind int) *span {
+	t := tracer
+	if t == nil {
+		return nil
+	}
+	return t.startSpan(t.root, name, kind)
+}
+
+func (t *runTracer) startSpan(parent *span, name string, kind int) *span {
+	s := &span{
+		tracer: t,
+		id:     newTraceID(8),
+		name:   name,
+		kind:   kind,
+		start:  tracingNow(),
+		attrs:  map[string]interface{}{},
+	}
+	if parent != nil {
+		s.parentID = parent.id
+	}
+	return s
+}
+
+// setAttribute sets an attribute of the span. The value must be a string,
+// an int or a bool.
+func (s *span) setAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attrs[key] = value
+}
+
+// finish ends the span with an error status if err is not nil.
+func (s *span) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.end = tracingNow()
+	s.status = spanStatusOK
+	if err != nil {
+		s.status = spanStatusError
+		s.message = err.Error()
+	}
+	s.tracer.Lock()
+	defer s.tracer.Unlock()
+	s.tracer.spans = append(s.tracer.spans, s)
+}
+
+// tracingTransport is an http.RoundTripper that records a span for every
+// GitHub API request, including every page of a list and every retry.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+var _ http.RoundTripper = &tracingTransport{}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := startSpan(fmt.Sprintf("%s %s", req.Method, req.URL.Path), spanKindClient)
+	s.setAttribute("http.method", req.Method)
+	s.setAttribute("http.url", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path)
+	if page := req.URL.Query().Get("page"); len(page) != 0 {
+		s.setAttribute("github.page", page)
+	}
+	if req.ContentLength > 0 {
+		s.setAttribute("http.request_content_length", int(req.ContentLength))
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		s.setAttribute("http.status_code", resp.StatusCode)
+		if remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining)); err == nil {
+			s.setAttribute("github.rate_limit.remaining", remaining)
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			s.finish(errors.Errorf("received status %d", resp.StatusCode))
+			return resp, err
+		}
+	}
+	s.finish(err)
+	return resp, err
+}
+
+// otlpAttribute is a key value pair in the OTLP JSON encoding.
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// otlpSpan is a span in the OTLP JSON encoding. IDs are hex encoded and
+// timestamps are strings with the Unix time in nanoseconds.
+type otlpSpan struct {
+	TraceID           string                 `json:"traceId"`
+	SpanID            string                 `json:"spanId"`
+	ParentSpanID      string                 `json:"parentSpanId,omitempty"`
+	Name              string                 `json:"name"`
+	Kind              int                    `json:"kind"`
+	StartTimeUnixNano string                 `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string                 `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute        `json:"attributes,omitempty"`
+	Status            map[string]interface{} `json:"status"`
+}
+
+// otlpAttributes converts attributes to the OTLP JSON encoding sorted by key.
+func otlpAttributes(attrs map[string]interface{}) []otlpAttribute {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]otlpAttribute, 0, len(keys))
+	for _, k := range keys {
+		var v map[string]interface{}
+		switch value := attrs[k].(type) {
+		case int:
+			// 64 bit integers are strings in the JSON encoding.
+			v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+		case bool:
+			v = map[string]interface{}{"boolValue": value}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+		}
+		result = append(result, otlpAttribute{Key: k, Value: v})
+	}
+	return result
+}
+
+// export returns the OTLP JSON request with all finished spans.
+func (t *runTracer) export() ([]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+	spans := make([]otlpSpan, len(t.spans))
+	for i, s := range t.spans {
+		status := map[string]interface{}{"code": s.status}
+		if len(s.message) != 0 {
+			status["message"] = s.message
+		}
+		spans[i] = otlpSpan{
+			TraceID:           t.traceID,
+			SpanID:            s.id,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attrs),
+			Status:            status,
+		}
+	}
+	return json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{
+						"service.name":    t.tool,
+						"service.version": GetVersionInfo().GitVersion,
+					}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": tracingScope},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	})
+}
+
+// write sends the spans to the OTLP endpoint.
+func (t *runTracer) write(data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "could not create the OTLP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := NewHTTPClient(tracingExportTimeout).Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "could not export the traces to %q", t.endpoint)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("could not export the traces to %q: received status %d", t.endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// finishTracing ends the root span of the run with its exit code and error and
+// exports all spans if tracing is enabled. Only the first call has an effect.
+// Failing to export the spans does not fail the run and only logs a warning.
+func finishTracing(exitCode int, err error) {
+	t := tracer
+	if t == nil {
+		return
+	}
+	t.finish.Do(func() {
+		t.root.setAttribute("process.exit_code", exitCode)
+		if err == nil && exitCode != 0 {
+			err = errors.Errorf("exit status %d", exitCode)
+		}
+		if err != nil {
+			t.root.setAttribute("error.kind", string(ErrorKindOf(err)))
+		}
+		t.root.finish(err)
+		data, exportErr := t.export()
+		if exportErr == nil {
+			exportErr = t.write(data)
+		}
+		if exportErr != nil {
+			Warningf("%v", exportErr)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestRunTracer(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+	defer func(now func() time.Time) { tracingNow = now }(tracingNow)
+	defer func() { tracer = nil }()
+
+	// exported is the subset of the OTLP JSON request that is compared.
+	type exportedSpan struct {
+		Name       string
+		Root       bool
+		Attributes map[string]interface{}
+		Status     map[string]interface{}
+	}
+
+	tests := []struct {
+		name          string
+		exitCode      int
+		err           error
+		expectedSpans []exportedSpan
+	}{
+		{
+			name: "valid: successful run",
+			expectedSpans: []exportedSpan{
+				{
+					Name: "GET /repos/org/repo/git/refs/tags/v1.0.0",
+					Attributes: map[string]interface{}{
+						"http.method":                 map[string]interface{}{"stringValue": "GET"},
+						"http.url":                    map[string]interface{}{"stringValue": "https://api.github.com/repos/org/repo/git/refs/tags/v1.0.0"},
+						"http.status_code":            map[string]interface{}{"intValue": "200"},
+						"github.rate_limit.remaining": map[string]interface{}{"intValue": "4321"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusOK)},
+				},
+				{
+					Name: "GET /repos/org/repo/git/refs/tags/v2.0.0",
+					Attributes: map[string]interface{}{
+						"http.method":                 map[string]interface{}{"stringValue": "GET"},
+						"http.url":                    map[string]interface{}{"stringValue": "https://api.github.com/repos/org/repo/git/refs/tags/v2.0.0"},
+						"http.status_code":            map[string]interface{}{"intValue": "404"},
+						"github.rate_limit.remaining": map[string]interface{}{"intValue": "4321"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusError), "message": "received status 404"},
+				},
+				{
+					Name: "k8s-repo-sync",
+					Root: true,
+					Attributes: map[string]interface{}{
+						"process.exit_code": map[string]interface{}{"intValue": "0"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusOK)},
+				},
+			},
+		},
+		{
+			name:     "valid: failed run",
+			exitCode: 1,
+			err:      NewErrorf(ErrorKindRateLimited, "rate limited"),
+			expectedSpans: []exportedSpan{
+				{
+					Name: "GET /repos/org/repo/git/refs/tags/v1.0.0",
+					Attributes: map[string]interface{}{
+						"http.method":                 map[string]interface{}{"stringValue": "GET"},
+						"http.url":                    map[string]interface{}{"stringValue": "https://api.github.com/repos/org/repo/git/refs/tags/v1.0.0"},
+						"http.status_code":            map[string]interface{}{"intValue": "200"},
+						"github.rate_limit.remaining": map[string]interface{}{"intValue": "4321"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusOK)},
+				},
+				{
+					Name: "GET /repos/org/repo/git/refs/tags/v2.0.0",
+					Attributes: map[string]interface{}{
+						"http.method":                 map[string]interface{}{"stringValue": "GET"},
+						"http.url":                    map[string]interface{}{"stringValue": "https://api.github.com/repos/org/repo/git/refs/tags/v2.0.0"},
+						"http.status_code":            map[string]interface{}{"intValue": "404"},
+						"github.rate_limit.remaining": map[string]interface{}{"intValue": "4321"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusError), "message": "received status 404"},
+				},
+				{
+					Name: "k8s-repo-sync",
+					Root: true,
+					Attributes: map[string]interface{}{
+						"process.exit_code": map[string]interface{}{"intValue": "1"},
+						"error.kind":        map[string]interface{}{"stringValue": "RateLimited"},
+					},
+					Status: map[string]interface{}{"code": float64(spanStatusError), "message": "rate limited"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exported []byte
+			var exportPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				exportPath = req.URL.Path
+				exported, _ = ioutil.ReadAll(req.Body)
+			}))
+			defer server.Close()
+
+			fs := flag.NewFlagSet("k8s-repo-sync", flag.ContinueOnError)
+			fs.String(FlagOTLPEndpoint, server.URL+"/", "")
+			tracingNow = func() time.Time { return time.Unix(1600000000, 0) }
+			if err := applyTracing(fs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			refs := []*github.Reference{newTestRef("refs/tags/v1.0.0", "sha0", "commit")}
+			d := &Data{Timeout: time.Minute}
+			NewClient(d, NewTransport())
+			d.Transport.SetHandler("https://api.github.com/repos/org/repo/git/refs", func(req *http.Request) (*http.Response, error) {
+				resp, err := NewReferenceHandler(&refs, nil)(req)
+				if resp != nil {
+					resp.Header.Set(headerRateLimitRemaining, "4321")
+				}
+				return resp, err
+			})
+			if _, err := GitHubGetRef(d, "org/repo", "refs/tags/v1.0.0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := GitHubGetRef(d, "org/repo", "refs/tags/v2.0.0"); err == nil {
+				t.Fatal("expected error")
+			}
+
+			FinishRun(tt.exitCode, tt.err)
+			// Only the first call exports the spans.
+			FinishRun(0, nil)
+
+			if exportPath != "/v1/traces" {
+				t.Errorf("expected the spans to be exported to %q, got %q", "/v1/traces", exportPath)
+			}
+			var request struct {
+				ResourceSpans []struct {
+					ScopeSpans []struct {
+						Spans []struct {
+							TraceID      string
+							SpanID       string
+							ParentSpanID string
+							Name         string
+							Attributes   []otlpAttribute
+							Status       map[string]interface{}
+						}
+					}
+				}
+			}
+			if err := json.Unmarshal(exported, &request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+			got := make([]exportedSpan, len(spans))
+			for i, s := range spans {
+				if s.TraceID != tracer.traceID {
+					t.Errorf("expected trace ID %q, got %q", tracer.traceID, s.TraceID)
+				}
+				if len(s.ParentSpanID) != 0 && s.ParentSpanID != tracer.root.id {
+					t.Errorf("expected parent span ID %q, got %q", tracer.root.id, s.ParentSpanID)
+				}
+				got[i] = exportedSpan{Name: s.Name, Root: len(s.ParentSpanID) == 0, Attributes: map[string]interface{}{}, Status: s.Status}
+				for _, a := range s.Attributes {
+					got[i].Attributes[a.Key] = a.Value
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expectedSpans) {
+				t.Errorf("expected spans:\n%+v\ngot:\n%+v", tt.expectedSpans, got)
+			}
+		})
+	}
+}
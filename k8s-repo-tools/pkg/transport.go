@@ -19,11 +19,14 @@ package pkg
 import (
 	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/v29/github"
@@ -45,11 +48,14 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	var fn HTTPHandler
 
 	t.RLock()
-	// Find an endpoint handler.
+	// Find an endpoint handler. The handler with the longest matching
+	// URL is used, so that a handler for a repository does not match
+	// the URLs of other handlers for the same repository.
+	var match string
 	for k, v := range t.handlers {
-		if strings.HasPrefix(url, k) {
+		if strings.HasPrefix(url, k) && len(k) > len(match) {
+			match = k
 			fn = v
-			break
 		}
 	}
 	t.RUnlock()
@@ -70,18 +76,67 @@ func NewTransport() *Transport {
 // requests and responses that the go-github library otherwise does.
 func NewClient(d *Data, t *Transport) {
 	// create an ouath2 client with token authorization.
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: d.Token},
-	)
-	httpClient := oauth2.NewClient(context.Background(), ts)
+	// Without a token only unauthenticated requests are possible.
+	// With multiple tokens the tokens are rotated when the rate limit
+	// of the current token is exhausted.
+	httpClient := NewHTTPClient(0)
+	switch {
+	case len(d.Tokens) > 1:
+		base := httpClient.Transport
+		if t != nil {
+			base = t
+		}
+		httpClient.Transport = newTokenTransport(d.Tokens, base)
+	case len(d.Token) != 0:
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: d.Token},
+		)
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+		httpClient = oauth2.NewClient(ctx, ts)
+	}
 
 	// Override the HTTP client transport.
 	if t != nil {
-		httpClient.Transport = t
+		if len(d.Tokens) <= 1 {
+			httpClient.Transport = t
+		}
 		d.Transport = t
 	}
 
-	d.client = github.NewClient(httpClient)
+	// Record the API requests in the metrics of the run.
+	if metrics != nil {
+		httpClient.Transport = &metricsTransport{base: httpClient.Transport, metrics: metrics}
+	}
+
+	// Record a span for every API request if tracing is enabled.
+	if tracer != nil {
+		httpClient.Transport = &tracingTransport{base: httpClient.Transport}
+	}
+
+	// Follow the redirects for renamed repositories.
+	httpClient.Transport = newRedirectTransport(httpClient.Transport, d.Strict)
+
+	// Refuse writes to repositories that are not allowed.
+	if patterns := SplitRepos(d.AllowedDest); len(patterns) != 0 {
+		httpClient.Transport = newAllowedDestTransport(httpClient.Transport, patterns)
+	}
+
+	// Use a GitHub Enterprise Server instance if a base URL is set.
+	if len(d.GitHubBaseURL) != 0 {
+		uploadURL := d.GitHubUploadURL
+		if len(uploadURL) == 0 {
+			uploadURL = d.GitHubBaseURL
+		}
+		client, err := github.NewEnterpriseClient(d.GitHubBaseURL, uploadURL, httpClient)
+		if err != nil {
+			PrintErrorAndExit(errors.Wrap(err, "could not create a GitHub Enterprise Server client"))
+		}
+		d.client = NewGitHubClient(client)
+		d.httpClient = httpClient
+		return
+	}
+	d.client = NewGitHubClient(github.NewClient(httpClient))
+	d.httpClient = httpClient
 }
 
 // NewReferenceHandler creates a HTTPHandler function that manages a list of GitHub References.
@@ -135,7 +190,6 @@ func NewReferenceHandler(refs *[]*github.Reference, methodErrors map[string]bool
 			}
 
 			// Simulate a GET by writing the list of filtered refs to the response body.
-			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
 			var buf []byte
 			var err error
 			if foundSpecificRef {
@@ -147,10 +201,23 @@ func NewReferenceHandler(refs *[]*github.Reference, methodErrors map[string]bool
 				return nil, err
 			}
 
+			// Simulate conditional requests with an ETag that is the checksum of the body.
+			etag := fmt.Sprintf("%q", fmt.Sprintf("%x", sha1.Sum(buf)))
+			if req.Header.Get("If-None-Match") == etag {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotModified, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotModified,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(""))),
+					Header:     http.Header{"Etag": []string{etag}},
+				}, nil
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
 			return &http.Response{
 				StatusCode: http.StatusOK,
 				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
-				Header:     http.Header{},
+				Header:     http.Header{"Etag": []string{etag}},
 			}, nil
 
 		case http.MethodPost: // Handle POST
@@ -165,6 +232,19 @@ func NewReferenceHandler(refs *[]*github.Reference, methodErrors map[string]bool
 			if err := json.Unmarshal(body, &r); err != nil {
 				return nil, err
 			}
+			// Refs that already exist are rejected like in the GitHub API.
+			for _, ref := range *refs {
+				if ref.GetRef() != r.Ref {
+					continue
+				}
+				Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusUnprocessableEntity, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusUnprocessableEntity,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{"message":"Reference already exists"}`))),
+					Header:     http.Header{},
+				}, nil
+			}
 			newRef := &github.Reference{
 				Ref: github.String(r.Ref),
 				Object: &github.GitObject{
@@ -183,6 +263,66 @@ func NewReferenceHandler(refs *[]*github.Reference, methodErrors map[string]bool
 				Header:     http.Header{},
 			}, nil
 
+		case http.MethodPatch: // Handle PATCH
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			r := referenceSubset{}
+			if err := json.Unmarshal(body, &r); err != nil {
+				return nil, err
+			}
+
+			// Simulate a PATCH by updating the SHA of the ref in the managed list of refs.
+			specificRef := strings.Split(url, "git/")[1]
+			for _, ref := range *refs {
+				if ref.GetRef() != specificRef {
+					continue
+				}
+				Logf("simulating method %q with status %d to URL %q with; ref %q with sha %q",
+					req.Method, http.StatusOK, url, specificRef, r.SHA)
+				ref.Object = &github.GitObject{SHA: github.String(r.SHA)}
+				buf, err := json.Marshal(ref)
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+					Header:     http.Header{},
+				}, nil
+			}
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusUnprocessableEntity, url)
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusUnprocessableEntity,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+				Header:     http.Header{},
+			}, nil
+
+		case http.MethodDelete: // Handle DELETE
+			// Simulate a DELETE by removing the ref from the managed list of refs.
+			specificRef := strings.Split(url, "git/")[1]
+			for i, ref := range *refs {
+				if ref.GetRef() != specificRef {
+					continue
+				}
+				Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusNoContent, url)
+				*refs = append((*refs)[:i], (*refs)[i+1:]...)
+				return &http.Response{
+					StatusCode: http.StatusNoContent,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte{})),
+					Header:     http.Header{},
+				}, nil
+			}
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusUnprocessableEntity, url)
+			return &http.Response{
+				Request:    req,
+				StatusCode: http.StatusUnprocessableEntity,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+				Header:     http.Header{},
+			}, nil
+
 		default:
 			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
 		}
@@ -220,6 +360,7 @@ func NewCompareHandler(commitsA, commitsB *[]*github.RepositoryCommit, methodErr
 					}
 					cmp = &github.CommitsComparison{
 						Status:  github.String("ahead"),
+						AheadBy: github.Int(len(commits)),
 						Commits: commits,
 					}
 				} else {
@@ -227,8 +368,9 @@ func NewCompareHandler(commitsA, commitsB *[]*github.RepositoryCommit, methodErr
 						commits = append(commits, *(*commitsB)[i])
 					}
 					cmp = &github.CommitsComparison{
-						Status:  github.String("behind"),
-						Commits: commits,
+						Status:   github.String("behind"),
+						BehindBy: github.Int(len(commits)),
+						Commits:  commits,
 					}
 				}
 			}
@@ -308,7 +450,21 @@ func NewReleaseHandler(releases *[]*github.RepositoryRelease, methodErrors map[s
 		switch req.Method {
 		case http.MethodGet: // Handle GET
 
-			requestedTag := strings.Split(url, "tags/")[1]
+			// Return the list of releases if the URL is not of the format ".../releases/tags/<tag>".
+			tagSplit := strings.Split(url, "tags/")
+			if len(tagSplit) != 2 {
+				buf, err := json.Marshal(*releases)
+				if err != nil {
+					return nil, err
+				}
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+					Header:     http.Header{},
+				}, nil
+			}
+			requestedTag := tagSplit[1]
 
 			var release *github.RepositoryRelease
 			for _, rel := range *releases {
@@ -321,6 +477,7 @@ func NewReleaseHandler(releases *[]*github.RepositoryRelease, methodErrors map[s
 			if release == nil {
 				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
 				return &http.Response{
+					Request:    req,
 					StatusCode: http.StatusNotFound,
 					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{"message":"","documentation_url":""}`))),
 					Header:     http.Header{},
@@ -430,3 +587,1152 @@ func NewReleaseAssetsHandler(release *github.RepositoryRelease, methodErrors map
 		}
 	}
 }
+
+// NewReleaseAssetDownloadHandler creates a HTTPHandler function that returns the contents
+// of release assets by their ID.
+func NewReleaseAssetDownloadHandler(contents map[int64][]byte, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			var data []byte
+			var found bool
+			if id := strings.Split(req.URL.Path, "assets/"); len(id) == 2 {
+				if n, err := strconv.ParseInt(id[1], 10, 64); err == nil {
+					data, found = contents[n]
+				}
+			}
+			if !found {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+					Header:     http.Header{},
+				}, nil
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(data)),
+				Header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewIssueCommentHandler creates a HTTPHandler function that manages a list of GitHub IssueComments.
+func NewIssueCommentHandler(comments *[]*github.IssueComment, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodPost: // Handle POST
+
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			comment := &github.IssueComment{}
+			if err := json.Unmarshal(body, comment); err != nil {
+				return nil, err
+			}
+
+			// Simulate a POST by appending to the managed list of comments.
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusCreated, url)
+			*comments = append(*comments, comment)
+
+			buf, err := json.Marshal(comment)
+			if err != nil {
+				return nil, err
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewIssueHandler creates a HTTPHandler function that manages a list of GitHub Issues.
+// Listing issues supports filtering by the "labels" query parameter.
+func NewIssueHandler(issues *[]*github.Issue, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		var result interface{}
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			list := []*github.Issue{}
+			var labels []string
+			if query := req.URL.Query().Get("labels"); len(query) != 0 {
+				labels = strings.Split(query, ",")
+			}
+			for _, issue := range *issues {
+				names := map[string]bool{}
+				for _, l := range issue.Labels {
+					names[l.GetName()] = true
+				}
+				match := true
+				for _, l := range labels {
+					match = match && names[l]
+				}
+				if match {
+					list = append(list, issue)
+				}
+			}
+			result = list
+
+		case http.MethodPost, http.MethodPatch: // Handle POST and PATCH
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			request := &github.IssueRequest{}
+			if err := json.Unmarshal(body, request); err != nil {
+				return nil, err
+			}
+
+			if req.Method == http.MethodPost {
+				// Simulate a POST by appending to the managed list of issues.
+				issue := &github.Issue{
+					Number: github.Int(len(*issues) + 1),
+					Title:  request.Title,
+					Body:   request.Body,
+					State:  github.String("open"),
+				}
+				if request.Labels != nil {
+					for _, l := range *request.Labels {
+						issue.Labels = append(issue.Labels, github.Label{Name: github.String(l)})
+					}
+				}
+				*issues = append(*issues, issue)
+				status = http.StatusCreated
+				result = issue
+			} else {
+				// Simulate a PATCH by updating the issue with the number from
+				// the URL of the format ".../issues/<number>".
+				number := strings.Split(req.URL.Path, "issues/")[1]
+				for _, existing := range *issues {
+					if strconv.Itoa(existing.GetNumber()) != number {
+						continue
+					}
+					if request.Title != nil {
+						existing.Title = request.Title
+					}
+					if request.State != nil {
+						existing.State = request.State
+					}
+					result = existing
+				}
+			}
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewWorkflowRunHandler creates a HTTPHandler function that manages a list of GitHub
+// Actions workflow runs. Listing runs supports filtering by the "branch" query parameter.
+// Re-running a run sets its status to "queued".
+func NewWorkflowRunHandler(runs *[]*WorkflowRun, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		var result interface{}
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			list := &workflowRuns{WorkflowRuns: []*WorkflowRun{}}
+			branch := req.URL.Query().Get("branch")
+			for _, run := range *runs {
+				if len(branch) == 0 || run.HeadBranch == branch {
+					list.WorkflowRuns = append(list.WorkflowRuns, run)
+				}
+			}
+			list.TotalCount = len(list.WorkflowRuns)
+			result = list
+
+		case http.MethodPost: // Handle POST
+			// Simulate a re-run by updating the run with the ID from
+			// the URL of the format ".../runs/<id>/rerun".
+			id := strings.TrimSuffix(strings.Split(req.URL.Path, "runs/")[1], "/rerun")
+			for _, run := range *runs {
+				if strconv.FormatInt(run.ID, 10) == id {
+					run.Status = "queued"
+					run.Conclusion = ""
+				}
+			}
+			status = http.StatusCreated
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewCheckSuiteHandler creates a HTTPHandler function that manages a list of GitHub
+// check suites. Listing check suites returns the suites whose head branch or head SHA
+// matches the ref from the URL. Re-requesting a suite sets its status to "queued".
+func NewCheckSuiteHandler(suites *[]*github.CheckSuite, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		var result interface{}
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			// The URL is of the format ".../commits/<ref>/check-suites".
+			ref := strings.TrimSuffix(strings.Split(req.URL.Path, "commits/")[1], "/check-suites")
+			list := &github.ListCheckSuiteResults{CheckSuites: []*github.CheckSuite{}}
+			for _, suite := range *suites {
+				if suite.GetHeadBranch() == ref || suite.GetHeadSHA() == ref {
+					list.CheckSuites = append(list.CheckSuites, suite)
+				}
+			}
+			list.Total = github.Int(len(list.CheckSuites))
+			result = list
+
+		case http.MethodPost: // Handle POST
+			// Simulate a re-request by updating the suite with the ID from
+			// the URL of the format ".../check-suites/<id>/rerequest".
+			id := strings.TrimSuffix(strings.Split(req.URL.Path, "check-suites/")[1], "/rerequest")
+			for _, suite := range *suites {
+				if strconv.FormatInt(suite.GetID(), 10) == id {
+					suite.Status = github.String("queued")
+					suite.Conclusion = nil
+				}
+			}
+			status = http.StatusCreated
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewRepositoryHandler creates a HTTPHandler function that returns a GitHub Repository.
+func NewRepositoryHandler(repo *github.Repository, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			buf, err := json.Marshal(repo)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewMovedRepositoryHandler creates a HTTPHandler function that answers all requests
+// with 301 Moved Permanently, like the GitHub API does for renamed repositories.
+// The location is the URL of the request with the prefix from replaced by to.
+func NewMovedRepositoryHandler(from, to string) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+		location := to + strings.TrimPrefix(url, from)
+		buf, err := json.Marshal(map[string]string{"message": "Moved Permanently", "url": location})
+		if err != nil {
+			return nil, err
+		}
+
+		Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusMovedPermanently, url)
+		return &http.Response{
+			Request:    req,
+			StatusCode: http.StatusMovedPermanently,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{"Location": []string{location}},
+		}, nil
+	}
+}
+
+// NewBranchProtectionHandler creates a HTTPHandler function that manages a list of protected
+// branch names.
+func NewBranchProtectionHandler(branches *[]string, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodPut: // Handle PUT
+			// The URL is of the format ".../branches/<branch>/protection".
+			branch := strings.TrimSuffix(strings.Split(url, "branches/")[1], "/protection")
+
+			// Simulate a PUT by appending to the managed list of protected branches.
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusOK, url)
+			*branches = append(*branches, branch)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewProtectionHandler creates a HTTPHandler function that returns the protection of
+// branches. Branches that are not in the map are not protected.
+func NewProtectionHandler(protections map[string]*github.Protection, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			// The URL is of the format ".../branches/<branch>/protection".
+			branch := strings.TrimSuffix(strings.Split(url, "branches/")[1], "/protection")
+			protection, ok := protections[branch]
+			if !ok {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte(`{"message":"Branch not protected"}`))),
+					Header:     http.Header{},
+				}, nil
+			}
+			buf, err := json.Marshal(protection)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewHookHandler creates a HTTPHandler function that returns a list of GitHub Hooks.
+func NewHookHandler(hooks []*github.Hook, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			buf, err := json.Marshal(hooks)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewContentsHandler creates a HTTPHandler function that manages a map of file paths to file
+// contents. Committed files are appended to commits.
+func NewContentsHandler(files map[string]string, commits *[]*github.RepositoryContentFileOptions, methodErrors map[string]bool) HTTPHandler {
+	blobSHA := func(content string) string {
+		return fmt.Sprintf("%x", sha1.Sum([]byte(content)))
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		// The URL path is of the format ".../contents/<path>".
+		path := strings.SplitN(req.URL.Path, "contents/", 2)[1]
+		existing, found := files[path]
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			if !found {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+					Header:     http.Header{},
+				}, nil
+			}
+			file := &github.RepositoryContent{
+				Type:     github.String("file"),
+				Path:     github.String(path),
+				SHA:      github.String(blobSHA(existing)),
+				Encoding: github.String("base64"),
+				Content:  github.String(base64.StdEncoding.EncodeToString([]byte(existing))),
+			}
+			buf, err := json.Marshal(file)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		case http.MethodPut: // Handle PUT
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			opt := &github.RepositoryContentFileOptions{}
+			if err := json.Unmarshal(body, opt); err != nil {
+				return nil, err
+			}
+
+			// The SHA of an existing file must match.
+			if found && opt.GetSHA() != blobSHA(existing) || !found && opt.SHA != nil {
+				Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusConflict, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusConflict,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+					Header:     http.Header{},
+				}, nil
+			}
+
+			// Simulate a PUT by updating the file and appending to the list of commits.
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusOK, url)
+			files[path] = string(opt.Content)
+			*commits = append(*commits, opt)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewPullRequestHandler creates a HTTPHandler function that manages a list of GitHub PullRequests.
+func NewPullRequestHandler(prs *[]*github.PullRequest, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			// Filter the list by the "state" query parameter. Pull requests without
+			// a state are open.
+			list := []*github.PullRequest{}
+			for _, pr := range *prs {
+				state := pr.GetState()
+				if len(state) == 0 {
+					state = "open"
+				}
+				if query := req.URL.Query().Get("state"); len(query) == 0 || query == "all" || query == state {
+					list = append(list, pr)
+				}
+			}
+
+			// Return a single pull request if the URL is of the format ".../pulls/<number>".
+			var result interface{} = list
+			if number := strings.Split(req.URL.Path, "pulls/"); len(number) == 2 {
+				result = nil
+				for _, pr := range *prs {
+					if strconv.Itoa(pr.GetNumber()) == number[1] {
+						result = pr
+						break
+					}
+				}
+				if result == nil {
+					Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+					return &http.Response{
+						Request:    req,
+						StatusCode: http.StatusNotFound,
+						Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+						Header:     http.Header{},
+					}, nil
+				}
+			}
+			buf, err := json.Marshal(result)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		case http.MethodPost: // Handle POST
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			newPR := &github.NewPullRequest{}
+			if err := json.Unmarshal(body, newPR); err != nil {
+				return nil, err
+			}
+			pr := &github.PullRequest{
+				Number: github.Int(len(*prs) + 1),
+				Title:  newPR.Title,
+				Body:   newPR.Body,
+				Base:   &github.PullRequestBranch{Ref: newPR.Base},
+				Head:   &github.PullRequestBranch{Ref: newPR.Head},
+			}
+
+			// Simulate a POST by appending to the managed list of pull requests.
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusCreated, url)
+			*prs = append(*prs, pr)
+
+			buf, err := json.Marshal(pr)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewPullRequestCommitsHandler creates a HTTPHandler function that returns the list of commits
+// of a pull request.
+func NewPullRequestCommitsHandler(commits []*github.RepositoryCommit, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			buf, err := json.Marshal(commits)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewGitCommitHandler creates a HTTPHandler function that manages a map of Git commit SHAs
+// to GitHub Commits. Created commits are assigned the SHAs "created-1", "created-2" and so on.
+func NewGitCommitHandler(commits map[string]*github.Commit, methodErrors map[string]bool) HTTPHandler {
+	var created int
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		var commit *github.Commit
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			// The URL is of the format ".../git/commits/<sha>".
+			sha := strings.Split(req.URL.Path, "commits/")[1]
+			var found bool
+			if commit, found = commits[sha]; !found {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+					Header:     http.Header{},
+				}, nil
+			}
+
+		case http.MethodPost: // Handle POST
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			c := commitSubset{}
+			if err := json.Unmarshal(body, &c); err != nil {
+				return nil, err
+			}
+
+			// Simulate a POST by adding the commit to the managed map of commits.
+			created++
+			commit = &github.Commit{
+				SHA:     github.String(fmt.Sprintf("created-%d", created)),
+				Message: github.String(c.Message),
+				Tree:    &github.Tree{SHA: github.String(c.Tree)},
+				Author:  c.Author,
+			}
+			for _, p := range c.Parents {
+				commit.Parents = append(commit.Parents, github.Commit{SHA: github.String(p)})
+			}
+			commits[commit.GetSHA()] = commit
+			status = http.StatusCreated
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		buf, err := json.Marshal(commit)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewGitTagHandler creates a HTTPHandler function that returns annotated Git tags
+// from a map of tag object SHAs to GitHub Tags.
+func NewGitTagHandler(tags map[string]*github.Tag, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			// The URL is of the format ".../git/tags/<sha>".
+			sha := strings.Split(req.URL.Path, "git/tags/")[1]
+			tag, found := tags[sha]
+			if !found {
+				Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusNotFound, url)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+					Header:     http.Header{},
+				}, nil
+			}
+			buf, err := json.Marshal(tag)
+			if err != nil {
+				return nil, err
+			}
+
+			Logf("simulating method %q with status %d from URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewIssueLabelsHandler creates a HTTPHandler function that manages a list of labels of an issue.
+func NewIssueLabelsHandler(labels *[]string, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		switch req.Method {
+		case http.MethodPost: // Handle POST
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			newLabels := []string{}
+			if err := json.Unmarshal(body, &newLabels); err != nil {
+				return nil, err
+			}
+
+			// Simulate a POST by appending to the managed list of labels.
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusOK, url)
+			*labels = append(*labels, newLabels...)
+
+			result := []*github.Label{}
+			for _, l := range *labels {
+				result = append(result, &github.Label{Name: github.String(l)})
+			}
+			buf, err := json.Marshal(result)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+	}
+}
+
+// NewMilestoneHandler creates a HTTPHandler function that manages a list of GitHub Milestones.
+func NewMilestoneHandler(milestones *[]*github.Milestone, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		var result interface{}
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			result = *milestones
+
+		case http.MethodPost, http.MethodPatch: // Handle POST and PATCH
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			m := &github.Milestone{}
+			if err := json.Unmarshal(body, m); err != nil {
+				return nil, err
+			}
+
+			if req.Method == http.MethodPost {
+				// Simulate a POST by appending to the managed list of milestones.
+				m.Number = github.Int(len(*milestones) + 1)
+				*milestones = append(*milestones, m)
+				status = http.StatusCreated
+			} else {
+				// Simulate a PATCH by replacing the milestone with the number from
+				// the URL of the format ".../milestones/<number>".
+				number := strings.Split(req.URL.Path, "milestones/")[1]
+				for i, existing := range *milestones {
+					if strconv.Itoa(existing.GetNumber()) == number {
+						m.Number = existing.Number
+						(*milestones)[i] = m
+					}
+				}
+			}
+			result = m
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewLabelHandler creates a HTTPHandler function that manages a list of GitHub Labels.
+func NewLabelHandler(labels *[]*github.Label, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+
+		// The URL path of a single label is of the format ".../labels/<name>".
+		var name string
+		if parts := strings.SplitN(req.URL.Path, "labels/", 2); len(parts) == 2 {
+			name = parts[1]
+		}
+		index := -1
+		for i, l := range *labels {
+			if strings.EqualFold(l.GetName(), name) {
+				index = i
+				break
+			}
+		}
+
+		var result interface{}
+		status := http.StatusOK
+		switch req.Method {
+		case http.MethodGet: // Handle GET
+			result = *labels
+			if len(name) != 0 {
+				if index == -1 {
+					status = http.StatusNotFound
+				} else {
+					result = (*labels)[index]
+				}
+			}
+
+		case http.MethodPost, http.MethodPatch: // Handle POST and PATCH
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			l := &github.Label{}
+			if err := json.Unmarshal(body, l); err != nil {
+				return nil, err
+			}
+			if req.Method == http.MethodPost {
+				// Simulate a POST by appending to the managed list of labels.
+				*labels = append(*labels, l)
+				status = http.StatusCreated
+			} else if index == -1 {
+				status = http.StatusNotFound
+			} else {
+				// Simulate a PATCH by replacing the label.
+				(*labels)[index] = l
+			}
+			result = l
+
+		case http.MethodDelete: // Handle DELETE
+			if index == -1 {
+				status = http.StatusNotFound
+			} else {
+				// Simulate a DELETE by removing the label from the managed list of labels.
+				*labels = append((*labels)[:index], (*labels)[index+1:]...)
+				status = http.StatusNoContent
+			}
+
+		default:
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		if status == http.StatusNotFound {
+			Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+			return &http.Response{
+				Request:    req,
+				StatusCode: status,
+				Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+				Header:     http.Header{},
+			}, nil
+		}
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		Logf("simulating method %q with status %d to URL %q", req.Method, status, url)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+// NewGraphQLRefHandler creates a HTTPHandler function that manages the refs of
+// repositories through the GraphQL queries and mutations of gitHubGetRefsGraphQL
+// and gitHubCreateRefsGraphQL. The keys of repos are in the format 'org/repo'
+// and the ID of a repository is its key.
+func NewGraphQLRefHandler(repos map[string]*[]*github.Reference, methodErrors map[string]bool) HTTPHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		url := req.URL.String()
+
+		// Return an early error if methodErrors matches the Method of this http.Request.
+		if val, ok := methodErrors[req.Method]; ok && val {
+			msg := fmt.Sprintf("simulating error for method %q to URL %q", req.Method, url)
+			Logf(msg)
+			return nil, errors.New(msg)
+		}
+		if req.Method != http.MethodPost {
+			panic(fmt.Sprintf("unhandled HTTP method %q", req.Method))
+		}
+
+		// All inputs of the queries and mutations are passed as variables.
+		body := struct {
+			Query     string                     `json:"query"`
+			Variables map[string]json.RawMessage `json:"variables"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		respond := func(data interface{}, errs ...string) (*http.Response, error) {
+			out := map[string]interface{}{"data": data}
+			if len(errs) != 0 {
+				list := make([]map[string]string, len(errs))
+				for i := range errs {
+					list[i] = map[string]string{"message": errs[i]}
+				}
+				out["errors"] = list
+			}
+			buf, err := json.Marshal(out)
+			if err != nil {
+				return nil, err
+			}
+			Logf("simulating method %q with status %d to URL %q", req.Method, http.StatusOK, url)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(buf)),
+				Header:     http.Header{},
+			}, nil
+		}
+
+		// Simulate createRef mutations by appending to the managed list of refs.
+		if strings.HasPrefix(body.Query, "mutation") {
+			data := map[string]interface{}{}
+			var errs []string
+			for i := 0; ; i++ {
+				raw, ok := body.Variables[fmt.Sprintf("i%d", i)]
+				if !ok {
+					break
+				}
+				input := struct {
+					RepositoryID string `json:"repositoryId"`
+					Name         string `json:"name"`
+					OID          string `json:"oid"`
+				}{}
+				if err := json.Unmarshal(raw, &input); err != nil {
+					return nil, err
+				}
+				refs, ok := repos[input.RepositoryID]
+				if !ok {
+					errs = append(errs, fmt.Sprintf("Could not resolve to a node with the global id of '%s'", input.RepositoryID))
+					continue
+				}
+				exists := false
+				for _, ref := range *refs {
+					if ref.GetRef() == input.Name {
+						exists = true
+						break
+					}
+				}
+				if exists {
+					errs = append(errs, "A ref named \""+input.Name+"\" already exists in the repository.")
+					continue
+				}
+				*refs = append(*refs, &github.Reference{
+					Ref:    github.String(input.Name),
+					Object: &github.GitObject{SHA: github.String(input.OID), Type: github.String("commit")},
+				})
+				data[fmt.Sprintf("r%d", i)] = map[string]interface{}{"ref": map[string]string{"name": input.Name}}
+			}
+			return respond(data, errs...)
+		}
+
+		// Simulate a query for the refs of a repository.
+		var owner, name string
+		var first int
+		json.Unmarshal(body.Variables["owner"], &owner)
+		json.Unmarshal(body.Variables["name"], &name)
+		json.Unmarshal(body.Variables["first"], &first)
+		refs, ok := repos[owner+"/"+name]
+		if !ok {
+			return respond(map[string]interface{}{"repository": nil},
+				fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", owner, name))
+		}
+		repository := map[string]interface{}{"id": owner + "/" + name}
+		// Later pages only include the prefixes that have more refs.
+		for i := 0; i < len(body.Variables); i++ {
+			raw, ok := body.Variables[fmt.Sprintf("p%d", i)]
+			if !ok {
+				continue
+			}
+			var prefix, cursor string
+			json.Unmarshal(raw, &prefix)
+			json.Unmarshal(body.Variables[fmt.Sprintf("c%d", i)], &cursor)
+
+			// The cursor is the index of the first ref of the page.
+			var matched []*github.Reference
+			for _, ref := range *refs {
+				if strings.HasPrefix(ref.GetRef(), prefix) {
+					matched = append(matched, ref)
+				}
+			}
+			start, _ := strconv.Atoi(cursor)
+			end := start + first
+			if end > len(matched) {
+				end = len(matched)
+			}
+			nodes := []map[string]interface{}{}
+			for _, ref := range matched[start:end] {
+				typename := "Commit"
+				if ref.GetObject().GetType() == "tag" {
+					typename = "Tag"
+				}
+				nodes = append(nodes, map[string]interface{}{
+					"name":   strings.TrimPrefix(ref.GetRef(), prefix),
+					"target": map[string]string{"__typename": typename, "oid": ref.GetObject().GetSHA()},
+				})
+			}
+			repository[fmt.Sprintf("r%d", i)] = map[string]interface{}{
+				"nodes":    nodes,
+				"pageInfo": map[string]interface{}{"hasNextPage": end < len(matched), "endCursor": strconv.Itoa(end)},
+			}
+		}
+		return respond(map[string]interface{}{"repository": repository})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +36,21 @@ const (
 	PrefixBranch = "release-"
 	// PrefixDryRun ...
 	PrefixDryRun = "DRY-RUN"
+	// OutputFormatText ...
+	OutputFormatText = "text"
+	// OutputFormatJSON ...
+	OutputFormatJSON = "json"
+	// OutputFormatHTML ...
+	OutputFormatHTML = "html"
+	// DefaultChecksumsAsset ...
+	DefaultChecksumsAsset = "SHA256SUMS"
+	// DefaultRequestTimeout ...
+	DefaultRequestTimeout = 20 * time.Second
+	// DefaultTransferTimeout is the minimum timeout for uploading or downloading
+	// a release asset, which can take much longer than other requests.
+	DefaultTransferTimeout = 30 * time.Minute
+	// DefaultBuildTimeout is the default timeout for a remote build of the release assets.
+	DefaultBuildTimeout = time.Hour
 )
 
 // assetMap is a type that implements the flag.Value interface
@@ -62,6 +78,20 @@ func (m *assetMap) Set(value string) error {
 	return nil
 }
 
+// With returns a copy of m with an additional asset.
+func (m assetMap) With(name, path string) assetMap {
+	result := make(assetMap, len(m)+1)
+	for k, v := range m {
+		result[k] = v
+	}
+	result[name] = path
+	return result
+}
+
+// AssetMap is the exported name of assetMap, for the options structs
+// of the tools.
+type AssetMap = assetMap
+
 // multiString is a type that implements the flag.Value interface
 type multiString []string
 
@@ -74,46 +104,308 @@ func (m *multiString) Set(value string) error {
 	return nil
 }
 
+// MultiString is the exported name of multiString, for the options structs
+// of the tools.
+type MultiString = multiString
+
+// tokenValue is a type that implements the flag.Value interface
+// for one or more tokens. The first token is stored in token and
+// all tokens are stored in tokens.
+type tokenValue struct {
+	token  *string
+	tokens *multiString
+}
+
+func (t tokenValue) String() string {
+	if t.tokens == nil {
+		return ""
+	}
+	return t.tokens.String()
+}
+
+func (t tokenValue) Set(value string) error {
+	if len(*t.tokens) == 0 {
+		*t.token = value
+	}
+	return t.tokens.Set(value)
+}
+
+// reset removes all tokens, so that the next Set replaces them.
+func (t tokenValue) reset() {
+	*t.token = ""
+	*t.tokens = nil
+}
+
+// repoListValue is a type that implements the flag.Value interface
+// for one or more repositories. A value can be a comma separated list.
+// The first value replaces the default and later values are appended,
+// so that passing the flag multiple times is the same as passing a
+// comma separated list.
+type repoListValue struct {
+	value *[]string
+	set   bool
+}
+
+func (r *repoListValue) String() string {
+	if r.value == nil {
+		return ""
+	}
+	return strings.Join(*r.value, ",")
+}
+
+func (r *repoListValue) Set(value string) error {
+	if !r.set {
+		*r.value = nil
+		r.set = true
+	}
+	*r.value = append(*r.value, SplitRepos(value)...)
+	return nil
+}
+
+// urlValue is a type that implements the flag.Value interface
+// for validating that a string is an absolute HTTP(S) URL.
+type urlValue struct {
+	value *string
+}
+
+func (u urlValue) String() string {
+	if u.value == nil {
+		return ""
+	}
+	return *u.value
+}
+
+func (u urlValue) Set(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL %q", value)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+		return errors.Errorf("invalid URL %q. Value must be an absolute HTTP or HTTPS URL", value)
+	}
+	*u.value = value
+	return nil
+}
+
 // Data is the main data structure of the application.
 type Data struct {
 	// From flags
-	Dest                 string
-	Source               string
+	Dest                 []string
+	Source               []string
 	MinVersion           string
 	Token                string
+	TokenFile            string
+	Tokens               multiString
+	GitHubBaseURL        string
+	GitHubUploadURL      string
+	GiteaURL             string
+	GiteaToken           string
+	HTTPSProxy           string
+	CABundle             string
+	AllowedDest          string
+	MetricsFile          string
+	MetricsPushgateway   string
+	OTLPEndpoint         string
 	Branch               string
 	PrefixBranch         string
 	Output               string
 	ReleaseTag           string
 	ReleaseNotesToolPath string
 	ReleaseNotesPath     string
+	PullRequestNotes     bool
+	PullRequestLabels    multiString
+	PullRequestMilestone string
 	ReleaseAssets        assetMap
 	IgnorePaths          multiString
+	Branches             multiString
+	Labels               multiString
+	Inputs               multiString
+	EmailTo              multiString
+	Workflows            multiString
 	BuildCommand         string
+	BuildWorkflow        string
+	BuildArtifactsDir    string
+	OCIRepository        string
+	OCIUsername          string
+	OCIPassword          string
 	Timeout              time.Duration
+	RunDeadline          time.Duration
+	BuildTimeout         time.Duration
+	RetryWait            time.Duration
+	PreReleaseMaxAge     time.Duration
+	BranchMaxAge         time.Duration
+	MaxAge               time.Duration
+	Offset               int
+	KeepPatches          int
+	PullRequestNumber    int
+	Retries              int
+	Verbosity            int
+	Parallelism          int
 	TargetIssue          string
+	ComparisonConfig     string
+	Config               string
+	LogFormat            string
+	Bump                 string
+	OutputFormat         string
+	GitDir               string
+	ChannelDir           string
+	ChannelBucket        string
+	PlanFile             string
+	ApplyPlanFile        string
+	ReleaseBranch        string
+	TargetBranch         string
+	BaseBranch           string
+	LabelSpec            string
+	AssetManifest        string
+	SignatureCommand     string
+	BackupDir            string
+	Title                string
+	WebhookURL           string
+	SlackWebhookURL      string
+	SlackTemplate        string
+	TeamsWebhookURL      string
+	EmailFrom            string
+	SMTPServer           string
+	SMTPUsername         string
+	SkewPolicy           string
+	AuditPolicy          string
+	SourceBranch         string
+	DestBranch           string
+	SourcePath           string
+	DestPath             string
+	AutobumpConfig       string
+	DownloadDir          string
+	ChecksumsAsset       string
+	ChecksumManifest     string
+	CacheDir             string
+	SHA                  string
 	DryRun               bool
 	Force                bool
+	CheckVulnerabilities bool
+	CheckLicenses        bool
+	GroupByNamespace     bool
+	StableOnly           bool
+	List                 bool
+	Strict               bool
+	Quiet                bool
+	Annotations          bool
+	Version              bool
+	Preflight            bool
+	ProtectBranch        bool
+	PullRequest          bool
+	Prune                bool
+	DeleteMerged         bool
+	Restore              bool
+	GraphQL              bool
+	CompareSHA           bool
+	ForceUpdate          bool
+	RequireSourceRelease bool
+	TagNextPreRelease    bool
+	CloseMilestone       bool
+
+	// From the HTTP connection flags, which are read by applyHTTP
+	HTTPMaxIdleConns        int
+	HTTPMaxIdleConnsPerHost int
+	HTTPMaxConnsPerHost     int
+	HTTPIdleConnTimeout     time.Duration
+	HTTPDisableKeepAlives   bool
+	HTTPDisableHTTP2        bool
 
 	// Dynamic fields
-	client    *github.Client
-	Transport *Transport
+	client       Client
+	httpClient   *http.Client
+	provider     Provider
+	prompter     Prompter
+	Transport    *Transport
+	ctx          context.Context
+	planSteps    []PlanStep
+	assetUploads []AssetUpload
 }
 
+// recordMutex guards the planSteps and assetUploads of Data, which can be
+// recorded while multiple repositories are processed in parallel.
+var recordMutex sync.Mutex
+
 // NewData creates an instance of the Data structure.
 func NewData() *Data {
 	return &Data{
 		ReleaseAssets: assetMap{},
 		IgnorePaths:   multiString{},
+		Branches:      multiString{},
+		Labels:        multiString{},
 	}
 }
 
+// GetTokens returns all tokens that were passed with the token flag.
+// If only d.Token is set a list with this token is returned.
+func (d *Data) GetTokens() []string {
+	if len(d.Tokens) != 0 {
+		return d.Tokens
+	}
+	if len(d.Token) != 0 {
+		return []string{d.Token}
+	}
+	return nil
+}
+
+// SourceRepo returns the first repository of d.Source, or an empty string
+// if there is none. It is used by the tools that have a single source repository.
+func (d *Data) SourceRepo() string {
+	if len(d.Source) == 0 {
+		return ""
+	}
+	return d.Source[0]
+}
+
+// DestRepo returns the first repository of d.Dest, or an empty string
+// if there is none. It is used by the tools that have a single destination
+// repository.
+func (d *Data) DestRepo() string {
+	if len(d.Dest) == 0 {
+		return ""
+	}
+	return d.Dest[0]
+}
+
 // CreateContext can be used to create a new Go context with a timeout
-// from data#timeout.
+// from data#timeout. The context is canceled if the process is interrupted
+// or if the run deadline is exceeded.
 func (d *Data) CreateContext() (context.Context, context.CancelFunc) {
+	if d.ctx == nil {
+		return context.WithTimeout(context.Background(), d.Timeout)
+	}
+	return context.WithTimeout(d.ctx, d.Timeout)
+}
+
+// CreateWriteContext is like CreateContext, but the context is not canceled
+// if the process is interrupted. It should be used for write operations, so that
+// an interrupt does not leave a write in an unknown state.
+func (d *Data) CreateWriteContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), d.Timeout)
 }
 
+// transferTimeout returns the timeout for uploading or downloading a release asset.
+// It is at least DefaultTransferTimeout.
+func (d *Data) transferTimeout() time.Duration {
+	if d.Timeout > DefaultTransferTimeout {
+		return d.Timeout
+	}
+	return DefaultTransferTimeout
+}
+
+// createTransferContext is like CreateContext, but uses the transfer timeout.
+func (d *Data) createTransferContext() (context.Context, context.CancelFunc) {
+	if d.ctx == nil {
+		return context.WithTimeout(context.Background(), d.transferTimeout())
+	}
+	return context.WithTimeout(d.ctx, d.transferTimeout())
+}
+
+// createTransferWriteContext is like CreateWriteContext, but uses the transfer timeout.
+func (d *Data) createTransferWriteContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), d.transferTimeout())
+}
+
 // HTTPHandler has the same signature as the only function
 // in the http.RoundTripper interface.
 type HTTPHandler func(*http.Request) (*http.Response, error)
@@ -133,6 +425,15 @@ type referenceSubset struct {
 	SHA string `json:"sha"`
 }
 
+// commitSubset is a subset of the go-github Commit object as it is sent
+// when creating a commit.
+type commitSubset struct {
+	Message string               `json:"message"`
+	Tree    string               `json:"tree"`
+	Parents []string             `json:"parents"`
+	Author  *github.CommitAuthor `json:"author,omitempty"`
+}
+
 // releaseSubset is a subset of the go-github RepositoryRelease object.
 type releaseSubset struct {
 	TagName         string `json:"tag_name"`
@@ -142,3 +443,39 @@ type releaseSubset struct {
 	Draft           bool   `json:"draft"`
 	Prerelease      bool   `json:"prerelease"`
 }
+
+// WorkflowRun is a run of a GitHub Actions workflow. The GitHub Actions API
+// is not supported by go-github yet.
+type WorkflowRun struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	WorkflowID int64     `json:"workflow_id"`
+	HeadBranch string    `json:"head_branch"`
+	HeadSHA    string    `json:"head_sha"`
+	Event      string    `json:"event"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HTMLURL    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// workflowRuns is the response of the GitHub API for listing workflow runs.
+type workflowRuns struct {
+	TotalCount   int            `json:"total_count"`
+	WorkflowRuns []*WorkflowRun `json:"workflow_runs"`
+}
+
+// Artifact is an artifact of a GitHub Actions workflow run.
+type Artifact struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	SizeInBytes        int64  `json:"size_in_bytes"`
+	ArchiveDownloadURL string `json:"archive_download_url"`
+	Expired            bool   `json:"expired"`
+}
+
+// artifacts is the response of the GitHub API for listing the artifacts of a workflow run.
+type artifacts struct {
+	TotalCount int         `json:"total_count"`
+	Artifacts  []*Artifact `json:"artifacts"`
+}
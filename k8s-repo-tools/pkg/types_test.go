@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAssetMap(t *testing.T) {
@@ -122,3 +123,126 @@ func TestMultiString(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoListValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultValue  []string
+		input         []string
+		expectedValue string
+		expectedRepos []string
+	}{
+		{
+			name:          "valid: a single repository replaces the default",
+			defaultValue:  []string{"org/default"},
+			input:         []string{"org/a"},
+			expectedValue: "org/a",
+			expectedRepos: []string{"org/a"},
+		},
+		{
+			name:          "valid: multiple instances and comma separated lists",
+			input:         []string{"org/a", "org/b,org/c"},
+			expectedValue: "org/a,org/b,org/c",
+			expectedRepos: []string{"org/a", "org/b", "org/c"},
+		},
+		{
+			name:          "valid: no input keeps the default",
+			defaultValue:  []string{"org/default"},
+			expectedValue: "org/default",
+			expectedRepos: []string{"org/default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Dest: tt.defaultValue}
+			r := &repoListValue{value: &d.Dest}
+			for _, i := range tt.input {
+				if err := r.Set(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if r.String() != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, r.String())
+			}
+			if repos := d.Dest; !reflect.DeepEqual(repos, tt.expectedRepos) {
+				t.Errorf("expected repos %v, got %v", tt.expectedRepos, repos)
+			}
+		})
+	}
+}
+
+func TestURLValue(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedError bool
+	}{
+		{
+			name:  "valid: HTTPS URL",
+			input: "https://github.example.com/",
+		},
+		{
+			name:  "valid: HTTP URL with a path",
+			input: "http://github.example.com/api/v3/",
+		},
+		{
+			name:          "invalid: missing scheme",
+			input:         "github.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: unsupported scheme",
+			input:         "ftp://github.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "invalid: malformed URL",
+			input:         "https://github.example.com/%zz",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value string
+			u := urlValue{&value}
+			err := u.Set(tt.input)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if err == nil && u.String() != tt.input {
+				t.Errorf("expected value %q, got %q", tt.input, u.String())
+			}
+		})
+	}
+}
+
+func TestTransferTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "the request timeout is raised to the minimum",
+			timeout:  DefaultRequestTimeout,
+			expected: DefaultTransferTimeout,
+		},
+		{
+			name:     "a longer request timeout is used",
+			timeout:  time.Hour,
+			expected: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Data{Timeout: tt.timeout}
+			if got := d.transferTimeout(); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	urlCacheDir      string
+	urlCacheDirMutex sync.RWMutex
+)
+
+// ConfigureURLCache sets the directory in which ReadFromURL stores the
+// downloaded files together with their ETag and Last-Modified headers.
+// Files that did not change since the last download are then served from
+// the cache after a conditional request. An empty dir disables the cache.
+func ConfigureURLCache(dir string) {
+	urlCacheDirMutex.Lock()
+	defer urlCacheDirMutex.Unlock()
+	urlCacheDir = dir
+}
+
+func getURLCacheDir() string {
+	urlCacheDirMutex.RLock()
+	defer urlCacheDirMutex.RUnlock()
+	return urlCacheDir
+}
+
+// urlCacheEntry is the cached contents of a URL.
+type urlCacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Data         []byte `json:"data"`
+}
+
+// urlCachePath returns the path of the cache file for a URL,
+// which is of the format '<dir>/urls/<sha1-of-url>.json'.
+func urlCachePath(dir, url string) string {
+	return filepath.Join(dir, "urls", fmt.Sprintf("%x.json", sha1.Sum([]byte(url))))
+}
+
+// readURLCache reads the cache entry of a URL. It returns nil if the cache
+// is disabled, or if there is no usable entry for the URL.
+func readURLCache(url string) *urlCacheEntry {
+	dir := getURLCacheDir()
+	if len(dir) == 0 {
+		return nil
+	}
+	path := urlCachePath(dir, url)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		Warningf("ignoring the URL cache: %v", err)
+		return nil
+	}
+	e := &urlCacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil || e.URL != url {
+		Warningf("ignoring the URL cache %q: the file is not valid", path)
+		return nil
+	}
+	return e
+}
+
+// writeURLCache writes the cache entry of a URL if the cache is enabled
+// and the response has a validator that can be used in a conditional request.
+func writeURLCache(e *urlCacheEntry) {
+	dir := getURLCacheDir()
+	if len(dir) == 0 || (len(e.ETag) == 0 && len(e.LastModified) == 0) {
+		return
+	}
+	path := urlCachePath(dir, e.URL)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		Warningf("could not write the URL cache: %v", err)
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		Warningf("could not write the URL cache: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		Warningf("could not write the URL cache: %v", err)
+	}
+}
+
+// setConditionalHeaders adds the validators of a cache entry to a request.
+func setConditionalHeaders(req *http.Request, e *urlCacheEntry) {
+	if e == nil {
+		return
+	}
+	if len(e.ETag) != 0 {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if len(e.LastModified) != 0 {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// readResponseBody reads the body of a response and decompresses it
+// if it was sent with 'Content-Encoding: gzip'.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	var r io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decompress the response body")
+		}
+		defer gr.Close()
+		r = gr
+	}
+	return ioutil.ReadAll(r)
+}
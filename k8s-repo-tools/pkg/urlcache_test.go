@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestReadFromURLCache(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	dir, err := ioutil.TempDir("", "urlcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	if _, err := gw.Write([]byte("gzip")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var downloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag":
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+		case "/last-modified":
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", lastModified)
+		case "/gzip":
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("expected the header 'Accept-Encoding: gzip', got %q", r.Header.Get("Accept-Encoding"))
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			downloads++
+			w.Write(gzipped.Bytes())
+			return
+		case "/not-found":
+			http.NotFound(w, r)
+			return
+		}
+		downloads++
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		cacheDir          string
+		path              string
+		expectedData      string
+		expectedDownloads int
+		expectedError     bool
+	}{
+		{
+			name:              "valid: unchanged file with an ETag is downloaded once",
+			cacheDir:          dir,
+			path:              "/etag",
+			expectedData:      "/etag",
+			expectedDownloads: 1,
+		},
+		{
+			name:              "valid: unchanged file with Last-Modified is downloaded once",
+			cacheDir:          dir,
+			path:              "/last-modified",
+			expectedData:      "/last-modified",
+			expectedDownloads: 1,
+		},
+		{
+			name:              "valid: file is downloaded every time without a cache",
+			path:              "/etag",
+			expectedData:      "/etag",
+			expectedDownloads: 2,
+		},
+		{
+			name:              "valid: file without validators is downloaded every time",
+			cacheDir:          dir,
+			path:              "/none",
+			expectedData:      "/none",
+			expectedDownloads: 2,
+		},
+		{
+			name:              "valid: gzip response is decompressed",
+			cacheDir:          dir,
+			path:              "/gzip",
+			expectedData:      "gzip",
+			expectedDownloads: 2,
+		},
+		{
+			name:          "invalid: file not found",
+			cacheDir:      dir,
+			path:          "/not-found",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureURLCache(tt.cacheDir)
+			defer ConfigureURLCache("")
+			os.RemoveAll(urlCachePath(dir, server.URL+tt.path))
+			downloads = 0
+
+			// Read the URL twice to use the cache of the first read.
+			for i := 0; i < 2; i++ {
+				data, err := ReadFromURL(server.URL+tt.path, -1)
+				if (err != nil) != tt.expectedError {
+					t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+				}
+				if string(data) != tt.expectedData {
+					t.Errorf("expected data %q, got %q", tt.expectedData, data)
+				}
+			}
+			if downloads != tt.expectedDownloads {
+				t.Errorf("expected %d downloads, got %d", tt.expectedDownloads, downloads)
+			}
+		})
+	}
+}
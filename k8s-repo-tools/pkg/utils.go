@@ -17,8 +17,8 @@ limitations under the License.
 package pkg
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -31,45 +31,77 @@ import (
 	"k8s.io/apimachinery/pkg/util/version"
 )
 
+// UnparsableRefsError is returned in strict mode if refs that should be versioned
+// cannot be parsed as versions, for example a tag with a typo.
+type UnparsableRefsError struct {
+	Refs []string
+}
+
+func (e *UnparsableRefsError) Error() string {
+	return fmt.Sprintf("found %d refs that are not valid versions: %v", len(e.Refs), e.Refs)
+}
+
+// unparsableRefs collects the refs that cannot be parsed as versions. If strict
+// is false the refs are skipped with a warning instead.
+type unparsableRefs struct {
+	strict bool
+	refs   []string
+}
+
+// add skips a ref that cannot be parsed because of err.
+func (u *unparsableRefs) add(ref *github.Reference, err error) {
+	if !u.strict {
+		Warningf(err.Error())
+		return
+	}
+	u.refs = append(u.refs, ref.GetRef())
+}
+
+// err returns an UnparsableRefsError if refs were collected in strict mode.
+func (u *unparsableRefs) err() error {
+	if len(u.refs) == 0 {
+		return nil
+	}
+	return &UnparsableRefsError{Refs: u.refs}
+}
+
 // TrimTags goes trough a list of tags and returns a trimmed list of those that are
-// SemVer and are newer or equal than the provided minimum version.
-func TrimTags(refs []*github.Reference, minV *version.Version) []*github.Reference {
-	result := []*github.Reference{}
-	for _, ref := range refs {
-		v, err := TagRefToVersion(ref)
-		if err != nil {
-			Warningf(err.Error())
-			continue
-		}
-		if v.LessThan(minV) {
-			Warningf("skipping ref %s; version is older than the minimum version", ref.GetRef())
-			continue
-		}
-		result = append(result, ref)
+// SemVer and are newer or equal than the provided minimum version. Tags that are not
+// SemVer are skipped with a warning, or returned in an UnparsableRefsError if strict is set.
+func TrimTags(refs []*github.Reference, minV *version.Version, strict bool) ([]*github.Reference, error) {
+	tags, err := NewRefSet(refs).FilterSemVer(minV, strict)
+	if err != nil {
+		return nil, err
 	}
-	return result
+	return tags.Refs(), nil
 }
 
 // TrimBranches goes trough a list of branches and returns a trimmed list of those that contain
 // a SemVer newer or equal than the provided minimum version and also contain the user provided
-// branch prefix.
-func TrimBranches(refs []*github.Reference, minV *version.Version, prefix string) []*github.Reference {
-	result := []*github.Reference{}
-	for _, ref := range refs {
-		v, err := BranchRefToVersion(ref, prefix)
-		if err != nil {
-			Warningf(err.Error())
-			continue
-		}
+// branch prefix. Branches with the prefix that are not versioned are skipped with a warning,
+// or returned in an UnparsableRefsError if strict is set.
+func TrimBranches(refs []*github.Reference, minV *version.Version, prefix string, strict bool) ([]*github.Reference, error) {
+	branches, err := NewRefSet(refs).FilterBranches(prefix, minV, strict)
+	if err != nil {
+		return nil, err
+	}
+	return branches.Refs(), nil
+}
 
-		// Only handle branches whose MAJOR.MINOR are equal or newer than the minimum version.
-		if v.Major() < minV.Major() || (minV.Major() == v.Major() && v.Minor() < minV.Minor()) {
-			Warningf("the MAJOR.MINOR in ref %q is older than the minimum version; skipping...", ref.GetRef())
-			continue
-		}
-		result = append(result, ref)
+// parseBranchRef converts a branch Reference to a Version. Branches without the
+// prefix, such as the master branch, are skipped with a warning. Branches with the
+// prefix that cannot be parsed are added to unparsable.
+func parseBranchRef(ref *github.Reference, prefix string, unparsable *unparsableRefs) (*version.Version, error) {
+	v, err := BranchRefToVersion(ref, prefix)
+	if err == nil {
+		return v, nil
 	}
-	return result
+	if !strings.HasPrefix(strings.TrimPrefix(ref.GetRef(), "refs/heads/"), prefix) {
+		Warningf(err.Error())
+		return nil, err
+	}
+	unparsable.add(ref, err)
+	return nil, err
 }
 
 // TagRefToVersion converts a tag Reference to a Version.
@@ -79,12 +111,7 @@ func TagRefToVersion(ref *github.Reference) (*version.Version, error) {
 
 // TagToVersion converts a tag string to Version.
 func TagToVersion(tag string) (*version.Version, error) {
-	ver := strings.TrimPrefix(tag, "refs/tags/")
-	if strings.Count(ver, ".") < 2 { // a version without a .PATCH component?
-		ver = ver + ".0"
-	}
-
-	v, err := version.ParseSemantic(ver)
+	v, err := ParseVersion(strings.TrimPrefix(tag, "refs/tags/"))
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot parse tag reference %q", tag)
 	}
@@ -94,40 +121,16 @@ func TagToVersion(tag string) (*version.Version, error) {
 // BranchRefToVersion converts a branch Reference to a Version.
 func BranchRefToVersion(ref *github.Reference, prefix string) (*version.Version, error) {
 	refStr := ref.GetRef()
-	ver := strings.TrimPrefix(refStr, "refs/heads/")
-	if !strings.HasPrefix(ver, prefix) {
+	if !strings.HasPrefix(strings.TrimPrefix(refStr, "refs/heads/"), prefix) {
 		return nil, errors.Errorf("skipping non-prefixed ref %q...", refStr)
 	}
-
-	ver = strings.TrimPrefix(ver, prefix)
-	if strings.Count(ver, ".") < 2 { // a version without a .PATCH component?
-		ver = ver + ".0"
-	}
-
-	v, err := version.ParseSemantic(ver)
-	if err != nil {
-		return nil, errors.Wrapf(err, "cannot parse versioned branch from ref %q", refStr)
-	}
-	return v, nil
+	return BranchToVersion(refStr, prefix)
 }
 
 // FindNewRefs goes trough two lists, src and dest and returns a list
 // of elements present in dest but not in src.
 func FindNewRefs(src, dest []*github.Reference) []*github.Reference {
-	new := []*github.Reference{}
-	for _, a := range src {
-		found := false
-		for _, b := range dest {
-			if a.GetRef() == b.GetRef() {
-				found = true
-				break
-			}
-		}
-		if !found {
-			new = append(new, a)
-		}
-	}
-	return new
+	return NewRefSet(src).Diff(NewRefSet(dest)).Refs()
 }
 
 // FindBranchHEADForTag matches a SemVer tag to a versioned branch's MAJOR.MINOR
@@ -138,110 +141,105 @@ func FindBranchHEADForTag(
 	masterSHA string,
 	branches []*github.Reference) string {
 
-	tagStr := tag.GetRef()
-	prefix := "refs/tags/"
-	tagStr = strings.TrimPrefix(tagStr, prefix)
-	tagVer, err := version.ParseSemantic(tagStr)
+	if branch := FindBranchForTag(tag, prefixBranch, branches); branch != nil {
+		sha := branch.GetObject().GetSHA()
+		Logf("found matching destination branch %q for tag %q with HEAD %q", branch.GetRef(), tag, sha)
+		return sha
+	}
+	Logf("using the %q branch for new tag %q", BranchMaster, tag)
+	return masterSHA
+}
+
+// FindBranchForTag matches a SemVer tag to a versioned branch's MAJOR.MINOR
+// and returns the matching branch. If no branch matches it returns nil.
+func FindBranchForTag(tag *github.Reference, prefixBranch string, branches []*github.Reference) *github.Reference {
+	tagStr := strings.TrimPrefix(tag.GetRef(), "refs/tags/")
+	tagVer, err := TagToVersion(tagStr)
 	if err != nil {
-		Warningf("skipping non-versioned input ref %s", tag.GetRef(), err)
-		goto exit
+		V(2).Warningf("skipping non-versioned input ref %s: %v", tag.GetRef(), err)
+		return nil
 	}
-	Logf("finding branch for tag %q", tagStr)
+	V(1).Logf("finding branch for tag %q", tagStr)
 
 	for _, branch := range branches {
 		branchRef := strings.TrimPrefix(branch.GetRef(), "refs/heads/")
 		if branchRef == BranchMaster {
 			continue
 		}
-		branchRef = strings.TrimPrefix(branchRef, prefixBranch)
-		if strings.Count(branchRef, ".") < 2 {
-			branchRef = branchRef + ".0"
-		}
-		branchVer, err := version.ParseSemantic(branchRef)
+		branchVer, err := BranchToVersion(branchRef, prefixBranch)
 		if err != nil {
-			Warningf("skipping ref %s: %v", branch.GetRef(), err)
+			V(2).Warningf("skipping ref %s: %v", branch.GetRef(), err)
 			continue
 		}
 		if tagVer.Major() == branchVer.Major() && tagVer.Minor() == branchVer.Minor() {
-			sha := branch.GetObject().GetSHA()
-			Logf("found matching destination branch %q for tag %q with HEAD %q", branch.GetRef(), tag, sha)
-			return sha
+			return branch
 		}
 	}
-exit:
-	Logf("using the %q branch for new tag %q", BranchMaster, tag)
-	return masterSHA
+	return nil
 }
 
 // FindLatestBranch goes trough a list of branches and finds the latest
-// based on its prefixMAJOR.MINOR format.
-func FindLatestBranch(refs []*github.Reference, prefix string) (*github.Reference, error) {
-	var result *github.Reference
-	minV := version.MustParseSemantic("v0.0.0")
-
-	for _, ref := range refs {
-		v, err := BranchRefToVersion(ref, prefix)
-		if err != nil {
-			Warningf(err.Error())
-			continue
-		}
+// based on its prefixMAJOR.MINOR format. If strict is set, branches with the
+// prefix that are not versioned are returned in an UnparsableRefsError.
+func FindLatestBranch(refs []*github.Reference, prefix string, strict bool) (*github.Reference, error) {
+	ref, err := NewRefSet(refs).LatestBranch(prefix, strict)
+	if err != nil {
+		return nil, err
+	}
+	r := *ref
+	return &r, nil
+}
 
-		if minV.LessThan(v) {
-			minV = v
+// FindLatestTag goes trough a list of tags and finds the latest for a given branch version.
+// If strict is set, tags that are not SemVer are returned in an UnparsableRefsError.
+func FindLatestTag(refs []*github.Reference, branchV *version.Version, strict bool) (*github.Reference, error) {
+	latest, err := NewRefSet(refs).LatestPerMinor(strict)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range latest.refs {
+		v, _ := TagRefToVersion(ref)
+		if v.Major() == branchV.Major() && v.Minor() == branchV.Minor() {
 			r := *ref
-			result = &r
+			return &r, nil
 		}
 	}
-
-	if result == nil {
-		return nil, errors.Errorf("could not find any branches of the format %sMAJOR.MINOR", prefix)
-	}
-	return result, nil
+	return nil, errors.Errorf("could not find any SemVer tag that matches branch version %d.%d",
+		branchV.Major(), branchV.Minor())
 }
 
-// FindLatestTag goes trough a list of tags and finds the latest for a given branch version.
-func FindLatestTag(refs []*github.Reference, branchV *version.Version) (*github.Reference, error) {
-	var result *github.Reference
-	minV := version.MustParseSemantic("v0.0.0")
-
-	for _, ref := range refs {
-		v, err := TagRefToVersion(ref)
-		if err != nil {
-			Warningf(err.Error())
-			continue
-		}
+// promptInput and promptOutput are used by ShowPrompt.
+var (
+	promptInput  io.Reader = os.Stdin
+	promptOutput io.Writer = os.Stdout
+)
 
-		if v.Major() != branchV.Major() || v.Minor() != branchV.Minor() {
-			continue
-		}
+// SetPromptIO sets the reader and writer that ShowPrompt and the default
+// Prompter of Data use instead of stdin and stdout.
+func SetPromptIO(r io.Reader, w io.Writer) {
+	promptInput = r
+	promptOutput = w
+}
 
-		if minV.LessThan(v) {
-			minV = v
-			r := *ref
-			result = &r
-		}
+// isInteractive returns false if r is a file that is not a terminal,
+// such as stdin in CI or when the input is piped.
+func isInteractive(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return true
 	}
-
-	if result == nil {
-		return nil, errors.Errorf("could not find any SemVer tag that matches branch version %d.%d",
-			branchV.Major(), branchV.Minor())
+	fi, err := f.Stat()
+	if err != nil {
+		return false
 	}
-	return result, nil
+	return fi.Mode()&os.ModeCharDevice != 0
 }
 
 // ShowPrompt shows a confirmation prompt to the user.
+// It returns an error if the input is not interactive.
+// Tools use the Prompter of Data instead, which can be replaced.
 func ShowPrompt(message string) (bool, error) {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf(message + " [y/n]: ")
-	resp, err := reader.ReadString('\n')
-	if err != nil {
-		return false, err
-	}
-	resp = strings.ToLower(strings.TrimSpace(resp))
-	if resp == "y" || resp == "yes" {
-		return true, nil
-	}
-	return false, nil
+	return NewInteractivePrompter(promptInput, promptOutput).Confirm(message)
 }
 
 // FindReleaseNotesSinceRef takes a k8s release SemVer tag reference and determines
@@ -268,59 +266,23 @@ func FindReleaseNotesSinceRef(ref *github.Reference, refs []*github.Reference) (
 
 	var result *github.Reference
 
-	// Not a pre-release.
-	if len(ver.PreRelease()) == 0 {
-		// Handle MINOR release.
-		if ver.Patch() == 0 {
-			major := int(ver.Major())
-			minor := int(ver.Minor()) - 1
-			// Handle MAJOR release.
-			if minor < 0 {
-				major--
-				if major < 0 {
-					goto exit
-				}
-				largest := version.MustParseSemantic(fmt.Sprintf("v%d.0.0", major))
-				result = findLargestMinorForMajorRef(largest, refs)
-			} else {
-				target := version.MustParseSemantic(fmt.Sprintf("v%d.%d.0", major, minor))
-				result = findExactVersionRef(target, refs)
-			}
-		} else {
-			// Handle PATCH release.
-			target := version.MustParseSemantic(ver.String()).WithPatch(ver.Patch() - 1)
+	switch {
+	case ver.PreRelease() == "alpha.0":
+		break
+	case len(ver.PreRelease()) == 0, ver.PreRelease() == "alpha.1":
+		if target := PreviousRelease(ver); target != nil {
 			result = findExactVersionRef(target, refs)
+		} else if ver.Minor() == 0 && ver.Major() > 0 {
+			// Handle MAJOR release.
+			largest := version.MustParseSemantic(fmt.Sprintf("v%d.0.0", ver.Major()-1))
+			result = findLargestMinorForMajorRef(largest, refs)
 		}
-	} else {
-		// Split the pre-release into "pre[0].pre[1]" e.g. "alpha.1".
-		// This format is guaranteed by the initial ParseSemantic() for "ver".
-		pre := strings.Split(ver.PreRelease(), ".")
-		// Handle alpha.0.
-		if pre[0] == "alpha" && pre[1] == "0" {
-			goto exit
-		}
-		// Handle alpha.1.
-		if pre[0] == "alpha" && pre[1] == "1" {
-			major := int(ver.Major())
-			minor := int(ver.Minor()) - 1
-			if minor < 0 {
-				major--
-				if major < 0 {
-					goto exit
-				}
-				largest := version.MustParseSemantic(fmt.Sprintf("v%d.0.0", major))
-				result = findLargestMinorForMajorRef(largest, refs)
-			} else {
-				target := version.MustParseSemantic(fmt.Sprintf("v%d.%d.0", major, minor))
-				result = findExactVersionRef(target, refs)
-			}
-			goto exit
-		}
+	default:
 		// Handle other pre-releases.
 		// k8s does not have pre-releases for PATCH releases.
 		result = findPreviousPreRelease(ver, refs)
 	}
-exit:
+
 	if result == nil {
 		Warningf("could not find a release notes range based on reference %q; returning the same reference", ref.GetRef())
 		result = ref
@@ -332,14 +294,9 @@ func findLargestMinorForMajorRef(largest *version.Version, refs []*github.Refere
 	var result *github.Reference
 
 	for i := range refs {
-		tag := refs[i].GetRef()
-		tag = strings.TrimPrefix(tag, "refs/tags/")
-		if strings.Count(tag, ".") < 2 { // a version without a .PATCH component?
-			tag = tag + ".0"
-		}
-		ver, err := version.ParseSemantic(tag)
+		ver, err := TagRefToVersion(refs[i])
 		if err != nil {
-			Warningf("skipping ref %s: %v", refs[i], err)
+			V(2).Warningf("skipping ref %s: %v", refs[i], err)
 			continue
 		}
 		if largest.LessThan(ver) && largest.Major() == ver.Major() {
@@ -352,17 +309,12 @@ func findLargestMinorForMajorRef(largest *version.Version, refs []*github.Refere
 
 func findExactVersionRef(target *version.Version, refs []*github.Reference) *github.Reference {
 	for i := range refs {
-		tag := refs[i].GetRef()
-		tag = strings.TrimPrefix(tag, "refs/tags/")
-		if strings.Count(tag, ".") < 2 { // a version without a .PATCH component?
-			tag = tag + ".0"
-		}
-		ver, err := version.ParseSemantic(tag)
+		ver, err := TagRefToVersion(refs[i])
 		if err != nil {
-			Warningf("skipping ref %s: %v", refs[i], err)
+			V(2).Warningf("skipping ref %s: %v", refs[i], err)
 			continue
 		}
-		if target.String() == ver.String() {
+		if EqualVersions(target, ver) {
 			return refs[i]
 		}
 	}
@@ -374,14 +326,9 @@ func findPreviousPreRelease(target *version.Version, refs []*github.Reference) *
 	var result *github.Reference
 
 	for i := range refs {
-		tag := refs[i].GetRef()
-		tag = strings.TrimPrefix(tag, "refs/tags/")
-		if strings.Count(tag, ".") < 2 { // a version without a .PATCH component?
-			tag = tag + ".0"
-		}
-		ver, err := version.ParseSemantic(tag)
+		ver, err := TagRefToVersion(refs[i])
 		if err != nil {
-			Warningf("skipping ref %s: %v", refs[i], err)
+			V(2).Warningf("skipping ref %s: %v", refs[i], err)
 			continue
 		}
 		if largest.LessThan(ver) && ver.LessThan(target) {
@@ -403,33 +350,52 @@ func FormatMergeCommitMessage(base, head string) string {
 // ReadFromURL reads the contents of a URL and returns the data
 // as bytes. "timeout" allows passing timeout to the HTTP request.
 // If -1 is passed as "timeout" a default value is used.
+// If a cache directory was set with ConfigureURLCache, unchanged
+// contents are served from the cache after a conditional request.
 func ReadFromURL(url string, timeout time.Duration) ([]byte, error) {
 	Logf("fetching date from %s", url)
 
 	if timeout < 0 {
 		timeout = 10 * time.Second
 	}
-	client := http.Client{Timeout: timeout}
+	client := NewHTTPClient(timeout)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
+	// Setting the header disables the transparent decompression of the
+	// transport, so the body is decompressed in readResponseBody.
+	req.Header.Set("Accept-Encoding", "gzip")
+	setURLToken(req)
+	cached := readURLCache(url)
+	setConditionalHeaders(req, cached)
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		Logf("using the cached copy of %s, since it did not change", url)
+		return cached.Data, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Wrapf(err, "received status %d", resp.StatusCode)
+		return nil, errors.Errorf("received status %d from %s", resp.StatusCode, url)
 	}
 
-	data, err := ioutil.ReadAll(resp.Body)
+	data, err := readResponseBody(resp)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not read response body")
 	}
 
+	writeURLCache(&urlCacheEntry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Data:         data,
+	})
 	return data, nil
 }
 
@@ -442,10 +408,20 @@ func isValidURL(s string) bool {
 }
 
 // ReadFromFileOrURL loads the data from location if its a file path or URL.
+// A location of the format 'github://org/repo@ref/path' is read from
+// raw.githubusercontent.com, with the token set with ConfigureURLToken
+// for private repositories.
 func ReadFromFileOrURL(location string, timeout time.Duration) ([]byte, error) {
 	var err error
 	var data []byte
 
+	if IsGitHubURL(location) {
+		u, err := ParseGitHubURL(location)
+		if err != nil {
+			return nil, err
+		}
+		location = u.RawURL()
+	}
 	if isValidURL(location) {
 		data, err = ReadFromURL(location, timeout)
 	} else {
@@ -17,12 +17,15 @@ limitations under the License.
 package pkg
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v29/github"
+	"k8s.io/apimachinery/pkg/util/version"
 )
 
 func TestFindReleaseNotesSinceRef(t *testing.T) {
@@ -157,6 +160,100 @@ func TestFindReleaseNotesSinceRef(t *testing.T) {
 	}
 }
 
+func TestTrimRefsStrict(t *testing.T) {
+	SetLogWriters(ioutil.Discard, ioutil.Discard)
+
+	minV := version.MustParseSemantic("v1.0.0")
+	tests := []struct {
+		name             string
+		tags             []*github.Reference
+		branches         []*github.Reference
+		strict           bool
+		expectedTags     []string
+		expectedBranches []string
+		expectedRefs     []string
+	}{
+		{
+			name: "valid: unparsable refs are skipped if not strict",
+			tags: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.0.0")},
+				&github.Reference{Ref: github.String("refs/tags/v1.1.O")},
+			},
+			branches: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/master")},
+				&github.Reference{Ref: github.String("refs/heads/release-1.0")},
+				&github.Reference{Ref: github.String("refs/heads/release-1.x")},
+			},
+			expectedTags:     []string{"refs/tags/v1.0.0"},
+			expectedBranches: []string{"refs/heads/release-1.0"},
+		},
+		{
+			name: "valid: branches without the prefix are skipped in strict mode",
+			tags: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.0.0")},
+			},
+			branches: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/master")},
+				&github.Reference{Ref: github.String("refs/heads/release-1.0")},
+			},
+			strict:           true,
+			expectedTags:     []string{"refs/tags/v1.0.0"},
+			expectedBranches: []string{"refs/heads/release-1.0"},
+		},
+		{
+			name: "invalid: unparsable refs are returned in strict mode",
+			tags: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/tags/v1.0.0")},
+				&github.Reference{Ref: github.String("refs/tags/v1.1.O")},
+			},
+			branches: []*github.Reference{
+				&github.Reference{Ref: github.String("refs/heads/release-1.0")},
+				&github.Reference{Ref: github.String("refs/heads/release-1.x")},
+			},
+			strict:       true,
+			expectedRefs: []string{"refs/tags/v1.1.O", "refs/heads/release-1.x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var refs []string
+			tags, err := TrimTags(tt.tags, minV, tt.strict)
+			if unparsable, ok := err.(*UnparsableRefsError); ok {
+				refs = append(refs, unparsable.Refs...)
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			branches, err := TrimBranches(tt.branches, minV, "release-", tt.strict)
+			if unparsable, ok := err.(*UnparsableRefsError); ok {
+				refs = append(refs, unparsable.Refs...)
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(refs, tt.expectedRefs) {
+				t.Errorf("expected unparsable refs %v, got %v", tt.expectedRefs, refs)
+			}
+			if refs != nil {
+				return
+			}
+			if got := refNames(tags); !reflect.DeepEqual(got, tt.expectedTags) {
+				t.Errorf("expected tags %v, got %v", tt.expectedTags, got)
+			}
+			if got := refNames(branches); !reflect.DeepEqual(got, tt.expectedBranches) {
+				t.Errorf("expected branches %v, got %v", tt.expectedBranches, got)
+			}
+		})
+	}
+}
+
+func refNames(refs []*github.Reference) []string {
+	result := []string{}
+	for _, ref := range refs {
+		result = append(result, ref.GetRef())
+	}
+	return result
+}
+
 func TestIsValidURL(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -194,3 +291,60 @@ func TestIsValidURL(t *testing.T) {
 		})
 	}
 }
+
+func TestShowPrompt(t *testing.T) {
+	defer SetPromptIO(os.Stdin, os.Stdout)
+
+	// A regular file is not a terminal.
+	file, err := ioutil.TempFile("", "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	tests := []struct {
+		name           string
+		input          io.Reader
+		expectedResult bool
+		expectedError  bool
+	}{
+		{
+			name:           "valid: yes",
+			input:          strings.NewReader("yes\n"),
+			expectedResult: true,
+		},
+		{
+			name:           "valid: y without a new line",
+			input:          strings.NewReader("Y"),
+			expectedResult: true,
+		},
+		{
+			name:  "valid: no",
+			input: strings.NewReader("n\n"),
+		},
+		{
+			name:          "invalid: empty input",
+			input:         strings.NewReader(""),
+			expectedError: true,
+		},
+		{
+			name:          "invalid: input is not a terminal",
+			input:         file,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPromptIO(tt.input, ioutil.Discard)
+			result, err := ShowPrompt("continue?")
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error %v, got %v, error: %v", tt.expectedError, err != nil, err)
+			}
+			if result != tt.expectedResult {
+				t.Errorf("expected result: %v, got: %v", tt.expectedResult, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// These variables are set at build time with -ldflags "-X", for example
+// "-X 'k8s.io/kubeadm/k8s-repo-tools/pkg.gitVersion=v1.0.0'". See hack/version.sh.
+var (
+	gitVersion = ""
+	gitCommit  = ""
+	buildDate  = ""
+)
+
+// VersionInfo holds the build information of a tool.
+type VersionInfo struct {
+	GitVersion string `json:"gitVersion"`
+	GitCommit  string `json:"gitCommit"`
+	BuildDate  string `json:"buildDate"`
+	GoVersion  string `json:"goVersion"`
+	Platform   string `json:"platform"`
+}
+
+// GetVersionInfo returns the build information. If the version was not set
+// at build time the version of the main module is used, which is set when
+// the tool is installed with "go get" at a specific version.
+func GetVersionInfo() VersionInfo {
+	info := VersionInfo{
+		GitVersion: gitVersion,
+		GitCommit:  gitCommit,
+		BuildDate:  buildDate,
+		GoVersion:  runtime.Version(),
+		Platform:   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+	if len(info.GitVersion) == 0 {
+		info.GitVersion = "unknown"
+		if bi, ok := debug.ReadBuildInfo(); ok && len(bi.Main.Version) != 0 {
+			info.GitVersion = bi.Main.Version
+		}
+	}
+	if len(info.GitCommit) == 0 {
+		info.GitCommit = "unknown"
+	}
+	if len(info.BuildDate) == 0 {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// PrintVersion writes the build information of a tool to w.
+func PrintVersion(w io.Writer, name string) {
+	info := GetVersionInfo()
+	fmt.Fprintf(w, "%s version %s, git commit %s, built %s with %s for %s\n",
+		name, info.GitVersion, info.GitCommit, info.BuildDate, info.GoVersion, info.Platform)
+}
+
+// PrintVersionAndExit prints the build information of a tool to stdout and exits.
+func PrintVersionAndExit(name string) {
+	PrintVersion(os.Stdout, name)
+	os.Exit(0)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPrintVersion(t *testing.T) {
+	defer func(v, c, b string) {
+		gitVersion, gitCommit, buildDate = v, c, b
+	}(gitVersion, gitCommit, buildDate)
+
+	tests := []struct {
+		name           string
+		gitVersion     string
+		gitCommit      string
+		buildDate      string
+		expectedPrefix string
+	}{
+		{
+			name:           "valid: version set at build time",
+			gitVersion:     "v1.2.3",
+			gitCommit:      "abcdef",
+			buildDate:      "2020-01-01T00:00:00Z",
+			expectedPrefix: "tool version v1.2.3, git commit abcdef, built 2020-01-01T00:00:00Z with " + runtime.Version(),
+		},
+		{
+			name:           "valid: commit and date are not set",
+			gitVersion:     "v1.2.3",
+			expectedPrefix: "tool version v1.2.3, git commit unknown, built unknown with " + runtime.Version(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitVersion, gitCommit, buildDate = tt.gitVersion, tt.gitCommit, tt.buildDate
+			buf := &bytes.Buffer{}
+			PrintVersion(buf, "tool")
+			if !strings.HasPrefix(buf.String(), tt.expectedPrefix) {
+				t.Errorf("expected output with prefix %q, got %q", tt.expectedPrefix, buf.String())
+			}
+		})
+	}
+}